@@ -0,0 +1,163 @@
+package atomic
+
+import (
+	"encoding/binary"
+	"log"
+
+	"github.com/sarchlab/akita/v4/mem/mem"
+	"github.com/sarchlab/mgpusim/v4/amd/insts"
+)
+
+// Op is a read-modify-write operation.
+type Op int
+
+// A list of all the supported operations.
+const (
+	OpSwap Op = iota
+	OpCmpSwap
+	OpAdd
+	OpSub
+	OpSMin
+	OpUMin
+	OpSMax
+	OpUMax
+	OpAnd
+	OpOr
+	OpXor
+	OpInc
+	OpDec
+)
+
+// Info describes the operation of an atomic access.
+type Info struct {
+	Op Op
+
+	// Data is the operand of the operation. It has the same size as the
+	// access.
+	Data []byte
+
+	// CmpData is the value to compare with. It is only used by OpCmpSwap.
+	CmpData []byte
+}
+
+// InfoOf returns the atomic operation that a request carries. It returns nil
+// if the request is not an atomic access.
+func InfoOf(req mem.AccessReq) *Info {
+	read, ok := req.(*mem.ReadReq)
+	if !ok {
+		return nil
+	}
+
+	info, _ := read.Info.(*Info)
+
+	return info
+}
+
+// Apply returns the data after applying the operation to the old data. The
+// old data must be either 4 or 8 bytes.
+//
+//nolint:gocyclo
+func (i *Info) Apply(old []byte) []byte {
+	size := len(old)
+	o := toUint64(old)
+	d := toUint64(i.Data)
+
+	var n uint64
+
+	switch i.Op {
+	case OpSwap:
+		n = d
+	case OpCmpSwap:
+		n = o
+		if o == toUint64(i.CmpData) {
+			n = d
+		}
+	case OpAdd:
+		n = o + d
+	case OpSub:
+		n = o - d
+	case OpSMin:
+		n = d
+		if toInt64(o, size) < toInt64(d, size) {
+			n = o
+		}
+	case OpUMin:
+		n = min(o, d)
+	case OpSMax:
+		n = d
+		if toInt64(o, size) > toInt64(d, size) {
+			n = o
+		}
+	case OpUMax:
+		n = max(o, d)
+	case OpAnd:
+		n = o & d
+	case OpOr:
+		n = o | d
+	case OpXor:
+		n = o ^ d
+	case OpInc:
+		n = o + 1
+		if o >= d {
+			n = 0
+		}
+	case OpDec:
+		n = o - 1
+		if o == 0 || o > d {
+			n = d
+		}
+	default:
+		log.Panicf("atomic operation %d is not supported", i.Op)
+	}
+
+	return fromUint64(n, size)
+}
+
+func toUint64(data []byte) uint64 {
+	switch len(data) {
+	case 4:
+		return uint64(binary.LittleEndian.Uint32(data))
+	case 8:
+		return binary.LittleEndian.Uint64(data)
+	default:
+		log.Panicf("atomic access of %d bytes is not supported", len(data))
+	}
+
+	return 0
+}
+
+func toInt64(v uint64, size int) int64 {
+	if size == 4 {
+		return int64(int32(uint32(v)))
+	}
+
+	return int64(v)
+}
+
+func fromUint64(v uint64, size int) []byte {
+	data := make([]byte, 8)
+	binary.LittleEndian.PutUint64(data, v)
+
+	return data[:size]
+}
+
+var flatOps = map[insts.Opcode]Op{
+	64: OpSwap, 65: OpCmpSwap, 66: OpAdd, 67: OpSub,
+	68: OpSMin, 69: OpUMin, 70: OpSMax, 71: OpUMax,
+	72: OpAnd, 73: OpOr, 74: OpXor, 75: OpInc, 76: OpDec,
+}
+
+// FlatOp returns the operation of a FLAT atomic instruction and the number of
+// bytes that each lane accesses. The returned ok is false if the opcode is not
+// a FLAT atomic instruction.
+func FlatOp(opcode insts.Opcode) (op Op, byteSize uint64, ok bool) {
+	if op, ok = flatOps[opcode]; ok {
+		return op, 4, true
+	}
+
+	if op, ok = flatOps[opcode-32]; ok && opcode >= 96 {
+		return op, 8, true
+	}
+
+	return 0, 0, false
+}
@@ -0,0 +1,90 @@
+package atomic_test
+
+import (
+	"encoding/binary"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/sarchlab/akita/v4/mem/mem"
+	"github.com/sarchlab/mgpusim/v4/amd/atomic"
+)
+
+func u32(v uint32) []byte {
+	data := make([]byte, 4)
+	binary.LittleEndian.PutUint32(data, v)
+
+	return data
+}
+
+var _ = Describe("Info", func() {
+	DescribeTable("should apply 32-bit operations",
+		func(op atomic.Op, old, data, cmp, expected uint32) {
+			info := &atomic.Info{Op: op, Data: u32(data), CmpData: u32(cmp)}
+
+			Expect(info.Apply(u32(old))).To(Equal(u32(expected)))
+		},
+		Entry("swap", atomic.OpSwap, uint32(1), uint32(2), uint32(0), uint32(2)),
+		Entry("cmpswap equal", atomic.OpCmpSwap,
+			uint32(1), uint32(2), uint32(1), uint32(2)),
+		Entry("cmpswap not equal", atomic.OpCmpSwap,
+			uint32(1), uint32(2), uint32(3), uint32(1)),
+		Entry("add", atomic.OpAdd, uint32(1), uint32(2), uint32(0), uint32(3)),
+		Entry("sub", atomic.OpSub,
+			uint32(1), uint32(2), uint32(0), uint32(0xffffffff)),
+		Entry("smin", atomic.OpSMin,
+			uint32(0xffffffff), uint32(2), uint32(0), uint32(0xffffffff)),
+		Entry("umin", atomic.OpUMin,
+			uint32(0xffffffff), uint32(2), uint32(0), uint32(2)),
+		Entry("smax", atomic.OpSMax,
+			uint32(0xffffffff), uint32(2), uint32(0), uint32(2)),
+		Entry("umax", atomic.OpUMax,
+			uint32(0xffffffff), uint32(2), uint32(0), uint32(0xffffffff)),
+		Entry("and", atomic.OpAnd, uint32(6), uint32(3), uint32(0), uint32(2)),
+		Entry("or", atomic.OpOr, uint32(6), uint32(3), uint32(0), uint32(7)),
+		Entry("xor", atomic.OpXor, uint32(6), uint32(3), uint32(0), uint32(5)),
+		Entry("inc", atomic.OpInc, uint32(1), uint32(3), uint32(0), uint32(2)),
+		Entry("inc wraps", atomic.OpInc,
+			uint32(3), uint32(3), uint32(0), uint32(0)),
+		Entry("dec", atomic.OpDec, uint32(2), uint32(3), uint32(0), uint32(1)),
+		Entry("dec wraps", atomic.OpDec,
+			uint32(0), uint32(3), uint32(0), uint32(3)),
+	)
+
+	It("should apply 64-bit operations", func() {
+		old := make([]byte, 8)
+		binary.LittleEndian.PutUint64(old, 0xffffffff)
+		data := make([]byte, 8)
+		binary.LittleEndian.PutUint64(data, 1)
+		info := &atomic.Info{Op: atomic.OpAdd, Data: data}
+
+		n := info.Apply(old)
+
+		Expect(binary.LittleEndian.Uint64(n)).To(Equal(uint64(0x100000000)))
+	})
+
+	It("should find the info of an atomic request", func() {
+		info := &atomic.Info{Op: atomic.OpAdd, Data: u32(1)}
+		read := mem.ReadReqBuilder{}.WithInfo(info).Build()
+
+		Expect(atomic.InfoOf(read)).To(BeIdenticalTo(info))
+		Expect(atomic.InfoOf(mem.ReadReqBuilder{}.Build())).To(BeNil())
+		Expect(atomic.InfoOf(mem.WriteReqBuilder{}.Build())).To(BeNil())
+	})
+})
+
+var _ = Describe("FlatOp", func() {
+	It("should decode FLAT atomic opcodes", func() {
+		op, size, ok := atomic.FlatOp(66)
+		Expect(ok).To(BeTrue())
+		Expect(op).To(Equal(atomic.OpAdd))
+		Expect(size).To(Equal(uint64(4)))
+
+		op, size, ok = atomic.FlatOp(97)
+		Expect(ok).To(BeTrue())
+		Expect(op).To(Equal(atomic.OpCmpSwap))
+		Expect(size).To(Equal(uint64(8)))
+
+		_, _, ok = atomic.FlatOp(20)
+		Expect(ok).To(BeFalse())
+	})
+})
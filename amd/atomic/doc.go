@@ -0,0 +1,8 @@
+// Package atomic defines the read-modify-write operations of the atomic
+// memory instructions.
+//
+// An atomic access travels through the memory hierarchy as a mem.ReadReq
+// whose Info field is an *Info. The caches that do not hold the data
+// coherently forward the request. The cache that does applies the operation
+// to the data and responds with the data before the operation.
+package atomic
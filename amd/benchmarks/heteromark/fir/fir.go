@@ -34,7 +34,12 @@ type Benchmark struct {
 	hsaco   *insts.HsaCo
 	gpus    []int
 
-	Length       int
+	Length int
+
+	// NumIterations is the number of times that the kernel runs on the same
+	// input. The kernel runs once if it is not set.
+	NumIterations int
+
 	numTaps      int
 	inputData    []float32
 	filterData   []float32
@@ -147,12 +152,24 @@ func (b *Benchmark) exec() {
 			int64(i * numWi / len(b.gpus)), 0, 0,
 		}
 
-		b.driver.EnqueueLaunchKernel(
+		if b.NumIterations <= 1 {
+			b.driver.EnqueueLaunchKernel(
+				queues[i],
+				b.hsaco,
+				[3]uint32{uint32(numWi / len(b.gpus)), 1, 1},
+				[3]uint16{256, 1, 1}, &kernArg,
+			)
+
+			continue
+		}
+
+		cmd := b.driver.PrepareKernel(
 			queues[i],
 			b.hsaco,
 			[3]uint32{uint32(numWi / len(b.gpus)), 1, 1},
 			[3]uint16{256, 1, 1}, &kernArg,
 		)
+		b.driver.EnqueueKernelRepeated(queues[i], cmd, b.NumIterations)
 	}
 
 	for i := range b.gpus {
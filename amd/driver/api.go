@@ -1,6 +1,7 @@
 package driver
 
 import (
+	"fmt"
 	"log"
 	"math"
 	"sync/atomic"
@@ -29,6 +30,8 @@ func (d *Driver) Init() *Context {
 	d.contexts = append(d.contexts, c)
 	d.contextMutex.Unlock()
 
+	d.recordNewContext(c, nil, nil)
+
 	return c
 }
 
@@ -37,6 +40,7 @@ func (d *Driver) Init() *Context {
 func (d *Driver) InitWithExistingPID(ctx *Context) *Context {
 	c := &Context{
 		pid:          ctx.pid,
+		isolated:     ctx.isolated,
 		currentGPUID: 1,
 	}
 
@@ -44,14 +48,78 @@ func (d *Driver) InitWithExistingPID(ctx *Context) *Context {
 	d.contexts = append(d.contexts, c)
 	d.contextMutex.Unlock()
 
+	d.recordNewContext(c, ctx, nil)
+
+	return c
+}
+
+// ContextOptions configures a context created with CreateContextWithOptions.
+type ContextOptions struct {
+	// PreferredDevice is the GPU that the context selects when it is created.
+	// If it is 0, GPU 1 is selected.
+	PreferredDevice int
+
+	// IsolatedAddressSpace gives the context a page table region of its own.
+	// The virtual addresses allocated in an isolated context are not visible
+	// to any other context. Contexts that are not isolated share the address
+	// space of the first context that is not isolated, which is usually the
+	// one that Init creates.
+	IsolatedAddressSpace bool
+}
+
+// CreateContextWithOptions creates a context with the given options.
+func (d *Driver) CreateContextWithOptions(opts ContextOptions) *Context {
+	gpuID := 1
+	if opts.PreferredDevice != 0 {
+		if opts.PreferredDevice < 0 || opts.PreferredDevice >= len(d.devices) {
+			log.Panicf("GPU %d is not available", opts.PreferredDevice)
+		}
+
+		gpuID = opts.PreferredDevice
+	}
+
+	d.contextMutex.Lock()
+	defer d.contextMutex.Unlock()
+
+	c := &Context{
+		isolated:     opts.IsolatedAddressSpace,
+		currentGPUID: gpuID,
+	}
+	if c.isolated {
+		c.pid = vm.PID(atomic.AddUint64(&nextPID, 1))
+	} else {
+		c.pid = d.sharedContextPID()
+	}
+	d.contexts = append(d.contexts, c)
+
+	d.recordNewContext(c, nil, &opts)
+
 	return c
 }
 
+// sharedContextPID returns the PID of the first context that is not isolated.
+// A new PID is returned if there is no such context.
+func (d *Driver) sharedContextPID() vm.PID {
+	for _, c := range d.contexts {
+		if !c.isolated {
+			return c.pid
+		}
+	}
+
+	return vm.PID(atomic.AddUint64(&nextPID, 1))
+}
+
 // GetNumGPUs return the number of GPUs in the platform
 func (d *Driver) GetNumGPUs() int {
 	return len(d.GPUs)
 }
 
+// GetSimulatedTime returns the current time of the simulation. It can be
+// called from the host program while the engine is running.
+func (d *Driver) GetSimulatedTime() sim.VTimeInSec {
+	return d.Engine.CurrentTime()
+}
+
 // SelectGPU requires the driver to perform the following APIs on a selected
 // GPU
 func (d *Driver) SelectGPU(c *Context, gpuID int) {
@@ -59,6 +127,8 @@ func (d *Driver) SelectGPU(c *Context, gpuID int) {
 		log.Panicf("GPU %d is not available", gpuID)
 	}
 	c.currentGPUID = gpuID
+
+	d.recordContext("select_gpu", c, recordedCall{GPU: gpuID})
 }
 
 // CreateUnifiedGPU can create a virtual GPU that bundles multiple GPUs
@@ -81,6 +151,9 @@ func (d *Driver) CreateUnifiedGPU(c *Context, gpuIDs []int) int {
 	d.devices = append(d.devices, dev)
 	d.memAllocator.RegisterDevice(dev)
 
+	d.recordContext("create_unified_gpu", c,
+		recordedCall{GPU: dev.ID, GPUs: gpuIDs})
+
 	return dev.ID
 }
 
@@ -101,19 +174,56 @@ func (d *Driver) mustBeAllActualGPUs(gpuIDs []int) {
 
 // CreateCommandQueue creates a command queue in the driver
 func (d *Driver) CreateCommandQueue(c *Context) *CommandQueue {
+	c.queueMutex.Lock()
+	defer c.queueMutex.Unlock()
+
+	return d.createCommandQueue(c)
+}
+
+// createCommandQueue creates a command queue in the context. The caller must
+// hold the queue mutex of the context.
+func (d *Driver) createCommandQueue(c *Context) *CommandQueue {
 	q := new(CommandQueue)
 	q.GPUID = c.currentGPUID
 	q.Context = c
 
-	c.queueMutex.Lock()
 	c.queues = append(c.queues, q)
-	c.queueMutex.Unlock()
+
+	d.recordNewQueue(q)
 
 	return q
 }
 
+// GetLastKernelError returns the most recent fault, such as an illegal
+// instruction or an access to an unmapped address, that a kernel launched on
+// the command queue encounters. It returns nil if no kernel on the queue has
+// faulted. A faulting work-group stops executing, but the other work-groups of
+// the kernel still run to completion.
+//
+// The emulation CUs detect illegal instructions, accesses to unmapped
+// addresses, and the uncorrectable memory errors that an emulation platform
+// can inject. The timing CUs only detect the vector memory accesses to
+// unmapped addresses, which they do not send to the memory; the rest of the
+// work-group still runs. The timing CUs check the addresses only when the
+// platform enables fault detection. The uncorrectable memory errors that the DRAM
+// controllers of a timing platform inject are reported to all the kernels
+// running on the GPU. Other faults still stop a timing simulation.
+func (d *Driver) GetLastKernelError(queue *CommandQueue) error {
+	return queue.getLastKernelError()
+}
+
+// GetCommandQueueDepth returns the number of commands in the command queue
+// that are not completed, including the command that is being executed. Host
+// programs can stop enqueuing when the depth is large, so that they do not run
+// too far ahead of the GPU. It is safe to call while the driver runs.
+func (d *Driver) GetCommandQueueDepth(q *CommandQueue) int {
+	return q.NumCommand()
+}
+
 // DrainCommandQueue will return when there is no command to execute
 func (d *Driver) DrainCommandQueue(q *CommandQueue) {
+	d.recordQueue("drain", q, recordedCall{})
+
 	listener := q.Subscribe()
 	defer q.Unsubscribe(listener)
 
@@ -143,6 +253,9 @@ func (d *Driver) AllocateMemory(
 		l2Dirty: false,
 	})
 
+	d.recordContext("allocate", ctx,
+		recordedCall{Ptr: Ptr(ptr), Size: byteSize, GPU: ctx.currentGPUID})
+
 	// log.Printf("Allocate %d\n", ptr)
 	return Ptr(ptr)
 }
@@ -161,6 +274,9 @@ func (d *Driver) AllocateUnifiedMemory(
 		l2Dirty: false,
 	})
 
+	d.recordContext("allocate_unified", ctx,
+		recordedCall{Ptr: ptr, Size: byteSize})
+
 	return ptr
 }
 
@@ -168,6 +284,9 @@ func (d *Driver) AllocateUnifiedMemory(
 // another GPU
 func (d *Driver) Remap(ctx *Context, addr, size uint64, deviceID int) {
 	d.memAllocator.Remap(ctx.pid, addr, size, deviceID)
+
+	d.recordContext("remap", ctx,
+		recordedCall{Ptr: Ptr(addr), Size: size, GPU: deviceID})
 }
 
 // Distribute rearranges a consecutive virtual memory space and re-allocate the
@@ -179,6 +298,9 @@ func (d *Driver) Distribute(
 	byteSize uint64,
 	gpuIDs []int,
 ) []uint64 {
+	d.recordContext("distribute", ctx,
+		recordedCall{Ptr: addr, Size: byteSize, GPUs: gpuIDs})
+
 	if len(gpuIDs) == 1 {
 		return []uint64{byteSize}
 	}
@@ -201,21 +323,67 @@ func unique(in []int) []int {
 }
 
 // FreeMemory frees the memory pointed by ptr. The pointer must be allocated
-// with the function AllocateMemory earlier. Error will be returned if the ptr
-// provided is invalid.
+// with the function AllocateMemory earlier. All the pages of the buffer are
+// returned to the devices and removed from the page table. Error will be
+// returned if the ptr provided is invalid or already freed, or if a page of
+// the buffer is being migrated.
 func (d *Driver) FreeMemory(ctx *Context, ptr Ptr) error {
-	// log.Printf("Free %d\n", ptr)
-	d.memAllocator.Free(uint64(ptr))
+	buf := ctx.findBuffer(ptr)
+	if buf == nil {
+		return fmt.Errorf("0x%x is not the start of an allocated buffer",
+			uint64(ptr))
+	}
 
-	for i, buffer := range ctx.buffers {
-		if buffer.vAddr == ptr {
-			ctx.buffers[i].freed = true
-		}
+	if d.isBufferMigrating(ctx, buf) {
+		return fmt.Errorf("buffer at 0x%x is being migrated", uint64(ptr))
 	}
 
+	d.recordContext("free", ctx, recordedCall{Ptr: ptr})
+	d.untagScratchpad(ctx, ptr)
+	d.resetMemQoSClass(ctx, ptr, buf.size)
+	d.memAllocator.Free(ctx.pid, uint64(ptr), buf.size)
+	buf.freed = true
+
 	return nil
 }
 
+func (d *Driver) isBufferMigrating(ctx *Context, buf *buffer) bool {
+	end := uint64(buf.vAddr) + buf.size
+
+	for addr := uint64(buf.vAddr); addr < end; {
+		page, found := d.pageTable.Find(ctx.pid, addr)
+		if !found {
+			addr += 1 << d.Log2PageSize
+			continue
+		}
+
+		if page.IsMigrating {
+			return true
+		}
+
+		addr = page.VAddr + page.PageSize
+	}
+
+	return false
+}
+
+// GetAllocatedBytes returns the number of bytes that are allocated in the
+// context and not yet freed, including the unified memory and the scratchpads.
+// The buffers are counted with the sizes requested at allocation, no matter
+// which devices they are distributed to. It helps to find the buffers that are
+// not freed in long simulations.
+func (d *Driver) GetAllocatedBytes(ctx *Context) uint64 {
+	total := uint64(0)
+
+	for _, b := range ctx.buffers {
+		if !b.freed {
+			total += b.size
+		}
+	}
+
+	return total
+}
+
 // EnqueueMemCopyH2D registers a MemCopyH2DCommand in the queue.
 func (d *Driver) EnqueueMemCopyH2D(
 	queue *CommandQueue,
@@ -245,6 +413,28 @@ func (d *Driver) EnqueueMemCopyD2H(
 	d.Enqueue(queue, cmd)
 }
 
+// EnqueueFillPattern registers a command in the queue that fills size bytes
+// starting from ptr by repeating the pattern. If size is not a multiple of the
+// length of the pattern, the last copy of the pattern is truncated. The data is
+// written by the DMA engine, in the same way as EnqueueMemCopyH2D.
+func (d *Driver) EnqueueFillPattern(
+	queue *CommandQueue,
+	ptr Ptr,
+	pattern []byte,
+	size uint64,
+) {
+	if len(pattern) == 0 {
+		panic("the fill pattern is empty")
+	}
+
+	data := make([]byte, size)
+	for i := uint64(0); i < size; i += uint64(len(pattern)) {
+		copy(data[i:], pattern)
+	}
+
+	d.EnqueueMemCopyH2D(queue, ptr, data)
+}
+
 //go:embed memcopy.hsaco
 var kernelBytes []byte
 
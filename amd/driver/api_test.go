@@ -1,6 +1,8 @@
 package driver
 
 import (
+	"sync"
+
 	"github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"github.com/rs/xid"
@@ -8,6 +10,7 @@ import (
 	"github.com/sarchlab/akita/v4/mem/vm"
 	"github.com/sarchlab/akita/v4/sim"
 	"github.com/sarchlab/mgpusim/v4/amd/driver/internal"
+	"github.com/sarchlab/mgpusim/v4/amd/insts"
 )
 
 func enqueueNoopCommand(d *Driver, q *CommandQueue) {
@@ -66,6 +69,95 @@ var _ = ginkgo.Describe("Driver async API execution", func() {
 		Expect(q.commands).To(HaveLen(0))
 	})
 
+	ginkgo.It("should report the depth of a command queue", func() {
+		context := driver.Init()
+		q := driver.CreateCommandQueue(context)
+		Expect(driver.GetCommandQueueDepth(q)).To(Equal(0))
+
+		enqueueNoopCommand(driver, q)
+		enqueueNoopCommand(driver, q)
+		enqueueNoopCommand(driver, q)
+		Expect(driver.GetCommandQueueDepth(q)).To(Equal(3))
+
+		driver.DrainCommandQueue(q)
+
+		Expect(driver.GetCommandQueueDepth(q)).To(Equal(0))
+	})
+
+	ginkgo.It("should enqueue to the default queue", func() {
+		context := driver.Init()
+		q := driver.CreateCommandQueue(context)
+		context.SetDefaultQueue(q)
+
+		driver.EnqueueOnDefault(context, &NoopCommand{ID: xid.New().String()})
+		Expect(context.DefaultQueue()).To(BeIdenticalTo(q))
+		Expect(q.NumCommand()).To(Equal(1))
+
+		driver.DrainCommandQueue(q)
+
+		Expect(q.commands).To(HaveLen(0))
+	})
+
+	ginkgo.It("should create a default queue on the first use", func() {
+		context := driver.Init()
+		Expect(context.DefaultQueue()).To(BeNil())
+
+		driver.EnqueueOnDefault(context, &NoopCommand{ID: xid.New().String()})
+		q := context.DefaultQueue()
+		driver.EnqueueOnDefault(context, &NoopCommand{ID: xid.New().String()})
+
+		Expect(q).NotTo(BeNil())
+		Expect(context.DefaultQueue()).To(BeIdenticalTo(q))
+		Expect(context.queues).To(HaveLen(1))
+
+		driver.DrainCommandQueue(q)
+
+		Expect(q.commands).To(HaveLen(0))
+	})
+
+	ginkgo.It("should create one default queue for concurrent first uses",
+		func() {
+			context := driver.Init()
+
+			var wg sync.WaitGroup
+			for i := 0; i < 16; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					driver.EnqueueOnDefault(context,
+						&NoopCommand{ID: xid.New().String()})
+				}()
+			}
+			wg.Wait()
+
+			Expect(context.queues).To(HaveLen(1))
+			Expect(context.DefaultQueue().NumCommand()).To(Equal(16))
+
+			driver.DrainCommandQueue(context.DefaultQueue())
+		})
+
+	ginkgo.It("should not use a queue of another context as default", func() {
+		context := driver.Init()
+		otherContext := driver.Init()
+		q := driver.CreateCommandQueue(otherContext)
+
+		Expect(func() { context.SetDefaultQueue(q) }).To(Panic())
+	})
+
+	ginkgo.It("should reject work-groups larger than the launch bound", func() {
+		context := driver.Init()
+		q := driver.CreateCommandQueue(context)
+		co := &insts.HsaCo{HsaCoHeader: &insts.HsaCoHeader{}}
+
+		err := driver.EnqueueKernelWithLaunchBounds(q, co,
+			[3]uint32{1024, 1, 1}, [3]uint16{256, 1, 1}, nil,
+			LaunchBounds{MaxWorkGroupSize: 128})
+
+		Expect(err).To(MatchError(
+			"work-group size 256 exceeds the launch bound of 128 work-items"))
+		Expect(q.NumCommand()).To(Equal(0))
+	})
+
 	ginkgo.It("should allocate memory", func() {
 		context := driver.Init()
 
@@ -90,6 +182,211 @@ var _ = ginkgo.Describe("Driver async API execution", func() {
 		Expect(context.buffers[0].l2Dirty).To(BeFalse())
 	})
 
+	ginkgo.It("should tag the pages of scratchpad memory", func() {
+		context := driver.Init()
+
+		scratchpad := driver.AllocateScratchpad(context, 1, 8192)
+		regular := driver.AllocateMemory(context, 4096)
+
+		scratchpadPage, _ := pageTable.Find(context.pid, uint64(scratchpad))
+		regularPage, _ := pageTable.Find(context.pid, uint64(regular))
+		Expect(context.buffers[0].scratchpad).To(BeTrue())
+		Expect(driver.IsScratchpadAddress(scratchpadPage.PAddr + 64)).
+			To(BeTrue())
+		Expect(driver.IsScratchpadAddress(scratchpadPage.PAddr + 4096)).
+			To(BeTrue())
+		Expect(driver.IsScratchpadAddress(regularPage.PAddr)).To(BeFalse())
+
+		Expect(driver.FreeMemory(context, scratchpad)).To(Succeed())
+		Expect(driver.IsScratchpadAddress(scratchpadPage.PAddr)).To(BeFalse())
+	})
+
+	ginkgo.It("should set and reset the QoS classes of the pages", func() {
+		qosController := make(fakeMemQoSController)
+		driver.RegisterMemQoSController(1, qosController)
+		context := driver.Init()
+
+		ptr := driver.AllocateMemory(context, 2*4096)
+		page0, _ := pageTable.Find(context.pid, uint64(ptr))
+		page1, _ := pageTable.Find(context.pid, uint64(ptr)+4096)
+
+		driver.SetMemQoSClass(context, ptr, 2*4096, MemQoSLatencyCritical)
+		Expect(qosController).To(HaveLen(2))
+		Expect(qosController[page0.PAddr]).To(Equal(MemQoSLatencyCritical))
+		Expect(qosController[page1.PAddr]).To(Equal(MemQoSLatencyCritical))
+
+		Expect(driver.FreeMemory(context, ptr)).To(Succeed())
+		Expect(qosController).To(BeEmpty())
+		Expect(driver.memQoSPages).To(BeEmpty())
+	})
+
+	ginkgo.It("should route the constant memory to the constant caches",
+		func() {
+			router := &fakeConstantMemoryRouter{}
+			driver.RegisterConstantMemoryRouter(1, router)
+			context := driver.Init()
+
+			ptr := driver.AllocateConstantMemory(context, 4096+100)
+			page0, _ := pageTable.Find(context.pid, uint64(ptr))
+			page1, _ := pageTable.Find(context.pid, uint64(ptr)+4096)
+
+			Expect(router.ranges).To(Equal([][2]uint64{
+				{page0.PAddr, 4096},
+				{page1.PAddr, 100},
+			}))
+		})
+
+	ginkgo.It("should allocate normal memory without constant caches",
+		func() {
+			context := driver.Init()
+
+			ptr := driver.AllocateConstantMemory(context, 4096)
+
+			Expect(context.buffers).To(HaveLen(1))
+			Expect(context.buffers[0].vAddr).To(Equal(ptr))
+		})
+
+	ginkgo.It("should give isolated contexts separate address spaces", func() {
+		ctx1 := driver.CreateContextWithOptions(
+			ContextOptions{IsolatedAddressSpace: true})
+		ctx2 := driver.CreateContextWithOptions(
+			ContextOptions{IsolatedAddressSpace: true})
+
+		ptr1 := driver.AllocateMemory(ctx1, 4096)
+		ptr2 := driver.AllocateMemory(ctx2, 4096)
+
+		Expect(ptr1).To(Equal(ptr2))
+
+		page1, found1 := pageTable.Find(ctx1.pid, uint64(ptr1))
+		page2, found2 := pageTable.Find(ctx2.pid, uint64(ptr2))
+		Expect(found1).To(BeTrue())
+		Expect(found2).To(BeTrue())
+		Expect(page1.PAddr).NotTo(Equal(page2.PAddr))
+
+		Expect(driver.FreeMemory(ctx1, ptr1)).To(Succeed())
+		_, found1 = pageTable.Find(ctx1.pid, uint64(ptr1))
+		_, found2 = pageTable.Find(ctx2.pid, uint64(ptr2))
+		Expect(found1).To(BeFalse())
+		Expect(found2).To(BeTrue())
+	})
+
+	ginkgo.It("should free all the pages of a buffer", func() {
+		ctx := driver.Init()
+		ptr := driver.AllocateMemory(ctx, 3*4096)
+
+		Expect(driver.FreeMemory(ctx, ptr)).To(Succeed())
+
+		for i := uint64(0); i < 3; i++ {
+			_, found := pageTable.Find(ctx.pid, uint64(ptr)+i*4096)
+			Expect(found).To(BeFalse())
+		}
+	})
+
+	ginkgo.It("should return an error when freeing a buffer twice", func() {
+		ctx := driver.Init()
+		ptr := driver.AllocateMemory(ctx, 4096)
+
+		Expect(driver.FreeMemory(ctx, ptr)).To(Succeed())
+		Expect(driver.FreeMemory(ctx, ptr)).NotTo(Succeed())
+	})
+
+	ginkgo.It("should not free a buffer that is being migrated", func() {
+		ctx := driver.Init()
+		ptr := driver.AllocateMemory(ctx, 2*4096)
+
+		page, _ := pageTable.Find(ctx.pid, uint64(ptr)+4096)
+		page.IsMigrating = true
+		pageTable.Update(page)
+
+		Expect(driver.FreeMemory(ctx, ptr)).NotTo(Succeed())
+		_, found := pageTable.Find(ctx.pid, uint64(ptr))
+		Expect(found).To(BeTrue())
+	})
+
+	ginkgo.It("should track the allocated bytes of a context", func() {
+		ctx1 := driver.CreateContextWithOptions(ContextOptions{})
+		ctx2 := driver.CreateContextWithOptions(ContextOptions{})
+
+		ptr1 := driver.AllocateMemory(ctx1, 4096)
+		ptr2 := driver.AllocateMemory(ctx1, 100)
+		driver.AllocateMemory(ctx2, 8192)
+		Expect(driver.GetAllocatedBytes(ctx1)).To(Equal(uint64(4196)))
+		Expect(driver.GetAllocatedBytes(ctx2)).To(Equal(uint64(8192)))
+
+		Expect(driver.FreeMemory(ctx1, ptr1)).To(Succeed())
+		Expect(driver.GetAllocatedBytes(ctx1)).To(Equal(uint64(100)))
+
+		Expect(driver.FreeMemory(ctx1, ptr2)).To(Succeed())
+		Expect(driver.GetAllocatedBytes(ctx1)).To(BeZero())
+		Expect(driver.GetAllocatedBytes(ctx2)).To(Equal(uint64(8192)))
+	})
+
+	ginkgo.It("should share the address space of non-isolated contexts",
+		func() {
+			ctx1 := driver.CreateContextWithOptions(ContextOptions{})
+			ctx2 := driver.CreateContextWithOptions(ContextOptions{})
+
+			ptr1 := driver.AllocateMemory(ctx1, 4096)
+			ptr2 := driver.AllocateMemory(ctx2, 4096)
+
+			Expect(ctx1.pid).To(Equal(ctx2.pid))
+			Expect(ptr1).NotTo(Equal(ptr2))
+		})
+
+	ginkgo.It("should not consume a PID when creating an isolated context",
+		func() {
+			ctx1 := driver.CreateContextWithOptions(
+				ContextOptions{IsolatedAddressSpace: true})
+			ctx2 := driver.CreateContextWithOptions(
+				ContextOptions{IsolatedAddressSpace: true})
+
+			Expect(ctx2.pid).To(Equal(ctx1.pid + 1))
+		})
+
+	ginkgo.It("should share the address space of the Init context", func() {
+		isolated := driver.CreateContextWithOptions(
+			ContextOptions{IsolatedAddressSpace: true})
+		context := driver.Init()
+		ctx := driver.CreateContextWithOptions(ContextOptions{})
+
+		Expect(ctx.pid).To(Equal(context.pid))
+		Expect(ctx.pid).NotTo(Equal(isolated.pid))
+	})
+
+	ginkgo.It("should select the preferred device", func() {
+		ctx := driver.CreateContextWithOptions(ContextOptions{})
+		Expect(ctx.currentGPUID).To(Equal(1))
+
+		Expect(func() {
+			driver.CreateContextWithOptions(
+				ContextOptions{PreferredDevice: 2})
+		}).To(Panic())
+	})
+
+	ginkgo.It("should write the data of a host memory buffer", func() {
+		driver.hostMemoryEnabled = true
+		driver.globalStorage = mem.NewStorage(4 * mem.GB)
+		ctx := driver.Init()
+
+		ptr := driver.MapHostMemory(ctx, []byte{1, 2, 3, 4})
+
+		page, found := pageTable.Find(ctx.pid, uint64(ptr))
+		Expect(found).To(BeTrue())
+		Expect(page.PAddr).To(BeNumerically("<", 4*mem.GB))
+		data, err := driver.globalStorage.Read(page.PAddr, 4)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(data).To(Equal([]byte{1, 2, 3, 4}))
+	})
+
+	ginkgo.It("should not map host memory if the GPUs cannot reach it",
+		func() {
+			ctx := driver.Init()
+
+			Expect(func() {
+				driver.MapHostMemory(ctx, []byte{1, 2, 3, 4})
+			}).To(Panic())
+		})
+
 	// ginkgo.Measure("Memory allocation", func(b ginkgo.Benchmarker) {
 	// 	context := driver.Init()
 	// 	b.Time("runtime", func() {
@@ -97,3 +394,28 @@ var _ = ginkgo.Describe("Driver async API execution", func() {
 	// 	})
 	// }, 10)
 })
+
+// fakeMemQoSController records the QoS classes of the pages that are not of
+// the MemQoSBandwidth class.
+type fakeMemQoSController map[uint64]MemQoSClass
+
+func (c fakeMemQoSController) SetQoSClass(
+	pAddr, size uint64,
+	class MemQoSClass,
+) {
+	if class == MemQoSBandwidth {
+		delete(c, pAddr)
+		return
+	}
+
+	c[pAddr] = class
+}
+
+// fakeConstantMemoryRouter records the address ranges of the constant memory.
+type fakeConstantMemoryRouter struct {
+	ranges [][2]uint64
+}
+
+func (r *fakeConstantMemoryRouter) AddConstantRange(pAddr, size uint64) {
+	r.ranges = append(r.ranges, [2]uint64{pAddr, size})
+}
@@ -0,0 +1,52 @@
+package driver
+
+import (
+	"log"
+
+	"github.com/sarchlab/akita/v4/sim"
+)
+
+// AtomicStats summarizes the atomic read-modify-write accesses of a GPU.
+type AtomicStats struct {
+	// NumAtomics is the number of atomic accesses that the L1 vector caches
+	// receive.
+	NumAtomics uint64
+
+	// NumL2Atomics is the number of atomic accesses that the L2 caches
+	// receive, which are the caches that perform the operations.
+	NumL2Atomics uint64
+
+	// AverageLatency is the average time from an atomic access arriving at an
+	// L1 vector cache to the cache responding. It only counts the completed
+	// accesses.
+	AverageLatency sim.VTimeInSec
+}
+
+// An AtomicStatsReporter reports the atomic accesses of a GPU.
+type AtomicStatsReporter interface {
+	AtomicStats() AtomicStats
+}
+
+// RegisterAtomicStatsReporter sets the reporter that records the atomic
+// accesses of the given GPU.
+func (d *Driver) RegisterAtomicStatsReporter(
+	gpuID int,
+	reporter AtomicStatsReporter,
+) {
+	if d.atomicStatsReporters == nil {
+		d.atomicStatsReporters = make(map[int]AtomicStatsReporter)
+	}
+
+	d.atomicStatsReporters[gpuID] = reporter
+}
+
+// GetAtomicStats returns the statistics of the atomic accesses of the given
+// GPU, collected from the start of the simulation.
+func (d *Driver) GetAtomicStats(gpuID int) AtomicStats {
+	reporter, found := d.atomicStatsReporters[gpuID]
+	if !found {
+		log.Panicf("GPU %d does not report atomic accesses", gpuID)
+	}
+
+	return reporter.AtomicStats()
+}
@@ -9,21 +9,26 @@ import (
 
 // A Builder can build a driver.
 type Builder struct {
-	engine              sim.Engine
-	freq                sim.Freq
-	log2PageSize        uint64
-	pageTable           vm.PageTable
-	globalStorage       *mem.Storage
-	useMagicMemoryCopy  bool
-	middlewareD2HCycles int
-	middlewareH2DCycles int
+	engine               sim.Engine
+	freq                 sim.Freq
+	log2PageSize         uint64
+	log2HugePageSize     uint64
+	pageTable            vm.PageTable
+	globalStorage        *mem.Storage
+	useMagicMemoryCopy   bool
+	middlewareD2HCycles  int
+	middlewareH2DCycles  int
+	stagingBytesPerCycle int
+	wgDistribution       WGDistribution
+	hostMemory           bool
 }
 
 // MakeBuilder creates a driver builder with some default configuration
 // parameters.
 func MakeBuilder() Builder {
 	return Builder{
-		freq: 1 * sim.GHz,
+		freq:                 1 * sim.GHz,
+		stagingBytesPerCycle: 16,
 	}
 }
 
@@ -52,6 +57,14 @@ func (b Builder) WithLog2PageSize(log2PageSize uint64) Builder {
 	return b
 }
 
+// WithHugePageSize lets the allocations on the GPUs that are at least as large
+// as a huge page be backed by huge pages. The size is given as a power of 2.
+// The page table must be created by NewHugePageTable with the same size.
+func (b Builder) WithHugePageSize(log2HugePageSize uint64) Builder {
+	b.log2HugePageSize = log2HugePageSize
+	return b
+}
+
 // WithGlobalStorage sets the global storage that the driver uses.
 func (b Builder) WithGlobalStorage(storage *mem.Storage) Builder {
 	b.globalStorage = storage
@@ -74,6 +87,28 @@ func (b Builder) WithH2DCycles(h2dCycles int) Builder {
 	return b
 }
 
+// WithStagingBytesPerCycle sets the number of bytes that the driver copies
+// per cycle from pageable host memory to a pinned staging buffer before an
+// asynchronous copy from pageable memory starts.
+func (b Builder) WithStagingBytesPerCycle(n int) Builder {
+	b.stagingBytesPerCycle = n
+	return b
+}
+
+// WithWGDistribution sets how the work-groups of a kernel launched on a
+// unified GPU are distributed to the GPUs.
+func (b Builder) WithWGDistribution(dist WGDistribution) Builder {
+	b.wgDistribution = dist
+	return b
+}
+
+// WithHostMemory lets MapHostMemory map buffers into the host memory. It
+// should only be used if the GPUs of the platform can access the host memory.
+func (b Builder) WithHostMemory() Builder {
+	b.hostMemory = true
+	return b
+}
+
 // Build creates a driver.
 func (b Builder) Build(name string) *Driver {
 	driver := new(Driver)
@@ -83,6 +118,10 @@ func (b Builder) Build(name string) *Driver {
 	driver.Log2PageSize = b.log2PageSize
 
 	memAllocatorImpl := internal.NewMemoryAllocator(b.pageTable, b.log2PageSize)
+	if b.log2HugePageSize != 0 {
+		memAllocatorImpl = internal.NewMemoryAllocatorWithHugePages(
+			b.pageTable, b.log2PageSize, b.log2HugePageSize)
+	}
 	driver.memAllocator = memAllocatorImpl
 
 	distributorImpl := newDistributorImpl(memAllocatorImpl)
@@ -91,6 +130,8 @@ func (b Builder) Build(name string) *Driver {
 
 	driver.pageTable = b.pageTable
 	driver.globalStorage = b.globalStorage
+	driver.wgDistribution = b.wgDistribution
+	driver.hostMemoryEnabled = b.hostMemory
 
 	if b.useMagicMemoryCopy {
 		globalStorageMemoryCopyMiddleware := &globalStorageMemoryCopyMiddleware{
@@ -99,9 +140,10 @@ func (b Builder) Build(name string) *Driver {
 		driver.middlewares = append(driver.middlewares, globalStorageMemoryCopyMiddleware)
 	} else {
 		defaultMemoryCopyMiddleware := &defaultMemoryCopyMiddleware{
-			driver:       driver,
-			cyclesPerD2H: b.middlewareD2HCycles,
-			cyclesPerH2D: b.middlewareH2DCycles,
+			driver:               driver,
+			cyclesPerD2H:         b.middlewareD2HCycles,
+			cyclesPerH2D:         b.middlewareH2DCycles,
+			stagingBytesPerCycle: b.stagingBytesPerCycle,
 		}
 		driver.middlewares = append(driver.middlewares, defaultMemoryCopyMiddleware)
 	}
@@ -0,0 +1,53 @@
+package driver
+
+import (
+	"log"
+)
+
+// CoalescingStats summarizes how well the vector memory instructions of a GPU
+// coalesce into cache-line accesses.
+type CoalescingStats struct {
+	// NumVMemInsts is the number of vector memory instructions that the CUs
+	// have issued.
+	NumVMemInsts uint64
+
+	// NumTransactions is the number of cache-line accesses that the
+	// instructions have generated.
+	NumTransactions uint64
+
+	// AverageTransactions is the average number of cache-line accesses of
+	// each instruction. It is close to 1 if the work-items of a wavefront
+	// access consecutive addresses and close to the wavefront size if they
+	// access scattered addresses.
+	AverageTransactions float64
+}
+
+// A CoalescingStatsReporter reports the memory access coalescing of a GPU.
+type CoalescingStatsReporter interface {
+	CoalescingStats() CoalescingStats
+}
+
+// RegisterCoalescingStatsReporter sets the reporter that records the memory
+// access coalescing of the given GPU.
+func (d *Driver) RegisterCoalescingStatsReporter(
+	gpuID int,
+	reporter CoalescingStatsReporter,
+) {
+	if d.coalescingReporters == nil {
+		d.coalescingReporters = make(map[int]CoalescingStatsReporter)
+	}
+
+	d.coalescingReporters[gpuID] = reporter
+}
+
+// GetCoalescingStats returns the statistics of the memory access coalescing
+// of the vector memory instructions of the given GPU, collected from the start
+// of the simulation.
+func (d *Driver) GetCoalescingStats(gpuID int) CoalescingStats {
+	reporter, found := d.coalescingReporters[gpuID]
+	if !found {
+		log.Panicf("GPU %d does not report memory access coalescing", gpuID)
+	}
+
+	return reporter.CoalescingStats()
+}
@@ -0,0 +1,40 @@
+package driver
+
+import (
+	"github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// fakeCoalescingStatsReporter reports fixed coalescing statistics.
+type fakeCoalescingStatsReporter struct {
+	stats CoalescingStats
+}
+
+func (r fakeCoalescingStatsReporter) CoalescingStats() CoalescingStats {
+	return r.stats
+}
+
+var _ = ginkgo.Describe("Coalescing Stats", func() {
+	var driver *Driver
+
+	ginkgo.BeforeEach(func() {
+		driver = MakeBuilder().WithLog2PageSize(12).Build("Driver")
+	})
+
+	ginkgo.It("should report the coalescing stats of a GPU", func() {
+		stats := CoalescingStats{
+			NumVMemInsts:        2,
+			NumTransactions:     8,
+			AverageTransactions: 4,
+		}
+		driver.RegisterCoalescingStatsReporter(1,
+			fakeCoalescingStatsReporter{stats})
+
+		Expect(driver.GetCoalescingStats(1)).To(Equal(stats))
+	})
+
+	ginkgo.It("should panic if the GPU does not report coalescing stats",
+		func() {
+			Expect(func() { driver.GetCoalescingStats(1) }).To(Panic())
+		})
+})
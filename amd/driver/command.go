@@ -21,6 +21,19 @@ type MemCopyH2DCommand struct {
 	Dst  Ptr
 	Src  interface{}
 	Reqs []sim.Msg
+
+	// Pageable is true if the source is in pageable host memory. The driver
+	// has to copy the data to a pinned staging buffer before the DMA engine
+	// can read it.
+	Pageable bool
+
+	// ThroughCache is true if the data is written through the L2 caches
+	// rather than to the DRAM directly, so that the kernels that are running
+	// see the data.
+	ThroughCache bool
+
+	// OnComplete, if not nil, is called once when the copy completes.
+	OnComplete func()
 }
 
 // GetID returns the ID of the command
@@ -86,6 +99,16 @@ type LaunchKernelCommand struct {
 	Packet     *kernels.HsaKernelDispatchPacket
 	DPacket    Ptr
 	Reqs       []sim.Msg
+
+	// OnComplete, if not nil, is called once when the kernel completes. See
+	// EnqueueKernelWithCompletionCallback for the restrictions.
+	OnComplete func()
+
+	// LaunchBounds are the bounds that the launch has been validated against.
+	// See EnqueueKernelWithLaunchBounds.
+	LaunchBounds LaunchBounds
+
+	fault error
 }
 
 // GetID returns the ID of the command
@@ -109,6 +132,39 @@ func (c *LaunchKernelCommand) RemoveReq(req sim.Msg) {
 	c.Reqs = removeMsgFromMsgList(req, c.Reqs)
 }
 
+// A PersistentKernelCommand is a command that runs a kernel that loops until
+// the host sets a stop flag in the device memory. See EnqueuePersistentKernel
+// for the termination protocol.
+type PersistentKernelCommand struct {
+	ID         string
+	CodeObject *insts.HsaCo
+	Packet     *kernels.HsaKernelDispatchPacket
+	DPacket    Ptr
+	StopFlag   Ptr
+	Reqs       []sim.Msg
+}
+
+// GetID returns the ID of the command
+func (c *PersistentKernelCommand) GetID() string {
+	return c.ID
+}
+
+// GetReqs returns the request associated with the command
+func (c *PersistentKernelCommand) GetReqs() []sim.Msg {
+	return c.Reqs
+}
+
+// AddReq adds a request to the request list associated with the command
+func (c *PersistentKernelCommand) AddReq(req sim.Msg) {
+	c.Reqs = append(c.Reqs, req)
+}
+
+// RemoveReq removes a request from the request list associated with the
+// command.
+func (c *PersistentKernelCommand) RemoveReq(req sim.Msg) {
+	c.Reqs = removeMsgFromMsgList(req, c.Reqs)
+}
+
 // A FlushCommand is a command triggers the GPU cache to flush
 type FlushCommand struct {
 	ID   string
@@ -184,6 +240,18 @@ type LaunchUnifiedMultiGPUKernelCommand struct {
 	PacketArray  []*kernels.HsaKernelDispatchPacket
 	DPacketArray []Ptr
 	Reqs         []sim.Msg
+
+	// WGDistribution determines how the work-groups are assigned to the
+	// GPUs.
+	WGDistribution WGDistribution
+
+	// LaunchBounds are the bounds that the launch has been validated against.
+	// See EnqueueKernelWithLaunchBounds.
+	LaunchBounds LaunchBounds
+
+	// OnComplete, if not nil, is called once when the kernel completes on all
+	// the GPUs.
+	OnComplete func()
 }
 
 // GetID returns the ID of the command
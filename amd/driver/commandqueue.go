@@ -14,11 +14,19 @@ type CommandQueue struct {
 	PID       vm.PID
 	Context   *Context
 
+	// Priority decides the order in which the driver services the queues of
+	// a context. When multiple queues have commands ready, the queues with
+	// higher priorities start their commands first. The default priority is 0.
+	Priority int
+
 	commandsMutex sync.Mutex
 	commands      []Command
 
 	listenerMutex sync.Mutex
 	listeners     []*CommandQueueStatusListener
+
+	kernelErrorMutex sync.Mutex
+	lastKernelError  error
 }
 
 // Subscribe returns a CommandQueueStatusListener that listens to the update
@@ -100,13 +108,45 @@ func (q *CommandQueue) NumCommand() int {
 	return l
 }
 
+func (q *CommandQueue) setLastKernelError(err error) {
+	q.kernelErrorMutex.Lock()
+	q.lastKernelError = err
+	q.kernelErrorMutex.Unlock()
+}
+
+func (q *CommandQueue) getLastKernelError() error {
+	q.kernelErrorMutex.Lock()
+	defer q.kernelErrorMutex.Unlock()
+
+	return q.lastKernelError
+}
+
 // Enqueue adds a command to a command queue and triggers GPUs to start to
 // consume the command.
 func (d *Driver) Enqueue(q *CommandQueue, c Command) {
+	d.recordCommand(q, c)
 	q.Enqueue(c)
 	// d.enqueueSignal <- true
 }
 
+// EnqueueOnDefault adds a command to the default queue of the context. If the
+// context does not have a default queue, a new queue on the current GPU of the
+// context becomes the default queue.
+func (d *Driver) EnqueueOnDefault(ctx *Context, c Command) {
+	d.Enqueue(d.defaultQueueOf(ctx), c)
+}
+
+func (d *Driver) defaultQueueOf(ctx *Context) *CommandQueue {
+	ctx.queueMutex.Lock()
+	defer ctx.queueMutex.Unlock()
+
+	if ctx.defaultQueue == nil {
+		ctx.defaultQueue = d.createCommandQueue(ctx)
+	}
+
+	return ctx.defaultQueue
+}
+
 // A CommandQueueStatusListener can be notified when a queue updates its state
 type CommandQueueStatusListener struct {
 	closeSignal chan bool
@@ -0,0 +1,54 @@
+package driver
+
+// A ConstantMemoryRouter sends the accesses to a physical address range to the
+// constant caches of a GPU.
+type ConstantMemoryRouter interface {
+	AddConstantRange(pAddr, size uint64)
+}
+
+// RegisterConstantMemoryRouter sets the component that routes the accesses to
+// the constant memory of the given GPU to its constant caches.
+func (d *Driver) RegisterConstantMemoryRouter(
+	gpuID int,
+	r ConstantMemoryRouter,
+) {
+	if d.constantMemoryRouters == nil {
+		d.constantMemoryRouters = make(map[int]ConstantMemoryRouter)
+	}
+
+	d.constantMemoryRouters[gpuID] = r
+}
+
+// AllocateConstantMemory allocates a buffer that the kernels only read. On the
+// GPUs that have constant caches, the accesses to the buffer go through the
+// constant caches instead of the L1 vector caches. On the other GPUs, the
+// buffer is a normal buffer. The buffer should not be migrated, as the
+// accesses to its new pages are not routed to the constant caches.
+func (d *Driver) AllocateConstantMemory(ctx *Context, byteSize uint64) Ptr {
+	ptr := d.AllocateMemory(ctx, byteSize)
+	d.routeConstantMemory(ctx, ptr, byteSize)
+
+	return ptr
+}
+
+// routeConstantMemory sends the accesses to the buffer to the constant caches
+// of the GPUs that hold its pages.
+func (d *Driver) routeConstantMemory(ctx *Context, ptr Ptr, byteSize uint64) {
+	start := uint64(ptr)
+	end := start + byteSize
+
+	for _, page := range d.GetPhysicalPages(ctx.pid, start, byteSize) {
+		r, found := d.constantMemoryRouters[int(page.DeviceID)]
+		if !found {
+			continue
+		}
+
+		rangeStart := max(start, page.VAddr)
+		rangeEnd := min(end, page.VAddr+page.PageSize)
+		r.AddConstantRange(page.PAddr+rangeStart-page.VAddr,
+			rangeEnd-rangeStart)
+	}
+
+	d.recordContext("route_constant_memory", ctx,
+		recordedCall{Ptr: ptr, Size: byteSize})
+}
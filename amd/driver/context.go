@@ -15,19 +15,62 @@ type buffer struct {
 	// to this buffer. Therefore, copying from or to this buffer triggers L2
 	// flushing.
 	l2Dirty bool
+
+	// A scratchpad buffer is allocated with AllocateScratchpad.
+	scratchpad bool
 }
 
 // Context is an opaque struct that carries the information used by the driver.
 type Context struct {
 	pid           vm.PID
+	isolated      bool
 	currentGPUID  int
 	prevPageVAddr uint64
 	l2Dirty       bool
 
-	queueMutex sync.Mutex
-	queues     []*CommandQueue
+	queueMutex   sync.Mutex
+	queues       []*CommandQueue
+	defaultQueue *CommandQueue
 
 	buffers []*buffer
+
+	memAdvices []memAdviceRange
+}
+
+// PID returns the ID of the process that the context belongs to.
+func (c *Context) PID() vm.PID {
+	return c.pid
+}
+
+// SetDefaultQueue sets the queue that Driver.EnqueueOnDefault enqueues the
+// commands of the context to. The queue must belong to the context.
+func (c *Context) SetDefaultQueue(q *CommandQueue) {
+	if q.Context != c {
+		panic("the default queue must belong to the context")
+	}
+
+	c.queueMutex.Lock()
+	c.defaultQueue = q
+	c.queueMutex.Unlock()
+}
+
+// DefaultQueue returns the default queue of the context. It returns nil if the
+// context does not have a default queue yet.
+func (c *Context) DefaultQueue() *CommandQueue {
+	c.queueMutex.Lock()
+	defer c.queueMutex.Unlock()
+
+	return c.defaultQueue
+}
+
+func (c *Context) findBuffer(vAddr Ptr) *buffer {
+	for _, b := range c.buffers {
+		if b.vAddr == vAddr && !b.freed {
+			return b
+		}
+	}
+
+	return nil
 }
 
 func (c *Context) markAllBuffersDirty() {
@@ -0,0 +1,76 @@
+package driver
+
+import (
+	"sync"
+
+	"github.com/sarchlab/akita/v4/sim"
+)
+
+// A CopyEvent tracks the completion of an asynchronous memory copy.
+type CopyEvent struct {
+	lock         sync.Mutex
+	completed    bool
+	completeTime sim.VTimeInSec
+	done         chan bool
+}
+
+// IsComplete returns true if the copy has completed.
+func (e *CopyEvent) IsComplete() bool {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	return e.completed
+}
+
+// CompleteTime returns the time when the copy completes. It returns 0 if the
+// copy has not completed.
+func (e *CopyEvent) CompleteTime() sim.VTimeInSec {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	return e.completeTime
+}
+
+func (e *CopyEvent) complete(now sim.VTimeInSec) {
+	e.lock.Lock()
+	e.completed = true
+	e.completeTime = now
+	e.lock.Unlock()
+
+	close(e.done)
+}
+
+// EnqueueMemcpyH2DAsync enqueues a copy of the first size bytes of src to the
+// GPU memory at dst and returns an event that completes with the copy. A
+// pinned source is read by the DMA engine directly. A pageable source is first
+// copied to a pinned staging buffer, which delays the copy according to the
+// staging bandwidth of the driver.
+func (d *Driver) EnqueueMemcpyH2DAsync(
+	queue *CommandQueue,
+	dst Ptr,
+	src []byte,
+	size uint64,
+	pinned bool,
+) *CopyEvent {
+	event := &CopyEvent{done: make(chan bool)}
+
+	cmd := &MemCopyH2DCommand{
+		ID:       sim.GetIDGenerator().Generate(),
+		Dst:      dst,
+		Src:      src[:size],
+		Pageable: !pinned,
+		OnComplete: func() {
+			event.complete(d.Engine.CurrentTime())
+		},
+	}
+
+	d.Enqueue(queue, cmd)
+
+	return event
+}
+
+// WaitCopyEvent returns when the copy of the event completes.
+func (d *Driver) WaitCopyEvent(event *CopyEvent) {
+	d.enqueueSignal <- true
+	<-event.done
+}
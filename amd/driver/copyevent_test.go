@@ -0,0 +1,26 @@
+package driver
+
+import (
+	"github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = ginkgo.Describe("CopyEvent", func() {
+	ginkgo.It("should not be complete before the copy completes", func() {
+		event := &CopyEvent{done: make(chan bool)}
+
+		Expect(event.IsComplete()).To(BeFalse())
+		Expect(event.CompleteTime()).To(BeZero())
+		Expect(event.done).NotTo(BeClosed())
+	})
+
+	ginkgo.It("should record the time when the copy completes", func() {
+		event := &CopyEvent{done: make(chan bool)}
+
+		event.complete(12)
+
+		Expect(event.IsComplete()).To(BeTrue())
+		Expect(event.CompleteTime()).To(BeNumerically("==", 12))
+		Expect(event.done).To(BeClosed())
+	})
+})
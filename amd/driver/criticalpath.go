@@ -0,0 +1,68 @@
+package driver
+
+import (
+	"log"
+
+	"github.com/sarchlab/akita/v4/sim"
+)
+
+// A CriticalPathStep is a task on the critical path of a kernel.
+type CriticalPathStep struct {
+	Component string
+	Kind      string
+	What      string
+	StartTime sim.VTimeInSec
+	EndTime   sim.VTimeInSec
+
+	// SelfTime is the part of the task that is not covered by the next step
+	// on the path. The self time of all the steps adds up to the length of
+	// the path.
+	SelfTime sim.VTimeInSec
+}
+
+// A CriticalPath is the chain of tasks that determines when a kernel
+// completes. The first step is the kernel launch command and each following
+// step is the subtask of the previous step that completes the last.
+type CriticalPath struct {
+	Length sim.VTimeInSec
+	Steps  []CriticalPathStep
+}
+
+// TimeByComponent returns the self time of the steps on the critical path,
+// added up by the component that runs the steps.
+func (p CriticalPath) TimeByComponent() map[string]sim.VTimeInSec {
+	time := make(map[string]sim.VTimeInSec)
+	for _, step := range p.Steps {
+		time[step.Component] += step.SelfTime
+	}
+
+	return time
+}
+
+// A CriticalPathAnalyzer reconstructs the critical path of a task from the
+// task trace.
+type CriticalPathAnalyzer interface {
+	CriticalPath(taskID string) (path CriticalPath, found bool)
+}
+
+// RegisterCriticalPathAnalyzer sets the component that reconstructs the
+// critical paths of the kernels.
+func (d *Driver) RegisterCriticalPathAnalyzer(analyzer CriticalPathAnalyzer) {
+	d.criticalPathAnalyzer = analyzer
+}
+
+// GetKernelCriticalPath returns the critical path of a completed kernel
+// launch command, which tells which components and operations the completion
+// of the kernel waits for the longest.
+func (d *Driver) GetKernelCriticalPath(cmd *LaunchKernelCommand) CriticalPath {
+	if d.criticalPathAnalyzer == nil {
+		log.Panic("the platform does not analyze the critical paths")
+	}
+
+	path, found := d.criticalPathAnalyzer.CriticalPath(cmd.ID)
+	if !found {
+		log.Panicf("kernel %s has not completed", cmd.ID)
+	}
+
+	return path
+}
@@ -0,0 +1,67 @@
+package driver
+
+import (
+	"github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/sarchlab/akita/v4/sim"
+)
+
+// fakeCriticalPathAnalyzer reports fixed critical paths by the task IDs.
+type fakeCriticalPathAnalyzer struct {
+	paths map[string]CriticalPath
+}
+
+func (a fakeCriticalPathAnalyzer) CriticalPath(
+	taskID string,
+) (path CriticalPath, found bool) {
+	path, found = a.paths[taskID]
+	return path, found
+}
+
+var _ = ginkgo.Describe("Critical Path", func() {
+	var (
+		driver *Driver
+		cmd    *LaunchKernelCommand
+		path   CriticalPath
+	)
+
+	ginkgo.BeforeEach(func() {
+		driver = MakeBuilder().WithLog2PageSize(12).Build("Driver")
+		cmd = &LaunchKernelCommand{ID: "cmd"}
+		path = CriticalPath{
+			Length: 5,
+			Steps: []CriticalPathStep{
+				{Component: "Driver", SelfTime: 1},
+				{Component: "GPU.CU[0]", SelfTime: 3},
+				{Component: "Driver", SelfTime: 1},
+			},
+		}
+	})
+
+	ginkgo.It("should report the critical path of a completed kernel",
+		func() {
+			driver.RegisterCriticalPathAnalyzer(fakeCriticalPathAnalyzer{
+				paths: map[string]CriticalPath{"cmd": path},
+			})
+
+			Expect(driver.GetKernelCriticalPath(cmd)).To(Equal(path))
+		})
+
+	ginkgo.It("should panic if the kernel has not completed", func() {
+		driver.RegisterCriticalPathAnalyzer(fakeCriticalPathAnalyzer{})
+
+		Expect(func() { driver.GetKernelCriticalPath(cmd) }).To(Panic())
+	})
+
+	ginkgo.It("should panic if the platform does not analyze critical paths",
+		func() {
+			Expect(func() { driver.GetKernelCriticalPath(cmd) }).To(Panic())
+		})
+
+	ginkgo.It("should add up the self time by component", func() {
+		Expect(path.TimeByComponent()).To(Equal(map[string]sim.VTimeInSec{
+			"Driver":    2,
+			"GPU.CU[0]": 3,
+		}))
+	})
+})
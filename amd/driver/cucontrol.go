@@ -0,0 +1,46 @@
+package driver
+
+import (
+	"log"
+)
+
+// A CUController controls which CUs of a GPU can run work-groups.
+type CUController interface {
+	DisableCU(cuIndex int)
+	EnableCU(cuIndex int)
+}
+
+// RegisterCUController sets the component that controls the CUs of the given
+// GPU.
+func (d *Driver) RegisterCUController(gpuID int, c CUController) {
+	if d.cuControllers == nil {
+		d.cuControllers = make(map[int]CUController)
+	}
+
+	d.cuControllers[gpuID] = c
+}
+
+// DisableCU marks a CU of the given GPU as unavailable, modeling a defective
+// or power-gated unit. The Command Processor stops dispatching work-groups to
+// the CU and sends the remaining work-groups to the other CUs. The
+// work-groups that are already running on the CU run to completion.
+func (d *Driver) DisableCU(gpuID, cuIndex int) {
+	d.mustFindCUController(gpuID).DisableCU(cuIndex)
+	d.recordGPU("disable_cu", gpuID, recordedCall{CU: cuIndex})
+}
+
+// EnableCU lets the Command Processor dispatch work-groups to a CU that was
+// disabled by DisableCU again.
+func (d *Driver) EnableCU(gpuID, cuIndex int) {
+	d.mustFindCUController(gpuID).EnableCU(cuIndex)
+	d.recordGPU("enable_cu", gpuID, recordedCall{CU: cuIndex})
+}
+
+func (d *Driver) mustFindCUController(gpuID int) CUController {
+	c, found := d.cuControllers[gpuID]
+	if !found {
+		log.Panicf("GPU %d does not have a registered CU controller", gpuID)
+	}
+
+	return c
+}
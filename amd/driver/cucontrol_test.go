@@ -0,0 +1,44 @@
+package driver
+
+import (
+	"github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// fakeCUController records the CUs that are disabled.
+type fakeCUController struct {
+	disabled map[int]bool
+}
+
+func (c *fakeCUController) DisableCU(cuIndex int) {
+	c.disabled[cuIndex] = true
+}
+
+func (c *fakeCUController) EnableCU(cuIndex int) {
+	delete(c.disabled, cuIndex)
+}
+
+var _ = ginkgo.Describe("CU Control", func() {
+	var (
+		driver     *Driver
+		controller *fakeCUController
+	)
+
+	ginkgo.BeforeEach(func() {
+		driver = MakeBuilder().WithLog2PageSize(12).Build("Driver")
+		controller = &fakeCUController{disabled: make(map[int]bool)}
+		driver.RegisterCUController(1, controller)
+	})
+
+	ginkgo.It("should disable and enable the CUs of a GPU", func() {
+		driver.DisableCU(1, 3)
+		driver.DisableCU(1, 5)
+		driver.EnableCU(1, 3)
+
+		Expect(controller.disabled).To(Equal(map[int]bool{5: true}))
+	})
+
+	ginkgo.It("should panic if the GPU has no CU controller", func() {
+		Expect(func() { driver.DisableCU(2, 0) }).To(Panic())
+	})
+})
@@ -0,0 +1,47 @@
+package driver
+
+import (
+	"log"
+)
+
+// A DMAConcurrencyController controls how many memory copies the DMA engine of
+// a GPU can process at the same time.
+type DMAConcurrencyController interface {
+	SetMaxOutstanding(n int)
+	MaxOutstanding() int
+}
+
+// RegisterDMAEngine sets the DMA engine that serves the memory copies of the
+// given GPU.
+func (d *Driver) RegisterDMAEngine(
+	gpuID int,
+	dma DMAConcurrencyController,
+) {
+	if d.dmaEngines == nil {
+		d.dmaEngines = make(map[int]DMAConcurrencyController)
+	}
+
+	d.dmaEngines[gpuID] = dma
+}
+
+// SetDMAConcurrency sets the number of memory copies that the DMA engine of
+// the given GPU can process at the same time.
+func (d *Driver) SetDMAConcurrency(gpuID, n int) {
+	d.mustFindDMAEngine(gpuID).SetMaxOutstanding(n)
+	d.recordGPU("set_dma_concurrency", gpuID, recordedCall{Concurrency: n})
+}
+
+// GetDMAConcurrency returns the number of memory copies that the DMA engine of
+// the given GPU can process at the same time.
+func (d *Driver) GetDMAConcurrency(gpuID int) int {
+	return d.mustFindDMAEngine(gpuID).MaxOutstanding()
+}
+
+func (d *Driver) mustFindDMAEngine(gpuID int) DMAConcurrencyController {
+	dma, found := d.dmaEngines[gpuID]
+	if !found {
+		log.Panicf("GPU %d does not have a registered DMA engine", gpuID)
+	}
+
+	return dma
+}
@@ -0,0 +1,51 @@
+package driver
+
+import "log"
+
+// DRAMRowBufferStats counts how the accesses to a DRAM bank use the row
+// buffer.
+type DRAMRowBufferStats struct {
+	// Bank is the name of the bank.
+	Bank string
+
+	// Hits counts the accesses to the row that is already open.
+	Hits uint64
+
+	// Misses counts the accesses that find the bank precharged and have to
+	// activate the row.
+	Misses uint64
+
+	// Conflicts counts the accesses that find another row open and have to
+	// precharge the bank before activating the row.
+	Conflicts uint64
+}
+
+// A DRAMRowBufferStatsReporter reports the row buffer usage of the DRAM banks
+// of a GPU.
+type DRAMRowBufferStatsReporter interface {
+	DRAMRowBufferStats() []DRAMRowBufferStats
+}
+
+// RegisterDRAMRowBufferStatsReporter sets the reporter that reports the row
+// buffer usage of the DRAM banks of the given GPU.
+func (d *Driver) RegisterDRAMRowBufferStatsReporter(
+	gpuID int,
+	reporter DRAMRowBufferStatsReporter,
+) {
+	if d.dramStatsReporters == nil {
+		d.dramStatsReporters = make(map[int]DRAMRowBufferStatsReporter)
+	}
+
+	d.dramStatsReporters[gpuID] = reporter
+}
+
+// GetDRAMRowBufferStats returns the row buffer hits, misses, and conflicts of
+// each DRAM bank of the given GPU from the start of the simulation.
+func (d *Driver) GetDRAMRowBufferStats(gpuID int) []DRAMRowBufferStats {
+	reporter, found := d.dramStatsReporters[gpuID]
+	if !found {
+		log.Panicf("GPU %d does not report DRAM row buffer stats", gpuID)
+	}
+
+	return reporter.DRAMRowBufferStats()
+}
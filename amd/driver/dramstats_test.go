@@ -0,0 +1,39 @@
+package driver
+
+import (
+	"github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// fakeDRAMRowBufferStatsReporter reports fixed row buffer stats.
+type fakeDRAMRowBufferStatsReporter struct {
+	stats []DRAMRowBufferStats
+}
+
+func (r fakeDRAMRowBufferStatsReporter) DRAMRowBufferStats() []DRAMRowBufferStats {
+	return r.stats
+}
+
+var _ = ginkgo.Describe("DRAM Row Buffer Stats", func() {
+	var driver *Driver
+
+	ginkgo.BeforeEach(func() {
+		driver = MakeBuilder().WithLog2PageSize(12).Build("Driver")
+	})
+
+	ginkgo.It("should report the row buffer stats of a GPU", func() {
+		stats := []DRAMRowBufferStats{
+			{Bank: "Bank[0]", Hits: 3, Misses: 1},
+			{Bank: "Bank[1]", Conflicts: 2},
+		}
+		driver.RegisterDRAMRowBufferStatsReporter(1,
+			fakeDRAMRowBufferStatsReporter{stats})
+
+		Expect(driver.GetDRAMRowBufferStats(1)).To(Equal(stats))
+	})
+
+	ginkgo.It("should panic if the GPU does not report row buffer stats",
+		func() {
+			Expect(func() { driver.GetDRAMRowBufferStats(1) }).To(Panic())
+		})
+})
@@ -4,6 +4,7 @@ import (
 	"log"
 	"reflect"
 	"runtime/debug"
+	"sort"
 	"sync"
 
 	"github.com/rs/xid"
@@ -12,6 +13,7 @@ import (
 	"github.com/sarchlab/akita/v4/sim"
 	"github.com/sarchlab/akita/v4/tracing"
 	"github.com/sarchlab/mgpusim/v4/amd/driver/internal"
+	"github.com/sarchlab/mgpusim/v4/amd/insts"
 	"github.com/sarchlab/mgpusim/v4/amd/kernels"
 	"github.com/sarchlab/mgpusim/v4/amd/protocol"
 	"github.com/tebeka/atexit"
@@ -59,6 +61,49 @@ type Driver struct {
 	isCurrentlyMigratingOnePage     bool
 
 	RemotePMCPorts []sim.Port
+
+	wgDistribution    WGDistribution
+	hostMemoryEnabled bool
+
+	dmaEngines             map[int]DMAConcurrencyController
+	reuseDistanceAnalyzers map[int]ReuseDistanceAnalyzer
+	atomicStatsReporters   map[int]AtomicStatsReporter
+	rdmaStatsReporters     map[int]RDMAStatsReporter
+	dramStatsReporters     map[int]DRAMRowBufferStatsReporter
+	simdUtilReporters      map[int]SIMDUtilizationReporter
+	coalescingReporters    map[int]CoalescingStatsReporter
+	stallReporters         map[int]StallBreakdownReporter
+	energyReporters        map[int]EnergyReporter
+	thermalSensors         map[int]ThermalSensor
+	cuControllers          map[int]CUController
+	l2Partitioners         map[int]L2Partitioner
+	memQoSControllers      map[int]MemQoSController
+	constantMemoryRouters  map[int]ConstantMemoryRouter
+	criticalPathAnalyzer   CriticalPathAnalyzer
+
+	occupancyMutex     sync.RWMutex
+	occupancyReporters map[int]OccupancyReporter
+
+	memQoSMutex sync.Mutex
+	memQoSPages map[uint64]memQoSPage
+
+	memcpyBandwidthMutex sync.RWMutex
+	memcpyBandwidthCaps  map[int]float64
+
+	scratchpadMutex sync.RWMutex
+	scratchpadPages map[uint64]bool
+
+	recorder *recorder
+
+	controlReqMutex    sync.Mutex
+	pendingControlReqs []sim.Msg
+	controlReqWaiters  map[string]chan bool
+
+	pauseMutex sync.Mutex
+	paused     bool
+
+	kernelStartMutex sync.Mutex
+	kernelStartTimes map[Command]sim.VTimeInSec
 }
 
 // Run starts a new threads that handles all commands in the command queues
@@ -73,6 +118,35 @@ func (d *Driver) Terminate() {
 	d.logSimulationTerminate()
 }
 
+// Pause stops the engine from triggering more events, so that the state of
+// the simulation can be inspected. The event being handled completes before
+// Pause returns. The commands in flight do not progress until Continue is
+// called. Pause must not be called while handling an event.
+func (d *Driver) Pause() {
+	d.pauseMutex.Lock()
+	defer d.pauseMutex.Unlock()
+
+	if d.paused {
+		return
+	}
+
+	d.Engine.Pause()
+	d.paused = true
+}
+
+// Continue lets the engine of a paused simulation trigger events again.
+func (d *Driver) Continue() {
+	d.pauseMutex.Lock()
+	defer d.pauseMutex.Unlock()
+
+	if !d.paused {
+		return
+	}
+
+	d.Engine.Continue()
+	d.paused = false
+}
+
 func (d *Driver) logSimulationStart() {
 	d.simulationID = xid.New().String()
 	tracing.StartTask(
@@ -94,9 +168,7 @@ func (d *Driver) runAsync() {
 		case <-d.driverStopped:
 			return
 		case <-d.enqueueSignal:
-			d.Engine.Pause()
-			d.TickLater()
-			d.Engine.Continue()
+			d.tickLaterWhilePaused()
 
 			d.engineRunningMutex.Lock()
 			if d.engineRunning {
@@ -111,6 +183,22 @@ func (d *Driver) runAsync() {
 	}
 }
 
+// tickLaterWhilePaused schedules a tick while the engine does not trigger
+// events. It keeps the engine paused if the simulation is paused.
+func (d *Driver) tickLaterWhilePaused() {
+	d.pauseMutex.Lock()
+	defer d.pauseMutex.Unlock()
+
+	if d.paused {
+		d.TickLater()
+		return
+	}
+
+	d.Engine.Pause()
+	d.TickLater()
+	d.Engine.Continue()
+}
+
 func (d *Driver) runEngine() {
 	defer func() {
 		if r := recover(); r != nil {
@@ -164,6 +252,7 @@ func (d *Driver) RegisterGPU(
 func (d *Driver) Tick() bool {
 	madeProgress := false
 
+	madeProgress = d.sendPendingControlReqs() || madeProgress
 	madeProgress = d.sendToGPUs() || madeProgress
 	madeProgress = d.sendToMMU() || madeProgress
 	madeProgress = d.sendMigrationReqToCP() || madeProgress
@@ -220,6 +309,15 @@ func (d *Driver) processReturnReq() bool {
 	case *protocol.GPURestartRsp:
 		d.gpuPort.RetrieveIncoming()
 		return d.handleGPURestartRsp(req)
+	case *protocol.PreemptKernelRsp:
+		d.gpuPort.RetrieveIncoming()
+		return d.completeControlReq(req.RspTo)
+	case *protocol.ResumeKernelRsp:
+		d.gpuPort.RetrieveIncoming()
+		return d.completeControlReq(req.RspTo)
+	case *protocol.InvalidateTLBRsp:
+		d.gpuPort.RetrieveIncoming()
+		return d.completeControlReq(req.RspTo)
 	}
 
 	return false
@@ -242,7 +340,7 @@ func (d *Driver) processNewCommandFromContext(
 ) bool {
 	madeProgress := false
 	ctx.queueMutex.Lock()
-	for _, q := range ctx.queues {
+	for _, q := range queuesByPriority(ctx.queues) {
 		madeProgress = d.processNewCommandFromCmdQueue(q) || madeProgress
 	}
 	ctx.queueMutex.Unlock()
@@ -250,6 +348,18 @@ func (d *Driver) processNewCommandFromContext(
 	return madeProgress
 }
 
+// queuesByPriority returns the queues ordered from the highest priority to the
+// lowest. The queues with the same priority keep their creation order.
+func queuesByPriority(queues []*CommandQueue) []*CommandQueue {
+	sorted := make([]*CommandQueue, len(queues))
+	copy(sorted, queues)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Priority > sorted[j].Priority
+	})
+
+	return sorted
+}
+
 func (d *Driver) processNewCommandFromCmdQueue(
 	q *CommandQueue,
 ) bool {
@@ -272,13 +382,25 @@ func (d *Driver) processOneCommand(
 	switch cmd := cmd.(type) {
 	case *LaunchKernelCommand:
 		d.logCmdStart(cmd)
+		d.recordKernelStart(cmd)
 		return d.processLaunchKernelCommand(cmd, cmdQueue)
 	case *NoopCommand:
 		d.logCmdStart(cmd)
 		return d.processNoopCommand(cmd, cmdQueue)
+	case *TimelineEventCommand:
+		d.logCmdStart(cmd)
+		return d.processTimelineEventCommand(cmd, cmdQueue)
+	case *MemAdviseCommand:
+		d.logCmdStart(cmd)
+		return d.processMemAdviseCommand(cmd, cmdQueue)
 	case *LaunchUnifiedMultiGPUKernelCommand:
 		d.logCmdStart(cmd)
+		d.recordKernelStart(cmd)
 		return d.processUnifiedMultiGPULaunchKernelCommand(cmd, cmdQueue)
+	case *PersistentKernelCommand:
+		d.logCmdStart(cmd)
+		d.recordKernelStart(cmd)
+		return d.processPersistentKernelCommand(cmd, cmdQueue)
 	default:
 		return d.processCommandWithMiddleware(cmd, cmdQueue)
 	}
@@ -340,16 +462,34 @@ func (d *Driver) processLaunchKernelCommand(
 	cmd *LaunchKernelCommand,
 	queue *CommandQueue,
 ) bool {
+	d.launchKernel(cmd, cmd.CodeObject, cmd.Packet, cmd.DPacket, queue,
+		cmd.LaunchBounds)
+
+	return true
+}
+
+// launchKernel sends the request that launches the kernel of the command to
+// the GPU of the queue. The GPU reserves the resources of each work-group
+// according to the launch bounds.
+func (d *Driver) launchKernel(
+	cmd Command,
+	co *insts.HsaCo,
+	packet *kernels.HsaKernelDispatchPacket,
+	dPacket Ptr,
+	queue *CommandQueue,
+	bounds LaunchBounds,
+) {
 	req := protocol.NewLaunchKernelReq(d.gpuPort,
 		d.GPUs[queue.GPUID-1])
 	req.PID = queue.Context.pid
-	req.HsaCo = cmd.CodeObject
+	req.HsaCo = co
+	req.MaxWorkGroupSize = bounds.MaxWorkGroupSize
 
-	req.Packet = cmd.Packet
-	req.PacketAddress = uint64(cmd.DPacket)
+	req.Packet = packet
+	req.PacketAddress = uint64(dPacket)
 
 	queue.IsRunning = true
-	cmd.Reqs = append(cmd.Reqs, req)
+	cmd.AddReq(req)
 
 	d.requestsToSend = append(d.requestsToSend, req)
 
@@ -357,19 +497,17 @@ func (d *Driver) processLaunchKernelCommand(
 	queue.Context.markAllBuffersDirty()
 
 	d.logTaskToGPUInitiate(cmd, req)
-
-	return true
 }
 
 func (d *Driver) processUnifiedMultiGPULaunchKernelCommand(
 	cmd *LaunchUnifiedMultiGPUKernelCommand,
 	queue *CommandQueue,
 ) bool {
-	wgDist := d.distributeWGToGPUs(queue, cmd)
+	filters := d.createWGFilters(queue, cmd)
 
 	dev := d.devices[queue.GPUID]
 	for i, gpuID := range dev.UnifiedGPUIDs {
-		if wgDist[i+1]-wgDist[i] == 0 {
+		if filters[i] == nil {
 			continue
 		}
 
@@ -378,27 +516,8 @@ func (d *Driver) processUnifiedMultiGPULaunchKernelCommand(
 		req.HsaCo = cmd.CodeObject
 		req.Packet = cmd.PacketArray[i]
 		req.PacketAddress = uint64(cmd.DPacketArray[i])
-
-		currentGPUIndex := i
-		req.WGFilter = func(
-			pkt *kernels.HsaKernelDispatchPacket,
-			wg *kernels.WorkGroup,
-		) bool {
-			numWGX := (pkt.GridSizeX-1)/uint32(pkt.WorkgroupSizeX) + 1
-			numWGY := (pkt.GridSizeY-1)/uint32(pkt.WorkgroupSizeY) + 1
-
-			flattenedID :=
-				wg.IDZ*int(numWGX)*int(numWGY) +
-					wg.IDY*int(numWGX) +
-					wg.IDX
-
-			if flattenedID >= wgDist[currentGPUIndex] &&
-				flattenedID < wgDist[currentGPUIndex+1] {
-				return true
-			}
-
-			return false
-		}
+		req.WGFilter = filters[i]
+		req.MaxWorkGroupSize = cmd.LaunchBounds.MaxWorkGroupSize
 
 		queue.IsRunning = true
 		cmd.Reqs = append(cmd.Reqs, req)
@@ -428,10 +547,7 @@ func (d *Driver) distributeWGToGPUs(
 		totalCUCount += d.devices[devID].Properties.CUCount
 	}
 
-	numWGX := (cmd.PacketArray[0].GridSizeX-1)/uint32(cmd.PacketArray[0].WorkgroupSizeX) + 1
-	numWGY := (cmd.PacketArray[0].GridSizeY-1)/uint32(cmd.PacketArray[0].WorkgroupSizeY) + 1
-	numWGZ := (cmd.PacketArray[0].GridSizeZ-1)/uint32(cmd.PacketArray[0].WorkgroupSizeZ) + 1
-	totalWGCount := int(numWGX * numWGY * numWGZ)
+	totalWGCount := numWGs(cmd.PacketArray[0])
 	wgPerCU := (totalWGCount-1)/totalCUCount + 1
 
 	for i, devID := range actualGPUs {
@@ -456,16 +572,44 @@ func (d *Driver) processLaunchKernelReturn(
 
 	d.logTaskToGPUClear(req)
 
+	if rsp.Fault != nil {
+		cmdQueue.setLastKernelError(rsp.Fault)
+
+		if launch, ok := cmd.(*LaunchKernelCommand); ok {
+			launch.fault = rsp.Fault
+		}
+	}
+
 	if len(cmd.GetReqs()) == 0 {
 		cmdQueue.IsRunning = false
+
 		cmdQueue.Dequeue()
 
 		d.logCmdComplete(cmd)
+		d.recordKernelEnd(cmd)
+		invokeOnComplete(cmd)
 	}
 
 	return true
 }
 
+// invokeOnComplete calls the completion callback of a kernel launch command.
+// The callback runs on the engine goroutine while no driver lock is held.
+func invokeOnComplete(cmd Command) {
+	var onComplete func()
+
+	switch cmd := cmd.(type) {
+	case *LaunchKernelCommand:
+		onComplete = cmd.OnComplete
+	case *LaunchUnifiedMultiGPUKernelCommand:
+		onComplete = cmd.OnComplete
+	}
+
+	if onComplete != nil {
+		onComplete()
+	}
+}
+
 func (d *Driver) findCommandByReq(req sim.Msg) (Command, *CommandQueue) {
 	d.contextMutex.Lock()
 	defer d.contextMutex.Unlock()
@@ -625,11 +769,14 @@ func (d *Driver) processShootdownCompleteRsp(
 		for gpuID, vAddrs := range pageVaddrs {
 			for i := 0; i < len(vAddrs); i++ {
 				vAddr := vAddrs[i]
-				page, oldPAddr :=
+				page, oldPAddr, migrating :=
 					d.preparePageForMigration(vAddr, context, gpuID)
+				if !migrating {
+					continue
+				}
 
 				req := protocol.NewPageMigrationReqToCP(d.gpuPort,
-					d.GPUs[gpuID])
+					d.GPUs[page.DeviceID-1])
 				req.DestinationPMCPort = toRequestFromPMCPort
 				req.ToReadFromPhysicalAddress = oldPAddr
 				req.ToWriteToPhysicalAddress = page.PAddr
@@ -639,6 +786,13 @@ func (d *Driver) processShootdownCompleteRsp(
 				d.numPagesMigratingACK++
 			}
 		}
+
+		// The advice may keep all the pages where they are.
+		if d.numPagesMigratingACK == 0 {
+			d.prepareGPURestartReqs()
+			d.preparePageMigrationRspToMMU()
+		}
+
 		return true
 	}
 
@@ -672,25 +826,34 @@ func (d *Driver) findContext(pid vm.PID) *Context {
 	return context
 }
 
+// preparePageForMigration allocates the page on the GPU that the page is
+// migrated to when the GPU with the given index accesses it. The page is not
+// migrated if the advice of the page keeps it where it is.
 func (d *Driver) preparePageForMigration(
 	vAddr uint64,
 	context *Context,
 	gpuID uint64,
-) (*vm.Page, uint64) {
+) (newPage *vm.Page, oldPAddr uint64, migrating bool) {
 	page, found := d.pageTable.Find(context.pid, vAddr)
 	if !found {
 		panic("page not founds")
 	}
-	oldPAddr := page.PAddr
+	oldPAddr = page.PAddr
+
+	targetGPU := migrationTarget(context, page, int(gpuID+1))
+	if uint64(targetGPU) == page.DeviceID {
+		return &page, oldPAddr, false
+	}
 
-	newPage := d.memAllocator.AllocatePageWithGivenVAddr(
-		context.pid, int(gpuID+1), vAddr, true)
-	newPage.DeviceID = gpuID + 1
+	p := d.memAllocator.AllocatePageWithGivenVAddr(
+		context.pid, targetGPU, vAddr, true)
+	p.DeviceID = uint64(targetGPU)
 
-	newPage.IsMigrating = true
-	d.pageTable.Update(newPage)
+	p.IsMigrating = true
+	d.pageTable.Update(p)
+	d.moveMemQoSClass(oldPAddr, p)
 
-	return &newPage, oldPAddr
+	return &p, oldPAddr, true
 }
 
 func (d *Driver) sendMigrationReqToCP() bool {
@@ -7,6 +7,8 @@ import (
 	"github.com/sarchlab/akita/v4/mem/mem"
 	"github.com/sarchlab/akita/v4/mem/vm"
 	"github.com/sarchlab/akita/v4/sim"
+	"github.com/sarchlab/mgpusim/v4/amd/insts"
+	"github.com/sarchlab/mgpusim/v4/amd/kernels"
 	"github.com/sarchlab/mgpusim/v4/amd/protocol"
 )
 
@@ -76,6 +78,12 @@ var _ = ginkgo.Describe("Driver", func() {
 		mockCtrl.Finish()
 	})
 
+	ginkgo.It("should report the engine time as the simulated time", func() {
+		engine.EXPECT().CurrentTime().Return(sim.VTimeInSec(11))
+
+		Expect(driver.GetSimulatedTime()).To(Equal(sim.VTimeInSec(11)))
+	})
+
 	ginkgo.Context("process MemCopyH2D command", func() {
 		ginkgo.It("should send request", func() {
 			srcData := make([]byte, 0x2200)
@@ -232,6 +240,40 @@ var _ = ginkgo.Describe("Driver", func() {
 			Expect(cmdQueue.NumCommand()).To(Equal(0))
 		})
 
+		ginkgo.It("should call OnComplete when the copy completes", func() {
+			nilPort := NewMockPort(mockCtrl)
+			nilPort.EXPECT().AsRemote().AnyTimes()
+
+			completed := 0
+			req := protocol.NewMemCopyH2DReq(toGPUs, nilPort,
+				make([]byte, 4), 0x100)
+			cmd := &MemCopyH2DCommand{
+				Dst:        Ptr(0x100),
+				Src:        uint32(1),
+				Reqs:       []sim.Msg{req},
+				OnComplete: func() { completed++ },
+			}
+			cmdQueue.Enqueue(cmd)
+			cmdQueue.IsRunning = true
+
+			rsp := sim.GeneralRspBuilder{}.WithOriginalReq(req).Build()
+			toGPUs.EXPECT().PeekIncoming().Return(rsp)
+			toGPUs.EXPECT().PeekIncoming().Return(nil)
+			toGPUs.EXPECT().
+				RetrieveIncoming().
+				Return(req)
+
+			toMMU.EXPECT().RetrieveIncoming().Return(nil)
+
+			engine.EXPECT().Schedule(
+				gomock.AssignableToTypeOf(sim.TickEvent{}))
+
+			engine.EXPECT().CurrentTime().Return(sim.VTimeInSec(11))
+
+			driver.Handle(sim.MakeTickEvent(nil, 11))
+
+			Expect(completed).To(Equal(1))
+		})
 	})
 
 	ginkgo.Context("process MemCopyD2HCommand", func() {
@@ -380,6 +422,7 @@ var _ = ginkgo.Describe("Driver", func() {
 				gomock.AssignableToTypeOf(sim.TickEvent{}))
 
 			engine.EXPECT().CurrentTime().Return(sim.VTimeInSec(11))
+			engine.EXPECT().CurrentTime().Return(sim.VTimeInSec(11))
 
 			driver.Handle(sim.MakeTickEvent(nil, 11))
 
@@ -388,7 +431,442 @@ var _ = ginkgo.Describe("Driver", func() {
 			req := cmd.Reqs[0].(*protocol.LaunchKernelReq)
 			Expect(req.PID).To(Equal(vm.PID(1)))
 			Expect(driver.requestsToSend).To(HaveLen(1))
+			Expect(driver.GetInFlightKernels()).To(Equal([]KernelInfo{{
+				GridSize:  [3]uint32{256, 1, 1},
+				WGSize:    [3]uint16{64, 1, 1},
+				GPUID:     cmdQueue.GPUID,
+				Queue:     cmdQueue,
+				StartTime: 11,
+			}}))
+		})
+
+		ginkgo.It("should pass the launch bounds to the GPU", func() {
+			cmd := &LaunchKernelCommand{
+				GridSize:     [3]uint32{256, 1, 1},
+				WGSize:       [3]uint16{64, 1, 1},
+				LaunchBounds: LaunchBounds{MaxWorkGroupSize: 256},
+			}
+			cmdQueue.Enqueue(cmd)
+
+			toGPUs.EXPECT().PeekIncoming().Return(nil).AnyTimes()
+			toMMU.EXPECT().RetrieveIncoming().Return(nil)
+			engine.EXPECT().Schedule(
+				gomock.AssignableToTypeOf(sim.TickEvent{}))
+			engine.EXPECT().CurrentTime().
+				Return(sim.VTimeInSec(11)).Times(2)
+
+			driver.Handle(sim.MakeTickEvent(nil, 11))
+
+			Expect(cmd.Reqs).To(HaveLen(1))
+			req := cmd.Reqs[0].(*protocol.LaunchKernelReq)
+			Expect(req.MaxWorkGroupSize).To(Equal(256))
+		})
+	})
+
+	ginkgo.Context("process TimelineEventCommand", func() {
+		ginkgo.It("should not fire the event before the queue reaches it",
+			func() {
+				event := driver.EnqueueEvent(cmdQueue)
+
+				Expect(event.IsComplete()).To(BeFalse())
+			})
+
+		ginkgo.It("should fire the event when the queue reaches it", func() {
+			event := driver.EnqueueEvent(cmdQueue)
+
+			toGPUs.EXPECT().PeekIncoming().Return(nil).AnyTimes()
+			toMMU.EXPECT().RetrieveIncoming().Return(nil)
+			engine.EXPECT().Schedule(
+				gomock.AssignableToTypeOf(sim.TickEvent{}))
+			engine.EXPECT().CurrentTime().Return(sim.VTimeInSec(11)).AnyTimes()
+
+			driver.Handle(sim.MakeTickEvent(nil, 11))
+
+			Expect(event.IsComplete()).To(BeTrue())
+			Expect(event.CompletionTime()).To(Equal(sim.VTimeInSec(11)))
+			Expect(cmdQueue.NumCommand()).To(Equal(0))
+		})
+	})
+
+	ginkgo.Context("process MemAdviseCommand", func() {
+		ginkgo.It("should set the advice when the queue reaches it", func() {
+			advice := MemAdvice{PreferredLocation: 2}
+			driver.EnqueueMemAdvise(cmdQueue, Ptr(0x1000), 0x2000, advice)
+
+			Expect(context.memAdvices).To(BeEmpty())
+
+			toGPUs.EXPECT().PeekIncoming().Return(nil).AnyTimes()
+			toMMU.EXPECT().RetrieveIncoming().Return(nil)
+			engine.EXPECT().Schedule(
+				gomock.AssignableToTypeOf(sim.TickEvent{}))
+			engine.EXPECT().CurrentTime().Return(sim.VTimeInSec(11)).AnyTimes()
+
+			driver.Handle(sim.MakeTickEvent(nil, 11))
+
+			Expect(context.memAdvices).To(Equal([]memAdviceRange{
+				{vAddr: 0x1000, size: 0x2000, advice: advice},
+			}))
+			Expect(cmdQueue.NumCommand()).To(Equal(0))
+		})
+
+		ginkgo.It("should panic if the preferred location does not exist",
+			func() {
+				Expect(func() {
+					driver.EnqueueMemAdvise(cmdQueue, Ptr(0x1000), 0x1000,
+						MemAdvice{PreferredLocation: 3})
+				}).To(Panic())
+			})
+
+		ginkgo.It("should not migrate a page that the advice keeps", func() {
+			context.memAdvices = append(context.memAdvices, memAdviceRange{
+				vAddr:  0x1000,
+				size:   0x1000,
+				advice: MemAdvice{PreferredLocation: 1},
+			})
+			pageTable.EXPECT().
+				Find(vm.PID(1), uint64(0x1000)).
+				Return(vm.Page{
+					PID:      1,
+					VAddr:    0x1000,
+					PAddr:    0x100001000,
+					PageSize: 0x1000,
+					Valid:    true,
+					DeviceID: 1,
+					Unified:  true,
+				}, true)
+
+			page, oldPAddr, migrating :=
+				driver.preparePageForMigration(0x1000, context, 1)
+
+			Expect(migrating).To(BeFalse())
+			Expect(oldPAddr).To(Equal(uint64(0x100001000)))
+			Expect(page.DeviceID).To(Equal(uint64(1)))
+		})
+	})
+
+	ginkgo.Context("enqueue a kernel repeatedly", func() {
+		ginkgo.It("should enqueue a launch for each iteration", func() {
+			cmd := &LaunchKernelCommand{
+				ID:      "kernel",
+				Packet:  &kernels.HsaKernelDispatchPacket{},
+				DPacket: 0x1000,
+			}
+
+			driver.EnqueueKernelRepeated(cmdQueue, cmd, 3)
+
+			Expect(cmdQueue.commands).To(HaveLen(3))
+			ids := make(map[string]bool)
+			for _, c := range cmdQueue.commands {
+				launch := c.(*LaunchKernelCommand)
+				Expect(launch).NotTo(BeIdenticalTo(cmd))
+				Expect(launch.Packet).To(BeIdenticalTo(cmd.Packet))
+				Expect(launch.DPacket).To(Equal(cmd.DPacket))
+				ids[launch.ID] = true
+			}
+			Expect(ids).To(HaveLen(3))
+			Expect(ids).NotTo(HaveKey("kernel"))
+		})
+
+		ginkgo.It("should keep the launch bounds of each iteration", func() {
+			cmd := &LaunchKernelCommand{
+				ID:           "kernel",
+				LaunchBounds: LaunchBounds{MaxWorkGroupSize: 128},
+			}
+
+			driver.EnqueueKernelRepeated(cmdQueue, cmd, 2)
+
+			Expect(cmdQueue.commands).To(HaveLen(2))
+			for _, c := range cmdQueue.commands {
+				Expect(c.(*LaunchKernelCommand).LaunchBounds).
+					To(Equal(cmd.LaunchBounds))
+			}
+		})
+
+		ginkgo.It("should call OnComplete after each iteration", func() {
+			numCompleted := 0
+			cmd := &LaunchKernelCommand{
+				ID:         "kernel",
+				OnComplete: func() { numCompleted++ },
+			}
+
+			driver.EnqueueKernelRepeated(cmdQueue, cmd, 2)
+			for _, c := range cmdQueue.commands {
+				c.(*LaunchKernelCommand).OnComplete()
+			}
+
+			Expect(numCompleted).To(Equal(2))
+		})
+
+		ginkgo.It("should panic if the count is negative", func() {
+			cmd := &LaunchKernelCommand{ID: "kernel"}
+
+			Expect(func() {
+				driver.EnqueueKernelRepeated(cmdQueue, cmd, -1)
+			}).To(Panic())
+		})
+	})
+
+	ginkgo.Context("enqueue a kernel with launch bounds", func() {
+		ginkgo.It("should reject kernels that cannot fit the min "+
+			"work-groups per CU", func() {
+			reporter := &fakeOccupancyReporter{maxWG: 2}
+			driver.RegisterOccupancyReporter(1, reporter)
+			co := &insts.HsaCo{HsaCoHeader: &insts.HsaCoHeader{}}
+
+			err := driver.EnqueueKernelWithLaunchBounds(cmdQueue, co,
+				[3]uint32{1024, 1, 1}, [3]uint16{64, 1, 1}, nil,
+				LaunchBounds{MaxWorkGroupSize: 128, MinWGPerCU: 4})
+
+			Expect(err).To(MatchError(
+				"only 2 work-groups of 128 work-items fit in a CU of GPU 1, " +
+					"fewer than the launch bound of 4 work-groups per CU"))
+			Expect(reporter.co).To(BeIdenticalTo(co))
+			Expect(reporter.numWI).To(Equal(128))
+			Expect(cmdQueue.NumCommand()).To(Equal(0))
+		})
+
+		ginkgo.It("should check the min work-groups per CU on every GPU of "+
+			"a unified GPU", func() {
+			driver.RegisterOccupancyReporter(1,
+				&fakeOccupancyReporter{maxWG: 4})
+			driver.RegisterOccupancyReporter(2,
+				&fakeOccupancyReporter{maxWG: 3})
+			cmdQueue.GPUID = driver.CreateUnifiedGPU(context, []int{1, 2})
+			co := &insts.HsaCo{HsaCoHeader: &insts.HsaCoHeader{}}
+
+			err := driver.EnqueueKernelWithLaunchBounds(cmdQueue, co,
+				[3]uint32{1024, 1, 1}, [3]uint16{64, 1, 1}, nil,
+				LaunchBounds{MinWGPerCU: 4})
+
+			Expect(err).To(MatchError(
+				"only 3 work-groups of 64 work-items fit in a CU of GPU 2, " +
+					"fewer than the launch bound of 4 work-groups per CU"))
+			Expect(cmdQueue.NumCommand()).To(Equal(0))
+		})
+	})
+
+	ginkgo.Context("process commands of queues with priorities", func() {
+		ginkgo.It("should launch the kernel of the high-priority queue first",
+			func() {
+				lowPriorityCmd := &LaunchKernelCommand{
+					GridSize: [3]uint32{256, 1, 1},
+					WGSize:   [3]uint16{64, 1, 1},
+				}
+				cmdQueue.Enqueue(lowPriorityCmd)
+
+				highPriorityQueue := driver.CreateCommandQueue(context)
+				highPriorityQueue.Priority = 1
+				highPriorityCmd := &LaunchKernelCommand{
+					GridSize: [3]uint32{256, 1, 1},
+					WGSize:   [3]uint16{64, 1, 1},
+				}
+				highPriorityQueue.Enqueue(highPriorityCmd)
+
+				toGPUs.EXPECT().PeekIncoming().Return(nil).AnyTimes()
+				toMMU.EXPECT().RetrieveIncoming().Return(nil)
+				engine.EXPECT().Schedule(
+					gomock.AssignableToTypeOf(sim.TickEvent{}))
+				engine.EXPECT().CurrentTime().
+					Return(sim.VTimeInSec(11)).Times(3)
+
+				driver.Handle(sim.MakeTickEvent(nil, 11))
+
+				Expect(driver.requestsToSend).To(HaveLen(2))
+				Expect(driver.requestsToSend[0]).
+					To(BeIdenticalTo(highPriorityCmd.Reqs[0]))
+				Expect(driver.requestsToSend[1]).
+					To(BeIdenticalTo(lowPriorityCmd.Reqs[0]))
+			})
+	})
+
+	ginkgo.Context("pause and continue", func() {
+		ginkgo.It("should pause the engine once", func() {
+			engine.EXPECT().Pause().Times(1)
+
+			driver.Pause()
+			driver.Pause()
+		})
+
+		ginkgo.It("should continue a paused engine", func() {
+			engine.EXPECT().Pause()
+			engine.EXPECT().Continue().Times(1)
+
+			driver.Pause()
+			driver.Continue()
+			driver.Continue()
+		})
+
+		ginkgo.It("should not continue an engine that is not paused", func() {
+			driver.Continue()
+		})
+
+		ginkgo.It("should tick without continuing a paused engine", func() {
+			engine.EXPECT().Pause()
+			engine.EXPECT().CurrentTime().Return(sim.VTimeInSec(10)).AnyTimes()
+			engine.EXPECT().Schedule(gomock.AssignableToTypeOf(sim.TickEvent{}))
+
+			driver.Pause()
+			driver.tickLaterWhilePaused()
+		})
+	})
+
+	ginkgo.Context("process LaunchUnifiedMultiGPUKernelCommand", func() {
+		var (
+			cmd *LaunchUnifiedMultiGPUKernelCommand
+		)
+
+		ginkgo.BeforeEach(func() {
+			cmdQueue.GPUID = driver.CreateUnifiedGPU(context, []int{1, 2})
+
+			cmd = &LaunchUnifiedMultiGPUKernelCommand{}
+			for i := 0; i < 2; i++ {
+				packet := &kernels.HsaKernelDispatchPacket{
+					GridSizeX:      320,
+					GridSizeY:      3,
+					GridSizeZ:      2,
+					WorkgroupSizeX: 64,
+					WorkgroupSizeY: 1,
+					WorkgroupSizeZ: 1,
+				}
+				cmd.PacketArray = append(cmd.PacketArray, packet)
+				cmd.DPacketArray = append(cmd.DPacketArray, Ptr(0))
+			}
 		})
+
+		dispatchCount := func() map[int]int {
+			count := make(map[int]int)
+			for _, msg := range cmd.Reqs {
+				req := msg.(*protocol.LaunchKernelReq)
+				for z := 0; z < 2; z++ {
+					for y := 0; y < 3; y++ {
+						for x := 0; x < 5; x++ {
+							wg := &kernels.WorkGroup{IDX: x, IDY: y, IDZ: z}
+							if req.WGFilter(req.Packet, wg) {
+								count[flattenedWGID(req.Packet, wg)]++
+							}
+						}
+					}
+				}
+			}
+
+			return count
+		}
+
+		ginkgo.It("should dispatch each WG once with block distribution",
+			func() {
+				cmd.WGDistribution = WGDistributionBlock
+
+				driver.processUnifiedMultiGPULaunchKernelCommand(cmd, cmdQueue)
+
+				Expect(cmd.Reqs).To(HaveLen(2))
+				count := dispatchCount()
+				Expect(count).To(HaveLen(30))
+				for _, c := range count {
+					Expect(c).To(Equal(1))
+				}
+
+				req := cmd.Reqs[0].(*protocol.LaunchKernelReq)
+				Expect(req.WGFilter(req.Packet,
+					&kernels.WorkGroup{IDX: 0, IDY: 0, IDZ: 0})).To(BeTrue())
+				Expect(req.WGFilter(req.Packet,
+					&kernels.WorkGroup{IDX: 1, IDY: 0, IDZ: 0})).To(BeTrue())
+			})
+
+		ginkgo.It("should pass the launch bounds to every GPU", func() {
+			cmd.LaunchBounds = LaunchBounds{MaxWorkGroupSize: 128}
+
+			driver.processUnifiedMultiGPULaunchKernelCommand(cmd, cmdQueue)
+
+			Expect(cmd.Reqs).To(HaveLen(2))
+			for _, msg := range cmd.Reqs {
+				req := msg.(*protocol.LaunchKernelReq)
+				Expect(req.MaxWorkGroupSize).To(Equal(128))
+			}
+		})
+
+		ginkgo.It("should dispatch each WG once with round-robin distribution",
+			func() {
+				cmd.WGDistribution = WGDistributionRoundRobin
+
+				driver.processUnifiedMultiGPULaunchKernelCommand(cmd, cmdQueue)
+
+				Expect(cmd.Reqs).To(HaveLen(2))
+				count := dispatchCount()
+				Expect(count).To(HaveLen(30))
+				for _, c := range count {
+					Expect(c).To(Equal(1))
+				}
+
+				req := cmd.Reqs[0].(*protocol.LaunchKernelReq)
+				Expect(req.WGFilter(req.Packet,
+					&kernels.WorkGroup{IDX: 0, IDY: 0, IDZ: 0})).To(BeTrue())
+				Expect(req.WGFilter(req.Packet,
+					&kernels.WorkGroup{IDX: 1, IDY: 0, IDZ: 0})).To(BeFalse())
+			})
+
+		ginkgo.It("should not send request to GPUs without WGs", func() {
+			cmd.WGDistribution = WGDistributionRoundRobin
+			for _, packet := range cmd.PacketArray {
+				packet.GridSizeX = 64
+				packet.GridSizeY = 1
+				packet.GridSizeZ = 1
+			}
+
+			driver.processUnifiedMultiGPULaunchKernelCommand(cmd, cmdQueue)
+
+			Expect(cmd.Reqs).To(HaveLen(1))
+		})
+	})
+
+	ginkgo.It("should launch a persistent kernel once", func() {
+		cmd := &PersistentKernelCommand{
+			Packet:   &kernels.HsaKernelDispatchPacket{},
+			DPacket:  Ptr(0x1080),
+			StopFlag: Ptr(0x1040),
+		}
+		cmdQueue.Enqueue(cmd)
+
+		toGPUs.EXPECT().PeekIncoming().Return(nil).AnyTimes()
+		toMMU.EXPECT().RetrieveIncoming().Return(nil)
+		engine.EXPECT().Schedule(gomock.AssignableToTypeOf(sim.TickEvent{}))
+		engine.EXPECT().CurrentTime().Return(sim.VTimeInSec(11)).Times(2)
+
+		driver.Handle(sim.MakeTickEvent(nil, 11))
+
+		Expect(cmdQueue.IsRunning).To(BeTrue())
+		Expect(cmd.Reqs).To(HaveLen(1))
+		Expect(driver.requestsToSend).To(HaveLen(1))
+	})
+
+	ginkgo.It("should complete a persistent kernel when it returns", func() {
+		nilPort := NewMockPort(mockCtrl)
+		nilPort.EXPECT().AsRemote().AnyTimes()
+
+		req := protocol.NewLaunchKernelReq(toGPUs, nilPort)
+		cmd := &PersistentKernelCommand{
+			Packet:   &kernels.HsaKernelDispatchPacket{},
+			StopFlag: Ptr(0x1040),
+			Reqs:     []sim.Msg{req},
+		}
+		cmdQueue.Enqueue(cmd)
+		cmdQueue.IsRunning = true
+		rsp := protocol.NewLaunchKernelRsp("", "", req.ID)
+
+		toGPUs.EXPECT().PeekIncoming().Return(rsp).Times(2)
+		toGPUs.EXPECT().
+			RetrieveIncoming().
+			Return(rsp)
+
+		toMMU.EXPECT().RetrieveIncoming().Return(nil)
+
+		engine.EXPECT().Schedule(gomock.AssignableToTypeOf(sim.TickEvent{}))
+
+		engine.EXPECT().CurrentTime().Return(sim.VTimeInSec(11))
+
+		driver.Handle(sim.MakeTickEvent(nil, 11))
+
+		Expect(cmdQueue.IsRunning).To(BeFalse())
+		Expect(cmdQueue.commands).To(HaveLen(0))
 	})
 
 	ginkgo.It("should process LaunchKernel return", func() {
@@ -420,6 +898,93 @@ var _ = ginkgo.Describe("Driver", func() {
 		Expect(cmdQueue.commands).To(HaveLen(0))
 	})
 
+	ginkgo.It("should not list the kernels waiting in the queues", func() {
+		cmdQueue.Enqueue(&LaunchKernelCommand{})
+
+		Expect(driver.GetInFlightKernels()).To(BeEmpty())
+	})
+
+	ginkgo.It("should list the sizes of a unified kernel", func() {
+		engine.EXPECT().CurrentTime().Return(sim.VTimeInSec(11))
+
+		driver.enqueueLaunchUnifiedKernelCommand(cmdQueue,
+			&insts.HsaCo{}, [3]uint32{256, 2, 1}, [3]uint16{64, 2, 1},
+			nil, nil, nil, LaunchBounds{})
+		driver.recordKernelStart(cmdQueue.Peek())
+
+		Expect(driver.GetInFlightKernels()).To(Equal([]KernelInfo{{
+			GridSize:  [3]uint32{256, 2, 1},
+			WGSize:    [3]uint16{64, 2, 1},
+			GPUID:     cmdQueue.GPUID,
+			Queue:     cmdQueue,
+			StartTime: 11,
+		}}))
+	})
+
+	ginkgo.It("should not list a kernel after it completes", func() {
+		nilPort := NewMockPort(mockCtrl)
+		nilPort.EXPECT().AsRemote().AnyTimes()
+
+		req := protocol.NewLaunchKernelReq(toGPUs, nilPort)
+		cmd := &LaunchKernelCommand{
+			Reqs: []sim.Msg{req},
+		}
+		cmdQueue.Enqueue(cmd)
+		cmdQueue.IsRunning = true
+		rsp := protocol.NewLaunchKernelRsp("", "", req.ID)
+
+		toGPUs.EXPECT().PeekIncoming().Return(rsp).Times(2)
+		toGPUs.EXPECT().RetrieveIncoming().Return(rsp)
+		toMMU.EXPECT().RetrieveIncoming().Return(nil)
+		engine.EXPECT().Schedule(gomock.AssignableToTypeOf(sim.TickEvent{}))
+		engine.EXPECT().CurrentTime().Return(sim.VTimeInSec(11)).AnyTimes()
+
+		driver.recordKernelStart(cmd)
+		Expect(driver.GetInFlightKernels()).To(HaveLen(1))
+
+		driver.Handle(sim.MakeTickEvent(nil, 11))
+
+		Expect(driver.GetInFlightKernels()).To(BeEmpty())
+	})
+
+	ginkgo.It("should call OnComplete once after the kernel completes", func() {
+		nilPort := NewMockPort(mockCtrl)
+		nilPort.EXPECT().AsRemote().AnyTimes()
+
+		numCalls := 0
+		req1 := protocol.NewLaunchKernelReq(toGPUs, nilPort)
+		req2 := protocol.NewLaunchKernelReq(toGPUs, nilPort)
+		cmd := &LaunchKernelCommand{
+			Reqs:       []sim.Msg{req1, req2},
+			OnComplete: func() { numCalls++ },
+		}
+		cmdQueue.Enqueue(cmd)
+		cmdQueue.IsRunning = true
+		rsp1 := protocol.NewLaunchKernelRsp("", "", req1.ID)
+		rsp2 := protocol.NewLaunchKernelRsp("", "", req2.ID)
+
+		toGPUs.EXPECT().PeekIncoming().Return(rsp1).Times(2)
+		toGPUs.EXPECT().PeekIncoming().Return(rsp2).Times(2)
+		toGPUs.EXPECT().RetrieveIncoming().Return(rsp1)
+		toGPUs.EXPECT().RetrieveIncoming().Return(rsp2)
+		toMMU.EXPECT().RetrieveIncoming().Return(nil).Times(2)
+		engine.EXPECT().
+			Schedule(gomock.AssignableToTypeOf(sim.TickEvent{})).
+			Times(2)
+		engine.EXPECT().CurrentTime().Return(sim.VTimeInSec(11))
+		engine.EXPECT().CurrentTime().Return(sim.VTimeInSec(12))
+
+		driver.Handle(sim.MakeTickEvent(nil, 11))
+
+		Expect(numCalls).To(Equal(0))
+		Expect(cmdQueue.commands).To(HaveLen(1))
+
+		driver.Handle(sim.MakeTickEvent(nil, 12))
+
+		Expect(numCalls).To(Equal(1))
+		Expect(cmdQueue.commands).To(HaveLen(0))
+	})
+
 	ginkgo.It("should handle page migration req from MMU ", func() {
 		req := vm.NewPageMigrationReqToDriver("", driver.mmuPort.AsRemote())
 		toMMU.EXPECT().RetrieveIncoming().Return(req)
@@ -675,3 +1240,25 @@ var _ = ginkgo.Describe("Driver", func() {
 		Expect(driver.toSendToMMU).To(BeNil())
 	})
 })
+
+// fakeOccupancyReporter reports that a fixed number of work-groups fit in a
+// CU and records the kernel that it is asked about.
+type fakeOccupancyReporter struct {
+	maxWG int
+	co    *insts.HsaCo
+	numWI int
+}
+
+func (r *fakeOccupancyReporter) GetOccupancy() []protocol.CUOccupancy {
+	return nil
+}
+
+func (r *fakeOccupancyReporter) MaxResidentWGPerCU(
+	co *insts.HsaCo,
+	numWI int,
+) int {
+	r.co = co
+	r.numWI = numWI
+
+	return r.maxWG
+}
@@ -0,0 +1,61 @@
+package driver
+
+import (
+	"log"
+)
+
+// EnergyBreakdown is the energy, in joules, that the activities of a GPU
+// consume.
+type EnergyBreakdown struct {
+	// VALU is the energy of the vector ALU instructions.
+	VALU float64
+
+	// Scalar is the energy of the scalar and branch instructions.
+	Scalar float64
+
+	// VMem is the energy of the vector memory instructions.
+	VMem float64
+
+	// LDS is the energy of the local data share instructions.
+	LDS float64
+
+	// DRAM is the energy of the DRAM accesses.
+	DRAM float64
+}
+
+// Total returns the energy of all the activities.
+func (b EnergyBreakdown) Total() float64 {
+	return b.VALU + b.Scalar + b.VMem + b.LDS + b.DRAM
+}
+
+// An EnergyReporter reports the energy that a GPU consumes.
+type EnergyReporter interface {
+	EnergyBreakdown() EnergyBreakdown
+}
+
+// RegisterEnergyReporter sets the reporter that estimates the energy that the
+// given GPU consumes.
+func (d *Driver) RegisterEnergyReporter(gpuID int, reporter EnergyReporter) {
+	if d.energyReporters == nil {
+		d.energyReporters = make(map[int]EnergyReporter)
+	}
+
+	d.energyReporters[gpuID] = reporter
+}
+
+// GetEnergyConsumed returns the energy, in joules, that the given GPU has
+// consumed from the start of the simulation.
+func (d *Driver) GetEnergyConsumed(gpuID int) float64 {
+	return d.GetEnergyBreakdown(gpuID).Total()
+}
+
+// GetEnergyBreakdown returns the energy that the given GPU has consumed from
+// the start of the simulation, broken down by activity.
+func (d *Driver) GetEnergyBreakdown(gpuID int) EnergyBreakdown {
+	reporter, found := d.energyReporters[gpuID]
+	if !found {
+		log.Panicf("GPU %d does not have a power model", gpuID)
+	}
+
+	return reporter.EnergyBreakdown()
+}
@@ -0,0 +1,60 @@
+package driver_test
+
+import (
+	"encoding/binary"
+	"math"
+
+	"github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/sarchlab/mgpusim/v4/amd/driver"
+	"github.com/sarchlab/mgpusim/v4/amd/samples/runner"
+)
+
+var _ = ginkgo.Describe("Test Fill Pattern", func() {
+	var (
+		gpuDriver *driver.Driver
+		context   *driver.Context
+	)
+
+	ginkgo.BeforeEach(func() {
+		platform := runner.MakeEmuBuilder().
+			WithNumGPU(1).
+			Build()
+		gpuDriver = platform.Driver
+		gpuDriver.Run()
+		context = gpuDriver.Init()
+	})
+
+	ginkgo.AfterEach(func() {
+		gpuDriver.Terminate()
+	})
+
+	ginkgo.It("should fill floats with 1.0", func() {
+		pattern := make([]byte, 4)
+		binary.LittleEndian.PutUint32(pattern, math.Float32bits(1.0))
+
+		ptr := gpuDriver.AllocateMemory(context, 1024*4)
+		queue := gpuDriver.CreateCommandQueue(context)
+		gpuDriver.EnqueueFillPattern(queue, ptr, pattern, 1024*4)
+		gpuDriver.DrainCommandQueue(queue)
+
+		hOutput := make([]float32, 1024)
+		gpuDriver.MemCopyD2H(context, hOutput, ptr)
+		for i := 0; i < 1024; i++ {
+			Expect(hOutput[i]).To(Equal(float32(1.0)))
+		}
+	})
+
+	ginkgo.It("should truncate the last copy of the pattern", func() {
+		ptr := gpuDriver.AllocateMemory(context, 8)
+		gpuDriver.MemCopyH2D(context, ptr, make([]byte, 8))
+
+		queue := gpuDriver.CreateCommandQueue(context)
+		gpuDriver.EnqueueFillPattern(queue, ptr, []byte{1, 2, 3}, 7)
+		gpuDriver.DrainCommandQueue(queue)
+
+		hOutput := make([]byte, 8)
+		gpuDriver.MemCopyD2H(context, hOutput, ptr)
+		Expect(hOutput).To(Equal([]byte{1, 2, 3, 1, 2, 3, 1, 0}))
+	})
+})
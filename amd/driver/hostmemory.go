@@ -0,0 +1,68 @@
+package driver
+
+import "log"
+
+// MapHostMemory copies the data into a newly allocated buffer in the host
+// memory and maps the buffer into the address space of the context. The
+// kernels access the buffer directly over the host link, without the buffer
+// being copied to the GPU memory first, so that zero-copy accesses can be
+// modeled. The pages of the buffer are never migrated to a GPU.
+//
+// The buffer is freed with FreeMemory. It cannot be copied with MemCopyH2D or
+// MemCopyD2H, as no DMA engine holds the host memory. The host reads the
+// buffer back with DumpDeviceMemory.
+//
+// MapHostMemory panics if the platform does not let the GPUs access the host
+// memory.
+func (d *Driver) MapHostMemory(ctx *Context, data []byte) Ptr {
+	if !d.hostMemoryEnabled {
+		log.Panic("the GPUs of the platform cannot access the host memory")
+	}
+
+	byteSize := uint64(len(data))
+	ptr := Ptr(d.memAllocator.Allocate(ctx.pid, byteSize, 0))
+
+	ctx.buffers = append(ctx.buffers, &buffer{
+		vAddr:   ptr,
+		size:    byteSize,
+		freed:   false,
+		l2Dirty: false,
+	})
+
+	d.writeHostMemory(ctx, ptr, data)
+
+	d.recordContext("map_host_memory", ctx,
+		recordedCall{Ptr: ptr, Size: byteSize, Data: data})
+
+	return ptr
+}
+
+// writeHostMemory writes the data to the storage that backs the virtual
+// address range, page by page.
+func (d *Driver) writeHostMemory(ctx *Context, ptr Ptr, data []byte) {
+	offset := uint64(0)
+	addr := uint64(ptr)
+	sizeLeft := uint64(len(data))
+	for sizeLeft > 0 {
+		page, found := d.pageTable.Find(ctx.pid, addr)
+		if !found {
+			log.Panicf("address 0x%x is not mapped", addr)
+		}
+
+		pAddr := page.PAddr + (addr - page.VAddr)
+		sizeLeftInPage := page.PageSize - (addr - page.VAddr)
+		sizeToWrite := sizeLeftInPage
+		if sizeLeft < sizeLeftInPage {
+			sizeToWrite = sizeLeft
+		}
+
+		err := d.globalStorage.Write(pAddr, data[offset:offset+sizeToWrite])
+		if err != nil {
+			log.Panic(err)
+		}
+
+		sizeLeft -= sizeToWrite
+		addr += sizeToWrite
+		offset += sizeToWrite
+	}
+}
@@ -0,0 +1,32 @@
+package driver
+
+import (
+	"github.com/sarchlab/akita/v4/sim"
+)
+
+// HotAddGPU registers a GPU while the simulation is running and returns the
+// ID of the new GPU. It waits until the engine has no event to process, so
+// the GPU is available to all the commands enqueued after it returns. The
+// memory of the GPU is placed after the memory of the existing devices.
+//
+// The GPU must be wired to the rest of the platform before it is added. The
+// unified GPUs take the device IDs that follow the GPUs, so a GPU cannot be
+// hot-added after a unified GPU is created. A GPU cannot be hot-added while
+// the driver is recording (see StartRecording).
+func (d *Driver) HotAddGPU(
+	commandProcessorPort sim.Port,
+	props DeviceProperties,
+) int {
+	d.mustNotRecord("hot-add a GPU")
+
+	d.engineMutex.Lock()
+	defer d.engineMutex.Unlock()
+
+	if len(d.devices) != len(d.GPUs)+1 {
+		panic("cannot hot-add a GPU after creating a unified GPU")
+	}
+
+	d.RegisterGPU(commandProcessorPort, props)
+
+	return len(d.GPUs)
+}
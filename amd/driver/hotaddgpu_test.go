@@ -0,0 +1,48 @@
+package driver
+
+import (
+	"github.com/golang/mock/gomock"
+	"github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/sarchlab/akita/v4/mem/mem"
+)
+
+var _ = ginkgo.Describe("Hot-Add GPU", func() {
+	var (
+		mockCtrl *gomock.Controller
+		driver   *Driver
+		props    DeviceProperties
+	)
+
+	newGPUPort := func() *MockPort {
+		port := NewMockPort(mockCtrl)
+		port.EXPECT().AsRemote().AnyTimes()
+
+		return port
+	}
+
+	ginkgo.BeforeEach(func() {
+		mockCtrl = gomock.NewController(ginkgo.GinkgoT())
+		props = DeviceProperties{CUCount: 4, DRAMSize: 4 * mem.GB}
+		driver = MakeBuilder().WithLog2PageSize(12).Build("Driver")
+		driver.RegisterGPU(newGPUPort(), props)
+	})
+
+	ginkgo.AfterEach(func() {
+		mockCtrl.Finish()
+	})
+
+	ginkgo.It("should register the GPU after the existing GPUs", func() {
+		gpuID := driver.HotAddGPU(newGPUPort(), props)
+
+		Expect(gpuID).To(Equal(2))
+		Expect(driver.GetNumGPUs()).To(Equal(2))
+	})
+
+	ginkgo.It("should panic after creating a unified GPU", func() {
+		driver.RegisterGPU(newGPUPort(), props)
+		driver.CreateUnifiedGPU(nil, []int{1, 2})
+
+		Expect(func() { driver.HotAddGPU(newGPUPort(), props) }).To(Panic())
+	})
+})
@@ -0,0 +1,38 @@
+package driver
+
+import "github.com/sarchlab/akita/v4/mem/vm"
+
+// NewHugePageTable creates a page table that can hold both base pages and huge
+// pages. The pages are still keyed by the virtual address that they start
+// from, so that the pages can be inserted, updated, and removed in the same way
+// regardless of their sizes.
+func NewHugePageTable(log2PageSize, log2HugePageSize uint64) vm.PageTable {
+	return &hugePageTable{
+		PageTable:        vm.NewPageTable(log2PageSize),
+		log2HugePageSize: log2HugePageSize,
+	}
+}
+
+type hugePageTable struct {
+	vm.PageTable
+	log2HugePageSize uint64
+}
+
+// Find returns the base page that contains the address. If there is no such
+// base page, it returns the huge page that contains the address.
+func (pt *hugePageTable) Find(pid vm.PID, vAddr uint64) (vm.Page, bool) {
+	page, found := pt.PageTable.Find(pid, vAddr)
+	if found {
+		return page, true
+	}
+
+	hugePageSize := uint64(1) << pt.log2HugePageSize
+	hugePageVAddr := vAddr >> pt.log2HugePageSize << pt.log2HugePageSize
+
+	page, found = pt.PageTable.Find(pid, hugePageVAddr)
+	if found && page.PageSize == hugePageSize {
+		return page, true
+	}
+
+	return vm.Page{}, false
+}
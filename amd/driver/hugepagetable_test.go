@@ -0,0 +1,66 @@
+package driver
+
+import (
+	"github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/sarchlab/akita/v4/mem/vm"
+)
+
+var _ = ginkgo.Describe("Huge Page Table", func() {
+	var (
+		pageTable vm.PageTable
+		basePage  vm.Page
+		hugePage  vm.Page
+	)
+
+	ginkgo.BeforeEach(func() {
+		pageTable = NewHugePageTable(12, 21)
+
+		basePage = vm.Page{
+			PID:      1,
+			VAddr:    0x1000,
+			PAddr:    0x10_1000,
+			PageSize: 0x1000,
+			Valid:    true,
+		}
+		hugePage = vm.Page{
+			PID:      1,
+			VAddr:    0x20_0000,
+			PAddr:    0x80_0000,
+			PageSize: 0x20_0000,
+			Valid:    true,
+		}
+
+		pageTable.Insert(basePage)
+		pageTable.Insert(hugePage)
+	})
+
+	ginkgo.It("should find base pages", func() {
+		page, found := pageTable.Find(1, 0x1040)
+
+		Expect(found).To(BeTrue())
+		Expect(page).To(Equal(basePage))
+	})
+
+	ginkgo.It("should find huge pages", func() {
+		page, found := pageTable.Find(1, 0x2F_F040)
+
+		Expect(found).To(BeTrue())
+		Expect(page).To(Equal(hugePage))
+	})
+
+	ginkgo.It("should not find addresses that are not mapped", func() {
+		_, found := pageTable.Find(1, 0x2040)
+		Expect(found).To(BeFalse())
+
+		_, found = pageTable.Find(1, 0x40_0000)
+		Expect(found).To(BeFalse())
+	})
+
+	ginkgo.It("should remove huge pages", func() {
+		pageTable.Remove(1, 0x20_0000)
+
+		_, found := pageTable.Find(1, 0x2F_F040)
+		Expect(found).To(BeFalse())
+	})
+})
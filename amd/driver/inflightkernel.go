@@ -0,0 +1,121 @@
+package driver
+
+import (
+	"github.com/sarchlab/akita/v4/sim"
+	"github.com/sarchlab/mgpusim/v4/amd/insts"
+)
+
+// KernelInfo describes a kernel that is running on a GPU.
+type KernelInfo struct {
+	// Name is the symbol name of the kernel. It is empty if the code object
+	// is loaded without a kernel name.
+	Name string
+
+	GridSize [3]uint32
+	WGSize   [3]uint16
+
+	// GPUID is the GPU of the command queue that launches the kernel.
+	GPUID int
+	Queue *CommandQueue
+
+	// StartTime is the time that the driver starts to launch the kernel.
+	StartTime sim.VTimeInSec
+}
+
+// GetInFlightKernels returns the kernels that are currently running, at most
+// one for each command queue. The kernels are ordered by the creation of
+// their contexts and queues.
+func (d *Driver) GetInFlightKernels() []KernelInfo {
+	d.contextMutex.Lock()
+	defer d.contextMutex.Unlock()
+
+	var kernels []KernelInfo
+	for _, ctx := range d.contexts {
+		ctx.queueMutex.Lock()
+
+		for _, q := range ctx.queues {
+			cmd := q.Peek()
+			if cmd == nil {
+				continue
+			}
+
+			startTime, running := d.kernelStartTime(cmd)
+			if !running {
+				continue
+			}
+
+			info := kernelInfoOf(cmd)
+			info.GPUID = q.GPUID
+			info.Queue = q
+			info.StartTime = startTime
+			kernels = append(kernels, info)
+		}
+
+		ctx.queueMutex.Unlock()
+	}
+
+	return kernels
+}
+
+func (d *Driver) kernelStartTime(cmd Command) (sim.VTimeInSec, bool) {
+	d.kernelStartMutex.Lock()
+	defer d.kernelStartMutex.Unlock()
+
+	startTime, running := d.kernelStartTimes[cmd]
+
+	return startTime, running
+}
+
+func kernelInfoOf(cmd Command) KernelInfo {
+	info := KernelInfo{}
+
+	switch cmd := cmd.(type) {
+	case *LaunchKernelCommand:
+		info.Name = kernelName(cmd.CodeObject)
+		info.GridSize = cmd.GridSize
+		info.WGSize = cmd.WGSize
+	case *LaunchUnifiedMultiGPUKernelCommand:
+		info.Name = kernelName(cmd.CodeObject)
+		info.GridSize = cmd.GridSize
+		info.WGSize = cmd.WGSize
+	case *PersistentKernelCommand:
+		info.Name = kernelName(cmd.CodeObject)
+		info.GridSize = [3]uint32{
+			cmd.Packet.GridSizeX, cmd.Packet.GridSizeY, cmd.Packet.GridSizeZ}
+		info.WGSize = [3]uint16{
+			cmd.Packet.WorkgroupSizeX,
+			cmd.Packet.WorkgroupSizeY,
+			cmd.Packet.WorkgroupSizeZ,
+		}
+	}
+
+	return info
+}
+
+func kernelName(co *insts.HsaCo) string {
+	if co == nil || co.Symbol == nil {
+		return ""
+	}
+
+	return co.Symbol.Name
+}
+
+// recordKernelStart marks a kernel command as running from the current time.
+func (d *Driver) recordKernelStart(cmd Command) {
+	d.kernelStartMutex.Lock()
+	defer d.kernelStartMutex.Unlock()
+
+	if d.kernelStartTimes == nil {
+		d.kernelStartTimes = make(map[Command]sim.VTimeInSec)
+	}
+
+	d.kernelStartTimes[cmd] = d.CurrentTime()
+}
+
+// recordKernelEnd marks a kernel command as completed.
+func (d *Driver) recordKernelEnd(cmd Command) {
+	d.kernelStartMutex.Lock()
+	defer d.kernelStartMutex.Unlock()
+
+	delete(d.kernelStartTimes, cmd)
+}
@@ -67,6 +67,16 @@ func (d *Device) allocateMultiplePages(numPages int) (pAddrs []uint64) {
 	return pAddrs
 }
 
+// allocateContiguousPages allocates physically contiguous pages. It returns
+// false if the device is a unified GPU or cannot find enough contiguous space.
+func (d *Device) allocateContiguousPages(numPages int) (pAddr uint64, ok bool) {
+	if d.Type == DeviceTypeUnifiedGPU {
+		return 0, false
+	}
+
+	return d.MemState.allocateContiguousPages(numPages)
+}
+
 func (d *Device) mustHaveSpaceLeft() {
 	if d.MemState.noAvailablePAddrs() {
 		panic("out of memory")
@@ -89,9 +89,7 @@ func (bms *deviceBuddyMemoryState) allocateMultiplePages(
 ) (pAddrs []uint64) {
 	freeListLen := len(bms.freeList) - 1
 
-	var order int
-	for order = 12; (1 << order) < (numPages * 4096); order++ {}
-	level := freeListLen - (order - 12)
+	level := freeListLen - bms.orderOf(numPages)
 
 	i := level
 
@@ -127,12 +125,41 @@ func (bms *deviceBuddyMemoryState) allocateMultiplePages(
 	for j := 0; j < numPages; j++ {
 		pAddrs = append(pAddrs, block)
 		bms.blockTracking[block] = bTracker
-		block += 4096
+		block += 1 << bms.log2PageSize
 	}
 
 	return pAddrs
 }
 
+// allocateContiguousPages allocates a single block, which is always
+// contiguous. It returns false if no block is large enough.
+func (bms *deviceBuddyMemoryState) allocateContiguousPages(
+	numPages int,
+) (pAddr uint64, ok bool) {
+	freeListLen := len(bms.freeList) - 1
+
+	level := freeListLen - bms.orderOf(numPages)
+
+	for i := level; i >= 0; i-- {
+		if bms.freeList[i].Len() != 0 {
+			return bms.allocateMultiplePages(numPages)[0], true
+		}
+	}
+
+	return 0, false
+}
+
+// orderOf returns the order of the smallest block that holds the pages, where
+// a block of order 0 holds a single page.
+func (bms *deviceBuddyMemoryState) orderOf(numPages int) int {
+	order := 0
+	for (1 << order) < numPages {
+		order++
+	}
+
+	return order
+}
+
 func (bms *deviceBuddyMemoryState) buddyOf(addr uint64, level int) (buddy uint64) {
 	if bms.indexInLevelOf(addr, level) % 2 == 0 {
 		buddy = addr + bms.sizeOfLevel(level)
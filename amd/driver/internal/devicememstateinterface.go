@@ -10,6 +10,7 @@ type DeviceMemoryState interface {
 	popNextAvailablePAddrs() uint64
 	noAvailablePAddrs() bool
 	allocateMultiplePages(numPages int) []uint64
+	allocateContiguousPages(numPages int) (pAddr uint64, ok bool)
 }
 
 // NewDeviceMemoryState creates a new device memory state based on allocator type.
@@ -82,4 +83,30 @@ func (dms *deviceMemoryStateImpl) allocateMultiplePages(
 		pAddrs = append(pAddrs, pAddr)
 	}
 	return pAddrs
-}
\ No newline at end of file
+}
+
+// allocateContiguousPages finds a run of consecutive free pages. It returns
+// false if the free pages are too fragmented.
+func (dms *deviceMemoryStateImpl) allocateContiguousPages(
+	numPages int,
+) (pAddr uint64, ok bool) {
+	pageSize := uint64(1 << dms.log2PageSize)
+	runStart := 0
+
+	for i := range dms.availablePAddrs {
+		if i > runStart &&
+			dms.availablePAddrs[i] != dms.availablePAddrs[i-1]+pageSize {
+			runStart = i
+		}
+
+		if i-runStart+1 == numPages {
+			pAddr = dms.availablePAddrs[runStart]
+			dms.availablePAddrs = append(
+				dms.availablePAddrs[:runStart], dms.availablePAddrs[i+1:]...)
+
+			return pAddr, true
+		}
+	}
+
+	return 0, false
+}
@@ -60,6 +60,24 @@ var _ = Describe("Implementation of regular DeviceMemoryState", func() {
 		Expect(rDMS.availablePAddrs).To(HaveLen(1))
 	})
 
+	It("should allocate contiguous PAddrs", func() {
+		regularDMS.addSinglePAddr(0x0_0000_1000)
+		regularDMS.addSinglePAddr(0x0_0000_3000)
+		regularDMS.addSinglePAddr(0x0_0000_4000)
+		regularDMS.addSinglePAddr(0x0_0000_5000)
+
+		addr, ok := regularDMS.allocateContiguousPages(2)
+
+		Expect(ok).To(BeTrue())
+		Expect(addr).To(Equal(uint64(0x0_0000_3000)))
+		rDMS := regularDMS.(*deviceMemoryStateImpl)
+		Expect(rDMS.availablePAddrs).To(Equal(
+			[]uint64{0x0_0000_1000, 0x0_0000_5000}))
+
+		_, ok = regularDMS.allocateContiguousPages(2)
+		Expect(ok).To(BeFalse())
+	})
+
 	It("should have no available PAddrs", func() {
 		ok := regularDMS.noAvailablePAddrs()
 		Expect(ok).To(BeTrue())
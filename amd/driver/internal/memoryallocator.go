@@ -13,9 +13,10 @@ type MemoryAllocator interface {
 	GetDeviceIDByPAddr(pAddr uint64) int
 	Allocate(pid vm.PID, byteSize uint64, deviceID int) uint64
 	AllocateUnified(pid vm.PID, byteSize uint64) uint64
-	Free(vAddr uint64)
+	Free(pid vm.PID, vAddr, byteSize uint64)
 	Remap(pid vm.PID, pageVAddr, byteSize uint64, deviceID int)
-	RemovePage(vAddr uint64)
+	Move(pid vm.PID, vAddr, byteSize uint64, deviceID int) []uint64
+	RemovePage(pid vm.PID, vAddr uint64)
 	AllocatePageWithGivenVAddr(
 		pid vm.PID,
 		deviceID int,
@@ -34,12 +35,32 @@ func NewMemoryAllocator(
 		totalStorageByteSize: 1 << log2PageSize, // Starting with a page to avoid 0 address.
 		log2PageSize:         log2PageSize,
 		processMemoryStates:  make(map[vm.PID]*processMemoryState),
-		vAddrToPageMapping:   make(map[uint64]vm.Page),
+		vAddrToPageMapping:   make(map[pageKey]vm.Page),
 		devices:              make(map[int]*Device),
 	}
 	return a
 }
 
+// NewMemoryAllocatorWithHugePages creates a new memory allocator that backs
+// the allocations on the GPUs that are at least as large as a huge page with
+// huge pages. The page table must be able to find the huge pages.
+func NewMemoryAllocatorWithHugePages(
+	pageTable vm.PageTable,
+	log2PageSize uint64,
+	log2HugePageSize uint64,
+) MemoryAllocator {
+	a := NewMemoryAllocator(pageTable, log2PageSize).(*memoryAllocatorImpl)
+	a.log2HugePageSize = log2HugePageSize
+
+	return a
+}
+
+// pageKey identifies a page in the virtual address space of a process.
+type pageKey struct {
+	pid   vm.PID
+	vAddr uint64
+}
+
 type processMemoryState struct {
 	pid       vm.PID
 	nextVAddr uint64
@@ -51,7 +72,8 @@ type memoryAllocatorImpl struct {
 	sync.Mutex
 	pageTable            vm.PageTable
 	log2PageSize         uint64
-	vAddrToPageMapping   map[uint64]vm.Page
+	log2HugePageSize     uint64
+	vAddrToPageMapping   map[pageKey]vm.Page
 	processMemoryStates  map[vm.PID]*processMemoryState
 	devices              map[int]*Device
 	totalStorageByteSize uint64
@@ -107,11 +129,64 @@ func (a *memoryAllocatorImpl) Allocate(
 	a.Lock()
 	defer a.Unlock()
 
+	if a.log2HugePageSize != 0 && byteSize >= 1<<a.log2HugePageSize {
+		return a.allocateWithHugePages(pid, byteSize, deviceID)
+	}
+
 	pageSize := uint64(1 << a.log2PageSize)
 	numPages := (byteSize-1)/pageSize + 1
 	return a.allocatePages(int(numPages), pid, deviceID, false)
 }
 
+// allocateWithHugePages starts the allocation at a huge page boundary and maps
+// as much of it as possible with huge pages. The tail that does not fill a
+// huge page, as well as the part that cannot find contiguous physical memory,
+// is mapped with base pages.
+func (a *memoryAllocatorImpl) allocateWithHugePages(
+	pid vm.PID,
+	byteSize uint64,
+	deviceID int,
+) (firstPageVAddr uint64) {
+	pState := a.getProcessMemoryState(pid)
+	device := a.devices[deviceID]
+
+	pageSize := uint64(1 << a.log2PageSize)
+	hugePageSize := uint64(1 << a.log2HugePageSize)
+	numPagesPerHugePage := int(hugePageSize / pageSize)
+
+	firstPageVAddr = (pState.nextVAddr + hugePageSize - 1) /
+		hugePageSize * hugePageSize
+	pState.nextVAddr = firstPageVAddr
+
+	for i := uint64(0); i < byteSize/hugePageSize; i++ {
+		pAddr, ok := device.allocateContiguousPages(numPagesPerHugePage)
+		if !ok {
+			break
+		}
+
+		page := vm.Page{
+			PID:      pid,
+			VAddr:    pState.nextVAddr,
+			PAddr:    pAddr,
+			PageSize: hugePageSize,
+			Valid:    true,
+			DeviceID: uint64(a.deviceIDByPAddr(pAddr)),
+		}
+		a.pageTable.Insert(page)
+		a.vAddrToPageMapping[pageKey{page.PID, page.VAddr}] = page
+
+		pState.nextVAddr += hugePageSize
+	}
+
+	sizeLeft := firstPageVAddr + byteSize - pState.nextVAddr
+	if sizeLeft > 0 {
+		numPages := (sizeLeft-1)/pageSize + 1
+		a.allocatePages(int(numPages), pid, deviceID, false)
+	}
+
+	return firstPageVAddr
+}
+
 func (a *memoryAllocatorImpl) AllocateUnified(
 	pid vm.PID,
 	byteSize uint64,
@@ -134,14 +209,7 @@ func (a *memoryAllocatorImpl) allocatePages(
 	deviceID int,
 	unified bool,
 ) (firstPageVAddr uint64) {
-	pState, found := a.processMemoryStates[pid]
-	if !found {
-		a.processMemoryStates[pid] = &processMemoryState{
-			pid:       pid,
-			nextVAddr: uint64(1 << a.log2PageSize),
-		}
-		pState = a.processMemoryStates[pid]
-	}
+	pState := a.getProcessMemoryState(pid)
 	device := a.devices[deviceID]
 
 	pageSize := uint64(1 << a.log2PageSize)
@@ -164,7 +232,7 @@ func (a *memoryAllocatorImpl) allocatePages(
 		// fmt.Printf("page.addr is %x piage Device ID is %d \n", page.PAddr, page.DeviceID)
 		// debug.PrintStack()
 		a.pageTable.Insert(page)
-		a.vAddrToPageMapping[page.VAddr] = page
+		a.vAddrToPageMapping[pageKey{page.PID, page.VAddr}] = page
 	}
 
 	pState.nextVAddr += pageSize * uint64(numPages)
@@ -172,6 +240,21 @@ func (a *memoryAllocatorImpl) allocatePages(
 	return nextVAddr
 }
 
+func (a *memoryAllocatorImpl) getProcessMemoryState(
+	pid vm.PID,
+) *processMemoryState {
+	pState, found := a.processMemoryStates[pid]
+	if !found {
+		pState = &processMemoryState{
+			pid:       pid,
+			nextVAddr: uint64(1 << a.log2PageSize),
+		}
+		a.processMemoryStates[pid] = pState
+	}
+
+	return pState
+}
+
 func (a *memoryAllocatorImpl) Remap(
 	pid vm.PID,
 	pageVAddr, byteSize uint64,
@@ -191,15 +274,60 @@ func (a *memoryAllocatorImpl) Remap(
 	a.allocateMultiplePagesWithGivenVAddrs(pid, deviceID, vAddrs, false)
 }
 
-func (a *memoryAllocatorImpl) RemovePage(vAddr uint64) {
+// Move frees the physical pages that back the virtual address range and maps
+// the range to newly allocated pages on the given device. Huge pages are
+// replaced by base pages. It returns the virtual addresses of the pages that
+// are unmapped. The data is not copied.
+func (a *memoryAllocatorImpl) Move(
+	pid vm.PID,
+	vAddr, byteSize uint64,
+	deviceID int,
+) []uint64 {
+	a.Lock()
+	defer a.Unlock()
+
+	pageSize := uint64(1 << a.log2PageSize)
+	oldPageVAddrs := make([]uint64, 0)
+
+	addr := vAddr
+	for addr < vAddr+byteSize {
+		page, ok := a.vAddrToPageMapping[pageKey{pid, addr}]
+		if !ok {
+			panic("page not found")
+		}
+
+		a.removePage(pid, addr)
+		oldPageVAddrs = append(oldPageVAddrs, addr)
+
+		for offset := uint64(0); offset < page.PageSize; offset += pageSize {
+			newPage := vm.Page{
+				PID:      pid,
+				VAddr:    addr + offset,
+				PAddr:    a.devices[deviceID].allocatePage(),
+				PageSize: pageSize,
+				Valid:    true,
+				DeviceID: uint64(deviceID),
+				Unified:  page.Unified,
+			}
+			a.vAddrToPageMapping[pageKey{pid, newPage.VAddr}] = newPage
+			a.pageTable.Insert(newPage)
+		}
+
+		addr += page.PageSize
+	}
+
+	return oldPageVAddrs
+}
+
+func (a *memoryAllocatorImpl) RemovePage(pid vm.PID, vAddr uint64) {
 	a.Lock()
 	defer a.Unlock()
 
-	a.removePage(vAddr)
+	a.removePage(pid, vAddr)
 }
 
-func (a *memoryAllocatorImpl) removePage(vAddr uint64) {
-	page, ok := a.vAddrToPageMapping[vAddr]
+func (a *memoryAllocatorImpl) removePage(pid vm.PID, vAddr uint64) {
+	page, ok := a.vAddrToPageMapping[pageKey{pid, vAddr}]
 
 	if !ok {
 		panic("page not found")
@@ -207,9 +335,14 @@ func (a *memoryAllocatorImpl) removePage(vAddr uint64) {
 
 	deviceID := a.deviceIDByPAddr(page.PAddr)
 	dState := a.devices[deviceID].MemState
-	dState.addSinglePAddr(page.PAddr)
+
+	pageSize := uint64(1 << a.log2PageSize)
+	for offset := uint64(0); offset < page.PageSize; offset += pageSize {
+		dState.addSinglePAddr(page.PAddr + offset)
+	}
 
 	a.pageTable.Remove(page.PID, page.VAddr)
+	delete(a.vAddrToPageMapping, pageKey{pid, vAddr})
 }
 
 func (a *memoryAllocatorImpl) AllocatePageWithGivenVAddr(
@@ -244,7 +377,7 @@ func (a *memoryAllocatorImpl) allocatePageWithGivenVAddr(
 		DeviceID: uint64(deviceID),
 		Unified:  isUnified,
 	}
-	a.vAddrToPageMapping[page.VAddr] = page
+	a.vAddrToPageMapping[pageKey{page.PID, page.VAddr}] = page
 	a.pageTable.Update(page)
 
 	return page
@@ -271,7 +404,7 @@ func (a *memoryAllocatorImpl) allocateMultiplePagesWithGivenVAddrs(
 			DeviceID: uint64(deviceID),
 			Unified:  isUnified,
 		}
-		a.vAddrToPageMapping[page.VAddr] = page
+		a.vAddrToPageMapping[pageKey{page.PID, page.VAddr}] = page
 		a.pageTable.Update(page)
 		pages = append(pages, page)
 	}
@@ -279,9 +412,20 @@ func (a *memoryAllocatorImpl) allocateMultiplePagesWithGivenVAddrs(
 	return pages
 }
 
-func (a *memoryAllocatorImpl) Free(ptr uint64) {
+// Free returns all the pages that back the virtual address range to the
+// devices and removes them from the page table.
+func (a *memoryAllocatorImpl) Free(pid vm.PID, vAddr, byteSize uint64) {
 	a.Lock()
 	defer a.Unlock()
 
-	a.removePage(ptr)
+	addr := vAddr
+	for addr < vAddr+byteSize {
+		page, ok := a.vAddrToPageMapping[pageKey{pid, addr}]
+		if !ok {
+			panic("page not found")
+		}
+
+		a.removePage(pid, addr)
+		addr += page.PageSize
+	}
 }
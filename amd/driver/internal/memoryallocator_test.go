@@ -94,6 +94,100 @@ var _ = Describe("MemoryAllocatorImpl", func() {
 		pageTable.EXPECT().Update(updatedPage)
 		allocator.Remap(1, ptr, 4000, 2)
 	})
+
+	It("should move pages to another device", func() {
+		pageTable.EXPECT().Insert(gomock.Any()).Times(2)
+		ptr := allocator.Allocate(1, 8192, 1)
+
+		pageTable.EXPECT().Remove(vm.PID(1), uint64(4096))
+		pageTable.EXPECT().Remove(vm.PID(1), uint64(8192))
+		pageTable.EXPECT().Insert(vm.Page{
+			PID:      1,
+			PAddr:    0x2_0000_1000,
+			VAddr:    4096,
+			PageSize: 4096,
+			DeviceID: 2,
+			Valid:    true,
+		})
+		pageTable.EXPECT().Insert(vm.Page{
+			PID:      1,
+			PAddr:    0x2_0000_2000,
+			VAddr:    8192,
+			PageSize: 4096,
+			DeviceID: 2,
+			Valid:    true,
+		})
+
+		oldPageVAddrs := allocator.Move(1, ptr, 8192, 2)
+
+		Expect(oldPageVAddrs).To(Equal([]uint64{4096, 8192}))
+		Expect(allocator.devices[1].MemState.(*deviceMemoryStateImpl).
+			availablePAddrs).To(ContainElements(
+			uint64(0x1_0000_1000), uint64(0x1_0000_2000)))
+	})
+
+	It("should back large allocations with huge pages", func() {
+		allocator = NewMemoryAllocatorWithHugePages(
+			pageTable, 12, 21).(*memoryAllocatorImpl)
+		configAFourGPUSystem(allocator)
+
+		hugePage := vm.Page{
+			PID:      1,
+			PAddr:    0x1_0000_1000,
+			VAddr:    0x20_0000,
+			PageSize: 0x20_0000,
+			DeviceID: 1,
+			Valid:    true,
+		}
+		pageTable.EXPECT().Insert(hugePage)
+		for i := uint64(0); i < 2; i++ {
+			pageTable.EXPECT().Insert(
+				vm.Page{
+					PID:      1,
+					PAddr:    0x1_0020_1000 + 0x1000*i,
+					VAddr:    0x40_0000 + 0x1000*i,
+					DeviceID: 1,
+					PageSize: 4096,
+					Valid:    true,
+				})
+		}
+
+		ptr := allocator.Allocate(1, 0x20_2000, 1)
+		Expect(ptr).To(Equal(uint64(0x20_0000)))
+
+		pageTable.EXPECT().Remove(vm.PID(1), uint64(0x20_0000))
+		pageTable.EXPECT().Remove(vm.PID(1), uint64(0x40_0000))
+		pageTable.EXPECT().Remove(vm.PID(1), uint64(0x40_1000))
+		allocator.Free(1, ptr, 0x20_2000)
+
+		dState := allocator.devices[1].MemState.(*deviceMemoryStateImpl)
+		Expect(dState.availablePAddrs).To(ContainElements(
+			uint64(0x1_0000_1000), uint64(0x1_001F_F000),
+			uint64(0x1_0020_1000), uint64(0x1_0020_2000)))
+	})
+
+	It("should reuse the pages of a freed buffer", func() {
+		gpu := &Device{
+			ID:       5,
+			Type:     DeviceTypeGPU,
+			MemState: NewDeviceMemoryState(12),
+		}
+		gpu.SetTotalMemSize(0x3000)
+		allocator.RegisterDevice(gpu)
+
+		var pAddrs []uint64
+		pageTable.EXPECT().Insert(gomock.Any()).
+			Do(func(page vm.Page) {
+				pAddrs = append(pAddrs, page.PAddr)
+			}).Times(6)
+		pageTable.EXPECT().Remove(vm.PID(1), gomock.Any()).Times(3)
+
+		ptr := allocator.Allocate(1, 0x3000, 5)
+		allocator.Free(1, ptr, 0x3000)
+		allocator.Allocate(1, 0x3000, 5)
+
+		Expect(pAddrs[3:]).To(ConsistOf(pAddrs[:3]))
+	})
 })
 
 func configAFourGPUSystem(allocator *memoryAllocatorImpl) {
@@ -2,6 +2,7 @@ package driver
 
 import (
 	"encoding/binary"
+	"log"
 	"reflect"
 
 	"github.com/sarchlab/akita/v4/sim"
@@ -17,22 +18,111 @@ func (d *Driver) EnqueueLaunchKernel(
 	gridSize [3]uint32,
 	wgSize [3]uint16,
 	kernelArgs interface{},
+) {
+	d.enqueueKernel(queue, co, gridSize, wgSize, kernelArgs, nil)
+}
+
+// EnqueueKernelWithCompletionCallback schedules a kernel to be launched later
+// and calls onComplete once as soon as the kernel completes. The callback runs
+// on the simulation engine goroutine at the simulated time when the kernel
+// completes. It can enqueue new commands, but it must not call the functions
+// that wait for the simulation, such as DrainCommandQueue and LaunchKernel.
+func (d *Driver) EnqueueKernelWithCompletionCallback(
+	queue *CommandQueue,
+	co *insts.HsaCo,
+	gridSize [3]uint32,
+	wgSize [3]uint16,
+	kernelArgs interface{},
+	onComplete func(),
+) {
+	d.enqueueKernel(queue, co, gridSize, wgSize, kernelArgs, onComplete)
+}
+
+func (d *Driver) enqueueKernel(
+	queue *CommandQueue,
+	co *insts.HsaCo,
+	gridSize [3]uint32,
+	wgSize [3]uint16,
+	kernelArgs interface{},
+	onComplete func(),
 ) {
 	dev := d.devices[queue.GPUID]
 
 	if dev.Type == internal.DeviceTypeUnifiedGPU {
-		d.enqueueLaunchUnifiedKernel(queue, co, gridSize, wgSize, kernelArgs)
+		d.enqueueLaunchUnifiedKernel(
+			queue, co, gridSize, wgSize, kernelArgs, onComplete, LaunchBounds{})
 	} else {
-		dCoData, dKernArgData, dPacket := d.allocateGPUMemory(queue.Context, co)
+		cmd := d.PrepareKernel(queue, co, gridSize, wgSize, kernelArgs)
+		cmd.OnComplete = onComplete
+		d.Enqueue(queue, cmd)
+	}
+}
 
-		packet := d.createAQLPacket(gridSize, wgSize, dCoData, dKernArgData)
-		newKernelArgs := d.prepareLocalMemory(co, kernelArgs, packet)
+// PrepareKernel enqueues the commands that copy the code object, the kernel
+// arguments, and the dispatch packet of a kernel to the GPU of the queue. It
+// returns the command that launches the kernel, without enqueueing it. The
+// command can be enqueued with Enqueue or EnqueueKernelRepeated after the
+// copies.
+func (d *Driver) PrepareKernel(
+	queue *CommandQueue,
+	co *insts.HsaCo,
+	gridSize [3]uint32,
+	wgSize [3]uint16,
+	kernelArgs interface{},
+) *LaunchKernelCommand {
+	dev := d.devices[queue.GPUID]
+	if dev.Type == internal.DeviceTypeUnifiedGPU {
+		log.Panic("cannot prepare kernels for unified GPUs")
+	}
 
-		d.EnqueueMemCopyH2D(queue, dCoData, co.Data)
-		d.EnqueueMemCopyH2D(queue, dKernArgData, newKernelArgs)
-		d.EnqueueMemCopyH2D(queue, dPacket, packet)
+	dCoData, dKernArgData, dPacket := d.allocateGPUMemory(queue.Context, co)
+
+	packet := d.createAQLPacket(gridSize, wgSize, dCoData, dKernArgData)
+	newKernelArgs := d.prepareLocalMemory(co, kernelArgs, packet)
+
+	d.EnqueueMemCopyH2D(queue, dCoData, co.Data)
+	d.EnqueueMemCopyH2D(queue, dKernArgData, newKernelArgs)
+	d.EnqueueMemCopyH2D(queue, dPacket, packet)
 
-		d.enqueueLaunchKernelCommand(queue, co, packet, dPacket)
+	return &LaunchKernelCommand{
+		ID:         sim.GetIDGenerator().Generate(),
+		CodeObject: co,
+		GridSize:   gridSize,
+		WGSize:     wgSize,
+		KernelArgs: kernelArgs,
+		DPacket:    dPacket,
+		Packet:     packet,
+	}
+}
+
+// EnqueueKernelRepeated enqueues count launches of the kernel of a command,
+// usually returned by PrepareKernel. All the launches reuse the code object,
+// the kernel arguments, and the dispatch packet that are already in the GPU
+// memory, so that no memory copy happens between the iterations. Each launch
+// calls the OnComplete callback of the command, if any, which is where the
+// counters can be read and reset between the iterations.
+func (d *Driver) EnqueueKernelRepeated(
+	queue *CommandQueue,
+	cmd *LaunchKernelCommand,
+	count int,
+) {
+	if count < 0 {
+		log.Panicf("cannot launch a kernel %d times", count)
+	}
+
+	for i := 0; i < count; i++ {
+		d.Enqueue(queue, &LaunchKernelCommand{
+			ID:         sim.GetIDGenerator().Generate(),
+			CodeObject: cmd.CodeObject,
+			GridSize:   cmd.GridSize,
+			WGSize:     cmd.WGSize,
+			KernelArgs: cmd.KernelArgs,
+			Packet:     cmd.Packet,
+			DPacket:    cmd.DPacket,
+			OnComplete: cmd.OnComplete,
+
+			LaunchBounds: cmd.LaunchBounds,
+		})
 	}
 }
 
@@ -94,6 +184,22 @@ func (d *Driver) LaunchKernel(
 	d.DrainCommandQueue(queue)
 }
 
+// EnqueueKernelAndWait enqueues a kernel launch command, usually returned by
+// PrepareKernel, and waits until the command queue is drained. It returns the
+// fault that the kernel encounters, or nil if the kernel completes without a
+// fault. See GetLastKernelError for the faults that the platforms detect.
+func (d *Driver) EnqueueKernelAndWait(
+	queue *CommandQueue,
+	cmd *LaunchKernelCommand,
+) error {
+	cmd.fault = nil
+
+	d.Enqueue(queue, cmd)
+	d.DrainCommandQueue(queue)
+
+	return cmd.fault
+}
+
 func (d *Driver) createAQLPacket(
 	gridSize [3]uint32,
 	wgSize [3]uint16,
@@ -112,32 +218,26 @@ func (d *Driver) createAQLPacket(
 	return packet
 }
 
-func (d *Driver) enqueueLaunchKernelCommand(
-	queue *CommandQueue,
-	co *insts.HsaCo,
-	packet *kernels.HsaKernelDispatchPacket,
-	dPacket Ptr,
-) {
-	cmd := &LaunchKernelCommand{
-		ID:         sim.GetIDGenerator().Generate(),
-		CodeObject: co,
-		DPacket:    dPacket,
-		Packet:     packet,
-	}
-	d.Enqueue(queue, cmd)
-}
-
 func (d *Driver) enqueueLaunchUnifiedKernelCommand(
 	queue *CommandQueue,
 	co *insts.HsaCo,
+	gridSize [3]uint32,
+	wgSize [3]uint16,
 	packet []*kernels.HsaKernelDispatchPacket,
 	dPacket []Ptr,
+	onComplete func(),
+	bounds LaunchBounds,
 ) {
 	cmd := &LaunchUnifiedMultiGPUKernelCommand{
-		ID:           sim.GetIDGenerator().Generate(),
-		CodeObject:   co,
-		DPacketArray: dPacket,
-		PacketArray:  packet,
+		ID:             sim.GetIDGenerator().Generate(),
+		CodeObject:     co,
+		GridSize:       gridSize,
+		WGSize:         wgSize,
+		DPacketArray:   dPacket,
+		PacketArray:    packet,
+		WGDistribution: d.wgDistribution,
+		OnComplete:     onComplete,
+		LaunchBounds:   bounds,
 	}
 	d.Enqueue(queue, cmd)
 }
@@ -147,6 +247,8 @@ func (d *Driver) enqueueLaunchUnifiedKernel(
 	gridSize [3]uint32,
 	wgSize [3]uint16,
 	kernelArgs interface{},
+	onComplete func(),
+	bounds LaunchBounds,
 ) {
 	dev := d.devices[queue.GPUID]
 	initGPUID := queue.Context.currentGPUID
@@ -177,5 +279,6 @@ func (d *Driver) enqueueLaunchUnifiedKernel(
 	}
 
 	queue.Context.currentGPUID = initGPUID
-	d.enqueueLaunchUnifiedKernelCommand(queue, co, packetArray, dPacketArray)
+	d.enqueueLaunchUnifiedKernelCommand(queue, co, gridSize, wgSize,
+		packetArray, dPacketArray, onComplete, bounds)
 }
@@ -0,0 +1,72 @@
+package driver_test
+
+import (
+	"github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/sarchlab/mgpusim/v4/amd/benchmarks/heteromark/fir"
+	"github.com/sarchlab/mgpusim/v4/amd/driver"
+	"github.com/sarchlab/mgpusim/v4/amd/kernels"
+	"github.com/sarchlab/mgpusim/v4/amd/samples/runner"
+)
+
+var _ = ginkgo.Describe("Enqueue Kernel And Wait", func() {
+	const length = 256
+
+	var (
+		gpuDriver *driver.Driver
+		context   *driver.Context
+		queue     *driver.CommandQueue
+	)
+
+	ginkgo.BeforeEach(func() {
+		gpuDriver = runner.MakeEmuBuilder().WithNumGPU(1).Build().Driver
+		gpuDriver.Run()
+		context = gpuDriver.Init()
+		queue = gpuDriver.CreateCommandQueue(context)
+	})
+
+	ginkgo.AfterEach(func() {
+		gpuDriver.Terminate()
+	})
+
+	prepareFIR := func(input driver.Ptr) *driver.LaunchKernelCommand {
+		hsaco := kernels.LoadProgram(
+			"../benchmarks/heteromark/fir/kernels.hsaco", "FIR")
+		args := &fir.KernelArgs{
+			Output:  gpuDriver.AllocateMemory(context, length*4),
+			Filter:  gpuDriver.AllocateMemory(context, 16*4),
+			Input:   input,
+			History: gpuDriver.AllocateMemory(context, 16*4),
+			NumTaps: 16,
+		}
+
+		return gpuDriver.PrepareKernel(queue, hsaco,
+			[3]uint32{length, 1, 1}, [3]uint16{64, 1, 1}, args)
+	}
+
+	ginkgo.It("should return after the kernel completes", func() {
+		cmd := prepareFIR(gpuDriver.AllocateMemory(context, length*4))
+		completed := false
+		cmd.OnComplete = func() { completed = true }
+
+		Expect(gpuDriver.EnqueueKernelAndWait(queue, cmd)).To(Succeed())
+		Expect(completed).To(BeTrue())
+		Expect(queue.NumCommand()).To(Equal(0))
+	})
+
+	ginkgo.It("should return the fault of the kernel", func() {
+		cmd := prepareFIR(driver.Ptr(0xdead0000))
+
+		Expect(gpuDriver.EnqueueKernelAndWait(queue, cmd)).
+			To(MatchError(ContainSubstring("illegal memory address")))
+	})
+
+	ginkgo.It("should not return the fault of an earlier kernel", func() {
+		faulty := prepareFIR(driver.Ptr(0xdead0000))
+		Expect(gpuDriver.EnqueueKernelAndWait(queue, faulty)).NotTo(Succeed())
+
+		cmd := prepareFIR(gpuDriver.AllocateMemory(context, length*4))
+
+		Expect(gpuDriver.EnqueueKernelAndWait(queue, cmd)).To(Succeed())
+	})
+})
@@ -0,0 +1,111 @@
+package driver
+
+import (
+	"encoding/binary"
+	"log"
+	"math"
+	"reflect"
+)
+
+// SetKernelArgStruct writes a Go value into a kernel argument buffer at the
+// given offset. The value is laid out following the HSA kernel argument rules,
+// where each field is aligned to its natural alignment and each struct is
+// aligned to and padded to the alignment of its most aligned field. Nested
+// structs and arrays are supported.
+func (d *Driver) SetKernelArgStruct(
+	ctx *Context,
+	argBuffer Ptr,
+	offset uint64,
+	v interface{},
+) {
+	value := reflect.Indirect(reflect.ValueOf(v))
+	if offset%kernelArgAlignment(value.Type()) != 0 {
+		log.Panicf("offset %d is not aligned for type %s",
+			offset, value.Type())
+	}
+
+	data := marshalKernelArg(v)
+	d.MemCopyH2D(ctx, argBuffer+Ptr(offset), data)
+}
+
+// marshalKernelArg converts a value to bytes using the HSA kernel argument
+// layout.
+func marshalKernelArg(v interface{}) []byte {
+	value := reflect.Indirect(reflect.ValueOf(v))
+	return appendKernelArg(nil, value)
+}
+
+func appendKernelArg(buf []byte, v reflect.Value) []byte {
+	buf = padKernelArg(buf, kernelArgAlignment(v.Type()))
+
+	switch v.Kind() {
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			buf = appendKernelArg(buf, v.Field(i))
+		}
+		buf = padKernelArg(buf, kernelArgAlignment(v.Type()))
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			buf = appendKernelArg(buf, v.Index(i))
+		}
+	case reflect.Bool:
+		if v.Bool() {
+			buf = append(buf, 1)
+		} else {
+			buf = append(buf, 0)
+		}
+	case reflect.Int8, reflect.Uint8:
+		buf = append(buf, byte(kernelArgBits(v)))
+	case reflect.Int16, reflect.Uint16:
+		buf = binary.LittleEndian.AppendUint16(buf, uint16(kernelArgBits(v)))
+	case reflect.Int32, reflect.Uint32, reflect.Float32:
+		buf = binary.LittleEndian.AppendUint32(buf, uint32(kernelArgBits(v)))
+	case reflect.Int64, reflect.Uint64, reflect.Float64:
+		buf = binary.LittleEndian.AppendUint64(buf, kernelArgBits(v))
+	default:
+		log.Panicf("type %s cannot be used as a kernel argument", v.Type())
+	}
+
+	return buf
+}
+
+func kernelArgBits(v reflect.Value) uint64 {
+	switch v.Kind() {
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return uint64(v.Int())
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return v.Uint()
+	case reflect.Float32:
+		return uint64(math.Float32bits(float32(v.Float())))
+	case reflect.Float64:
+		return math.Float64bits(v.Float())
+	}
+
+	panic("never")
+}
+
+func kernelArgAlignment(t reflect.Type) uint64 {
+	switch t.Kind() {
+	case reflect.Struct:
+		alignment := uint64(1)
+		for i := 0; i < t.NumField(); i++ {
+			fieldAlignment := kernelArgAlignment(t.Field(i).Type)
+			if fieldAlignment > alignment {
+				alignment = fieldAlignment
+			}
+		}
+		return alignment
+	case reflect.Array:
+		return kernelArgAlignment(t.Elem())
+	default:
+		return uint64(t.Size())
+	}
+}
+
+func padKernelArg(buf []byte, alignment uint64) []byte {
+	for uint64(len(buf))%alignment != 0 {
+		buf = append(buf, 0)
+	}
+
+	return buf
+}
@@ -0,0 +1,72 @@
+package driver
+
+import (
+	"github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type kernelArgInner struct {
+	A uint8
+	B float32
+}
+
+type kernelArgOuter struct {
+	X     uint16
+	Inner kernelArgInner
+	Y     uint8
+	Ptr   Ptr
+	Arr   [3]uint16
+	Z     int32
+}
+
+var _ = ginkgo.Describe("Kernel Argument Marshalling", func() {
+	ginkgo.It("should align scalar fields", func() {
+		args := struct {
+			A uint8
+			B uint32
+			C uint8
+			D uint64
+		}{1, 2, 3, 4}
+
+		data := marshalKernelArg(&args)
+
+		Expect(data).To(HaveLen(24))
+		Expect(data[0]).To(Equal(byte(1)))
+		Expect(data[4:8]).To(Equal([]byte{2, 0, 0, 0}))
+		Expect(data[8]).To(Equal(byte(3)))
+		Expect(data[16:24]).To(Equal([]byte{4, 0, 0, 0, 0, 0, 0, 0}))
+	})
+
+	ginkgo.It("should align nested structs and arrays", func() {
+		args := kernelArgOuter{
+			X:     0x0102,
+			Inner: kernelArgInner{A: 5, B: 1.0},
+			Y:     6,
+			Ptr:   0x1122334455667788,
+			Arr:   [3]uint16{7, 8, 9},
+			Z:     -1,
+		}
+
+		data := marshalKernelArg(args)
+
+		// X at 0, Inner at 4 (A at 4, B at 8), Y at 12, Ptr at 16,
+		// Arr at 24, Z at 32, and the struct is padded to 40 bytes.
+		Expect(data).To(HaveLen(40))
+		Expect(data[0:2]).To(Equal([]byte{0x02, 0x01}))
+		Expect(data[4]).To(Equal(byte(5)))
+		Expect(data[8:12]).To(Equal([]byte{0x00, 0x00, 0x80, 0x3f}))
+		Expect(data[12]).To(Equal(byte(6)))
+		Expect(data[16:24]).To(Equal(
+			[]byte{0x88, 0x77, 0x66, 0x55, 0x44, 0x33, 0x22, 0x11}))
+		Expect(data[24:30]).To(Equal([]byte{7, 0, 8, 0, 9, 0}))
+		Expect(data[32:36]).To(Equal([]byte{0xff, 0xff, 0xff, 0xff}))
+	})
+
+	ginkgo.It("should panic on unsupported types", func() {
+		args := struct {
+			S []int32
+		}{}
+
+		Expect(func() { marshalKernelArg(&args) }).To(Panic())
+	})
+})
@@ -0,0 +1,200 @@
+package driver
+
+import (
+	"log"
+	"sync"
+
+	"github.com/sarchlab/akita/v4/sim"
+	"github.com/sarchlab/mgpusim/v4/amd/insts"
+)
+
+// A KernelGraph is a set of kernel launches and the dependencies among them.
+// As a node can only depend on the nodes added before it, the graph never has
+// cycles.
+type KernelGraph struct {
+	ctx   *Context
+	nodes []*KernelGraphNode
+
+	lock          sync.Mutex
+	queued        bool
+	numIncomplete int
+	idleQueues    []*CommandQueue
+	done          chan bool
+}
+
+// A KernelGraphNode is a kernel launch in a KernelGraph.
+type KernelGraphNode struct {
+	graph          *KernelGraph
+	codeObject     *insts.HsaCo
+	gridSize       [3]uint32
+	wgSize         [3]uint16
+	kernelArgs     interface{}
+	dependencies   []*KernelGraphNode
+	successors     []*KernelGraphNode
+	numPending     int
+	recordedLaunch int
+	releaseTime    sim.VTimeInSec
+	completeTime   sim.VTimeInSec
+}
+
+// ReleaseTime returns the time when all the dependencies of the node complete
+// and the driver enqueues the kernel.
+func (n *KernelGraphNode) ReleaseTime() sim.VTimeInSec {
+	n.graph.lock.Lock()
+	defer n.graph.lock.Unlock()
+
+	return n.releaseTime
+}
+
+// CompleteTime returns the time when the kernel completes.
+func (n *KernelGraphNode) CompleteTime() sim.VTimeInSec {
+	n.graph.lock.Lock()
+	defer n.graph.lock.Unlock()
+
+	return n.completeTime
+}
+
+// NewKernelGraph creates an empty kernel graph whose kernels run in the given
+// context.
+func NewKernelGraph(ctx *Context) *KernelGraph {
+	return &KernelGraph{
+		ctx:  ctx,
+		done: make(chan bool),
+	}
+}
+
+// AddKernel adds a kernel launch that runs after all the given dependencies
+// complete.
+func (g *KernelGraph) AddKernel(
+	co *insts.HsaCo,
+	gridSize [3]uint32,
+	wgSize [3]uint16,
+	kernelArgs interface{},
+	dependencies ...*KernelGraphNode,
+) *KernelGraphNode {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	if g.queued {
+		log.Panic("cannot add kernels to a queued kernel graph")
+	}
+
+	n := &KernelGraphNode{
+		graph:        g,
+		codeObject:   co,
+		gridSize:     gridSize,
+		wgSize:       wgSize,
+		kernelArgs:   kernelArgs,
+		dependencies: dependencies,
+		numPending:   len(dependencies),
+	}
+
+	for _, dep := range dependencies {
+		if dep.graph != g {
+			log.Panic("a kernel can only depend on kernels of the same graph")
+		}
+
+		dep.successors = append(dep.successors, n)
+	}
+
+	g.nodes = append(g.nodes, n)
+
+	return n
+}
+
+// QueueKernelGraph launches the kernels of the graph. A kernel is enqueued
+// once all its dependencies complete. The kernels that are ready at the same
+// time are enqueued to different command queues so that they can run
+// concurrently. A graph can only be queued once. Use DrainKernelGraph to wait
+// for the graph to complete.
+func (d *Driver) QueueKernelGraph(graph *KernelGraph) {
+	graph.lock.Lock()
+	defer graph.lock.Unlock()
+
+	if graph.queued {
+		log.Panic("the kernel graph is already queued")
+	}
+
+	graph.queued = true
+	graph.numIncomplete = len(graph.nodes)
+
+	if graph.numIncomplete == 0 {
+		close(graph.done)
+		return
+	}
+
+	for _, n := range graph.nodes {
+		if n.numPending == 0 {
+			d.releaseKernelGraphNode(n)
+		}
+	}
+}
+
+// DrainKernelGraph returns when all the kernels of a queued graph complete.
+func (d *Driver) DrainKernelGraph(graph *KernelGraph) {
+	d.enqueueSignal <- true
+	<-graph.done
+
+	graph.lock.Lock()
+	defer graph.lock.Unlock()
+
+	d.recordWait(recordedLaunchesOf(graph.nodes))
+}
+
+// releaseKernelGraphNode enqueues the kernel of a node to an idle queue. The
+// graph lock must be held.
+func (d *Driver) releaseKernelGraphNode(n *KernelGraphNode) {
+	g := n.graph
+
+	var queue *CommandQueue
+	if len(g.idleQueues) > 0 {
+		queue = g.idleQueues[len(g.idleQueues)-1]
+		g.idleQueues = g.idleQueues[:len(g.idleQueues)-1]
+	} else {
+		queue = d.CreateCommandQueue(g.ctx)
+	}
+
+	n.releaseTime = d.Engine.CurrentTime()
+
+	d.recordWait(recordedLaunchesOf(n.dependencies))
+
+	d.enqueueKernel(queue, n.codeObject, n.gridSize, n.wgSize, n.kernelArgs,
+		func() { d.completeKernelGraphNode(n, queue) })
+	n.recordedLaunch = d.recordedLastLaunch(queue)
+}
+
+// completeKernelGraphNode runs on the engine goroutine when the kernel of a
+// node completes.
+func (d *Driver) completeKernelGraphNode(
+	n *KernelGraphNode,
+	queue *CommandQueue,
+) {
+	g := n.graph
+
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	n.completeTime = d.Engine.CurrentTime()
+	g.idleQueues = append(g.idleQueues, queue)
+
+	for _, s := range n.successors {
+		s.numPending--
+		if s.numPending == 0 {
+			d.releaseKernelGraphNode(s)
+		}
+	}
+
+	g.numIncomplete--
+	if g.numIncomplete == 0 {
+		close(g.done)
+	}
+}
+
+func recordedLaunchesOf(nodes []*KernelGraphNode) []int {
+	launches := make([]int, 0, len(nodes))
+	for _, n := range nodes {
+		launches = append(launches, n.recordedLaunch)
+	}
+
+	return launches
+}
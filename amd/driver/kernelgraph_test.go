@@ -0,0 +1,104 @@
+package driver_test
+
+import (
+	"github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/sarchlab/mgpusim/v4/amd/benchmarks/heteromark/fir"
+	"github.com/sarchlab/mgpusim/v4/amd/driver"
+	"github.com/sarchlab/mgpusim/v4/amd/insts"
+	"github.com/sarchlab/mgpusim/v4/amd/kernels"
+	"github.com/sarchlab/mgpusim/v4/amd/samples/runner"
+)
+
+var _ = ginkgo.Describe("Kernel Graph", func() {
+	const length = 1024
+
+	var (
+		gpuDriver *driver.Driver
+		context   *driver.Context
+		hsaco     *insts.HsaCo
+		graph     *driver.KernelGraph
+	)
+
+	ginkgo.BeforeEach(func() {
+		platform := runner.MakeEmuBuilder().
+			WithNumGPU(1).
+			Build()
+		gpuDriver = platform.Driver
+		gpuDriver.Run()
+		context = gpuDriver.Init()
+		hsaco = kernels.LoadProgram(
+			"../benchmarks/heteromark/fir/kernels.hsaco", "FIR")
+		graph = driver.NewKernelGraph(context)
+	})
+
+	ginkgo.AfterEach(func() {
+		gpuDriver.Terminate()
+	})
+
+	addFIR := func(
+		input driver.Ptr,
+		dependencies ...*driver.KernelGraphNode,
+	) (*driver.KernelGraphNode, driver.Ptr) {
+		output := gpuDriver.AllocateMemory(context, length*4)
+		args := &fir.KernelArgs{
+			Output:  output,
+			Filter:  gpuDriver.AllocateMemory(context, 16*4),
+			Input:   input,
+			History: gpuDriver.AllocateMemory(context, 16*4),
+			NumTaps: 16,
+		}
+
+		n := graph.AddKernel(hsaco,
+			[3]uint32{length, 1, 1}, [3]uint16{64, 1, 1}, args,
+			dependencies...)
+
+		return n, output
+	}
+
+	ginkgo.It("should release the kernels after their dependencies", func() {
+		a, outA := addFIR(gpuDriver.AllocateMemory(context, length*4))
+		b, outB := addFIR(outA, a)
+		c, _ := addFIR(outA, a)
+		d, _ := addFIR(outB, b, c)
+
+		gpuDriver.QueueKernelGraph(graph)
+		gpuDriver.DrainKernelGraph(graph)
+
+		Expect(b.ReleaseTime()).To(BeNumerically(">=", a.CompleteTime()))
+		Expect(c.ReleaseTime()).To(Equal(b.ReleaseTime()))
+		Expect(d.ReleaseTime()).To(BeNumerically(">=", b.CompleteTime()))
+		Expect(d.ReleaseTime()).To(BeNumerically(">=", c.CompleteTime()))
+		Expect(d.CompleteTime()).To(BeNumerically(">", d.ReleaseTime()))
+	})
+
+	ginkgo.It("should complete an empty graph", func() {
+		gpuDriver.QueueKernelGraph(graph)
+		gpuDriver.DrainKernelGraph(graph)
+	})
+
+	ginkgo.It("should not queue a graph twice", func() {
+		gpuDriver.QueueKernelGraph(graph)
+
+		Expect(func() { gpuDriver.QueueKernelGraph(graph) }).To(Panic())
+	})
+
+	ginkgo.It("should not add kernels to a queued graph", func() {
+		gpuDriver.QueueKernelGraph(graph)
+
+		Expect(func() {
+			addFIR(gpuDriver.AllocateMemory(context, length*4))
+		}).To(Panic())
+	})
+
+	ginkgo.It("should not depend on the kernels of another graph", func() {
+		other := graph
+		a, outA := addFIR(gpuDriver.AllocateMemory(context, length*4))
+		graph = driver.NewKernelGraph(context)
+
+		Expect(func() { addFIR(outA, a) }).To(Panic())
+
+		gpuDriver.QueueKernelGraph(other)
+		gpuDriver.DrainKernelGraph(other)
+	})
+})
@@ -0,0 +1,39 @@
+package driver
+
+import (
+	"log"
+
+	"github.com/sarchlab/akita/v4/mem/vm"
+)
+
+// An L2Partitioner restricts the processes to parts of the ways of the L2
+// caches of a GPU. The L2 caches tell the processes apart by the PIDs that the
+// address translators attach to the physical accesses.
+type L2Partitioner interface {
+	SetWayPartition(partitions map[vm.PID]int)
+}
+
+// RegisterL2Partitioner sets the partitioner that controls the L2 caches of
+// the given GPU.
+func (d *Driver) RegisterL2Partitioner(gpuID int, partitioner L2Partitioner) {
+	if d.l2Partitioners == nil {
+		d.l2Partitioners = make(map[int]L2Partitioner)
+	}
+
+	d.l2Partitioners[gpuID] = partitioner
+}
+
+// SetL2Partition gives each process in partitions the given number of ways of
+// every set of the L2 caches of the given GPU, so that the processes cannot
+// evict each other's data. The ways are assigned in the order of the PIDs. The
+// processes that are not in partitions can use all the ways, and passing nil
+// removes the partitioning. The PID of a context is returned by Context.PID.
+func (d *Driver) SetL2Partition(gpuID int, partitions map[vm.PID]int) {
+	partitioner, found := d.l2Partitioners[gpuID]
+	if !found {
+		log.Panicf("GPU %d does not support L2 partitioning", gpuID)
+	}
+
+	partitioner.SetWayPartition(partitions)
+	d.recordL2Partition(gpuID, partitions)
+}
@@ -0,0 +1,40 @@
+package driver
+
+import (
+	"github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/sarchlab/akita/v4/mem/vm"
+)
+
+// fakeL2Partitioner records the last partitioning.
+type fakeL2Partitioner struct {
+	partitions map[vm.PID]int
+}
+
+func (p *fakeL2Partitioner) SetWayPartition(partitions map[vm.PID]int) {
+	p.partitions = partitions
+}
+
+var _ = ginkgo.Describe("L2 Partition", func() {
+	var (
+		driver      *Driver
+		partitioner *fakeL2Partitioner
+	)
+
+	ginkgo.BeforeEach(func() {
+		driver = MakeBuilder().WithLog2PageSize(12).Build("Driver")
+		partitioner = &fakeL2Partitioner{}
+		driver.RegisterL2Partitioner(1, partitioner)
+	})
+
+	ginkgo.It("should partition the L2 caches of a GPU", func() {
+		driver.SetL2Partition(1, map[vm.PID]int{1: 4, 2: 12})
+
+		Expect(partitioner.partitions).To(Equal(map[vm.PID]int{1: 4, 2: 12}))
+	})
+
+	ginkgo.It("should panic if the GPU does not support L2 partitioning",
+		func() {
+			Expect(func() { driver.SetL2Partition(2, nil) }).To(Panic())
+		})
+})
@@ -0,0 +1,93 @@
+package driver
+
+import (
+	"fmt"
+
+	"github.com/sarchlab/mgpusim/v4/amd/driver/internal"
+	"github.com/sarchlab/mgpusim/v4/amd/insts"
+)
+
+// LaunchBounds are the occupancy constraints that a kernel is compiled for,
+// as emitted by the compiler in the launch-bounds metadata. A zero field means
+// that the constraint is not set.
+type LaunchBounds struct {
+	// MaxWorkGroupSize is the maximum number of work-items in a work-group.
+	MaxWorkGroupSize int
+
+	// MinWGPerCU is the minimum number of work-groups that must be able to
+	// reside on a CU at the same time.
+	MinWGPerCU int
+}
+
+// EnqueueKernelWithLaunchBounds schedules a kernel to be launched later, after
+// validating the work-group size and the resources that the kernel uses
+// against the launch bounds. If the launch violates the bounds, nothing is
+// enqueued and an error that names the violated bound is returned. The number
+// of resident work-groups is checked against the CUs of the GPUs that report
+// their occupancy (see RegisterOccupancyReporter); on the other GPUs, only the
+// work-group size is checked. When the kernel runs, each work-group reserves
+// the CU resources of a work-group of the maximum size.
+func (d *Driver) EnqueueKernelWithLaunchBounds(
+	queue *CommandQueue,
+	co *insts.HsaCo,
+	gridSize [3]uint32,
+	wgSize [3]uint16,
+	kernelArgs interface{},
+	bounds LaunchBounds,
+) error {
+	numWI := int(wgSize[0]) * int(wgSize[1]) * int(wgSize[2])
+	if bounds.MaxWorkGroupSize > 0 && numWI > bounds.MaxWorkGroupSize {
+		return fmt.Errorf(
+			"work-group size %d exceeds the launch bound of %d work-items",
+			numWI, bounds.MaxWorkGroupSize)
+	}
+
+	dev := d.devices[queue.GPUID]
+	gpuIDs := []int{queue.GPUID}
+	if dev.Type == internal.DeviceTypeUnifiedGPU {
+		gpuIDs = dev.UnifiedGPUIDs
+	}
+
+	for _, gpuID := range gpuIDs {
+		err := d.checkMinWGPerCU(gpuID, co, numWI, bounds)
+		if err != nil {
+			return err
+		}
+	}
+
+	if dev.Type == internal.DeviceTypeUnifiedGPU {
+		d.enqueueLaunchUnifiedKernel(
+			queue, co, gridSize, wgSize, kernelArgs, nil, bounds)
+		return nil
+	}
+
+	cmd := d.PrepareKernel(queue, co, gridSize, wgSize, kernelArgs)
+	cmd.LaunchBounds = bounds
+	d.Enqueue(queue, cmd)
+
+	return nil
+}
+
+func (d *Driver) checkMinWGPerCU(
+	gpuID int,
+	co *insts.HsaCo,
+	numWI int,
+	bounds LaunchBounds,
+) error {
+	reporter, found := d.occupancyReporter(gpuID)
+	if bounds.MinWGPerCU == 0 || !found {
+		return nil
+	}
+
+	// The work-groups reserve the resources of the maximum size.
+	numWIToReserve := max(numWI, bounds.MaxWorkGroupSize)
+	numWG := reporter.MaxResidentWGPerCU(co, numWIToReserve)
+	if numWG < bounds.MinWGPerCU {
+		return fmt.Errorf(
+			"only %d work-groups of %d work-items fit in a CU of GPU %d, "+
+				"fewer than the launch bound of %d work-groups per CU",
+			numWG, numWIToReserve, gpuID, bounds.MinWGPerCU)
+	}
+
+	return nil
+}
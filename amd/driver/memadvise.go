@@ -0,0 +1,141 @@
+package driver
+
+import (
+	"github.com/sarchlab/akita/v4/mem/vm"
+	"github.com/sarchlab/akita/v4/sim"
+)
+
+// MemAdvice tells the driver how the pages of a unified buffer are going to
+// be accessed, so that the driver can decide where to migrate them when a GPU
+// that does not hold them accesses them. A GPU that accesses a page without
+// migrating it accesses the page remotely.
+type MemAdvice struct {
+	// PreferredLocation is the ID of the GPU that should hold the pages. The
+	// pages that are migrated are migrated to this GPU rather than to the GPU
+	// that accesses them, and the pages that are on this GPU are not migrated.
+	// 0 means that there is no preferred location.
+	PreferredLocation int
+
+	// ReadMostly tells that the pages are mostly read. The driver does not
+	// duplicate pages, so the pages are not migrated and all the GPUs read
+	// them from where they are.
+	ReadMostly bool
+
+	// AccessedBy lists the GPUs that access the pages remotely. The pages are
+	// not migrated when these GPUs access them.
+	AccessedBy []int
+}
+
+type memAdviceRange struct {
+	vAddr, size uint64
+	advice      MemAdvice
+}
+
+// A MemAdviseCommand is a command that sets the advice of a virtual address
+// range when the command queue reaches it.
+type MemAdviseCommand struct {
+	ID     string
+	Ptr    Ptr
+	Size   uint64
+	Advice MemAdvice
+}
+
+// GetID returns the ID of the command
+func (c *MemAdviseCommand) GetID() string {
+	return c.ID
+}
+
+// GetReqs returns the request associated with the command
+func (c *MemAdviseCommand) GetReqs() []sim.Msg {
+	return nil
+}
+
+// AddReq adds a request to the request list associated with the command
+func (c *MemAdviseCommand) AddReq(req sim.Msg) {
+	// No action
+}
+
+// RemoveReq removes a request from the request list associated with the
+// command.
+func (c *MemAdviseCommand) RemoveReq(req sim.Msg) {
+	// No action
+}
+
+// EnqueueMemAdvise registers a MemAdviseCommand in the queue. The advice
+// replaces the earlier advice of the overlapping addresses. It takes effect on
+// the migrations that start after the queue reaches the command, and it does
+// not move the pages that are already placed.
+func (d *Driver) EnqueueMemAdvise(
+	queue *CommandQueue,
+	ptr Ptr,
+	size uint64,
+	advice MemAdvice,
+) {
+	if advice.PreferredLocation != 0 {
+		d.mustBeValidGPUID(advice.PreferredLocation)
+	}
+
+	for _, gpuID := range advice.AccessedBy {
+		d.mustBeValidGPUID(gpuID)
+	}
+
+	d.Enqueue(queue, &MemAdviseCommand{
+		ID:     sim.GetIDGenerator().Generate(),
+		Ptr:    ptr,
+		Size:   size,
+		Advice: advice,
+	})
+}
+
+func (d *Driver) processMemAdviseCommand(
+	cmd *MemAdviseCommand,
+	queue *CommandQueue,
+) bool {
+	ctx := queue.Context
+	ctx.memAdvices = append(ctx.memAdvices, memAdviceRange{
+		vAddr:  uint64(cmd.Ptr),
+		size:   cmd.Size,
+		advice: cmd.Advice,
+	})
+
+	queue.Dequeue()
+	d.logCmdComplete(cmd)
+
+	return true
+}
+
+func (c *Context) memAdviceOf(vAddr uint64) (MemAdvice, bool) {
+	for i := len(c.memAdvices) - 1; i >= 0; i-- {
+		r := c.memAdvices[i]
+		if vAddr >= r.vAddr && vAddr < r.vAddr+r.size {
+			return r.advice, true
+		}
+	}
+
+	return MemAdvice{}, false
+}
+
+// migrationTarget returns the ID of the GPU that the page should be migrated
+// to when the given GPU accesses it.
+func migrationTarget(ctx *Context, page vm.Page, accessingGPU int) int {
+	advice, found := ctx.memAdviceOf(page.VAddr)
+	if !found {
+		return accessingGPU
+	}
+
+	if advice.ReadMostly {
+		return int(page.DeviceID)
+	}
+
+	for _, gpuID := range advice.AccessedBy {
+		if gpuID == accessingGPU {
+			return int(page.DeviceID)
+		}
+	}
+
+	if advice.PreferredLocation != 0 {
+		return advice.PreferredLocation
+	}
+
+	return accessingGPU
+}
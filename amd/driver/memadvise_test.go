@@ -0,0 +1,66 @@
+package driver
+
+import (
+	"github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/sarchlab/akita/v4/mem/vm"
+)
+
+var _ = ginkgo.Describe("Mem Advise", func() {
+	var (
+		ctx  *Context
+		page vm.Page
+	)
+
+	ginkgo.BeforeEach(func() {
+		ctx = &Context{}
+		page = vm.Page{VAddr: 0x1000, PageSize: 0x1000, DeviceID: 1}
+	})
+
+	advise := func(vAddr, size uint64, advice MemAdvice) {
+		ctx.memAdvices = append(ctx.memAdvices, memAdviceRange{
+			vAddr:  vAddr,
+			size:   size,
+			advice: advice,
+		})
+	}
+
+	ginkgo.It("should migrate to the accessing GPU without advice", func() {
+		Expect(migrationTarget(ctx, page, 2)).To(Equal(2))
+	})
+
+	ginkgo.It("should ignore the advice of other addresses", func() {
+		advise(0x2000, 0x1000, MemAdvice{PreferredLocation: 3})
+
+		Expect(migrationTarget(ctx, page, 2)).To(Equal(2))
+	})
+
+	ginkgo.It("should migrate to the preferred location", func() {
+		advise(0x1000, 0x1000, MemAdvice{PreferredLocation: 3})
+
+		Expect(migrationTarget(ctx, page, 2)).To(Equal(3))
+	})
+
+	ginkgo.It("should keep the pages that are read mostly", func() {
+		advise(0x1000, 0x1000, MemAdvice{
+			PreferredLocation: 3,
+			ReadMostly:        true,
+		})
+
+		Expect(migrationTarget(ctx, page, 2)).To(Equal(1))
+	})
+
+	ginkgo.It("should keep the pages that the GPU accesses remotely", func() {
+		advise(0x1000, 0x1000, MemAdvice{AccessedBy: []int{2}})
+
+		Expect(migrationTarget(ctx, page, 2)).To(Equal(1))
+		Expect(migrationTarget(ctx, page, 3)).To(Equal(3))
+	})
+
+	ginkgo.It("should follow the latest advice", func() {
+		advise(0x0, 0x4000, MemAdvice{PreferredLocation: 3})
+		advise(0x1000, 0x1000, MemAdvice{PreferredLocation: 4})
+
+		Expect(migrationTarget(ctx, page, 2)).To(Equal(4))
+	})
+})
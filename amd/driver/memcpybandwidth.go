@@ -0,0 +1,50 @@
+package driver
+
+import (
+	"log"
+)
+
+// SetMemcpyBandwidthCap limits the bandwidth of the memory copies between the
+// host and the given GPU to bytesPerSec. A copy does not complete before the
+// bytes that it moves to or from the GPU could pass through a link of the
+// given bandwidth, which models a host link that is slower than the DMA
+// engine. The cap applies to the link of the GPU rather than to each copy, so
+// concurrent copies to the same GPU share the bandwidth. A cap of 0 removes
+// the limit. The cap has no effect when the driver uses the magic memory copy
+// middleware.
+func (d *Driver) SetMemcpyBandwidthCap(gpuID int, bytesPerSec float64) {
+	if gpuID < 1 || gpuID > len(d.GPUs) {
+		log.Panicf("GPU %d does not exist", gpuID)
+	}
+
+	if bytesPerSec < 0 {
+		log.Panicf("invalid memory copy bandwidth cap %f", bytesPerSec)
+	}
+
+	d.recordGPU("set_memcpy_bandwidth_cap", gpuID,
+		recordedCall{Bandwidth: bytesPerSec})
+
+	d.memcpyBandwidthMutex.Lock()
+	defer d.memcpyBandwidthMutex.Unlock()
+
+	if d.memcpyBandwidthCaps == nil {
+		d.memcpyBandwidthCaps = make(map[int]float64)
+	}
+
+	if bytesPerSec == 0 {
+		delete(d.memcpyBandwidthCaps, gpuID)
+		return
+	}
+
+	d.memcpyBandwidthCaps[gpuID] = bytesPerSec
+}
+
+// GetMemcpyBandwidthCap returns the bandwidth cap of the memory copies between
+// the host and the given GPU in bytes per second. It returns 0 if the copies
+// are not limited.
+func (d *Driver) GetMemcpyBandwidthCap(gpuID int) float64 {
+	d.memcpyBandwidthMutex.RLock()
+	defer d.memcpyBandwidthMutex.RUnlock()
+
+	return d.memcpyBandwidthCaps[gpuID]
+}
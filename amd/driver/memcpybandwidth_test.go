@@ -0,0 +1,42 @@
+package driver
+
+import (
+	"github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/sarchlab/akita/v4/sim"
+)
+
+var _ = ginkgo.Describe("Memory Copy Bandwidth Cap", func() {
+	var driver *Driver
+
+	ginkgo.BeforeEach(func() {
+		driver = MakeBuilder().WithLog2PageSize(12).Build("Driver")
+		driver.GPUs = make([]sim.Port, 2)
+	})
+
+	ginkgo.It("should not limit the copies by default", func() {
+		Expect(driver.GetMemcpyBandwidthCap(1)).To(BeZero())
+	})
+
+	ginkgo.It("should set the cap of each GPU", func() {
+		driver.SetMemcpyBandwidthCap(2, 1e8)
+
+		Expect(driver.GetMemcpyBandwidthCap(1)).To(BeZero())
+		Expect(driver.GetMemcpyBandwidthCap(2)).To(Equal(1e8))
+	})
+
+	ginkgo.It("should remove the cap if the cap is 0", func() {
+		driver.SetMemcpyBandwidthCap(1, 1e8)
+		driver.SetMemcpyBandwidthCap(1, 0)
+
+		Expect(driver.GetMemcpyBandwidthCap(1)).To(BeZero())
+	})
+
+	ginkgo.It("should panic if the GPU does not exist", func() {
+		Expect(func() { driver.SetMemcpyBandwidthCap(3, 1e8) }).To(Panic())
+	})
+
+	ginkgo.It("should panic if the cap is negative", func() {
+		Expect(func() { driver.SetMemcpyBandwidthCap(1, -1) }).To(Panic())
+	})
+})
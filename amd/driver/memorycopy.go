@@ -13,11 +13,24 @@ import (
 type defaultMemoryCopyMiddleware struct {
 	driver *Driver
 
-	cyclesPerH2D int
-	cyclesPerD2H int
-	cyclesLeft   int
+	cyclesPerH2D         int
+	cyclesPerD2H         int
+	stagingBytesPerCycle int
+	cyclesLeft           int
 
 	awaitingReqs []sim.Msg
+
+	copyDeadlines   map[Command]sim.VTimeInSec
+	throttledCopies []throttledCopy
+	linkFreeTimes   map[int]sim.VTimeInSec
+}
+
+// throttledCopy is a memory copy that the GPUs have finished but that cannot
+// complete before its deadline because of the memory copy bandwidth cap.
+type throttledCopy struct {
+	cmd      Command
+	queue    *CommandQueue
+	deadline sim.VTimeInSec
 }
 
 func (m *defaultMemoryCopyMiddleware) ProcessCommand(
@@ -38,7 +51,8 @@ func (m *defaultMemoryCopyMiddleware) processMemCopyH2DCommand(
 	cmd *MemCopyH2DCommand,
 	queue *CommandQueue,
 ) bool {
-	if m.needFlushing(queue.Context, cmd.Dst, uint64(binary.Size(cmd.Src))) {
+	if !cmd.ThroughCache &&
+		m.needFlushing(queue.Context, cmd.Dst, uint64(binary.Size(cmd.Src))) {
 		m.sendFlushRequest(cmd)
 	}
 
@@ -52,6 +66,7 @@ func (m *defaultMemoryCopyMiddleware) processMemCopyH2DCommand(
 	offset := uint64(0)
 	addr := uint64(cmd.Dst)
 	sizeLeft := uint64(len(rawBytes))
+	bytesPerGPU := make(map[int]uint64)
 	for sizeLeft > 0 {
 		page, found := m.driver.pageTable.Find(queue.Context.pid, addr)
 		if !found {
@@ -66,10 +81,12 @@ func (m *defaultMemoryCopyMiddleware) processMemCopyH2DCommand(
 		}
 
 		gpuID := m.driver.memAllocator.GetDeviceIDByPAddr(pAddr)
+		bytesPerGPU[gpuID] += sizeToCopy
 		req := protocol.NewMemCopyH2DReq(
 			m.driver.gpuPort, m.driver.GPUs[gpuID-1],
 			rawBytes[offset:offset+sizeToCopy],
 			pAddr)
+		req.ThroughCache = cmd.ThroughCache
 		cmd.Reqs = append(cmd.Reqs, req)
 		m.awaitingReqs = append(m.awaitingReqs, req)
 		// m.driver.requestsToSend = append(m.driver.requestsToSend, req)
@@ -81,13 +98,72 @@ func (m *defaultMemoryCopyMiddleware) processMemCopyH2DCommand(
 		m.driver.logTaskToGPUInitiate(cmd, req)
 	}
 
-	m.cyclesLeft = m.cyclesPerH2D
+	m.cyclesLeft = m.cyclesPerH2D + m.stagingCycles(cmd, len(rawBytes))
+	m.setCopyDeadline(cmd, bytesPerGPU)
 
 	queue.IsRunning = true
 
 	return true
 }
 
+// stagingCycles returns the number of cycles to copy the source of a copy from
+// pageable host memory to a pinned staging buffer.
+func (m *defaultMemoryCopyMiddleware) stagingCycles(
+	cmd *MemCopyH2DCommand,
+	numBytes int,
+) int {
+	if !cmd.Pageable || m.stagingBytesPerCycle == 0 {
+		return 0
+	}
+
+	return (numBytes + m.stagingBytesPerCycle - 1) / m.stagingBytesPerCycle
+}
+
+// setCopyDeadline records the earliest time that a copy can complete under the
+// memory copy bandwidth caps of the GPUs that it touches. The bytes of a copy
+// can only start to pass through the link of a GPU after the bytes of the
+// earlier copies to the same GPU have passed, so that concurrent copies share
+// the capped bandwidth.
+func (m *defaultMemoryCopyMiddleware) setCopyDeadline(
+	cmd Command,
+	bytesPerGPU map[int]uint64,
+) {
+	deadline := sim.VTimeInSec(0)
+
+	for gpuID, numBytes := range bytesPerGPU {
+		bandwidthCap := m.driver.GetMemcpyBandwidthCap(gpuID)
+		if bandwidthCap == 0 {
+			continue
+		}
+
+		if m.linkFreeTimes == nil {
+			m.linkFreeTimes = make(map[int]sim.VTimeInSec)
+		}
+
+		start := m.driver.CurrentTime()
+		if m.linkFreeTimes[gpuID] > start {
+			start = m.linkFreeTimes[gpuID]
+		}
+
+		end := start + sim.VTimeInSec(float64(numBytes)/bandwidthCap)
+		m.linkFreeTimes[gpuID] = end
+
+		if end > deadline {
+			deadline = end
+		}
+	}
+
+	if deadline == 0 {
+		return
+	}
+
+	if m.copyDeadlines == nil {
+		m.copyDeadlines = make(map[Command]sim.VTimeInSec)
+	}
+
+	m.copyDeadlines[cmd] = m.driver.Freq.ThisTick(deadline)
+}
+
 func (m *defaultMemoryCopyMiddleware) processMemCopyD2HCommand(
 	cmd *MemCopyD2HCommand,
 	queue *CommandQueue,
@@ -102,6 +178,7 @@ func (m *defaultMemoryCopyMiddleware) processMemCopyD2HCommand(
 	offset := uint64(0)
 	addr := uint64(cmd.Src)
 	sizeLeft := uint64(len(cmd.RawData))
+	bytesPerGPU := make(map[int]uint64)
 	for sizeLeft > 0 {
 		page, found := m.driver.pageTable.Find(queue.Context.pid, addr)
 		if !found {
@@ -116,6 +193,7 @@ func (m *defaultMemoryCopyMiddleware) processMemCopyD2HCommand(
 		}
 
 		gpuID := m.driver.memAllocator.GetDeviceIDByPAddr(pAddr)
+		bytesPerGPU[gpuID] += sizeToCopy
 		req := protocol.NewMemCopyD2HReq(
 			m.driver.gpuPort, m.driver.GPUs[gpuID-1],
 			pAddr, cmd.RawData[offset:offset+sizeToCopy])
@@ -131,6 +209,7 @@ func (m *defaultMemoryCopyMiddleware) processMemCopyD2HCommand(
 	}
 
 	m.cyclesLeft = m.cyclesPerD2H
+	m.setCopyDeadline(cmd, bytesPerGPU)
 
 	queue.IsRunning = true
 	return true
@@ -195,6 +274,8 @@ func (m *defaultMemoryCopyMiddleware) Tick() (madeProgress bool) {
 		madeProgress = true
 	}
 
+	madeProgress = m.completeThrottledCopies() || madeProgress
+
 	req := m.driver.gpuPort.PeekIncoming()
 	if req == nil {
 		return madeProgress
@@ -245,10 +326,7 @@ func (m *defaultMemoryCopyMiddleware) processMemCopyH2DReturn(
 	copyCmd.Reqs = newReqs
 
 	if len(copyCmd.Reqs) == 0 {
-		cmdQueue.IsRunning = false
-		cmdQueue.Dequeue()
-
-		m.driver.logCmdComplete(cmd)
+		m.completeOrThrottleCopy(copyCmd, cmdQueue)
 	}
 
 	return true
@@ -267,19 +345,80 @@ func (m *defaultMemoryCopyMiddleware) processMemCopyD2HReturn(
 	copyCmd.RemoveReq(req)
 
 	if len(copyCmd.Reqs) == 0 {
-		cmdQueue.IsRunning = false
-		buf := bytes.NewReader(copyCmd.RawData)
-		err := binary.Read(buf, binary.LittleEndian, copyCmd.Dst)
+		m.completeOrThrottleCopy(copyCmd, cmdQueue)
+	}
+
+	return true
+}
+
+// completeOrThrottleCopy completes a copy that the GPUs have finished. If the
+// copy is faster than the memory copy bandwidth cap allows, the completion is
+// postponed to the deadline of the copy.
+func (m *defaultMemoryCopyMiddleware) completeOrThrottleCopy(
+	cmd Command,
+	queue *CommandQueue,
+) {
+	deadline, throttled := m.copyDeadlines[cmd]
+	delete(m.copyDeadlines, cmd)
+
+	if throttled && deadline > m.driver.CurrentTime() {
+		m.throttledCopies = append(m.throttledCopies,
+			throttledCopy{cmd: cmd, queue: queue, deadline: deadline})
+		m.driver.Engine.Schedule(sim.MakeTickEvent(m.driver, deadline))
+
+		return
+	}
+
+	m.completeCopy(cmd, queue)
+}
+
+func (m *defaultMemoryCopyMiddleware) completeThrottledCopies() bool {
+	if len(m.throttledCopies) == 0 {
+		return false
+	}
+
+	now := m.driver.CurrentTime()
+	madeProgress := false
+
+	remaining := m.throttledCopies[:0]
+	for _, c := range m.throttledCopies {
+		if c.deadline > now {
+			remaining = append(remaining, c)
+			continue
+		}
+
+		m.completeCopy(c.cmd, c.queue)
+		madeProgress = true
+	}
+	m.throttledCopies = remaining
+
+	return madeProgress
+}
+
+func (m *defaultMemoryCopyMiddleware) completeCopy(
+	cmd Command,
+	queue *CommandQueue,
+) {
+	queue.IsRunning = false
+
+	switch cmd := cmd.(type) {
+	case *MemCopyH2DCommand:
+		queue.Dequeue()
+		m.driver.logCmdComplete(cmd)
+
+		if cmd.OnComplete != nil {
+			cmd.OnComplete()
+		}
+	case *MemCopyD2HCommand:
+		buf := bytes.NewReader(cmd.RawData)
+		err := binary.Read(buf, binary.LittleEndian, cmd.Dst)
 		if err != nil {
 			panic(err)
 		}
 
-		cmdQueue.Dequeue()
-
-		m.driver.logCmdComplete(copyCmd)
+		queue.Dequeue()
+		m.driver.logCmdComplete(cmd)
 	}
-
-	return true
 }
 
 func (m *defaultMemoryCopyMiddleware) processFlushReturn(
@@ -1,10 +1,108 @@
 package driver
 
 import (
+	"github.com/golang/mock/gomock"
 	"github.com/onsi/ginkgo/v2"
-	// . "github.com/onsi/gomega"
+	. "github.com/onsi/gomega"
+	"github.com/sarchlab/akita/v4/sim"
 )
 
 var _ = ginkgo.Describe("Defaultmemorycopymiddleware", func() {
+	var m *defaultMemoryCopyMiddleware
 
+	ginkgo.BeforeEach(func() {
+		m = &defaultMemoryCopyMiddleware{stagingBytesPerCycle: 16}
+	})
+
+	ginkgo.It("should not stage a pinned source", func() {
+		cmd := &MemCopyH2DCommand{}
+
+		Expect(m.stagingCycles(cmd, 1024)).To(Equal(0))
+	})
+
+	ginkgo.It("should stage a pageable source", func() {
+		cmd := &MemCopyH2DCommand{Pageable: true}
+
+		Expect(m.stagingCycles(cmd, 1024)).To(Equal(64))
+		Expect(m.stagingCycles(cmd, 1025)).To(Equal(65))
+	})
+
+	ginkgo.It("should not stage if the staging bandwidth is not set", func() {
+		m.stagingBytesPerCycle = 0
+		cmd := &MemCopyH2DCommand{Pageable: true}
+
+		Expect(m.stagingCycles(cmd, 1024)).To(Equal(0))
+	})
+
+	ginkgo.Context("with a bandwidth cap", func() {
+		var (
+			mockCtrl *gomock.Controller
+			engine   *MockEngine
+			now      sim.VTimeInSec
+		)
+
+		ginkgo.BeforeEach(func() {
+			mockCtrl = gomock.NewController(ginkgo.GinkgoT())
+			engine = NewMockEngine(mockCtrl)
+			engine.EXPECT().CurrentTime().
+				DoAndReturn(func() sim.VTimeInSec { return now }).
+				AnyTimes()
+
+			now = 0
+			m.driver = MakeBuilder().
+				WithEngine(engine).
+				WithLog2PageSize(12).
+				Build("Driver")
+			m.driver.GPUs = make([]sim.Port, 2)
+			m.driver.SetMemcpyBandwidthCap(1, 1e8)
+		})
+
+		ginkgo.AfterEach(func() {
+			mockCtrl.Finish()
+		})
+
+		ginkgo.It("should not limit the copies to a GPU without a cap",
+			func() {
+				cmd := &MemCopyH2DCommand{}
+
+				m.setCopyDeadline(cmd, map[int]uint64{2: 1e6})
+
+				Expect(m.copyDeadlines).NotTo(HaveKey(cmd))
+			})
+
+		ginkgo.It("should share the capped link among the copies", func() {
+			first := &MemCopyH2DCommand{}
+			second := &MemCopyD2HCommand{}
+
+			m.setCopyDeadline(first, map[int]uint64{1: 1e6, 2: 1e6})
+			m.setCopyDeadline(second, map[int]uint64{1: 1e6})
+
+			Expect(float64(m.copyDeadlines[first])).
+				To(BeNumerically("~", 0.01))
+			Expect(float64(m.copyDeadlines[second])).
+				To(BeNumerically("~", 0.02))
+		})
+
+		ginkgo.It("should complete a copy at its deadline", func() {
+			numCompleted := 0
+			cmd := &MemCopyH2DCommand{OnComplete: func() { numCompleted++ }}
+			queue := &CommandQueue{}
+			queue.Enqueue(cmd)
+			queue.IsRunning = true
+			m.setCopyDeadline(cmd, map[int]uint64{1: 1e6})
+
+			engine.EXPECT().Schedule(gomock.AssignableToTypeOf(sim.TickEvent{}))
+			m.completeOrThrottleCopy(cmd, queue)
+
+			Expect(m.completeThrottledCopies()).To(BeFalse())
+			Expect(numCompleted).To(Equal(0))
+			Expect(queue.IsRunning).To(BeTrue())
+
+			now = 0.01
+			Expect(m.completeThrottledCopies()).To(BeTrue())
+			Expect(numCompleted).To(Equal(1))
+			Expect(queue.IsRunning).To(BeFalse())
+			Expect(queue.NumCommand()).To(Equal(0))
+		})
+	})
 })
@@ -62,6 +62,10 @@ func (m *globalStorageMemoryCopyMiddleware) processMemCopyH2DCommand(
 	queue.IsRunning = false
 	queue.Dequeue()
 
+	if cmd.OnComplete != nil {
+		cmd.OnComplete()
+	}
+
 	return true
 }
 
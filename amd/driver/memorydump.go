@@ -0,0 +1,49 @@
+package driver
+
+import (
+	"fmt"
+	"io"
+)
+
+// DumpDeviceMemory writes size bytes of the device memory starting from ptr to
+// w. The data is read from the storage directly rather than by the DMA
+// engines, so the dump does not take simulated time and works even if the
+// engine is not running. Data that is held in dirty cache lines and has not
+// been written back to the memory is not included. An error is returned if
+// part of the range is not mapped, after the bytes before it are written.
+func (d *Driver) DumpDeviceMemory(
+	ctx *Context,
+	ptr Ptr,
+	size uint64,
+	w io.Writer,
+) error {
+	addr := uint64(ptr)
+	sizeLeft := size
+	for sizeLeft > 0 {
+		page, found := d.pageTable.Find(ctx.pid, addr)
+		if !found {
+			return fmt.Errorf("address 0x%x is not mapped", addr)
+		}
+
+		pAddr := page.PAddr + (addr - page.VAddr)
+		sizeLeftInPage := page.PageSize - (addr - page.VAddr)
+		sizeToDump := sizeLeftInPage
+		if sizeLeft < sizeLeftInPage {
+			sizeToDump = sizeLeft
+		}
+
+		data, err := d.globalStorage.Read(pAddr, sizeToDump)
+		if err != nil {
+			return err
+		}
+
+		if _, err := w.Write(data); err != nil {
+			return err
+		}
+
+		sizeLeft -= sizeToDump
+		addr += sizeToDump
+	}
+
+	return nil
+}
@@ -0,0 +1,55 @@
+package driver_test
+
+import (
+	"bytes"
+
+	"github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/sarchlab/mgpusim/v4/amd/driver"
+	"github.com/sarchlab/mgpusim/v4/amd/samples/runner"
+)
+
+var _ = ginkgo.Describe("Test Device Memory Dump", func() {
+	var (
+		gpuDriver *driver.Driver
+		context   *driver.Context
+	)
+
+	ginkgo.BeforeEach(func() {
+		platform := runner.MakeEmuBuilder().
+			WithNumGPU(1).
+			Build()
+		gpuDriver = platform.Driver
+		gpuDriver.Run()
+		context = gpuDriver.Init()
+	})
+
+	ginkgo.It("should dump the memory after the driver terminates", func() {
+		data := make([]byte, 10000)
+		for i := range data {
+			data[i] = byte(i * 13)
+		}
+
+		ptr := gpuDriver.AllocateMemory(context, uint64(len(data)))
+		gpuDriver.MemCopyH2D(context, ptr, data)
+		gpuDriver.Terminate()
+
+		buf := bytes.NewBuffer(nil)
+		err := gpuDriver.DumpDeviceMemory(
+			context, ptr+100, uint64(len(data)-100), buf)
+
+		Expect(err).To(BeNil())
+		Expect(buf.Bytes()).To(Equal(data[100:]))
+	})
+
+	ginkgo.It("should return an error if the range is not mapped", func() {
+		ptr := gpuDriver.AllocateMemory(context, 4096)
+		gpuDriver.Terminate()
+
+		buf := bytes.NewBuffer(nil)
+		err := gpuDriver.DumpDeviceMemory(context, ptr+1<<30, 16, buf)
+
+		Expect(err).To(HaveOccurred())
+		Expect(buf.Len()).To(Equal(0))
+	})
+})
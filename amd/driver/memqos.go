@@ -0,0 +1,131 @@
+package driver
+
+import (
+	"log"
+
+	"github.com/sarchlab/akita/v4/mem/vm"
+)
+
+// A MemQoSClass tells the DRAM controllers how urgent the accesses to a buffer
+// are.
+type MemQoSClass int
+
+// A list of all supported memory QoS classes.
+const (
+	// MemQoSBandwidth is the class of the buffers whose accesses only need
+	// throughput. It is the class of all the buffers by default.
+	MemQoSBandwidth MemQoSClass = iota
+
+	// MemQoSLatencyCritical is the class of the buffers whose accesses the
+	// kernels are waiting for. The DRAM controllers with QoS scheduling serve
+	// them before the other accesses.
+	MemQoSLatencyCritical
+)
+
+// A MemQoSController sets the QoS class of the accesses to a physical address
+// range in the DRAM controllers of a GPU.
+type MemQoSController interface {
+	SetQoSClass(pAddr, size uint64, class MemQoSClass)
+}
+
+// RegisterMemQoSController sets the component that sets the QoS classes in the
+// DRAM controllers of the given GPU.
+func (d *Driver) RegisterMemQoSController(gpuID int, c MemQoSController) {
+	if d.memQoSControllers == nil {
+		d.memQoSControllers = make(map[int]MemQoSController)
+	}
+
+	d.memQoSControllers[gpuID] = c
+}
+
+// memQoSPage is a physical page that has a QoS class other than
+// MemQoSBandwidth.
+type memQoSPage struct {
+	deviceID uint64
+	pageSize uint64
+	class    MemQoSClass
+}
+
+// SetMemQoSClass sets the QoS class of the accesses to the buffer. The class
+// is set for the whole physical pages that currently back the buffer, on the
+// GPUs that hold the pages. The class follows the pages when they are
+// migrated, and it is dropped when the buffer is freed.
+func (d *Driver) SetMemQoSClass(
+	ctx *Context,
+	ptr Ptr,
+	size uint64,
+	class MemQoSClass,
+) {
+	d.recordContext("set_mem_qos", ctx,
+		recordedCall{Ptr: ptr, Size: size, QoSClass: class})
+
+	d.memQoSMutex.Lock()
+	defer d.memQoSMutex.Unlock()
+
+	for _, page := range d.GetPhysicalPages(ctx.pid, uint64(ptr), size) {
+		d.setPageMemQoSClass(page.DeviceID, page.PAddr, page.PageSize, class)
+	}
+}
+
+func (d *Driver) setPageMemQoSClass(
+	deviceID, pAddr, pageSize uint64,
+	class MemQoSClass,
+) {
+	c, found := d.memQoSControllers[int(deviceID)]
+	if !found {
+		log.Panicf("GPU %d does not have a registered memory QoS "+
+			"controller", deviceID)
+	}
+
+	c.SetQoSClass(pAddr, pageSize, class)
+
+	if class == MemQoSBandwidth {
+		delete(d.memQoSPages, pAddr)
+		return
+	}
+
+	if d.memQoSPages == nil {
+		d.memQoSPages = make(map[uint64]memQoSPage)
+	}
+
+	d.memQoSPages[pAddr] = memQoSPage{
+		deviceID: deviceID,
+		pageSize: pageSize,
+		class:    class,
+	}
+}
+
+// resetMemQoSClass drops the QoS class of the physical pages that back the
+// buffer, so that the memory does not keep the class after it is reused.
+func (d *Driver) resetMemQoSClass(ctx *Context, ptr Ptr, size uint64) {
+	d.memQoSMutex.Lock()
+	defer d.memQoSMutex.Unlock()
+
+	if len(d.memQoSPages) == 0 {
+		return
+	}
+
+	for _, page := range d.GetPhysicalPages(ctx.pid, uint64(ptr), size) {
+		if _, found := d.memQoSPages[page.PAddr]; found {
+			d.setPageMemQoSClass(page.DeviceID, page.PAddr, page.PageSize,
+				MemQoSBandwidth)
+		}
+	}
+}
+
+// moveMemQoSClass moves the QoS class of a page that is migrated from the old
+// physical address to the new one.
+func (d *Driver) moveMemQoSClass(oldPAddr uint64, newPage vm.Page) {
+	d.memQoSMutex.Lock()
+	defer d.memQoSMutex.Unlock()
+
+	old, found := d.memQoSPages[oldPAddr]
+	if !found {
+		return
+	}
+
+	d.setPageMemQoSClass(old.deviceID, oldPAddr, old.pageSize,
+		MemQoSBandwidth)
+	d.setPageMemQoSClass(newPage.DeviceID, newPage.PAddr, newPage.PageSize,
+		old.class)
+}
@@ -77,15 +77,15 @@ func (mr *MockMemoryAllocatorMockRecorder) AllocateUnified(arg0, arg1 interface{
 }
 
 // Free mocks base method.
-func (m *MockMemoryAllocator) Free(arg0 uint64) {
+func (m *MockMemoryAllocator) Free(arg0 vm.PID, arg1, arg2 uint64) {
 	m.ctrl.T.Helper()
-	m.ctrl.Call(m, "Free", arg0)
+	m.ctrl.Call(m, "Free", arg0, arg1, arg2)
 }
 
 // Free indicates an expected call of Free.
-func (mr *MockMemoryAllocatorMockRecorder) Free(arg0 interface{}) *gomock.Call {
+func (mr *MockMemoryAllocatorMockRecorder) Free(arg0, arg1, arg2 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Free", reflect.TypeOf((*MockMemoryAllocator)(nil).Free), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Free", reflect.TypeOf((*MockMemoryAllocator)(nil).Free), arg0, arg1, arg2)
 }
 
 // GetDeviceIDByPAddr mocks base method.
@@ -102,6 +102,20 @@ func (mr *MockMemoryAllocatorMockRecorder) GetDeviceIDByPAddr(arg0 interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeviceIDByPAddr", reflect.TypeOf((*MockMemoryAllocator)(nil).GetDeviceIDByPAddr), arg0)
 }
 
+// Move mocks base method.
+func (m *MockMemoryAllocator) Move(arg0 vm.PID, arg1, arg2 uint64, arg3 int) []uint64 {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Move", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].([]uint64)
+	return ret0
+}
+
+// Move indicates an expected call of Move.
+func (mr *MockMemoryAllocatorMockRecorder) Move(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Move", reflect.TypeOf((*MockMemoryAllocator)(nil).Move), arg0, arg1, arg2, arg3)
+}
+
 // RegisterDevice mocks base method.
 func (m *MockMemoryAllocator) RegisterDevice(arg0 *internal.Device) {
 	m.ctrl.T.Helper()
@@ -127,13 +141,13 @@ func (mr *MockMemoryAllocatorMockRecorder) Remap(arg0, arg1, arg2, arg3 interfac
 }
 
 // RemovePage mocks base method.
-func (m *MockMemoryAllocator) RemovePage(arg0 uint64) {
+func (m *MockMemoryAllocator) RemovePage(arg0 vm.PID, arg1 uint64) {
 	m.ctrl.T.Helper()
-	m.ctrl.Call(m, "RemovePage", arg0)
+	m.ctrl.Call(m, "RemovePage", arg0, arg1)
 }
 
 // RemovePage indicates an expected call of RemovePage.
-func (mr *MockMemoryAllocatorMockRecorder) RemovePage(arg0 interface{}) *gomock.Call {
+func (mr *MockMemoryAllocatorMockRecorder) RemovePage(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemovePage", reflect.TypeOf((*MockMemoryAllocator)(nil).RemovePage), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemovePage", reflect.TypeOf((*MockMemoryAllocator)(nil).RemovePage), arg0, arg1)
 }
@@ -0,0 +1,55 @@
+package driver
+
+import (
+	"log"
+
+	"github.com/sarchlab/mgpusim/v4/amd/insts"
+	"github.com/sarchlab/mgpusim/v4/amd/protocol"
+)
+
+// An OccupancyReporter reports how the work-groups occupy the CUs of a GPU.
+type OccupancyReporter interface {
+	GetOccupancy() []protocol.CUOccupancy
+
+	// MaxResidentWGPerCU returns how many work-groups of the kernel, each with
+	// the given number of work-items, can be resident on a CU at the same
+	// time.
+	MaxResidentWGPerCU(co *insts.HsaCo, numWI int) int
+}
+
+// RegisterOccupancyReporter sets the component that reports the occupancy of
+// the CUs of the given GPU.
+func (d *Driver) RegisterOccupancyReporter(
+	gpuID int,
+	reporter OccupancyReporter,
+) {
+	d.occupancyMutex.Lock()
+	defer d.occupancyMutex.Unlock()
+
+	if d.occupancyReporters == nil {
+		d.occupancyReporters = make(map[int]OccupancyReporter)
+	}
+
+	d.occupancyReporters[gpuID] = reporter
+}
+
+// GetOccupancy returns the number of resident work-groups and wavefronts of
+// each CU in the given GPU, together with the resource that limits the number
+// of resident work-groups.
+func (d *Driver) GetOccupancy(gpuID int) []protocol.CUOccupancy {
+	reporter, found := d.occupancyReporter(gpuID)
+	if !found {
+		log.Panicf("GPU %d does not report occupancy", gpuID)
+	}
+
+	return reporter.GetOccupancy()
+}
+
+func (d *Driver) occupancyReporter(gpuID int) (OccupancyReporter, bool) {
+	d.occupancyMutex.RLock()
+	defer d.occupancyMutex.RUnlock()
+
+	reporter, found := d.occupancyReporters[gpuID]
+
+	return reporter, found
+}
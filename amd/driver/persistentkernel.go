@@ -0,0 +1,129 @@
+package driver
+
+import (
+	"log"
+
+	"github.com/sarchlab/akita/v4/sim"
+	"github.com/sarchlab/mgpusim/v4/amd/driver/internal"
+	"github.com/sarchlab/mgpusim/v4/amd/insts"
+)
+
+// AllocateStopFlag allocates a 32-bit stop flag for a persistent kernel in the
+// device memory. See EnqueuePersistentKernel for the termination protocol.
+func (d *Driver) AllocateStopFlag(ctx *Context) Ptr {
+	return d.AllocateMemory(ctx, 4)
+}
+
+// SetStopFlag asks the persistent kernels that poll the stop flag to exit. It
+// returns after the flag is written, without waiting for the kernels.
+func (d *Driver) SetStopFlag(ctx *Context, stopFlag Ptr) {
+	queue := d.CreateCommandQueue(ctx)
+	d.EnqueueSetStopFlag(queue, stopFlag)
+	d.DrainCommandQueue(queue)
+}
+
+// EnqueueSetStopFlag schedules setting the stop flag of a persistent kernel.
+// The queue must not be the one that runs the kernel, as the kernel holds the
+// queue until the flag is set.
+func (d *Driver) EnqueueSetStopFlag(queue *CommandQueue, stopFlag Ptr) {
+	d.mustBeStopFlag(queue.Context, stopFlag)
+	d.enqueueWriteStopFlag(queue, stopFlag, 1)
+}
+
+// enqueueWriteStopFlag writes the stop flag through the L2 caches, so that
+// the kernels that poll the flag see the new value.
+func (d *Driver) enqueueWriteStopFlag(
+	queue *CommandQueue,
+	stopFlag Ptr,
+	value uint32,
+) {
+	cmd := &MemCopyH2DCommand{
+		ID:           sim.GetIDGenerator().Generate(),
+		Dst:          stopFlag,
+		Src:          value,
+		ThroughCache: true,
+	}
+	d.Enqueue(queue, cmd)
+}
+
+// EnqueuePersistentKernel schedules a persistent kernel to be launched later.
+// A persistent kernel loops until the host asks it to exit, which allows
+// producer-consumer patterns between the host and the GPU.
+//
+// The termination protocol is as follows. The host allocates the stop flag
+// in the device memory with AllocateStopFlag and passes it to the kernel as an
+// argument. The driver clears the flag before it launches the kernel. The
+// kernel polls the flag and returns once the flag is non-zero. The host sets
+// the flag with SetStopFlag or EnqueueSetStopFlag, usually from another
+// goroutine or queue than the one that waits for the kernel. The DMA engine
+// writes the flag through the L2 caches rather than to the DRAM, so that the
+// L2 caches do not keep a stale copy of it. The kernel must poll the flag
+// with an atomic operation, such as an atomic add of 0, as the L1 caches can
+// return a stale copy of a loaded value. The driver launches the kernel once,
+// and the command completes when the kernel returns.
+//
+// Persistent kernels need the timing GPUs. The emulation GPUs run each
+// work-group to completion in a single event, so the host never gets a chance
+// to set the flag.
+func (d *Driver) EnqueuePersistentKernel(
+	queue *CommandQueue,
+	co *insts.HsaCo,
+	gridSize [3]uint32,
+	wgSize [3]uint16,
+	kernelArgs interface{},
+	stopFlag Ptr,
+) {
+	dev := d.devices[queue.GPUID]
+	if dev.Type == internal.DeviceTypeUnifiedGPU {
+		log.Panic("persistent kernels are not supported on unified GPUs")
+	}
+
+	d.mustBeStopFlag(queue.Context, stopFlag)
+
+	dCoData, dKernArgData, dPacket := d.allocateGPUMemory(queue.Context, co)
+
+	packet := d.createAQLPacket(gridSize, wgSize, dCoData, dKernArgData)
+	newKernelArgs := d.prepareLocalMemory(co, kernelArgs, packet)
+
+	d.EnqueueMemCopyH2D(queue, dCoData, co.Data)
+	d.EnqueueMemCopyH2D(queue, dKernArgData, newKernelArgs)
+	d.EnqueueMemCopyH2D(queue, dPacket, packet)
+	d.enqueueWriteStopFlag(queue, stopFlag, 0)
+
+	cmd := &PersistentKernelCommand{
+		ID:         sim.GetIDGenerator().Generate(),
+		CodeObject: co,
+		Packet:     packet,
+		DPacket:    dPacket,
+		StopFlag:   stopFlag,
+	}
+	d.Enqueue(queue, cmd)
+}
+
+func (d *Driver) processPersistentKernelCommand(
+	cmd *PersistentKernelCommand,
+	queue *CommandQueue,
+) bool {
+	d.launchKernel(cmd, cmd.CodeObject, cmd.Packet, cmd.DPacket, queue,
+		LaunchBounds{})
+
+	return true
+}
+
+// mustBeStopFlag panics if the pointer is not a 4-byte aligned address in the
+// device memory.
+func (d *Driver) mustBeStopFlag(ctx *Context, stopFlag Ptr) {
+	if stopFlag%4 != 0 {
+		log.Panicf("stop flag 0x%x is not 4-byte aligned", stopFlag)
+	}
+
+	page, found := d.pageTable.Find(ctx.pid, uint64(stopFlag))
+	if !found {
+		log.Panicf("stop flag 0x%x is not mapped", stopFlag)
+	}
+
+	if page.DeviceID == 0 {
+		log.Panicf("stop flag 0x%x is not in the device memory, "+
+			"allocate it with AllocateStopFlag", stopFlag)
+	}
+}
@@ -0,0 +1,29 @@
+package driver
+
+import "github.com/sarchlab/akita/v4/mem/vm"
+
+// GetPhysicalPages returns the pages that back the virtual address range of
+// the process, in the order of their virtual addresses. The DeviceID of each
+// page tells which device holds the page, so that the caller can find out if a
+// buffer is spread across devices, for example, after page migration. The
+// addresses that are not mapped are skipped. The page table is not changed.
+func (d *Driver) GetPhysicalPages(
+	pid vm.PID,
+	vAddr, size uint64,
+) []vm.Page {
+	pages := make([]vm.Page, 0)
+	end := vAddr + size
+
+	for addr := vAddr; addr < end; {
+		page, found := d.pageTable.Find(pid, addr)
+		if !found {
+			addr = (addr>>d.Log2PageSize + 1) << d.Log2PageSize
+			continue
+		}
+
+		pages = append(pages, page)
+		addr = page.VAddr + page.PageSize
+	}
+
+	return pages
+}
@@ -0,0 +1,60 @@
+package driver_test
+
+import (
+	"github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/sarchlab/mgpusim/v4/amd/driver"
+	"github.com/sarchlab/mgpusim/v4/amd/samples/runner"
+)
+
+var _ = ginkgo.Describe("Test Get Physical Pages", func() {
+	var (
+		gpuDriver *driver.Driver
+		context   *driver.Context
+		pageSize  uint64
+	)
+
+	ginkgo.BeforeEach(func() {
+		platform := runner.MakeEmuBuilder().
+			WithNumGPU(2).
+			Build()
+		gpuDriver = platform.Driver
+		gpuDriver.Run()
+		context = gpuDriver.Init()
+		pageSize = 1 << gpuDriver.Log2PageSize
+	})
+
+	ginkgo.AfterEach(func() {
+		gpuDriver.Terminate()
+	})
+
+	ginkgo.It("should report the pages on both devices after migration",
+		func() {
+			gpuDriver.SelectGPU(context, 1)
+			ptr := gpuDriver.AllocateMemory(context, 4*pageSize)
+
+			gpuDriver.Remap(context, uint64(ptr)+2*pageSize, 2*pageSize, 2)
+
+			pages := gpuDriver.GetPhysicalPages(
+				context.PID(), uint64(ptr), 4*pageSize)
+
+			Expect(pages).To(HaveLen(4))
+			for i, page := range pages {
+				Expect(page.VAddr).To(Equal(uint64(ptr) + uint64(i)*pageSize))
+			}
+			Expect(pages[0].DeviceID).To(Equal(uint64(1)))
+			Expect(pages[1].DeviceID).To(Equal(uint64(1)))
+			Expect(pages[2].DeviceID).To(Equal(uint64(2)))
+			Expect(pages[3].DeviceID).To(Equal(uint64(2)))
+		})
+
+	ginkgo.It("should include the page that holds the start address", func() {
+		ptr := gpuDriver.AllocateMemory(context, 2*pageSize)
+
+		pages := gpuDriver.GetPhysicalPages(
+			context.PID(), uint64(ptr)+pageSize/2, pageSize)
+
+		Expect(pages).To(HaveLen(2))
+		Expect(pages[0].VAddr).To(Equal(uint64(ptr)))
+	})
+})
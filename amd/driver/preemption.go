@@ -0,0 +1,87 @@
+package driver
+
+import (
+	"log"
+
+	"github.com/sarchlab/akita/v4/sim"
+	"github.com/sarchlab/mgpusim/v4/amd/protocol"
+)
+
+// PreemptKernel halts the kernels that are running on a GPU. It returns after
+// all the CUs of the GPU have stopped. The wavefronts stay resident on the CUs
+// and no new work-groups are dispatched until ResumeKernel is called. The
+// traffic of saving and restoring the wavefront context is not modeled.
+//
+// PreemptKernel blocks, so it must be called from a different goroutine than
+// the one that waits for the kernel to complete. It cannot be recorded (see
+// StartRecording).
+func (d *Driver) PreemptKernel(gpuID int) {
+	d.mustNotRecord("preempt a kernel")
+	d.mustBeValidGPUID(gpuID)
+
+	req := protocol.NewPreemptKernelReq(d.gpuPort, d.GPUs[gpuID-1])
+	d.sendControlReqAndWait(req)
+}
+
+// ResumeKernel lets a GPU that is halted by PreemptKernel continue executing
+// the kernels. It returns after all the CUs of the GPU have resumed. Resuming
+// a GPU that is not preempted has no effect. It cannot be recorded.
+func (d *Driver) ResumeKernel(gpuID int) {
+	d.mustNotRecord("resume a kernel")
+	d.mustBeValidGPUID(gpuID)
+
+	req := protocol.NewResumeKernelReq(d.gpuPort, d.GPUs[gpuID-1])
+	d.sendControlReqAndWait(req)
+}
+
+func (d *Driver) mustBeValidGPUID(gpuID int) {
+	if gpuID < 1 || gpuID > len(d.GPUs) {
+		log.Panicf("GPU %d does not exist", gpuID)
+	}
+}
+
+// sendControlReqAndWait lets the driver send the request in the next tick and
+// blocks until the GPU responds.
+func (d *Driver) sendControlReqAndWait(req sim.Msg) {
+	done := make(chan bool)
+
+	d.controlReqMutex.Lock()
+	if d.controlReqWaiters == nil {
+		d.controlReqWaiters = make(map[string]chan bool)
+	}
+	d.controlReqWaiters[req.Meta().ID] = done
+	d.pendingControlReqs = append(d.pendingControlReqs, req)
+	d.controlReqMutex.Unlock()
+
+	d.enqueueSignal <- true
+	<-done
+}
+
+func (d *Driver) sendPendingControlReqs() bool {
+	d.controlReqMutex.Lock()
+	defer d.controlReqMutex.Unlock()
+
+	if len(d.pendingControlReqs) == 0 {
+		return false
+	}
+
+	d.requestsToSend = append(d.requestsToSend, d.pendingControlReqs...)
+	d.pendingControlReqs = nil
+
+	return true
+}
+
+func (d *Driver) completeControlReq(reqID string) bool {
+	d.controlReqMutex.Lock()
+	defer d.controlReqMutex.Unlock()
+
+	done, found := d.controlReqWaiters[reqID]
+	if !found {
+		log.Panicf("no one is waiting for request %s", reqID)
+	}
+
+	delete(d.controlReqWaiters, reqID)
+	close(done)
+
+	return true
+}
@@ -0,0 +1,94 @@
+package driver
+
+import (
+	"github.com/golang/mock/gomock"
+	"github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/sarchlab/akita/v4/mem/mem"
+	"github.com/sarchlab/akita/v4/sim"
+	"github.com/sarchlab/mgpusim/v4/amd/protocol"
+)
+
+var _ = ginkgo.Describe("Preemption", func() {
+	var (
+		mockCtrl *gomock.Controller
+		toGPUs   *MockPort
+		gpu      *MockPort
+		driver   *Driver
+	)
+
+	ginkgo.BeforeEach(func() {
+		mockCtrl = gomock.NewController(ginkgo.GinkgoT())
+		toGPUs = NewMockPort(mockCtrl)
+		toGPUs.EXPECT().AsRemote().
+			Return(sim.RemotePort("Driver.ToGPUs")).AnyTimes()
+		gpu = NewMockPort(mockCtrl)
+		gpu.EXPECT().AsRemote().Return(sim.RemotePort("GPU1.CP")).AnyTimes()
+
+		driver = MakeBuilder().
+			WithEngine(NewMockEngine(mockCtrl)).
+			WithLog2PageSize(12).
+			Build("Driver")
+		driver.gpuPort = toGPUs
+		driver.RegisterGPU(gpu, DeviceProperties{
+			CUCount:  4,
+			DRAMSize: 4 * mem.GB,
+		})
+	})
+
+	ginkgo.AfterEach(func() {
+		mockCtrl.Finish()
+	})
+
+	// sendReq calls the API in a new goroutine, lets the driver send the
+	// request, and returns the request together with a channel that is
+	// closed when the API returns.
+	sendReq := func(call func()) (sim.Msg, chan bool) {
+		done := make(chan bool)
+		go func() {
+			call()
+			close(done)
+		}()
+
+		Eventually(driver.enqueueSignal).Should(Receive())
+		Expect(driver.sendPendingControlReqs()).To(BeTrue())
+		Expect(driver.requestsToSend).To(HaveLen(1))
+
+		return driver.requestsToSend[0], done
+	}
+
+	ginkgo.It("should wait until the GPU halts the kernels", func() {
+		req, done := sendReq(func() { driver.PreemptKernel(1) })
+
+		Expect(req).To(BeAssignableToTypeOf(&protocol.PreemptKernelReq{}))
+		Expect(req.Meta().Dst).To(Equal(gpu.AsRemote()))
+		Expect(done).NotTo(BeClosed())
+
+		rsp := protocol.NewPreemptKernelRsp(gpu, toGPUs, req.Meta().ID)
+		toGPUs.EXPECT().PeekIncoming().Return(rsp)
+		toGPUs.EXPECT().RetrieveIncoming().Return(rsp)
+
+		Expect(driver.processReturnReq()).To(BeTrue())
+		Eventually(done).Should(BeClosed())
+	})
+
+	ginkgo.It("should wait until the GPU resumes the kernels", func() {
+		req, done := sendReq(func() { driver.ResumeKernel(1) })
+
+		Expect(req).To(BeAssignableToTypeOf(&protocol.ResumeKernelReq{}))
+		Expect(req.Meta().Dst).To(Equal(gpu.AsRemote()))
+		Expect(done).NotTo(BeClosed())
+
+		rsp := protocol.NewResumeKernelRsp(gpu, toGPUs, req.Meta().ID)
+		toGPUs.EXPECT().PeekIncoming().Return(rsp)
+		toGPUs.EXPECT().RetrieveIncoming().Return(rsp)
+
+		Expect(driver.processReturnReq()).To(BeTrue())
+		Eventually(done).Should(BeClosed())
+	})
+
+	ginkgo.It("should panic if the GPU does not exist", func() {
+		Expect(func() { driver.PreemptKernel(2) }).To(Panic())
+		Expect(func() { driver.ResumeKernel(0) }).To(Panic())
+	})
+})
@@ -0,0 +1,60 @@
+package driver
+
+import (
+	"log"
+
+	"github.com/sarchlab/akita/v4/sim"
+)
+
+// RDMAStats summarizes the traffic that a GPU exchanges with the other GPUs
+// through its RDMA engine.
+type RDMAStats struct {
+	// NumReqSent is the number of requests that the GPU sends to access the
+	// memory of the other GPUs.
+	NumReqSent uint64
+
+	// NumReqReceived is the number of requests from the other GPUs that the
+	// GPU serves with its own memory.
+	NumReqReceived uint64
+
+	// BytesSent is the number of bytes that the sent requests read or write.
+	BytesSent uint64
+
+	// BytesReceived is the number of bytes that the received requests read or
+	// write.
+	BytesReceived uint64
+
+	// AverageLatency is the average time from a sent request arriving at the
+	// RDMA engine to the engine responding. It only counts the completed
+	// requests.
+	AverageLatency sim.VTimeInSec
+}
+
+// An RDMAStatsReporter reports the traffic of the RDMA engine of a GPU.
+type RDMAStatsReporter interface {
+	RDMAStats() RDMAStats
+}
+
+// RegisterRDMAStatsReporter sets the reporter that records the traffic of the
+// RDMA engine of the given GPU.
+func (d *Driver) RegisterRDMAStatsReporter(
+	gpuID int,
+	reporter RDMAStatsReporter,
+) {
+	if d.rdmaStatsReporters == nil {
+		d.rdmaStatsReporters = make(map[int]RDMAStatsReporter)
+	}
+
+	d.rdmaStatsReporters[gpuID] = reporter
+}
+
+// GetRDMATraffic returns the traffic that the given GPU has exchanged with the
+// other GPUs from the start of the simulation.
+func (d *Driver) GetRDMATraffic(gpuID int) RDMAStats {
+	reporter, found := d.rdmaStatsReporters[gpuID]
+	if !found {
+		log.Panicf("GPU %d does not report RDMA traffic", gpuID)
+	}
+
+	return reporter.RDMAStats()
+}
@@ -0,0 +1,34 @@
+package driver
+
+import (
+	"github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// fakeRDMAStatsReporter reports fixed RDMA statistics.
+type fakeRDMAStatsReporter struct {
+	stats RDMAStats
+}
+
+func (r fakeRDMAStatsReporter) RDMAStats() RDMAStats {
+	return r.stats
+}
+
+var _ = ginkgo.Describe("RDMA Stats", func() {
+	var driver *Driver
+
+	ginkgo.BeforeEach(func() {
+		driver = MakeBuilder().WithLog2PageSize(12).Build("Driver")
+	})
+
+	ginkgo.It("should report the RDMA traffic of a GPU", func() {
+		stats := RDMAStats{NumReqSent: 2, BytesSent: 128}
+		driver.RegisterRDMAStatsReporter(1, fakeRDMAStatsReporter{stats})
+
+		Expect(driver.GetRDMATraffic(1)).To(Equal(stats))
+	})
+
+	ginkgo.It("should panic if the GPU does not report RDMA traffic", func() {
+		Expect(func() { driver.GetRDMATraffic(1) }).To(Panic())
+	})
+})
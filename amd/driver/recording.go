@@ -0,0 +1,700 @@
+package driver
+
+import (
+	"bufio"
+	"bytes"
+	"debug/elf"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"sync"
+
+	"github.com/sarchlab/akita/v4/mem/vm"
+	"github.com/sarchlab/akita/v4/sim"
+	"github.com/sarchlab/mgpusim/v4/amd/insts"
+	"github.com/sarchlab/mgpusim/v4/amd/kernels"
+)
+
+// A recordedCall is a driver API call, written as one JSON line by the
+// recorder.
+type recordedCall struct {
+	Op      string          `json:"op"`
+	Context int             `json:"context,omitempty"`
+	Parent  int             `json:"parent,omitempty"`
+	Queue   int             `json:"queue,omitempty"`
+	GPU     int             `json:"gpu,omitempty"`
+	GPUs    []int           `json:"gpus,omitempty"`
+	Options *ContextOptions `json:"options,omitempty"`
+	Ptr     Ptr             `json:"ptr,omitempty"`
+	Size    uint64          `json:"size,omitempty"`
+	Data    []byte          `json:"data,omitempty"`
+
+	Pageable     bool                               `json:"pageable,omitempty"`
+	ThroughCache bool                               `json:"through_cache,omitempty"`
+	Symbol       string                             `json:"symbol,omitempty"`
+	GridSize     [3]uint32                          `json:"grid_size,omitempty"`
+	WGSize       [3]uint16                          `json:"wg_size,omitempty"`
+	Packet       *kernels.HsaKernelDispatchPacket   `json:"packet,omitempty"`
+	DPacket      Ptr                                `json:"dpacket,omitempty"`
+	Packets      []*kernels.HsaKernelDispatchPacket `json:"packets,omitempty"`
+	DPackets     []Ptr                              `json:"dpackets,omitempty"`
+	Distribution WGDistribution                     `json:"distribution,omitempty"`
+	Advice       *MemAdvice                         `json:"advice,omitempty"`
+	LaunchBounds *LaunchBounds                      `json:"launch_bounds,omitempty"`
+	Kernels      []int                              `json:"kernels,omitempty"`
+	Priority     int                                `json:"priority,omitempty"`
+	QoSClass     MemQoSClass                        `json:"qos_class,omitempty"`
+	Bandwidth    float64                            `json:"bandwidth,omitempty"`
+	CU           int                                `json:"cu,omitempty"`
+	Concurrency  int                                `json:"concurrency,omitempty"`
+	Partitions   map[int]int                        `json:"partitions,omitempty"`
+}
+
+// A recorder writes the driver API calls to a writer. The contexts and the
+// command queues are identified by the order in which they are created, and
+// the kernel launches by the order in which they are enqueued.
+type recorder struct {
+	sync.Mutex
+
+	encoder      *json.Encoder
+	contexts     map[*Context]int
+	queues       map[*CommandQueue]int
+	priorities   map[*CommandQueue]int
+	numLaunches  int
+	lastLaunches map[*CommandQueue]int
+}
+
+// StartRecording writes all the following calls that the host program makes
+// to the driver to w, so that ReplayRecording can reproduce the run. The calls
+// include the context and queue creations, the memory allocations, the memory
+// copies, the buffer remappings, the memory QoS classes, and the kernel
+// launches, including the ones that the driver issues for the host program,
+// such as copying the kernel arguments. The GPU settings, which are the
+// disabled CUs, the L2 partitions, the DMA concurrency, and the memory copy
+// bandwidth caps, are recorded as well. The priority of a queue is recorded
+// with the next command that the queue records.
+//
+// Recording must start before the host program creates the contexts that it
+// uses. The completion callbacks of the kernels and the waits for the copy
+// events are not recorded. The commands of the types that the driver does not
+// define are skipped. PreemptKernel, ResumeKernel, and HotAddGPU panic while
+// the driver is recording, as their effects depend on the time at which they
+// are called and on how the platform is wired.
+//
+// The replay of a kernel graph is approximate. The kernels of a kernel graph
+// are recorded when they are released, together with the kernels that they
+// wait for. The replay releases a kernel once the host program learns that
+// these kernels are complete, which can be later than the completion that
+// releases the kernel in the recorded run.
+func (d *Driver) StartRecording(w io.Writer) {
+	d.recorder = &recorder{
+		encoder:      json.NewEncoder(w),
+		contexts:     make(map[*Context]int),
+		queues:       make(map[*CommandQueue]int),
+		priorities:   make(map[*CommandQueue]int),
+		lastLaunches: make(map[*CommandQueue]int),
+	}
+}
+
+// mustNotRecord panics if the driver is recording, as a recording cannot
+// reproduce the call.
+func (d *Driver) mustNotRecord(call string) {
+	if d.recorder != nil {
+		log.Panicf("cannot %s while recording", call)
+	}
+}
+
+func (d *Driver) record(call recordedCall) {
+	if d.recorder == nil {
+		return
+	}
+
+	err := d.recorder.encoder.Encode(call)
+	if err != nil {
+		log.Panicf("cannot record driver call %s: %v", call.Op, err)
+	}
+}
+
+func (d *Driver) recordContext(op string, ctx *Context, call recordedCall) {
+	if d.recorder == nil {
+		return
+	}
+
+	d.recorder.Lock()
+	defer d.recorder.Unlock()
+
+	call.Op = op
+	call.Context = d.recordedContextID(ctx)
+
+	d.record(call)
+}
+
+func (d *Driver) recordNewContext(ctx, parent *Context, opts *ContextOptions) {
+	if d.recorder == nil {
+		return
+	}
+
+	d.recorder.Lock()
+	defer d.recorder.Unlock()
+
+	call := recordedCall{
+		Op:      "create_context",
+		Context: len(d.recorder.contexts) + 1,
+		Options: opts,
+	}
+	if parent != nil {
+		call.Parent = d.recordedContextID(parent)
+	}
+	d.recorder.contexts[ctx] = call.Context
+
+	d.record(call)
+}
+
+func (d *Driver) recordNewQueue(q *CommandQueue) {
+	if d.recorder == nil {
+		return
+	}
+
+	d.recorder.Lock()
+	defer d.recorder.Unlock()
+
+	call := recordedCall{
+		Op:      "create_queue",
+		Context: d.recordedContextID(q.Context),
+		Queue:   len(d.recorder.queues) + 1,
+		GPU:     q.GPUID,
+	}
+	d.recorder.queues[q] = call.Queue
+
+	d.record(call)
+}
+
+func (d *Driver) recordQueue(op string, q *CommandQueue, call recordedCall) {
+	if d.recorder == nil {
+		return
+	}
+
+	d.recorder.Lock()
+	defer d.recorder.Unlock()
+
+	call.Op = op
+	call.Queue = d.recordedQueueID(q)
+
+	d.recordPriority(q, call.Queue)
+	d.record(call)
+}
+
+// recordLaunch records a kernel launch and numbers it, so that the kernel
+// graphs can record the launches that their kernels wait for.
+func (d *Driver) recordLaunch(op string, q *CommandQueue, call recordedCall) {
+	d.recorder.Lock()
+	defer d.recorder.Unlock()
+
+	call.Op = op
+	call.Queue = d.recordedQueueID(q)
+
+	d.recordPriority(q, call.Queue)
+	d.record(call)
+
+	d.recorder.numLaunches++
+	d.recorder.lastLaunches[q] = d.recorder.numLaunches
+}
+
+// recordPriority records the priority of a queue if the host program has
+// changed it since the last call recorded on the queue. The caller must hold
+// the recorder lock.
+func (d *Driver) recordPriority(q *CommandQueue, queueID int) {
+	if q.Priority == d.recorder.priorities[q] {
+		return
+	}
+
+	d.recorder.priorities[q] = q.Priority
+	d.record(recordedCall{
+		Op:       "set_priority",
+		Queue:    queueID,
+		Priority: q.Priority,
+	})
+}
+
+func (d *Driver) recordGPU(op string, gpuID int, call recordedCall) {
+	if d.recorder == nil {
+		return
+	}
+
+	d.recorder.Lock()
+	defer d.recorder.Unlock()
+
+	call.Op = op
+	call.GPU = gpuID
+
+	d.record(call)
+}
+
+// recordL2Partition records the L2 partitions by the contexts of the
+// processes, as the replayed contexts do not have the recorded PIDs.
+func (d *Driver) recordL2Partition(gpuID int, partitions map[vm.PID]int) {
+	if d.recorder == nil {
+		return
+	}
+
+	d.recorder.Lock()
+	defer d.recorder.Unlock()
+
+	call := recordedCall{Op: "set_l2_partition", GPU: gpuID}
+	for pid, ways := range partitions {
+		if call.Partitions == nil {
+			call.Partitions = make(map[int]int)
+		}
+
+		call.Partitions[d.recordedContextIDOfPID(pid)] = ways
+	}
+
+	d.record(call)
+}
+
+// recordedLastLaunch returns the number of the last kernel launch recorded on
+// a queue, or 0 if the driver is not recording.
+func (d *Driver) recordedLastLaunch(q *CommandQueue) int {
+	if d.recorder == nil {
+		return 0
+	}
+
+	d.recorder.Lock()
+	defer d.recorder.Unlock()
+
+	return d.recorder.lastLaunches[q]
+}
+
+// recordWait records that the following calls are made after the given
+// kernel launches complete.
+func (d *Driver) recordWait(launches []int) {
+	if d.recorder == nil || len(launches) == 0 {
+		return
+	}
+
+	d.recorder.Lock()
+	defer d.recorder.Unlock()
+
+	d.record(recordedCall{Op: "wait", Kernels: launches})
+}
+
+func (d *Driver) recordedQueueID(q *CommandQueue) int {
+	queueID, found := d.recorder.queues[q]
+	if !found {
+		log.Panic("cannot record a command queue created before recording")
+	}
+
+	return queueID
+}
+
+func (d *Driver) recordedContextID(ctx *Context) int {
+	if ctx == nil {
+		return 0
+	}
+
+	ctxID, found := d.recorder.contexts[ctx]
+	if !found {
+		log.Panic("cannot record a context created before recording")
+	}
+
+	return ctxID
+}
+
+// recordedContextIDOfPID returns the first recorded context of a process.
+func (d *Driver) recordedContextIDOfPID(pid vm.PID) int {
+	ctxID := 0
+	for ctx, id := range d.recorder.contexts {
+		if ctx.pid == pid && (ctxID == 0 || id < ctxID) {
+			ctxID = id
+		}
+	}
+
+	if ctxID == 0 {
+		log.Panicf("cannot record PID %d, which has no recorded context", pid)
+	}
+
+	return ctxID
+}
+
+func (d *Driver) recordCommand(q *CommandQueue, c Command) {
+	if d.recorder == nil {
+		return
+	}
+
+	switch c := c.(type) {
+	case *MemCopyH2DCommand:
+		buffer := bytes.NewBuffer(nil)
+		err := binary.Write(buffer, binary.LittleEndian, c.Src)
+		if err != nil {
+			panic(err)
+		}
+
+		d.recordQueue("memcopy_h2d", q, recordedCall{
+			Ptr:          c.Dst,
+			Data:         buffer.Bytes(),
+			Pageable:     c.Pageable,
+			ThroughCache: c.ThroughCache,
+		})
+	case *MemCopyD2HCommand:
+		d.recordQueue("memcopy_d2h", q, recordedCall{
+			Ptr:  c.Src,
+			Size: uint64(binary.Size(c.Dst)),
+		})
+	case *LaunchKernelCommand:
+		call := recordedCodeObject(c.CodeObject)
+		call.GridSize = c.GridSize
+		call.WGSize = c.WGSize
+		call.Packet = c.Packet
+		call.DPacket = c.DPacket
+		call.LaunchBounds = recordedLaunchBounds(c.LaunchBounds)
+
+		d.recordLaunch("launch_kernel", q, call)
+	case *LaunchUnifiedMultiGPUKernelCommand:
+		call := recordedCodeObject(c.CodeObject)
+		call.GridSize = c.GridSize
+		call.WGSize = c.WGSize
+		call.Packets = c.PacketArray
+		call.DPackets = c.DPacketArray
+		call.Distribution = c.WGDistribution
+		call.LaunchBounds = recordedLaunchBounds(c.LaunchBounds)
+
+		d.recordLaunch("launch_unified_kernel", q, call)
+	case *PersistentKernelCommand:
+		call := recordedCodeObject(c.CodeObject)
+		call.Packet = c.Packet
+		call.DPacket = c.DPacket
+		call.Ptr = c.StopFlag
+
+		d.recordQueue("launch_persistent_kernel", q, call)
+	case *FlushCommand:
+		d.recordQueue("flush", q, recordedCall{})
+	case *NoopCommand:
+		d.recordQueue("noop", q, recordedCall{})
+	case *TimelineEventCommand:
+		d.recordQueue("event", q, recordedCall{})
+	case *MemAdviseCommand:
+		d.recordQueue("mem_advise", q, recordedCall{
+			Ptr:    c.Ptr,
+			Size:   c.Size,
+			Advice: &c.Advice,
+		})
+	}
+}
+
+// recordedLaunchBounds returns the launch bounds to record, or nil if the
+// launch is not bounded.
+func recordedLaunchBounds(bounds LaunchBounds) *LaunchBounds {
+	if bounds == (LaunchBounds{}) {
+		return nil
+	}
+
+	return &bounds
+}
+
+func recordedCodeObject(co *insts.HsaCo) recordedCall {
+	call := recordedCall{Data: co.Data}
+	if co.Symbol != nil {
+		call.Symbol = co.Symbol.Name
+		call.Size = co.Symbol.Size
+	}
+
+	return call
+}
+
+// ReplayRecording reissues the driver calls written by StartRecording. The
+// driver must be running and must belong to a platform that is built in the
+// same way as the recorded one, so that the allocations return the recorded
+// addresses. An error is returned if the recording cannot be parsed or if the
+// replay does not reproduce the recorded addresses.
+func (d *Driver) ReplayRecording(r io.Reader) error {
+	replayer := &replayer{
+		driver: d,
+		queues: make(map[int]*CommandQueue),
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1<<30)
+
+	for scanner.Scan() {
+		var call recordedCall
+
+		err := json.Unmarshal(scanner.Bytes(), &call)
+		if err != nil {
+			return err
+		}
+
+		err = replayer.replay(call)
+		if err != nil {
+			return err
+		}
+	}
+
+	return scanner.Err()
+}
+
+type replayer struct {
+	driver   *Driver
+	contexts []*Context
+	queues   map[int]*CommandQueue
+	launches []chan bool
+}
+
+func (r *replayer) replay(call recordedCall) error {
+	switch call.Op {
+	case "create_context":
+		return r.replayCreateContext(call)
+	case "select_gpu":
+		r.driver.SelectGPU(r.context(call.Context), call.GPU)
+	case "create_unified_gpu":
+		ctx := r.context(call.Context)
+		gpuID := r.driver.CreateUnifiedGPU(ctx, call.GPUs)
+		return r.mustMatch(call, uint64(gpuID), uint64(call.GPU))
+	case "create_queue":
+		return r.replayCreateQueue(call)
+	case "allocate", "allocate_unified", "allocate_scratchpad",
+		"map_host_memory":
+		return r.replayAllocation(call)
+	case "free":
+		return r.driver.FreeMemory(r.context(call.Context), call.Ptr)
+	case "distribute":
+		ctx := r.context(call.Context)
+		r.driver.Distribute(ctx, call.Ptr, call.Size, call.GPUs)
+	case "remap":
+		ctx := r.context(call.Context)
+		r.driver.Remap(ctx, uint64(call.Ptr), call.Size, call.GPU)
+	case "move_memory":
+		ctx := r.context(call.Context)
+		r.driver.moveBuffer(ctx, call.Ptr, call.Size, call.GPU)
+	case "route_constant_memory":
+		ctx := r.context(call.Context)
+		r.driver.routeConstantMemory(ctx, call.Ptr, call.Size)
+	case "set_mem_qos":
+		ctx := r.context(call.Context)
+		r.driver.SetMemQoSClass(ctx, call.Ptr, call.Size, call.QoSClass)
+	case "set_priority":
+		r.queues[call.Queue].Priority = call.Priority
+	case "set_memcpy_bandwidth_cap":
+		r.driver.SetMemcpyBandwidthCap(call.GPU, call.Bandwidth)
+	case "disable_cu":
+		r.driver.DisableCU(call.GPU, call.CU)
+	case "enable_cu":
+		r.driver.EnableCU(call.GPU, call.CU)
+	case "set_l2_partition":
+		r.driver.SetL2Partition(call.GPU, r.partitions(call))
+	case "set_dma_concurrency":
+		r.driver.SetDMAConcurrency(call.GPU, call.Concurrency)
+	case "memcopy_h2d", "memcopy_d2h", "launch_kernel",
+		"launch_unified_kernel", "launch_persistent_kernel", "flush", "noop",
+		"event", "mem_advise":
+		r.replayCommand(call)
+	case "drain":
+		r.driver.DrainCommandQueue(r.queues[call.Queue])
+	case "wait":
+		return r.replayWait(call)
+	default:
+		return fmt.Errorf("unknown recorded driver call %q", call.Op)
+	}
+
+	return nil
+}
+
+func (r *replayer) context(id int) *Context {
+	if id == 0 {
+		return nil
+	}
+
+	return r.contexts[id-1]
+}
+
+func (r *replayer) replayCreateContext(call recordedCall) error {
+	if call.Context != len(r.contexts)+1 {
+		return fmt.Errorf("context %d is created out of order", call.Context)
+	}
+
+	var ctx *Context
+	switch {
+	case call.Options != nil:
+		ctx = r.driver.CreateContextWithOptions(*call.Options)
+	case call.Parent != 0:
+		ctx = r.driver.InitWithExistingPID(r.context(call.Parent))
+	default:
+		ctx = r.driver.Init()
+	}
+
+	r.contexts = append(r.contexts, ctx)
+
+	return nil
+}
+
+// partitions keys the recorded L2 partitions by the PIDs of the replayed
+// contexts.
+func (r *replayer) partitions(call recordedCall) map[vm.PID]int {
+	if call.Partitions == nil {
+		return nil
+	}
+
+	partitions := make(map[vm.PID]int)
+	for ctxID, ways := range call.Partitions {
+		partitions[r.context(ctxID).pid] = ways
+	}
+
+	return partitions
+}
+
+func (r *replayer) replayCreateQueue(call recordedCall) error {
+	q := r.driver.CreateCommandQueue(r.context(call.Context))
+	r.queues[call.Queue] = q
+
+	return r.mustMatch(call, uint64(q.GPUID), uint64(call.GPU))
+}
+
+func (r *replayer) replayAllocation(call recordedCall) error {
+	ctx := r.context(call.Context)
+
+	var ptr Ptr
+	switch call.Op {
+	case "allocate":
+		ptr = r.allocateOn(ctx, call.GPU, call.Size)
+	case "allocate_unified":
+		ptr = r.driver.AllocateUnifiedMemory(ctx, call.Size)
+	case "allocate_scratchpad":
+		ptr = r.driver.AllocateScratchpad(ctx, call.GPU, call.Size)
+	case "map_host_memory":
+		ptr = r.driver.MapHostMemory(ctx, call.Data)
+	}
+
+	return r.mustMatch(call, uint64(ptr), uint64(call.Ptr))
+}
+
+// allocateOn allocates the device memory on the recorded GPU, which is not the
+// current GPU of the context when the driver allocates the memory of a kernel
+// launch on a unified GPU.
+func (r *replayer) allocateOn(ctx *Context, gpuID int, size uint64) Ptr {
+	if gpuID != 0 {
+		currentGPUID := ctx.currentGPUID
+		ctx.currentGPUID = gpuID
+		defer func() { ctx.currentGPUID = currentGPUID }()
+	}
+
+	return r.driver.AllocateMemory(ctx, size)
+}
+
+func (r *replayer) replayWait(call recordedCall) error {
+	r.driver.enqueueSignal <- true
+
+	for _, launch := range call.Kernels {
+		if launch < 1 || launch > len(r.launches) {
+			return fmt.Errorf("kernel launch %d is not replayed", launch)
+		}
+
+		<-r.launches[launch-1]
+	}
+
+	return nil
+}
+
+// launchDone returns a completion callback of a replayed kernel launch, so
+// that the recorded waits can wait for the launch.
+func (r *replayer) launchDone() func() {
+	done := make(chan bool)
+	r.launches = append(r.launches, done)
+
+	return func() { close(done) }
+}
+
+func (r *replayer) replayCommand(call recordedCall) {
+	var cmd Command
+
+	id := sim.GetIDGenerator().Generate()
+	switch call.Op {
+	case "memcopy_h2d":
+		cmd = &MemCopyH2DCommand{
+			ID:           id,
+			Dst:          call.Ptr,
+			Src:          call.Data,
+			Pageable:     call.Pageable,
+			ThroughCache: call.ThroughCache,
+		}
+	case "memcopy_d2h":
+		cmd = &MemCopyD2HCommand{
+			ID:  id,
+			Dst: make([]byte, call.Size),
+			Src: call.Ptr,
+		}
+	case "launch_kernel":
+		cmd = &LaunchKernelCommand{
+			ID:         id,
+			CodeObject: replayedCodeObject(call),
+			GridSize:   call.GridSize,
+			WGSize:     call.WGSize,
+			Packet:     call.Packet,
+			DPacket:    call.DPacket,
+			OnComplete: r.launchDone(),
+
+			LaunchBounds: replayedLaunchBounds(call),
+		}
+	case "launch_unified_kernel":
+		cmd = &LaunchUnifiedMultiGPUKernelCommand{
+			ID:             id,
+			CodeObject:     replayedCodeObject(call),
+			GridSize:       call.GridSize,
+			WGSize:         call.WGSize,
+			PacketArray:    call.Packets,
+			DPacketArray:   call.DPackets,
+			WGDistribution: call.Distribution,
+			OnComplete:     r.launchDone(),
+			LaunchBounds:   replayedLaunchBounds(call),
+		}
+	case "launch_persistent_kernel":
+		cmd = &PersistentKernelCommand{
+			ID:         id,
+			CodeObject: replayedCodeObject(call),
+			Packet:     call.Packet,
+			DPacket:    call.DPacket,
+			StopFlag:   call.Ptr,
+		}
+	case "flush":
+		cmd = &FlushCommand{ID: id}
+	case "noop":
+		cmd = &NoopCommand{ID: id}
+	case "event":
+		cmd = &TimelineEventCommand{ID: id, Event: &TimelineEvent{}}
+	case "mem_advise":
+		cmd = &MemAdviseCommand{
+			ID:     id,
+			Ptr:    call.Ptr,
+			Size:   call.Size,
+			Advice: *call.Advice,
+		}
+	}
+
+	r.driver.Enqueue(r.queues[call.Queue], cmd)
+}
+
+func replayedCodeObject(call recordedCall) *insts.HsaCo {
+	co := insts.NewHsaCoFromData(call.Data)
+	if call.Symbol != "" {
+		co.Symbol = &elf.Symbol{Name: call.Symbol, Size: call.Size}
+	}
+
+	return co
+}
+
+func replayedLaunchBounds(call recordedCall) LaunchBounds {
+	if call.LaunchBounds == nil {
+		return LaunchBounds{}
+	}
+
+	return *call.LaunchBounds
+}
+
+func (r *replayer) mustMatch(call recordedCall, got, recorded uint64) error {
+	if got != recorded {
+		return fmt.Errorf("replaying %s returns 0x%x, but 0x%x is recorded",
+			call.Op, got, recorded)
+	}
+
+	return nil
+}
@@ -0,0 +1,134 @@
+package driver_test
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/sarchlab/mgpusim/v4/amd/benchmarks/heteromark/fir"
+	"github.com/sarchlab/mgpusim/v4/amd/driver"
+	"github.com/sarchlab/mgpusim/v4/amd/kernels"
+	"github.com/sarchlab/mgpusim/v4/amd/samples/runner"
+)
+
+var _ = ginkgo.Describe("Recording", func() {
+	// record runs the host program on an emulated platform with the given
+	// number of GPUs and returns the recorded driver calls.
+	record := func(numGPU int, run func(gpuDriver *driver.Driver)) []byte {
+		gpuDriver := runner.MakeEmuBuilder().WithNumGPU(numGPU).Build().Driver
+
+		recording := bytes.NewBuffer(nil)
+		gpuDriver.StartRecording(recording)
+		gpuDriver.Run()
+		run(gpuDriver)
+		gpuDriver.Terminate()
+
+		return recording.Bytes()
+	}
+
+	replay := func(numGPU int, recording []byte) error {
+		gpuDriver := runner.MakeEmuBuilder().WithNumGPU(numGPU).Build().Driver
+		gpuDriver.Run()
+		defer gpuDriver.Terminate()
+
+		return gpuDriver.ReplayRecording(bytes.NewReader(recording))
+	}
+
+	runFIR := func(gpuDriver *driver.Driver, gpuID int) {
+		benchmark := fir.NewBenchmark(gpuDriver)
+		benchmark.Length = 1024
+		benchmark.SelectGPU([]int{gpuID})
+		benchmark.Run()
+		benchmark.Verify()
+	}
+
+	ginkgo.It("should replay a recorded run", func() {
+		recording := record(1, func(gpuDriver *driver.Driver) {
+			runFIR(gpuDriver, 1)
+		})
+
+		Expect(string(recording)).To(ContainSubstring(`"op":"launch_kernel"`))
+		Expect(replay(1, recording)).To(Succeed())
+	})
+
+	ginkgo.It("should replay a run on a unified GPU", func() {
+		recording := record(2, func(gpuDriver *driver.Driver) {
+			runFIR(gpuDriver, gpuDriver.CreateUnifiedGPU(nil, []int{1, 2}))
+		})
+
+		Expect(string(recording)).
+			To(ContainSubstring(`"op":"launch_unified_kernel"`))
+		Expect(replay(2, recording)).To(Succeed())
+	})
+
+	ginkgo.It("should replay the dependencies of a kernel graph", func() {
+		const length = 1024
+
+		recording := record(1, func(gpuDriver *driver.Driver) {
+			ctx := gpuDriver.Init()
+			hsaco := kernels.LoadProgram(
+				"../benchmarks/heteromark/fir/kernels.hsaco", "FIR")
+			args := func() *fir.KernelArgs {
+				return &fir.KernelArgs{
+					Output:  gpuDriver.AllocateMemory(ctx, length*4),
+					Filter:  gpuDriver.AllocateMemory(ctx, 16*4),
+					Input:   gpuDriver.AllocateMemory(ctx, length*4),
+					History: gpuDriver.AllocateMemory(ctx, 16*4),
+					NumTaps: 16,
+				}
+			}
+
+			gridSize := [3]uint32{length, 1, 1}
+			wgSize := [3]uint16{64, 1, 1}
+			graph := driver.NewKernelGraph(ctx)
+			a := graph.AddKernel(hsaco, gridSize, wgSize, args())
+			b := graph.AddKernel(hsaco, gridSize, wgSize, args(), a)
+			c := graph.AddKernel(hsaco, gridSize, wgSize, args(), a)
+			graph.AddKernel(hsaco, gridSize, wgSize, args(), b, c)
+
+			gpuDriver.QueueKernelGraph(graph)
+			gpuDriver.DrainKernelGraph(graph)
+		})
+
+		Expect(string(recording)).To(ContainSubstring(`"op":"wait"`))
+		Expect(replay(1, recording)).To(Succeed())
+	})
+
+	ginkgo.It("should replay remapping a buffer", func() {
+		recording := record(2, func(gpuDriver *driver.Driver) {
+			ctx := gpuDriver.Init()
+			data := make([]uint32, 2048)
+			ptr := gpuDriver.AllocateMemory(ctx, 2048*4)
+			gpuDriver.MemCopyH2D(ctx, ptr, data)
+
+			err := gpuDriver.RemapMemory(ctx, ptr, 2)
+			Expect(err).To(Succeed())
+		})
+
+		Expect(string(recording)).To(ContainSubstring(`"op":"move_memory"`))
+		Expect(replay(2, recording)).To(Succeed())
+	})
+
+	ginkgo.It("should fail if an allocation differs from the recording",
+		func() {
+			recording := record(1, func(gpuDriver *driver.Driver) {
+				runFIR(gpuDriver, 1)
+			})
+
+			// Prefixing a digit changes the first recorded address.
+			recording = bytes.Replace(recording,
+				[]byte(`"ptr":`), []byte(`"ptr":1`), 1)
+
+			Expect(replay(1, recording)).
+				To(MatchError(ContainSubstring("is recorded")))
+		})
+
+	ginkgo.It("should fail on an unknown call", func() {
+		gpuDriver := runner.MakeEmuBuilder().WithNumGPU(1).Build().Driver
+
+		err := gpuDriver.ReplayRecording(strings.NewReader(`{"op":"fly"}`))
+
+		Expect(err).To(MatchError(ContainSubstring("fly")))
+	})
+})
@@ -0,0 +1,79 @@
+package driver
+
+import (
+	"fmt"
+
+	"github.com/sarchlab/mgpusim/v4/amd/protocol"
+)
+
+// RemapMemory moves a buffer that is allocated with AllocateMemory to the
+// memory of another GPU, keeping its virtual address. The driver copies the
+// buffer out with DMA, backs the buffer with newly allocated pages on the
+// target GPU, removes the old translations from the TLBs of all the GPUs, and
+// copies the data back with DMA. The old pages are freed.
+//
+// Unlike the page migration triggered by the MMU, RemapMemory is initiated by
+// the host and returns when the buffer is moved. It must not be called while
+// a kernel is accessing the buffer.
+func (d *Driver) RemapMemory(ctx *Context, ptr Ptr, targetGPU int) error {
+	d.mustBeValidGPUID(targetGPU)
+
+	buf := ctx.findBuffer(ptr)
+	if buf == nil {
+		return fmt.Errorf("0x%x is not the start of an allocated buffer",
+			uint64(ptr))
+	}
+
+	_, found := d.pageTable.Find(ctx.pid, uint64(ptr))
+	if !found {
+		return fmt.Errorf("buffer at 0x%x is not mapped", uint64(ptr))
+	}
+
+	if d.isBufferOnDevice(ctx, buf, targetGPU) {
+		return nil
+	}
+
+	data := make([]byte, buf.size)
+	d.MemCopyD2H(ctx, data, ptr)
+
+	d.moveBuffer(ctx, ptr, buf.size, targetGPU)
+	d.MemCopyH2D(ctx, ptr, data)
+
+	return nil
+}
+
+// moveBuffer backs the buffer with new pages on the target GPU and removes the
+// old translations from the TLBs of all the GPUs. The data is not copied.
+func (d *Driver) moveBuffer(
+	ctx *Context,
+	ptr Ptr,
+	size uint64,
+	targetGPU int,
+) {
+	oldPageVAddrs := d.memAllocator.Move(ctx.pid, uint64(ptr), size, targetGPU)
+	for _, gpu := range d.GPUs {
+		req := protocol.NewInvalidateTLBReq(
+			d.gpuPort, gpu, ctx.pid, oldPageVAddrs)
+		d.sendControlReqAndWait(req)
+	}
+
+	d.recordContext("move_memory", ctx,
+		recordedCall{Ptr: ptr, Size: size, GPU: targetGPU})
+}
+
+// isBufferOnDevice tells if all the pages of the buffer are on the given
+// device. A buffer can be split across devices, for example, by Distribute.
+func (d *Driver) isBufferOnDevice(
+	ctx *Context,
+	buf *buffer,
+	deviceID int,
+) bool {
+	pages := d.GetPhysicalPages(ctx.pid, uint64(buf.vAddr), buf.size)
+	for _, page := range pages {
+		if d.memAllocator.GetDeviceIDByPAddr(page.PAddr) != deviceID {
+			return false
+		}
+	}
+
+	return true
+}
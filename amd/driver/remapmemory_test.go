@@ -0,0 +1,88 @@
+package driver_test
+
+import (
+	"github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/sarchlab/mgpusim/v4/amd/driver"
+	"github.com/sarchlab/mgpusim/v4/amd/samples/runner"
+)
+
+var _ = ginkgo.Describe("Test Remap Memory", func() {
+	var (
+		gpuDriver *driver.Driver
+		context   *driver.Context
+	)
+
+	ginkgo.BeforeEach(func() {
+		platform := runner.MakeEmuBuilder().
+			WithNumGPU(2).
+			Build()
+		gpuDriver = platform.Driver
+		gpuDriver.Run()
+		context = gpuDriver.Init()
+	})
+
+	ginkgo.AfterEach(func() {
+		gpuDriver.Terminate()
+	})
+
+	ginkgo.It("should keep the data and let kernels use the buffer", func() {
+		data := make([]uint32, 4096)
+		for i := range data {
+			data[i] = uint32(i)
+		}
+
+		gpuDriver.SelectGPU(context, 1)
+		ptr := gpuDriver.AllocateMemory(context, 4096*4)
+		gpuDriver.MemCopyH2D(context, ptr, data)
+
+		err := gpuDriver.RemapMemory(context, ptr, 2)
+		Expect(err).NotTo(HaveOccurred())
+
+		remapped := make([]uint32, 4096)
+		gpuDriver.MemCopyD2H(context, remapped, ptr)
+		Expect(remapped).To(Equal(data))
+
+		gpuDriver.SelectGPU(context, 2)
+		dst := gpuDriver.AllocateMemory(context, 4096*4)
+		gpuDriver.MemCopyD2D(context, dst, ptr, 4096*4)
+
+		copied := make([]uint32, 4096)
+		gpuDriver.MemCopyD2H(context, copied, dst)
+		Expect(copied).To(Equal(data))
+	})
+
+	ginkgo.It("should move a buffer that spans more than one GPU", func() {
+		data := make([]uint32, 2048)
+		for i := range data {
+			data[i] = uint32(i)
+		}
+
+		gpuDriver.SelectGPU(context, 1)
+		ptr := gpuDriver.AllocateMemory(context, 2048*4)
+		gpuDriver.Distribute(context, ptr, 2048*4, []int{1, 2})
+		gpuDriver.MemCopyH2D(context, ptr, data)
+
+		err := gpuDriver.RemapMemory(context, ptr, 1)
+		Expect(err).NotTo(HaveOccurred())
+
+		pages := gpuDriver.GetPhysicalPages(
+			context.PID(), uint64(ptr), 2048*4)
+		Expect(pages).To(HaveLen(2))
+		for _, page := range pages {
+			Expect(page.DeviceID).To(Equal(uint64(1)))
+		}
+
+		remapped := make([]uint32, 2048)
+		gpuDriver.MemCopyD2H(context, remapped, ptr)
+		Expect(remapped).To(Equal(data))
+	})
+
+	ginkgo.It("should reject an address that is not a buffer", func() {
+		ptr := gpuDriver.AllocateMemory(context, 4096)
+
+		err := gpuDriver.RemapMemory(context, ptr+4, 2)
+
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,200 @@
+package driver
+
+import (
+	"bytes"
+
+	"github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/sarchlab/akita/v4/mem/vm"
+	"github.com/sarchlab/mgpusim/v4/amd/insts"
+)
+
+// fakeDMAEngine records the number of concurrent memory copies.
+type fakeDMAEngine struct {
+	maxOutstanding int
+}
+
+func (e *fakeDMAEngine) SetMaxOutstanding(n int) {
+	e.maxOutstanding = n
+}
+
+func (e *fakeDMAEngine) MaxOutstanding() int {
+	return e.maxOutstanding
+}
+
+var _ = ginkgo.Describe("Replay", func() {
+	var (
+		recorded  *Driver
+		replayed  *Driver
+		recording *bytes.Buffer
+		ctx       *Context
+		queue     *CommandQueue
+	)
+
+	// newDriver builds a driver with two GPUs and without an engine, so that
+	// the enqueued commands stay in their queues.
+	newDriver := func() *Driver {
+		d := MakeBuilder().
+			WithPageTable(vm.NewPageTable(12)).
+			WithLog2PageSize(12).
+			Build("Driver")
+		for i := 0; i < 2; i++ {
+			d.RegisterGPU(nil, DeviceProperties{CUCount: 4, DRAMSize: 1 << 30})
+		}
+
+		return d
+	}
+
+	ginkgo.BeforeEach(func() {
+		recorded = newDriver()
+		replayed = newDriver()
+		recording = bytes.NewBuffer(nil)
+		recorded.StartRecording(recording)
+
+		ctx = recorded.Init()
+		queue = recorded.CreateCommandQueue(ctx)
+	})
+
+	replay := func() {
+		err := replayed.ReplayRecording(bytes.NewReader(recording.Bytes()))
+		Expect(err).To(Succeed())
+	}
+
+	replayedQueue := func() *CommandQueue {
+		return replayed.contexts[0].queues[0]
+	}
+
+	ginkgo.It("should replay the launch bounds of a kernel launch", func() {
+		bounds := LaunchBounds{MaxWorkGroupSize: 128, MinWGPerCU: 2}
+		recorded.Enqueue(queue, &LaunchKernelCommand{
+			CodeObject:   &insts.HsaCo{},
+			LaunchBounds: bounds,
+		})
+
+		replay()
+
+		cmd := replayedQueue().Peek().(*LaunchKernelCommand)
+		Expect(cmd.LaunchBounds).To(Equal(bounds))
+	})
+
+	ginkgo.It("should replay the launch bounds of a unified kernel launch",
+		func() {
+			bounds := LaunchBounds{MaxWorkGroupSize: 256}
+			recorded.Enqueue(queue, &LaunchUnifiedMultiGPUKernelCommand{
+				CodeObject:   &insts.HsaCo{},
+				LaunchBounds: bounds,
+			})
+
+			replay()
+
+			cmd := replayedQueue().Peek().(*LaunchUnifiedMultiGPUKernelCommand)
+			Expect(cmd.LaunchBounds).To(Equal(bounds))
+		})
+
+	ginkgo.It("should replay the priority of a queue", func() {
+		queue.Priority = 2
+		recorded.Enqueue(queue, &NoopCommand{})
+		recorded.Enqueue(queue, &NoopCommand{})
+
+		replay()
+
+		Expect(replayedQueue().Priority).To(Equal(2))
+		Expect(bytes.Count(recording.Bytes(), []byte("set_priority"))).
+			To(Equal(1))
+	})
+
+	ginkgo.It("should replay the memory QoS classes", func() {
+		recordedController := make(fakeMemQoSController)
+		recorded.RegisterMemQoSController(1, recordedController)
+		replayedController := make(fakeMemQoSController)
+		replayed.RegisterMemQoSController(1, replayedController)
+
+		ptr := recorded.AllocateMemory(ctx, 4096)
+		recorded.SetMemQoSClass(ctx, ptr, 4096, MemQoSLatencyCritical)
+
+		replay()
+
+		Expect(replayedController).To(HaveLen(1))
+		Expect(replayedController).To(Equal(recordedController))
+	})
+
+	ginkgo.It("should replay the routing of the constant memory", func() {
+		recordedRouter := &fakeConstantMemoryRouter{}
+		recorded.RegisterConstantMemoryRouter(1, recordedRouter)
+		replayedRouter := &fakeConstantMemoryRouter{}
+		replayed.RegisterConstantMemoryRouter(1, replayedRouter)
+
+		recorded.AllocateConstantMemory(ctx, 1024)
+
+		replay()
+
+		Expect(replayedRouter.ranges).To(HaveLen(1))
+		Expect(replayedRouter.ranges).To(Equal(recordedRouter.ranges))
+	})
+
+	ginkgo.It("should replay moving a buffer to another GPU", func() {
+		// Without the command processors, the TLBs are not invalidated, so
+		// that moving does not wait for the engine.
+		recorded.GPUs = nil
+		replayed.GPUs = nil
+
+		ptr := recorded.AllocateMemory(ctx, 8192)
+		recorded.moveBuffer(ctx, ptr, 8192, 2)
+
+		replay()
+
+		pages := replayed.GetPhysicalPages(
+			replayed.contexts[0].pid, uint64(ptr), 8192)
+		Expect(pages).To(HaveLen(2))
+		for _, page := range pages {
+			Expect(page.DeviceID).To(Equal(uint64(2)))
+		}
+	})
+
+	ginkgo.It("should replay the GPU settings", func() {
+		cuController := &fakeCUController{disabled: make(map[int]bool)}
+		dma := &fakeDMAEngine{}
+		recorded.RegisterCUController(1, &fakeCUController{
+			disabled: make(map[int]bool)})
+		recorded.RegisterDMAEngine(1, &fakeDMAEngine{})
+		replayed.RegisterCUController(1, cuController)
+		replayed.RegisterDMAEngine(1, dma)
+
+		recorded.SetMemcpyBandwidthCap(2, 1e9)
+		recorded.DisableCU(1, 2)
+		recorded.DisableCU(1, 3)
+		recorded.EnableCU(1, 2)
+		recorded.SetDMAConcurrency(1, 4)
+
+		replay()
+
+		Expect(replayed.GetMemcpyBandwidthCap(2)).To(Equal(1e9))
+		Expect(cuController.disabled).To(Equal(map[int]bool{3: true}))
+		Expect(dma.maxOutstanding).To(Equal(4))
+	})
+
+	ginkgo.It("should replay the L2 partitions for the replayed processes",
+		func() {
+			other := recorded.Init()
+			partitioner := &fakeL2Partitioner{}
+			recorded.RegisterL2Partitioner(1, &fakeL2Partitioner{})
+			replayed.RegisterL2Partitioner(1, partitioner)
+
+			recorded.SetL2Partition(1, map[vm.PID]int{ctx.pid: 4, other.pid: 2})
+
+			replay()
+
+			Expect(partitioner.partitions).To(Equal(map[vm.PID]int{
+				replayed.contexts[0].pid: 4,
+				replayed.contexts[1].pid: 2,
+			}))
+		})
+
+	ginkgo.It("should not record the calls that it cannot reproduce", func() {
+		Expect(func() { recorded.PreemptKernel(1) }).To(Panic())
+		Expect(func() { recorded.ResumeKernel(1) }).To(Panic())
+		Expect(func() {
+			recorded.HotAddGPU(nil, DeviceProperties{})
+		}).To(Panic())
+	})
+})
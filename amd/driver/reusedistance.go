@@ -0,0 +1,49 @@
+package driver
+
+import (
+	"log"
+)
+
+// A ReuseDistanceHistogram counts the cache line accesses by their reuse
+// distance. The reuse distance of an access is the number of distinct cache
+// lines that the same cache has accessed since the previous access to the
+// same line.
+type ReuseDistanceHistogram struct {
+	// Buckets[0] counts the accesses with a reuse distance of 0. Buckets[i]
+	// (i > 0) counts the accesses with a reuse distance in [2^(i-1), 2^i).
+	Buckets []uint64
+
+	// ColdAccesses counts the first accesses to each cache line, which do not
+	// have a reuse distance.
+	ColdAccesses uint64
+}
+
+// A ReuseDistanceAnalyzer records the reuse distance of the accesses to the
+// caches of a GPU.
+type ReuseDistanceAnalyzer interface {
+	ReuseDistanceHistogram() ReuseDistanceHistogram
+}
+
+// RegisterReuseDistanceAnalyzer sets the analyzer that records the reuse
+// distance of the cache accesses of the given GPU.
+func (d *Driver) RegisterReuseDistanceAnalyzer(
+	gpuID int,
+	analyzer ReuseDistanceAnalyzer,
+) {
+	if d.reuseDistanceAnalyzers == nil {
+		d.reuseDistanceAnalyzers = make(map[int]ReuseDistanceAnalyzer)
+	}
+
+	d.reuseDistanceAnalyzers[gpuID] = analyzer
+}
+
+// GetReuseDistanceHistogram returns the reuse distance histogram of the cache
+// accesses of the given GPU, collected from the start of the simulation.
+func (d *Driver) GetReuseDistanceHistogram(gpuID int) ReuseDistanceHistogram {
+	analyzer, found := d.reuseDistanceAnalyzers[gpuID]
+	if !found {
+		log.Panicf("GPU %d does not analyze reuse distance", gpuID)
+	}
+
+	return analyzer.ReuseDistanceHistogram()
+}
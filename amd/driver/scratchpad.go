@@ -0,0 +1,78 @@
+package driver
+
+import (
+	"log"
+
+	"github.com/sarchlab/akita/v4/mem/vm"
+)
+
+// AllocateScratchpad allocates a software-managed scratchpad region, such as a
+// staging buffer, in the DRAM of the given GPU. The region works like the
+// memory allocated with AllocateMemory, except that the accesses to it can be
+// told apart with IsScratchpadAddress.
+func (d *Driver) AllocateScratchpad(
+	ctx *Context,
+	gpuID int,
+	byteSize uint64,
+) Ptr {
+	ptr := Ptr(d.memAllocator.Allocate(ctx.pid, byteSize, gpuID))
+
+	ctx.buffers = append(ctx.buffers, &buffer{
+		vAddr:      ptr,
+		size:       byteSize,
+		scratchpad: true,
+	})
+
+	d.setScratchpadPages(ctx.pid, ptr, byteSize, true)
+
+	d.recordContext("allocate_scratchpad", ctx,
+		recordedCall{GPU: gpuID, Ptr: ptr, Size: byteSize})
+
+	return ptr
+}
+
+// IsScratchpadAddress returns true if the given physical address belongs to a
+// scratchpad region allocated with AllocateScratchpad.
+func (d *Driver) IsScratchpadAddress(pAddr uint64) bool {
+	d.scratchpadMutex.RLock()
+	defer d.scratchpadMutex.RUnlock()
+
+	return d.scratchpadPages[pAddr>>d.Log2PageSize]
+}
+
+func (d *Driver) untagScratchpad(ctx *Context, ptr Ptr) {
+	for _, b := range ctx.buffers {
+		if b.vAddr == ptr && b.scratchpad && !b.freed {
+			d.setScratchpadPages(ctx.pid, ptr, b.size, false)
+		}
+	}
+}
+
+func (d *Driver) setScratchpadPages(
+	pid vm.PID,
+	ptr Ptr,
+	byteSize uint64,
+	isScratchpad bool,
+) {
+	d.scratchpadMutex.Lock()
+	defer d.scratchpadMutex.Unlock()
+
+	if d.scratchpadPages == nil {
+		d.scratchpadPages = make(map[uint64]bool)
+	}
+
+	pageSize := uint64(1) << d.Log2PageSize
+	for addr := uint64(ptr); addr < uint64(ptr)+byteSize; addr += pageSize {
+		page, found := d.pageTable.Find(pid, addr)
+		if !found {
+			log.Panicf("page of address 0x%x is not allocated", addr)
+		}
+
+		pAddr := page.PAddr + (addr - page.VAddr)
+		if isScratchpad {
+			d.scratchpadPages[pAddr>>d.Log2PageSize] = true
+		} else {
+			delete(d.scratchpadPages, pAddr>>d.Log2PageSize)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package driver
+
+import (
+	"log"
+)
+
+// A SIMDUtilizationReporter reports how many lanes of the SIMD units of a GPU
+// are active.
+type SIMDUtilizationReporter interface {
+	SIMDUtilization() []float64
+}
+
+// RegisterSIMDUtilizationReporter sets the reporter that records the lane
+// utilization of the SIMD units of the given GPU.
+func (d *Driver) RegisterSIMDUtilizationReporter(
+	gpuID int,
+	reporter SIMDUtilizationReporter,
+) {
+	if d.simdUtilReporters == nil {
+		d.simdUtilReporters = make(map[int]SIMDUtilizationReporter)
+	}
+
+	d.simdUtilReporters[gpuID] = reporter
+}
+
+// GetSIMDUtilization returns the average fraction of the active lanes of the
+// vector instructions that each SIMD unit of the given GPU has executed since
+// the start of the simulation. Branch divergence masks off lanes and lowers
+// the utilization. A SIMD unit that has not executed any instruction reports
+// 0.
+func (d *Driver) GetSIMDUtilization(gpuID int) []float64 {
+	reporter, found := d.simdUtilReporters[gpuID]
+	if !found {
+		log.Panicf("GPU %d does not report SIMD utilization", gpuID)
+	}
+
+	return reporter.SIMDUtilization()
+}
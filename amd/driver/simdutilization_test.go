@@ -0,0 +1,33 @@
+package driver
+
+import (
+	"github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// fakeSIMDUtilizationReporter reports a fixed lane utilization.
+type fakeSIMDUtilizationReporter []float64
+
+func (r fakeSIMDUtilizationReporter) SIMDUtilization() []float64 {
+	return r
+}
+
+var _ = ginkgo.Describe("SIMD Utilization", func() {
+	var driver *Driver
+
+	ginkgo.BeforeEach(func() {
+		driver = MakeBuilder().WithLog2PageSize(12).Build("Driver")
+	})
+
+	ginkgo.It("should report the lane utilization of a GPU", func() {
+		driver.RegisterSIMDUtilizationReporter(1,
+			fakeSIMDUtilizationReporter{1, 0.25, 0})
+
+		Expect(driver.GetSIMDUtilization(1)).To(Equal([]float64{1, 0.25, 0}))
+	})
+
+	ginkgo.It("should panic if the GPU does not report SIMD utilization",
+		func() {
+			Expect(func() { driver.GetSIMDUtilization(1) }).To(Panic())
+		})
+})
@@ -0,0 +1,60 @@
+package driver
+
+import "log"
+
+// StallBreakdown tells how many cycles the wavefronts of a GPU have stalled
+// on each reason. The cycles of the wavefronts that stall at the same time are
+// added up, so the total can exceed the number of cycles that have passed.
+type StallBreakdown struct {
+	// VMem counts the cycles of waiting for vector memory accesses.
+	VMem uint64
+
+	// SMem counts the cycles of waiting for scalar memory accesses.
+	SMem uint64
+
+	// Barrier counts the cycles of waiting for the other wavefronts of the
+	// work-group at a barrier.
+	Barrier uint64
+
+	// Dependency counts the cycles of waiting for the previous instruction of
+	// the wavefront to complete.
+	Dependency uint64
+
+	// IssuePort counts the cycles that an instruction is ready but is not
+	// issued, because another wavefront wins the issue arbitration or the
+	// execution unit is busy.
+	IssuePort uint64
+
+	// InstFetch counts the cycles of waiting for the next instruction to be
+	// fetched and decoded.
+	InstFetch uint64
+}
+
+// A StallBreakdownReporter reports what the wavefronts of a GPU stall on.
+type StallBreakdownReporter interface {
+	StallBreakdown() StallBreakdown
+}
+
+// RegisterStallBreakdownReporter sets the reporter that records what the
+// wavefronts of the given GPU stall on.
+func (d *Driver) RegisterStallBreakdownReporter(
+	gpuID int,
+	reporter StallBreakdownReporter,
+) {
+	if d.stallReporters == nil {
+		d.stallReporters = make(map[int]StallBreakdownReporter)
+	}
+
+	d.stallReporters[gpuID] = reporter
+}
+
+// GetStallBreakdown returns the cycles that the wavefronts of the given GPU
+// have stalled on each reason since the start of the simulation.
+func (d *Driver) GetStallBreakdown(gpuID int) StallBreakdown {
+	reporter, found := d.stallReporters[gpuID]
+	if !found {
+		log.Panicf("GPU %d does not report stall breakdown", gpuID)
+	}
+
+	return reporter.StallBreakdown()
+}
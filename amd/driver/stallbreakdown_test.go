@@ -0,0 +1,36 @@
+package driver
+
+import (
+	"github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// fakeStallBreakdownReporter reports a fixed stall breakdown.
+type fakeStallBreakdownReporter struct {
+	stalls StallBreakdown
+}
+
+func (r fakeStallBreakdownReporter) StallBreakdown() StallBreakdown {
+	return r.stalls
+}
+
+var _ = ginkgo.Describe("Stall Breakdown", func() {
+	var driver *Driver
+
+	ginkgo.BeforeEach(func() {
+		driver = MakeBuilder().WithLog2PageSize(12).Build("Driver")
+	})
+
+	ginkgo.It("should report the stall breakdown of a GPU", func() {
+		stalls := StallBreakdown{VMem: 100, Barrier: 20, Dependency: 3}
+		driver.RegisterStallBreakdownReporter(1,
+			fakeStallBreakdownReporter{stalls})
+
+		Expect(driver.GetStallBreakdown(1)).To(Equal(stalls))
+	})
+
+	ginkgo.It("should panic if the GPU does not report stall breakdown",
+		func() {
+			Expect(func() { driver.GetStallBreakdown(1) }).To(Panic())
+		})
+})
@@ -0,0 +1,31 @@
+package driver
+
+import (
+	"log"
+)
+
+// A ThermalSensor reports the modeled temperature of a GPU.
+type ThermalSensor interface {
+	Temperature() float64
+}
+
+// RegisterThermalSensor sets the sensor that reports the temperature of the
+// given GPU.
+func (d *Driver) RegisterThermalSensor(gpuID int, sensor ThermalSensor) {
+	if d.thermalSensors == nil {
+		d.thermalSensors = make(map[int]ThermalSensor)
+	}
+
+	d.thermalSensors[gpuID] = sensor
+}
+
+// GetTemperature returns the modeled temperature, in degrees Celsius, of the
+// given GPU.
+func (d *Driver) GetTemperature(gpuID int) float64 {
+	sensor, found := d.thermalSensors[gpuID]
+	if !found {
+		log.Panicf("GPU %d does not have a thermal model", gpuID)
+	}
+
+	return sensor.Temperature()
+}
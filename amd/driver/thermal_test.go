@@ -0,0 +1,35 @@
+package driver
+
+import (
+	"github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// fakeThermalSensor reports a fixed temperature.
+type fakeThermalSensor struct {
+	temperature float64
+}
+
+func (s fakeThermalSensor) Temperature() float64 {
+	return s.temperature
+}
+
+var _ = ginkgo.Describe("Thermal Sensor", func() {
+	var driver *Driver
+
+	ginkgo.BeforeEach(func() {
+		driver = MakeBuilder().WithLog2PageSize(12).Build("Driver")
+	})
+
+	ginkgo.It("should report the temperature of a GPU", func() {
+		driver.RegisterThermalSensor(1, fakeThermalSensor{temperature: 60})
+		driver.RegisterThermalSensor(2, fakeThermalSensor{temperature: 70})
+
+		Expect(driver.GetTemperature(1)).To(Equal(60.0))
+		Expect(driver.GetTemperature(2)).To(Equal(70.0))
+	})
+
+	ginkgo.It("should panic if the GPU does not have a thermal model", func() {
+		Expect(func() { driver.GetTemperature(1) }).To(Panic())
+	})
+})
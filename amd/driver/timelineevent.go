@@ -0,0 +1,93 @@
+package driver
+
+import (
+	"sync"
+
+	"github.com/sarchlab/akita/v4/sim"
+)
+
+// A TimelineEvent marks a point in a command queue. It fires when all the
+// commands enqueued before it have completed.
+type TimelineEvent struct {
+	lock           sync.Mutex
+	fired          bool
+	completionTime sim.VTimeInSec
+}
+
+// IsComplete returns true if the event has fired.
+func (e *TimelineEvent) IsComplete() bool {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	return e.fired
+}
+
+// CompletionTime returns the simulated time when the event fires. It returns 0
+// if the event has not fired.
+func (e *TimelineEvent) CompletionTime() sim.VTimeInSec {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	return e.completionTime
+}
+
+func (e *TimelineEvent) fire(now sim.VTimeInSec) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	e.fired = true
+	e.completionTime = now
+}
+
+// A TimelineEventCommand is a command that fires a timeline event when the
+// command queue reaches it.
+type TimelineEventCommand struct {
+	ID    string
+	Event *TimelineEvent
+}
+
+// GetID returns the ID of the command
+func (c *TimelineEventCommand) GetID() string {
+	return c.ID
+}
+
+// GetReqs returns the request associated with the command
+func (c *TimelineEventCommand) GetReqs() []sim.Msg {
+	return nil
+}
+
+// AddReq adds a request to the request list associated with the command
+func (c *TimelineEventCommand) AddReq(req sim.Msg) {
+	// No action
+}
+
+// RemoveReq removes a request from the request list associated with the
+// command.
+func (c *TimelineEventCommand) RemoveReq(req sim.Msg) {
+	// No action
+}
+
+// EnqueueEvent places an event in the command queue and returns the event.
+// The event records the simulated time when the queue reaches it, so the time
+// between two events measures the commands enqueued between them.
+func (d *Driver) EnqueueEvent(queue *CommandQueue) *TimelineEvent {
+	event := &TimelineEvent{}
+
+	d.Enqueue(queue, &TimelineEventCommand{
+		ID:    sim.GetIDGenerator().Generate(),
+		Event: event,
+	})
+
+	return event
+}
+
+func (d *Driver) processTimelineEventCommand(
+	cmd *TimelineEventCommand,
+	queue *CommandQueue,
+) bool {
+	cmd.Event.fire(d.Engine.CurrentTime())
+	queue.Dequeue()
+	d.logCmdComplete(cmd)
+
+	return true
+}
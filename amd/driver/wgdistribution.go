@@ -0,0 +1,107 @@
+package driver
+
+import (
+	"github.com/sarchlab/mgpusim/v4/amd/kernels"
+)
+
+// WGDistribution determines how the work-groups of a kernel launched on a
+// unified GPU are assigned to the GPUs that form the unified GPU.
+type WGDistribution int
+
+// A list of all the supported work-group distribution schemes.
+const (
+	// WGDistributionBlock assigns a contiguous range of flattened
+	// work-group IDs to each GPU, proportional to the number of CUs of the
+	// GPU.
+	WGDistributionBlock WGDistribution = iota
+
+	// WGDistributionRoundRobin assigns work-group N to GPU N % numGPU.
+	WGDistributionRoundRobin
+)
+
+// createWGFilters returns a work-group filter for each of the GPUs that form
+// the unified GPU. The filter is nil if the GPU is not assigned any
+// work-group.
+func (d *Driver) createWGFilters(
+	queue *CommandQueue,
+	cmd *LaunchUnifiedMultiGPUKernelCommand,
+) []kernels.WGFilterFunc {
+	switch cmd.WGDistribution {
+	case WGDistributionBlock:
+		return d.createBlockWGFilters(queue, cmd)
+	case WGDistributionRoundRobin:
+		return d.createRoundRobinWGFilters(queue, cmd)
+	}
+
+	panic("unknown work-group distribution")
+}
+
+func (d *Driver) createBlockWGFilters(
+	queue *CommandQueue,
+	cmd *LaunchUnifiedMultiGPUKernelCommand,
+) []kernels.WGFilterFunc {
+	wgDist := d.distributeWGToGPUs(queue, cmd)
+	numGPUs := len(wgDist) - 1
+	filters := make([]kernels.WGFilterFunc, numGPUs)
+
+	for i := 0; i < numGPUs; i++ {
+		if wgDist[i+1]-wgDist[i] == 0 {
+			continue
+		}
+
+		currentGPUIndex := i
+		filters[i] = func(
+			pkt *kernels.HsaKernelDispatchPacket,
+			wg *kernels.WorkGroup,
+		) bool {
+			flattenedID := flattenedWGID(pkt, wg)
+
+			return flattenedID >= wgDist[currentGPUIndex] &&
+				flattenedID < wgDist[currentGPUIndex+1]
+		}
+	}
+
+	return filters
+}
+
+func (d *Driver) createRoundRobinWGFilters(
+	queue *CommandQueue,
+	cmd *LaunchUnifiedMultiGPUKernelCommand,
+) []kernels.WGFilterFunc {
+	dev := d.devices[queue.GPUID]
+	numGPUs := len(dev.UnifiedGPUIDs)
+	totalWGCount := numWGs(cmd.PacketArray[0])
+	filters := make([]kernels.WGFilterFunc, numGPUs)
+
+	for i := 0; i < numGPUs && i < totalWGCount; i++ {
+		currentGPUIndex := i
+		filters[i] = func(
+			pkt *kernels.HsaKernelDispatchPacket,
+			wg *kernels.WorkGroup,
+		) bool {
+			return flattenedWGID(pkt, wg)%numGPUs == currentGPUIndex
+		}
+	}
+
+	return filters
+}
+
+func numWGs(pkt *kernels.HsaKernelDispatchPacket) int {
+	numWGX := (pkt.GridSizeX-1)/uint32(pkt.WorkgroupSizeX) + 1
+	numWGY := (pkt.GridSizeY-1)/uint32(pkt.WorkgroupSizeY) + 1
+	numWGZ := (pkt.GridSizeZ-1)/uint32(pkt.WorkgroupSizeZ) + 1
+
+	return int(numWGX * numWGY * numWGZ)
+}
+
+func flattenedWGID(
+	pkt *kernels.HsaKernelDispatchPacket,
+	wg *kernels.WorkGroup,
+) int {
+	numWGX := (pkt.GridSizeX-1)/uint32(pkt.WorkgroupSizeX) + 1
+	numWGY := (pkt.GridSizeY-1)/uint32(pkt.WorkgroupSizeY) + 1
+
+	return wg.IDZ*int(numWGX)*int(numWGY) +
+		wg.IDY*int(numWGX) +
+		wg.IDX
+}
@@ -3,6 +3,7 @@ package emu
 import (
 	"log"
 
+	"github.com/sarchlab/mgpusim/v4/amd/atomic"
 	"github.com/sarchlab/mgpusim/v4/amd/insts"
 )
 
@@ -30,6 +31,11 @@ func (u *ALUImpl) runFlat(state InstEmuState) {
 	case 31:
 		u.runFlatStoreDWordX4(state)
 	default:
+		if _, _, ok := atomic.FlatOp(inst.Opcode); ok {
+			u.runFlatAtomic(state)
+			return
+		}
+
 		log.Panicf("Opcode %d for FLAT format is not implemented", inst.Opcode)
 	}
 }
@@ -177,3 +183,39 @@ func (u *ALUImpl) runFlatStoreDWordX4(state InstEmuState) {
 		u.storageAccessor.Write(pid, sp.ADDR[i], buf)
 	}
 }
+
+func (u *ALUImpl) runFlatAtomic(state InstEmuState) {
+	inst := state.Inst()
+	sp := state.Scratchpad().AsFlat()
+	pid := state.PID()
+	op, byteSize, _ := atomic.FlatOp(inst.Opcode)
+	numReg := int(byteSize / 4)
+
+	for i := uint(0); i < 64; i++ {
+		if !laneMasked(sp.EXEC, i) {
+			continue
+		}
+
+		info := &atomic.Info{
+			Op:      op,
+			Data:    flatLaneData(sp, i, 0, numReg),
+			CmpData: flatLaneData(sp, i, numReg, numReg),
+		}
+
+		old := u.storageAccessor.Read(pid, sp.ADDR[i], byteSize)
+		u.storageAccessor.Write(pid, sp.ADDR[i], info.Apply(old))
+
+		for j := 0; j < numReg; j++ {
+			sp.DST[int(i)*4+j] = insts.BytesToUint32(old[j*4 : j*4+4])
+		}
+	}
+}
+
+func flatLaneData(sp *FlatLayout, lane uint, firstReg, numReg int) []byte {
+	buf := make([]byte, numReg*4)
+	for j := 0; j < numReg; j++ {
+		copy(buf[j*4:j*4+4], insts.Uint32ToBytes(sp.DATA[int(lane)*4+firstReg+j]))
+	}
+
+	return buf
+}
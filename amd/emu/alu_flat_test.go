@@ -307,4 +307,64 @@ var _ = Describe("ALU", func() {
 			Expect(insts.BytesToUint32(buf[12:16])).To(Equal(uint32(i)))
 		}
 	})
+
+	It("should run FLAT_ATOMIC_ADD", func() {
+		pageTable.EXPECT().
+			Find(vm.PID(1), uint64(0x100)).
+			Return(vm.Page{
+				PAddr: uint64(0),
+			}, true).
+			AnyTimes()
+		state.inst = insts.NewInst()
+		state.inst.FormatType = insts.FLAT
+		state.inst.Opcode = 66
+
+		layout := state.Scratchpad().AsFlat()
+		for i := 0; i < 64; i++ {
+			layout.ADDR[i] = uint64(0x100)
+			layout.DATA[i*4] = uint32(1)
+		}
+		storage.Write(uint64(0x100), insts.Uint32ToBytes(uint32(10)))
+		layout.EXEC = 0xffffffffffffffff
+
+		alu.Run(state)
+
+		buf, err := storage.Read(uint64(0x100), uint64(4))
+		Expect(err).To(BeNil())
+		Expect(insts.BytesToUint32(buf)).To(Equal(uint32(74)))
+		for i := 0; i < 64; i++ {
+			Expect(layout.DST[i*4]).To(Equal(uint32(10 + i)))
+		}
+	})
+
+	It("should run FLAT_ATOMIC_CMPSWAP", func() {
+		pageTable.EXPECT().
+			Find(vm.PID(1), gomock.Any()).
+			Return(vm.Page{
+				PAddr: uint64(0),
+			}, true).
+			AnyTimes()
+		state.inst = insts.NewInst()
+		state.inst.FormatType = insts.FLAT
+		state.inst.Opcode = 65
+
+		layout := state.Scratchpad().AsFlat()
+		layout.ADDR[0] = uint64(0x100)
+		layout.DATA[0] = uint32(5)
+		layout.DATA[1] = uint32(1)
+		layout.ADDR[1] = uint64(0x104)
+		layout.DATA[4] = uint32(5)
+		layout.DATA[5] = uint32(2)
+		storage.Write(uint64(0x100), insts.Uint32ToBytes(uint32(1)))
+		storage.Write(uint64(0x104), insts.Uint32ToBytes(uint32(1)))
+		layout.EXEC = 0x3
+
+		alu.Run(state)
+
+		buf, _ := storage.Read(uint64(0x100), uint64(8))
+		Expect(insts.BytesToUint32(buf[0:4])).To(Equal(uint32(5)))
+		Expect(insts.BytesToUint32(buf[4:8])).To(Equal(uint32(1)))
+		Expect(layout.DST[0]).To(Equal(uint32(1)))
+		Expect(layout.DST[4]).To(Equal(uint32(1)))
+	})
 })
@@ -2,6 +2,7 @@ package emu
 
 import (
 	"encoding/binary"
+	"fmt"
 	"log"
 	"math"
 	"reflect"
@@ -40,6 +41,9 @@ type ComputeUnit struct {
 	ToDispatcher sim.Port
 
 	finishedMapWGReqs []string
+
+	// fault is the first fault that is not yet reported to the dispatcher.
+	fault error
 }
 
 // ControlPort returns the port that can receive controlling messages from the
@@ -142,7 +146,12 @@ func (cu *ComputeUnit) runWG(
 	for !cu.isAllWfCompleted(wg) {
 		for _, wf := range cu.wfs[wg] {
 			cu.alu.SetLDS(wf.LDS)
-			cu.runWfUntilBarrier(wf)
+
+			err := cu.runWfUntilBarrier(wf)
+			if err != nil {
+				cu.abortWG(wg, err)
+				break
+			}
 		}
 		cu.resolveBarrier(wg)
 	}
@@ -311,11 +320,38 @@ func (cu *ComputeUnit) isAllWfCompleted(wg *kernels.WorkGroup) bool {
 	return true
 }
 
-func (cu *ComputeUnit) runWfUntilBarrier(wf *Wavefront) error {
+// abortWG stops executing a work-group after one of its wavefronts encounters
+// a fault. The fault is reported when the work-group completes.
+func (cu *ComputeUnit) abortWG(wg *kernels.WorkGroup, fault error) {
+	if cu.fault == nil {
+		cu.fault = fault
+	}
+
+	for _, wf := range cu.wfs[wg] {
+		wf.Completed = true
+	}
+}
+
+func (cu *ComputeUnit) runWfUntilBarrier(wf *Wavefront) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			switch r.(type) {
+			case *memoryAccessFault, *uncorrectableMemoryFault:
+				err = fmt.Errorf("%w on %s", r.(error), cu.Name())
+			default:
+				panic(r)
+			}
+		}
+	}()
+
 	for {
 		instBuf := cu.storageAccessor.Read(wf.pid, wf.PC, 8)
 
-		inst, _ := cu.decoder.Decode(instBuf)
+		inst, decodeErr := cu.decoder.Decode(instBuf)
+		if decodeErr != nil {
+			return fmt.Errorf("illegal instruction at PC 0x%x on %s: %w",
+				wf.PC, cu.Name(), decodeErr)
+		}
 		wf.inst = inst
 
 		wf.PC += uint64(inst.ByteSize)
@@ -388,11 +424,13 @@ func (cu *ComputeUnit) handleWGCompleteEvent(evt *WGCompleteEvent) error {
 		WithSrc(cu.ToDispatcher.AsRemote()).
 		WithDst(evt.Req.Src).
 		WithRspTo(cu.finishedMapWGReqs).
+		WithFault(cu.fault).
 		Build()
 
 	err := cu.ToDispatcher.Send(req)
 	if err == nil {
 		cu.finishedMapWGReqs = nil
+		cu.fault = nil
 	} else {
 		newEvent := NewWGCompleteEvent(cu.Freq.NextTick(evt.Time()),
 			cu, evt.Req)
@@ -402,6 +440,15 @@ func (cu *ComputeUnit) handleWGCompleteEvent(evt *WGCompleteEvent) error {
 	return nil
 }
 
+// SetUncorrectableFaultInjector sets the predicate that decides which memory
+// reads encounter uncorrectable errors. A wavefront that reads faulty memory
+// stops executing, and the fault is reported as a kernel error.
+func (cu *ComputeUnit) SetUncorrectableFaultInjector(
+	predicate UncorrectableFaultPredicate,
+) {
+	cu.storageAccessor.faultInjector = predicate
+}
+
 // NewComputeUnit creates a new ComputeUnit with the given name
 func NewComputeUnit(
 	name string,
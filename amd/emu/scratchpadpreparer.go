@@ -4,6 +4,7 @@ import (
 	"log"
 	"math"
 
+	"github.com/sarchlab/mgpusim/v4/amd/atomic"
 	"github.com/sarchlab/mgpusim/v4/amd/insts"
 )
 
@@ -484,14 +485,21 @@ func (p *ScratchpadPreparerImpl) commitFlat(
 	scratchpad := instEmuState.Scratchpad()
 	exec := scratchpad.AsFlat().EXEC
 
-	if inst.Opcode < 24 || inst.Opcode > 31 { // Skip store instructions
-		for i := 0; i < 64; i++ {
-			if !laneMasked(exec, uint(i)) {
-				continue
-			}
+	if inst.Opcode >= 24 && inst.Opcode <= 31 { // Skip store instructions
+		return
+	}
 
-			p.writeOperand(inst.Dst, wf, i, scratchpad[1544+i*16:1544+i*16+16])
+	_, _, isAtomic := atomic.FlatOp(inst.Opcode)
+	if isAtomic && !inst.GlobalLevelCoherent { // Atomics that do not return
+		return
+	}
+
+	for i := 0; i < 64; i++ {
+		if !laneMasked(exec, uint(i)) {
+			continue
 		}
+
+		p.writeOperand(inst.Dst, wf, i, scratchpad[1544+i*16:1544+i*16+16])
 	}
 }
 
@@ -1,17 +1,45 @@
 package emu
 
 import (
+	"fmt"
 	"log"
 
 	"github.com/sarchlab/akita/v4/mem/mem"
 	"github.com/sarchlab/akita/v4/mem/vm"
 )
 
+// A memoryAccessFault is raised when a wavefront accesses a virtual address
+// that is not mapped in the page table.
+type memoryAccessFault struct {
+	vAddr uint64
+}
+
+func (f *memoryAccessFault) Error() string {
+	return fmt.Sprintf("illegal memory address 0x%x", f.vAddr)
+}
+
+// An UncorrectableFaultPredicate decides if reading the memory at a physical
+// address encounters an error that ECC detects but cannot correct.
+type UncorrectableFaultPredicate func(pAddr uint64) bool
+
+// An uncorrectableMemoryFault is raised when a wavefront reads data that has
+// an uncorrectable error.
+type uncorrectableMemoryFault struct {
+	vAddr, pAddr uint64
+}
+
+func (f *uncorrectableMemoryFault) Error() string {
+	return fmt.Sprintf(
+		"uncorrectable memory error at address 0x%x (physical address 0x%x)",
+		f.vAddr, f.pAddr)
+}
+
 type storageAccessor struct {
 	storage       *mem.Storage
 	addrConverter mem.AddressConverter
 	pageTable     vm.PageTable
 	log2PageSize  uint64
+	faultInjector UncorrectableFaultPredicate
 }
 
 func (a *storageAccessor) Read(pid vm.PID, vAddr, byteSize uint64) []byte {
@@ -30,10 +58,14 @@ func (a *storageAccessor) Read(pid vm.PID, vAddr, byteSize uint64) []byte {
 
 		page, found := a.pageTable.Find(pid, currVAddr)
 		if !found {
-			panic("page not found in page table")
+			panic(&memoryAccessFault{vAddr: currVAddr})
 		}
 		pAddr := page.PAddr + (currVAddr - page.VAddr)
 
+		if a.faultInjector != nil && a.faultInjector(pAddr) {
+			panic(&uncorrectableMemoryFault{vAddr: currVAddr, pAddr: pAddr})
+		}
+
 		storageAddr := pAddr
 		if a.addrConverter != nil {
 			storageAddr = a.addrConverter.ConvertExternalToInternal(pAddr)
@@ -68,7 +100,7 @@ func (a *storageAccessor) Write(pid vm.PID, vAddr uint64, data []byte) {
 
 		page, found := a.pageTable.Find(pid, vAddr)
 		if !found {
-			panic("page not found in page table")
+			panic(&memoryAccessFault{vAddr: vAddr})
 		}
 		pAddr := page.PAddr + (currVAddr - page.VAddr)
 
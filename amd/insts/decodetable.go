@@ -197,32 +197,32 @@ func (d *Disassembler) initializeDecodeTable() {
 	d.addInstType(&InstType{"flat_store_dwordx2", 29, FormatTable[FLAT], 0, ExeUnitVMem, 32, 32, 32, 0, 0})
 	d.addInstType(&InstType{"flat_store_dwordx3", 30, FormatTable[FLAT], 0, ExeUnitVMem, 32, 32, 32, 0, 0})
 	d.addInstType(&InstType{"flat_store_dwordx4", 31, FormatTable[FLAT], 0, ExeUnitVMem, 32, 32, 32, 0, 0})
-	d.addInstType(&InstType{"flat_atomic_swap", 48, FormatTable[FLAT], 0, ExeUnitVMem, 32, 32, 32, 0, 0})
-	d.addInstType(&InstType{"flat_atomic_cmpswap", 49, FormatTable[FLAT], 0, ExeUnitVMem, 32, 32, 32, 0, 0})
-	d.addInstType(&InstType{"flat_atomic_add", 50, FormatTable[FLAT], 0, ExeUnitVMem, 32, 32, 32, 0, 0})
-	d.addInstType(&InstType{"flat_atomic_sub", 51, FormatTable[FLAT], 0, ExeUnitVMem, 32, 32, 32, 0, 0})
-	d.addInstType(&InstType{"flat_atomic_smin", 53, FormatTable[FLAT], 0, ExeUnitVMem, 32, 32, 32, 0, 0})
-	d.addInstType(&InstType{"flat_atomic_umin", 54, FormatTable[FLAT], 0, ExeUnitVMem, 32, 32, 32, 0, 0})
-	d.addInstType(&InstType{"flat_atomic_smax", 55, FormatTable[FLAT], 0, ExeUnitVMem, 32, 32, 32, 0, 0})
-	d.addInstType(&InstType{"flag_atomic_umax", 56, FormatTable[FLAT], 0, ExeUnitVMem, 32, 32, 32, 0, 0})
-	d.addInstType(&InstType{"flat_atomic_and", 57, FormatTable[FLAT], 0, ExeUnitVMem, 32, 32, 32, 0, 0})
-	d.addInstType(&InstType{"flat_atomic_or", 58, FormatTable[FLAT], 0, ExeUnitVMem, 32, 32, 32, 0, 0})
-	d.addInstType(&InstType{"flat_atomic_xor", 59, FormatTable[FLAT], 0, ExeUnitVMem, 32, 32, 32, 0, 0})
-	d.addInstType(&InstType{"flat_atomic_inc", 60, FormatTable[FLAT], 0, ExeUnitVMem, 32, 32, 32, 0, 0})
-	d.addInstType(&InstType{"flat_atomic_dec", 61, FormatTable[FLAT], 0, ExeUnitVMem, 32, 32, 32, 0, 0})
-	d.addInstType(&InstType{"flat_atomic_swap_x2", 80, FormatTable[FLAT], 0, ExeUnitVMem, 32, 32, 32, 0, 0})
-	d.addInstType(&InstType{"flat_atomic_cmpswap_x2", 81, FormatTable[FLAT], 0, ExeUnitVMem, 32, 32, 32, 0, 0})
-	d.addInstType(&InstType{"flat_atomic_add_x2", 82, FormatTable[FLAT], 0, ExeUnitVMem, 32, 32, 32, 0, 0})
-	d.addInstType(&InstType{"flat_atomic_sub_x2", 83, FormatTable[FLAT], 0, ExeUnitVMem, 32, 32, 32, 0, 0})
-	d.addInstType(&InstType{"flat_atomic_smin_x2", 85, FormatTable[FLAT], 0, ExeUnitVMem, 32, 32, 32, 0, 0})
-	d.addInstType(&InstType{"flat_atomic_umin_x2", 86, FormatTable[FLAT], 0, ExeUnitVMem, 32, 32, 32, 0, 0})
-	d.addInstType(&InstType{"flat_atomic_smax_x2", 87, FormatTable[FLAT], 0, ExeUnitVMem, 32, 32, 32, 0, 0})
-	d.addInstType(&InstType{"flat_atomic_umax_x2", 88, FormatTable[FLAT], 0, ExeUnitVMem, 32, 32, 32, 0, 0})
-	d.addInstType(&InstType{"flat_atomic_and_x2", 89, FormatTable[FLAT], 0, ExeUnitVMem, 32, 32, 32, 0, 0})
-	d.addInstType(&InstType{"flat_atomic_or_x2", 90, FormatTable[FLAT], 0, ExeUnitVMem, 32, 32, 32, 0, 0})
-	d.addInstType(&InstType{"flat_atomic_xor_x2", 91, FormatTable[FLAT], 0, ExeUnitVMem, 32, 32, 32, 0, 0})
-	d.addInstType(&InstType{"flat_atomic_inc_x2", 92, FormatTable[FLAT], 0, ExeUnitVMem, 32, 32, 32, 0, 0})
-	d.addInstType(&InstType{"flat_atomic_dec_x2", 93, FormatTable[FLAT], 0, ExeUnitVMem, 32, 32, 32, 0, 0})
+	d.addInstType(&InstType{"flat_atomic_swap", 64, FormatTable[FLAT], 0, ExeUnitVMem, 32, 32, 32, 0, 0})
+	d.addInstType(&InstType{"flat_atomic_cmpswap", 65, FormatTable[FLAT], 0, ExeUnitVMem, 32, 32, 32, 0, 0})
+	d.addInstType(&InstType{"flat_atomic_add", 66, FormatTable[FLAT], 0, ExeUnitVMem, 32, 32, 32, 0, 0})
+	d.addInstType(&InstType{"flat_atomic_sub", 67, FormatTable[FLAT], 0, ExeUnitVMem, 32, 32, 32, 0, 0})
+	d.addInstType(&InstType{"flat_atomic_smin", 68, FormatTable[FLAT], 0, ExeUnitVMem, 32, 32, 32, 0, 0})
+	d.addInstType(&InstType{"flat_atomic_umin", 69, FormatTable[FLAT], 0, ExeUnitVMem, 32, 32, 32, 0, 0})
+	d.addInstType(&InstType{"flat_atomic_smax", 70, FormatTable[FLAT], 0, ExeUnitVMem, 32, 32, 32, 0, 0})
+	d.addInstType(&InstType{"flat_atomic_umax", 71, FormatTable[FLAT], 0, ExeUnitVMem, 32, 32, 32, 0, 0})
+	d.addInstType(&InstType{"flat_atomic_and", 72, FormatTable[FLAT], 0, ExeUnitVMem, 32, 32, 32, 0, 0})
+	d.addInstType(&InstType{"flat_atomic_or", 73, FormatTable[FLAT], 0, ExeUnitVMem, 32, 32, 32, 0, 0})
+	d.addInstType(&InstType{"flat_atomic_xor", 74, FormatTable[FLAT], 0, ExeUnitVMem, 32, 32, 32, 0, 0})
+	d.addInstType(&InstType{"flat_atomic_inc", 75, FormatTable[FLAT], 0, ExeUnitVMem, 32, 32, 32, 0, 0})
+	d.addInstType(&InstType{"flat_atomic_dec", 76, FormatTable[FLAT], 0, ExeUnitVMem, 32, 32, 32, 0, 0})
+	d.addInstType(&InstType{"flat_atomic_swap_x2", 96, FormatTable[FLAT], 0, ExeUnitVMem, 32, 32, 32, 0, 0})
+	d.addInstType(&InstType{"flat_atomic_cmpswap_x2", 97, FormatTable[FLAT], 0, ExeUnitVMem, 32, 32, 32, 0, 0})
+	d.addInstType(&InstType{"flat_atomic_add_x2", 98, FormatTable[FLAT], 0, ExeUnitVMem, 32, 32, 32, 0, 0})
+	d.addInstType(&InstType{"flat_atomic_sub_x2", 99, FormatTable[FLAT], 0, ExeUnitVMem, 32, 32, 32, 0, 0})
+	d.addInstType(&InstType{"flat_atomic_smin_x2", 100, FormatTable[FLAT], 0, ExeUnitVMem, 32, 32, 32, 0, 0})
+	d.addInstType(&InstType{"flat_atomic_umin_x2", 101, FormatTable[FLAT], 0, ExeUnitVMem, 32, 32, 32, 0, 0})
+	d.addInstType(&InstType{"flat_atomic_smax_x2", 102, FormatTable[FLAT], 0, ExeUnitVMem, 32, 32, 32, 0, 0})
+	d.addInstType(&InstType{"flat_atomic_umax_x2", 103, FormatTable[FLAT], 0, ExeUnitVMem, 32, 32, 32, 0, 0})
+	d.addInstType(&InstType{"flat_atomic_and_x2", 104, FormatTable[FLAT], 0, ExeUnitVMem, 32, 32, 32, 0, 0})
+	d.addInstType(&InstType{"flat_atomic_or_x2", 105, FormatTable[FLAT], 0, ExeUnitVMem, 32, 32, 32, 0, 0})
+	d.addInstType(&InstType{"flat_atomic_xor_x2", 106, FormatTable[FLAT], 0, ExeUnitVMem, 32, 32, 32, 0, 0})
+	d.addInstType(&InstType{"flat_atomic_inc_x2", 107, FormatTable[FLAT], 0, ExeUnitVMem, 32, 32, 32, 0, 0})
+	d.addInstType(&InstType{"flat_atomic_dec_x2", 108, FormatTable[FLAT], 0, ExeUnitVMem, 32, 32, 32, 0, 0})
 
 	// SMEM instructions
 	d.addInstType(&InstType{"s_load_dword", 0, FormatTable[SMEM], 0, ExeUnitScalar, 32, 32, 32, 0, 0})
@@ -366,9 +366,14 @@ func (d *Disassembler) decodeFLAT(inst *Inst, buf []byte) error {
 	inst.Data = NewVRegOperand(bits, bits, 0)
 
 	switch inst.Opcode {
-	case 21, 29, 80, 81, 82, 83, 84, 85, 86, 87, 88, 89, 90, 91, 92, 93:
+	case 21, 29, 96, 98, 99, 100, 101, 102, 103, 104, 105, 106, 107, 108:
 		inst.Data.RegCount = 2
 		inst.Dst.RegCount = 2
+	case 65: // FLAT_ATOMIC_CMPSWAP
+		inst.Data.RegCount = 2
+	case 97: // FLAT_ATOMIC_CMPSWAP_X2
+		inst.Data.RegCount = 4
+		inst.Dst.RegCount = 2
 	case 22, 30:
 		inst.Data.RegCount = 3
 		inst.Dst.RegCount = 3
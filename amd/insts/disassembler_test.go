@@ -272,4 +272,41 @@ var _ = Describe("Disassembler", func() {
 		Expect(inst.String(nil)).
 			To(Equal("ds_read_b128 v[17:20], v1 offset:128"))
 	})
+
+	It("should decode DD090000 01000402", func() {
+		buf := []byte{0x00, 0x00, 0x09, 0xdd, 0x02, 0x04, 0x00, 0x01}
+
+		inst, err := disassembler.Decode(buf)
+
+		Expect(err).To(BeNil())
+		Expect(inst.String(nil)).To(Equal("flat_atomic_add v1, v[2:3], v4 glc"))
+	})
+
+	It("should decode DD1C0000 00000402", func() {
+		buf := []byte{0x00, 0x00, 0x1c, 0xdd, 0x02, 0x04, 0x00, 0x00}
+
+		inst, err := disassembler.Decode(buf)
+
+		Expect(err).To(BeNil())
+		Expect(inst.String(nil)).To(Equal("flat_atomic_umax v[2:3], v4"))
+	})
+
+	It("should decode DD850000 00000402", func() {
+		buf := []byte{0x00, 0x00, 0x85, 0xdd, 0x02, 0x04, 0x00, 0x00}
+
+		inst, err := disassembler.Decode(buf)
+
+		Expect(err).To(BeNil())
+		Expect(inst.String(nil)).
+			To(Equal("flat_atomic_cmpswap_x2 v[0:1], v[2:3], v[4:7] glc"))
+	})
+
+	It("should decode DDB00000 00000402", func() {
+		buf := []byte{0x00, 0x00, 0xb0, 0xdd, 0x02, 0x04, 0x00, 0x00}
+
+		inst, err := disassembler.Decode(buf)
+
+		Expect(err).To(BeNil())
+		Expect(inst.String(nil)).To(Equal("flat_atomic_dec_x2 v[2:3], v[4:5]"))
+	})
 })
@@ -115,6 +115,12 @@ func (i Inst) flatString() string {
 	} else if i.Opcode >= 24 && i.Opcode <= 31 {
 		s = i.InstName + " " + i.Addr.String() + ", " +
 			i.Data.String()
+	} else if i.Opcode >= 64 && i.Opcode <= 108 {
+		s = i.InstName + " " + i.Addr.String() + ", " + i.Data.String()
+		if i.GlobalLevelCoherent {
+			s = i.InstName + " " + i.Dst.String() + ", " +
+				i.Addr.String() + ", " + i.Data.String() + " glc"
+		}
 	}
 	return s
 }
@@ -35,6 +35,12 @@ type WorkGroup struct {
 
 	Wavefronts []*Wavefront
 	WorkItems  []*WorkItem
+
+	// NumWfToReserve is the number of wavefronts that the CU reserves the
+	// resources for when the work-group is dispatched. If it is smaller than
+	// the number of wavefronts, only the wavefronts of the work-group reserve
+	// resources.
+	NumWfToReserve int
 }
 
 // NewWorkGroup creates a workgroup object.
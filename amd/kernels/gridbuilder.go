@@ -1,6 +1,10 @@
 package kernels
 
-import "github.com/sarchlab/mgpusim/v4/amd/insts"
+import (
+	"log"
+
+	"github.com/sarchlab/mgpusim/v4/amd/insts"
+)
 
 // WGFilterFunc is a filter
 type WGFilterFunc func(
@@ -14,6 +18,12 @@ type KernelLaunchInfo struct {
 	Packet     *HsaKernelDispatchPacket
 	PacketAddr uint64
 	WGFilter   WGFilterFunc
+
+	// MaxWorkGroupSize is the number of work-items that the resources of each
+	// work-group are reserved for, as declared by the launch bounds of the
+	// kernel. Zero means that each work-group only reserves the resources of
+	// its own work-items.
+	MaxWorkGroupSize int
 }
 
 // A GridBuilder is the unit that can build a grid and its internal structure
@@ -25,9 +35,20 @@ type GridBuilder interface {
 	Skip(n int)
 }
 
-// NewGridBuilder creates a default grid builder
+// NewGridBuilder creates a default grid builder, which forms wavefronts of 64
+// work-items.
 func NewGridBuilder() GridBuilder {
-	return &gridBuilderImpl{}
+	return NewGridBuilderWithWavefrontSize(64)
+}
+
+// NewGridBuilderWithWavefrontSize creates a grid builder that forms wavefronts
+// of the given number of work-items. The size can be 32 or 64.
+func NewGridBuilderWithWavefrontSize(wavefrontSize int) GridBuilder {
+	if wavefrontSize != 32 && wavefrontSize != 64 {
+		log.Panicf("wavefront size %d is not supported", wavefrontSize)
+	}
+
+	return &gridBuilderImpl{wavefrontSize: wavefrontSize}
 }
 
 type gridBuilderImpl struct {
@@ -37,6 +58,10 @@ type gridBuilderImpl struct {
 	packetAddr uint64
 	numWG      int
 
+	numWfToReserve int
+
+	wavefrontSize int
+
 	xid, yid, zid int
 }
 
@@ -47,6 +72,8 @@ func (b *gridBuilderImpl) SetKernel(
 	b.packet = info.Packet
 	b.packetAddr = info.PacketAddr
 	b.filter = info.WGFilter
+	b.numWfToReserve =
+		(info.MaxWorkGroupSize + b.wavefrontSize - 1) / b.wavefrontSize
 	b.xid = 0
 	b.yid = 0
 	b.zid = 0
@@ -110,6 +137,7 @@ func (b *gridBuilderImpl) NextWG() *WorkGroup {
 
 		wg.Packet = b.packet
 		wg.CodeObject = b.hsaco
+		wg.NumWfToReserve = b.numWfToReserve
 		wg.SizeX = int(b.packet.WorkgroupSizeX)
 		wg.SizeY = int(b.packet.WorkgroupSizeY)
 		wg.SizeZ = int(b.packet.WorkgroupSizeZ)
@@ -164,7 +192,7 @@ func (b *gridBuilderImpl) spawnWorkItems(wg *WorkGroup) {
 
 func (b *gridBuilderImpl) formWavefronts(wg *WorkGroup) {
 	var wf *Wavefront
-	wavefrontSize := 64
+	wavefrontSize := b.wavefrontSize
 	for i, wi := range wg.WorkItems {
 		wg := wi.WG
 		inWGID := wi.IDZ*wg.SizeX*wg.SizeY + wi.IDY*wg.SizeX + wi.IDX
@@ -13,7 +13,7 @@ var _ = Describe("GridBuilder", func() {
 	)
 
 	BeforeEach(func() {
-		builder = &gridBuilderImpl{}
+		builder = NewGridBuilder().(*gridBuilderImpl)
 	})
 
 	It("should build partial wavefront", func() {
@@ -42,6 +42,55 @@ var _ = Describe("GridBuilder", func() {
 			To(Equal(uint64(0x00000000ffffffff)))
 	})
 
+	It("should build wave32 wavefronts", func() {
+		builder = NewGridBuilderWithWavefrontSize(32).(*gridBuilderImpl)
+		codeObject := new(insts.HsaCo)
+		packet := new(HsaKernelDispatchPacket)
+		packet.WorkgroupSizeX = 64
+		packet.WorkgroupSizeY = 1
+		packet.WorkgroupSizeZ = 1
+		packet.GridSizeX = 48
+		packet.GridSizeY = 1
+		packet.GridSizeZ = 1
+		builder.SetKernel(KernelLaunchInfo{
+			CodeObject: codeObject,
+			Packet:     packet,
+			PacketAddr: 0,
+		})
+
+		wg := builder.NextWG()
+
+		Expect(wg.Wavefronts).To(HaveLen(2))
+		Expect(wg.Wavefronts[0].WorkItems).To(HaveLen(32))
+		Expect(wg.Wavefronts[0].InitExecMask).
+			To(Equal(uint64(0x00000000ffffffff)))
+		Expect(wg.Wavefronts[1].FirstWiFlatID).To(Equal(32))
+		Expect(wg.Wavefronts[1].WorkItems).To(HaveLen(16))
+		Expect(wg.Wavefronts[1].InitExecMask).
+			To(Equal(uint64(0x000000000000ffff)))
+	})
+
+	It("should ask for the wavefronts of the max work-group size", func() {
+		codeObject := new(insts.HsaCo)
+		packet := new(HsaKernelDispatchPacket)
+		packet.WorkgroupSizeX = 64
+		packet.WorkgroupSizeY = 1
+		packet.WorkgroupSizeZ = 1
+		packet.GridSizeX = 64
+		packet.GridSizeY = 1
+		packet.GridSizeZ = 1
+		builder.SetKernel(KernelLaunchInfo{
+			CodeObject:       codeObject,
+			Packet:           packet,
+			MaxWorkGroupSize: 200,
+		})
+
+		wg := builder.NextWG()
+
+		Expect(wg.Wavefronts).To(HaveLen(1))
+		Expect(wg.NumWfToReserve).To(Equal(4))
+	})
+
 	It("should build partial 2d wavefront", func() {
 		codeObject := new(insts.HsaCo)
 		packet := new(HsaKernelDispatchPacket)
@@ -3,6 +3,7 @@ package kernels
 import (
 	"bytes"
 	"debug/elf"
+	"io/fs"
 	"log"
 
 	"github.com/sarchlab/mgpusim/v4/amd/insts"
@@ -99,3 +100,18 @@ func LoadProgramFromMemory(data []byte, kernelName string) *insts.HsaCo {
 
 	return nil
 }
+
+// LoadProgramFromFS loads program from a file of a file system, such as an
+// embed.FS. It allows the code objects to be embedded into the binary or
+// served from a custom file system.
+func LoadProgramFromFS(
+	fsys fs.FS,
+	filePath, kernelName string,
+) *insts.HsaCo {
+	data, err := fs.ReadFile(fsys, filePath)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return LoadProgramFromMemory(data, kernelName)
+}
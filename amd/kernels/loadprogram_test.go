@@ -0,0 +1,30 @@
+package kernels
+
+import (
+	"os"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Load Program", func() {
+	const dir = "../benchmarks/heteromark/fir"
+
+	It("should load a kernel from a file system", func() {
+		expected := LoadProgram(dir+"/kernels.hsaco", "FIR")
+
+		hsaco := LoadProgramFromFS(os.DirFS(dir), "kernels.hsaco", "FIR")
+
+		Expect(hsaco).To(Equal(expected))
+	})
+
+	It("should load a kernel from memory", func() {
+		expected := LoadProgram(dir+"/kernels.hsaco", "FIR")
+		data, err := os.ReadFile(dir + "/kernels.hsaco")
+		Expect(err).To(BeNil())
+
+		hsaco := LoadProgramFromMemory(data, "FIR")
+
+		Expect(hsaco).To(Equal(expected))
+	})
+})
@@ -268,6 +268,9 @@ func (b MapWGReqBuilder) Build() *MapWGReq {
 type WGCompletionMsg struct {
 	sim.MsgMeta
 	RspTo []string
+
+	// Fault is not nil if the CU fails to execute one of the work-groups.
+	Fault error
 }
 
 // Meta returns the meta data associated with the MapWGReq.
@@ -287,6 +290,7 @@ func (r *WGCompletionMsg) Clone() sim.Msg {
 type WGCompletionMsgBuilder struct {
 	src, dst sim.RemotePort
 	rspTo    []string
+	fault    error
 }
 
 // WithSrc sets the source of the message.
@@ -313,6 +317,14 @@ func (b WGCompletionMsgBuilder) WithRspTo(
 	return b
 }
 
+// WithFault sets the fault that the CU encounters.
+func (b WGCompletionMsgBuilder) WithFault(
+	fault error,
+) WGCompletionMsgBuilder {
+	b.fault = fault
+	return b
+}
+
 // Build builds WGCompletionMsg
 func (b WGCompletionMsgBuilder) Build() *WGCompletionMsg {
 	msg := &WGCompletionMsg{}
@@ -320,5 +332,6 @@ func (b WGCompletionMsgBuilder) Build() *WGCompletionMsg {
 	msg.Meta().Src = b.src
 	msg.Meta().Dst = b.dst
 	msg.RspTo = b.rspTo
+	msg.Fault = b.fault
 	return msg
 }
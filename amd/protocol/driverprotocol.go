@@ -45,6 +45,11 @@ type LaunchKernelReq struct {
 	PacketAddress uint64
 	HsaCo         *insts.HsaCo
 	WGFilter      kernels.WGFilterFunc
+
+	// MaxWorkGroupSize is the maximum work-group size in the launch bounds of
+	// the kernel. If it is set, the dispatcher reserves the resources of a
+	// work-group of this size for every work-group of the kernel.
+	MaxWorkGroupSize int
 }
 
 // Meta returns the meta data associated with the message.
@@ -77,6 +82,10 @@ type LaunchKernelRsp struct {
 	sim.MsgMeta
 
 	RspTo string
+
+	// Fault is the first fault that the CUs encounter while executing the
+	// kernel. It is nil if the kernel completes successfully.
+	Fault error
 }
 
 // Meta returns the meta data associated with the message.
@@ -113,6 +122,11 @@ type MemCopyH2DReq struct {
 	sim.MsgMeta
 	SrcBuffer  []byte
 	DstAddress uint64
+
+	// ThroughCache asks the DMAEngine to write the data through the L2
+	// caches rather than to the DRAM directly, so that the kernels that are
+	// running see the data.
+	ThroughCache bool
 }
 
 // Meta returns the meta data associated with the message.
@@ -512,3 +526,205 @@ func NewRDMARestartRspToDriver(
 	cmd.Dst = dst.AsRemote()
 	return cmd
 }
+
+// PreemptKernelReq asks the GPU to stop executing the kernels and keep the
+// wavefronts on the CUs until a ResumeKernelReq arrives.
+type PreemptKernelReq struct {
+	sim.MsgMeta
+
+	StartTime sim.VTimeInSec
+	EndTime   sim.VTimeInSec
+}
+
+// Meta returns the meta data associated with the message.
+func (m *PreemptKernelReq) Meta() *sim.MsgMeta {
+	return &m.MsgMeta
+}
+
+// Clone returns a clone of the PreemptKernelReq with different ID.
+func (m *PreemptKernelReq) Clone() sim.Msg {
+	cloneMsg := *m
+	cloneMsg.ID = sim.GetIDGenerator().Generate()
+
+	return &cloneMsg
+}
+
+// NewPreemptKernelReq creates a PreemptKernelReq.
+func NewPreemptKernelReq(
+	src, dst sim.Port,
+) *PreemptKernelReq {
+	cmd := new(PreemptKernelReq)
+	cmd.ID = sim.GetIDGenerator().Generate()
+	cmd.Src = src.AsRemote()
+	cmd.Dst = dst.AsRemote()
+	return cmd
+}
+
+// PreemptKernelRsp is sent by the GPU when all the CUs have halted.
+type PreemptKernelRsp struct {
+	sim.MsgMeta
+
+	RspTo string
+}
+
+// Meta returns the meta data associated with the message.
+func (m *PreemptKernelRsp) Meta() *sim.MsgMeta {
+	return &m.MsgMeta
+}
+
+// Clone returns a clone of the PreemptKernelRsp with different ID.
+func (m *PreemptKernelRsp) Clone() sim.Msg {
+	cloneMsg := *m
+	cloneMsg.ID = sim.GetIDGenerator().Generate()
+
+	return &cloneMsg
+}
+
+// NewPreemptKernelRsp creates a PreemptKernelRsp.
+func NewPreemptKernelRsp(
+	src, dst sim.Port,
+	rspTo string,
+) *PreemptKernelRsp {
+	cmd := new(PreemptKernelRsp)
+	cmd.ID = sim.GetIDGenerator().Generate()
+	cmd.Src = src.AsRemote()
+	cmd.Dst = dst.AsRemote()
+	cmd.RspTo = rspTo
+	return cmd
+}
+
+// ResumeKernelReq asks a preempted GPU to continue executing the kernels.
+type ResumeKernelReq struct {
+	sim.MsgMeta
+
+	StartTime sim.VTimeInSec
+	EndTime   sim.VTimeInSec
+}
+
+// Meta returns the meta data associated with the message.
+func (m *ResumeKernelReq) Meta() *sim.MsgMeta {
+	return &m.MsgMeta
+}
+
+// Clone returns a clone of the ResumeKernelReq with different ID.
+func (m *ResumeKernelReq) Clone() sim.Msg {
+	cloneMsg := *m
+	cloneMsg.ID = sim.GetIDGenerator().Generate()
+
+	return &cloneMsg
+}
+
+// NewResumeKernelReq creates a ResumeKernelReq.
+func NewResumeKernelReq(
+	src, dst sim.Port,
+) *ResumeKernelReq {
+	cmd := new(ResumeKernelReq)
+	cmd.ID = sim.GetIDGenerator().Generate()
+	cmd.Src = src.AsRemote()
+	cmd.Dst = dst.AsRemote()
+	return cmd
+}
+
+// ResumeKernelRsp is sent by the GPU when all the CUs have resumed.
+type ResumeKernelRsp struct {
+	sim.MsgMeta
+
+	RspTo string
+}
+
+// Meta returns the meta data associated with the message.
+func (m *ResumeKernelRsp) Meta() *sim.MsgMeta {
+	return &m.MsgMeta
+}
+
+// Clone returns a clone of the ResumeKernelRsp with different ID.
+func (m *ResumeKernelRsp) Clone() sim.Msg {
+	cloneMsg := *m
+	cloneMsg.ID = sim.GetIDGenerator().Generate()
+
+	return &cloneMsg
+}
+
+// NewResumeKernelRsp creates a ResumeKernelRsp.
+func NewResumeKernelRsp(
+	src, dst sim.Port,
+	rspTo string,
+) *ResumeKernelRsp {
+	cmd := new(ResumeKernelRsp)
+	cmd.ID = sim.GetIDGenerator().Generate()
+	cmd.Src = src.AsRemote()
+	cmd.Dst = dst.AsRemote()
+	cmd.RspTo = rspTo
+	return cmd
+}
+
+// InvalidateTLBReq asks the GPU to remove the translations of the given pages
+// from all its TLBs.
+type InvalidateTLBReq struct {
+	sim.MsgMeta
+
+	PID    vm.PID
+	VAddrs []uint64
+}
+
+// Meta returns the meta data associated with the message.
+func (m *InvalidateTLBReq) Meta() *sim.MsgMeta {
+	return &m.MsgMeta
+}
+
+// Clone returns a clone of the InvalidateTLBReq with different ID.
+func (m *InvalidateTLBReq) Clone() sim.Msg {
+	cloneMsg := *m
+	cloneMsg.ID = sim.GetIDGenerator().Generate()
+
+	return &cloneMsg
+}
+
+// NewInvalidateTLBReq creates an InvalidateTLBReq.
+func NewInvalidateTLBReq(
+	src, dst sim.Port,
+	pid vm.PID,
+	vAddrs []uint64,
+) *InvalidateTLBReq {
+	cmd := new(InvalidateTLBReq)
+	cmd.ID = sim.GetIDGenerator().Generate()
+	cmd.Src = src.AsRemote()
+	cmd.Dst = dst.AsRemote()
+	cmd.PID = pid
+	cmd.VAddrs = vAddrs
+	return cmd
+}
+
+// InvalidateTLBRsp is sent by the GPU when the translations are removed from
+// all the TLBs.
+type InvalidateTLBRsp struct {
+	sim.MsgMeta
+
+	RspTo string
+}
+
+// Meta returns the meta data associated with the message.
+func (m *InvalidateTLBRsp) Meta() *sim.MsgMeta {
+	return &m.MsgMeta
+}
+
+// Clone returns a clone of the InvalidateTLBRsp with different ID.
+func (m *InvalidateTLBRsp) Clone() sim.Msg {
+	cloneMsg := *m
+	cloneMsg.ID = sim.GetIDGenerator().Generate()
+
+	return &cloneMsg
+}
+
+// NewInvalidateTLBRsp creates an InvalidateTLBRsp.
+func NewInvalidateTLBRsp(
+	src, dst sim.Port,
+	rspTo string,
+) *InvalidateTLBRsp {
+	cmd := new(InvalidateTLBRsp)
+	cmd.ID = sim.GetIDGenerator().Generate()
+	cmd.Src = src.AsRemote()
+	cmd.Dst = dst.AsRemote()
+	cmd.RspTo = rspTo
+	return cmd
+}
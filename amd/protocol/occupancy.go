@@ -0,0 +1,38 @@
+package protocol
+
+// OccupancyLimiter is the type of resource that limits how many work-groups
+// can be resident on a CU at the same time.
+type OccupancyLimiter int
+
+// A list of all the resources that may limit the occupancy of a CU.
+const (
+	OccupancyLimiterNone OccupancyLimiter = iota
+	OccupancyLimiterVGPR
+	OccupancyLimiterSGPR
+	OccupancyLimiterLDS
+	OccupancyLimiterWGSlots
+)
+
+func (l OccupancyLimiter) String() string {
+	switch l {
+	case OccupancyLimiterNone:
+		return "None"
+	case OccupancyLimiterVGPR:
+		return "VGPR"
+	case OccupancyLimiterSGPR:
+		return "SGPR"
+	case OccupancyLimiterLDS:
+		return "LDS"
+	case OccupancyLimiterWGSlots:
+		return "WGSlots"
+	}
+
+	return "Unknown"
+}
+
+// CUOccupancy describes the work-groups that are resident on a CU.
+type CUOccupancy struct {
+	NumWG   int
+	NumWf   int
+	Limiter OccupancyLimiter
+}
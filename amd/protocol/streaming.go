@@ -0,0 +1,21 @@
+package protocol
+
+import "github.com/sarchlab/akita/v4/mem/mem"
+
+// StreamingStoreInfo marks a write as a streaming (non-temporal) store. A
+// write request carries it in its Info field. The caches write a streaming
+// store through to the memory without allocating a line for it, so that the
+// data that is never read again does not evict other lines.
+type StreamingStoreInfo struct{}
+
+// IsStreamingStore returns true if the request is a streaming store.
+func IsStreamingStore(req mem.AccessReq) bool {
+	write, ok := req.(*mem.WriteReq)
+	if !ok {
+		return false
+	}
+
+	_, streaming := write.Info.(*StreamingStoreInfo)
+
+	return streaming
+}
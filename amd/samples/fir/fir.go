@@ -8,6 +8,8 @@ import (
 )
 
 var numData = flag.Int("length", 4096, "The number of samples to filter.")
+var numIterations = flag.Int("iterations", 1,
+	"The number of times that the filter runs.")
 
 func main() {
 	flag.Parse()
@@ -16,6 +18,7 @@ func main() {
 
 	benchmark := fir.NewBenchmark(runner.Driver())
 	benchmark.Length = *numData
+	benchmark.NumIterations = *numIterations
 
 	runner.AddBenchmark(benchmark)
 
@@ -0,0 +1,29 @@
+// Package main runs the same FIR filter on two GPUs that tick at different
+// frequencies. Run it with `-timing -gpus=1,2 -gpu-freqs=1000,1500
+// -report-all` and compare the kernel times of the two GPUs.
+package main
+
+import (
+	"flag"
+
+	"github.com/sarchlab/mgpusim/v4/amd/benchmarks/heteromark/fir"
+	"github.com/sarchlab/mgpusim/v4/amd/samples/runner"
+)
+
+var numData = flag.Int("length", 4096, "The number of samples to filter.")
+
+func main() {
+	flag.Parse()
+
+	runner := new(runner.Runner).Init()
+
+	for _, gpu := range runner.GPUIDs {
+		benchmark := fir.NewBenchmark(runner.Driver())
+		benchmark.Length = *numData
+		benchmark.SelectGPU([]int{gpu})
+
+		runner.AddBenchmarkWithoutSettingGPUsToUse(benchmark)
+	}
+
+	runner.Run()
+}
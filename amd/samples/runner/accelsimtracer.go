@@ -0,0 +1,194 @@
+package runner
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/sarchlab/akita/v4/mem/mem"
+	"github.com/sarchlab/akita/v4/tracing"
+	"github.com/sarchlab/mgpusim/v4/amd/timing/wavefront"
+)
+
+// accelSimTraceFormat describes the columns of the records. The columns follow
+// the instruction traces of Accel-Sim, with the CU that issues the access
+// added in front. Register operands are not traced, so the numbers of
+// destination and source registers are always 0.
+const accelSimTraceFormat = "#traces format = cu threadblock_x " +
+	"threadblock_y threadblock_z warpid_tb PC mask dest_num [reg_dests] " +
+	"opcode src_num [reg_srcs] mem_width [adrrescompress?] [mem_addresses]"
+
+// accelSimInst is a vector memory instruction that is being executed.
+type accelSimInst struct {
+	pc     uint64
+	mask   uint64
+	tbX    int
+	tbY    int
+	tbZ    int
+	warpID int
+}
+
+// An AccelSimTracer is a tracer that writes the vector memory accesses of the
+// CUs in a layout compatible with the Accel-Sim traces. Each record is a
+// cache-line access generated after coalescing, so each record lists a single
+// address. The PCs are relative to the entry of the kernel.
+type AccelSimTracer struct {
+	sync.Mutex
+
+	w   *bufio.Writer
+	err error
+
+	numCUs     int
+	numRecords int
+	insts      map[string]*accelSimInst
+}
+
+// NewAccelSimTracer creates an AccelSimTracer that writes the trace to the
+// given writer. The trace is only complete after Terminate is called.
+func NewAccelSimTracer(w io.Writer) *AccelSimTracer {
+	t := &AccelSimTracer{
+		w:     bufio.NewWriter(w),
+		insts: make(map[string]*accelSimInst),
+	}
+
+	t.write(accelSimTraceFormat + "\n")
+
+	return t
+}
+
+// NumRecords returns the number of accesses that are written.
+func (t *AccelSimTracer) NumRecords() int {
+	t.Lock()
+	defer t.Unlock()
+
+	return t.numRecords
+}
+
+// traceCU lets the tracer record the accesses of the given CU. The CUs are
+// numbered in the order that they are traced.
+func (t *AccelSimTracer) traceCU(cu tracing.NamedHookable) {
+	t.Lock()
+	cuID := t.numCUs
+	t.numCUs++
+	t.Unlock()
+
+	tracing.CollectTrace(cu, &accelSimCUTracer{tracer: t, cuID: cuID})
+}
+
+// Terminate flushes the trace. It returns the first error that occurs when
+// writing the trace.
+func (t *AccelSimTracer) Terminate() error {
+	t.Lock()
+	defer t.Unlock()
+
+	if t.err == nil {
+		t.err = t.w.Flush()
+	}
+
+	return t.err
+}
+
+func (t *AccelSimTracer) startInst(task tracing.Task) {
+	detail := task.Detail.(map[string]interface{})
+	wf := detail["wf"].(*wavefront.Wavefront)
+	wg := wf.Wavefront.WG
+
+	inst := &accelSimInst{
+		pc: wf.PC - wf.Packet.KernelObject -
+			wf.CodeObject.KernelCodeEntryByteOffset,
+		mask:   wf.EXEC,
+		tbX:    wg.IDX,
+		tbY:    wg.IDY,
+		tbZ:    wg.IDZ,
+		warpID: -1,
+	}
+
+	for i, w := range wg.Wavefronts {
+		if w == wf.Wavefront {
+			inst.warpID = i
+			break
+		}
+	}
+
+	t.Lock()
+	defer t.Unlock()
+
+	t.insts[task.ID] = inst
+}
+
+func (t *AccelSimTracer) endInst(task tracing.Task) {
+	t.Lock()
+	defer t.Unlock()
+
+	delete(t.insts, task.ID)
+}
+
+func (t *AccelSimTracer) writeAccess(cuID int, task tracing.Task) {
+	var opcode string
+	switch task.Detail.(type) {
+	case *mem.ReadReq:
+		opcode = "LDG"
+	case *mem.WriteReq:
+		opcode = "STG"
+	default:
+		return
+	}
+
+	t.Lock()
+	defer t.Unlock()
+
+	inst, found := t.insts[task.ParentID]
+	if !found {
+		return
+	}
+
+	req := task.Detail.(mem.AccessReq)
+	t.write(fmt.Sprintf("%d %d %d %d %d %04x %016x 0 %s 0 %d 0 0x%016x\n",
+		cuID, inst.tbX, inst.tbY, inst.tbZ, inst.warpID,
+		inst.pc, inst.mask, opcode,
+		req.GetByteSize(), req.GetAddress()))
+
+	t.numRecords++
+}
+
+func (t *AccelSimTracer) write(s string) {
+	if t.err != nil {
+		return
+	}
+
+	_, t.err = t.w.WriteString(s)
+}
+
+// accelSimCUTracer forwards the tasks of a CU to the AccelSimTracer.
+type accelSimCUTracer struct {
+	tracer *AccelSimTracer
+	cuID   int
+}
+
+// StartTask records the vector memory instructions and the accesses that
+// they generate.
+func (t *accelSimCUTracer) StartTask(task tracing.Task) {
+	switch {
+	case task.Kind == "inst" && task.What == "VMem":
+		t.tracer.startInst(task)
+	case task.Kind == "req_out":
+		t.tracer.writeAccess(t.cuID, task)
+	}
+}
+
+// StepTask does nothing.
+func (t *accelSimCUTracer) StepTask(_ tracing.Task) {
+	// Do nothing
+}
+
+// AddMilestone does nothing.
+func (t *accelSimCUTracer) AddMilestone(_ tracing.Milestone) {
+	// Do nothing
+}
+
+// EndTask forgets the instructions that are completed. Since the ended tasks
+// only carry their IDs, all the ended tasks are looked up.
+func (t *accelSimCUTracer) EndTask(task tracing.Task) {
+	t.tracer.endInst(task)
+}
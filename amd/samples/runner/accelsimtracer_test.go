@@ -0,0 +1,115 @@
+package runner
+
+import (
+	"bytes"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/sarchlab/akita/v4/mem/mem"
+	"github.com/sarchlab/akita/v4/tracing"
+	"github.com/sarchlab/mgpusim/v4/amd/insts"
+	"github.com/sarchlab/mgpusim/v4/amd/kernels"
+	"github.com/sarchlab/mgpusim/v4/amd/timing/wavefront"
+)
+
+var _ = Describe("Accel-Sim Tracer", func() {
+	var (
+		buf      *bytes.Buffer
+		tracer   *AccelSimTracer
+		cuTracer *accelSimCUTracer
+		wf       *wavefront.Wavefront
+	)
+
+	BeforeEach(func() {
+		buf = new(bytes.Buffer)
+		tracer = NewAccelSimTracer(buf)
+		cuTracer = &accelSimCUTracer{tracer: tracer, cuID: 3}
+
+		wg := kernels.NewWorkGroup()
+		wg.IDX, wg.IDY, wg.IDZ = 1, 2, 0
+
+		raw := kernels.NewWavefront()
+		raw.WG = wg
+		raw.Packet = &kernels.HsaKernelDispatchPacket{KernelObject: 0x1000}
+		raw.CodeObject = &insts.HsaCo{
+			HsaCoHeader: &insts.HsaCoHeader{KernelCodeEntryByteOffset: 256},
+		}
+		wg.Wavefronts = append(wg.Wavefronts, kernels.NewWavefront(), raw)
+
+		wf = wavefront.NewWavefront(raw)
+		wf.PC = 0x1120
+		wf.EXEC = 0xffff
+	})
+
+	startInst := func(id string) {
+		cuTracer.StartTask(tracing.Task{
+			ID:     id,
+			Kind:   "inst",
+			What:   "VMem",
+			Detail: map[string]interface{}{"wf": wf},
+		})
+	}
+
+	access := func(parentID string, req mem.AccessReq) {
+		cuTracer.StartTask(tracing.Task{
+			ID:       req.Meta().ID,
+			ParentID: parentID,
+			Kind:     "req_out",
+			Detail:   req,
+		})
+	}
+
+	records := func() []string {
+		Expect(tracer.Terminate()).To(Succeed())
+
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		Expect(lines[0]).To(Equal(accelSimTraceFormat))
+
+		return lines[1:]
+	}
+
+	It("should write a record for each access of a vector memory instruction",
+		func() {
+			startInst("inst")
+			access("inst", mem.ReadReqBuilder{}.
+				WithAddress(0x200040).
+				WithByteSize(64).
+				Build())
+			access("inst", mem.WriteReqBuilder{}.
+				WithAddress(0x300000).
+				WithData(make([]byte, 16)).
+				Build())
+
+			Expect(records()).To(Equal([]string{
+				"3 1 2 0 1 0020 000000000000ffff 0 LDG 0 64 0 " +
+					"0x0000000000200040",
+				"3 1 2 0 1 0020 000000000000ffff 0 STG 0 16 0 " +
+					"0x0000000000300000",
+			}))
+			Expect(tracer.NumRecords()).To(Equal(2))
+		})
+
+	It("should not write the accesses of other instructions", func() {
+		access("inst", mem.ReadReqBuilder{}.
+			WithAddress(0x200040).
+			WithByteSize(64).
+			Build())
+
+		Expect(records()).To(BeEmpty())
+		Expect(tracer.NumRecords()).To(Equal(0))
+	})
+
+	It("should not write the accesses after the instruction completes",
+		func() {
+			startInst("inst")
+			cuTracer.EndTask(tracing.Task{ID: "inst"})
+			access("inst", mem.ReadReqBuilder{}.
+				WithAddress(0x200040).
+				WithByteSize(64).
+				Build())
+
+			Expect(records()).To(BeEmpty())
+		})
+})
@@ -0,0 +1,174 @@
+package runner
+
+import (
+	"sync"
+
+	"github.com/sarchlab/akita/v4/analysis"
+	"github.com/sarchlab/akita/v4/mem/mem"
+	"github.com/sarchlab/akita/v4/sim"
+	"github.com/sarchlab/akita/v4/tracing"
+	"github.com/sarchlab/mgpusim/v4/amd/atomic"
+	"github.com/sarchlab/mgpusim/v4/amd/driver"
+)
+
+// An atomicStatsTracer counts the atomic accesses of the caches of a GPU. It
+// measures the latency of the atomics at the L1 vector caches. If a perf
+// logger is set, it also reports the count and the average latency of each
+// cache.
+type atomicStatsTracer struct {
+	timeTeller sim.TimeTeller
+	perfLogger analysis.PerfLogger
+
+	lock       sync.Mutex
+	l1vCaches  map[string]bool
+	inFlight   map[string]sim.VTimeInSec
+	inFlightAt map[string]string
+	caches     map[string]*atomicCacheStats
+}
+
+type atomicCacheStats struct {
+	count        uint64
+	completed    uint64
+	totalLatency sim.VTimeInSec
+}
+
+func newAtomicStatsTracer(
+	timeTeller sim.TimeTeller,
+	perfLogger analysis.PerfLogger,
+) *atomicStatsTracer {
+	return &atomicStatsTracer{
+		timeTeller: timeTeller,
+		perfLogger: perfLogger,
+		l1vCaches:  make(map[string]bool),
+		inFlight:   make(map[string]sim.VTimeInSec),
+		inFlightAt: make(map[string]string),
+		caches:     make(map[string]*atomicCacheStats),
+	}
+}
+
+// collectL1V lets the tracer count the atomics of an L1 vector cache.
+func (t *atomicStatsTracer) collectL1V(cache tracing.NamedHookable) {
+	t.l1vCaches[cache.Name()] = true
+	tracing.CollectTrace(cache, t)
+}
+
+// collectL2 lets the tracer count the atomics of an L2 cache.
+func (t *atomicStatsTracer) collectL2(cache tracing.NamedHookable) {
+	tracing.CollectTrace(cache, t)
+}
+
+// AtomicStats returns the statistics of the atomics recorded so far.
+func (t *atomicStatsTracer) AtomicStats() driver.AtomicStats {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	stats := driver.AtomicStats{}
+	completed := uint64(0)
+	totalLatency := sim.VTimeInSec(0)
+
+	for name, c := range t.caches {
+		if !t.l1vCaches[name] {
+			stats.NumL2Atomics += c.count
+			continue
+		}
+
+		stats.NumAtomics += c.count
+		completed += c.completed
+		totalLatency += c.totalLatency
+	}
+
+	if completed > 0 {
+		stats.AverageLatency = totalLatency / sim.VTimeInSec(completed)
+	}
+
+	return stats
+}
+
+// StartTask counts an atomic access that arrives at a cache.
+func (t *atomicStatsTracer) StartTask(task tracing.Task) {
+	if task.Kind != "req_in" {
+		return
+	}
+
+	req, ok := task.Detail.(mem.AccessReq)
+	if !ok || atomic.InfoOf(req) == nil {
+		return
+	}
+
+	now := t.timeTeller.CurrentTime()
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	c, found := t.caches[task.Where]
+	if !found {
+		c = &atomicCacheStats{}
+		t.caches[task.Where] = c
+	}
+
+	c.count++
+
+	if t.l1vCaches[task.Where] {
+		t.inFlight[task.ID] = now
+		t.inFlightAt[task.ID] = task.Where
+	}
+
+	t.report(task.Where, now, "AtomicCount", float64(c.count), "")
+}
+
+// StepTask does nothing.
+func (t *atomicStatsTracer) StepTask(_ tracing.Task) {
+	// Do nothing
+}
+
+// AddMilestone does nothing.
+func (t *atomicStatsTracer) AddMilestone(_ tracing.Milestone) {
+	// Do nothing
+}
+
+// EndTask records the latency of an atomic access that completes at an L1
+// vector cache.
+func (t *atomicStatsTracer) EndTask(task tracing.Task) {
+	now := t.timeTeller.CurrentTime()
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	start, found := t.inFlight[task.ID]
+	if !found {
+		return
+	}
+
+	where := t.inFlightAt[task.ID]
+	delete(t.inFlight, task.ID)
+	delete(t.inFlightAt, task.ID)
+
+	c := t.caches[where]
+	c.completed++
+	c.totalLatency += now - start
+
+	t.report(where, now, "AtomicAverageLatency",
+		float64(c.totalLatency/sim.VTimeInSec(c.completed)), "s")
+}
+
+func (t *atomicStatsTracer) report(
+	where string,
+	now sim.VTimeInSec,
+	what string,
+	value float64,
+	unit string,
+) {
+	if t.perfLogger == nil {
+		return
+	}
+
+	t.perfLogger.AddDataEntry(analysis.PerfAnalyzerEntry{
+		EntryType: "Cache",
+		Start:     now,
+		End:       now,
+		Where:     where,
+		What:      what,
+		Value:     value,
+		Unit:      unit,
+	})
+}
@@ -0,0 +1,64 @@
+package runner
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/sarchlab/akita/v4/mem/mem"
+	"github.com/sarchlab/akita/v4/sim"
+	"github.com/sarchlab/akita/v4/tracing"
+	"github.com/sarchlab/mgpusim/v4/amd/atomic"
+	"github.com/sarchlab/mgpusim/v4/amd/driver"
+)
+
+var _ = Describe("Atomic Stats Tracer", func() {
+	var (
+		timeTeller *fakeTimeTeller
+		tracer     *atomicStatsTracer
+	)
+
+	BeforeEach(func() {
+		timeTeller = &fakeTimeTeller{}
+		tracer = newAtomicStatsTracer(timeTeller, nil)
+		tracer.collectL1V(sim.NewComponentBase("L1V"))
+		tracer.collectL2(sim.NewComponentBase("L2"))
+	})
+
+	reqIn := func(id, where string, info *atomic.Info) tracing.Task {
+		return tracing.Task{
+			ID:    id,
+			Kind:  "req_in",
+			Where: where,
+			Detail: mem.ReadReqBuilder{}.
+				WithAddress(0x100).
+				WithByteSize(4).
+				WithInfo(info).
+				Build(),
+		}
+	}
+
+	It("should count the atomics of the L1V and the L2 caches", func() {
+		add := &atomic.Info{Op: atomic.OpAdd, Data: []byte{1, 0, 0, 0}}
+
+		timeTeller.now = 1
+		tracer.StartTask(reqIn("1", "L1V", add))
+		timeTeller.now = 2
+		tracer.StartTask(reqIn("2", "L2", add))
+		tracer.EndTask(tracing.Task{ID: "2"})
+		timeTeller.now = 4
+		tracer.EndTask(tracing.Task{ID: "1"})
+
+		Expect(tracer.AtomicStats()).To(Equal(driver.AtomicStats{
+			NumAtomics:     1,
+			NumL2Atomics:   1,
+			AverageLatency: 3,
+		}))
+	})
+
+	It("should not count the reads", func() {
+		tracer.StartTask(reqIn("1", "L1V", nil))
+		tracer.EndTask(tracing.Task{ID: "1"})
+
+		Expect(tracer.AtomicStats()).To(Equal(driver.AtomicStats{}))
+	})
+})
@@ -0,0 +1,222 @@
+package runner
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"sort"
+	"sync"
+
+	"github.com/sarchlab/akita/v4/sim"
+	"github.com/sarchlab/akita/v4/tracing"
+)
+
+// chromeTraceEvent is an event in the Chrome Trace Event format.
+type chromeTraceEvent struct {
+	Name string            `json:"name"`
+	Cat  string            `json:"cat,omitempty"`
+	Ph   string            `json:"ph"`
+	TS   float64           `json:"ts"`
+	PID  int               `json:"pid"`
+	TID  int               `json:"tid"`
+	Args map[string]string `json:"args,omitempty"`
+}
+
+// chromeTraceProcess groups the tasks of a component. Since the tasks of a
+// component may overlap without being nested, each in-flight task occupies a
+// thread of the process, so that the begin and end events on each thread are
+// always properly nested.
+type chromeTraceProcess struct {
+	pid         int
+	busyThreads []bool
+}
+
+type chromeTraceOpenTask struct {
+	task tracing.Task
+	pid  int
+	tid  int
+}
+
+// A ChromeTracer is a tracer that writes the tasks in the Chrome Trace Event
+// JSON format, which can be viewed with chrome://tracing or Perfetto. Each
+// component is shown as a process and each of the overlapping tasks of the
+// component is shown on a separate thread.
+type ChromeTracer struct {
+	sync.Mutex
+
+	timeTeller sim.TimeTeller
+	w          *bufio.Writer
+	err        error
+
+	numEvents int
+	processes map[string]*chromeTraceProcess
+	openTasks map[string]chromeTraceOpenTask
+}
+
+// NewChromeTracer creates a ChromeTracer that writes the trace to the given
+// writer. The trace is only complete after Terminate is called.
+func NewChromeTracer(w io.Writer) *ChromeTracer {
+	return &ChromeTracer{
+		w:         bufio.NewWriter(w),
+		processes: make(map[string]*chromeTraceProcess),
+		openTasks: make(map[string]chromeTraceOpenTask),
+	}
+}
+
+// setTimeTeller sets the component that provides the time of the events.
+func (t *ChromeTracer) setTimeTeller(timeTeller sim.TimeTeller) {
+	t.timeTeller = timeTeller
+}
+
+// StartTask writes a begin event.
+func (t *ChromeTracer) StartTask(task tracing.Task) {
+	t.Lock()
+	defer t.Unlock()
+
+	process := t.findOrCreateProcess(task.Where)
+	tid := process.acquireThread()
+
+	t.openTasks[task.ID] = chromeTraceOpenTask{
+		task: task,
+		pid:  process.pid,
+		tid:  tid,
+	}
+
+	t.writeEvent(chromeTraceEvent{
+		Name: task.What,
+		Cat:  task.Kind,
+		Ph:   "B",
+		TS:   t.now(),
+		PID:  process.pid,
+		TID:  tid,
+		Args: map[string]string{
+			"id":        task.ID,
+			"parent_id": task.ParentID,
+		},
+	})
+}
+
+// StepTask does nothing.
+func (t *ChromeTracer) StepTask(_ tracing.Task) {
+	// Do nothing
+}
+
+// AddMilestone does nothing.
+func (t *ChromeTracer) AddMilestone(_ tracing.Milestone) {
+	// Do nothing
+}
+
+// EndTask writes an end event.
+func (t *ChromeTracer) EndTask(task tracing.Task) {
+	t.Lock()
+	defer t.Unlock()
+
+	openTask, ok := t.openTasks[task.ID]
+	if !ok {
+		return
+	}
+
+	t.endTask(openTask)
+}
+
+func (t *ChromeTracer) endTask(openTask chromeTraceOpenTask) {
+	delete(t.openTasks, openTask.task.ID)
+	t.processes[openTask.task.Where].busyThreads[openTask.tid] = false
+
+	t.writeEvent(chromeTraceEvent{
+		Name: openTask.task.What,
+		Cat:  openTask.task.Kind,
+		Ph:   "E",
+		TS:   t.now(),
+		PID:  openTask.pid,
+		TID:  openTask.tid,
+	})
+}
+
+// Terminate ends all the tasks that are still running and completes the JSON
+// document. It returns the first error that occurs when writing the trace.
+func (t *ChromeTracer) Terminate() error {
+	t.Lock()
+	defer t.Unlock()
+
+	ids := make([]string, 0, len(t.openTasks))
+	for id := range t.openTasks {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		t.endTask(t.openTasks[id])
+	}
+
+	if t.numEvents == 0 {
+		t.write([]byte("["))
+	}
+	t.write([]byte("\n]\n"))
+
+	if t.err == nil {
+		t.err = t.w.Flush()
+	}
+
+	return t.err
+}
+
+func (t *ChromeTracer) findOrCreateProcess(where string) *chromeTraceProcess {
+	process, found := t.processes[where]
+	if found {
+		return process
+	}
+
+	process = &chromeTraceProcess{pid: len(t.processes) + 1}
+	t.processes[where] = process
+
+	t.writeEvent(chromeTraceEvent{
+		Name: "process_name",
+		Ph:   "M",
+		PID:  process.pid,
+		Args: map[string]string{"name": where},
+	})
+
+	return process
+}
+
+func (p *chromeTraceProcess) acquireThread() int {
+	for tid, busy := range p.busyThreads {
+		if !busy {
+			p.busyThreads[tid] = true
+			return tid
+		}
+	}
+
+	p.busyThreads = append(p.busyThreads, true)
+
+	return len(p.busyThreads) - 1
+}
+
+func (t *ChromeTracer) now() float64 {
+	return float64(t.timeTeller.CurrentTime()) * 1e6
+}
+
+func (t *ChromeTracer) writeEvent(event chromeTraceEvent) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		panic(err)
+	}
+
+	if t.numEvents == 0 {
+		t.write([]byte("[\n"))
+	} else {
+		t.write([]byte(",\n"))
+	}
+
+	t.write(data)
+	t.numEvents++
+}
+
+func (t *ChromeTracer) write(data []byte) {
+	if t.err != nil {
+		return
+	}
+
+	_, t.err = t.w.Write(data)
+}
@@ -0,0 +1,117 @@
+package runner
+
+import (
+	"bytes"
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/sarchlab/akita/v4/tracing"
+)
+
+var _ = Describe("Chrome Tracer", func() {
+	var (
+		buf        *bytes.Buffer
+		timeTeller *fakeTimeTeller
+		tracer     *ChromeTracer
+	)
+
+	BeforeEach(func() {
+		buf = new(bytes.Buffer)
+		timeTeller = &fakeTimeTeller{}
+		tracer = NewChromeTracer(buf)
+		tracer.setTimeTeller(timeTeller)
+	})
+
+	events := func() []chromeTraceEvent {
+		Expect(tracer.Terminate()).To(Succeed())
+
+		var events []chromeTraceEvent
+		Expect(json.Unmarshal(buf.Bytes(), &events)).To(Succeed())
+
+		return events
+	}
+
+	It("should write an empty trace", func() {
+		Expect(events()).To(BeEmpty())
+	})
+
+	It("should write the begin and end events of a task", func() {
+		task := tracing.Task{ID: "1", Kind: "req", What: "read", Where: "CU"}
+
+		timeTeller.now = 1e-6
+		tracer.StartTask(task)
+		timeTeller.now = 3e-6
+		tracer.EndTask(task)
+
+		Expect(events()).To(Equal([]chromeTraceEvent{
+			{
+				Name: "process_name", Ph: "M", PID: 1,
+				Args: map[string]string{"name": "CU"},
+			},
+			{
+				Name: "read", Cat: "req", Ph: "B", TS: 1, PID: 1, TID: 0,
+				Args: map[string]string{"id": "1", "parent_id": ""},
+			},
+			{Name: "read", Cat: "req", Ph: "E", TS: 3, PID: 1, TID: 0},
+		}))
+	})
+
+	It("should put overlapping tasks on different threads", func() {
+		task1 := tracing.Task{ID: "1", What: "read", Where: "CU"}
+		task2 := tracing.Task{ID: "2", What: "write", Where: "CU"}
+		task3 := tracing.Task{ID: "3", What: "read", Where: "CU"}
+
+		tracer.StartTask(task1)
+		tracer.StartTask(task2)
+		tracer.EndTask(task1)
+		tracer.StartTask(task3)
+		tracer.EndTask(task2)
+		tracer.EndTask(task3)
+
+		tids := map[string][]int{}
+		for _, e := range events() {
+			if e.Ph == "B" {
+				tids[e.Args["id"]] = append(tids[e.Args["id"]], e.TID)
+			}
+		}
+		Expect(tids).To(Equal(map[string][]int{
+			"1": {0},
+			"2": {1},
+			"3": {0},
+		}))
+	})
+
+	It("should put the tasks of each component in a process", func() {
+		tracer.StartTask(tracing.Task{ID: "1", Where: "CU"})
+		tracer.StartTask(tracing.Task{ID: "2", Where: "L1"})
+
+		pids := map[string]int{}
+		for _, e := range events() {
+			if e.Ph == "M" {
+				pids[e.Args["name"]] = e.PID
+			}
+		}
+		Expect(pids).To(Equal(map[string]int{"CU": 1, "L1": 2}))
+	})
+
+	It("should end the tasks that are running when terminated", func() {
+		tracer.StartTask(tracing.Task{ID: "1", Where: "CU"})
+		tracer.StartTask(tracing.Task{ID: "2", Where: "CU"})
+
+		var ended []int
+		for _, e := range events() {
+			if e.Ph == "E" {
+				ended = append(ended, e.TID)
+			}
+		}
+		Expect(ended).To(ConsistOf(0, 1))
+	})
+
+	It("should ignore the end of a task that is not started", func() {
+		tracer.EndTask(tracing.Task{ID: "1", Where: "CU"})
+
+		Expect(events()).To(BeEmpty())
+	})
+})
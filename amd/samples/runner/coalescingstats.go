@@ -0,0 +1,28 @@
+package runner
+
+import (
+	"github.com/sarchlab/mgpusim/v4/amd/driver"
+	"github.com/sarchlab/mgpusim/v4/amd/timing/cu"
+)
+
+// coalescingStatsReporter reports the memory access coalescing of the vector
+// memory units of all the CUs of a GPU.
+type coalescingStatsReporter []*cu.VectorMemoryUnit
+
+// CoalescingStats sums up the instructions and the transactions of all the
+// vector memory units.
+func (r coalescingStatsReporter) CoalescingStats() driver.CoalescingStats {
+	stats := driver.CoalescingStats{}
+	for _, unit := range r {
+		numInst, numTransaction := unit.CoalescingStats()
+		stats.NumVMemInsts += numInst
+		stats.NumTransactions += numTransaction
+	}
+
+	if stats.NumVMemInsts > 0 {
+		stats.AverageTransactions =
+			float64(stats.NumTransactions) / float64(stats.NumVMemInsts)
+	}
+
+	return stats
+}
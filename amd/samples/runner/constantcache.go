@@ -0,0 +1,58 @@
+package runner
+
+import (
+	"sync"
+
+	"github.com/sarchlab/akita/v4/mem/mem"
+	"github.com/sarchlab/akita/v4/sim"
+)
+
+type constantRange struct {
+	start, end uint64
+}
+
+// constantMemoryRouter keeps the physical address ranges of the constant
+// memory of a GPU.
+type constantMemoryRouter struct {
+	sync.RWMutex
+
+	ranges []constantRange
+}
+
+// AddConstantRange lets the accesses to the address range go to the constant
+// caches.
+func (r *constantMemoryRouter) AddConstantRange(pAddr, size uint64) {
+	r.Lock()
+	defer r.Unlock()
+
+	r.ranges = append(r.ranges, constantRange{start: pAddr, end: pAddr + size})
+}
+
+func (r *constantMemoryRouter) isConstant(addr uint64) bool {
+	r.RLock()
+	defer r.RUnlock()
+
+	for _, rng := range r.ranges {
+		if addr >= rng.start && addr < rng.end {
+			return true
+		}
+	}
+
+	return false
+}
+
+// constantAddressMapper sends the accesses to the constant memory to the
+// constant cache and all the other accesses to the default low module.
+type constantAddressMapper struct {
+	router        *constantMemoryRouter
+	constantCache sim.RemotePort
+	lowModule     mem.AddressToPortMapper
+}
+
+func (m *constantAddressMapper) Find(address uint64) sim.RemotePort {
+	if m.router.isConstant(address) {
+		return m.constantCache
+	}
+
+	return m.lowModule.Find(address)
+}
@@ -0,0 +1,44 @@
+package runner
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/sarchlab/akita/v4/mem/mem"
+	"github.com/sarchlab/akita/v4/sim"
+)
+
+var _ = Describe("Constant Address Mapper", func() {
+	var (
+		router *constantMemoryRouter
+		mapper *constantAddressMapper
+	)
+
+	BeforeEach(func() {
+		router = &constantMemoryRouter{}
+		mapper = &constantAddressMapper{
+			router:        router,
+			constantCache: "ConstantCache",
+			lowModule:     &mem.SinglePortMapper{Port: "L1V"},
+		}
+	})
+
+	It("should send all the accesses to the low module by default", func() {
+		Expect(mapper.Find(0x1000)).To(Equal(sim.RemotePort("L1V")))
+	})
+
+	It("should send the accesses to the constant memory to the constant cache",
+		func() {
+			router.AddConstantRange(0x1000, 0x100)
+			router.AddConstantRange(0x3000, 0x40)
+
+			Expect(mapper.Find(0x1000)).
+				To(Equal(sim.RemotePort("ConstantCache")))
+			Expect(mapper.Find(0x10ff)).
+				To(Equal(sim.RemotePort("ConstantCache")))
+			Expect(mapper.Find(0x303c)).
+				To(Equal(sim.RemotePort("ConstantCache")))
+			Expect(mapper.Find(0x1100)).To(Equal(sim.RemotePort("L1V")))
+			Expect(mapper.Find(0x3040)).To(Equal(sim.RemotePort("L1V")))
+		})
+})
@@ -0,0 +1,241 @@
+package runner
+
+import (
+	"encoding/csv"
+	"io"
+	"math"
+	"strconv"
+
+	"github.com/sarchlab/akita/v4/tracing"
+)
+
+var cacheCounterNames = []string{
+	"read-hit", "read-miss", "read-mshr-hit",
+	"write-hit", "write-miss", "write-mshr-hit",
+	"atomic-hit", "atomic-miss", "atomic-mshr-hit", "atomic-bypass",
+}
+
+var tlbCounterNames = []string{"hit", "miss", "mshr-hit"}
+
+// counterRow is a row in the CSV written by DumpCountersCSV.
+type counterRow struct {
+	component string
+	counter   string
+	value     float64
+}
+
+// addCounterTracers attaches the tracers that DumpCountersCSV, GetGPUStats,
+// and GetMemoryTrafficBreakdown read from.
+func (r *Runner) addCounterTracers() {
+	if !r.CollectCounters {
+		return
+	}
+
+	tracedDRAMs := make(map[TraceableComponent]bool)
+	for _, gpu := range r.platform.GPUs {
+		caches := append([]TraceableComponent{}, gpu.L1VCaches...)
+		caches = append(caches, gpu.L1SCaches...)
+		caches = append(caches, gpu.L1ICaches...)
+		caches = append(caches, gpu.L1Caches...)
+		caches = append(caches, gpu.L2Caches...)
+
+		for _, cache := range caches {
+			tracer := tracing.NewStepCountTracer(
+				func(task tracing.Task) bool { return true })
+			r.cacheCounters = append(r.cacheCounters,
+				cacheHitRateTracer{tracer: tracer, cache: cache})
+			tracing.CollectTrace(cache, tracer)
+
+			trafficTracer := newMemTrafficTracer()
+			r.cacheTrafficCounters = append(r.cacheTrafficCounters,
+				cacheTrafficTracer{tracer: trafficTracer, cache: cache})
+			tracing.CollectTrace(cache, trafficTracer)
+		}
+
+		tlbs := append([]TraceableComponent{}, gpu.L1VTLBs...)
+		tlbs = append(tlbs, gpu.L1STLBs...)
+		tlbs = append(tlbs, gpu.L1ITLBs...)
+		tlbs = append(tlbs, gpu.L2TLBs...)
+
+		for _, tlb := range tlbs {
+			tracer := tracing.NewStepCountTracer(
+				func(task tracing.Task) bool { return true })
+			r.tlbCounters = append(r.tlbCounters,
+				tlbHitRateTracer{tracer: tracer, tlb: tlb})
+			tracing.CollectTrace(tlb, tracer)
+		}
+
+		for _, cu := range gpu.CUs {
+			tracer := newInstTracer()
+			r.cuCounters = append(r.cuCounters,
+				instCountTracer{tracer: tracer, cu: cu})
+			tracing.CollectTrace(cu, tracer)
+		}
+
+		for _, dram := range gpu.MemControllers {
+			if tracedDRAMs[dram] {
+				continue
+			}
+			tracedDRAMs[dram] = true
+
+			tracer := newDramTracer(r.platform.Engine)
+			tracer.isScratchpad = r.platform.Driver.IsScratchpadAddress
+			r.dramCounters = append(r.dramCounters,
+				dramTransactionCountTracer{tracer: tracer, dram: dram})
+			tracing.CollectTrace(dram, tracer)
+		}
+
+		if gpu.RDMAEngine != nil {
+			tracer := newRDMATrafficTracer()
+			r.rdmaCounters = append(r.rdmaCounters,
+				rdmaTrafficCounter{tracer: tracer, rdma: gpu.RDMAEngine})
+			tracing.CollectTrace(gpu.RDMAEngine, tracer)
+		}
+	}
+
+	for _, cache := range r.sharedLLCBanks() {
+		tracer := tracing.NewStepCountTracer(
+			func(task tracing.Task) bool { return true })
+		r.cacheCounters = append(r.cacheCounters,
+			cacheHitRateTracer{tracer: tracer, cache: cache})
+		tracing.CollectTrace(cache, tracer)
+	}
+}
+
+// DumpCountersCSV writes the counters of the caches, the TLBs, the CUs, and
+// the DRAM controllers to w as a CSV file, with one counter per row. The
+// columns are the component name, the counter name, and the value. The
+// counters are only collected if CollectCounters is set.
+func (r *Runner) DumpCountersCSV(w io.Writer) error {
+	records := [][]string{{"component", "counter", "value"}}
+	for _, row := range r.counterRows() {
+		records = append(records, []string{
+			row.component,
+			row.counter,
+			strconv.FormatFloat(row.value, 'f', -1, 64),
+		})
+	}
+
+	return csv.NewWriter(w).WriteAll(records)
+}
+
+func (r *Runner) counterRows() []counterRow {
+	var rows []counterRow
+
+	for _, t := range r.cacheCounters {
+		for _, name := range cacheCounterNames {
+			rows = append(rows, counterRow{
+				t.cache.Name(), name, float64(t.tracer.GetStepCount(name))})
+		}
+	}
+
+	for _, t := range r.tlbCounters {
+		for _, name := range tlbCounterNames {
+			rows = append(rows, counterRow{
+				t.tlb.Name(), name, float64(t.tracer.GetStepCount(name))})
+		}
+	}
+
+	for _, t := range r.cuCounters {
+		rows = append(rows,
+			counterRow{t.cu.Name(), "inst_count", float64(t.tracer.count)},
+			counterRow{
+				t.cu.Name(), "simd_inst_count", float64(t.tracer.simdCount)},
+		)
+	}
+
+	for _, t := range r.dramCounters {
+		name := t.dram.Name()
+		rows = append(rows,
+			counterRow{name, "read_count", float64(t.tracer.readCount)},
+			counterRow{name, "write_count", float64(t.tracer.writeCount)},
+			counterRow{name, "read_bytes", float64(t.tracer.readSize)},
+			counterRow{name, "write_bytes", float64(t.tracer.writeSize)},
+			counterRow{name, "scratchpad_read_bytes",
+				float64(t.tracer.scratchpadReadSize)},
+			counterRow{name, "scratchpad_write_bytes",
+				float64(t.tracer.scratchpadWriteSize)},
+			counterRow{
+				name, "read_avg_latency", float64(t.tracer.readAvgLatency)},
+			counterRow{
+				name, "write_avg_latency", float64(t.tracer.writeAvgLatency)},
+		)
+	}
+
+	return rows
+}
+
+// A CounterDiff is a counter that has different values in two runs.
+type CounterDiff struct {
+	Component string
+	Counter   string
+
+	// Value is the value in the run that CompareWith is called on, and
+	// OtherValue is the value in the other run. A value is NaN if the counter
+	// does not exist in the run.
+	Value      float64
+	OtherValue float64
+}
+
+// CompareWith compares the counters that DumpCountersCSV writes with the
+// counters of another run and returns the counters that differ. The
+// components are matched by name, so the two runs should build the same
+// platform. It should be called after both runs complete. Since the
+// simulation is deterministic, any difference between two runs of the same
+// benchmark on the same configuration is caused by a change to the simulator.
+func (r *Runner) CompareWith(other *Runner) []CounterDiff {
+	type counterKey struct {
+		component string
+		counter   string
+	}
+
+	otherValues := make(map[counterKey]float64)
+	otherKeys := make([]counterKey, 0)
+	for _, row := range other.counterRows() {
+		key := counterKey{row.component, row.counter}
+		otherValues[key] = row.value
+		otherKeys = append(otherKeys, key)
+	}
+
+	diffs := make([]CounterDiff, 0)
+	found := make(map[counterKey]bool)
+	for _, row := range r.counterRows() {
+		key := counterKey{row.component, row.counter}
+		found[key] = true
+
+		otherValue, ok := otherValues[key]
+		if ok && isSameCounterValue(row.value, otherValue) {
+			continue
+		}
+
+		if !ok {
+			otherValue = math.NaN()
+		}
+
+		diffs = append(diffs, CounterDiff{
+			Component:  row.component,
+			Counter:    row.counter,
+			Value:      row.value,
+			OtherValue: otherValue,
+		})
+	}
+
+	for _, key := range otherKeys {
+		if !found[key] {
+			diffs = append(diffs, CounterDiff{
+				Component:  key.component,
+				Counter:    key.counter,
+				Value:      math.NaN(),
+				OtherValue: otherValues[key],
+			})
+		}
+	}
+
+	return diffs
+}
+
+// isSameCounterValue treats two NaN values, such as the average latencies of
+// components that serve no requests, as the same.
+func isSameCounterValue(a, b float64) bool {
+	return a == b || (math.IsNaN(a) && math.IsNaN(b))
+}
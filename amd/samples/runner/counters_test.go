@@ -0,0 +1,78 @@
+package runner
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"math"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/sarchlab/akita/v4/sim"
+)
+
+// runnerWithInstCounts returns a runner that has counted the instructions of
+// a CU for each of the given counts.
+func runnerWithInstCounts(counts ...uint64) *Runner {
+	r := &Runner{}
+	for i, count := range counts {
+		r.cuCounters = append(r.cuCounters, instCountTracer{
+			tracer: &instTracer{count: count, simdCount: count / 2},
+			cu:     sim.NewComponentBase(fmt.Sprintf("GPU[1].CU[%d]", i)),
+		})
+	}
+
+	return r
+}
+
+var _ = Describe("Counters", func() {
+	It("should dump the counters as CSV", func() {
+		r := runnerWithInstCounts(10, 20)
+
+		buf := new(bytes.Buffer)
+		Expect(r.DumpCountersCSV(buf)).To(Succeed())
+
+		records, err := csv.NewReader(buf).ReadAll()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(records).To(Equal([][]string{
+			{"component", "counter", "value"},
+			{"GPU[1].CU[0]", "inst_count", "10"},
+			{"GPU[1].CU[0]", "simd_inst_count", "5"},
+			{"GPU[1].CU[1]", "inst_count", "20"},
+			{"GPU[1].CU[1]", "simd_inst_count", "10"},
+		}))
+	})
+
+	It("should find no difference between the same counters", func() {
+		r := runnerWithInstCounts(10, 20)
+
+		Expect(r.CompareWith(runnerWithInstCounts(10, 20))).To(BeEmpty())
+	})
+
+	It("should find the counters that differ", func() {
+		r := runnerWithInstCounts(10, 20)
+
+		Expect(r.CompareWith(runnerWithInstCounts(10, 22))).To(Equal(
+			[]CounterDiff{
+				{"GPU[1].CU[1]", "inst_count", 20, 22},
+				{"GPU[1].CU[1]", "simd_inst_count", 10, 11},
+			}))
+	})
+
+	It("should find the counters that only exist in one run", func() {
+		r := runnerWithInstCounts(10)
+
+		diffs := r.CompareWith(runnerWithInstCounts(10, 20))
+
+		Expect(diffs).To(HaveLen(2))
+		Expect(diffs[0].Component).To(Equal("GPU[1].CU[1]"))
+		Expect(math.IsNaN(diffs[0].Value)).To(BeTrue())
+		Expect(diffs[0].OtherValue).To(Equal(20.0))
+	})
+
+	It("should treat two NaN values as the same", func() {
+		Expect(isSameCounterValue(math.NaN(), math.NaN())).To(BeTrue())
+		Expect(isSameCounterValue(math.NaN(), 0)).To(BeFalse())
+	})
+})
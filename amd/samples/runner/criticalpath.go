@@ -0,0 +1,166 @@
+package runner
+
+import (
+	"sync"
+
+	"github.com/sarchlab/akita/v4/sim"
+	"github.com/sarchlab/akita/v4/tracing"
+	"github.com/sarchlab/mgpusim/v4/amd/driver"
+)
+
+// criticalPathCommandKind is the kind of the tasks that the driver starts for
+// the commands. The critical paths of these tasks are kept after the tasks
+// complete.
+const criticalPathCommandKind = "Driver Command"
+
+type criticalPathTask struct {
+	id        string
+	kind      string
+	what      string
+	where     string
+	startTime sim.VTimeInSec
+	endTime   sim.VTimeInSec
+	ended     bool
+	parent    *criticalPathTask
+
+	// lastChild is the subtask that ends the last before the task ends.
+	lastChild *criticalPathTask
+
+	// numPending is the number of tasks in the subtree of the task, including
+	// the task itself, that have not ended.
+	numPending int
+}
+
+// criticalPathTracer records the task tree of the simulation, so that the
+// critical paths of the driver commands can be reconstructed after the
+// commands complete. A task only keeps the subtask that ends the last, and
+// the tasks are forgotten once all the tasks in their subtrees have ended,
+// so that the memory that the tracer uses does not grow with the length of
+// the simulation.
+type criticalPathTracer struct {
+	sync.Mutex
+
+	timeTeller sim.TimeTeller
+	tasks      map[string]*criticalPathTask
+	paths      map[string]driver.CriticalPath
+}
+
+func newCriticalPathTracer(timeTeller sim.TimeTeller) *criticalPathTracer {
+	return &criticalPathTracer{
+		timeTeller: timeTeller,
+		tasks:      make(map[string]*criticalPathTask),
+		paths:      make(map[string]driver.CriticalPath),
+	}
+}
+
+// StartTask records the task as a child of its parent.
+func (t *criticalPathTracer) StartTask(task tracing.Task) {
+	t.Lock()
+	defer t.Unlock()
+
+	if _, found := t.tasks[task.ID]; found {
+		return
+	}
+
+	cpTask := &criticalPathTask{
+		id:        task.ID,
+		kind:      task.Kind,
+		what:      task.What,
+		where:     task.Where,
+		startTime: t.timeTeller.CurrentTime(),
+		parent:    t.tasks[task.ParentID],
+	}
+	t.tasks[task.ID] = cpTask
+
+	for ancestor := cpTask; ancestor != nil; ancestor = ancestor.parent {
+		ancestor.numPending++
+	}
+}
+
+// StepTask does nothing.
+func (t *criticalPathTracer) StepTask(_ tracing.Task) {
+	// Do nothing
+}
+
+// AddMilestone does nothing.
+func (t *criticalPathTracer) AddMilestone(_ tracing.Milestone) {
+	// Do nothing
+}
+
+// EndTask records the time that the task ends and forgets the subtrees that
+// have no running tasks.
+func (t *criticalPathTracer) EndTask(task tracing.Task) {
+	t.Lock()
+	defer t.Unlock()
+
+	cpTask, found := t.tasks[task.ID]
+	if !found {
+		return
+	}
+
+	cpTask.endTime = t.timeTeller.CurrentTime()
+	cpTask.ended = true
+
+	parent := cpTask.parent
+	if parent != nil && (!parent.ended || cpTask.endTime <= parent.endTime) {
+		if parent.lastChild == nil ||
+			cpTask.endTime > parent.lastChild.endTime {
+			parent.lastChild = cpTask
+		}
+	}
+
+	if cpTask.kind == criticalPathCommandKind {
+		t.paths[cpTask.id] = criticalPathOf(cpTask)
+	}
+
+	for ancestor := cpTask; ancestor != nil; ancestor = ancestor.parent {
+		ancestor.numPending--
+		if ancestor.numPending == 0 {
+			delete(t.tasks, ancestor.id)
+		}
+	}
+}
+
+// CriticalPath returns the critical path of a driver command that has
+// completed.
+func (t *criticalPathTracer) CriticalPath(
+	taskID string,
+) (path driver.CriticalPath, found bool) {
+	t.Lock()
+	defer t.Unlock()
+
+	path, found = t.paths[taskID]
+
+	return path, found
+}
+
+// criticalPathOf follows the task tree from the given task, each time
+// stepping into the subtask that ends the last before its parent ends. The
+// subtasks that outlive their parents, such as the write-backs that complete
+// after the data is returned, do not delay the parents and are skipped. Since
+// only the parent-child relations are traced, a task that waits on another
+// subtree, such as an S_ENDPGM that waits for the stores of the wavefront,
+// ends the path with its waiting time as its self time.
+func criticalPathOf(task *criticalPathTask) (path driver.CriticalPath) {
+	path.Length = task.endTime - task.startTime
+	for task != nil {
+		next := task.lastChild
+
+		step := driver.CriticalPathStep{
+			Component: task.where,
+			Kind:      task.kind,
+			What:      task.what,
+			StartTime: task.startTime,
+			EndTime:   task.endTime,
+			SelfTime:  task.endTime - task.startTime,
+		}
+		if next != nil {
+			step.SelfTime -= next.endTime - next.startTime
+		}
+
+		path.Steps = append(path.Steps, step)
+		task = next
+	}
+
+	return path
+}
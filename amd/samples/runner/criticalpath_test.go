@@ -0,0 +1,98 @@
+package runner
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/sarchlab/akita/v4/sim"
+	"github.com/sarchlab/akita/v4/tracing"
+	"github.com/sarchlab/mgpusim/v4/amd/driver"
+)
+
+var _ = Describe("Critical Path Tracer", func() {
+	var (
+		timeTeller *fakeTimeTeller
+		tracer     *criticalPathTracer
+	)
+
+	BeforeEach(func() {
+		timeTeller = &fakeTimeTeller{}
+		tracer = newCriticalPathTracer(timeTeller)
+
+		tracer.StartTask(tracing.Task{ID: "sim", Kind: "Simulation"})
+		tracer.StartTask(tracing.Task{
+			ID: "cmd", ParentID: "sim", Kind: criticalPathCommandKind})
+	})
+
+	startAt := func(now sim.VTimeInSec, id, parentID, where string) {
+		timeTeller.now = now
+		tracer.StartTask(tracing.Task{ID: id, ParentID: parentID, Where: where})
+	}
+
+	endAt := func(now sim.VTimeInSec, id string) {
+		timeTeller.now = now
+		tracer.EndTask(tracing.Task{ID: id})
+	}
+
+	selfTimes := func(path driver.CriticalPath) []sim.VTimeInSec {
+		var times []sim.VTimeInSec
+		for _, step := range path.Steps {
+			times = append(times, step.SelfTime)
+		}
+
+		return times
+	}
+
+	It("should follow the subtask that ends the last", func() {
+		startAt(0, "read", "cmd", "L2")
+		startAt(0, "write", "cmd", "L2")
+		startAt(1, "dram", "write", "DRAM")
+		endAt(2, "read")
+		endAt(3, "dram")
+		endAt(4, "write")
+		endAt(5, "cmd")
+
+		path, found := tracer.CriticalPath("cmd")
+
+		Expect(found).To(BeTrue())
+		Expect(path.Length).To(Equal(sim.VTimeInSec(5)))
+		Expect(path.Steps[2].Component).To(Equal("DRAM"))
+		Expect(selfTimes(path)).To(Equal([]sim.VTimeInSec{1, 2, 2}))
+	})
+
+	It("should skip the subtasks that outlive their parents", func() {
+		startAt(0, "read", "cmd", "L2")
+		startAt(0, "writeback", "cmd", "L2")
+		endAt(2, "read")
+		endAt(3, "cmd")
+		endAt(4, "writeback")
+
+		path, _ := tracer.CriticalPath("cmd")
+
+		Expect(path.Length).To(Equal(sim.VTimeInSec(3)))
+		Expect(selfTimes(path)).To(Equal([]sim.VTimeInSec{1, 2}))
+	})
+
+	It("should forget the subtrees that have ended", func() {
+		startAt(0, "read", "cmd", "L2")
+		startAt(0, "write", "cmd", "L2")
+		endAt(2, "read")
+		endAt(3, "cmd")
+
+		Expect(tracer.tasks).NotTo(HaveKey("read"))
+		Expect(tracer.tasks).To(HaveKey("cmd"))
+
+		endAt(4, "write")
+
+		_, found := tracer.CriticalPath("cmd")
+
+		Expect(tracer.tasks).To(HaveLen(1))
+		Expect(found).To(BeTrue())
+	})
+
+	It("should not find the path of a command that has not ended", func() {
+		_, found := tracer.CriticalPath("cmd")
+
+		Expect(found).To(BeFalse())
+	})
+})
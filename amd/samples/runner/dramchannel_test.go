@@ -0,0 +1,24 @@
+package runner
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("DRAM Channels", func() {
+	It("should increase the bandwidth of the DRAM", func() {
+		oneChannel := measureStreamingBandwidth(MakeR9NanoGPUBuilder())
+		twoChannels := measureStreamingBandwidth(
+			MakeR9NanoGPUBuilder().WithDRAMChannelsPerBank(2))
+
+		// The streaming accesses spread evenly over the channels, so the
+		// bandwidth should almost double.
+		Expect(twoChannels).To(BeNumerically(">=", 1.8*oneChannel))
+	})
+
+	It("should panic if a channel has less than one rank", func() {
+		builder := MakeR9NanoGPUBuilder().WithDRAMChannelsPerBank(4)
+
+		Expect(func() { measureStreamingBandwidth(builder) }).To(Panic())
+	})
+})
@@ -0,0 +1,26 @@
+package runner
+
+import (
+	"github.com/sarchlab/mgpusim/v4/amd/driver"
+	"github.com/sarchlab/mgpusim/v4/amd/timing/dram"
+)
+
+// dramQoSController sets the QoS classes in all the DRAM controllers that a
+// GPU uses. Each controller only serves the addresses that are mapped to it,
+// so setting a class in all the controllers is harmless.
+type dramQoSController []*dram.Comp
+
+// SetQoSClass sets the QoS class of the accesses to the physical address range.
+func (c dramQoSController) SetQoSClass(
+	pAddr, size uint64,
+	class driver.MemQoSClass,
+) {
+	dramClass := dram.QoSBandwidth
+	if class == driver.MemQoSLatencyCritical {
+		dramClass = dram.QoSLatencyCritical
+	}
+
+	for _, memCtrl := range c {
+		memCtrl.SetQoSClass(pAddr, size, dramClass)
+	}
+}
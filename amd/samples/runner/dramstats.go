@@ -0,0 +1,28 @@
+package runner
+
+import (
+	"github.com/sarchlab/mgpusim/v4/amd/driver"
+	"github.com/sarchlab/mgpusim/v4/amd/timing/dram"
+)
+
+// dramRowBufferStatsReporter reports the row buffer usage of the banks of all
+// the DRAM controllers that a GPU uses.
+type dramRowBufferStatsReporter []*dram.Comp
+
+// DRAMRowBufferStats returns the row buffer stats of each DRAM bank.
+func (r dramRowBufferStatsReporter) DRAMRowBufferStats() []driver.DRAMRowBufferStats {
+	var stats []driver.DRAMRowBufferStats
+
+	for _, memCtrl := range r {
+		for _, s := range memCtrl.RowBufferStats() {
+			stats = append(stats, driver.DRAMRowBufferStats{
+				Bank:      s.Bank,
+				Hits:      s.Hits,
+				Misses:    s.Misses,
+				Conflicts: s.Conflicts,
+			})
+		}
+	}
+
+	return stats
+}
@@ -0,0 +1,103 @@
+package runner
+
+import (
+	"math/rand"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/sarchlab/akita/v4/mem/mem"
+	"github.com/sarchlab/akita/v4/sim"
+	"github.com/sarchlab/akita/v4/sim/directconnection"
+)
+
+// randomAccessAgent reads cache lines from random addresses. It keeps a fixed
+// number of reads in flight.
+type randomAccessAgent struct {
+	*sim.TickingComponent
+
+	port           sim.Port
+	dst            sim.RemotePort
+	rand           *rand.Rand
+	addrRange      uint64
+	numReqLeft     int
+	maxOutstanding int
+	numOutstanding int
+}
+
+func (a *randomAccessAgent) Tick() bool {
+	madeProgress := false
+
+	if a.port.RetrieveIncoming() != nil {
+		a.numOutstanding--
+		madeProgress = true
+	}
+
+	if a.numReqLeft > 0 && a.numOutstanding < a.maxOutstanding {
+		addr := uint64(a.rand.Int63n(int64(a.addrRange/64))) * 64
+		req := mem.ReadReqBuilder{}.
+			WithSrc(a.port.AsRemote()).
+			WithDst(a.dst).
+			WithAddress(addr).
+			WithByteSize(64).
+			Build()
+		if a.port.Send(req) == nil {
+			a.numReqLeft--
+			a.numOutstanding++
+			madeProgress = true
+		}
+	}
+
+	return madeProgress
+}
+
+// measureRandomAccessBandwidth reads cache lines from random addresses of a
+// DRAM controller and returns the number of data bytes that are read per
+// second.
+func measureRandomAccessBandwidth(b R9NanoGPUBuilder) float64 {
+	const numReq = 16384
+
+	engine := sim.NewSerialEngine()
+	b = b.WithEngine(engine)
+
+	dramBuilder := b.createDramControllerBuilder(4 * mem.GB)
+	dram := dramBuilder.Build("DRAM")
+
+	agent := &randomAccessAgent{
+		dst:            dram.GetPortByName("Top").AsRemote(),
+		rand:           rand.New(rand.NewSource(1)),
+		addrRange:      256 * mem.MB,
+		numReqLeft:     numReq,
+		maxOutstanding: 1024,
+	}
+	agent.TickingComponent = sim.NewTickingComponent(
+		"Agent", engine, 1*sim.GHz, agent)
+	agent.port = sim.NewPort(agent, 64, 64, "Agent.ToDRAM")
+
+	conn := directconnection.MakeBuilder().
+		WithEngine(engine).
+		WithFreq(1 * sim.GHz).
+		Build("Conn")
+	conn.PlugIn(agent.port)
+	conn.PlugIn(dram.GetPortByName("Top"))
+
+	agent.TickLater()
+	if err := engine.Run(); err != nil {
+		panic(err)
+	}
+
+	return numReq * 64 / float64(engine.CurrentTime())
+}
+
+var _ = Describe("DRAM Sub-Channels", func() {
+	It("should increase the random access bandwidth", func() {
+		oneSubChannel := measureRandomAccessBandwidth(MakeR9NanoGPUBuilder())
+		twoSubChannels := measureRandomAccessBandwidth(
+			MakeR9NanoGPUBuilder().WithDRAMSubChannels(2))
+
+		// A 64-byte read only uses half of a burst on the full bus, but a
+		// full burst on a sub-channel. Two sub-channels also double the
+		// number of banks that can serve the random reads in parallel.
+		Expect(twoSubChannels).To(BeNumerically(">", 1.2*oneSubChannel))
+	})
+})
@@ -21,6 +21,12 @@ type dramTracer struct {
 	writeAvgLatency sim.VTimeInSec
 	readSize        uint64
 	writeSize       uint64
+
+	// isScratchpad tells if an address belongs to a scratchpad region. The
+	// scratchpad traffic is only counted if it is set.
+	isScratchpad        func(pAddr uint64) bool
+	scratchpadReadSize  uint64
+	scratchpadWriteSize uint64
 }
 
 func newDramTracer(timeTeller sim.TimeTeller) *dramTracer {
@@ -65,17 +71,27 @@ func (t *dramTracer) EndTask(task tracing.Task) {
 
 	switch originalTask.What {
 	case "*mem.ReadReq":
+		req := originalTask.Detail.(*mem.ReadReq)
 		t.readAvgLatency = sim.VTimeInSec(
 			(float64(t.readAvgLatency)*float64(t.readCount) +
 				float64(taskTime)) / float64(t.readCount+1))
 		t.readCount++
-		t.readSize += originalTask.Detail.(*mem.ReadReq).AccessByteSize
+		t.readSize += req.AccessByteSize
+
+		if t.isScratchpad != nil && t.isScratchpad(req.Address) {
+			t.scratchpadReadSize += req.AccessByteSize
+		}
 	case "*mem.WriteReq":
 		t.writeAvgLatency = sim.VTimeInSec(
 			(float64(t.writeAvgLatency)*float64(t.writeCount) +
 				float64(taskTime)) / float64(t.writeCount+1))
 		t.writeCount++
-		t.writeSize += uint64(len(originalTask.Detail.(*mem.WriteReq).Data))
+		req := originalTask.Detail.(*mem.WriteReq)
+		t.writeSize += uint64(len(req.Data))
+
+		if t.isScratchpad != nil && t.isScratchpad(req.Address) {
+			t.scratchpadWriteSize += uint64(len(req.Data))
+		}
 	}
 
 	delete(t.inflightTasks, task.ID)
@@ -0,0 +1,85 @@
+package runner
+
+import (
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/sarchlab/akita/v4/mem/mem"
+	"github.com/sarchlab/akita/v4/tracing"
+)
+
+var _ = Describe("DRAM Tracer", func() {
+	var (
+		timeTeller *fakeTimeTeller
+		tracer     *dramTracer
+	)
+
+	BeforeEach(func() {
+		timeTeller = &fakeTimeTeller{}
+		tracer = newDramTracer(timeTeller)
+	})
+
+	// access traces a request that the DRAM serves in 10 ns.
+	access := func(req mem.AccessReq) {
+		task := tracing.Task{
+			ID:     req.Meta().ID,
+			What:   fmt.Sprintf("%T", req),
+			Detail: req,
+		}
+
+		tracer.StartTask(task)
+		timeTeller.now += 10e-9
+		tracer.EndTask(tracing.Task{ID: task.ID})
+	}
+
+	read := func(addr uint64) *mem.ReadReq {
+		return mem.ReadReqBuilder{}.
+			WithAddress(addr).
+			WithByteSize(64).
+			Build()
+	}
+
+	write := func(addr uint64) *mem.WriteReq {
+		return mem.WriteReqBuilder{}.
+			WithAddress(addr).
+			WithData(make([]byte, 32)).
+			Build()
+	}
+
+	It("should count the bytes and the latency of the accesses", func() {
+		access(read(0x0))
+		access(read(0x40))
+		access(write(0x80))
+
+		Expect(tracer.readCount).To(Equal(2))
+		Expect(tracer.readSize).To(Equal(uint64(128)))
+		Expect(tracer.writeCount).To(Equal(1))
+		Expect(tracer.writeSize).To(Equal(uint64(32)))
+		Expect(float64(tracer.readAvgLatency)).To(BeNumerically("~", 10e-9))
+	})
+
+	It("should not count scratchpad traffic if it cannot tell", func() {
+		access(read(0x1000))
+
+		Expect(tracer.scratchpadReadSize).To(BeZero())
+	})
+
+	It("should count the traffic of the scratchpad separately", func() {
+		tracer.isScratchpad = func(pAddr uint64) bool {
+			return pAddr >= 0x1000
+		}
+
+		access(read(0x0))
+		access(read(0x1000))
+		access(write(0x40))
+		access(write(0x1040))
+		access(write(0x1080))
+
+		Expect(tracer.readSize).To(Equal(uint64(128)))
+		Expect(tracer.scratchpadReadSize).To(Equal(uint64(64)))
+		Expect(tracer.writeSize).To(Equal(uint64(96)))
+		Expect(tracer.scratchpadWriteSize).To(Equal(uint64(64)))
+	})
+})
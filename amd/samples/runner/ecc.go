@@ -0,0 +1,9 @@
+package runner
+
+// The default ECC parameters. One out of every nine bytes transferred is an
+// ECC byte, as in a 72-bit bus that carries 64 data bits. The latency penalty
+// is in DRAM cycles.
+const (
+	DefaultECCBandwidthOverhead = 1.0 / 9
+	DefaultECCLatencyPenalty    = 2
+)
@@ -0,0 +1,17 @@
+package runner
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ECC", func() {
+	It("should reduce the effective bandwidth of the DRAM", func() {
+		noECC := measureStreamingBandwidth(MakeR9NanoGPUBuilder())
+		withECC := measureStreamingBandwidth(
+			MakeR9NanoGPUBuilder().WithECCEnabled())
+
+		Expect(withECC / noECC).To(
+			BeNumerically("~", 1-DefaultECCBandwidthOverhead, 0.05))
+	})
+})
@@ -37,6 +37,7 @@ type EmuGPUBuilder struct {
 
 	enableISADebug   bool
 	enableMemTracing bool
+	faultInjector    emu.UncorrectableFaultPredicate
 }
 
 // MakeEmuGPUBuilder creates a new EmuGPUBuilder
@@ -104,6 +105,15 @@ func (b EmuGPUBuilder) WithMemTracing() EmuGPUBuilder {
 	return b
 }
 
+// WithUncorrectableFaultInjector sets the predicate that selects the physical
+// addresses whose reads encounter uncorrectable memory errors.
+func (b EmuGPUBuilder) WithUncorrectableFaultInjector(
+	predicate emu.UncorrectableFaultPredicate,
+) EmuGPUBuilder {
+	b.faultInjector = predicate
+	return b
+}
+
 // Build creates a very simple GPU for emulation purposes
 func (b EmuGPUBuilder) Build(name string) *GPU {
 	b.clear()
@@ -141,6 +151,10 @@ func (b *EmuGPUBuilder) buildComputeUnits() {
 			b.engine, disassembler, b.pageTable,
 			b.log2PageSize, b.gpuMem.Storage, nil)
 
+		if b.faultInjector != nil {
+			computeUnit.SetUncorrectableFaultInjector(b.faultInjector)
+		}
+
 		b.computeUnits = append(b.computeUnits, computeUnit)
 
 		if b.enableISADebug {
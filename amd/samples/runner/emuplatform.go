@@ -8,6 +8,7 @@ import (
 	"github.com/sarchlab/akita/v4/sim"
 	"github.com/sarchlab/akita/v4/sim/directconnection"
 	"github.com/sarchlab/mgpusim/v4/amd/driver"
+	"github.com/sarchlab/mgpusim/v4/amd/emu"
 )
 
 // EmuBuilder can build a platform for emulation purposes.
@@ -19,6 +20,7 @@ type EmuBuilder struct {
 	numGPU             int
 	log2PageSize       uint64
 	useMagicMemoryCopy bool
+	faultInjector      emu.UncorrectableFaultPredicate
 	gpus               []*GPU
 }
 
@@ -73,6 +75,18 @@ func (b EmuBuilder) WithMagicMemoryCopy() EmuBuilder {
 	return b
 }
 
+// WithUncorrectableFaultInjector injects uncorrectable memory errors into the
+// reads of the physical addresses that the predicate selects. Rather than
+// returning corrupted data, a faulty read stops the wavefront and the fault is
+// reported by Driver.GetLastKernelError. This models ECC that detects, but
+// cannot correct, an error.
+func (b EmuBuilder) WithUncorrectableFaultInjector(
+	predicate emu.UncorrectableFaultPredicate,
+) EmuBuilder {
+	b.faultInjector = predicate
+	return b
+}
+
 // Build builds a emulation platform.
 func (b EmuBuilder) Build() *Platform {
 	var engine sim.Engine
@@ -138,6 +152,11 @@ func (b *EmuBuilder) createGPUBuilder(
 	if b.traceMem {
 		gpuBuilder = gpuBuilder.WithMemTracing()
 	}
+
+	if b.faultInjector != nil {
+		gpuBuilder = gpuBuilder.WithUncorrectableFaultInjector(b.faultInjector)
+	}
+
 	return gpuBuilder
 }
 
@@ -157,6 +176,7 @@ func (b *EmuBuilder) buildGPUDriver(
 		WithPageTable(pageTable).
 		WithLog2PageSize(b.log2PageSize).
 		WithGlobalStorage(storage).
+		WithHostMemory().
 		Build("Driver")
 
 	return gpuDriver
@@ -35,12 +35,25 @@ var filenameFlag = flag.String("metric-file-name", "metrics",
 	"Modify the name of the output csv file.")
 var magicMemoryCopy = flag.Bool("magic-memory-copy", false,
 	"Copy data from CPU directly to global memory")
+var sharedDRAMFlag = flag.Bool("shared-dram", false,
+	"Let all the GPUs share a single pool of DRAM controllers.")
+var sharedLLCSizeFlag = flag.Uint64("shared-llc-size", 0,
+	`The capacity in bytes of a last-level cache shared by all the GPUs. The
+LLC is not modeled if the capacity is 0. Implies -shared-dram.`)
+var eccFlag = flag.Bool("ecc", false,
+	"Model the bandwidth and latency overhead of ECC on DRAM accesses.")
+var gpuFreqsFlag = flag.String("gpu-freqs", "",
+	`The frequencies of the GPUs in MHz, separated by commas, in the order of
+the GPU IDs (e.g., 1000,1500). The GPUs that are not listed run at the default
+frequency.`)
 var bufferLevelTraceDirFlag = flag.String("buffer-level-trace-dir", "",
 	"The directory to dump the buffer level traces.")
 var bufferLevelTracePeriodFlag = flag.Float64("buffer-level-trace-period", 0.0,
 	"The period to dump the buffer level trace.")
 var simdBusyTimeTracerFlag = flag.Bool("report-busy-time", false, "Report SIMD Unit's busy time")
 var reportCPIStackFlag = flag.Bool("report-cpi-stack", false, "Report CPI stack")
+var collectCountersFlag = flag.Bool("collect-counters", false,
+	"Collect the counters of the caches, the TLBs, the CUs, and the DRAMs.")
 var customPortForAkitaRTM = flag.Int("akitartm-port", 0,
 	`Custom port to host AkitaRTM. A 4-digit or 5-digit port number is required. If 
 this number is not given or a invalid number is given number, a random port 
@@ -123,6 +136,10 @@ func (r *Runner) ParseFlag() *Runner {
 		r.ReportCPIStack = true
 	}
 
+	if *collectCountersFlag {
+		r.CollectCounters = true
+	}
+
 	if *reportAll {
 		r.ReportInstCount = true
 		r.ReportCacheLatency = true
@@ -132,6 +149,7 @@ func (r *Runner) ParseFlag() *Runner {
 		r.ReportDRAMTransactionCount = true
 		r.ReportRDMATransactionCount = true
 		r.ReportCPIStack = true
+		r.CollectCounters = true
 	}
 
 	return r
@@ -0,0 +1,37 @@
+package runner
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/sarchlab/akita/v4/sim"
+	"github.com/sarchlab/mgpusim/v4/amd/timing/cu"
+)
+
+var _ = Describe("GPU Frequency", func() {
+	It("should tick the components of each GPU at the frequency of the GPU",
+		func() {
+			platform := MakeR9NanoBuilder().
+				WithNumGPU(2).
+				WithGPUFreqs(1*sim.GHz, 1500*sim.MHz).
+				Build()
+
+			for i, freq := range []sim.Freq{1 * sim.GHz, 1500 * sim.MHz} {
+				gpu := platform.GPUs[i]
+				Expect(gpu.CUs[0].(*cu.ComputeUnit).Freq).To(Equal(freq))
+				Expect(gpu.CommandProcessor.Freq).To(Equal(freq))
+				Expect(gpu.DMAEngine.Freq).To(Equal(freq))
+				Expect(gpu.RDMAEngine.Freq).To(Equal(freq))
+				Expect(gpu.PMC.Freq).To(Equal(freq))
+			}
+		})
+
+	It("should run the GPUs that are not listed at 1 GHz", func() {
+		platform := MakeR9NanoBuilder().
+			WithNumGPU(2).
+			WithGPUFreqs(1500 * sim.MHz).
+			Build()
+
+		Expect(platform.GPUs[1].CommandProcessor.Freq).To(Equal(1 * sim.GHz))
+	})
+})
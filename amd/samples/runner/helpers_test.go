@@ -0,0 +1,242 @@
+package runner
+
+import (
+	"github.com/sarchlab/akita/v4/mem/mem"
+	"github.com/sarchlab/akita/v4/sim"
+	"github.com/sarchlab/akita/v4/sim/directconnection"
+	"github.com/sarchlab/akita/v4/tracing"
+	"github.com/sarchlab/mgpusim/v4/amd/benchmarks/amdappsdk/matrixmultiplication"
+	"github.com/sarchlab/mgpusim/v4/amd/driver"
+	"github.com/sarchlab/mgpusim/v4/amd/kernels"
+)
+
+// The kernel arguments of the kernels in testdata.
+
+type computeBoundArgs struct {
+	Out driver.Ptr
+}
+
+type memoryBoundArgs struct {
+	In  driver.Ptr
+	Out driver.Ptr
+}
+
+type bufferArgs struct {
+	Buf driver.Ptr
+}
+
+// fakeTimeTeller tells a time that the tests set.
+type fakeTimeTeller struct {
+	now sim.VTimeInSec
+}
+
+func (t *fakeTimeTeller) CurrentTime() sim.VTimeInSec {
+	return t.now
+}
+
+// requestAgent sends requests to a component one after another. It sends a
+// request only after the response to the previous request arrives.
+type requestAgent struct {
+	*sim.TickingComponent
+
+	port      sim.Port
+	reqs      []sim.Msg
+	waiting   bool
+	sentAt    sim.VTimeInSec
+	latencies []sim.VTimeInSec
+}
+
+// newRequestAgent creates a request agent that is directly connected to dst.
+func newRequestAgent(engine sim.Engine, dst sim.Port) *requestAgent {
+	a := &requestAgent{}
+	a.TickingComponent = sim.NewTickingComponent(
+		"Agent", engine, 1*sim.GHz, a)
+	a.port = sim.NewPort(a, 64, 64, "Agent.Port")
+
+	conn := directconnection.MakeBuilder().
+		WithEngine(engine).
+		WithFreq(1 * sim.GHz).
+		Build("Agent.Conn")
+	conn.PlugIn(a.port)
+	conn.PlugIn(dst)
+
+	return a
+}
+
+func (a *requestAgent) Tick() bool {
+	if a.waiting {
+		if a.port.RetrieveIncoming() == nil {
+			return false
+		}
+
+		a.latencies = append(a.latencies, a.CurrentTime()-a.sentAt)
+		a.waiting = false
+	}
+
+	if len(a.reqs) == 0 {
+		return false
+	}
+
+	if a.port.Send(a.reqs[0]) != nil {
+		return false
+	}
+
+	a.reqs = a.reqs[1:]
+	a.sentAt = a.CurrentTime()
+	a.waiting = true
+
+	return true
+}
+
+// run sends the requests and returns the time that each of them takes.
+func (a *requestAgent) run(reqs ...sim.Msg) []sim.VTimeInSec {
+	a.reqs = reqs
+	a.latencies = nil
+
+	a.TickLater()
+	if err := a.Engine.Run(); err != nil {
+		panic(err)
+	}
+
+	return a.latencies
+}
+
+// streamingAgent reads one cache line from every 2 KB of memory, so that the
+// consecutive reads go to different DRAM banks. It keeps a fixed number of
+// reads in flight.
+type streamingAgent struct {
+	*sim.TickingComponent
+
+	port           sim.Port
+	dst            sim.RemotePort
+	nextAddr       uint64
+	endAddr        uint64
+	maxOutstanding int
+	numOutstanding int
+}
+
+func (a *streamingAgent) Tick() bool {
+	madeProgress := false
+
+	if a.port.RetrieveIncoming() != nil {
+		a.numOutstanding--
+		madeProgress = true
+	}
+
+	if a.nextAddr < a.endAddr && a.numOutstanding < a.maxOutstanding {
+		req := mem.ReadReqBuilder{}.
+			WithSrc(a.port.AsRemote()).
+			WithDst(a.dst).
+			WithAddress(a.nextAddr).
+			WithByteSize(64).
+			Build()
+		if a.port.Send(req) == nil {
+			a.nextAddr += streamingStride
+			a.numOutstanding++
+			madeProgress = true
+		}
+	}
+
+	return madeProgress
+}
+
+const streamingStride = 2048
+
+// measureStreamingBandwidth streams data from a DRAM controller and returns the
+// number of data bytes that are read per second.
+func measureStreamingBandwidth(b R9NanoGPUBuilder) float64 {
+	const numBytes = 1 << 20
+
+	engine := sim.NewSerialEngine()
+	b = b.WithEngine(engine)
+
+	dramBuilder := b.createDramControllerBuilder(4 * mem.GB)
+	dram := dramBuilder.Build("DRAM")
+
+	agent := &streamingAgent{
+		dst:            dram.GetPortByName("Top").AsRemote(),
+		endAddr:        numBytes / 64 * streamingStride,
+		maxOutstanding: 1024,
+	}
+	agent.TickingComponent = sim.NewTickingComponent(
+		"Agent", engine, 1*sim.GHz, agent)
+	agent.port = sim.NewPort(agent, 64, 64, "Agent.ToDRAM")
+
+	conn := directconnection.MakeBuilder().
+		WithEngine(engine).
+		WithFreq(1 * sim.GHz).
+		Build("Conn")
+	conn.PlugIn(agent.port)
+	conn.PlugIn(dram.GetPortByName("Top"))
+
+	agent.TickLater()
+	if err := engine.Run(); err != nil {
+		panic(err)
+	}
+
+	return numBytes / float64(engine.CurrentTime())
+}
+
+// kernelTime builds a platform, lets run use the driver, and returns the time
+// that the kernels take.
+func kernelTime(
+	builder R9NanoPlatformBuilder,
+	run func(gpuDriver *driver.Driver),
+) sim.VTimeInSec {
+	platform := builder.Build()
+	gpuDriver := platform.Driver
+
+	kernelTimeTracer := tracing.NewBusyTimeTracer(
+		platform.Engine,
+		func(task tracing.Task) bool {
+			return task.What == "*driver.LaunchKernelCommand"
+		})
+	tracing.CollectTrace(gpuDriver, kernelTimeTracer)
+
+	gpuDriver.Run()
+	defer gpuDriver.Terminate()
+
+	run(gpuDriver)
+
+	return kernelTimeTracer.BusyTime()
+}
+
+// runLargeCodeKernel runs testdata/largecode.s with one wavefront. The 16 KB
+// of code takes 256 cache lines.
+func runLargeCodeKernel(gpuDriver *driver.Driver) {
+	ctx := gpuDriver.Init()
+	hsaco := kernels.LoadProgram("testdata/largecode.hsaco", "")
+	args := computeBoundArgs{
+		Out: gpuDriver.AllocateMemory(ctx, 64*4),
+	}
+	gpuDriver.LaunchKernel(ctx, hsaco,
+		[3]uint32{64, 1, 1}, [3]uint16{64, 1, 1}, &args)
+}
+
+// runMatrixMultiplication runs the tiled matrix multiplication of 64x64
+// matrices. Each of the work-groups passes 8 barriers.
+func runMatrixMultiplication(gpuDriver *driver.Driver) {
+	benchmark := matrixmultiplication.NewBenchmark(gpuDriver)
+	benchmark.X = 64
+	benchmark.Y = 64
+	benchmark.Z = 64
+	benchmark.SelectGPU([]int{1})
+	benchmark.Run()
+}
+
+// runMigratingKernel runs a kernel that writes a buffer in unified memory on
+// the second GPU. The buffer starts on the first GPU, so the pages migrate to
+// the second GPU, which shoots down the TLB entries of the pages.
+func runMigratingKernel(gpuDriver *driver.Driver) {
+	const numItems = 4 * 1024
+
+	ctx := gpuDriver.Init()
+	hsaco := kernels.LoadProgram("testdata/store.hsaco", "")
+	args := bufferArgs{
+		Buf: gpuDriver.AllocateUnifiedMemory(ctx, numItems*4),
+	}
+
+	gpuDriver.SelectGPU(ctx, 2)
+	gpuDriver.LaunchKernel(ctx, hsaco,
+		[3]uint32{numItems, 1, 1}, [3]uint16{64, 1, 1}, &args)
+}
@@ -0,0 +1,43 @@
+package runner
+
+import (
+	"github.com/sarchlab/akita/v4/mem/idealmemcontroller"
+	"github.com/sarchlab/akita/v4/sim"
+	"github.com/sarchlab/akita/v4/tracing"
+)
+
+// hostMemoryLatency is the number of cycles that the host memory takes to
+// serve an access, on top of the time that the access spends on the PCIe
+// links.
+const hostMemoryLatency = 100
+
+// buildHostMemory builds the memory controller of the host. It serves the
+// first 4 GB of the physical address space, where the driver allocates the
+// buffers that are mapped with Driver.MapHostMemory. The GPUs reach it over
+// the PCIe links through their RDMA engines, so the host memory cannot be
+// reached if the RDMA engines are linked with WithInterGPUTopology or if the
+// GPUs share a DRAM pool. In these platforms, Driver.MapHostMemory panics.
+func (b *R9NanoPlatformBuilder) buildHostMemory() *idealmemcontroller.Comp {
+	hostMemory := idealmemcontroller.MakeBuilder().
+		WithEngine(b.engine).
+		WithFreq(1 * sim.GHz).
+		WithLatency(scaleLatency(hostMemoryLatency, b.timingScale)).
+		WithStorage(b.globalStorage).
+		Build("HostMemory")
+
+	if b.visTracer != nil {
+		tracing.CollectTrace(hostMemory, b.visTracer)
+	}
+
+	if b.monitor != nil {
+		b.monitor.RegisterComponent(hostMemory)
+	}
+
+	return hostMemory
+}
+
+// isHostMemoryReachable tells if the GPUs can access the host memory over the
+// PCIe links.
+func (b *R9NanoPlatformBuilder) isHostMemoryReachable() bool {
+	return b.interGPUTopology == InterGPUTopologyPCIe && !b.useSharedDRAMPool
+}
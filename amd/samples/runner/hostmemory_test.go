@@ -0,0 +1,87 @@
+package runner
+
+import (
+	"encoding/binary"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/sarchlab/akita/v4/mem/mem"
+	"github.com/sarchlab/akita/v4/sim"
+	"github.com/sarchlab/akita/v4/tracing"
+	"github.com/sarchlab/mgpusim/v4/amd/kernels"
+)
+
+var _ = Describe("Host Memory", func() {
+	const numItems = 4096
+
+	// copyFromHostOrDevice runs testdata/copy.s that copies an input buffer
+	// to a buffer in the GPU memory. The input is mapped from the host memory
+	// if zeroCopy is set, or copied to the GPU memory otherwise. It returns
+	// the average time of the reads that the L1 vector caches serve.
+	copyFromHostOrDevice := func(zeroCopy bool) sim.VTimeInSec {
+		platform := MakeR9NanoBuilder().WithNumGPU(1).Build()
+		gpuDriver := platform.Driver
+
+		readTracer := tracing.NewAverageTimeTracer(
+			platform.Engine,
+			func(task tracing.Task) bool {
+				_, isRead := task.Detail.(*mem.ReadReq)
+				return task.Kind == "req_in" && isRead
+			})
+		for _, l1v := range platform.GPUs[0].L1VCaches {
+			tracing.CollectTrace(l1v, readTracer)
+		}
+
+		hostMemoryTracer := tracing.NewAverageTimeTracer(
+			platform.Engine,
+			func(task tracing.Task) bool {
+				return task.Kind == "req_in"
+			})
+		tracing.CollectTrace(platform.HostMemory, hostMemoryTracer)
+
+		gpuDriver.Run()
+		defer gpuDriver.Terminate()
+
+		ctx := gpuDriver.Init()
+
+		input := make([]uint32, numItems)
+		for i := range input {
+			input[i] = uint32(i*7 + 1)
+		}
+
+		args := memoryBoundArgs{
+			Out: gpuDriver.AllocateMemory(ctx, numItems*4),
+		}
+		if zeroCopy {
+			data := make([]byte, numItems*4)
+			for i, v := range input {
+				binary.LittleEndian.PutUint32(data[i*4:], v)
+			}
+			args.In = gpuDriver.MapHostMemory(ctx, data)
+		} else {
+			args.In = gpuDriver.AllocateMemory(ctx, numItems*4)
+			gpuDriver.MemCopyH2D(ctx, args.In, input)
+		}
+
+		hsaco := kernels.LoadProgram("testdata/copy.hsaco", "")
+		gpuDriver.LaunchKernel(ctx, hsaco,
+			[3]uint32{numItems, 1, 1}, [3]uint16{64, 1, 1}, &args)
+
+		output := make([]uint32, numItems)
+		gpuDriver.MemCopyD2H(ctx, output, args.Out)
+		Expect(output).To(Equal(input))
+
+		Expect(hostMemoryTracer.TotalCount() > 0).To(Equal(zeroCopy))
+
+		return readTracer.AverageTime()
+	}
+
+	It("should serve the kernel reads of a mapped buffer over the host link",
+		func() {
+			deviceLatency := copyFromHostOrDevice(false)
+			hostLatency := copyFromHostOrDevice(true)
+
+			Expect(hostLatency).To(BeNumerically(">=", 2*deviceLatency))
+		})
+})
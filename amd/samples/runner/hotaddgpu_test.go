@@ -0,0 +1,29 @@
+package runner
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Hot-Add GPU", func() {
+	It("should add a spare GPU to the platform and the driver", func() {
+		platform := MakeR9NanoBuilder().
+			WithNumGPU(1).
+			WithNumSpareGPU(1).
+			Build()
+		spare := platform.spareGPUs[0]
+
+		gpuID := platform.HotAddGPU()
+
+		Expect(gpuID).To(Equal(2))
+		Expect(platform.GPUs).To(HaveLen(2))
+		Expect(platform.GPUs[1]).To(BeIdenticalTo(spare))
+		Expect(platform.Driver.GetNumGPUs()).To(Equal(2))
+	})
+
+	It("should panic if there is no spare GPU", func() {
+		platform := MakeR9NanoBuilder().WithNumGPU(1).Build()
+
+		Expect(func() { platform.HotAddGPU() }).To(Panic())
+	})
+})
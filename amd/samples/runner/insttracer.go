@@ -1,8 +1,8 @@
 package runner
 
 import (
+	"github.com/sarchlab/akita/v4/sim"
 	"github.com/sarchlab/akita/v4/tracing"
-	"github.com/tebeka/atexit"
 )
 
 // instTracer can trace the number of instruction completed.
@@ -12,6 +12,11 @@ type instTracer struct {
 	simdCount uint64
 	maxCount  uint64
 
+	// onMaxCount is called once when the number of instructions reaches the
+	// max count.
+	onMaxCount      func()
+	maxCountReached bool
+
 	inflightInst map[string]tracing.Task
 }
 
@@ -23,11 +28,12 @@ func newInstTracer() *instTracer {
 	return t
 }
 
-// newInstStopper with stop the execution after a given number of instructions
-// is retired.
-func newInstStopper(maxInst uint64) *instTracer {
+// newInstStopper calls onMaxCount to stop the execution after a given number
+// of instructions is retired.
+func newInstStopper(maxInst uint64, onMaxCount func()) *instTracer {
 	t := &instTracer{
 		maxCount:     maxInst,
+		onMaxCount:   onMaxCount,
 		inflightInst: map[string]tracing.Task{},
 	}
 	return t
@@ -69,7 +75,45 @@ func (t *instTracer) EndTask(task tracing.Task) {
 
 	t.count++
 
-	if t.maxCount > 0 && t.count >= t.maxCount {
-		atexit.Exit(0)
+	if t.maxCount > 0 && t.count >= t.maxCount && !t.maxCountReached {
+		t.maxCountReached = true
+		t.onMaxCount()
+	}
+}
+
+// engineGate is an engine hook that holds the engine before the next event
+// once the gate is closed, so that the simulation halts between two events.
+type engineGate struct {
+	closed bool
+	halted chan bool
+	opened chan bool
+}
+
+func newEngineGate() *engineGate {
+	return &engineGate{
+		halted: make(chan bool),
+		opened: make(chan bool),
 	}
 }
+
+// close lets the engine halt before the next event. It must be called while
+// the engine handles an event, such as from the hook of a component.
+func (g *engineGate) close() {
+	g.closed = true
+}
+
+// open lets the halted engine handle the events again.
+func (g *engineGate) open() {
+	g.opened <- true
+}
+
+// Func holds the engine before an event if the gate is closed.
+func (g *engineGate) Func(ctx sim.HookCtx) {
+	if ctx.Pos != sim.HookPosBeforeEvent || !g.closed {
+		return
+	}
+
+	g.closed = false
+	g.halted <- true
+	<-g.opened
+}
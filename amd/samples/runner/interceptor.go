@@ -0,0 +1,162 @@
+package runner
+
+import (
+	"github.com/sarchlab/akita/v4/mem/mem"
+	"github.com/sarchlab/akita/v4/sim"
+)
+
+// A MemRequestInterceptor observes the memory requests that the L1 caches send
+// to the L2 caches. It returns the request to forward, which is either the
+// given request, changed or not, or a new request. A new request must keep the
+// ID and the source of the given request, so that the response can find its
+// way back to the L1 cache. The request is routed by its address after the
+// interceptor returns, so that a remapped address reaches the right L2 bank.
+type MemRequestInterceptor func(req mem.AccessReq) mem.AccessReq
+
+type interceptedMsg struct {
+	original    sim.Msg
+	replacement sim.Msg
+}
+
+// An interceptingConnection connects the ports without latency, like a direct
+// connection. It calls the interceptor on the memory requests that go to the
+// L2 caches before delivering them.
+type interceptingConnection struct {
+	*sim.TickingComponent
+
+	ports      []sim.Port
+	portMap    map[sim.RemotePort]sim.Port
+	nextPortID int
+
+	interceptor     MemRequestInterceptor
+	lowModuleFinder mem.AddressToPortMapper
+	l2Ports         map[sim.RemotePort]bool
+	intercepted     map[sim.Port]interceptedMsg
+}
+
+func newInterceptingConnection(
+	name string,
+	engine sim.Engine,
+	freq sim.Freq,
+	interceptor MemRequestInterceptor,
+	lowModuleFinder mem.AddressToPortMapper,
+) *interceptingConnection {
+	c := &interceptingConnection{
+		portMap:         make(map[sim.RemotePort]sim.Port),
+		interceptor:     interceptor,
+		lowModuleFinder: lowModuleFinder,
+		l2Ports:         make(map[sim.RemotePort]bool),
+		intercepted:     make(map[sim.Port]interceptedMsg),
+	}
+	c.TickingComponent = sim.NewSecondaryTickingComponent(
+		name, engine, freq, c)
+
+	return c
+}
+
+// PlugIn marks the port connects to this connection.
+func (c *interceptingConnection) PlugIn(port sim.Port) {
+	c.Lock()
+	defer c.Unlock()
+
+	c.ports = append(c.ports, port)
+	c.portMap[port.AsRemote()] = port
+
+	port.SetConnection(c)
+}
+
+// plugInL2 plugs in the top port of an L2 cache. The requests to this port are
+// intercepted.
+func (c *interceptingConnection) plugInL2(port sim.Port) {
+	c.PlugIn(port)
+	c.l2Ports[port.AsRemote()] = true
+}
+
+// Unplug marks the port no longer connects to this connection.
+func (c *interceptingConnection) Unplug(_ sim.Port) {
+	panic("not implemented")
+}
+
+// NotifyAvailable is called by a port to notify that the connection can
+// deliver to the port again.
+func (c *interceptingConnection) NotifyAvailable(p sim.Port) {
+	for _, port := range c.ports {
+		if port == p {
+			continue
+		}
+
+		port.NotifyAvailable()
+	}
+
+	c.TickNow()
+}
+
+// NotifySend is called by a port to notify that the connection can start to
+// tick now.
+func (c *interceptingConnection) NotifySend() {
+	c.TickNow()
+}
+
+// Tick delivers the messages from all the ports.
+func (c *interceptingConnection) Tick() bool {
+	madeProgress := false
+
+	for i := range c.ports {
+		port := c.ports[(i+c.nextPortID)%len(c.ports)]
+		madeProgress = c.forwardMany(port) || madeProgress
+	}
+
+	c.nextPortID = (c.nextPortID + 1) % len(c.ports)
+
+	return madeProgress
+}
+
+func (c *interceptingConnection) forwardMany(port sim.Port) bool {
+	madeProgress := false
+
+	for {
+		head := port.PeekOutgoing()
+		if head == nil {
+			break
+		}
+
+		msg := c.intercept(port, head)
+		dstPort := c.portMap[msg.Meta().Dst]
+
+		err := dstPort.Deliver(msg)
+		if err != nil {
+			break
+		}
+
+		madeProgress = true
+
+		delete(c.intercepted, port)
+		port.RetrieveOutgoing()
+	}
+
+	return madeProgress
+}
+
+// intercept returns the message to deliver in place of the head of the
+// outgoing buffer of a port. A message is only intercepted once, even if it
+// cannot be delivered right away.
+func (c *interceptingConnection) intercept(port sim.Port, head sim.Msg) sim.Msg {
+	if e, found := c.intercepted[port]; found && e.original == head {
+		return e.replacement
+	}
+
+	req, ok := head.(mem.AccessReq)
+	if !ok || !c.l2Ports[head.Meta().Dst] {
+		return head
+	}
+
+	replacement := c.interceptor(req)
+	replacement.Meta().Dst = c.lowModuleFinder.Find(replacement.GetAddress())
+
+	c.intercepted[port] = interceptedMsg{
+		original:    head,
+		replacement: replacement,
+	}
+
+	return replacement
+}
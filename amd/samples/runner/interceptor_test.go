@@ -0,0 +1,105 @@
+package runner
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/sarchlab/akita/v4/mem/mem"
+	"github.com/sarchlab/akita/v4/sim"
+)
+
+// idleTicker owns the ports of the tests and never makes progress.
+type idleTicker struct{}
+
+func (idleTicker) Tick() bool {
+	return false
+}
+
+var _ = Describe("Intercepting Connection", func() {
+	var (
+		numIntercepted int
+		l1, l2a, l2b   sim.Port
+		conn           *interceptingConnection
+	)
+
+	BeforeEach(func() {
+		engine := sim.NewSerialEngine()
+		owner := sim.NewTickingComponent(
+			"Owner", engine, 1*sim.GHz, idleTicker{})
+		l1 = sim.NewPort(owner, 4, 4, "L1.BottomPort")
+		l2a = sim.NewPort(owner, 1, 4, "L2A.TopPort")
+		l2b = sim.NewPort(owner, 1, 4, "L2B.TopPort")
+
+		lowModuleFinder := mem.NewInterleavedAddressPortMapper(4096)
+		lowModuleFinder.LowModules = append(lowModuleFinder.LowModules,
+			l2a.AsRemote(), l2b.AsRemote())
+
+		// The interceptor moves the reads to the next 4 KB, which the other
+		// L2 cache serves.
+		numIntercepted = 0
+		interceptor := func(req mem.AccessReq) mem.AccessReq {
+			numIntercepted++
+			req.(*mem.ReadReq).Address += 4096
+
+			return req
+		}
+
+		conn = newInterceptingConnection("Conn", engine, 1*sim.GHz,
+			interceptor, lowModuleFinder)
+		conn.PlugIn(l1)
+		conn.plugInL2(l2a)
+		conn.plugInL2(l2b)
+	})
+
+	read := func(address uint64) *mem.ReadReq {
+		return mem.ReadReqBuilder{}.
+			WithSrc(l1.AsRemote()).
+			WithDst(l2a.AsRemote()).
+			WithAddress(address).
+			WithByteSize(4).
+			Build()
+	}
+
+	It("should route an intercepted request by its new address", func() {
+		Expect(l1.Send(read(0x40))).To(BeNil())
+
+		conn.Tick()
+
+		Expect(numIntercepted).To(Equal(1))
+		Expect(l2a.PeekIncoming()).To(BeNil())
+		req := l2b.RetrieveIncoming().(*mem.ReadReq)
+		Expect(req.Address).To(Equal(uint64(0x1040)))
+		Expect(req.Dst).To(Equal(l2b.AsRemote()))
+	})
+
+	It("should not intercept the responses to the L1 caches", func() {
+		rsp := mem.DataReadyRspBuilder{}.
+			WithSrc(l2a.AsRemote()).
+			WithDst(l1.AsRemote()).
+			WithRspTo("req").
+			Build()
+		Expect(l2a.Send(rsp)).To(BeNil())
+
+		conn.Tick()
+
+		Expect(numIntercepted).To(Equal(0))
+		Expect(l1.RetrieveIncoming()).To(BeIdenticalTo(rsp))
+	})
+
+	It("should intercept a request once if it waits to be delivered", func() {
+		Expect(l1.Send(read(0x40))).To(BeNil())
+		Expect(l1.Send(read(0x80))).To(BeNil())
+
+		conn.Tick()
+		conn.Tick()
+
+		Expect(numIntercepted).To(Equal(2))
+
+		l2b.RetrieveIncoming()
+		conn.Tick()
+
+		Expect(numIntercepted).To(Equal(2))
+		req := l2b.RetrieveIncoming().(*mem.ReadReq)
+		Expect(req.Address).To(Equal(uint64(0x1080)))
+	})
+})
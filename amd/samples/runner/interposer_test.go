@@ -0,0 +1,78 @@
+package runner
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/sarchlab/akita/v4/mem/mem"
+	"github.com/sarchlab/akita/v4/sim"
+)
+
+var _ = Describe("Latency Connection", func() {
+	var (
+		engine   sim.Engine
+		l2, dram sim.Port
+		conn     *latencyConnection
+	)
+
+	BeforeEach(func() {
+		engine = sim.NewSerialEngine()
+		owner := sim.NewTickingComponent(
+			"Owner", engine, 1*sim.GHz, idleTicker{})
+		l2 = sim.NewPort(owner, 4, 4, "L2.BottomPort")
+		dram = sim.NewPort(owner, 4, 4, "DRAM.TopPort")
+
+		conn = newLatencyConnection("Conn", engine, 1*sim.GHz, 100)
+		conn.PlugIn(l2)
+		conn.PlugIn(dram)
+	})
+
+	read := func(address uint64) *mem.ReadReq {
+		return mem.ReadReqBuilder{}.
+			WithSrc(l2.AsRemote()).
+			WithDst(dram.AsRemote()).
+			WithAddress(address).
+			WithByteSize(64).
+			Build()
+	}
+
+	It("should deliver a request after the latency", func() {
+		req := read(0x40)
+		Expect(l2.Send(req)).To(BeNil())
+
+		conn.Tick()
+
+		Expect(dram.PeekIncoming()).To(BeNil())
+
+		Expect(engine.Run()).To(Succeed())
+
+		Expect(dram.RetrieveIncoming()).To(BeIdenticalTo(req))
+		Expect(engine.CurrentTime()).
+			To(BeNumerically(">=", sim.VTimeInSec(100e-9)))
+	})
+
+	It("should not let the delayed requests limit each other", func() {
+		Expect(l2.Send(read(0x40))).To(BeNil())
+		Expect(l2.Send(read(0x80))).To(BeNil())
+
+		Expect(engine.Run()).To(Succeed())
+
+		Expect(dram.RetrieveIncoming()).NotTo(BeNil())
+		Expect(dram.RetrieveIncoming()).NotTo(BeNil())
+		Expect(engine.CurrentTime()).
+			To(BeNumerically("<", sim.VTimeInSec(110e-9)))
+	})
+
+	It("should deliver the responses without latency", func() {
+		rsp := mem.DataReadyRspBuilder{}.
+			WithSrc(dram.AsRemote()).
+			WithDst(l2.AsRemote()).
+			WithRspTo("req").
+			Build()
+		Expect(dram.Send(rsp)).To(BeNil())
+
+		conn.Tick()
+
+		Expect(l2.RetrieveIncoming()).To(BeIdenticalTo(rsp))
+	})
+})
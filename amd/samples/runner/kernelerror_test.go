@@ -0,0 +1,167 @@
+package runner
+
+import (
+	"fmt"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/sarchlab/mgpusim/v4/amd/benchmarks/heteromark/fir"
+	"github.com/sarchlab/mgpusim/v4/amd/driver"
+	"github.com/sarchlab/mgpusim/v4/amd/kernels"
+)
+
+func launchFIR(
+	gpuDriver *driver.Driver,
+	ctx *driver.Context,
+	input driver.Ptr,
+) *driver.CommandQueue {
+	const length = 256
+
+	hsaco := kernels.LoadProgram(
+		"../../benchmarks/heteromark/fir/kernels.hsaco", "FIR")
+
+	kernArg := fir.KernelArgs{
+		Output:  gpuDriver.AllocateMemory(ctx, length*4),
+		Filter:  gpuDriver.AllocateMemory(ctx, 16*4),
+		Input:   input,
+		History: gpuDriver.AllocateMemory(ctx, 16*4),
+		NumTaps: 16,
+	}
+
+	queue := gpuDriver.CreateCommandQueue(ctx)
+	gpuDriver.EnqueueLaunchKernel(queue, hsaco,
+		[3]uint32{length, 1, 1}, [3]uint16{64, 1, 1}, &kernArg)
+	gpuDriver.DrainCommandQueue(queue)
+
+	return queue
+}
+
+var _ = Describe("Kernel Error", func() {
+	var gpuDriver *driver.Driver
+
+	AfterEach(func() {
+		gpuDriver.Terminate()
+	})
+
+	run := func(platform *Platform) *driver.Context {
+		gpuDriver = platform.Driver
+		gpuDriver.Run()
+
+		return gpuDriver.Init()
+	}
+
+	It("should report the access to an unmapped address", func() {
+		ctx := run(MakeEmuBuilder().WithNumGPU(1).Build())
+
+		queue := launchFIR(gpuDriver, ctx, driver.Ptr(0xdead0000))
+
+		err := gpuDriver.GetLastKernelError(queue)
+		Expect(err).To(MatchError(And(
+			ContainSubstring("illegal memory address 0xdead"),
+			ContainSubstring("CU"))))
+	})
+
+	It("should report the access to an unmapped address in the timing "+
+		"simulation", func() {
+		ctx := run(MakeR9NanoBuilder().
+			WithNumGPU(1).
+			WithFaultDetection().
+			Build())
+
+		queue := launchFIR(gpuDriver, ctx, driver.Ptr(0xdead0000))
+
+		err := gpuDriver.GetLastKernelError(queue)
+		Expect(err).To(MatchError(And(
+			ContainSubstring("illegal VMEM address 0xdead"),
+			ContainSubstring("CU"))))
+	})
+
+	It("should not report an error if the addresses are mapped", func() {
+		ctx := run(MakeEmuBuilder().WithNumGPU(1).Build())
+		input := gpuDriver.AllocateMemory(ctx, 256*4)
+
+		queue := launchFIR(gpuDriver, ctx, input)
+
+		Expect(gpuDriver.GetLastKernelError(queue)).To(Succeed())
+	})
+
+	// faultInjector makes the nth read fail and records the address of the
+	// read.
+	faultInjector := func(n int, faultyAddr *uint64) func(uint64) bool {
+		numReads := 0
+
+		return func(pAddr uint64) bool {
+			numReads++
+			if numReads != n {
+				return false
+			}
+
+			*faultyAddr = pAddr
+
+			return true
+		}
+	}
+
+	It("should report an uncorrectable memory error", func() {
+		faultyAddr := uint64(0)
+		ctx := run(MakeEmuBuilder().
+			WithNumGPU(1).
+			WithUncorrectableFaultInjector(faultInjector(1000, &faultyAddr)).
+			Build())
+		input := gpuDriver.AllocateMemory(ctx, 256*4)
+
+		queue := launchFIR(gpuDriver, ctx, input)
+
+		err := gpuDriver.GetLastKernelError(queue)
+		Expect(err).To(MatchError(And(
+			ContainSubstring("uncorrectable memory error"),
+			ContainSubstring(fmt.Sprintf("0x%x", faultyAddr)))))
+	})
+
+	It("should report an uncorrectable memory error in the timing "+
+		"simulation", func() {
+		faultyAddr := uint64(0)
+		ctx := run(MakeR9NanoBuilder().
+			WithNumGPU(1).
+			WithUncorrectableFaultInjector(faultInjector(10, &faultyAddr)).
+			Build())
+		input := gpuDriver.AllocateMemory(ctx, 256*4)
+
+		queue := launchFIR(gpuDriver, ctx, input)
+
+		err := gpuDriver.GetLastKernelError(queue)
+		Expect(err).To(MatchError(And(
+			ContainSubstring("uncorrectable memory error"),
+			ContainSubstring(fmt.Sprintf("0x%x", faultyAddr)),
+			ContainSubstring("DRAM"))))
+	})
+})
+
+func BenchmarkFaultDetection(b *testing.B) {
+	builders := map[string]R9NanoPlatformBuilder{
+		"Off": MakeR9NanoBuilder().WithNumGPU(1),
+		"On":  MakeR9NanoBuilder().WithNumGPU(1).WithFaultDetection(),
+	}
+
+	for name, builder := range builders {
+		b.Run(name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				platform := builder.Build()
+				gpuDriver := platform.Driver
+				gpuDriver.Run()
+				ctx := gpuDriver.Init()
+				input := gpuDriver.AllocateMemory(ctx, 256*4)
+				b.StartTimer()
+
+				launchFIR(gpuDriver, ctx, input)
+
+				b.StopTimer()
+				gpuDriver.Terminate()
+				b.StartTimer()
+			}
+		})
+	}
+}
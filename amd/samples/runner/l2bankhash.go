@@ -0,0 +1,41 @@
+package runner
+
+import (
+	"github.com/sarchlab/akita/v4/mem/mem"
+	"github.com/sarchlab/akita/v4/sim"
+)
+
+// An xorHashAddressPortMapper maps the addresses to the L2 cache banks with an
+// XOR hash of the address bits, rather than only the bits right above the
+// interleaving size. Accesses with a power-of-two stride that would all fall
+// into the same bank are spread across the banks. It keeps the address space
+// limitation of the interleaved mapper that it wraps. The number of banks must
+// be a power of two.
+type xorHashAddressPortMapper struct {
+	*mem.InterleavedAddressPortMapper
+}
+
+// Find returns the bank that has the data at the provided address.
+func (f xorHashAddressPortMapper) Find(address uint64) sim.RemotePort {
+	if f.UseAddressSpaceLimitation &&
+		(address >= f.HighAddress || address < f.LowAddress) {
+		return f.ModuleForOtherAddresses
+	}
+
+	numBanks := uint64(len(f.LowModules))
+	if numBanks == 1 {
+		return f.LowModules[0]
+	}
+
+	log2NumBanks := uint64(0)
+	for 1<<log2NumBanks < numBanks {
+		log2NumBanks++
+	}
+
+	bank := uint64(0)
+	for block := address / f.InterleavingSize; block > 0; block >>= log2NumBanks {
+		bank ^= block & (numBanks - 1)
+	}
+
+	return f.LowModules[bank]
+}
@@ -0,0 +1,54 @@
+package runner
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/sarchlab/akita/v4/mem/mem"
+	"github.com/sarchlab/akita/v4/sim"
+)
+
+var _ = Describe("XOR Hash Address Port Mapper", func() {
+	var (
+		interleaved *mem.InterleavedAddressPortMapper
+		mapper      xorHashAddressPortMapper
+	)
+
+	BeforeEach(func() {
+		interleaved = mem.NewInterleavedAddressPortMapper(256)
+		interleaved.LowModules = []sim.RemotePort{
+			"Bank[0]", "Bank[1]", "Bank[2]", "Bank[3]",
+		}
+		mapper = xorHashAddressPortMapper{interleaved}
+	})
+
+	It("should spread the accesses that interleaving maps to one bank",
+		func() {
+			banks := make(map[sim.RemotePort]int)
+			for i := uint64(0); i < 4; i++ {
+				address := i * 4 * 256
+				Expect(interleaved.Find(address)).
+					To(Equal(sim.RemotePort("Bank[0]")))
+
+				banks[mapper.Find(address)]++
+			}
+
+			Expect(banks).To(HaveLen(4))
+		})
+
+	It("should map the consecutive blocks to different banks", func() {
+		Expect(mapper.Find(0)).To(Equal(sim.RemotePort("Bank[0]")))
+		Expect(mapper.Find(256)).To(Equal(sim.RemotePort("Bank[1]")))
+		Expect(mapper.Find(511)).To(Equal(sim.RemotePort("Bank[1]")))
+	})
+
+	It("should keep the address space limitation", func() {
+		interleaved.UseAddressSpaceLimitation = true
+		interleaved.LowAddress = 4096
+		interleaved.HighAddress = 8192
+		interleaved.ModuleForOtherAddresses = "RDMA"
+
+		Expect(mapper.Find(0)).To(Equal(sim.RemotePort("RDMA")))
+		Expect(mapper.Find(8192)).To(Equal(sim.RemotePort("RDMA")))
+	})
+})
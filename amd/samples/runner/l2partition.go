@@ -0,0 +1,18 @@
+package runner
+
+import (
+	"github.com/sarchlab/akita/v4/mem/vm"
+	"github.com/sarchlab/mgpusim/v4/amd/timing/writeback"
+)
+
+// l2Partitioner partitions the ways of all the L2 cache banks of a GPU in the
+// same way.
+type l2Partitioner []*writeback.Comp
+
+// SetWayPartition gives each process the given number of ways in every L2
+// cache bank.
+func (p l2Partitioner) SetWayPartition(partitions map[vm.PID]int) {
+	for _, l2 := range p {
+		l2.SetWayPartition(partitions)
+	}
+}
@@ -0,0 +1,151 @@
+package runner
+
+import (
+	"github.com/sarchlab/akita/v4/mem/mem"
+	"github.com/sarchlab/akita/v4/sim"
+)
+
+// delayedReq is a request that waits for the latency of the connection.
+type delayedReq struct {
+	msg       sim.Msg
+	readyTime sim.VTimeInSec
+}
+
+// A latencyConnection connects a memory component to the lower-level memory
+// with a fixed latency, such as the interposer between the L2 caches and the
+// DRAM controllers. The memory requests arrive at their destinations a fixed
+// number of cycles after they are sent, while the responses are delivered
+// without latency, so that the latency is added to each access only once. The
+// requests that are delayed at the same time do not limit each other.
+type latencyConnection struct {
+	*sim.TickingComponent
+
+	ports      []sim.Port
+	portMap    map[sim.RemotePort]sim.Port
+	nextPortID int
+
+	latency int
+	delayed []delayedReq
+}
+
+func newLatencyConnection(
+	name string,
+	engine sim.Engine,
+	freq sim.Freq,
+	latency int,
+) *latencyConnection {
+	c := &latencyConnection{
+		portMap: make(map[sim.RemotePort]sim.Port),
+		latency: latency,
+	}
+	c.TickingComponent = sim.NewSecondaryTickingComponent(
+		name, engine, freq, c)
+
+	return c
+}
+
+// PlugIn marks the port connects to this connection.
+func (c *latencyConnection) PlugIn(port sim.Port) {
+	c.Lock()
+	defer c.Unlock()
+
+	c.ports = append(c.ports, port)
+	c.portMap[port.AsRemote()] = port
+
+	port.SetConnection(c)
+}
+
+// Unplug marks the port no longer connects to this connection.
+func (c *latencyConnection) Unplug(_ sim.Port) {
+	panic("not implemented")
+}
+
+// NotifyAvailable is called by a port to notify that the connection can
+// deliver to the port again.
+func (c *latencyConnection) NotifyAvailable(p sim.Port) {
+	for _, port := range c.ports {
+		if port == p {
+			continue
+		}
+
+		port.NotifyAvailable()
+	}
+
+	c.TickNow()
+}
+
+// NotifySend is called by a port to notify that the connection can start to
+// tick now.
+func (c *latencyConnection) NotifySend() {
+	c.TickNow()
+}
+
+// Tick delivers the requests whose latency has passed and takes in the
+// messages from all the ports.
+func (c *latencyConnection) Tick() bool {
+	madeProgress := c.deliverDelayed()
+
+	for i := range c.ports {
+		port := c.ports[(i+c.nextPortID)%len(c.ports)]
+		madeProgress = c.forwardMany(port) || madeProgress
+	}
+
+	c.nextPortID = (c.nextPortID + 1) % len(c.ports)
+
+	// Keep ticking until all the delayed requests are delivered.
+	return madeProgress || len(c.delayed) > 0
+}
+
+// deliverDelayed delivers the requests whose latency has passed. A request
+// that its destination cannot accept stays until the next tick.
+func (c *latencyConnection) deliverDelayed() bool {
+	madeProgress := false
+	now := c.CurrentTime()
+
+	remaining := c.delayed[:0]
+	for _, m := range c.delayed {
+		if m.readyTime > now {
+			remaining = append(remaining, m)
+			continue
+		}
+
+		err := c.portMap[m.msg.Meta().Dst].Deliver(m.msg)
+		if err != nil {
+			remaining = append(remaining, m)
+			continue
+		}
+
+		madeProgress = true
+	}
+	c.delayed = remaining
+
+	return madeProgress
+}
+
+func (c *latencyConnection) forwardMany(port sim.Port) bool {
+	madeProgress := false
+
+	for {
+		head := port.PeekOutgoing()
+		if head == nil {
+			break
+		}
+
+		if _, isReq := head.(mem.AccessReq); isReq {
+			c.delayed = append(c.delayed, delayedReq{
+				msg:       head,
+				readyTime: c.Freq.NCyclesLater(c.latency, c.CurrentTime()),
+			})
+		} else {
+			err := c.portMap[head.Meta().Dst].Deliver(head)
+			if err != nil {
+				break
+			}
+		}
+
+		madeProgress = true
+		port.RetrieveOutgoing()
+	}
+
+	return madeProgress
+}
@@ -0,0 +1,46 @@
+package runner
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/sarchlab/akita/v4/mem/vm"
+	"github.com/sarchlab/akita/v4/sim"
+)
+
+// translationLatency returns the time that the MMU of a platform takes to
+// translate an address.
+func translationLatency(builder R9NanoPlatformBuilder) sim.VTimeInSec {
+	engine := sim.NewSerialEngine()
+	mmuComponent, pageTable := builder.createMMU(engine)
+	pageTable.Insert(vm.Page{
+		PID:      1,
+		VAddr:    0x1000,
+		PAddr:    0x2000,
+		PageSize: 4096,
+		Valid:    true,
+	})
+
+	agent := newRequestAgent(engine, mmuComponent.GetPortByName("Top"))
+	req := vm.TranslationReqBuilder{}.
+		WithSrc(agent.port.AsRemote()).
+		WithDst(mmuComponent.GetPortByName("Top").AsRemote()).
+		WithPID(1).
+		WithVAddr(0x1000).
+		Build()
+
+	return agent.run(req)[0]
+}
+
+var _ = Describe("Page Walk Latency", func() {
+	It("should delay the translations of the MMU", func() {
+		builder := MakeR9NanoBuilder().WithPageWalkLatency(100)
+
+		short := translationLatency(builder)
+		long := translationLatency(builder.WithPageWalkLatency(1000))
+		scaled := translationLatency(builder.WithTimingScale(10))
+
+		Expect(long - short).To(BeNumerically("~", 900e-9, 2e-9))
+		Expect(scaled).To(BeNumerically("~", long, 1e-9))
+	})
+})
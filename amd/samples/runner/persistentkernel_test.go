@@ -0,0 +1,66 @@
+package runner
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/sarchlab/akita/v4/tracing"
+	"github.com/sarchlab/mgpusim/v4/amd/driver"
+	"github.com/sarchlab/mgpusim/v4/amd/kernels"
+)
+
+type persistentArgs struct {
+	StopFlag driver.Ptr
+	Out      driver.Ptr
+}
+
+var _ = Describe("Persistent Kernel", func() {
+	// numPollsBeforeStop is the number of times that the kernel polls the
+	// stop flag before the host sets the flag.
+	const numPollsBeforeStop = 10
+
+	It("should exit after the host sets the stop flag", func() {
+		platform := MakeR9NanoBuilder().WithNumGPU(1).Build()
+
+		// The kernel executes 6 instructions before the loop and 5
+		// instructions in each poll.
+		polling := make(chan bool)
+		stopper := newInstStopper(6+5*numPollsBeforeStop, func() {
+			close(polling)
+		})
+		for _, cu := range platform.GPUs[0].CUs {
+			tracing.CollectTrace(cu, stopper)
+		}
+
+		gpuDriver := platform.Driver
+		gpuDriver.Run()
+		defer gpuDriver.Terminate()
+
+		ctx := gpuDriver.Init()
+		queue := gpuDriver.CreateCommandQueue(ctx)
+		hsaco := kernels.LoadProgram("testdata/persistent.hsaco", "")
+		args := persistentArgs{
+			StopFlag: gpuDriver.AllocateStopFlag(ctx),
+			Out:      gpuDriver.AllocateMemory(ctx, 4),
+		}
+
+		gpuDriver.EnqueuePersistentKernel(queue, hsaco,
+			[3]uint32{1, 1, 1}, [3]uint16{1, 1, 1}, &args, args.StopFlag)
+
+		done := make(chan bool)
+		go func() {
+			gpuDriver.DrainCommandQueue(queue)
+			close(done)
+		}()
+
+		Eventually(polling, "1m").Should(BeClosed())
+		Expect(done).NotTo(BeClosed())
+
+		gpuDriver.SetStopFlag(ctx, args.StopFlag)
+		Eventually(done, "1m").Should(BeClosed())
+
+		numPolls := make([]uint32, 1)
+		gpuDriver.MemCopyD2H(ctx, numPolls, args.Out)
+		Expect(numPolls[0]).To(BeNumerically(">", numPollsBeforeStop))
+	})
+})
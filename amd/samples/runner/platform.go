@@ -20,23 +20,103 @@ type Platform struct {
 	Engine sim.Engine
 	Driver *driver.Driver
 	GPUs   []*GPU
+
+	// SharedLLC is the last-level cache shared by all the GPUs. It is nil if
+	// the platform does not have a shared LLC.
+	SharedLLC *SharedLLC
+
+	// HostMemory is the memory of the host, which the GPUs access directly
+	// for the buffers that are mapped with Driver.MapHostMemory.
+	HostMemory TraceableComponent
+
+	spareGPUs          []*GPU
+	spareGPUProperties driver.DeviceProperties
+}
+
+// HotAddGPU registers the next spare GPU with the driver while the simulation
+// is running and returns the ID of the GPU. The GPU is appended to GPUs. The
+// spare GPUs are built with R9NanoPlatformBuilder.WithNumSpareGPU.
+func (p *Platform) HotAddGPU() int {
+	if len(p.spareGPUs) == 0 {
+		panic("no spare GPU to hot-add")
+	}
+
+	gpu := p.spareGPUs[0]
+	p.spareGPUs = p.spareGPUs[1:]
+
+	gpuID := p.Driver.HotAddGPU(
+		gpu.Domain.GetPortByName("CommandProcessor"), p.spareGPUProperties)
+	registerGPUComponents(p.Driver, gpuID, gpu)
+	p.GPUs = append(p.GPUs, gpu)
+
+	return gpuID
 }
 
 // A GPU is a collection of GPU internal Components
 type GPU struct {
 	Domain           *sim.Domain
 	CommandProcessor *cp.CommandProcessor
+	DMAEngine        *cp.DMAEngine
 	RDMAEngine       *rdma.Comp
 	PMC              *pagemigrationcontroller.PageMigrationController
 	CUs              []TraceableComponent
 	SIMDs            []TraceableComponent
+	L0Caches         []TraceableComponent
+	ConstantCaches   []TraceableComponent
 	L1VCaches        []TraceableComponent
 	L1SCaches        []TraceableComponent
 	L1ICaches        []TraceableComponent
+	L1Caches         []TraceableComponent
 	L2Caches         []TraceableComponent
 	L1VTLBs          []TraceableComponent
 	L1STLBs          []TraceableComponent
 	L1ITLBs          []TraceableComponent
 	L2TLBs           []TraceableComponent
-	MemControllers   []TraceableComponent
+
+	// MemControllers are the DRAM controllers that the GPU accesses. With a
+	// shared DRAM pool, all the GPUs list the controllers of the pool.
+	MemControllers []TraceableComponent
+
+	// ReuseDistanceAnalyzer records the reuse distance of the cache accesses.
+	// It is nil if the reuse distance analysis is not enabled.
+	ReuseDistanceAnalyzer driver.ReuseDistanceAnalyzer
+
+	// L2Partitioner partitions the ways of the L2 caches among the processes.
+	L2Partitioner driver.L2Partitioner
+
+	// AtomicStatsReporter counts the atomic accesses of the caches.
+	AtomicStatsReporter driver.AtomicStatsReporter
+
+	// RDMAStatsReporter counts the traffic of the RDMA engine.
+	RDMAStatsReporter driver.RDMAStatsReporter
+
+	// DRAMRowBufferStatsReporter reports the row buffer usage of the DRAM
+	// banks. With a shared DRAM pool, it reports the banks of the whole pool.
+	DRAMRowBufferStatsReporter driver.DRAMRowBufferStatsReporter
+
+	// MemQoSController sets the QoS classes of the accesses in the DRAM
+	// controllers.
+	MemQoSController driver.MemQoSController
+
+	// ConstantMemoryRouter routes the accesses to the constant memory to the
+	// constant caches. It is nil if the GPU does not have constant caches.
+	ConstantMemoryRouter driver.ConstantMemoryRouter
+
+	// SIMDUtilizationReporter reports the lane utilization of the SIMD units.
+	SIMDUtilizationReporter driver.SIMDUtilizationReporter
+
+	// CoalescingStatsReporter reports how many cache-line accesses the vector
+	// memory instructions generate.
+	CoalescingStatsReporter driver.CoalescingStatsReporter
+
+	// StallBreakdownReporter reports what the wavefronts of the CUs stall on.
+	StallBreakdownReporter driver.StallBreakdownReporter
+
+	// EnergyReporter estimates the energy that the GPU consumes. It is nil if
+	// the GPU does not have a power model.
+	EnergyReporter driver.EnergyReporter
+
+	// ThermalSensor reports the temperature of the GPU. It is nil if the GPU
+	// does not have a thermal model.
+	ThermalSensor driver.ThermalSensor
 }
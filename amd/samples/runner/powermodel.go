@@ -0,0 +1,99 @@
+package runner
+
+import (
+	"sync"
+
+	"github.com/sarchlab/akita/v4/mem/mem"
+	"github.com/sarchlab/akita/v4/tracing"
+	"github.com/sarchlab/mgpusim/v4/amd/driver"
+)
+
+// A PowerModel sets the energy, in joules, that each activity consumes. It is
+// a first-order activity-based model. The energy of an instruction is the
+// energy of executing it for a whole wavefront.
+type PowerModel struct {
+	VALUInstEnergy   float64
+	ScalarInstEnergy float64
+	VMemInstEnergy   float64
+	LDSInstEnergy    float64
+	DRAMAccessEnergy float64
+}
+
+// DefaultPowerModel returns a power model with rough energy costs of a 28 nm
+// GPU. The values are only meant to compare the activities with each other.
+func DefaultPowerModel() PowerModel {
+	return PowerModel{
+		VALUInstEnergy:   500e-12,
+		ScalarInstEnergy: 50e-12,
+		VMemInstEnergy:   1000e-12,
+		LDSInstEnergy:    300e-12,
+		DRAMAccessEnergy: 5000e-12,
+	}
+}
+
+// An energyTracer accumulates the energy of the activities of a GPU. It counts
+// the instructions that the CUs issue and the requests that arrive at the DRAM
+// controllers.
+type energyTracer struct {
+	model PowerModel
+
+	lock      sync.Mutex
+	breakdown driver.EnergyBreakdown
+}
+
+func newEnergyTracer(model PowerModel) *energyTracer {
+	return &energyTracer{model: model}
+}
+
+// EnergyBreakdown returns the energy consumed so far.
+func (t *energyTracer) EnergyBreakdown() driver.EnergyBreakdown {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	return t.breakdown
+}
+
+// StartTask adds the energy of an instruction or a DRAM access.
+func (t *energyTracer) StartTask(task tracing.Task) {
+	switch task.Kind {
+	case "inst":
+		t.addInstEnergy(task.What)
+	case "req_in":
+		if _, ok := task.Detail.(mem.AccessReq); ok {
+			t.lock.Lock()
+			t.breakdown.DRAM += t.model.DRAMAccessEnergy
+			t.lock.Unlock()
+		}
+	}
+}
+
+func (t *energyTracer) addInstEnergy(exeUnit string) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	switch exeUnit {
+	case "VALU":
+		t.breakdown.VALU += t.model.VALUInstEnergy
+	case "Scalar", "Branch":
+		t.breakdown.Scalar += t.model.ScalarInstEnergy
+	case "VMem":
+		t.breakdown.VMem += t.model.VMemInstEnergy
+	case "LDS":
+		t.breakdown.LDS += t.model.LDSInstEnergy
+	}
+}
+
+// StepTask does nothing.
+func (t *energyTracer) StepTask(_ tracing.Task) {
+	// Do nothing
+}
+
+// AddMilestone does nothing.
+func (t *energyTracer) AddMilestone(_ tracing.Milestone) {
+	// Do nothing
+}
+
+// EndTask does nothing.
+func (t *energyTracer) EndTask(_ tracing.Task) {
+	// Do nothing
+}
@@ -0,0 +1,62 @@
+package runner
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/sarchlab/akita/v4/mem/mem"
+	"github.com/sarchlab/akita/v4/tracing"
+	"github.com/sarchlab/mgpusim/v4/amd/driver"
+)
+
+var _ = Describe("Energy Tracer", func() {
+	var tracer *energyTracer
+
+	BeforeEach(func() {
+		tracer = newEnergyTracer(PowerModel{
+			VALUInstEnergy:   1,
+			ScalarInstEnergy: 2,
+			VMemInstEnergy:   4,
+			LDSInstEnergy:    8,
+			DRAMAccessEnergy: 16,
+		})
+	})
+
+	It("should add the energy of the instructions by execution unit", func() {
+		for _, exeUnit := range []string{
+			"VALU", "VALU", "Scalar", "Branch", "VMem", "LDS",
+		} {
+			tracer.StartTask(tracing.Task{Kind: "inst", What: exeUnit})
+		}
+
+		Expect(tracer.EnergyBreakdown()).To(Equal(driver.EnergyBreakdown{
+			VALU:   2,
+			Scalar: 4,
+			VMem:   4,
+			LDS:    8,
+		}))
+	})
+
+	It("should add the energy of the DRAM accesses", func() {
+		tracer.StartTask(tracing.Task{
+			Kind:   "req_in",
+			Detail: mem.ReadReqBuilder{}.Build(),
+		})
+		tracer.StartTask(tracing.Task{
+			Kind:   "req_in",
+			Detail: mem.WriteReqBuilder{}.Build(),
+		})
+
+		Expect(tracer.EnergyBreakdown()).To(Equal(driver.EnergyBreakdown{
+			DRAM: 32,
+		}))
+		Expect(tracer.EnergyBreakdown().Total()).To(Equal(32.0))
+	})
+
+	It("should ignore the other tasks", func() {
+		tracer.StartTask(tracing.Task{Kind: "req_in"})
+		tracer.StartTask(tracing.Task{Kind: "wavefront", What: "VALU"})
+
+		Expect(tracer.EnergyBreakdown()).To(Equal(driver.EnergyBreakdown{}))
+	})
+})
@@ -2,28 +2,39 @@ package runner
 
 import (
 	"fmt"
+	"math"
 
 	rob2 "github.com/sarchlab/mgpusim/v4/amd/timing/rob"
 
 	"github.com/sarchlab/akita/v4/analysis"
-	"github.com/sarchlab/akita/v4/mem/cache/writearound"
-	"github.com/sarchlab/akita/v4/mem/cache/writeback"
 	"github.com/sarchlab/akita/v4/mem/cache/writethrough"
-	"github.com/sarchlab/akita/v4/mem/dram"
 	"github.com/sarchlab/akita/v4/mem/mem"
-	"github.com/sarchlab/akita/v4/mem/vm/addresstranslator"
+	"github.com/sarchlab/akita/v4/mem/vm"
 	"github.com/sarchlab/akita/v4/mem/vm/mmu"
-	"github.com/sarchlab/akita/v4/mem/vm/tlb"
 	"github.com/sarchlab/akita/v4/monitoring"
 	"github.com/sarchlab/akita/v4/sim"
 	"github.com/sarchlab/akita/v4/sim/directconnection"
 	"github.com/sarchlab/akita/v4/tracing"
+	"github.com/sarchlab/mgpusim/v4/amd/insts"
+	"github.com/sarchlab/mgpusim/v4/amd/timing/addresstranslator"
 	"github.com/sarchlab/mgpusim/v4/amd/timing/cp"
 	"github.com/sarchlab/mgpusim/v4/amd/timing/cu"
+	"github.com/sarchlab/mgpusim/v4/amd/timing/dram"
 	"github.com/sarchlab/mgpusim/v4/amd/timing/pagemigrationcontroller"
 	"github.com/sarchlab/mgpusim/v4/amd/timing/rdma"
+	"github.com/sarchlab/mgpusim/v4/amd/timing/replacement"
+	"github.com/sarchlab/mgpusim/v4/amd/timing/tlb"
+	"github.com/sarchlab/mgpusim/v4/amd/timing/victimcache"
+	"github.com/sarchlab/mgpusim/v4/amd/timing/writearound"
+	"github.com/sarchlab/mgpusim/v4/amd/timing/writeback"
+	"github.com/sarchlab/mgpusim/v4/amd/timing/writecombining"
 )
 
+// TypicalDRAMRefreshInterval is a typical number of DRAM cycles between two
+// refreshes, which is 3.9 us at 500 MHz. The builders disable refresh by
+// default; pass the interval to WithDRAMRefreshInterval to model refresh.
+const TypicalDRAMRefreshInterval = 1950
+
 // R9NanoGPUBuilder can build R9 Nano GPUs.
 type R9NanoGPUBuilder struct {
 	engine                         sim.Engine
@@ -32,20 +43,73 @@ type R9NanoGPUBuilder struct {
 	mmu                            *mmu.Comp
 	numShaderArray                 int
 	numCUPerShaderArray            int
+	numSIMDPerCU                   int
+	wfSchedulingPolicy             cu.WavefrontSchedulingPolicy
+	wgSchedulingPolicy             cp.WGSchedulingPolicy
+	numVGPRBanks                   int
+	wavefrontSize                  int
+	wfSlotsPerSIMD                 int
+	sfuLatencyTable                map[insts.Opcode]int
+	barrierLatency                 int
+	interposerLatency              int
+	l1iMissLatency                 int
+	scalarMSHREntries              int
+	unifiedL1                      bool
+	numVGPRBankPorts               int
+	ldsSizePerCU                   uint64
+	memRequestInterceptor          MemRequestInterceptor
 	numMemoryBank                  int
 	dramSize                       uint64
 	l2CacheSize                    uint64
 	log2PageSize                   uint64
+	log2HugePageSize               uint64
 	log2CacheLineSize              uint64
 	log2MemoryBankInterleavingSize uint64
-
-	enableISADebugging bool
-	enableMemTracing   bool
-	enableVisTracing   bool
-	visTracer          tracing.Tracer
-	memTracer          tracing.Tracer
-	monitor            *monitoring.Monitor
-	perfAnalyzer       *analysis.PerfAnalyzer
+	timingScale                    float64
+	dmaMaxOutstanding              int
+	eccEnabled                     bool
+	eccBandwidthOverhead           float64
+	eccLatencyPenalty              int
+	dramRefreshInterval            int
+	dramChannelsPerBank            int
+	dramSubChannels                int
+	dramReadQueueSize              int
+	dramWriteQueueSize             int
+	dramOpenPage                   bool
+	dramQoSScheduling              bool
+	dramScheduler                  dram.SchedulingPolicy
+	dramFastTierSize               uint64
+	l2ReplacementPolicy            replacement.Policy
+	l2WriteBufferSize              int
+	l2BankHashMapping              bool
+	reuseDistanceAnalysis          bool
+	l1vReuseDistanceAnalysis       bool
+	l1vVictimCacheSize             uint64
+	l1vCoherence                   bool
+	l0CacheSize                    uint64
+	constantCacheSize              uint64
+	wcbDepth                       int
+	tlbShootdownLatency            int
+	l1TLBNumSets                   int
+	l1TLBNumWays                   int
+	l1vNumPorts                    int
+	kernelLaunchOverhead           int
+	wgDispatchRate                 int
+	powerModel                     *PowerModel
+	thermalModel                   *ThermalModel
+
+	enableISADebugging  bool
+	isaDebugWf          *isaDebugWavefront
+	validationPageTable vm.PageTable
+	idealTLBPageTable   vm.PageTable
+	faultPageTable      vm.PageTable
+	dramFaultInjector   dram.UncorrectableFaultPredicate
+	enableMemTracing    bool
+	enableVisTracing    bool
+	visTracer           tracing.Tracer
+	memTracer           tracing.Tracer
+	monitor             *monitoring.Monitor
+	perfAnalyzer        *analysis.PerfAnalyzer
 
 	gpuName                 string
 	gpu                     *GPU
@@ -55,9 +119,14 @@ type R9NanoGPUBuilder struct {
 	l1vReorderBuffers       []*rob2.ReorderBuffer
 	l1iReorderBuffers       []*rob2.ReorderBuffer
 	l1sReorderBuffers       []*rob2.ReorderBuffer
+	l0Caches                []*writearound.Comp
+	constantCaches          []*writearound.Comp
+	l1vWCBs                 []*writecombining.Comp
 	l1vCaches               []*writearound.Comp
+	l1vVictimCaches         []*victimcache.Comp
 	l1sCaches               []*writethrough.Comp
 	l1iCaches               []*writethrough.Comp
+	l1Caches                []*writearound.Comp
 	l2Caches                []*writeback.Comp
 	l1vAddrTrans            []*addresstranslator.Comp
 	l1sAddrTrans            []*addresstranslator.Comp
@@ -67,6 +136,7 @@ type R9NanoGPUBuilder struct {
 	l1iTLBs                 []*tlb.Comp
 	l2TLBs                  []*tlb.Comp
 	drams                   []*dram.Comp
+	slowTierDRAMs           []*dram.Comp
 	lowModuleFinderForL1    *mem.InterleavedAddressPortMapper
 	lowModuleFinderForL2    *mem.InterleavedAddressPortMapper
 	lowModuleFinderForPMC   *mem.InterleavedAddressPortMapper
@@ -74,11 +144,13 @@ type R9NanoGPUBuilder struct {
 	rdmaEngine              *rdma.Comp
 	pageMigrationController *pagemigrationcontroller.PageMigrationController
 	globalStorage           *mem.Storage
+	sharedDRAMPool          *SharedDRAMPool
+	sharedLLC               *SharedLLC
 
 	internalConn           *directconnection.Comp
 	l1TLBToL2TLBConnection *directconnection.Comp
 	l1ToL2Connection       *directconnection.Comp
-	l2ToDramConnection     *directconnection.Comp
+	l2ToDramConnection     sim.Connection
 }
 
 // MakeR9NanoGPUBuilder provides a GPU builder that can builds the R9Nano GPU.
@@ -87,12 +159,25 @@ func MakeR9NanoGPUBuilder() R9NanoGPUBuilder {
 		freq:                           1 * sim.GHz,
 		numShaderArray:                 16,
 		numCUPerShaderArray:            4,
+		numSIMDPerCU:                   4,
+		ldsSizePerCU:                   64 * mem.KB,
+		wavefrontSize:                  64,
+		wfSlotsPerSIMD:                 10,
 		numMemoryBank:                  16,
 		log2CacheLineSize:              6,
 		log2PageSize:                   12,
 		log2MemoryBankInterleavingSize: 12,
 		l2CacheSize:                    2 * mem.MB,
+		l2WriteBufferSize:              1024,
 		dramSize:                       4 * mem.GB,
+		timingScale:                    1,
+		dmaMaxOutstanding:              4,
+		eccBandwidthOverhead:           DefaultECCBandwidthOverhead,
+		eccLatencyPenalty:              DefaultECCLatencyPenalty,
+		dramChannelsPerBank:            1,
+		dramSubChannels:                1,
+		l1TLBNumSets:                   1,
+		l1TLBNumWays:                   64,
 	}
 	return b
 }
@@ -146,6 +231,16 @@ func (b R9NanoGPUBuilder) WithNumCUPerShaderArray(n int) R9NanoGPUBuilder {
 	return b
 }
 
+// WithNumSIMDPerCU sets the number of SIMD units in each CU.
+func (b R9NanoGPUBuilder) WithNumSIMDPerCU(n int) R9NanoGPUBuilder {
+	if n <= 0 {
+		panic("the number of SIMD units per CU must be positive")
+	}
+
+	b.numSIMDPerCU = n
+	return b
+}
+
 // WithLog2MemoryBankInterleavingSize sets the number of consecutive bytes that
 // are guaranteed to be on a memory bank.
 func (b R9NanoGPUBuilder) WithLog2MemoryBankInterleavingSize(
@@ -170,12 +265,92 @@ func (b R9NanoGPUBuilder) WithMemTracer(t tracing.Tracer) R9NanoGPUBuilder {
 	return b
 }
 
+// WithReuseDistanceAnalysis lets the GPU record the reuse distance of the
+// accesses to the L2 caches, which can be retrieved with
+// Driver.GetReuseDistanceHistogram. The analysis needs memory that grows
+// linearly with the number of accesses, about 1 GB for every 100 million
+// accesses, so it should only be enabled for small workloads.
+func (b R9NanoGPUBuilder) WithReuseDistanceAnalysis() R9NanoGPUBuilder {
+	b.reuseDistanceAnalysis = true
+	return b
+}
+
+// WithL1VReuseDistanceAnalysis lets the GPU record the reuse distance of the
+// accesses to the L1 vector caches in addition to the L2 caches. The accesses
+// of both levels are counted in the same histogram.
+func (b R9NanoGPUBuilder) WithL1VReuseDistanceAnalysis() R9NanoGPUBuilder {
+	b.reuseDistanceAnalysis = true
+	b.l1vReuseDistanceAnalysis = true
+	return b
+}
+
 // WithISADebugging enables the GPU to dump instruction execution information.
 func (b R9NanoGPUBuilder) WithISADebugging() R9NanoGPUBuilder {
 	b.enableISADebugging = true
 	return b
 }
 
+// isaDebugWavefront identifies the wavefront whose instructions are dumped.
+type isaDebugWavefront struct {
+	cuIndex int
+	wgID    int
+	wfID    int
+}
+
+// WithISADebuggingForWavefront lets the GPU dump the instruction execution
+// information of a single wavefront, rather than all the wavefronts. The CU is
+// identified by its index in the GPU, the work-group by its ID flattened in
+// the x-major order, and the wavefront by its index in the work-group. The
+// dump is only written if the work-group runs on the given CU.
+func (b R9NanoGPUBuilder) WithISADebuggingForWavefront(
+	cuIndex, wgID, wfID int,
+) R9NanoGPUBuilder {
+	if cuIndex < 0 || wgID < 0 || wfID < 0 {
+		panic("the CU index, work-group ID, and wavefront ID must not " +
+			"be negative")
+	}
+
+	b.isaDebugWf = &isaDebugWavefront{
+		cuIndex: cuIndex,
+		wgID:    wgID,
+		wfID:    wfID,
+	}
+	return b
+}
+
+// WithTranslationValidation lets the address translators of the GPU check the
+// physical address of every memory access against the page table and panic on
+// mismatch. It slows down the simulation and is meant for debugging the
+// address translation.
+func (b R9NanoGPUBuilder) WithTranslationValidation(
+	pageTable vm.PageTable,
+) R9NanoGPUBuilder {
+	b.validationPageTable = pageTable
+	return b
+}
+
+// WithFaultDetection lets the CUs of the GPU check the addresses of the vector
+// memory accesses against the page table. The accesses to unmapped addresses
+// are reported as kernel faults rather than reaching the MMU.
+func (b R9NanoGPUBuilder) WithFaultDetection(
+	pageTable vm.PageTable,
+) R9NanoGPUBuilder {
+	b.faultPageTable = pageTable
+	return b
+}
+
+// WithIdealTLB makes all the L1 and L2 TLBs of the GPU translate every address
+// from the page table in a single cycle, as if they had an infinite capacity,
+// so that the cache and DRAM behavior can be studied without the translation
+// overhead. The TLBs never miss, so the accesses never reach the MMU and never
+// trigger page migrations.
+func (b R9NanoGPUBuilder) WithIdealTLB(
+	pageTable vm.PageTable,
+) R9NanoGPUBuilder {
+	b.idealTLBPageTable = pageTable
+	return b
+}
+
 // WithLog2CacheLineSize sets the cache line size with the power of 2.
 func (b R9NanoGPUBuilder) WithLog2CacheLineSize(
 	log2CacheLine uint64,
@@ -190,6 +365,13 @@ func (b R9NanoGPUBuilder) WithLog2PageSize(log2PageSize uint64) R9NanoGPUBuilder
 	return b
 }
 
+// WithHugePageSize sets the huge page size with the power of 2. The TLBs can
+// hold huge pages in addition to the base pages. Use 0 to disable huge pages.
+func (b R9NanoGPUBuilder) WithHugePageSize(log2 uint64) R9NanoGPUBuilder {
+	b.log2HugePageSize = log2
+	return b
+}
+
 // WithMonitor sets the monitor to use.
 func (b R9NanoGPUBuilder) WithMonitor(m *monitoring.Monitor) R9NanoGPUBuilder {
 	b.monitor = m
@@ -226,8 +408,475 @@ func (b R9NanoGPUBuilder) WithGlobalStorage(
 	return b
 }
 
+// WithSharedDRAMPool lets the GPU to use the memory controllers in a shared
+// DRAM pool rather than building its own memory controllers. All the addresses
+// are served by the local L2 caches, so that multiple GPUs can access the same
+// physical pages without going through the RDMA engine.
+func (b R9NanoGPUBuilder) WithSharedDRAMPool(
+	pool *SharedDRAMPool,
+) R9NanoGPUBuilder {
+	b.sharedDRAMPool = pool
+	return b
+}
+
+// WithSharedLLC lets the L2 caches send the misses to a last-level cache that
+// is shared with other GPUs. The LLC must be built in front of the shared DRAM
+// pool that the GPU uses.
+func (b R9NanoGPUBuilder) WithSharedLLC(llc *SharedLLC) R9NanoGPUBuilder {
+	b.sharedLLC = llc
+	return b
+}
+
+// WithTimingScale multiplies all the latency-like parameters, including the
+// cache latencies, the TLB latencies, and the DRAM timing parameters, by the
+// given factor. The factor is applied when the GPU is built, so it also scales
+// the latencies that are set individually. The compute latencies, such as the
+// instruction latencies of the CUs, are not scaled. This is only an
+// approximation of a slower or faster technology node, as the
+// throughput-related parameters are not changed.
+func (b R9NanoGPUBuilder) WithTimingScale(factor float64) R9NanoGPUBuilder {
+	b.timingScale = factor
+	return b
+}
+
+// WithDMAMaxOutstanding sets the number of memory copy requests that the DMA
+// engine can process at the same time.
+func (b R9NanoGPUBuilder) WithDMAMaxOutstanding(n int) R9NanoGPUBuilder {
+	b.dmaMaxOutstanding = n
+	return b
+}
+
+// WithL2ReplacementPolicy sets the policy that the L2 caches use to select
+// the block to evict.
+func (b R9NanoGPUBuilder) WithL2ReplacementPolicy(
+	policy replacement.Policy,
+) R9NanoGPUBuilder {
+	b.l2ReplacementPolicy = policy
+	return b
+}
+
+// WithL2WriteBufferSize sets the number of evicted cache lines that the write
+// buffer of each L2 cache bank can hold while they are written to the DRAM. The
+// L2 caches stall when the write buffer is full, so a shallow write buffer
+// slows down the write-heavy kernels.
+func (b R9NanoGPUBuilder) WithL2WriteBufferSize(n int) R9NanoGPUBuilder {
+	if n <= 0 {
+		panic("the L2 write buffer size must be positive")
+	}
+
+	b.l2WriteBufferSize = n
+	return b
+}
+
+// WithL2BankHashMapping lets the L1 caches map the addresses to the L2 cache
+// banks with an XOR hash of the address bits instead of interleaving the
+// banks. The hash spreads power-of-two strided accesses across the banks. The
+// number of memory banks must be a power of two. Since a bank no longer holds
+// an interleaved part of the address space, the banks index their sets with
+// the full addresses.
+func (b R9NanoGPUBuilder) WithL2BankHashMapping() R9NanoGPUBuilder {
+	b.l2BankHashMapping = true
+	return b
+}
+
+// WithUncorrectableFaultInjector injects uncorrectable memory errors into the
+// reads of the physical addresses that the predicate selects in the DRAM
+// controllers of the GPU. A faulty read returns zeroed data, and the fault is
+// reported by Driver.GetLastKernelError for the kernels that are running on
+// the GPU. The controllers of a shared DRAM pool do not inject faults, as they
+// do not belong to a single GPU.
+func (b R9NanoGPUBuilder) WithUncorrectableFaultInjector(
+	predicate dram.UncorrectableFaultPredicate,
+) R9NanoGPUBuilder {
+	b.dramFaultInjector = predicate
+	return b
+}
+
+// WithECCEnabled lets the DRAM controllers model the overhead of ECC. The
+// bandwidth overhead is modeled by the DRAM controllers reading and writing
+// the ECC bits in addition to the data, and the latency penalty is added to
+// the CAS latency.
+func (b R9NanoGPUBuilder) WithECCEnabled() R9NanoGPUBuilder {
+	b.eccEnabled = true
+	return b
+}
+
+// WithECCOverhead sets the fraction of the raw DRAM bandwidth that is used to
+// transfer the ECC bits and the number of DRAM cycles that are added to each
+// read to check the ECC bits. It only takes effect if ECC is enabled.
+func (b R9NanoGPUBuilder) WithECCOverhead(
+	bandwidthOverhead float64,
+	latencyPenalty int,
+) R9NanoGPUBuilder {
+	b.eccBandwidthOverhead = bandwidthOverhead
+	b.eccLatencyPenalty = latencyPenalty
+	return b
+}
+
+// WithDRAMRefreshInterval sets the number of DRAM cycles between two refreshes
+// of the DRAM controllers (tREFI). Setting the interval to 0, which is the
+// default, disables refresh.
+func (b R9NanoGPUBuilder) WithDRAMRefreshInterval(trefi int) R9NanoGPUBuilder {
+	b.dramRefreshInterval = trefi
+	return b
+}
+
+// WithDRAMChannelsPerBank sets the number of channels that each DRAM
+// controller manages. Channels work in parallel, so more channels provide
+// more bandwidth. The capacity of each memory bank does not change; the ranks
+// are divided among the channels.
+func (b R9NanoGPUBuilder) WithDRAMChannelsPerBank(n int) R9NanoGPUBuilder {
+	b.dramChannelsPerBank = n
+	return b
+}
+
+// WithDRAMSubChannels splits each DRAM channel into a number of sub-channels
+// (pseudo-channels). Each sub-channel has its own banks and uses a part of the
+// bus, so that the DRAM serves smaller accesses with more parallelism. The
+// peak bandwidth and the capacity do not change.
+func (b R9NanoGPUBuilder) WithDRAMSubChannels(n int) R9NanoGPUBuilder {
+	b.dramSubChannels = n
+	return b
+}
+
+// WithDRAMReadQueueSize sets the number of read sub-transactions that each
+// DRAM controller can buffer. Setting a read or write queue size separates the
+// reads and the writes into two queues, so that write-heavy traffic cannot
+// take all the queue slots from the reads.
+func (b R9NanoGPUBuilder) WithDRAMReadQueueSize(n int) R9NanoGPUBuilder {
+	b.dramReadQueueSize = n
+	return b
+}
+
+// WithDRAMWriteQueueSize sets the number of write sub-transactions that each
+// DRAM controller can buffer. See WithDRAMReadQueueSize.
+func (b R9NanoGPUBuilder) WithDRAMWriteQueueSize(n int) R9NanoGPUBuilder {
+	b.dramWriteQueueSize = n
+	return b
+}
+
+// WithDRAMOpenPagePolicy lets the DRAM controllers keep the row of a bank open
+// after an access, so that the following accesses to the row hit in the row
+// buffer.
+func (b R9NanoGPUBuilder) WithDRAMOpenPagePolicy() R9NanoGPUBuilder {
+	b.dramOpenPage = true
+	return b
+}
+
+// WithDRAMQoSScheduling lets the DRAM controllers serve the accesses to the
+// buffers that are set as latency-critical with Driver.SetMemQoSClass before
+// the other accesses.
+func (b R9NanoGPUBuilder) WithDRAMQoSScheduling() R9NanoGPUBuilder {
+	b.dramQoSScheduling = true
+	return b
+}
+
+// WithDRAMScheduler sets the order in which the DRAM controllers issue the
+// commands. The default is dram.SchedulingFRFCFS, which issues the accesses to
+// the open rows first.
+func (b R9NanoGPUBuilder) WithDRAMScheduler(
+	policy dram.SchedulingPolicy,
+) R9NanoGPUBuilder {
+	b.dramScheduler = policy
+	return b
+}
+
+// WithTieredDRAM splits the DRAM of the GPU into two tiers. The first fastSize
+// bytes of the GPU memory are in the fast stacked memory and the rest are in
+// the slower capacity memory. Each memory bank has a controller for each tier.
+func (b R9NanoGPUBuilder) WithTieredDRAM(fastSize uint64) R9NanoGPUBuilder {
+	if fastSize == 0 {
+		panic("the fast DRAM tier must not be empty")
+	}
+
+	b.dramFastTierSize = fastSize
+	return b
+}
+
+// WithPowerModel lets the GPU estimate the energy that it consumes with the
+// given power model. The energy can be retrieved with
+// Driver.GetEnergyConsumed.
+func (b R9NanoGPUBuilder) WithPowerModel(model PowerModel) R9NanoGPUBuilder {
+	b.powerModel = &model
+	return b
+}
+
+// WithThermalModel lets the GPU model its temperature with the given thermal
+// model and throttle its CUs when it is too hot. The temperature can be
+// retrieved with Driver.GetTemperature. The temperature follows the energy of
+// the power model, so the GPU uses DefaultPowerModel if WithPowerModel is not
+// called.
+func (b R9NanoGPUBuilder) WithThermalModel(
+	model ThermalModel,
+) R9NanoGPUBuilder {
+	if model.ThermalResistance <= 0 || model.HeatCapacity <= 0 {
+		panic("the thermal resistance and heat capacity must be positive")
+	}
+
+	if model.ThrottledFreqScale <= 0 || model.ThrottledFreqScale > 1 {
+		panic("the throttled frequency scale must be in (0, 1]")
+	}
+
+	b.thermalModel = &model
+	return b
+}
+
+// WithL1VVictimCache inserts a fully-associative victim cache of the given
+// size between each L1 vector cache and the L2 caches. The victim cache holds
+// the lines that the L1 vector cache evicts.
+func (b R9NanoGPUBuilder) WithL1VVictimCache(byteSize uint64) R9NanoGPUBuilder {
+	b.l1vVictimCacheSize = byteSize
+	return b
+}
+
+// WithUnifiedL1 replaces the L1 vector, scalar, and instruction caches of each
+// shader array with a single L1 cache that serves all three types of accesses.
+// The unified cache has the total capacity of the caches that it replaces and
+// the latency of the L1 vector cache. The instruction fetches are translated
+// before they reach the cache. It cannot be used with the L1V victim caches,
+// the L1V coherence, or an L1I miss latency.
+func (b R9NanoGPUBuilder) WithUnifiedL1() R9NanoGPUBuilder {
+	b.unifiedL1 = true
+	return b
+}
+
+// WithL0Cache inserts a cache of the given size inside each CU, between the
+// vector memory unit and the L1 vector cache.
+func (b R9NanoGPUBuilder) WithL0Cache(byteSize uint64) R9NanoGPUBuilder {
+	b.l0CacheSize = byteSize
+	return b
+}
+
+// WithConstantCache builds a constant cache of the given size inside each CU.
+// The accesses to the buffers allocated with Driver.AllocateConstantMemory
+// bypass the L0 and L1 vector caches and go through the constant cache, which
+// serves a load that all the lanes of a wavefront make to the same address
+// with a single access. It cannot be used with the unified L1.
+func (b R9NanoGPUBuilder) WithConstantCache(byteSize uint64) R9NanoGPUBuilder {
+	b.constantCacheSize = byteSize
+	return b
+}
+
+// WithWriteCombining inserts a write-combining buffer in front of each L1
+// vector cache. The buffer holds up to depth cache lines and combines the
+// stores to the same cache line into one write to the L1 vector cache.
+func (b R9NanoGPUBuilder) WithWriteCombining(depth int) R9NanoGPUBuilder {
+	if depth < 0 {
+		panic("the write-combining buffer depth must not be negative")
+	}
+
+	b.wcbDepth = depth
+	return b
+}
+
+// WithTLBShootdownLatency sets the number of cycles that each TLB takes to
+// invalidate its entries during a TLB shootdown. A shootdown flushes all the
+// TLBs of the GPU, so a long latency slows down the page migrations.
+func (b R9NanoGPUBuilder) WithTLBShootdownLatency(cycles int) R9NanoGPUBuilder {
+	if cycles < 0 {
+		panic("the TLB shootdown latency must not be negative")
+	}
+
+	b.tlbShootdownLatency = cycles
+	return b
+}
+
+// WithL1TLBNumSets sets the number of sets of each L1 TLB.
+func (b R9NanoGPUBuilder) WithL1TLBNumSets(numSets int) R9NanoGPUBuilder {
+	if numSets <= 0 {
+		panic("the L1 TLB must have at least one set")
+	}
+
+	b.l1TLBNumSets = numSets
+	return b
+}
+
+// WithL1TLBNumWays sets the number of ways in each set of each L1 TLB.
+func (b R9NanoGPUBuilder) WithL1TLBNumWays(numWays int) R9NanoGPUBuilder {
+	if numWays <= 0 {
+		panic("the L1 TLB must have at least one way")
+	}
+
+	b.l1TLBNumWays = numWays
+	return b
+}
+
+// WithL1VNumPorts sets the number of accesses to the tag and the data arrays
+// that each L1 vector cache can serve in each cycle. The accesses that exceed
+// the number of ports wait for the next cycle. By default, the number of
+// accesses is not limited.
+func (b R9NanoGPUBuilder) WithL1VNumPorts(numPorts int) R9NanoGPUBuilder {
+	if numPorts <= 0 {
+		panic("the L1 vector cache must have at least one port")
+	}
+
+	b.l1vNumPorts = numPorts
+	return b
+}
+
+// WithKernelLaunchOverhead sets the number of cycles that the Command
+// Processor takes to set up each kernel before the first wavefront starts. The
+// overhead dominates the workloads that launch many small kernels.
+func (b R9NanoGPUBuilder) WithKernelLaunchOverhead(
+	cycles int,
+) R9NanoGPUBuilder {
+	b.kernelLaunchOverhead = cycles
+	return b
+}
+
+// WithWGDispatchRate sets the number of work-groups that the Command Processor
+// can dispatch to the CUs in each cycle for a kernel. A low rate limits the
+// kernels with many small work-groups.
+func (b R9NanoGPUBuilder) WithWGDispatchRate(wgPerCycle int) R9NanoGPUBuilder {
+	b.wgDispatchRate = wgPerCycle
+	return b
+}
+
+// WithWGSchedulingPolicy sets how the Command Processor chooses the CU that
+// each work-group runs on.
+func (b R9NanoGPUBuilder) WithWGSchedulingPolicy(
+	policy cp.WGSchedulingPolicy,
+) R9NanoGPUBuilder {
+	b.wgSchedulingPolicy = policy
+	return b
+}
+
+// WithL1VCoherence keeps the L1 vector caches coherent. The L2 caches track the
+// L1 vector caches that hold each cache line and invalidate the copies in the
+// other L1 vector caches when a cache line is written. It cannot be used
+// together with the L1 vector victim caches.
+func (b R9NanoGPUBuilder) WithL1VCoherence() R9NanoGPUBuilder {
+	b.l1vCoherence = true
+	return b
+}
+
+// WithWavefrontSchedulingPolicy sets the order in which the CUs consider the
+// wavefronts of a SIMD when issuing instructions.
+func (b R9NanoGPUBuilder) WithWavefrontSchedulingPolicy(
+	policy cu.WavefrontSchedulingPolicy,
+) R9NanoGPUBuilder {
+	b.wfSchedulingPolicy = policy
+	return b
+}
+
+// WithWavefrontSize sets the number of work-items in a wavefront, which can be
+// 32, as in the wave32 mode of RDNA GPUs, or 64. The same work-group is split
+// into twice as many wave32 wavefronts, each of which takes half the cycles to
+// execute a vector instruction and half the vector register space.
+func (b R9NanoGPUBuilder) WithWavefrontSize(n int) R9NanoGPUBuilder {
+	if n != 32 && n != 64 {
+		panic(fmt.Sprintf("wavefront size %d is not supported", n))
+	}
+
+	b.wavefrontSize = n
+	return b
+}
+
+// WithWavefrontSlotsPerSIMD sets the maximum number of wavefronts that can be
+// resident on each SIMD unit. Together with the registers and the LDS, the
+// wavefront slots limit the occupancy of the CUs.
+func (b R9NanoGPUBuilder) WithWavefrontSlotsPerSIMD(n int) R9NanoGPUBuilder {
+	b.wfSlotsPerSIMD = n
+	return b
+}
+
+// WithSFULatencyTable sets the number of cycles that a wavefront takes to
+// execute each of the transcendental VOP1 instructions, indexed by the opcode.
+// The instructions that are not in the table take as many cycles as the basic
+// arithmetic. By default, the CUs use cu.DefaultSFULatencyTable.
+func (b R9NanoGPUBuilder) WithSFULatencyTable(
+	table map[insts.Opcode]int,
+) R9NanoGPUBuilder {
+	b.sfuLatencyTable = table
+	return b
+}
+
+// WithBarrierLatency sets the number of cycles that the wavefronts of a
+// work-group wait after the last of them arrives at an s_barrier before they
+// can continue. By default, the wavefronts continue right away.
+func (b R9NanoGPUBuilder) WithBarrierLatency(cycles int) R9NanoGPUBuilder {
+	if cycles < 0 {
+		panic("the barrier latency must not be negative")
+	}
+
+	b.barrierLatency = cycles
+	return b
+}
+
+// WithInterposerLatency places the DRAM controllers on a separate die, so that
+// every memory request from the L2 caches, the DMA engine, and the page
+// migration controller takes the given number of extra cycles to reach the
+// DRAM. It cannot be used with a shared LLC or a shared DRAM pool.
+func (b R9NanoGPUBuilder) WithInterposerLatency(cycles int) R9NanoGPUBuilder {
+	if cycles < 0 {
+		panic("the interposer latency must not be negative")
+	}
+
+	b.interposerLatency = cycles
+	return b
+}
+
+// WithL1IMissLatency sets the number of extra cycles that an instruction fetch
+// takes when it misses in the L1 instruction cache. The wavefront that waits
+// for the fetch does not issue instructions until the fetch returns, so the
+// kernels with large code footprints slow down. By default, a miss only takes
+// the time of the lower-level memory.
+func (b R9NanoGPUBuilder) WithL1IMissLatency(cycles int) R9NanoGPUBuilder {
+	if cycles < 0 {
+		panic("the L1I miss latency must not be negative")
+	}
+
+	b.l1iMissLatency = cycles
+	return b
+}
+
+// WithScalarMSHREntries sets the number of scalar memory requests that each CU
+// can keep outstanding, which is also the number of MSHR entries of the L1
+// scalar caches. The scalar loads do not block the wavefronts, but a load that
+// does not find a free entry stalls the scalar unit of the CU. By default, the
+// CUs do not limit the scalar requests and the L1 scalar caches have 16 MSHR
+// entries.
+func (b R9NanoGPUBuilder) WithScalarMSHREntries(n int) R9NanoGPUBuilder {
+	if n < 1 {
+		panic("the number of scalar MSHR entries must be at least 1")
+	}
+
+	b.scalarMSHREntries = n
+	return b
+}
+
+// WithBankedRegisterFile models the vector register files of the CUs with a
+// number of banks, each of which can read a number of registers per cycle. The
+// VALU instructions whose operands conflict on a bank take extra cycles.
+func (b R9NanoGPUBuilder) WithBankedRegisterFile(
+	numBanks, numPorts int,
+) R9NanoGPUBuilder {
+	b.numVGPRBanks = numBanks
+	b.numVGPRBankPorts = numPorts
+	return b
+}
+
+// WithLDSSizePerCU sets the number of bytes in the LDS of each CU. The default
+// size is 64KB. A smaller LDS fits fewer work-groups that use shared memory.
+func (b R9NanoGPUBuilder) WithLDSSizePerCU(size uint64) R9NanoGPUBuilder {
+	b.ldsSizePerCU = size
+	return b
+}
+
+// WithMemRequestInterceptor calls the interceptor on each memory request that
+// the L1 caches send to the L2 caches. The interceptor can observe the
+// request, or change it, for example, to remap its address.
+func (b R9NanoGPUBuilder) WithMemRequestInterceptor(
+	interceptor MemRequestInterceptor,
+) R9NanoGPUBuilder {
+	b.memRequestInterceptor = interceptor
+	return b
+}
+
 // Build creates a pre-configure GPU similar to the AMD R9 Nano GPU.
 func (b R9NanoGPUBuilder) Build(name string, id uint64) *GPU {
+	b.mustHaveValidCacheLineSize()
+
 	b.createGPU(name, id)
 	b.buildSAs()
 	b.buildL2Caches()
@@ -241,10 +890,102 @@ func (b R9NanoGPUBuilder) Build(name string, id uint64) *GPU {
 	b.connectL1TLBToL2TLB()
 
 	b.populateExternalPorts()
+	b.buildReuseDistanceAnalyzer()
+	b.buildAtomicStatsTracer()
+	b.buildRDMAStatsTracer()
+	b.buildEnergyTracer()
 
 	return b.gpu
 }
 
+// mustHaveValidCacheLineSize panics if the cache line does not fit in the
+// memory bank interleaving size. The L2 caches count the interleaving in cache
+// lines, so each memory bank must hold whole cache lines.
+func (b *R9NanoGPUBuilder) mustHaveValidCacheLineSize() {
+	if b.log2CacheLineSize > b.log2MemoryBankInterleavingSize {
+		panic("the cache line must not be larger than the memory bank " +
+			"interleaving size")
+	}
+}
+
+func (b *R9NanoGPUBuilder) buildEnergyTracer() {
+	if b.powerModel == nil && b.thermalModel == nil {
+		return
+	}
+
+	powerModel := DefaultPowerModel()
+	if b.powerModel != nil {
+		powerModel = *b.powerModel
+	}
+
+	tracer := newEnergyTracer(powerModel)
+	b.gpu.EnergyReporter = tracer
+
+	components := append([]TraceableComponent{}, b.gpu.CUs...)
+	components = append(components, b.gpu.MemControllers...)
+
+	for _, c := range components {
+		tracing.CollectTrace(c, tracer)
+	}
+
+	if b.thermalModel == nil {
+		return
+	}
+
+	// The thermal tracer hooks after the energy tracer, so that it sees the
+	// energy of each task.
+	thermal := newThermalTracer(
+		*b.thermalModel, tracer, b.engine, b.cus, b.freq)
+	b.gpu.ThermalSensor = thermal
+
+	for _, c := range components {
+		tracing.CollectTrace(c, thermal)
+	}
+}
+
+func (b *R9NanoGPUBuilder) buildRDMAStatsTracer() {
+	tracer := newRDMAStatsTracer(b.engine)
+	b.gpu.RDMAStatsReporter = tracer
+	tracing.CollectTrace(b.rdmaEngine, tracer)
+}
+
+func (b *R9NanoGPUBuilder) buildAtomicStatsTracer() {
+	var perfLogger analysis.PerfLogger
+	if b.perfAnalyzer != nil {
+		perfLogger = b.perfAnalyzer
+	}
+
+	tracer := newAtomicStatsTracer(b.engine, perfLogger)
+	b.gpu.AtomicStatsReporter = tracer
+
+	for _, l1v := range b.gpu.L1VCaches {
+		tracer.collectL1V(l1v)
+	}
+
+	for _, l2 := range b.gpu.L2Caches {
+		tracer.collectL2(l2)
+	}
+}
+
+func (b *R9NanoGPUBuilder) buildReuseDistanceAnalyzer() {
+	if !b.reuseDistanceAnalysis {
+		return
+	}
+
+	analyzer := newReuseDistanceAnalyzer(b.log2CacheLineSize)
+	b.gpu.ReuseDistanceAnalyzer = analyzer
+
+	for _, l2 := range b.gpu.L2Caches {
+		tracing.CollectTrace(l2, analyzer)
+	}
+
+	if b.l1vReuseDistanceAnalysis {
+		for _, l1v := range b.gpu.L1VCaches {
+			tracing.CollectTrace(l1v, analyzer)
+		}
+	}
+}
+
 func (b *R9NanoGPUBuilder) populateExternalPorts() {
 	b.gpu.Domain.AddPort("CommandProcessor", b.cp.ToDriver)
 	b.gpu.Domain.AddPort("RDMA", b.rdmaEngine.ToOutside)
@@ -295,75 +1036,203 @@ func (b *R9NanoGPUBuilder) connectCP() {
 	b.connectCPWithCaches()
 }
 
+// createL1ToL2Connection returns the connection between the L1 and the L2
+// caches, together with the function that plugs in the top port of an L2
+// cache.
+func (b *R9NanoGPUBuilder) createL1ToL2Connection(
+	lowModuleFinder mem.AddressToPortMapper,
+) (sim.Connection, func(port sim.Port)) {
+	if b.memRequestInterceptor != nil {
+		conn := newInterceptingConnection(b.gpuName+".L1ToL2",
+			b.engine, b.freq, b.memRequestInterceptor, lowModuleFinder)
+
+		return conn, conn.plugInL2
+	}
+
+	conn := directconnection.MakeBuilder().
+		WithEngine(b.engine).
+		WithFreq(b.freq).
+		Build(b.gpuName + ".L1ToL2")
+
+	return conn, conn.PlugIn
+}
+
 func (b *R9NanoGPUBuilder) connectL1ToL2() {
 	lowModuleFinder := mem.NewInterleavedAddressPortMapper(
 		1 << b.log2MemoryBankInterleavingSize)
 	lowModuleFinder.ModuleForOtherAddresses = b.rdmaEngine.ToL1.AsRemote()
-	lowModuleFinder.UseAddressSpaceLimitation = true
+	lowModuleFinder.UseAddressSpaceLimitation = b.sharedDRAMPool == nil
 	lowModuleFinder.LowAddress = b.memAddrOffset
 	lowModuleFinder.HighAddress = b.memAddrOffset + 4*mem.GB
 
-	l1ToL2Conn := directconnection.MakeBuilder().
-		WithEngine(b.engine).
-		WithFreq(b.freq).
-		Build(b.gpuName + ".L1ToL2")
+	var l2Mapper mem.AddressToPortMapper = lowModuleFinder
+	if b.l2BankHashMapping {
+		if b.numMemoryBank&(b.numMemoryBank-1) != 0 {
+			panic("L2 bank hash mapping requires a power-of-two number " +
+				"of memory banks")
+		}
 
-	b.rdmaEngine.SetLocalModuleFinder(lowModuleFinder)
+		l2Mapper = xorHashAddressPortMapper{lowModuleFinder}
+	}
+
+	l1ToL2Conn, plugInL2 := b.createL1ToL2Connection(l2Mapper)
+
+	b.rdmaEngine.SetLocalModuleFinder(l2Mapper)
 	l1ToL2Conn.PlugIn(b.rdmaEngine.ToL1)
 	l1ToL2Conn.PlugIn(b.rdmaEngine.ToL2)
 
+	b.dmaEngine.SetL2DataSource(l2Mapper)
+	l1ToL2Conn.PlugIn(b.dmaEngine.ToL2)
+
 	for _, l2 := range b.l2Caches {
 		lowModuleFinder.LowModules = append(lowModuleFinder.LowModules,
 			l2.GetPortByName("Top").AsRemote())
-		l1ToL2Conn.PlugIn(l2.GetPortByName("Top"))
+		plugInL2(l2.GetPortByName("Top"))
+	}
+
+	if len(b.l1vVictimCaches) > 0 {
+		for _, victimCache := range b.l1vVictimCaches {
+			victimCache.SetAddressToPortMapper(l2Mapper)
+			l1ToL2Conn.PlugIn(victimCache.GetPortByName("Bottom"))
+		}
+	} else {
+		for _, l1v := range b.l1vCaches {
+			l1v.SetAddressToPortMapper(l2Mapper)
+			l1ToL2Conn.PlugIn(l1v.GetPortByName("Bottom"))
+		}
 	}
 
-	for _, l1v := range b.l1vCaches {
-		l1v.SetAddressToPortMapper(lowModuleFinder)
-		l1ToL2Conn.PlugIn(l1v.GetPortByName("Bottom"))
+	for _, c := range b.constantCaches {
+		c.SetAddressToPortMapper(l2Mapper)
+		l1ToL2Conn.PlugIn(c.GetPortByName("Bottom"))
 	}
 
 	for _, l1s := range b.l1sCaches {
-		l1s.SetAddressToPortMapper(lowModuleFinder)
+		l1s.SetAddressToPortMapper(l2Mapper)
 		l1ToL2Conn.PlugIn(l1s.GetPortByName("Bottom"))
 	}
 
+	for _, l1 := range b.l1Caches {
+		l1.SetAddressToPortMapper(l2Mapper)
+		l1ToL2Conn.PlugIn(l1.GetPortByName("Bottom"))
+	}
+
+	// With a unified L1, the instruction fetches go from the address
+	// translators to the L1 cache instead.
+	if b.unifiedL1 {
+		return
+	}
+
 	for _, l1iAT := range b.l1iAddrTrans {
-		l1iAT.SetAddressToPortMapper(lowModuleFinder)
+		l1iAT.SetAddressToPortMapper(l2Mapper)
 		l1ToL2Conn.PlugIn(l1iAT.GetPortByName("Bottom"))
 	}
 }
 
 func (b *R9NanoGPUBuilder) connectL2AndDRAM() {
-	b.l2ToDramConnection = directconnection.MakeBuilder().
-		WithEngine(b.engine).
-		WithFreq(b.freq).
-		Build(b.gpuName + ".L2ToDRAM")
+	if b.interposerLatency > 0 &&
+		(b.sharedLLC != nil || b.sharedDRAMPool != nil) {
+		panic("the interposer latency cannot be used with a shared LLC " +
+			"or a shared DRAM pool")
+	}
+
+	switch {
+	case b.sharedLLC != nil:
+		b.l2ToDramConnection = b.sharedLLC.conn
+	case b.sharedDRAMPool != nil:
+		b.l2ToDramConnection = b.sharedDRAMPool.conn
+	case b.interposerLatency > 0:
+		b.l2ToDramConnection = newLatencyConnection(
+			b.gpuName+".L2ToDRAM", b.engine, b.freq,
+			b.scaleLatency(b.interposerLatency))
+	default:
+		b.l2ToDramConnection = directconnection.MakeBuilder().
+			WithEngine(b.engine).
+			WithFreq(b.freq).
+			Build(b.gpuName + ".L2ToDRAM")
+	}
 
 	lowModuleFinder := mem.NewInterleavedAddressPortMapper(
 		1 << b.log2MemoryBankInterleavingSize)
 
 	for i, l2 := range b.l2Caches {
 		b.l2ToDramConnection.PlugIn(l2.GetPortByName("Bottom"))
-		l2.SetAddressToPortMapper(&mem.SinglePortMapper{
-			Port: b.drams[i].GetPortByName("Top").AsRemote(),
-		})
+		l2.SetAddressToPortMapper(b.l2LowModuleMapper(i))
 	}
 
-	for _, dram := range b.drams {
-		b.l2ToDramConnection.PlugIn(dram.GetPortByName("Top"))
+	for i, dram := range b.drams {
+		if b.sharedDRAMPool == nil {
+			b.l2ToDramConnection.PlugIn(dram.GetPortByName("Top"))
+		}
+
 		lowModuleFinder.LowModules = append(lowModuleFinder.LowModules,
-			dram.GetPortByName("Top").AsRemote())
+			b.lowModuleOfL2(i))
+	}
+
+	var memCtrlFinder mem.AddressToPortMapper = lowModuleFinder
+	if b.isDRAMTiered() {
+		memCtrlFinder = b.connectSlowTierDRAMs(lowModuleFinder)
 	}
 
-	b.dmaEngine.SetLocalDataSource(lowModuleFinder)
+	b.dmaEngine.SetLocalDataSource(memCtrlFinder)
 	b.l2ToDramConnection.PlugIn(b.dmaEngine.ToMem)
 
-	b.pageMigrationController.MemCtrlFinder = lowModuleFinder
+	b.pageMigrationController.MemCtrlFinder = memCtrlFinder
 	b.l2ToDramConnection.PlugIn(
 		b.pageMigrationController.GetPortByName("LocalMem"))
 }
 
+// l2LowModuleMapper returns the mapper that finds the port that serves a miss
+// of the i-th L2 cache. With a tiered DRAM, the port depends on the tier of the
+// address.
+func (b *R9NanoGPUBuilder) l2LowModuleMapper(i int) mem.AddressToPortMapper {
+	fast := &mem.SinglePortMapper{Port: b.lowModuleOfL2(i)}
+	if !b.isDRAMTiered() {
+		return fast
+	}
+
+	return tieredAddressPortMapper{
+		boundary: b.dramTierBoundary(),
+		fast:     fast,
+		slow: &mem.SinglePortMapper{
+			Port: b.slowTierDRAMs[i].GetPortByName("Top").AsRemote(),
+		},
+	}
+}
+
+// connectSlowTierDRAMs plugs the memory controllers of the slow DRAM tier into
+// the connection. It returns the mapper that finds the memory controller of
+// an address in either tier.
+func (b *R9NanoGPUBuilder) connectSlowTierDRAMs(
+	fastTierFinder *mem.InterleavedAddressPortMapper,
+) mem.AddressToPortMapper {
+	slowTierFinder := mem.NewInterleavedAddressPortMapper(
+		1 << b.log2MemoryBankInterleavingSize)
+
+	for _, dram := range b.slowTierDRAMs {
+		b.l2ToDramConnection.PlugIn(dram.GetPortByName("Top"))
+		slowTierFinder.LowModules = append(slowTierFinder.LowModules,
+			dram.GetPortByName("Top").AsRemote())
+	}
+
+	return tieredAddressPortMapper{
+		boundary: b.dramTierBoundary(),
+		fast:     fastTierFinder,
+		slow:     slowTierFinder,
+	}
+}
+
+// lowModuleOfL2 returns the port that serves the misses of the i-th L2 cache.
+// It is the port of the LLC bank if a shared LLC is used, or the port of the
+// memory controller otherwise.
+func (b *R9NanoGPUBuilder) lowModuleOfL2(i int) sim.RemotePort {
+	if b.sharedLLC != nil {
+		return b.sharedLLC.Banks[i].GetPortByName("Top").AsRemote()
+	}
+
+	return b.drams[i].GetPortByName("Top").AsRemote()
+}
+
 func (b *R9NanoGPUBuilder) connectL1TLBToL2TLB() {
 	tlbConn := directconnection.MakeBuilder().
 		WithEngine(b.engine).
@@ -476,12 +1345,44 @@ func (b *R9NanoGPUBuilder) connectCPWithCaches() {
 		b.internalConn.PlugIn(ctrlPort)
 	}
 
+	for _, c := range b.l1vVictimCaches {
+		ctrlPort := c.GetPortByName("Control")
+		b.cp.L1VCaches = append(b.cp.L1VCaches, ctrlPort)
+		b.internalConn.PlugIn(ctrlPort)
+	}
+
+	for _, c := range b.l0Caches {
+		ctrlPort := c.GetPortByName("Control")
+		b.cp.L1VCaches = append(b.cp.L1VCaches, ctrlPort)
+		b.internalConn.PlugIn(ctrlPort)
+	}
+
+	for _, c := range b.constantCaches {
+		ctrlPort := c.GetPortByName("Control")
+		b.cp.L1VCaches = append(b.cp.L1VCaches, ctrlPort)
+		b.internalConn.PlugIn(ctrlPort)
+	}
+
+	for _, c := range b.l1vWCBs {
+		ctrlPort := c.GetPortByName("Control")
+		b.cp.L1VCaches = append(b.cp.L1VCaches, ctrlPort)
+		b.internalConn.PlugIn(ctrlPort)
+	}
+
 	for _, c := range b.l1sCaches {
 		ctrlPort := c.GetPortByName("Control")
 		b.cp.L1SCaches = append(b.cp.L1SCaches, ctrlPort)
 		b.internalConn.PlugIn(ctrlPort)
 	}
 
+	// The command processor flushes and invalidates a unified L1 cache in the
+	// same way as an L1 vector cache.
+	for _, c := range b.l1Caches {
+		ctrlPort := c.GetPortByName("Control")
+		b.cp.L1VCaches = append(b.cp.L1VCaches, ctrlPort)
+		b.internalConn.PlugIn(ctrlPort)
+	}
+
 	for _, c := range b.l2Caches {
 		ctrlPort := c.GetPortByName("Control")
 		b.cp.L2Caches = append(b.cp.L2Caches, ctrlPort)
@@ -496,12 +1397,53 @@ func (b *R9NanoGPUBuilder) buildSAs() {
 		withGPUID(b.gpuID).
 		withLog2CachelineSize(b.log2CacheLineSize).
 		withLog2PageSize(b.log2PageSize).
-		withNumCU(b.numCUPerShaderArray)
+		withLog2HugePageSize(b.log2HugePageSize).
+		withNumCU(b.numCUPerShaderArray).
+		withNumSIMDPerCU(b.numSIMDPerCU).
+		withWavefrontSchedulingPolicy(b.wfSchedulingPolicy).
+		withBankedRegisterFile(b.numVGPRBanks, b.numVGPRBankPorts).
+		withWavefrontSize(b.wavefrontSize).
+		withWavefrontSlotsPerSIMD(b.wfSlotsPerSIMD).
+		withSFULatencyTable(b.sfuLatencyTable).
+		withBarrierLatency(b.barrierLatency).
+		withL1IMissLatency(b.l1iMissLatency).
+		withScalarMSHREntries(b.scalarMSHREntries).
+		withLDSSizePerCU(b.ldsSizePerCU).
+		withL1VVictimCache(b.l1vVictimCacheSize).
+		withL0Cache(b.l0CacheSize).
+		withWriteCombining(b.wcbDepth).
+		withTLBShootdownLatency(b.scaleLatency(b.tlbShootdownLatency)).
+		withL1TLBGeometry(b.l1TLBNumSets, b.l1TLBNumWays).
+		withL1VNumPorts(b.l1vNumPorts).
+		withTimingScale(b.timingScale)
+
+	if b.unifiedL1 {
+		b.mustBeCompatibleWithUnifiedL1()
+		saBuilder = saBuilder.withUnifiedL1()
+	}
 
 	if b.enableISADebugging {
 		saBuilder = saBuilder.withIsaDebugging()
 	}
 
+	if b.validationPageTable != nil {
+		saBuilder = saBuilder.withTranslationValidation(b.validationPageTable)
+	}
+
+	if b.faultPageTable != nil {
+		saBuilder = saBuilder.withFaultDetection(b.faultPageTable)
+	}
+
+	if b.idealTLBPageTable != nil {
+		saBuilder = saBuilder.withIdealTLB(b.idealTLBPageTable)
+	}
+
+	if b.constantCacheSize > 0 {
+		router := &constantMemoryRouter{}
+		b.gpu.ConstantMemoryRouter = router
+		saBuilder = saBuilder.withConstantCache(b.constantCacheSize, router)
+	}
+
 	if b.enableVisTracing {
 		saBuilder = saBuilder.withVisTracer(b.visTracer)
 	}
@@ -512,10 +1454,27 @@ func (b *R9NanoGPUBuilder) buildSAs() {
 
 	for i := 0; i < b.numShaderArray; i++ {
 		saName := fmt.Sprintf("%s.SA[%d]", b.gpuName, i)
-		b.buildSA(saBuilder, saName)
+		b.buildSA(b.isaDebugWavefrontOfSA(saBuilder, i), saName)
 	}
 }
 
+// isaDebugWavefrontOfSA lets the shader array dump the instructions of the
+// target wavefront if the target CU is in the shader array.
+func (b *R9NanoGPUBuilder) isaDebugWavefrontOfSA(
+	saBuilder shaderArrayBuilder,
+	saIndex int,
+) shaderArrayBuilder {
+	if b.isaDebugWf == nil ||
+		b.isaDebugWf.cuIndex/b.numCUPerShaderArray != saIndex {
+		return saBuilder
+	}
+
+	target := *b.isaDebugWf
+	target.cuIndex %= b.numCUPerShaderArray
+
+	return saBuilder.withIsaDebuggingForWavefront(target)
+}
+
 func (b *R9NanoGPUBuilder) buildL2Caches() {
 	byteSize := b.l2CacheSize / uint64(b.numMemoryBank)
 	l2Builder := writeback.MakeBuilder().
@@ -525,15 +1484,39 @@ func (b *R9NanoGPUBuilder) buildL2Caches() {
 		WithWayAssociativity(16).
 		WithByteSize(byteSize).
 		WithNumMSHREntry(64).
-		WithNumReqPerCycle(16)
+		WithNumReqPerCycle(16).
+		WithBankLatency(b.scaleLatency(10)).
+		WithWriteBufferSize(b.l2WriteBufferSize)
+
+	if b.l1vCoherence {
+		if b.l1vVictimCacheSize > 0 {
+			panic("L1V coherence cannot be used with the L1V victim caches")
+		}
+
+		if b.l0CacheSize > 0 {
+			panic("L1V coherence cannot be used with the L0 caches")
+		}
+
+		var sharers []sim.RemotePort
+		for _, l1v := range b.l1vCaches {
+			sharers = append(sharers, l1v.GetPortByName("Bottom").AsRemote())
+		}
+
+		l2Builder = l2Builder.WithCoherenceDirectory(sharers)
+	}
 
 	for i := 0; i < b.numMemoryBank; i++ {
 		cacheName := fmt.Sprintf("%s.L2[%d]", b.gpuName, i)
-		l2 := l2Builder.WithInterleaving(
-			1<<(b.log2MemoryBankInterleavingSize-b.log2CacheLineSize),
-			b.numMemoryBank,
-			i,
-		).Build(cacheName)
+		bankBuilder := l2Builder.WithVictimFinder(
+			replacement.NewVictimFinder(b.l2ReplacementPolicy))
+		if !b.l2BankHashMapping {
+			bankBuilder = bankBuilder.WithInterleaving(
+				1<<(b.log2MemoryBankInterleavingSize-b.log2CacheLineSize),
+				b.numMemoryBank,
+				i,
+			)
+		}
+		l2 := bankBuilder.Build(cacheName)
 		b.l2Caches = append(b.l2Caches, l2)
 		b.gpu.L2Caches = append(b.gpu.L2Caches, l2)
 
@@ -549,10 +1532,29 @@ func (b *R9NanoGPUBuilder) buildL2Caches() {
 			b.monitor.RegisterComponent(l2)
 		}
 	}
+
+	b.gpu.L2Partitioner = l2Partitioner(b.l2Caches)
 }
 
 func (b *R9NanoGPUBuilder) buildDRAMControllers() {
-	memCtrlBuilder := b.createDramControllerBuilder()
+	if b.sharedDRAMPool != nil {
+		if b.isDRAMTiered() {
+			panic("a GPU with a shared DRAM pool cannot have a tiered DRAM")
+		}
+
+		b.drams = b.sharedDRAMPool.Controllers
+		for _, dram := range b.drams {
+			b.gpu.MemControllers = append(b.gpu.MemControllers, dram)
+		}
+
+		b.gpu.DRAMRowBufferStatsReporter = dramRowBufferStatsReporter(b.drams)
+		b.gpu.MemQoSController = dramQoSController(b.drams)
+
+		return
+	}
+
+	memCtrlBuilder := b.withDRAMFaultInjector(
+		b.createDramControllerBuilder(4 * mem.GB))
 
 	for i := 0; i < b.numMemoryBank; i++ {
 		dramName := fmt.Sprintf("%s.DRAM[%d]", b.gpuName, i)
@@ -562,21 +1564,71 @@ func (b *R9NanoGPUBuilder) buildDRAMControllers() {
 		// 	fmt.Sprintf("%s.DRAM_%d", b.gpuName, i),
 		// 	b.engine, 512*mem.MB)
 		b.drams = append(b.drams, dram)
-		b.gpu.MemControllers = append(b.gpu.MemControllers, dram)
+		b.registerDRAMController(dram)
+	}
 
-		if b.enableMemTracing {
-			tracing.CollectTrace(dram, b.memTracer)
-		}
+	allDRAMs := b.drams
+	if b.isDRAMTiered() {
+		b.buildSlowTierDRAMControllers()
+		allDRAMs = append(allDRAMs, b.slowTierDRAMs...)
+	}
 
-		if b.monitor != nil {
-			b.monitor.RegisterComponent(dram)
-		}
+	b.gpu.DRAMRowBufferStatsReporter = dramRowBufferStatsReporter(allDRAMs)
+	b.gpu.MemQoSController = dramQoSController(allDRAMs)
+}
+
+func (b *R9NanoGPUBuilder) buildSlowTierDRAMControllers() {
+	if b.dramFastTierSize >= b.dramSize {
+		panic("the fast DRAM tier must be smaller than the DRAM")
+	}
+
+	memCtrlBuilder := b.withDRAMFaultInjector(
+		b.createSlowTierDRAMControllerBuilder(4 * mem.GB))
+
+	for i := 0; i < b.numMemoryBank; i++ {
+		dramName := fmt.Sprintf("%s.SlowDRAM[%d]", b.gpuName, i)
+		dram := memCtrlBuilder.Build(dramName)
+		b.slowTierDRAMs = append(b.slowTierDRAMs, dram)
+		b.registerDRAMController(dram)
+	}
+}
+
+// withDRAMFaultInjector lets the DRAM controllers report the uncorrectable
+// faults to the command processor. The command processor is built after the
+// DRAM controllers, so it is looked up when a fault is reported.
+func (b *R9NanoGPUBuilder) withDRAMFaultInjector(
+	memCtrlBuilder dram.Builder,
+) dram.Builder {
+	if b.dramFaultInjector == nil {
+		return memCtrlBuilder
 	}
+
+	gpu := b.gpu
+
+	return memCtrlBuilder.WithUncorrectableFaultInjector(
+		b.dramFaultInjector,
+		func(fault error) {
+			gpu.CommandProcessor.ReportFault(fault)
+		})
 }
 
-func (b *R9NanoGPUBuilder) createDramControllerBuilder() dram.Builder {
-	memBankSize := 4 * mem.GB / uint64(b.numMemoryBank)
-	if 4*mem.GB%uint64(b.numMemoryBank) != 0 {
+func (b *R9NanoGPUBuilder) registerDRAMController(dram *dram.Comp) {
+	b.gpu.MemControllers = append(b.gpu.MemControllers, dram)
+
+	if b.enableMemTracing {
+		tracing.CollectTrace(dram, b.memTracer)
+	}
+
+	if b.monitor != nil {
+		b.monitor.RegisterComponent(dram)
+	}
+}
+
+func (b *R9NanoGPUBuilder) createDramControllerBuilder(
+	capacity uint64,
+) dram.Builder {
+	memBankSize := capacity / uint64(b.numMemoryBank)
+	if capacity%uint64(b.numMemoryBank) != 0 {
 		panic("GPU memory size is not a multiple of the number of memory banks")
 	}
 
@@ -587,9 +1639,19 @@ func (b *R9NanoGPUBuilder) createDramControllerBuilder() dram.Builder {
 	dramBank := 4
 	dramBankGroup := 4
 	dramBusWidth := 256
-	dramDevicePerRank := dramBusWidth / dramDeviceWidth
+	dramDevicePerRank := dramBusWidth / b.dramSubChannels / dramDeviceWidth
 	dramRankSize := dramBankSize * dramDevicePerRank * dramBank
-	dramRank := int(memBankSize * 8 / uint64(dramRankSize))
+	dramRank := int(memBankSize * 8 /
+		(uint64(dramRankSize) * uint64(b.dramChannelsPerBank) *
+			uint64(b.dramSubChannels)))
+	if dramRank < 1 {
+		panic("too many DRAM channels for the memory bank size")
+	}
+
+	tCL := b.scaleLatency(7)
+	if b.eccEnabled {
+		tCL += b.scaleLatency(b.eccLatencyPenalty)
+	}
 
 	memCtrlBuilder := dram.MakeBuilder().
 		WithEngine(b.engine).
@@ -598,7 +1660,9 @@ func (b *R9NanoGPUBuilder) createDramControllerBuilder() dram.Builder {
 		WithBurstLength(4).
 		WithDeviceWidth(dramDeviceWidth).
 		WithBusWidth(dramBusWidth).
-		WithNumChannel(1).
+		WithNumChannel(b.dramChannelsPerBank).
+		WithNumSubChannel(b.dramSubChannels).
+		WithSchedulingPolicy(b.dramScheduler).
 		WithNumRank(dramRank).
 		WithNumBankGroup(dramBankGroup).
 		WithNumBank(dramBank).
@@ -606,23 +1670,25 @@ func (b *R9NanoGPUBuilder) createDramControllerBuilder() dram.Builder {
 		WithNumRow(dramRow).
 		WithCommandQueueSize(8).
 		WithTransactionQueueSize(32).
-		WithTCL(7).
-		WithTCWL(2).
-		WithTRCDRD(7).
-		WithTRCDWR(7).
-		WithTRP(7).
-		WithTRAS(17).
-		WithTREFI(1950).
-		WithTRRDS(2).
-		WithTRRDL(3).
-		WithTWTRS(3).
-		WithTWTRL(4).
-		WithTWR(8).
-		WithTCCDS(1).
-		WithTCCDL(1).
-		WithTRTRS(0).
-		WithTRTP(3).
-		WithTPPD(2)
+		WithReadQueueSize(b.dramReadQueueSize).
+		WithWriteQueueSize(b.dramWriteQueueSize).
+		WithTCL(tCL).
+		WithTCWL(b.scaleLatency(2)).
+		WithTRCDRD(b.scaleLatency(7)).
+		WithTRCDWR(b.scaleLatency(7)).
+		WithTRP(b.scaleLatency(7)).
+		WithTRAS(b.scaleLatency(17)).
+		WithTREFI(b.dramRefreshInterval).
+		WithTRRDS(b.scaleLatency(2)).
+		WithTRRDL(b.scaleLatency(3)).
+		WithTWTRS(b.scaleLatency(3)).
+		WithTWTRL(b.scaleLatency(4)).
+		WithTWR(b.scaleLatency(8)).
+		WithTCCDS(b.scaleLatency(1)).
+		WithTCCDL(b.scaleLatency(1)).
+		WithTRTRS(b.scaleLatency(0)).
+		WithTRTP(b.scaleLatency(3)).
+		WithTPPD(b.scaleLatency(2))
 
 	if b.visTracer != nil {
 		memCtrlBuilder = memCtrlBuilder.WithAdditionalTracer(b.visTracer)
@@ -632,9 +1698,40 @@ func (b *R9NanoGPUBuilder) createDramControllerBuilder() dram.Builder {
 		memCtrlBuilder = memCtrlBuilder.WithGlobalStorage(b.globalStorage)
 	}
 
+	if b.dramOpenPage {
+		memCtrlBuilder = memCtrlBuilder.WithOpenPagePolicy()
+	}
+
+	if b.dramQoSScheduling {
+		memCtrlBuilder = memCtrlBuilder.WithQoSScheduling()
+	}
+
+	if b.eccEnabled {
+		memCtrlBuilder = memCtrlBuilder.
+			WithECCBandwidthOverhead(b.eccBandwidthOverhead)
+	}
+
 	return memCtrlBuilder
 }
 
+func (b *R9NanoGPUBuilder) mustBeCompatibleWithUnifiedL1() {
+	if b.l1vVictimCacheSize > 0 {
+		panic("the unified L1 cannot be used with the L1V victim caches")
+	}
+
+	if b.l1vCoherence {
+		panic("the unified L1 cannot be used with the L1V coherence")
+	}
+
+	if b.l1iMissLatency > 0 {
+		panic("the unified L1 cannot be used with an L1I miss latency")
+	}
+
+	if b.constantCacheSize > 0 {
+		panic("the unified L1 cannot be used with the constant caches")
+	}
+}
+
 func (b *R9NanoGPUBuilder) buildSA(
 	saBuilder shaderArrayBuilder,
 	saName string,
@@ -648,6 +1745,7 @@ func (b *R9NanoGPUBuilder) buildSA(
 	b.populateL1Vs(&sa)
 	b.populateScalerMemoryHierarchy(&sa)
 	b.populateInstMemoryHierarchy(&sa)
+	b.populateUnifiedL1(&sa)
 }
 
 func (b *R9NanoGPUBuilder) populateCUs(sa *shaderArray) {
@@ -663,11 +1761,25 @@ func (b *R9NanoGPUBuilder) populateCUs(sa *shaderArray) {
 			b.perfAnalyzer.RegisterComponent(cu)
 		}
 	}
-	for _, cu := range sa.cus {
-		for _, simd := range cu.SIMDUnit {
+	reporter, _ := b.gpu.SIMDUtilizationReporter.(simdUtilizationReporter)
+	for _, computeUnit := range sa.cus {
+		for _, simd := range computeUnit.SIMDUnit {
 			b.gpu.SIMDs = append(b.gpu.SIMDs, simd.(TraceableComponent))
+			reporter = append(reporter, simd.(*cu.SIMDUnit))
 		}
 	}
+	b.gpu.SIMDUtilizationReporter = reporter
+
+	coalescing, _ := b.gpu.CoalescingStatsReporter.(coalescingStatsReporter)
+	for _, computeUnit := range sa.cus {
+		coalescing = append(coalescing,
+			computeUnit.VectorMemUnit.(*cu.VectorMemoryUnit))
+	}
+	b.gpu.CoalescingStatsReporter = coalescing
+
+	stalls, _ := b.gpu.StallBreakdownReporter.(stallBreakdownReporter)
+	stalls = append(stalls, sa.cus...)
+	b.gpu.StallBreakdownReporter = stalls
 }
 
 func (b *R9NanoGPUBuilder) populateROBs(sa *shaderArray) {
@@ -708,6 +1820,40 @@ func (b *R9NanoGPUBuilder) populateL1Vs(sa *shaderArray) {
 			b.monitor.RegisterComponent(l1v)
 		}
 	}
+
+	for _, victimCache := range sa.l1vVictimCaches {
+		b.l1vVictimCaches = append(b.l1vVictimCaches, victimCache)
+
+		if b.monitor != nil {
+			b.monitor.RegisterComponent(victimCache)
+		}
+	}
+
+	for _, l0 := range sa.l0Caches {
+		b.l0Caches = append(b.l0Caches, l0)
+		b.gpu.L0Caches = append(b.gpu.L0Caches, l0)
+
+		if b.monitor != nil {
+			b.monitor.RegisterComponent(l0)
+		}
+	}
+
+	for _, c := range sa.constantCaches {
+		b.constantCaches = append(b.constantCaches, c)
+		b.gpu.ConstantCaches = append(b.gpu.ConstantCaches, c)
+
+		if b.monitor != nil {
+			b.monitor.RegisterComponent(c)
+		}
+	}
+
+	for _, wcb := range sa.l1vWCBs {
+		b.l1vWCBs = append(b.l1vWCBs, wcb)
+
+		if b.monitor != nil {
+			b.monitor.RegisterComponent(wcb)
+		}
+	}
 }
 
 func (b *R9NanoGPUBuilder) populateL1VAddressTranslators(sa *shaderArray) {
@@ -723,40 +1869,69 @@ func (b *R9NanoGPUBuilder) populateL1VAddressTranslators(sa *shaderArray) {
 func (b *R9NanoGPUBuilder) populateScalerMemoryHierarchy(sa *shaderArray) {
 	b.l1sAddrTrans = append(b.l1sAddrTrans, sa.l1sAT)
 	b.l1sReorderBuffers = append(b.l1sReorderBuffers, sa.l1sROB)
-	b.l1sCaches = append(b.l1sCaches, sa.l1sCache)
-	b.gpu.L1SCaches = append(b.gpu.L1SCaches, sa.l1sCache)
 	b.l1sTLBs = append(b.l1sTLBs, sa.l1sTLB)
 	b.gpu.L1STLBs = append(b.gpu.L1STLBs, sa.l1sTLB)
 
 	if b.monitor != nil {
 		b.monitor.RegisterComponent(sa.l1sAT)
 		b.monitor.RegisterComponent(sa.l1sROB)
-		b.monitor.RegisterComponent(sa.l1sCache)
 		b.monitor.RegisterComponent(sa.l1sTLB)
 	}
+
+	if sa.l1sCache == nil {
+		return
+	}
+
+	b.l1sCaches = append(b.l1sCaches, sa.l1sCache)
+	b.gpu.L1SCaches = append(b.gpu.L1SCaches, sa.l1sCache)
+
+	if b.monitor != nil {
+		b.monitor.RegisterComponent(sa.l1sCache)
+	}
 }
 
 func (b *R9NanoGPUBuilder) populateInstMemoryHierarchy(sa *shaderArray) {
 	b.l1iAddrTrans = append(b.l1iAddrTrans, sa.l1iAT)
 	b.l1iReorderBuffers = append(b.l1iReorderBuffers, sa.l1iROB)
-	b.l1iCaches = append(b.l1iCaches, sa.l1iCache)
-	b.gpu.L1ICaches = append(b.gpu.L1ICaches, sa.l1iCache)
 	b.l1iTLBs = append(b.l1iTLBs, sa.l1iTLB)
 	b.gpu.L1ITLBs = append(b.gpu.L1ITLBs, sa.l1iTLB)
 
 	if b.monitor != nil {
 		b.monitor.RegisterComponent(sa.l1iAT)
 		b.monitor.RegisterComponent(sa.l1iROB)
-		b.monitor.RegisterComponent(sa.l1iCache)
 		b.monitor.RegisterComponent(sa.l1iTLB)
 	}
+
+	if sa.l1iCache == nil {
+		return
+	}
+
+	b.l1iCaches = append(b.l1iCaches, sa.l1iCache)
+	b.gpu.L1ICaches = append(b.gpu.L1ICaches, sa.l1iCache)
+
+	if b.monitor != nil {
+		b.monitor.RegisterComponent(sa.l1iCache)
+	}
+}
+
+func (b *R9NanoGPUBuilder) populateUnifiedL1(sa *shaderArray) {
+	if sa.l1Cache == nil {
+		return
+	}
+
+	b.l1Caches = append(b.l1Caches, sa.l1Cache)
+	b.gpu.L1Caches = append(b.gpu.L1Caches, sa.l1Cache)
+
+	if b.monitor != nil {
+		b.monitor.RegisterComponent(sa.l1Cache)
+	}
 }
 
 func (b *R9NanoGPUBuilder) buildRDMAEngine() {
 	name := fmt.Sprintf("%s.RDMA", b.gpuName)
 	b.rdmaEngine = rdma.MakeBuilder().
 		WithEngine(b.engine).
-		WithFreq(1 * sim.GHz).
+		WithFreq(b.freq).
 		WithLocalModules(b.lowModuleFinderForL1).
 		Build(name)
 	b.gpu.RDMAEngine = b.rdmaEngine
@@ -777,6 +1952,7 @@ func (b *R9NanoGPUBuilder) buildPageMigrationController() {
 			b.engine,
 			b.lowModuleFinderForPMC,
 			nil)
+	b.pageMigrationController.SetFreq(b.freq)
 	b.gpu.PMC = b.pageMigrationController
 
 	if b.monitor != nil {
@@ -789,6 +1965,10 @@ func (b *R9NanoGPUBuilder) buildDMAEngine() {
 		fmt.Sprintf("%s.DMA", b.gpuName),
 		b.engine,
 		nil)
+	b.dmaEngine.SetFreq(b.freq)
+	b.dmaEngine.Log2AccessSize = b.log2CacheLineSize
+	b.dmaEngine.SetMaxOutstanding(b.dmaMaxOutstanding)
+	b.gpu.DMAEngine = b.dmaEngine
 
 	if b.enableVisTracing {
 		tracing.CollectTrace(b.dmaEngine, b.visTracer)
@@ -804,7 +1984,14 @@ func (b *R9NanoGPUBuilder) buildCP() {
 		WithEngine(b.engine).
 		WithFreq(b.freq).
 		WithMonitor(b.monitor).
-		WithPerfAnalyzer(b.perfAnalyzer)
+		WithPerfAnalyzer(b.perfAnalyzer).
+		WithWavefrontSize(b.wavefrontSize).
+		WithKernelLaunchOverhead(b.kernelLaunchOverhead).
+		WithWGSchedulingPolicy(b.wgSchedulingPolicy)
+
+	if b.wgDispatchRate > 0 {
+		builder = builder.WithWGDispatchRate(b.wgDispatchRate)
+	}
 
 	if b.enableVisTracing {
 		builder = builder.WithVisTracer(b.visTracer)
@@ -828,12 +2015,19 @@ func (b *R9NanoGPUBuilder) buildL2TLB() {
 		WithEngine(b.engine).
 		WithFreq(b.freq).
 		WithNumWays(numWays).
-		WithNumSets(int(b.dramSize / (1 << b.log2PageSize) / uint64(numWays))).
+		WithNumSets(b.l2TLBNumSets(numWays)).
 		WithNumMSHREntry(64).
 		WithNumReqPerCycle(1024).
 		WithPageSize(1 << b.log2PageSize).
+		WithHugePageSize(b.hugePageSize()).
+		WithLatency(b.scaleLatency(4)).
+		WithShootdownLatency(b.scaleLatency(b.tlbShootdownLatency)).
 		WithLowModule(b.mmu.GetPortByName("Top").AsRemote())
 
+	if b.idealTLBPageTable != nil {
+		builder = builder.WithIdealTranslation(b.idealTLBPageTable)
+	}
+
 	l2TLB := builder.Build(fmt.Sprintf("%s.L2TLB", b.gpuName))
 	b.l2TLBs = append(b.l2TLBs, l2TLB)
 	b.gpu.L2TLBs = append(b.gpu.L2TLBs, l2TLB)
@@ -847,6 +2041,44 @@ func (b *R9NanoGPUBuilder) buildL2TLB() {
 	}
 }
 
+// l2TLBNumSets sizes the L2 TLB to map the whole DRAM. As the DRAM may be
+// mapped with a mix of base pages and huge pages, the TLB is sized for the
+// worst case where only base pages are used. A huge page takes a single entry,
+// so it never needs more entries than the base pages that it replaces.
+func (b *R9NanoGPUBuilder) l2TLBNumSets(numWays int) int {
+	numEntries := b.dramSize >> b.log2PageSize
+
+	numSets := int(numEntries / uint64(numWays))
+	if numSets < 1 {
+		numSets = 1
+	}
+
+	return numSets
+}
+
+func (b *R9NanoGPUBuilder) hugePageSize() uint64 {
+	if b.log2HugePageSize == 0 {
+		return 0
+	}
+
+	return 1 << b.log2HugePageSize
+}
+
+func (b *R9NanoGPUBuilder) scaleLatency(cycles int) int {
+	return scaleLatency(cycles, b.timingScale)
+}
+
+// scaleLatency multiplies a latency by a factor. A non-zero latency is never
+// scaled down to zero.
+func scaleLatency(cycles int, factor float64) int {
+	scaled := int(math.Round(float64(cycles) * factor))
+	if cycles > 0 && scaled < 1 {
+		return 1
+	}
+
+	return scaled
+}
+
 func (b *R9NanoGPUBuilder) numCU() int {
 	return b.numCUPerShaderArray * b.numShaderArray
 }
@@ -0,0 +1,266 @@
+package runner
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/sarchlab/akita/v4/mem/mem"
+	"github.com/sarchlab/akita/v4/mem/vm/mmu"
+	"github.com/sarchlab/akita/v4/sim"
+	"github.com/sarchlab/mgpusim/v4/amd/timing/cu"
+	"github.com/sarchlab/mgpusim/v4/amd/timing/tlb"
+)
+
+var _ = Describe("R9Nano GPU Builder", func() {
+	var builder R9NanoGPUBuilder
+
+	BeforeEach(func() {
+		engine := sim.NewSerialEngine()
+		builder = MakeR9NanoGPUBuilder().
+			WithEngine(engine).
+			WithMMU(mmu.MakeBuilder().WithEngine(engine).Build("MMU"))
+	})
+
+	It("should build the given number of SIMD units per CU", func() {
+		gpu := builder.WithNumSIMDPerCU(2).Build("GPU", 1)
+
+		Expect(gpu.SIMDs).To(HaveLen(len(gpu.CUs) * 2))
+	})
+
+	It("should panic if the number of SIMD units is not positive", func() {
+		Expect(func() { builder.WithNumSIMDPerCU(0) }).To(Panic())
+	})
+
+	It("should build CUs with the given LDS size", func() {
+		gpu := builder.WithLDSSizePerCU(32*mem.KB).Build("GPU", 1)
+
+		for _, computeUnit := range gpu.CUs {
+			Expect(computeUnit.(*cu.ComputeUnit).LDSBytes()).
+				To(Equal(32 * 1024))
+		}
+	})
+
+	It("should build CUs with the given wavefront slots", func() {
+		gpu := builder.WithWavefrontSlotsPerSIMD(4).Build("GPU", 1)
+
+		for _, computeUnit := range gpu.CUs {
+			Expect(computeUnit.(*cu.ComputeUnit).WfPoolSizes()).
+				To(Equal([]int{4, 4, 4, 4}))
+		}
+	})
+
+	It("should panic if the L1V coherence is used with the victim caches",
+		func() {
+			builder = builder.
+				WithL1VCoherence().
+				WithL1VVictimCache(4 * mem.KB)
+
+			Expect(func() { builder.Build("GPU", 1) }).To(Panic())
+		})
+
+	It("should build an L0 cache for each CU", func() {
+		Expect(builder.Build("GPU", 1).L0Caches).To(BeEmpty())
+
+		gpu := builder.WithL0Cache(1*mem.KB).Build("GPU", 2)
+
+		Expect(gpu.L0Caches).To(HaveLen(len(gpu.CUs)))
+	})
+
+	It("should panic if the L1V coherence is used with the L0 caches",
+		func() {
+			builder = builder.
+				WithL1VCoherence().
+				WithL0Cache(1 * mem.KB)
+
+			Expect(func() { builder.Build("GPU", 1) }).To(Panic())
+		})
+
+	It("should panic if the L2 write buffer size is not positive", func() {
+		Expect(func() { builder.WithL2WriteBufferSize(0) }).To(Panic())
+	})
+
+	It("should panic if the hashed L2 banks are not a power of two",
+		func() {
+			builder = builder.
+				WithNumMemoryBank(6).
+				WithL2BankHashMapping()
+
+			Expect(func() { builder.Build("GPU", 1) }).To(Panic())
+		})
+
+	It("should panic if the TLB shootdown latency is negative", func() {
+		Expect(func() { builder.WithTLBShootdownLatency(-1) }).To(Panic())
+	})
+
+	It("should build a memory controller of each tier for each bank",
+		func() {
+			numMemCtrls := len(builder.Build("GPU", 1).MemControllers)
+
+			gpu := builder.WithTieredDRAM(64*mem.MB).Build("GPU", 2)
+
+			Expect(gpu.MemControllers).To(HaveLen(2 * numMemCtrls))
+		})
+
+	It("should panic if the fast DRAM tier is empty", func() {
+		Expect(func() { builder.WithTieredDRAM(0) }).To(Panic())
+	})
+
+	It("should panic if the fast DRAM tier takes the whole DRAM", func() {
+		builder = builder.
+			WithDRAMSize(64 * mem.MB).
+			WithTieredDRAM(64 * mem.MB)
+
+		Expect(func() { builder.Build("GPU", 1) }).To(Panic())
+	})
+
+	It("should build L1 TLBs with the given number of sets", func() {
+		gpu := builder.
+			WithL1TLBNumSets(4).
+			WithL1TLBNumWays(16).
+			Build("GPU", 1)
+
+		for _, l1TLBs := range [][]TraceableComponent{
+			gpu.L1VTLBs, gpu.L1STLBs, gpu.L1ITLBs,
+		} {
+			for _, l1TLB := range l1TLBs {
+				Expect(l1TLB.(*tlb.Comp).Sets).To(HaveLen(4))
+			}
+		}
+	})
+
+	It("should panic if the L1 TLBs have no sets or no ways", func() {
+		Expect(func() { builder.WithL1TLBNumSets(0) }).To(Panic())
+		Expect(func() { builder.WithL1TLBNumWays(0) }).To(Panic())
+	})
+
+	It("should build a write-combining buffer for each L1V cache", func() {
+		gpu := builder.WithWriteCombining(16).Build("GPU", 1)
+
+		// The CP flushes the buffers together with the L1V caches.
+		Expect(gpu.CommandProcessor.L1VCaches).
+			To(HaveLen(2 * len(gpu.L1VCaches)))
+	})
+
+	It("should panic if the write-combining buffer depth is negative",
+		func() {
+			Expect(func() { builder.WithWriteCombining(-1) }).To(Panic())
+		})
+
+	It("should panic if the L1 vector caches have no port", func() {
+		Expect(func() { builder.WithL1VNumPorts(0) }).To(Panic())
+	})
+
+	It("should build a thermal sensor with the thermal model", func() {
+		gpu := builder.WithThermalModel(DefaultThermalModel()).Build("GPU", 1)
+
+		Expect(gpu.ThermalSensor.Temperature()).To(Equal(45.0))
+		Expect(gpu.EnergyReporter).NotTo(BeNil())
+	})
+
+	It("should panic if the throttled frequency scale is out of range",
+		func() {
+			model := DefaultThermalModel()
+			model.ThrottledFreqScale = 1.5
+
+			Expect(func() { builder.WithThermalModel(model) }).To(Panic())
+		})
+
+	It("should only dump the wavefront in the shader array of the CU",
+		func() {
+			builder = builder.WithISADebuggingForWavefront(6, 2, 1)
+
+			Expect(builder.isaDebugWavefrontOfSA(shaderArrayBuilder{}, 0).
+				isaDebugWf).To(BeNil())
+			Expect(builder.isaDebugWavefrontOfSA(shaderArrayBuilder{}, 1).
+				isaDebugWf).To(Equal(&isaDebugWavefront{
+				cuIndex: 2,
+				wgID:    2,
+				wfID:    1,
+			}))
+		})
+
+	It("should panic if the wavefront to debug is negative", func() {
+		Expect(func() { builder.WithISADebuggingForWavefront(0, 0, -1) }).
+			To(Panic())
+	})
+
+	It("should panic if the interposer latency is negative", func() {
+		Expect(func() { builder.WithInterposerLatency(-1) }).To(Panic())
+	})
+
+	It("should panic if the GPU crosses an interposer to a shared DRAM pool",
+		func() {
+			pool := builder.BuildSharedDRAMPool("Pool", 4*mem.GB)
+			builder = builder.
+				WithSharedDRAMPool(pool).
+				WithInterposerLatency(100)
+
+			Expect(func() { builder.Build("GPU", 1) }).To(Panic())
+		})
+
+	It("should panic if the L1I miss latency is negative", func() {
+		Expect(func() { builder.WithL1IMissLatency(-1) }).To(Panic())
+	})
+
+	It("should panic if the CUs have no scalar MSHR entry", func() {
+		Expect(func() { builder.WithScalarMSHREntries(0) }).To(Panic())
+	})
+
+	It("should build a unified L1 cache per shader array", func() {
+		gpu := builder.WithUnifiedL1().Build("GPU", 1)
+
+		Expect(gpu.L1Caches).To(HaveLen(16))
+		Expect(gpu.L1VCaches).To(BeEmpty())
+		Expect(gpu.L1SCaches).To(BeEmpty())
+		Expect(gpu.L1ICaches).To(BeEmpty())
+		Expect(gpu.CommandProcessor.L1VCaches).To(HaveLen(16))
+	})
+
+	DescribeTable("should panic if the unified L1 is used with",
+		func(configure func(R9NanoGPUBuilder) R9NanoGPUBuilder) {
+			builder = configure(builder.WithUnifiedL1())
+
+			Expect(func() { builder.Build("GPU", 1) }).To(Panic())
+		},
+		Entry("the L1V victim caches",
+			func(b R9NanoGPUBuilder) R9NanoGPUBuilder {
+				return b.WithL1VVictimCache(4 * mem.KB)
+			}),
+		Entry("the L1V coherence",
+			func(b R9NanoGPUBuilder) R9NanoGPUBuilder {
+				return b.WithL1VCoherence()
+			}),
+		Entry("an L1I miss latency",
+			func(b R9NanoGPUBuilder) R9NanoGPUBuilder {
+				return b.WithL1IMissLatency(100)
+			}),
+		Entry("the constant caches",
+			func(b R9NanoGPUBuilder) R9NanoGPUBuilder {
+				return b.WithConstantCache(4 * mem.KB)
+			}),
+	)
+
+	It("should build a constant cache for each CU", func() {
+		gpu := builder.WithConstantCache(4*mem.KB).Build("GPU", 1)
+
+		Expect(gpu.ConstantCaches).To(HaveLen(len(gpu.CUs)))
+		Expect(gpu.ConstantMemoryRouter).NotTo(BeNil())
+		Expect(gpu.CommandProcessor.L1VCaches).
+			To(HaveLen(len(gpu.L1VCaches) + len(gpu.CUs)))
+	})
+
+	It("should let the DMA engine access one cache line at a time", func() {
+		gpu := builder.WithLog2CacheLineSize(5).Build("GPU", 1)
+
+		Expect(gpu.DMAEngine.Log2AccessSize).To(Equal(uint64(5)))
+	})
+
+	It("should panic if the cache line is larger than the interleaving",
+		func() {
+			builder = builder.
+				WithLog2MemoryBankInterleavingSize(7).
+				WithLog2CacheLineSize(8)
+
+			Expect(func() { builder.Build("GPU", 1) }).To(Panic())
+		})
+})
@@ -0,0 +1,106 @@
+package runner
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/sarchlab/akita/v4/mem/mem"
+	"github.com/sarchlab/akita/v4/sim"
+	"github.com/sarchlab/akita/v4/tracing"
+	"github.com/sarchlab/mgpusim/v4/amd/driver"
+)
+
+// An rdmaStatsTracer counts the requests that an RDMA engine sends to and
+// receives from the other GPUs. It tells the two apart by the location of the
+// tasks, which the RDMA engine names after the direction of the requests.
+type rdmaStatsTracer struct {
+	timeTeller sim.TimeTeller
+
+	lock         sync.Mutex
+	stats        driver.RDMAStats
+	inFlight     map[string]sim.VTimeInSec
+	completed    uint64
+	totalLatency sim.VTimeInSec
+}
+
+func newRDMAStatsTracer(timeTeller sim.TimeTeller) *rdmaStatsTracer {
+	return &rdmaStatsTracer{
+		timeTeller: timeTeller,
+		inFlight:   make(map[string]sim.VTimeInSec),
+	}
+}
+
+// RDMAStats returns the statistics of the RDMA traffic recorded so far.
+func (t *rdmaStatsTracer) RDMAStats() driver.RDMAStats {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	stats := t.stats
+	if t.completed > 0 {
+		stats.AverageLatency = t.totalLatency / sim.VTimeInSec(t.completed)
+	}
+
+	return stats
+}
+
+// StartTask counts a request that arrives at the RDMA engine.
+func (t *rdmaStatsTracer) StartTask(task tracing.Task) {
+	if task.Kind != "req_in" {
+		return
+	}
+
+	req, ok := task.Detail.(mem.AccessReq)
+	if !ok {
+		return
+	}
+
+	var byteSize uint64
+	switch req := req.(type) {
+	case *mem.ReadReq:
+		byteSize = req.AccessByteSize
+	case *mem.WriteReq:
+		byteSize = uint64(len(req.Data))
+	}
+
+	now := t.timeTeller.CurrentTime()
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	switch {
+	case strings.HasSuffix(task.Where, ".InsideOut"):
+		t.stats.NumReqSent++
+		t.stats.BytesSent += byteSize
+		t.inFlight[task.ID] = now
+	case strings.HasSuffix(task.Where, ".OutsideIn"):
+		t.stats.NumReqReceived++
+		t.stats.BytesReceived += byteSize
+	}
+}
+
+// StepTask does nothing.
+func (t *rdmaStatsTracer) StepTask(_ tracing.Task) {
+	// Do nothing
+}
+
+// AddMilestone does nothing.
+func (t *rdmaStatsTracer) AddMilestone(_ tracing.Milestone) {
+	// Do nothing
+}
+
+// EndTask records the latency of a sent request that completes.
+func (t *rdmaStatsTracer) EndTask(task tracing.Task) {
+	now := t.timeTeller.CurrentTime()
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	start, found := t.inFlight[task.ID]
+	if !found {
+		return
+	}
+
+	delete(t.inFlight, task.ID)
+	t.completed++
+	t.totalLatency += now - start
+}
@@ -0,0 +1,80 @@
+package runner
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/sarchlab/akita/v4/mem/mem"
+	"github.com/sarchlab/akita/v4/tracing"
+	"github.com/sarchlab/mgpusim/v4/amd/driver"
+)
+
+var _ = Describe("RDMA Stats Tracer", func() {
+	var (
+		timeTeller *fakeTimeTeller
+		tracer     *rdmaStatsTracer
+	)
+
+	BeforeEach(func() {
+		timeTeller = &fakeTimeTeller{}
+		tracer = newRDMAStatsTracer(timeTeller)
+	})
+
+	// arrive traces a request that arrives at the RDMA engine from the given
+	// side and returns the task.
+	arrive := func(where string, req mem.AccessReq) tracing.Task {
+		task := tracing.Task{
+			ID:     req.Meta().ID,
+			Kind:   "req_in",
+			Where:  "GPU[1].RDMA." + where,
+			Detail: req,
+		}
+		tracer.StartTask(task)
+
+		return task
+	}
+
+	read := func() *mem.ReadReq {
+		return mem.ReadReqBuilder{}.WithByteSize(64).Build()
+	}
+
+	write := func() *mem.WriteReq {
+		return mem.WriteReqBuilder{}.WithData(make([]byte, 16)).Build()
+	}
+
+	It("should count the sent requests and their latency", func() {
+		first := arrive("InsideOut", read())
+		second := arrive("InsideOut", write())
+		timeTeller.now = 10e-9
+		tracer.EndTask(first)
+		timeTeller.now = 30e-9
+		tracer.EndTask(second)
+
+		stats := tracer.RDMAStats()
+
+		Expect(stats.NumReqSent).To(Equal(uint64(2)))
+		Expect(stats.BytesSent).To(Equal(uint64(80)))
+		Expect(float64(stats.AverageLatency)).To(BeNumerically("~", 20e-9))
+		Expect(stats.NumReqReceived).To(BeZero())
+	})
+
+	It("should count the received requests", func() {
+		task := arrive("OutsideIn", read())
+		timeTeller.now = 10e-9
+		tracer.EndTask(task)
+
+		Expect(tracer.RDMAStats()).To(Equal(driver.RDMAStats{
+			NumReqReceived: 1,
+			BytesReceived:  64,
+		}))
+	})
+
+	It("should not count the latency of the requests in flight", func() {
+		arrive("InsideOut", read())
+
+		stats := tracer.RDMAStats()
+
+		Expect(stats.NumReqSent).To(Equal(uint64(1)))
+		Expect(stats.AverageLatency).To(BeZero())
+	})
+})
@@ -0,0 +1,60 @@
+package runner
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/sarchlab/akita/v4/mem/mem"
+	"github.com/sarchlab/akita/v4/tracing"
+)
+
+// An rdmaTrafficTracer counts the requests that an RDMA engine sends to the
+// other GPUs, together with the bytes that they read or write.
+type rdmaTrafficTracer struct {
+	sync.Mutex
+
+	transactionCount uint64
+	bytes            uint64
+}
+
+func newRDMATrafficTracer() *rdmaTrafficTracer {
+	return &rdmaTrafficTracer{}
+}
+
+// StartTask counts the requests that come from inside the GPU. The requests
+// from the other RDMA engines are served by this GPU and are counted by the
+// RDMA engine that sends them.
+func (t *rdmaTrafficTracer) StartTask(task tracing.Task) {
+	if task.Kind != "req_in" {
+		return
+	}
+
+	req, ok := task.Detail.(mem.AccessReq)
+	if !ok || strings.Contains(string(req.Meta().Src), "RDMA") {
+		return
+	}
+
+	t.Lock()
+	defer t.Unlock()
+
+	t.transactionCount++
+
+	switch req := req.(type) {
+	case *mem.ReadReq:
+		t.bytes += req.AccessByteSize
+	case *mem.WriteReq:
+		t.bytes += uint64(len(req.Data))
+	}
+}
+
+func (t *rdmaTrafficTracer) StepTask(_ tracing.Task) {
+	// Do nothing
+}
+
+func (t *rdmaTrafficTracer) AddMilestone(_ tracing.Milestone) {
+	// Do nothing
+}
+
+func (t *rdmaTrafficTracer) EndTask(_ tracing.Task) {
+	// Do nothing
+}
@@ -0,0 +1,21 @@
+package runner
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("DRAM Refresh", func() {
+	It("should reduce the bandwidth of the DRAM", func() {
+		noRefresh := measureStreamingBandwidth(
+			MakeR9NanoGPUBuilder().WithDRAMRefreshInterval(0))
+		typicalRefresh := measureStreamingBandwidth(
+			MakeR9NanoGPUBuilder().WithDRAMRefreshInterval(
+				TypicalDRAMRefreshInterval))
+		shortRefresh := measureStreamingBandwidth(
+			MakeR9NanoGPUBuilder().WithDRAMRefreshInterval(400))
+
+		Expect(typicalRefresh).To(BeNumerically("<=", noRefresh))
+		Expect(shortRefresh).To(BeNumerically("<=", 0.8*noRefresh))
+	})
+})
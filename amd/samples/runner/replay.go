@@ -0,0 +1,16 @@
+package runner
+
+import (
+	"io"
+)
+
+// ReplayFrom runs the platform with the driver calls recorded by
+// Driver.StartRecording, read from rd. The platform must be configured in the
+// same way as the recorded one. Unlike Run, ReplayFrom returns after the
+// simulation, so that the stats can be read before exiting.
+func (r *Runner) ReplayFrom(rd io.Reader) error {
+	r.platform.Driver.Run()
+	defer r.platform.Driver.Terminate()
+
+	return r.platform.Driver.ReplayRecording(rd)
+}
@@ -0,0 +1,52 @@
+package runner
+
+import (
+	"bytes"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/sarchlab/mgpusim/v4/amd/benchmarks/heteromark/fir"
+)
+
+var _ = Describe("Replay", func() {
+	newRunner := func() *Runner {
+		r := &Runner{
+			platform:        MakeR9NanoBuilder().WithNumGPU(1).Build(),
+			CollectCounters: true,
+		}
+		r.addCounterTracers()
+
+		return r
+	}
+
+	dumpCounters := func(r *Runner) string {
+		buf := bytes.NewBuffer(nil)
+		Expect(r.DumpCountersCSV(buf)).To(Succeed())
+
+		return buf.String()
+	}
+
+	It("should reproduce the time and the counters of a recorded run", func() {
+		recorded := newRunner()
+		recording := bytes.NewBuffer(nil)
+		recorded.platform.Driver.StartRecording(recording)
+
+		benchmark := fir.NewBenchmark(recorded.platform.Driver)
+		benchmark.Length = 4096
+		benchmark.SelectGPU([]int{1})
+
+		recorded.platform.Driver.Run()
+		benchmark.Run()
+		benchmark.Verify()
+		recorded.platform.Driver.Terminate()
+
+		replayed := newRunner()
+		Expect(replayed.ReplayFrom(bytes.NewReader(recording.Bytes()))).
+			To(Succeed())
+
+		Expect(replayed.platform.Engine.CurrentTime()).
+			To(Equal(recorded.platform.Engine.CurrentTime()))
+		Expect(dumpCounters(replayed)).To(Equal(dumpCounters(recorded)))
+	})
+})
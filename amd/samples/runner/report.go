@@ -7,6 +7,7 @@ import (
 	"github.com/sarchlab/akita/v4/sim"
 	"github.com/sarchlab/akita/v4/tracing"
 	"github.com/sarchlab/mgpusim/v4/amd/timing/cu"
+	"github.com/sarchlab/mgpusim/v4/amd/timing/dram"
 	"github.com/sarchlab/mgpusim/v4/amd/timing/rdma"
 	"github.com/tebeka/atexit"
 )
@@ -64,6 +65,7 @@ func (r *Runner) defineMetrics() {
 	r.addRDMAEngineTracer()
 	r.addDRAMTracer()
 	r.addSIMDBusyTimeTracer()
+	r.addCounterTracers()
 
 	atexit.Register(func() { r.reportStats() })
 }
@@ -174,6 +176,17 @@ func (r *Runner) addCacheLatencyTracer() {
 			tracing.CollectTrace(cache, tracer)
 		}
 
+		for _, cache := range gpu.L1Caches {
+			tracer := tracing.NewAverageTimeTracer(
+				r.platform.Engine,
+				func(task tracing.Task) bool {
+					return task.Kind == "req_in"
+				})
+			r.cacheLatencyTracers = append(r.cacheLatencyTracers,
+				cacheLatencyTracer{tracer: tracer, cache: cache})
+			tracing.CollectTrace(cache, tracer)
+		}
+
 		for _, cache := range gpu.L2Caches {
 			tracer := tracing.NewAverageTimeTracer(
 				r.platform.Engine,
@@ -217,6 +230,14 @@ func (r *Runner) addCacheHitRateTracer() {
 			tracing.CollectTrace(cache, tracer)
 		}
 
+		for _, cache := range gpu.L1Caches {
+			tracer := tracing.NewStepCountTracer(
+				func(task tracing.Task) bool { return true })
+			r.cacheHitRateTracers = append(r.cacheHitRateTracers,
+				cacheHitRateTracer{tracer: tracer, cache: cache})
+			tracing.CollectTrace(cache, tracer)
+		}
+
 		for _, cache := range gpu.L2Caches {
 			tracer := tracing.NewStepCountTracer(
 				func(task tracing.Task) bool { return true })
@@ -225,6 +246,28 @@ func (r *Runner) addCacheHitRateTracer() {
 			tracing.CollectTrace(cache, tracer)
 		}
 	}
+
+	for _, cache := range r.sharedLLCBanks() {
+		tracer := tracing.NewStepCountTracer(
+			func(task tracing.Task) bool { return true })
+		r.cacheHitRateTracers = append(r.cacheHitRateTracers,
+			cacheHitRateTracer{tracer: tracer, cache: cache})
+		tracing.CollectTrace(cache, tracer)
+	}
+}
+
+// sharedLLCBanks returns the banks of the shared LLC, if there is one.
+func (r *Runner) sharedLLCBanks() []TraceableComponent {
+	if r.platform.SharedLLC == nil {
+		return nil
+	}
+
+	banks := make([]TraceableComponent, 0, len(r.platform.SharedLLC.Banks))
+	for _, bank := range r.platform.SharedLLC.Banks {
+		banks = append(banks, bank)
+	}
+
+	return banks
 }
 
 func (r *Runner) addTLBHitRateTracer() {
@@ -322,8 +365,14 @@ func (r *Runner) addDRAMTracer() {
 		return
 	}
 
+	tracedDRAMs := make(map[TraceableComponent]bool)
 	for _, gpu := range r.platform.GPUs {
 		for _, dram := range gpu.MemControllers {
+			if tracedDRAMs[dram] {
+				continue
+			}
+			tracedDRAMs[dram] = true
+
 			t := dramTransactionCountTracer{}
 			t.dram = dram.(TraceableComponent)
 			t.tracer = newDramTracer(r.platform.Engine)
@@ -564,6 +613,20 @@ func (r *Runner) reportDRAMTransactionCount() {
 			"write_size",
 			float64(t.tracer.writeSize),
 		)
+
+		d, ok := t.dram.(*dram.Comp)
+		if ok && d.ECCStats().NumAccess > 0 {
+			r.metricsCollector.Collect(
+				t.dram.Name(),
+				"ecc_trans_count",
+				float64(d.ECCStats().NumAccess),
+			)
+			r.metricsCollector.Collect(
+				t.dram.Name(),
+				"ecc_size",
+				float64(d.ECCStats().NumByte),
+			)
+		}
 	}
 }
 
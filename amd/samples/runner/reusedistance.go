@@ -0,0 +1,155 @@
+package runner
+
+import (
+	"math/bits"
+	"sync"
+
+	"github.com/sarchlab/akita/v4/mem/mem"
+	"github.com/sarchlab/akita/v4/tracing"
+	"github.com/sarchlab/mgpusim/v4/amd/driver"
+)
+
+// A reuseDistanceAnalyzer is a tracer that records the reuse distance of the
+// requests that arrive at caches. Each cache has its own LRU stack, as a cache
+// only holds the lines that it accesses. The histograms of all the caches are
+// merged.
+//
+// The analysis keeps an 8-byte counter for every access and a map entry for
+// every distinct line of every cache. The memory overhead therefore grows
+// linearly with the number of accesses, which is about 1 GB for every 100
+// million cache accesses.
+type reuseDistanceAnalyzer struct {
+	log2LineSize uint64
+
+	lock      sync.Mutex
+	stacks    map[string]*reuseDistanceStack
+	histogram driver.ReuseDistanceHistogram
+}
+
+func newReuseDistanceAnalyzer(log2LineSize uint64) *reuseDistanceAnalyzer {
+	return &reuseDistanceAnalyzer{
+		log2LineSize: log2LineSize,
+		stacks:       make(map[string]*reuseDistanceStack),
+	}
+}
+
+// ReuseDistanceHistogram returns the reuse distance histogram of all the
+// accesses recorded so far.
+func (a *reuseDistanceAnalyzer) ReuseDistanceHistogram() driver.ReuseDistanceHistogram {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	h := a.histogram
+	h.Buckets = append([]uint64(nil), a.histogram.Buckets...)
+
+	return h
+}
+
+// StartTask records an access if the task is a memory access request that
+// arrives at a cache.
+func (a *reuseDistanceAnalyzer) StartTask(task tracing.Task) {
+	if task.Kind != "req_in" {
+		return
+	}
+
+	req, ok := task.Detail.(mem.AccessReq)
+	if !ok {
+		return
+	}
+
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	stack, found := a.stacks[task.Where]
+	if !found {
+		stack = newReuseDistanceStack()
+		a.stacks[task.Where] = stack
+	}
+
+	distance, cold := stack.access(req.GetAddress() >> a.log2LineSize)
+	if cold {
+		a.histogram.ColdAccesses++
+		return
+	}
+
+	bucket := bits.Len64(distance)
+	for len(a.histogram.Buckets) <= bucket {
+		a.histogram.Buckets = append(a.histogram.Buckets, 0)
+	}
+	a.histogram.Buckets[bucket]++
+}
+
+// StepTask does nothing.
+func (a *reuseDistanceAnalyzer) StepTask(_ tracing.Task) {
+	// Do nothing
+}
+
+// AddMilestone does nothing.
+func (a *reuseDistanceAnalyzer) AddMilestone(_ tracing.Milestone) {
+	// Do nothing
+}
+
+// EndTask does nothing.
+func (a *reuseDistanceAnalyzer) EndTask(_ tracing.Task) {
+	// Do nothing
+}
+
+// A reuseDistanceStack calculates the stack distance of the accesses to one
+// cache. Rather than maintaining an LRU stack, it numbers the accesses and
+// marks the accesses that are the latest access to their lines. The number of
+// marks after the previous access to a line is the stack distance.
+type reuseDistanceStack struct {
+	lastAccess map[uint64]int
+	latest     fenwickTree
+}
+
+func newReuseDistanceStack() *reuseDistanceStack {
+	return &reuseDistanceStack{
+		lastAccess: make(map[uint64]int),
+		latest:     fenwickTree{0},
+	}
+}
+
+func (s *reuseDistanceStack) access(line uint64) (distance uint64, cold bool) {
+	numAccess := s.latest.len()
+	last, found := s.lastAccess[line]
+
+	if found {
+		distance = uint64(s.latest.prefixSum(numAccess) - s.latest.prefixSum(last))
+		s.latest.add(last, -1)
+	}
+
+	s.latest.append(1)
+	s.lastAccess[line] = numAccess + 1
+
+	return distance, !found
+}
+
+// A fenwickTree is a binary indexed tree that supports prefix sums and
+// appending elements. It is 1-indexed and element 0 is not used.
+type fenwickTree []int64
+
+func (t fenwickTree) len() int {
+	return len(t) - 1
+}
+
+func (t fenwickTree) prefixSum(i int) int64 {
+	sum := int64(0)
+	for ; i > 0; i -= i & -i {
+		sum += t[i]
+	}
+
+	return sum
+}
+
+func (t fenwickTree) add(i int, v int64) {
+	for ; i < len(t); i += i & -i {
+		t[i] += v
+	}
+}
+
+func (t *fenwickTree) append(v int64) {
+	i := len(*t)
+	covered := t.prefixSum(i-1) - t.prefixSum(i-(i&-i))
+	*t = append(*t, v+covered)
+}
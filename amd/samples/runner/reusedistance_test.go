@@ -0,0 +1,80 @@
+package runner
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/sarchlab/akita/v4/mem/mem"
+	"github.com/sarchlab/akita/v4/tracing"
+	"github.com/sarchlab/mgpusim/v4/amd/driver"
+)
+
+var _ = Describe("Reuse Distance Stack", func() {
+	It("should calculate the stack distance of the accesses", func() {
+		s := newReuseDistanceStack()
+
+		// Lines: a b c a a c b d a
+		lines := []uint64{1, 2, 3, 1, 1, 3, 2, 4, 1}
+		expected := []int{-1, -1, -1, 2, 0, 1, 2, -1, 3}
+
+		for i, line := range lines {
+			distance, cold := s.access(line)
+
+			if expected[i] < 0 {
+				Expect(cold).To(BeTrue(), "access %d", i)
+				continue
+			}
+
+			Expect(cold).To(BeFalse(), "access %d", i)
+			Expect(distance).To(Equal(uint64(expected[i])), "access %d", i)
+		}
+	})
+})
+
+var _ = Describe("Reuse Distance Analyzer", func() {
+	var analyzer *reuseDistanceAnalyzer
+
+	BeforeEach(func() {
+		analyzer = newReuseDistanceAnalyzer(6)
+	})
+
+	access := func(where string, addr uint64) {
+		analyzer.StartTask(tracing.Task{
+			Kind:   "req_in",
+			Where:  where,
+			Detail: mem.ReadReqBuilder{}.WithAddress(addr).Build(),
+		})
+	}
+
+	It("should count the accesses to the same line as reuses", func() {
+		access("L2", 0x100)
+		access("L2", 0x104)
+		access("L2", 0x140)
+		access("L2", 0x100)
+
+		Expect(analyzer.ReuseDistanceHistogram()).To(Equal(
+			driver.ReuseDistanceHistogram{
+				ColdAccesses: 2,
+				Buckets:      []uint64{1, 1},
+			}))
+	})
+
+	It("should keep a stack for each cache", func() {
+		access("L2[0]", 0x100)
+		access("L2[1]", 0x100)
+
+		h := analyzer.ReuseDistanceHistogram()
+		Expect(h.ColdAccesses).To(Equal(uint64(2)))
+		Expect(h.Buckets).To(BeEmpty())
+	})
+
+	It("should ignore the tasks that are not incoming requests", func() {
+		analyzer.StartTask(tracing.Task{
+			Kind:   "req_out",
+			Where:  "L2",
+			Detail: mem.ReadReqBuilder{}.WithAddress(0x100).Build(),
+		})
+
+		Expect(analyzer.ReuseDistanceHistogram().ColdAccesses).To(BeZero())
+	})
+})
@@ -0,0 +1,111 @@
+package runner
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/sarchlab/akita/v4/sim"
+	"github.com/sarchlab/akita/v4/tracing"
+	"github.com/sarchlab/mgpusim/v4/amd/benchmarks/heteromark/fir"
+)
+
+var _ = Describe("Instruction Stopper", func() {
+	retire := func(t *instTracer, id string) {
+		t.StartTask(tracing.Task{ID: id, Kind: "inst", What: "VALU"})
+		t.EndTask(tracing.Task{ID: id})
+	}
+
+	It("should stop once at the max count", func() {
+		numStops := 0
+		stopper := newInstStopper(2, func() { numStops++ })
+
+		retire(stopper, "inst0")
+		Expect(numStops).To(Equal(0))
+
+		retire(stopper, "inst1")
+		retire(stopper, "inst2")
+		Expect(numStops).To(Equal(1))
+		Expect(stopper.count).To(Equal(uint64(3)))
+	})
+
+	It("should not count the tasks that are not instructions", func() {
+		stopper := newInstStopper(1, func() {})
+
+		stopper.StartTask(tracing.Task{ID: "req", Kind: "req_out"})
+		stopper.EndTask(tracing.Task{ID: "req"})
+
+		Expect(stopper.count).To(Equal(uint64(0)))
+	})
+})
+
+var _ = Describe("Engine Gate", func() {
+	var gate *engineGate
+
+	BeforeEach(func() {
+		gate = newEngineGate()
+	})
+
+	beforeEvent := func() chan bool {
+		done := make(chan bool)
+		go func() {
+			gate.Func(sim.HookCtx{Pos: sim.HookPosBeforeEvent})
+			close(done)
+		}()
+
+		return done
+	}
+
+	It("should let the engine through while the gate is open", func() {
+		Eventually(beforeEvent()).Should(BeClosed())
+	})
+
+	It("should hold the engine before the next event once closed", func() {
+		gate.close()
+		done := beforeEvent()
+
+		Eventually(gate.halted).Should(Receive())
+		Consistently(done).ShouldNot(BeClosed())
+
+		gate.open()
+
+		Eventually(done).Should(BeClosed())
+		Expect(gate.closed).To(BeFalse())
+	})
+
+	It("should not hold the engine after an event", func() {
+		gate.close()
+
+		gate.Func(sim.HookCtx{Pos: sim.HookPosAfterEvent})
+
+		Expect(gate.closed).To(BeTrue())
+	})
+})
+
+var _ = Describe("Run For Instructions", func() {
+	It("should halt at the instruction count and continue to completion",
+		func() {
+			const maxInst = 20000
+
+			platform := MakeR9NanoBuilder().WithNumGPU(1).Build()
+
+			counter := newInstTracer()
+			for _, cu := range platform.GPUs[0].CUs {
+				tracing.CollectTrace(cu, counter)
+			}
+
+			benchmark := fir.NewBenchmark(platform.Driver)
+			benchmark.Length = 8 * 1024
+			benchmark.SelectGPU([]int{1})
+
+			r := &Runner{platform: platform}
+			r.AddBenchmarkWithoutSettingGPUsToUse(benchmark)
+			r.RunForInstructions(maxInst)
+
+			countAtHalt := counter.count
+			Expect(countAtHalt).To(Equal(uint64(maxInst)))
+
+			r.Continue()
+
+			Expect(counter.count).To(BeNumerically(">", countAtHalt))
+		})
+})
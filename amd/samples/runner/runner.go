@@ -2,6 +2,7 @@
 package runner
 
 import (
+	"io"
 	"log"
 
 	// Enable profiling
@@ -30,6 +31,8 @@ type verificationPreEnablingBenchmark interface {
 type Runner struct {
 	platform                *Platform
 	maxInstStopper          *instTracer
+	engineGate              *engineGate
+	benchmarksCompleted     chan bool
 	kernelTimeCounter       *tracing.BusyTimeTracer
 	perGPUKernelTimeCounter []*tracing.BusyTimeTracer
 	instCountTracers        []instCountTracer
@@ -43,6 +46,14 @@ type Runner struct {
 	metricsCollector        *collector
 	simdBusyTimeTracers     []simdBusyTimeTracer
 	cuCPITraces             []cuCPIStackTracer
+	chromeTracer            *ChromeTracer
+	accelSimTracer          *AccelSimTracer
+	cacheCounters           []cacheHitRateTracer
+	tlbCounters             []tlbHitRateTracer
+	cuCounters              []instCountTracer
+	dramCounters            []dramTransactionCountTracer
+	rdmaCounters            []rdmaTrafficCounter
+	cacheTrafficCounters    []cacheTrafficTracer
 
 	Timing                     bool
 	Verify                     bool
@@ -56,6 +67,7 @@ type Runner struct {
 	UseUnifiedMemory           bool
 	ReportSIMDBusyTime         bool
 	ReportCPIStack             bool
+	CollectCounters            bool
 
 	GPUIDs []int
 }
@@ -81,6 +93,36 @@ func (r *Runner) Init() *Runner {
 	return r
 }
 
+// WithChromeTracer lets the runner write the visualization traces to the
+// given writer in the Chrome Trace Event format. It only takes effect in
+// timing simulations and must be called before Init.
+func (r *Runner) WithChromeTracer(w io.Writer) *Runner {
+	r.chromeTracer = NewChromeTracer(w)
+	atexit.Register(func() {
+		err := r.chromeTracer.Terminate()
+		if err != nil {
+			log.Print(err)
+		}
+	})
+
+	return r
+}
+
+// WithAccelSimTracer lets the runner write the vector memory accesses to the
+// given writer in a layout compatible with the Accel-Sim traces. It only takes
+// effect in timing simulations and must be called before Init.
+func (r *Runner) WithAccelSimTracer(w io.Writer) *Runner {
+	r.accelSimTracer = NewAccelSimTracer(w)
+	atexit.Register(func() {
+		err := r.accelSimTracer.Terminate()
+		if err != nil {
+			log.Print(err)
+		}
+	})
+
+	return r
+}
+
 func (r *Runner) buildEmuPlatform() {
 	b := MakeEmuBuilder().
 		WithNumGPU(r.GPUIDs[len(r.GPUIDs)-1])
@@ -127,6 +169,14 @@ func (r *Runner) buildTimingPlatform() {
 		)
 	}
 
+	if r.chromeTracer != nil {
+		b = b.WithChromeTracer(r.chromeTracer)
+	}
+
+	if r.accelSimTracer != nil {
+		b = b.WithAccelSimTracer(r.accelSimTracer)
+	}
+
 	if *memTracing {
 		b = b.WithMemTracing()
 	}
@@ -144,6 +194,22 @@ func (r *Runner) buildTimingPlatform() {
 		b = b.WithMagicMemoryCopy()
 	}
 
+	if *sharedDRAMFlag {
+		b = b.WithSharedDRAMPool()
+	}
+
+	if *sharedLLCSizeFlag > 0 {
+		b = b.WithSharedLLC(*sharedLLCSizeFlag)
+	}
+
+	if *eccFlag {
+		b = b.WithECCEnabled()
+	}
+
+	if *gpuFreqsFlag != "" {
+		b = b.WithGPUFreqs(r.gpuFreqStringToList(*gpuFreqsFlag)...)
+	}
+
 	r.platform = b.Build()
 
 	if !*disableAkitaRTM {
@@ -172,7 +238,9 @@ func (r *Runner) addMaxInstStopper() {
 		return
 	}
 
-	r.maxInstStopper = newInstStopper(*maxInstCount)
+	r.maxInstStopper = newInstStopper(*maxInstCount, func() {
+		atexit.Exit(0)
+	})
 	for _, gpu := range r.platform.GPUs {
 		for _, cu := range gpu.CUs {
 			tracing.CollectTrace(cu.(tracing.NamedHookable), r.maxInstStopper)
@@ -224,6 +292,21 @@ func (r *Runner) gpuIDStringToList(gpuIDsString string) []int {
 	return gpuIDs
 }
 
+func (r *Runner) gpuFreqStringToList(gpuFreqsString string) []sim.Freq {
+	freqs := make([]sim.Freq, 0)
+	freqTokens := strings.Split(gpuFreqsString, ",")
+
+	for _, t := range freqTokens {
+		freqInMHz, err := strconv.ParseFloat(t, 64)
+		if err != nil {
+			panic(err)
+		}
+		freqs = append(freqs, sim.Freq(freqInMHz)*sim.MHz)
+	}
+
+	return freqs
+}
+
 // AddBenchmark adds an benchmark that the driver runs
 func (r *Runner) AddBenchmark(b benchmarks.Benchmark) {
 	b.SelectGPU(r.GPUIDs)
@@ -271,6 +354,62 @@ func (r *Runner) Run() {
 	atexit.Exit(0)
 }
 
+// RunForInstructions runs the benchmarks until the CUs of all the GPUs have
+// retired the given number of instructions in total, and then halts the
+// simulation, so that the state of the platform can be inspected. The engine
+// halts right after the event that retires the last instruction, so only the
+// instructions that retire in the same event exceed the count. If the
+// benchmarks complete before reaching the count, the driver is terminated
+// instead. Call Continue to run the halted benchmarks to completion. Unlike
+// Run, it does not verify the results or exit the program.
+func (r *Runner) RunForInstructions(count uint64) {
+	r.engineGate = newEngineGate()
+	r.platform.Engine.AcceptHook(r.engineGate)
+
+	stopper := newInstStopper(count, r.engineGate.close)
+	for _, gpu := range r.platform.GPUs {
+		for _, cu := range gpu.CUs {
+			tracing.CollectTrace(cu.(tracing.NamedHookable), stopper)
+		}
+	}
+
+	r.platform.Driver.Run()
+
+	r.benchmarksCompleted = make(chan bool)
+	go func() {
+		var wg sync.WaitGroup
+		for _, b := range r.benchmarks {
+			wg.Add(1)
+			go func(b benchmarks.Benchmark) {
+				b.Run()
+				wg.Done()
+			}(b)
+		}
+		wg.Wait()
+		close(r.benchmarksCompleted)
+	}()
+
+	select {
+	case <-r.engineGate.halted:
+	case <-r.benchmarksCompleted:
+		r.platform.Driver.Terminate()
+	}
+}
+
+// Continue lets the simulation that RunForInstructions has halted run the
+// benchmarks to completion, and then terminates the driver.
+func (r *Runner) Continue() {
+	select {
+	case <-r.benchmarksCompleted:
+		return
+	default:
+	}
+
+	r.engineGate.open()
+	<-r.benchmarksCompleted
+	r.platform.Driver.Terminate()
+}
+
 // Driver returns the GPU driver used by the current runner.
 func (r *Runner) Driver() *driver.Driver {
 	return r.platform.Driver
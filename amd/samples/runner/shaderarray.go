@@ -5,16 +5,20 @@ import (
 	"log"
 	"os"
 
-	"github.com/sarchlab/akita/v4/mem/cache/writearound"
 	"github.com/sarchlab/akita/v4/mem/cache/writethrough"
 	"github.com/sarchlab/akita/v4/mem/mem"
-	"github.com/sarchlab/akita/v4/mem/vm/addresstranslator"
-	"github.com/sarchlab/akita/v4/mem/vm/tlb"
+	"github.com/sarchlab/akita/v4/mem/vm"
 	"github.com/sarchlab/akita/v4/sim"
 	"github.com/sarchlab/akita/v4/sim/directconnection"
 	"github.com/sarchlab/akita/v4/tracing"
+	"github.com/sarchlab/mgpusim/v4/amd/insts"
+	"github.com/sarchlab/mgpusim/v4/amd/timing/addresstranslator"
 	"github.com/sarchlab/mgpusim/v4/amd/timing/cu"
 	"github.com/sarchlab/mgpusim/v4/amd/timing/rob"
+	"github.com/sarchlab/mgpusim/v4/amd/timing/tlb"
+	"github.com/sarchlab/mgpusim/v4/amd/timing/victimcache"
+	"github.com/sarchlab/mgpusim/v4/amd/timing/writearound"
+	"github.com/sarchlab/mgpusim/v4/amd/timing/writecombining"
 )
 
 type shaderArray struct {
@@ -28,9 +32,18 @@ type shaderArray struct {
 	l1sAT  *addresstranslator.Comp
 	l1iAT  *addresstranslator.Comp
 
-	l1vCaches []*writearound.Comp
-	l1sCache  *writethrough.Comp
-	l1iCache  *writethrough.Comp
+	l0Caches        []*writearound.Comp
+	constantCaches  []*writearound.Comp
+	l1vWCBs         []*writecombining.Comp
+	l1vCaches       []*writearound.Comp
+	l1vVictimCaches []*victimcache.Comp
+	l1sCache        *writethrough.Comp
+	l1iCache        *writethrough.Comp
+
+	// l1Cache replaces the L1V, L1S, and L1I caches if the L1 is unified. All
+	// the clients of the cache connect to its top port through l1Conn.
+	l1Cache *writearound.Comp
+	l1Conn  *directconnection.Comp
 
 	l1vTLBs []*tlb.Comp
 	l1sTLB  *tlb.Comp
@@ -38,19 +51,49 @@ type shaderArray struct {
 }
 
 type shaderArrayBuilder struct {
-	gpuID uint64
-	name  string
-	numCU int
+	gpuID        uint64
+	name         string
+	numCU        int
+	numSIMDPerCU int
+
+	wfSchedulingPolicy cu.WavefrontSchedulingPolicy
+	l1vVictimCacheSize uint64
+	l0CacheSize        uint64
+	constantCacheSize  uint64
+	wcbDepth           int
+	tlbShootdownCycles int
+	l1TLBNumSets       int
+	l1TLBNumWays       int
+	l1vNumPorts        int
+	numVGPRBanks       int
+	wavefrontSize      int
+	wfSlotsPerSIMD     int
+	sfuLatencyTable    map[insts.Opcode]int
+	barrierLatency     int
+	l1iMissLatency     int
+	scalarMSHREntries  int
+	numVGPRBankPorts   int
+	ldsSizePerCU       uint64
+	unifiedL1          bool
 
 	engine            sim.Engine
 	freq              sim.Freq
 	log2CacheLineSize uint64
 	log2PageSize      uint64
+	log2HugePageSize  uint64
+	timingScale       float64
 
 	isaDebugging bool
+	isaDebugWf   *isaDebugWavefront
+	pageTable    vm.PageTable
 	visTracer    tracing.Tracer
 	memTracer    tracing.Tracer
 
+	idealTLBPageTable vm.PageTable
+	faultPageTable    vm.PageTable
+
+	constantMemoryRouter *constantMemoryRouter
+
 	connectionCount int
 }
 
@@ -59,9 +102,13 @@ func makeShaderArrayBuilder() shaderArrayBuilder {
 		gpuID:             0,
 		name:              "SA",
 		numCU:             4,
+		numSIMDPerCU:      4,
 		freq:              1 * sim.GHz,
 		log2CacheLineSize: 6,
 		log2PageSize:      12,
+		timingScale:       1,
+		l1TLBNumSets:      1,
+		l1TLBNumWays:      64,
 	}
 	return b
 }
@@ -86,6 +133,118 @@ func (b shaderArrayBuilder) withNumCU(n int) shaderArrayBuilder {
 	return b
 }
 
+func (b shaderArrayBuilder) withNumSIMDPerCU(n int) shaderArrayBuilder {
+	b.numSIMDPerCU = n
+	return b
+}
+
+func (b shaderArrayBuilder) withWavefrontSchedulingPolicy(
+	policy cu.WavefrontSchedulingPolicy,
+) shaderArrayBuilder {
+	b.wfSchedulingPolicy = policy
+	return b
+}
+
+func (b shaderArrayBuilder) withBankedRegisterFile(
+	numBanks, numPorts int,
+) shaderArrayBuilder {
+	b.numVGPRBanks = numBanks
+	b.numVGPRBankPorts = numPorts
+	return b
+}
+
+func (b shaderArrayBuilder) withWavefrontSize(n int) shaderArrayBuilder {
+	b.wavefrontSize = n
+	return b
+}
+
+func (b shaderArrayBuilder) withWavefrontSlotsPerSIMD(
+	n int,
+) shaderArrayBuilder {
+	b.wfSlotsPerSIMD = n
+	return b
+}
+
+func (b shaderArrayBuilder) withSFULatencyTable(
+	table map[insts.Opcode]int,
+) shaderArrayBuilder {
+	b.sfuLatencyTable = table
+	return b
+}
+
+func (b shaderArrayBuilder) withBarrierLatency(cycles int) shaderArrayBuilder {
+	b.barrierLatency = cycles
+	return b
+}
+
+func (b shaderArrayBuilder) withL1IMissLatency(cycles int) shaderArrayBuilder {
+	b.l1iMissLatency = cycles
+	return b
+}
+
+func (b shaderArrayBuilder) withScalarMSHREntries(n int) shaderArrayBuilder {
+	b.scalarMSHREntries = n
+	return b
+}
+
+func (b shaderArrayBuilder) withUnifiedL1() shaderArrayBuilder {
+	b.unifiedL1 = true
+	return b
+}
+
+func (b shaderArrayBuilder) withLDSSizePerCU(size uint64) shaderArrayBuilder {
+	b.ldsSizePerCU = size
+	return b
+}
+
+func (b shaderArrayBuilder) withL1VVictimCache(
+	byteSize uint64,
+) shaderArrayBuilder {
+	b.l1vVictimCacheSize = byteSize
+	return b
+}
+
+func (b shaderArrayBuilder) withL0Cache(byteSize uint64) shaderArrayBuilder {
+	b.l0CacheSize = byteSize
+	return b
+}
+
+// withConstantCache builds a constant cache of the given size in each CU. The
+// router tells which accesses go to the constant caches.
+func (b shaderArrayBuilder) withConstantCache(
+	byteSize uint64,
+	router *constantMemoryRouter,
+) shaderArrayBuilder {
+	b.constantCacheSize = byteSize
+	b.constantMemoryRouter = router
+	return b
+}
+
+func (b shaderArrayBuilder) withWriteCombining(depth int) shaderArrayBuilder {
+	b.wcbDepth = depth
+	return b
+}
+
+func (b shaderArrayBuilder) withTLBShootdownLatency(
+	cycles int,
+) shaderArrayBuilder {
+	b.tlbShootdownCycles = cycles
+	return b
+}
+
+func (b shaderArrayBuilder) withL1TLBGeometry(
+	numSets, numWays int,
+) shaderArrayBuilder {
+	b.l1TLBNumSets = numSets
+	b.l1TLBNumWays = numWays
+	return b
+}
+
+func (b shaderArrayBuilder) withL1VNumPorts(numPorts int) shaderArrayBuilder {
+	b.l1vNumPorts = numPorts
+	return b
+}
+
 func (b shaderArrayBuilder) withLog2CachelineSize(
 	log2Size uint64,
 ) shaderArrayBuilder {
@@ -100,11 +259,63 @@ func (b shaderArrayBuilder) withLog2PageSize(
 	return b
 }
 
+func (b shaderArrayBuilder) withLog2HugePageSize(
+	log2Size uint64,
+) shaderArrayBuilder {
+	b.log2HugePageSize = log2Size
+	return b
+}
+
+func (b shaderArrayBuilder) hugePageSize() uint64 {
+	if b.log2HugePageSize == 0 {
+		return 0
+	}
+
+	return 1 << b.log2HugePageSize
+}
+
+func (b shaderArrayBuilder) withTimingScale(
+	factor float64,
+) shaderArrayBuilder {
+	b.timingScale = factor
+	return b
+}
+
+func (b shaderArrayBuilder) withTranslationValidation(
+	pageTable vm.PageTable,
+) shaderArrayBuilder {
+	b.pageTable = pageTable
+	return b
+}
+
+func (b shaderArrayBuilder) withFaultDetection(
+	pageTable vm.PageTable,
+) shaderArrayBuilder {
+	b.faultPageTable = pageTable
+	return b
+}
+
+func (b shaderArrayBuilder) withIdealTLB(
+	pageTable vm.PageTable,
+) shaderArrayBuilder {
+	b.idealTLBPageTable = pageTable
+	return b
+}
+
 func (b shaderArrayBuilder) withIsaDebugging() shaderArrayBuilder {
 	b.isaDebugging = true
 	return b
 }
 
+// withIsaDebuggingForWavefront only dumps the instructions of a wavefront. The
+// CU index of the target is the index of the CU in the shader array.
+func (b shaderArrayBuilder) withIsaDebuggingForWavefront(
+	target isaDebugWavefront,
+) shaderArrayBuilder {
+	b.isaDebugWf = &target
+	return b
+}
+
 func (b shaderArrayBuilder) withVisTracer(
 	visTracer tracing.Tracer,
 ) shaderArrayBuilder {
@@ -135,16 +346,26 @@ func (b *shaderArrayBuilder) buildComponents(sa *shaderArray) {
 	b.buildL1VTLBs(sa)
 	b.buildL1VAddressTranslators(sa)
 	b.buildL1VReorderBuffers(sa)
-	b.buildL1VCaches(sa)
+	b.buildL0Caches(sa)
+	b.buildConstantCaches(sa)
+	b.buildL1VWriteCombiningBuffers(sa)
 
 	b.buildL1STLB(sa)
 	b.buildL1SAddressTranslator(sa)
 	b.buildL1SReorderBuffer(sa)
-	b.buildL1SCache(sa)
 
 	b.buildL1ITLB(sa)
 	b.buildL1IAddressTranslator(sa)
 	b.buildL1IReorderBuffer(sa)
+
+	if b.unifiedL1 {
+		b.buildUnifiedL1Cache(sa)
+		return
+	}
+
+	b.buildL1VCaches(sa)
+	b.buildL1VVictimCaches(sa)
+	b.buildL1SCache(sa)
 	b.buildL1ICache(sa)
 }
 
@@ -159,7 +380,6 @@ func (b *shaderArrayBuilder) connectVectorMem(sa *shaderArray) {
 		cu := sa.cus[i]
 		rob := sa.l1vROBs[i]
 		at := sa.l1vATs[i]
-		l1v := sa.l1vCaches[i]
 		tlb := sa.l1vTLBs[i]
 
 		cu.VectorMemModules = &mem.SinglePortMapper{
@@ -178,11 +398,49 @@ func (b *shaderArrayBuilder) connectVectorMem(sa *shaderArray) {
 		b.connectWithDirectConnection(
 			at.GetPortByName("Translation"), tlbTopPort, 8)
 
-		at.SetAddressToPortMapper(&mem.SinglePortMapper{
-			Port: l1v.GetPortByName("Top").AsRemote(),
-		})
-		b.connectWithDirectConnection(l1v.GetPortByName("Top"),
-			at.GetPortByName("Bottom"), 8)
+		// The accesses go through the optional L0 cache and write-combining
+		// buffer on the way from the address translator to the L1V cache. The
+		// accesses to the constant memory leave the address translator for
+		// the constant cache instead.
+		client := at.GetPortByName("Bottom")
+		setClientMapper := at.SetAddressToPortMapper
+		connect := b.connectToLowModule
+		if len(sa.constantCaches) > 0 {
+			connect = b.constantCacheConnector(sa.constantCaches[i])
+		}
+
+		if len(sa.l0Caches) > 0 {
+			l0 := sa.l0Caches[i]
+			connect(client, setClientMapper, l0.GetPortByName("Top"))
+			client = l0.GetPortByName("Bottom")
+			setClientMapper = l0.SetAddressToPortMapper
+			connect = b.connectToLowModule
+		}
+
+		if len(sa.l1vWCBs) > 0 {
+			wcb := sa.l1vWCBs[i]
+			connect(client, setClientMapper, wcb.GetPortByName("Top"))
+			client = wcb.GetPortByName("Bottom")
+			setClientMapper = wcb.SetAddressToPortMapper
+			connect = b.connectToLowModule
+		}
+
+		if sa.l1Cache != nil {
+			b.connectToUnifiedL1(sa, client, setClientMapper)
+			continue
+		}
+
+		l1v := sa.l1vCaches[i]
+		connect(client, setClientMapper, l1v.GetPortByName("Top"))
+
+		if len(sa.l1vVictimCaches) > 0 {
+			victimTopPort := sa.l1vVictimCaches[i].GetPortByName("Top")
+			l1v.SetAddressToPortMapper(&mem.SinglePortMapper{
+				Port: victimTopPort.AsRemote(),
+			})
+			b.connectWithDirectConnection(l1v.GetPortByName("Bottom"),
+				victimTopPort, 8)
+		}
 	}
 }
 
@@ -190,7 +448,6 @@ func (b *shaderArrayBuilder) connectScalarMem(sa *shaderArray) {
 	rob := sa.l1sROB
 	at := sa.l1sAT
 	tlb := sa.l1sTLB
-	l1s := sa.l1sCache
 
 	atTopPort := at.GetPortByName("Top")
 	rob.BottomUnit = atTopPort
@@ -201,11 +458,13 @@ func (b *shaderArrayBuilder) connectScalarMem(sa *shaderArray) {
 	b.connectWithDirectConnection(
 		at.GetPortByName("Translation"), tlbTopPort, 8)
 
-	at.SetAddressToPortMapper(&mem.SinglePortMapper{
-		Port: l1s.GetPortByName("Top").AsRemote(),
-	})
-	b.connectWithDirectConnection(
-		l1s.GetPortByName("Top"), at.GetPortByName("Bottom"), 8)
+	if sa.l1Cache != nil {
+		b.connectToUnifiedL1(
+			sa, at.GetPortByName("Bottom"), at.SetAddressToPortMapper)
+	} else {
+		b.connectToLowModule(at.GetPortByName("Bottom"),
+			at.SetAddressToPortMapper, sa.l1sCache.GetPortByName("Top"))
+	}
 
 	conn := directconnection.MakeBuilder().
 		WithEngine(b.engine).
@@ -223,17 +482,19 @@ func (b *shaderArrayBuilder) connectInstMem(sa *shaderArray) {
 	rob := sa.l1iROB
 	at := sa.l1iAT
 	tlb := sa.l1iTLB
-	l1i := sa.l1iCache
-
-	l1iTopPort := l1i.GetPortByName("Top")
-	rob.BottomUnit = l1iTopPort
-	b.connectWithDirectConnection(rob.GetPortByName("Bottom"), l1iTopPort, 8)
 
-	atTopPort := at.GetPortByName("Top")
-	l1i.SetAddressToPortMapper(&mem.SinglePortMapper{
-		Port: atTopPort.AsRemote(),
-	})
-	b.connectWithDirectConnection(l1i.GetPortByName("Bottom"), atTopPort, 8)
+	if sa.l1Cache != nil {
+		// The unified L1 cache is physically addressed, so the instruction
+		// fetches are translated before they reach the cache.
+		atTopPort := at.GetPortByName("Top")
+		rob.BottomUnit = atTopPort
+		b.connectWithDirectConnection(
+			rob.GetPortByName("Bottom"), atTopPort, 8)
+		b.connectToUnifiedL1(
+			sa, at.GetPortByName("Bottom"), at.SetAddressToPortMapper)
+	} else {
+		b.connectInstMemToL1I(sa)
+	}
 
 	tlbTopPort := tlb.GetPortByName("Top")
 	at.SetTranslationProvider(tlbTopPort.AsRemote())
@@ -253,10 +514,85 @@ func (b *shaderArrayBuilder) connectInstMem(sa *shaderArray) {
 	}
 }
 
+// connectInstMemToL1I places the L1I cache between the reorder buffer and the
+// address translator of the instruction fetches.
+func (b *shaderArrayBuilder) connectInstMemToL1I(sa *shaderArray) {
+	rob := sa.l1iROB
+	at := sa.l1iAT
+	l1i := sa.l1iCache
+
+	l1iTopPort := l1i.GetPortByName("Top")
+	rob.BottomUnit = l1iTopPort
+	b.connectWithDirectConnection(rob.GetPortByName("Bottom"), l1iTopPort, 8)
+
+	atTopPort := at.GetPortByName("Top")
+	l1i.SetAddressToPortMapper(&mem.SinglePortMapper{
+		Port: atTopPort.AsRemote(),
+	})
+	if b.l1iMissLatency > 0 {
+		b.connectWithLatencyConnection(
+			l1i.GetPortByName("Bottom"), atTopPort,
+			scaleLatency(b.l1iMissLatency, b.timingScale))
+	} else {
+		b.connectWithDirectConnection(
+			l1i.GetPortByName("Bottom"), atTopPort, 8)
+	}
+}
+
+// connectToUnifiedL1 lets the client send all its requests to the unified L1
+// cache.
+func (b *shaderArrayBuilder) connectToUnifiedL1(
+	sa *shaderArray,
+	client sim.Port,
+	setClientMapper func(mem.AddressToPortMapper),
+) {
+	setClientMapper(&mem.SinglePortMapper{
+		Port: sa.l1Cache.GetPortByName("Top").AsRemote(),
+	})
+	sa.l1Conn.PlugIn(client)
+}
+
+// connectToLowModule lets the client send all its requests to the low module.
+func (b *shaderArrayBuilder) connectToLowModule(
+	client sim.Port,
+	setClientMapper func(mem.AddressToPortMapper),
+	lowModule sim.Port,
+) {
+	setClientMapper(&mem.SinglePortMapper{
+		Port: lowModule.AsRemote(),
+	})
+	b.connectWithDirectConnection(lowModule, client, 8)
+}
+
+// constantCacheConnector returns the function that connects the address
+// translator to its low module, also letting the accesses to the constant
+// memory go to the constant cache.
+func (b *shaderArrayBuilder) constantCacheConnector(
+	constantCache *writearound.Comp,
+) func(sim.Port, func(mem.AddressToPortMapper), sim.Port) {
+	return func(
+		client sim.Port,
+		setClientMapper func(mem.AddressToPortMapper),
+		lowModule sim.Port,
+	) {
+		constantCacheTop := constantCache.GetPortByName("Top")
+		setClientMapper(&constantAddressMapper{
+			router:        b.constantMemoryRouter,
+			constantCache: constantCacheTop.AsRemote(),
+			lowModule: &mem.SinglePortMapper{
+				Port: lowModule.AsRemote(),
+			},
+		})
+
+		conn := b.connectWithDirectConnection(lowModule, client, 8)
+		conn.PlugIn(constantCacheTop)
+	}
+}
+
 func (b *shaderArrayBuilder) connectWithDirectConnection(
 	port1, port2 sim.Port,
 	bufferSize int,
-) {
+) *directconnection.Comp {
 	name := fmt.Sprintf("%s.Conn[%d]", b.name, b.connectionCount)
 	b.connectionCount++
 
@@ -267,20 +603,73 @@ func (b *shaderArrayBuilder) connectWithDirectConnection(
 
 	conn.PlugIn(port1)
 	conn.PlugIn(port2)
+
+	return conn
+}
+
+// connectWithLatencyConnection connects a memory component to the lower-level
+// memory with a connection that delays the requests by the given cycles.
+func (b *shaderArrayBuilder) connectWithLatencyConnection(
+	upper, lower sim.Port,
+	latency int,
+) {
+	name := fmt.Sprintf("%s.Conn[%d]", b.name, b.connectionCount)
+	b.connectionCount++
+
+	conn := newLatencyConnection(name, b.engine, b.freq, latency)
+	conn.PlugIn(upper)
+	conn.PlugIn(lower)
 }
 
 func (b *shaderArrayBuilder) buildCUs(sa *shaderArray) {
 	cuBuilder := cu.MakeBuilder().
 		WithEngine(b.engine).
 		WithFreq(b.freq).
+		WithSIMDCount(b.numSIMDPerCU).
+		WithWavefrontSchedulingPolicy(b.wfSchedulingPolicy).
 		WithLog2CachelineSize(b.log2CacheLineSize)
 
+	if b.numVGPRBanks > 0 {
+		cuBuilder = cuBuilder.WithBankedRegisterFile(
+			b.numVGPRBanks, b.numVGPRBankPorts)
+	}
+
+	if b.ldsSizePerCU > 0 {
+		cuBuilder = cuBuilder.WithLDSByteSize(b.ldsSizePerCU)
+	}
+
+	if b.wavefrontSize > 0 {
+		cuBuilder = cuBuilder.WithWavefrontSize(b.wavefrontSize)
+	}
+
+	if b.wfSlotsPerSIMD > 0 {
+		cuBuilder = cuBuilder.WithWavefrontSlotsPerSIMD(b.wfSlotsPerSIMD)
+	}
+
+	if b.sfuLatencyTable != nil {
+		cuBuilder = cuBuilder.WithSFULatencyTable(b.sfuLatencyTable)
+	}
+
+	if b.scalarMSHREntries > 0 {
+		cuBuilder = cuBuilder.WithScalarMSHREntries(b.scalarMSHREntries)
+	}
+
+	if b.barrierLatency > 0 {
+		cuBuilder = cuBuilder.WithBarrierLatency(
+			scaleLatency(b.barrierLatency, b.timingScale))
+	}
+
+	if b.faultPageTable != nil {
+		cuBuilder = cuBuilder.WithFaultDetection(b.faultPageTable)
+	}
+
 	for i := 0; i < b.numCU; i++ {
 		cuName := fmt.Sprintf("%s.CU[%d]", b.name, i)
 		computeUnit := cuBuilder.Build(cuName)
 		sa.cus = append(sa.cus, computeUnit)
 
-		if b.isaDebugging {
+		isTargetCU := b.isaDebugWf != nil && b.isaDebugWf.cuIndex == i
+		if b.isaDebugging || isTargetCU {
 			isaDebug, err := os.Create(
 				fmt.Sprintf("isa_%s.debug", cuName))
 			if err != nil {
@@ -289,6 +678,11 @@ func (b *shaderArrayBuilder) buildCUs(sa *shaderArray) {
 			isaDebugger := cu.NewISADebugger(
 				log.New(isaDebug, "", 0), computeUnit)
 
+			if isTargetCU {
+				isaDebugger.OnlyLogWavefront(
+					b.isaDebugWf.wgID, b.isaDebugWf.wfID)
+			}
+
 			tracing.CollectTrace(computeUnit, isaDebugger)
 		}
 
@@ -321,7 +715,8 @@ func (b *shaderArrayBuilder) buildL1VAddressTranslators(sa *shaderArray) {
 		WithEngine(b.engine).
 		WithFreq(b.freq).
 		WithDeviceID(b.gpuID).
-		WithLog2PageSize(b.log2PageSize)
+		WithLog2PageSize(b.log2PageSize).
+		WithTranslationValidation(b.pageTable)
 
 	for i := 0; i < b.numCU; i++ {
 		name := fmt.Sprintf("%s.L1VAddrTrans[%d]", b.name, i)
@@ -339,9 +734,17 @@ func (b *shaderArrayBuilder) buildL1VTLBs(sa *shaderArray) {
 		WithEngine(b.engine).
 		WithFreq(b.freq).
 		WithNumMSHREntry(4).
-		WithNumSets(1).
-		WithNumWays(64).
-		WithNumReqPerCycle(4)
+		WithNumSets(b.l1TLBNumSets).
+		WithNumWays(b.l1TLBNumWays).
+		WithNumReqPerCycle(4).
+		WithPageSize(1 << b.log2PageSize).
+		WithHugePageSize(b.hugePageSize()).
+		WithLatency(scaleLatency(4, b.timingScale)).
+		WithShootdownLatency(b.tlbShootdownCycles)
+
+	if b.idealTLBPageTable != nil {
+		builder = builder.WithIdealTranslation(b.idealTLBPageTable)
+	}
 
 	for i := 0; i < b.numCU; i++ {
 		name := fmt.Sprintf("%s.L1VTLB[%d]", b.name, i)
@@ -358,13 +761,18 @@ func (b *shaderArrayBuilder) buildL1VCaches(sa *shaderArray) {
 	builder := writearound.NewBuilder().
 		WithEngine(b.engine).
 		WithFreq(b.freq).
-		WithBankLatency(60).
+		WithBankLatency(scaleLatency(60, b.timingScale)).
 		WithNumBanks(1).
 		WithLog2BlockSize(b.log2CacheLineSize).
 		WithWayAssociativity(4).
 		WithNumMSHREntry(16).
+		WithNumPorts(b.l1vNumPorts).
 		WithTotalByteSize(16 * mem.KB)
 
+	if b.l1vVictimCacheSize > 0 {
+		builder = builder.WithEvictionForwarding()
+	}
+
 	if b.visTracer != nil {
 		builder = builder.WithVisTracer(b.visTracer)
 	}
@@ -380,6 +788,164 @@ func (b *shaderArrayBuilder) buildL1VCaches(sa *shaderArray) {
 	}
 }
 
+// buildUnifiedL1Cache builds a single L1 cache that serves the vector, scalar,
+// and instruction accesses of all the CUs in the shader array. It has the
+// capacity of all the L1 caches that it replaces, one bank per CU, and the
+// latency of the L1 vector cache.
+func (b *shaderArrayBuilder) buildUnifiedL1Cache(sa *shaderArray) {
+	numClients := b.numCU + 2
+
+	builder := writearound.NewBuilder().
+		WithEngine(b.engine).
+		WithFreq(b.freq).
+		WithBankLatency(scaleLatency(60, b.timingScale)).
+		WithNumBanks(b.numCU).
+		WithLog2BlockSize(b.log2CacheLineSize).
+		WithWayAssociativity(4).
+		WithNumMSHREntry(16 * numClients).
+		WithMaxNumConcurrentTrans(16 * numClients).
+		WithNumReqsPerCycle(4 * b.numCU).
+		WithTotalByteSize(uint64(b.numCU)*16*mem.KB + 48*mem.KB)
+
+	if b.visTracer != nil {
+		builder = builder.WithVisTracer(b.visTracer)
+	}
+
+	name := fmt.Sprintf("%s.L1Cache", b.name)
+	cache := builder.Build(name)
+	sa.l1Cache = cache
+
+	if b.memTracer != nil {
+		tracing.CollectTrace(cache, b.memTracer)
+	}
+
+	sa.l1Conn = directconnection.MakeBuilder().
+		WithEngine(b.engine).
+		WithFreq(b.freq).
+		Build(name + ".TopConn")
+	sa.l1Conn.PlugIn(cache.GetPortByName("Top"))
+}
+
+// buildL0Caches builds a small cache inside each CU, which the vector memory
+// accesses go through before reaching the L1 vector cache.
+func (b *shaderArrayBuilder) buildL0Caches(sa *shaderArray) {
+	if b.l0CacheSize == 0 {
+		return
+	}
+
+	builder := writearound.NewBuilder().
+		WithEngine(b.engine).
+		WithFreq(b.freq).
+		WithBankLatency(scaleLatency(4, b.timingScale)).
+		WithNumBanks(1).
+		WithLog2BlockSize(b.log2CacheLineSize).
+		WithWayAssociativity(4).
+		WithNumMSHREntry(8).
+		WithTotalByteSize(b.l0CacheSize)
+
+	if b.visTracer != nil {
+		builder = builder.WithVisTracer(b.visTracer)
+	}
+
+	for i := 0; i < b.numCU; i++ {
+		name := fmt.Sprintf("%s.CU[%d].L0Cache", b.name, i)
+		cache := builder.Build(name)
+		sa.l0Caches = append(sa.l0Caches, cache)
+
+		if b.memTracer != nil {
+			tracing.CollectTrace(cache, b.memTracer)
+		}
+	}
+}
+
+// buildConstantCaches builds a cache inside each CU that serves the accesses
+// to the constant memory. As the vector memory unit coalesces the accesses of
+// a wavefront to the same cache line, a uniform access of all the lanes is a
+// single access to the constant cache.
+func (b *shaderArrayBuilder) buildConstantCaches(sa *shaderArray) {
+	if b.constantCacheSize == 0 {
+		return
+	}
+
+	builder := writearound.NewBuilder().
+		WithEngine(b.engine).
+		WithFreq(b.freq).
+		WithBankLatency(scaleLatency(4, b.timingScale)).
+		WithNumBanks(1).
+		WithLog2BlockSize(b.log2CacheLineSize).
+		WithWayAssociativity(4).
+		WithNumMSHREntry(8).
+		WithTotalByteSize(b.constantCacheSize)
+
+	if b.visTracer != nil {
+		builder = builder.WithVisTracer(b.visTracer)
+	}
+
+	for i := 0; i < b.numCU; i++ {
+		name := fmt.Sprintf("%s.CU[%d].ConstantCache", b.name, i)
+		cache := builder.Build(name)
+		sa.constantCaches = append(sa.constantCaches, cache)
+
+		if b.memTracer != nil {
+			tracing.CollectTrace(cache, b.memTracer)
+		}
+	}
+}
+
+// buildL1VWriteCombiningBuffers builds a buffer in front of each L1 vector
+// cache, which combines the writes to the same cache line.
+func (b *shaderArrayBuilder) buildL1VWriteCombiningBuffers(sa *shaderArray) {
+	if b.wcbDepth == 0 {
+		return
+	}
+
+	builder := writecombining.MakeBuilder().
+		WithEngine(b.engine).
+		WithFreq(b.freq).
+		WithLog2BlockSize(b.log2CacheLineSize).
+		WithDepth(b.wcbDepth)
+
+	for i := 0; i < b.numCU; i++ {
+		name := fmt.Sprintf("%s.L1VWCB[%d]", b.name, i)
+		wcb := builder.Build(name)
+		sa.l1vWCBs = append(sa.l1vWCBs, wcb)
+
+		if b.visTracer != nil {
+			tracing.CollectTrace(wcb, b.visTracer)
+		}
+
+		if b.memTracer != nil {
+			tracing.CollectTrace(wcb, b.memTracer)
+		}
+	}
+}
+
+func (b *shaderArrayBuilder) buildL1VVictimCaches(sa *shaderArray) {
+	if b.l1vVictimCacheSize == 0 {
+		return
+	}
+
+	builder := victimcache.MakeBuilder().
+		WithEngine(b.engine).
+		WithFreq(b.freq).
+		WithLog2BlockSize(b.log2CacheLineSize).
+		WithByteSize(b.l1vVictimCacheSize)
+
+	for i := 0; i < b.numCU; i++ {
+		name := fmt.Sprintf("%s.L1VVictimCache[%d]", b.name, i)
+		victimCache := builder.Build(name)
+		sa.l1vVictimCaches = append(sa.l1vVictimCaches, victimCache)
+
+		if b.visTracer != nil {
+			tracing.CollectTrace(victimCache, b.visTracer)
+		}
+
+		if b.memTracer != nil {
+			tracing.CollectTrace(victimCache, b.memTracer)
+		}
+	}
+}
+
 func (b *shaderArrayBuilder) buildL1SReorderBuffer(sa *shaderArray) {
 	builder := rob.MakeBuilder().
 		WithEngine(b.engine).
@@ -401,7 +967,8 @@ func (b *shaderArrayBuilder) buildL1SAddressTranslator(sa *shaderArray) {
 		WithEngine(b.engine).
 		WithFreq(b.freq).
 		WithDeviceID(b.gpuID).
-		WithLog2PageSize(b.log2PageSize)
+		WithLog2PageSize(b.log2PageSize).
+		WithTranslationValidation(b.pageTable)
 
 	name := fmt.Sprintf("%s.L1SAddrTrans", b.name)
 	at := builder.Build(name)
@@ -417,9 +984,17 @@ func (b *shaderArrayBuilder) buildL1STLB(sa *shaderArray) {
 		WithEngine(b.engine).
 		WithFreq(b.freq).
 		WithNumMSHREntry(4).
-		WithNumSets(1).
-		WithNumWays(64).
-		WithNumReqPerCycle(4)
+		WithNumSets(b.l1TLBNumSets).
+		WithNumWays(b.l1TLBNumWays).
+		WithNumReqPerCycle(4).
+		WithPageSize(1 << b.log2PageSize).
+		WithHugePageSize(b.hugePageSize()).
+		WithLatency(scaleLatency(4, b.timingScale)).
+		WithShootdownLatency(b.tlbShootdownCycles)
+
+	if b.idealTLBPageTable != nil {
+		builder = builder.WithIdealTranslation(b.idealTLBPageTable)
+	}
 
 	name := fmt.Sprintf("%s.L1STLB", b.name)
 	tlb := builder.Build(name)
@@ -431,14 +1006,19 @@ func (b *shaderArrayBuilder) buildL1STLB(sa *shaderArray) {
 }
 
 func (b *shaderArrayBuilder) buildL1SCache(sa *shaderArray) {
+	numMSHREntry := 16
+	if b.scalarMSHREntries > 0 {
+		numMSHREntry = b.scalarMSHREntries
+	}
+
 	builder := writethrough.NewBuilder().
 		WithEngine(b.engine).
 		WithFreq(b.freq).
-		WithBankLatency(1).
+		WithBankLatency(scaleLatency(1, b.timingScale)).
 		WithNumBanks(1).
 		WithLog2BlockSize(b.log2CacheLineSize).
 		WithWayAssociativity(4).
-		WithNumMSHREntry(16).
+		WithNumMSHREntry(numMSHREntry).
 		WithTotalByteSize(16 * mem.KB)
 
 	name := fmt.Sprintf("%s.L1SCache", b.name)
@@ -475,7 +1055,8 @@ func (b *shaderArrayBuilder) buildL1IAddressTranslator(sa *shaderArray) {
 		WithEngine(b.engine).
 		WithFreq(b.freq).
 		WithDeviceID(b.gpuID).
-		WithLog2PageSize(b.log2PageSize)
+		WithLog2PageSize(b.log2PageSize).
+		WithTranslationValidation(b.pageTable)
 
 	name := fmt.Sprintf("%s.L1IAddrTrans", b.name)
 	at := builder.Build(name)
@@ -491,9 +1072,17 @@ func (b *shaderArrayBuilder) buildL1ITLB(sa *shaderArray) {
 		WithEngine(b.engine).
 		WithFreq(b.freq).
 		WithNumMSHREntry(4).
-		WithNumSets(1).
-		WithNumWays(64).
-		WithNumReqPerCycle(4)
+		WithNumSets(b.l1TLBNumSets).
+		WithNumWays(b.l1TLBNumWays).
+		WithNumReqPerCycle(4).
+		WithPageSize(1 << b.log2PageSize).
+		WithHugePageSize(b.hugePageSize()).
+		WithLatency(scaleLatency(4, b.timingScale)).
+		WithShootdownLatency(b.tlbShootdownCycles)
+
+	if b.idealTLBPageTable != nil {
+		builder = builder.WithIdealTranslation(b.idealTLBPageTable)
+	}
 
 	name := fmt.Sprintf("%s.L1ITLB", b.name)
 	tlb := builder.Build(name)
@@ -508,7 +1097,7 @@ func (b *shaderArrayBuilder) buildL1ICache(sa *shaderArray) {
 	builder := writethrough.NewBuilder().
 		WithEngine(b.engine).
 		WithFreq(b.freq).
-		WithBankLatency(1).
+		WithBankLatency(scaleLatency(1, b.timingScale)).
 		WithNumBanks(1).
 		WithLog2BlockSize(b.log2CacheLineSize).
 		WithWayAssociativity(4).
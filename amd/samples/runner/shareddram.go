@@ -0,0 +1,52 @@
+package runner
+
+import (
+	"fmt"
+
+	"github.com/sarchlab/akita/v4/sim/directconnection"
+	"github.com/sarchlab/akita/v4/tracing"
+	"github.com/sarchlab/mgpusim/v4/amd/timing/dram"
+)
+
+// A SharedDRAMPool is a group of memory controllers that serve multiple GPUs.
+// All the L2 caches, DMA engines, and page migration controllers of the GPUs
+// that use the pool are connected to the memory controllers in the pool
+// through a single connection.
+type SharedDRAMPool struct {
+	Controllers []*dram.Comp
+
+	conn *directconnection.Comp
+}
+
+// BuildSharedDRAMPool creates a shared DRAM pool. The pool has the same number
+// of memory controllers as the number of memory banks of the GPUs to build.
+// The capacity is the total number of bytes provided by the pool.
+func (b R9NanoGPUBuilder) BuildSharedDRAMPool(
+	name string,
+	capacity uint64,
+) *SharedDRAMPool {
+	pool := &SharedDRAMPool{}
+
+	pool.conn = directconnection.MakeBuilder().
+		WithEngine(b.engine).
+		WithFreq(b.freq).
+		Build(name + ".Conn")
+
+	memCtrlBuilder := b.createDramControllerBuilder(capacity)
+	for i := 0; i < b.numMemoryBank; i++ {
+		dramName := fmt.Sprintf("%s.DRAM[%d]", name, i)
+		dram := memCtrlBuilder.Build(dramName)
+		pool.Controllers = append(pool.Controllers, dram)
+		pool.conn.PlugIn(dram.GetPortByName("Top"))
+
+		if b.enableMemTracing {
+			tracing.CollectTrace(dram, b.memTracer)
+		}
+
+		if b.monitor != nil {
+			b.monitor.RegisterComponent(dram)
+		}
+	}
+
+	return pool
+}
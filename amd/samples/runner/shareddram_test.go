@@ -0,0 +1,110 @@
+package runner
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/sarchlab/akita/v4/mem/mem"
+	"github.com/sarchlab/akita/v4/tracing"
+	"github.com/sarchlab/mgpusim/v4/amd/kernels"
+)
+
+var _ = Describe("Shared DRAM Pool", func() {
+	It("should let the GPUs read each other's pages", func() {
+		const numItems = 4096
+
+		platform := MakeR9NanoBuilder().
+			WithNumGPU(2).
+			WithSharedDRAMPool().
+			Build()
+
+		gpu1 := platform.GPUs[0]
+		gpu2 := platform.GPUs[1]
+		Expect(gpu1.MemControllers).NotTo(BeEmpty())
+		Expect(gpu2.MemControllers).To(Equal(gpu1.MemControllers))
+
+		gpuDriver := platform.Driver
+		gpuDriver.Run()
+		defer gpuDriver.Terminate()
+
+		data := make([]uint32, numItems)
+		for i := range data {
+			data[i] = uint32(i * 7)
+		}
+
+		ctx := gpuDriver.Init()
+		hsaco := kernels.LoadProgram("testdata/copy.hsaco", "")
+
+		// GPU 1 writes a buffer that it owns.
+		gpuDriver.SelectGPU(ctx, 1)
+		src := gpuDriver.AllocateMemory(ctx, numItems*4)
+		mid := gpuDriver.AllocateMemory(ctx, numItems*4)
+		gpuDriver.MemCopyH2D(ctx, src, data)
+		gpuDriver.LaunchKernel(ctx, hsaco,
+			[3]uint32{numItems, 1, 1}, [3]uint16{64, 1, 1},
+			&memoryBoundArgs{In: src, Out: mid})
+
+		// Copying to the host flushes the L2 caches, so that the written
+		// data reaches the pool.
+		gpuDriver.MemCopyD2H(ctx, make([]uint32, numItems), mid)
+
+		// GPU 2 reads the pages that GPU 1 wrote.
+		gpuDriver.SelectGPU(ctx, 2)
+		dst := gpuDriver.AllocateMemory(ctx, numItems*4)
+		gpuDriver.LaunchKernel(ctx, hsaco,
+			[3]uint32{numItems, 1, 1}, [3]uint16{64, 1, 1},
+			&memoryBoundArgs{In: mid, Out: dst})
+
+		retData := make([]uint32, numItems)
+		gpuDriver.MemCopyD2H(ctx, retData, dst)
+		Expect(retData).To(Equal(data))
+
+		for gpuID := 1; gpuID <= 2; gpuID++ {
+			traffic := gpuDriver.GetRDMATraffic(gpuID)
+			Expect(traffic.NumReqSent).To(BeZero())
+			Expect(traffic.NumReqReceived).To(BeZero())
+		}
+	})
+
+	It("should let the GPUs share the data in a shared LLC", func() {
+		const byteSize = 16384
+
+		platform := MakeR9NanoBuilder().
+			WithNumGPU(2).
+			WithSharedLLC(4 * mem.MB).
+			Build()
+		Expect(platform.SharedLLC).NotTo(BeNil())
+
+		hitTracer := tracing.NewStepCountTracer(
+			func(task tracing.Task) bool { return true })
+		for _, bank := range platform.SharedLLC.Banks {
+			tracing.CollectTrace(bank, hitTracer)
+		}
+
+		data := make([]byte, byteSize)
+		for i := range data {
+			data[i] = byte(i * 7)
+		}
+
+		gpuDriver := platform.Driver
+		gpuDriver.Run()
+		defer gpuDriver.Terminate()
+
+		ctx := gpuDriver.Init()
+		gpuDriver.SelectGPU(ctx, 1)
+		src := gpuDriver.AllocateMemory(ctx, byteSize)
+		gpuDriver.MemCopyH2D(ctx, src, data)
+
+		// GPU 2 reads the data that GPU 1 wrote from the LLC.
+		gpuDriver.SelectGPU(ctx, 2)
+		dst := gpuDriver.AllocateMemory(ctx, byteSize)
+		hitsBefore := hitTracer.GetStepCount("read-hit")
+		gpuDriver.MemCopyD2D(ctx, dst, src, byteSize)
+		Expect(hitTracer.GetStepCount("read-hit")).
+			To(BeNumerically(">", hitsBefore))
+
+		retData := make([]byte, byteSize)
+		gpuDriver.MemCopyD2H(ctx, retData, dst)
+		Expect(retData).To(Equal(data))
+	})
+})
@@ -0,0 +1,78 @@
+package runner
+
+import (
+	"fmt"
+
+	"github.com/sarchlab/akita/v4/mem/mem"
+	"github.com/sarchlab/akita/v4/sim/directconnection"
+	"github.com/sarchlab/akita/v4/tracing"
+	"github.com/sarchlab/mgpusim/v4/amd/timing/writeback"
+)
+
+// A SharedLLC is a last-level cache that is shared by multiple GPUs. It sits
+// between the L2 caches of the GPUs and a shared DRAM pool. The LLC has one
+// bank in front of each memory controller of the pool. The L2 caches, the DMA
+// engines, and the page migration controllers of all the GPUs access the
+// memory through the LLC, so that the LLC never holds stale data and does not
+// need to be flushed.
+type SharedLLC struct {
+	Banks []*writeback.Comp
+
+	conn *directconnection.Comp
+}
+
+// BuildSharedLLC creates a shared LLC in front of the memory controllers of a
+// shared DRAM pool. The byteSize is the total capacity of all the banks.
+func (b R9NanoGPUBuilder) BuildSharedLLC(
+	name string,
+	byteSize uint64,
+	pool *SharedDRAMPool,
+) *SharedLLC {
+	llc := &SharedLLC{}
+
+	llc.conn = directconnection.MakeBuilder().
+		WithEngine(b.engine).
+		WithFreq(b.freq).
+		Build(name + ".Conn")
+
+	numBanks := len(pool.Controllers)
+	bankBuilder := writeback.MakeBuilder().
+		WithEngine(b.engine).
+		WithFreq(b.freq).
+		WithLog2BlockSize(b.log2CacheLineSize).
+		WithWayAssociativity(16).
+		WithByteSize(byteSize / uint64(numBanks)).
+		WithNumMSHREntry(64).
+		WithNumReqPerCycle(16).
+		WithBankLatency(b.scaleLatency(20))
+
+	for i, dram := range pool.Controllers {
+		bankName := fmt.Sprintf("%s.Bank[%d]", name, i)
+		bank := bankBuilder.WithInterleaving(
+			1<<(b.log2MemoryBankInterleavingSize-b.log2CacheLineSize),
+			numBanks,
+			i,
+		).Build(bankName)
+		bank.SetAddressToPortMapper(&mem.SinglePortMapper{
+			Port: dram.GetPortByName("Top").AsRemote(),
+		})
+
+		llc.Banks = append(llc.Banks, bank)
+		llc.conn.PlugIn(bank.GetPortByName("Top"))
+		pool.conn.PlugIn(bank.GetPortByName("Bottom"))
+
+		if b.enableVisTracing {
+			tracing.CollectTrace(bank, b.visTracer)
+		}
+
+		if b.enableMemTracing {
+			tracing.CollectTrace(bank, b.memTracer)
+		}
+
+		if b.monitor != nil {
+			b.monitor.RegisterComponent(bank)
+		}
+	}
+
+	return llc
+}
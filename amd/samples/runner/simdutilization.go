@@ -0,0 +1,19 @@
+package runner
+
+import (
+	"github.com/sarchlab/mgpusim/v4/amd/timing/cu"
+)
+
+// simdUtilizationReporter reports the lane utilization of all the SIMD units
+// of a GPU.
+type simdUtilizationReporter []*cu.SIMDUnit
+
+// SIMDUtilization returns the lane utilization of each SIMD unit.
+func (r simdUtilizationReporter) SIMDUtilization() []float64 {
+	utilization := make([]float64, len(r))
+	for i, simd := range r {
+		utilization[i] = simd.LaneUtilization()
+	}
+
+	return utilization
+}
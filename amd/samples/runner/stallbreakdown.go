@@ -0,0 +1,25 @@
+package runner
+
+import (
+	"github.com/sarchlab/mgpusim/v4/amd/driver"
+	"github.com/sarchlab/mgpusim/v4/amd/timing/cu"
+)
+
+// stallBreakdownReporter reports what the wavefronts of all the CUs of a GPU
+// stall on.
+type stallBreakdownReporter []*cu.ComputeUnit
+
+// StallBreakdown sums up the stall cycles of all the CUs.
+func (r stallBreakdownReporter) StallBreakdown() driver.StallBreakdown {
+	stats := driver.StallBreakdown{}
+	for _, computeUnit := range r {
+		stats.VMem += computeUnit.StallCycles(cu.StallVMem)
+		stats.SMem += computeUnit.StallCycles(cu.StallSMem)
+		stats.Barrier += computeUnit.StallCycles(cu.StallBarrier)
+		stats.Dependency += computeUnit.StallCycles(cu.StallDependency)
+		stats.IssuePort += computeUnit.StallCycles(cu.StallIssuePort)
+		stats.InstFetch += computeUnit.StallCycles(cu.StallInstFetch)
+	}
+
+	return stats
+}
@@ -0,0 +1,107 @@
+package runner
+
+// GPUStats are the activities of a GPU since the start of the simulation.
+type GPUStats struct {
+	// InstCount is the number of instructions that the CUs have issued.
+	InstCount uint64
+
+	// DRAMReadBytes and DRAMWriteBytes are the bytes that the DRAM
+	// controllers have read and written.
+	DRAMReadBytes  uint64
+	DRAMWriteBytes uint64
+
+	// ScratchpadReadBytes and ScratchpadWriteBytes are the part of the DRAM
+	// traffic that accesses the scratchpad regions allocated with
+	// Driver.AllocateScratchpad.
+	ScratchpadReadBytes  uint64
+	ScratchpadWriteBytes uint64
+
+	// Energy is the energy, in joules, that the GPU has consumed. It is 0 if
+	// the GPU does not have a power model.
+	Energy float64
+}
+
+func (s *GPUStats) add(other GPUStats) {
+	s.InstCount += other.InstCount
+	s.DRAMReadBytes += other.DRAMReadBytes
+	s.DRAMWriteBytes += other.DRAMWriteBytes
+	s.ScratchpadReadBytes += other.ScratchpadReadBytes
+	s.ScratchpadWriteBytes += other.ScratchpadWriteBytes
+	s.Energy += other.Energy
+}
+
+// SystemStats are the activities of all the GPUs of the platform since the
+// start of the simulation.
+type SystemStats struct {
+	// GPUStats is the sum of the stats of all the GPUs.
+	GPUStats
+
+	// RDMATransactionCount is the number of requests that the RDMA engines
+	// have sent to other GPUs, and RDMABytes is the bytes that the requests
+	// read or write.
+	RDMATransactionCount uint64
+	RDMABytes            uint64
+}
+
+// GetGPUStats returns the stats of the GPU with the given ID. The first GPU
+// has ID 1. The stats other than the energy are only collected if
+// CollectCounters is set.
+func (r *Runner) GetGPUStats(gpuID int) GPUStats {
+	gpu := r.platform.GPUs[gpuID-1]
+	stats := GPUStats{}
+
+	for _, t := range r.cuCounters {
+		if containsComponent(gpu.CUs, t.cu) {
+			stats.InstCount += t.tracer.count
+		}
+	}
+
+	for _, t := range r.dramCounters {
+		if containsComponent(gpu.MemControllers, t.dram) {
+			t.tracer.Lock()
+			stats.DRAMReadBytes += t.tracer.readSize
+			stats.DRAMWriteBytes += t.tracer.writeSize
+			stats.ScratchpadReadBytes += t.tracer.scratchpadReadSize
+			stats.ScratchpadWriteBytes += t.tracer.scratchpadWriteSize
+			t.tracer.Unlock()
+		}
+	}
+
+	if gpu.EnergyReporter != nil {
+		stats.Energy = gpu.EnergyReporter.EnergyBreakdown().Total()
+	}
+
+	return stats
+}
+
+// GetAggregateStats returns the sum of the stats of all the GPUs, together
+// with the traffic between the GPUs.
+func (r *Runner) GetAggregateStats() SystemStats {
+	stats := SystemStats{}
+
+	for i := range r.platform.GPUs {
+		stats.add(r.GetGPUStats(i + 1))
+	}
+
+	for _, t := range r.rdmaCounters {
+		t.tracer.Lock()
+		stats.RDMATransactionCount += t.tracer.transactionCount
+		stats.RDMABytes += t.tracer.bytes
+		t.tracer.Unlock()
+	}
+
+	return stats
+}
+
+func containsComponent(
+	components []TraceableComponent,
+	c TraceableComponent,
+) bool {
+	for _, component := range components {
+		if component == c {
+			return true
+		}
+	}
+
+	return false
+}
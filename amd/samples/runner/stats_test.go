@@ -0,0 +1,105 @@
+package runner
+
+import (
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/sarchlab/akita/v4/mem/mem"
+	"github.com/sarchlab/akita/v4/sim"
+	"github.com/sarchlab/akita/v4/tracing"
+	"github.com/sarchlab/mgpusim/v4/amd/driver"
+)
+
+// fakeEnergyReporter reports a fixed amount of energy.
+type fakeEnergyReporter struct {
+	energy driver.EnergyBreakdown
+}
+
+func (r fakeEnergyReporter) EnergyBreakdown() driver.EnergyBreakdown {
+	return r.energy
+}
+
+var _ = Describe("Stats", func() {
+	var r *Runner
+
+	// addGPU adds a GPU with a CU and a DRAM controller that have counted the
+	// given instructions and bytes.
+	addGPU := func(numInst, readBytes, writeBytes uint64, energy float64) {
+		id := len(r.platform.GPUs) + 1
+		cu := sim.NewComponentBase(fmt.Sprintf("GPU[%d].CU", id))
+		dram := sim.NewComponentBase(fmt.Sprintf("GPU[%d].DRAM", id))
+
+		r.platform.GPUs = append(r.platform.GPUs, &GPU{
+			CUs:            []TraceableComponent{cu},
+			MemControllers: []TraceableComponent{dram},
+			EnergyReporter: fakeEnergyReporter{
+				energy: driver.EnergyBreakdown{VALU: energy},
+			},
+		})
+		r.cuCounters = append(r.cuCounters, instCountTracer{
+			tracer: &instTracer{count: numInst},
+			cu:     cu,
+		})
+		r.dramCounters = append(r.dramCounters, dramTransactionCountTracer{
+			tracer: &dramTracer{readSize: readBytes, writeSize: writeBytes},
+			dram:   dram,
+		})
+	}
+
+	BeforeEach(func() {
+		r = &Runner{platform: &Platform{}}
+		addGPU(100, 4096, 1024, 1.5)
+		addGPU(200, 8192, 2048, 2.5)
+	})
+
+	It("should report the stats of each GPU", func() {
+		Expect(r.GetGPUStats(2)).To(Equal(GPUStats{
+			InstCount:      200,
+			DRAMReadBytes:  8192,
+			DRAMWriteBytes: 2048,
+			Energy:         2.5,
+		}))
+	})
+
+	It("should sum the stats of the GPUs", func() {
+		tracer := newRDMATrafficTracer()
+		r.rdmaCounters = append(r.rdmaCounters, rdmaTrafficCounter{
+			tracer: tracer,
+		})
+		tracer.StartTask(tracing.Task{
+			Kind: "req_in",
+			Detail: mem.ReadReqBuilder{}.
+				WithSrc("GPU[1].L2Cache[0].BottomPort").
+				WithByteSize(64).
+				Build(),
+		})
+
+		stats := r.GetAggregateStats()
+
+		Expect(stats.GPUStats).To(Equal(GPUStats{
+			InstCount:      300,
+			DRAMReadBytes:  12288,
+			DRAMWriteBytes: 3072,
+			Energy:         4,
+		}))
+		Expect(stats.RDMATransactionCount).To(Equal(uint64(1)))
+		Expect(stats.RDMABytes).To(Equal(uint64(64)))
+	})
+
+	It("should not count the requests from other RDMA engines", func() {
+		tracer := newRDMATrafficTracer()
+
+		tracer.StartTask(tracing.Task{
+			Kind: "req_in",
+			Detail: mem.WriteReqBuilder{}.
+				WithSrc("GPU[2].RDMA.ToOutside").
+				WithData(make([]byte, 64)).
+				Build(),
+		})
+
+		Expect(tracer.transactionCount).To(BeZero())
+		Expect(tracer.bytes).To(BeZero())
+	})
+})
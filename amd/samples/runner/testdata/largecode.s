@@ -0,0 +1,42 @@
+// large_code runs 2048 v_mad_f32 instructions without a loop for each
+// work-item and writes the result to out[gid]. The 16 KB of code does not
+// have any instruction reuse, so each cache line of the code is fetched from
+// the memory once.
+//
+// Assemble with:
+//   llvm-mc -triple amdgcn--amdhsa -mcpu=fiji \
+//     --amdhsa-code-object-version=2 -filetype=obj \
+//     -o largecode.hsaco largecode.s
+
+.hsa_code_object_version 2,1
+.hsa_code_object_isa 8,0,3,"AMD","AMDGPU"
+
+.text
+.amdgpu_hsa_kernel large_code
+large_code:
+  .amd_kernel_code_t
+    enable_sgpr_kernarg_segment_ptr = 1
+    enable_sgpr_workgroup_id_x = 1
+    user_sgpr_count = 2
+    is_ptr64 = 1
+    kernarg_segment_byte_size = 8
+    wavefront_sgpr_count = 16
+    workitem_vgpr_count = 8
+    granulated_workitem_vgpr_count = 1
+    granulated_wavefront_sgpr_count = 1
+  .end_amd_kernel_code_t
+
+  s_load_dwordx2 s[4:5], s[0:1], 0x0
+  s_lshl_b32 s8, s2, 6
+  v_add_u32 v1, vcc, s8, v0
+  v_cvt_f32_u32 v2, v1
+  .rept 2048
+  v_mad_f32 v2, v2, 0.5, 1.0
+  .endr
+  v_lshlrev_b32 v3, 2, v1
+  s_waitcnt lgkmcnt(0)
+  v_mov_b32 v5, s5
+  v_add_u32 v4, vcc, s4, v3
+  v_addc_u32 v5, vcc, 0, v5, vcc
+  flat_store_dword v[4:5], v2
+  s_endpgm
@@ -0,0 +1,42 @@
+// persistent polls the stop flag with an atomic add of 0 until the host sets
+// the flag, and then writes the number of polls to out[0].
+//
+// Assemble with:
+//   llvm-mc -triple amdgcn--amdhsa -mcpu=fiji \
+//     --amdhsa-code-object-version=2 -filetype=obj \
+//     -o persistent.hsaco persistent.s
+
+.hsa_code_object_version 2,1
+.hsa_code_object_isa 8,0,3,"AMD","AMDGPU"
+
+.text
+.amdgpu_hsa_kernel persistent
+persistent:
+  .amd_kernel_code_t
+    enable_sgpr_kernarg_segment_ptr = 1
+    enable_sgpr_workgroup_id_x = 1
+    user_sgpr_count = 2
+    is_ptr64 = 1
+    kernarg_segment_byte_size = 16
+    wavefront_sgpr_count = 16
+    workitem_vgpr_count = 16
+    granulated_workitem_vgpr_count = 3
+    granulated_wavefront_sgpr_count = 1
+  .end_amd_kernel_code_t
+
+  s_load_dwordx4 s[4:7], s[0:1], 0x0
+  v_mov_b32 v4, 0
+  v_mov_b32 v5, 0
+  s_waitcnt lgkmcnt(0)
+  v_mov_b32 v2, s4
+  v_mov_b32 v3, s5
+poll:
+  v_add_u32 v5, vcc, 1, v5
+  flat_atomic_add v6, v[2:3], v4 glc
+  s_waitcnt vmcnt(0)
+  v_cmp_eq_u32 vcc, 0, v6
+  s_cbranch_vccnz poll
+  v_mov_b32 v7, s6
+  v_mov_b32 v8, s7
+  flat_store_dword v[7:8], v5
+  s_endpgm
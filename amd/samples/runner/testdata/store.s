@@ -0,0 +1,35 @@
+// store writes gid to out[gid].
+//
+// Assemble with:
+//   llvm-mc -triple amdgcn--amdhsa -mcpu=fiji \
+//     --amdhsa-code-object-version=2 -filetype=obj \
+//     -o store.hsaco store.s
+
+.hsa_code_object_version 2,1
+.hsa_code_object_isa 8,0,3,"AMD","AMDGPU"
+
+.text
+.amdgpu_hsa_kernel store
+store:
+  .amd_kernel_code_t
+    enable_sgpr_kernarg_segment_ptr = 1
+    enable_sgpr_workgroup_id_x = 1
+    user_sgpr_count = 2
+    is_ptr64 = 1
+    kernarg_segment_byte_size = 8
+    wavefront_sgpr_count = 16
+    workitem_vgpr_count = 8
+    granulated_workitem_vgpr_count = 1
+    granulated_wavefront_sgpr_count = 1
+  .end_amd_kernel_code_t
+
+  s_load_dwordx2 s[4:5], s[0:1], 0x0
+  s_lshl_b32 s8, s2, 6
+  v_add_u32 v0, vcc, s8, v0
+  v_lshlrev_b32 v1, 2, v0
+  s_waitcnt lgkmcnt(0)
+  v_mov_b32 v3, s5
+  v_add_u32 v2, vcc, s4, v1
+  v_addc_u32 v3, vcc, 0, v3, vcc
+  flat_store_dword v[2:3], v0
+  s_endpgm
@@ -0,0 +1,156 @@
+package runner
+
+import (
+	"math"
+	"sync"
+
+	"github.com/sarchlab/akita/v4/sim"
+	"github.com/sarchlab/akita/v4/tracing"
+	"github.com/sarchlab/mgpusim/v4/amd/timing/cu"
+)
+
+// A ThermalModel sets how the temperature of a GPU follows the energy that the
+// power model estimates, and how the GPU throttles when it is too hot. The GPU
+// is a single thermal node that has a heat capacity and a thermal resistance to
+// the ambient. While throttled, the CUs run at a reduced frequency.
+type ThermalModel struct {
+	// AmbientTemperature is the temperature, in degrees Celsius, that the GPU
+	// cools down to when it is idle.
+	AmbientTemperature float64
+
+	// ThermalResistance is the temperature rise, in degrees Celsius per watt,
+	// that a constant power causes in the steady state.
+	ThermalResistance float64
+
+	// HeatCapacity is the energy, in joules, that raises the temperature by 1
+	// degree Celsius.
+	HeatCapacity float64
+
+	// ThrottleTemperature is the temperature, in degrees Celsius, above which
+	// the GPU throttles.
+	ThrottleTemperature float64
+
+	// ThrottleHysteresis is how much, in degrees Celsius, the temperature has
+	// to drop below ThrottleTemperature for the GPU to stop throttling.
+	ThrottleHysteresis float64
+
+	// ThrottledFreqScale is the fraction of the full frequency that the CUs run
+	// at while the GPU throttles.
+	ThrottledFreqScale float64
+}
+
+// DefaultThermalModel returns a thermal model with rough values of a GPU with
+// an air cooler. The heat capacity is so large that only long simulations heat
+// the GPU up.
+func DefaultThermalModel() ThermalModel {
+	return ThermalModel{
+		AmbientTemperature:  45,
+		ThermalResistance:   0.25,
+		HeatCapacity:        0.05,
+		ThrottleTemperature: 85,
+		ThrottleHysteresis:  5,
+		ThrottledFreqScale:  0.75,
+	}
+}
+
+// A thermalTracer updates the temperature of a GPU whenever the energy tracer
+// of the GPU adds energy. The energy of an activity heats the GPU up at once,
+// and the GPU cools down exponentially between the activities. The tracer
+// scales the frequency of the CUs when the GPU starts or stops throttling.
+type thermalTracer struct {
+	model      ThermalModel
+	energy     *energyTracer
+	timeTeller sim.TimeTeller
+	cus        []*cu.ComputeUnit
+	fullFreq   sim.Freq
+
+	lock        sync.Mutex
+	temperature float64
+	lastEnergy  float64
+	lastTime    sim.VTimeInSec
+	throttled   bool
+}
+
+func newThermalTracer(
+	model ThermalModel,
+	energy *energyTracer,
+	timeTeller sim.TimeTeller,
+	cus []*cu.ComputeUnit,
+	fullFreq sim.Freq,
+) *thermalTracer {
+	return &thermalTracer{
+		model:       model,
+		energy:      energy,
+		timeTeller:  timeTeller,
+		cus:         cus,
+		fullFreq:    fullFreq,
+		temperature: model.AmbientTemperature,
+	}
+}
+
+// Temperature returns the temperature of the GPU at the current time.
+func (t *thermalTracer) Temperature() float64 {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	return t.cooledTemperature(t.timeTeller.CurrentTime())
+}
+
+func (t *thermalTracer) cooledTemperature(now sim.VTimeInSec) float64 {
+	elapsed := float64(now - t.lastTime)
+	timeConstant := t.model.ThermalResistance * t.model.HeatCapacity
+	ambient := t.model.AmbientTemperature
+
+	return ambient + (t.temperature-ambient)*math.Exp(-elapsed/timeConstant)
+}
+
+// StartTask adds the energy that the energy tracer counts for the task to the
+// temperature. The energy tracer must hook to the component before the thermal
+// tracer.
+func (t *thermalTracer) StartTask(_ tracing.Task) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	now := t.timeTeller.CurrentTime()
+	energy := t.energy.EnergyBreakdown().Total()
+
+	t.temperature = t.cooledTemperature(now) +
+		(energy-t.lastEnergy)/t.model.HeatCapacity
+	t.lastEnergy = energy
+	t.lastTime = now
+
+	t.updateThrottling()
+}
+
+func (t *thermalTracer) updateThrottling() {
+	switch {
+	case !t.throttled && t.temperature > t.model.ThrottleTemperature:
+		t.throttled = true
+		t.setCUFreq(sim.Freq(float64(t.fullFreq) * t.model.ThrottledFreqScale))
+	case t.throttled && t.temperature <
+		t.model.ThrottleTemperature-t.model.ThrottleHysteresis:
+		t.throttled = false
+		t.setCUFreq(t.fullFreq)
+	}
+}
+
+func (t *thermalTracer) setCUFreq(freq sim.Freq) {
+	for _, c := range t.cus {
+		c.Freq = freq
+	}
+}
+
+// StepTask does nothing.
+func (t *thermalTracer) StepTask(_ tracing.Task) {
+	// Do nothing
+}
+
+// AddMilestone does nothing.
+func (t *thermalTracer) AddMilestone(_ tracing.Milestone) {
+	// Do nothing
+}
+
+// EndTask does nothing.
+func (t *thermalTracer) EndTask(_ tracing.Task) {
+	// Do nothing
+}
@@ -0,0 +1,92 @@
+package runner
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/sarchlab/akita/v4/sim"
+	"github.com/sarchlab/akita/v4/tracing"
+	"github.com/sarchlab/mgpusim/v4/amd/timing/cu"
+)
+
+var _ = Describe("Thermal Tracer", func() {
+	var (
+		timeTeller  *fakeTimeTeller
+		computeUnit *cu.ComputeUnit
+		energy      *energyTracer
+		tracer      *thermalTracer
+	)
+
+	BeforeEach(func() {
+		timeTeller = &fakeTimeTeller{}
+		computeUnit = &cu.ComputeUnit{
+			TickingComponent: sim.NewTickingComponent(
+				"CU", sim.NewSerialEngine(), 1*sim.GHz, idleTicker{}),
+		}
+
+		// Each VALU instruction heats the GPU up by 10 degrees, and the GPU
+		// cools down with a time constant of 1 us.
+		energy = newEnergyTracer(PowerModel{VALUInstEnergy: 1e-6})
+		tracer = newThermalTracer(ThermalModel{
+			AmbientTemperature:  45,
+			ThermalResistance:   10,
+			HeatCapacity:        1e-7,
+			ThrottleTemperature: 55,
+			ThrottleHysteresis:  1,
+			ThrottledFreqScale:  0.5,
+		}, energy, timeTeller, []*cu.ComputeUnit{computeUnit}, 1*sim.GHz)
+	})
+
+	// runInstsAt traces VALU instructions at the given time. As in the GPU,
+	// the thermal tracer sees each task after the energy tracer.
+	runInstsAt := func(now sim.VTimeInSec, n int) {
+		timeTeller.now = now
+		for i := 0; i < n; i++ {
+			task := tracing.Task{Kind: "inst", What: "VALU"}
+			energy.StartTask(task)
+			tracer.StartTask(task)
+		}
+	}
+
+	It("should start at the ambient temperature", func() {
+		Expect(tracer.Temperature()).To(Equal(45.0))
+	})
+
+	It("should heat up with the energy of the activities", func() {
+		runInstsAt(0, 1)
+
+		Expect(tracer.Temperature()).To(BeNumerically("~", 55, 1e-9))
+		Expect(computeUnit.Freq).To(Equal(1 * sim.GHz))
+	})
+
+	It("should cool down exponentially", func() {
+		runInstsAt(0, 2)
+		timeTeller.now = 1e-6
+
+		// 45 + 20 / e
+		Expect(tracer.Temperature()).To(BeNumerically("~", 52.358, 1e-3))
+	})
+
+	It("should throttle the CUs above the throttle temperature", func() {
+		runInstsAt(0, 2)
+
+		Expect(computeUnit.Freq).To(Equal(500 * sim.MHz))
+	})
+
+	It("should keep throttling within the hysteresis", func() {
+		runInstsAt(0, 2)
+		timeTeller.now = 0.7e-6
+		tracer.StartTask(tracing.Task{Kind: "wavefront"})
+
+		Expect(tracer.Temperature()).To(BeNumerically(">", 54))
+		Expect(computeUnit.Freq).To(Equal(500 * sim.MHz))
+	})
+
+	It("should stop throttling after cooling down", func() {
+		runInstsAt(0, 2)
+		timeTeller.now = 5e-6
+		tracer.StartTask(tracing.Task{Kind: "wavefront"})
+
+		Expect(computeUnit.Freq).To(Equal(1 * sim.GHz))
+	})
+})
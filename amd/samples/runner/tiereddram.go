@@ -0,0 +1,62 @@
+package runner
+
+import (
+	"github.com/sarchlab/akita/v4/mem/mem"
+	"github.com/sarchlab/akita/v4/sim"
+	"github.com/sarchlab/mgpusim/v4/amd/timing/dram"
+)
+
+// slowDRAMTierLatencyFactor is how many times longer the row and column
+// timing parameters of the capacity tier are than those of the stacked tier.
+const slowDRAMTierLatencyFactor = 3
+
+// A tieredAddressPortMapper sends the addresses below a boundary to the fast
+// DRAM tier and the other addresses to the slow DRAM tier.
+type tieredAddressPortMapper struct {
+	boundary uint64
+	fast     mem.AddressToPortMapper
+	slow     mem.AddressToPortMapper
+}
+
+// Find returns the memory controller that serves the address.
+func (m tieredAddressPortMapper) Find(address uint64) sim.RemotePort {
+	if address < m.boundary {
+		return m.fast.Find(address)
+	}
+
+	return m.slow.Find(address)
+}
+
+// isDRAMTiered checks if the GPU has a fast and a slow DRAM tier.
+func (b *R9NanoGPUBuilder) isDRAMTiered() bool {
+	return b.dramFastTierSize > 0
+}
+
+// dramTierBoundary returns the first address of the slow DRAM tier.
+func (b *R9NanoGPUBuilder) dramTierBoundary() uint64 {
+	return b.memAddrOffset + b.dramFastTierSize
+}
+
+// createSlowTierDRAMControllerBuilder returns the builder of the memory
+// controllers of the capacity tier, which share the organization of the
+// stacked tier but take longer to open, read, and close the rows.
+func (b *R9NanoGPUBuilder) createSlowTierDRAMControllerBuilder(
+	capacity uint64,
+) dram.Builder {
+	slowLatency := func(cycles int) int {
+		return b.scaleLatency(cycles * slowDRAMTierLatencyFactor)
+	}
+
+	tCL := slowLatency(7)
+	if b.eccEnabled {
+		tCL += b.scaleLatency(b.eccLatencyPenalty)
+	}
+
+	return b.createDramControllerBuilder(capacity).
+		WithTCL(tCL).
+		WithTCWL(slowLatency(2)).
+		WithTRCDRD(slowLatency(7)).
+		WithTRCDWR(slowLatency(7)).
+		WithTRP(slowLatency(7)).
+		WithTRAS(slowLatency(17))
+}
@@ -0,0 +1,23 @@
+package runner
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/sarchlab/akita/v4/mem/mem"
+	"github.com/sarchlab/akita/v4/sim"
+)
+
+var _ = Describe("Tiered Address Port Mapper", func() {
+	It("should send the addresses to the tier that holds them", func() {
+		mapper := tieredAddressPortMapper{
+			boundary: 0x1000,
+			fast:     &mem.SinglePortMapper{Port: "Fast"},
+			slow:     &mem.SinglePortMapper{Port: "Slow"},
+		}
+
+		Expect(mapper.Find(0x0)).To(Equal(sim.RemotePort("Fast")))
+		Expect(mapper.Find(0xfff)).To(Equal(sim.RemotePort("Fast")))
+		Expect(mapper.Find(0x1000)).To(Equal(sim.RemotePort("Slow")))
+	})
+})
@@ -9,6 +9,7 @@ import (
 	memtraces "github.com/sarchlab/akita/v4/mem/trace"
 
 	"github.com/sarchlab/akita/v4/analysis"
+	"github.com/sarchlab/akita/v4/mem/idealmemcontroller"
 	"github.com/sarchlab/akita/v4/mem/mem"
 	"github.com/sarchlab/akita/v4/mem/vm"
 	"github.com/sarchlab/akita/v4/mem/vm/mmu"
@@ -17,20 +18,85 @@ import (
 	"github.com/sarchlab/akita/v4/sim"
 	"github.com/sarchlab/akita/v4/tracing"
 	"github.com/sarchlab/mgpusim/v4/amd/driver"
+	"github.com/sarchlab/mgpusim/v4/amd/insts"
+	"github.com/sarchlab/mgpusim/v4/amd/timing/cp"
+	"github.com/sarchlab/mgpusim/v4/amd/timing/cu"
+	"github.com/sarchlab/mgpusim/v4/amd/timing/dram"
+	"github.com/sarchlab/mgpusim/v4/amd/timing/replacement"
 )
 
 // R9NanoPlatformBuilder can build a platform that equips R9Nano GPU.
 type R9NanoPlatformBuilder struct {
 	useParallelEngine                  bool
 	debugISA                           bool
+	isaDebugWf                         *isaDebugWavefront
 	traceVis                           bool
 	traceVisStartTime, traceVisEndTime sim.VTimeInSec
 	traceMem                           bool
+	validateTranslation                bool
+	detectFaults                       bool
+	idealTLB                           bool
 	numGPU                             int
+	numSpareGPU                        int
+	gpuFreqs                           []sim.Freq
 	numSAPerGPU                        int
 	numCUPerSA                         int
+	numSIMDPerCU                       int
+	wfSchedulingPolicy                 cu.WavefrontSchedulingPolicy
+	wgSchedulingPolicy                 cp.WGSchedulingPolicy
+	numVGPRBanks                       int
+	wavefrontSize                      int
+	wfSlotsPerSIMD                     int
+	sfuLatencyTable                    map[insts.Opcode]int
+	barrierLatency                     int
+	interposerLatency                  int
+	l1iMissLatency                     int
+	scalarMSHREntries                  int
+	numVGPRBankPorts                   int
+	ldsSizePerCU                       uint64
+	memRequestInterceptor              MemRequestInterceptor
 	useMagicMemoryCopy                 bool
+	useSharedDRAMPool                  bool
+	sharedLLCSize                      uint64
+	log2CacheLineSize                  uint64
 	log2PageSize                       uint64
+	log2HugePageSize                   uint64
+	pageWalkLatency                    int
+	timingScale                        float64
+	dmaMaxOutstanding                  int
+	eccEnabled                         bool
+	eccBandwidthOverhead               float64
+	eccLatencyPenalty                  int
+	dramRefreshInterval                int
+	dramFaultInjector                  dram.UncorrectableFaultPredicate
+	dramChannelsPerBank                int
+	dramSubChannels                    int
+	dramReadQueueSize                  int
+	dramWriteQueueSize                 int
+	dramOpenPage                       bool
+	dramQoSScheduling                  bool
+	dramScheduler                      dram.SchedulingPolicy
+	dramFastTierSize                   uint64
+	l2ReplacementPolicy                replacement.Policy
+	l2WriteBufferSize                  int
+	l2BankHashMapping                  bool
+	reuseDistanceAnalysis              bool
+	l1vReuseDistanceAnalysis           bool
+	l1vVictimCacheSize                 uint64
+	l1vCoherence                       bool
+	unifiedL1                          bool
+	l0CacheSize                        uint64
+	constantCacheSize                  uint64
+	wcbDepth                           int
+	tlbShootdownLatency                int
+	l1TLBNumSets                       int
+	l1TLBNumWays                       int
+	l1vNumPorts                        int
+	kernelLaunchOverhead               int
+	wgDispatchRate                     int
+	powerModel                         *PowerModel
+	thermalModel                       *ThermalModel
+	interGPUTopology                   InterGPUTopology
 
 	engine               sim.Engine
 	monitor              *monitoring.Monitor
@@ -38,6 +104,10 @@ type R9NanoPlatformBuilder struct {
 	perfAnalyzingPeriod  float64
 	perfAnalyzer         *analysis.PerfAnalyzer
 	visTracer            tracing.Tracer
+	chromeTracer         *ChromeTracer
+	accelSimTracer       *AccelSimTracer
+	criticalPathAnalysis bool
+	criticalPathTracer   *criticalPathTracer
 
 	globalStorage *mem.Storage
 
@@ -47,12 +117,27 @@ type R9NanoPlatformBuilder struct {
 // MakeR9NanoBuilder creates a EmuBuilder with default parameters.
 func MakeR9NanoBuilder() R9NanoPlatformBuilder {
 	b := R9NanoPlatformBuilder{
-		numGPU:            4,
-		numSAPerGPU:       16,
-		numCUPerSA:        4,
-		log2PageSize:      12,
-		traceVisStartTime: -1,
-		traceVisEndTime:   -1,
+		numGPU:               4,
+		numSAPerGPU:          16,
+		numCUPerSA:           4,
+		numSIMDPerCU:         4,
+		ldsSizePerCU:         64 * mem.KB,
+		wavefrontSize:        64,
+		wfSlotsPerSIMD:       10,
+		l2WriteBufferSize:    1024,
+		log2CacheLineSize:    6,
+		log2PageSize:         12,
+		pageWalkLatency:      100,
+		timingScale:          1,
+		dmaMaxOutstanding:    4,
+		eccBandwidthOverhead: DefaultECCBandwidthOverhead,
+		eccLatencyPenalty:    DefaultECCLatencyPenalty,
+		dramChannelsPerBank:  1,
+		dramSubChannels:      1,
+		l1TLBNumSets:         1,
+		l1TLBNumWays:         64,
+		traceVisStartTime:    -1,
+		traceVisEndTime:      -1,
 	}
 	return b
 }
@@ -69,6 +154,47 @@ func (b R9NanoPlatformBuilder) WithISADebugging() R9NanoPlatformBuilder {
 	return b
 }
 
+// WithISADebuggingForWavefront lets the GPUs dump the instruction execution
+// information of a single wavefront, rather than all the wavefronts. The CU is
+// identified by its index in the GPU, the work-group by its ID flattened in
+// the x-major order, and the wavefront by its index in the work-group.
+func (b R9NanoPlatformBuilder) WithISADebuggingForWavefront(
+	cuIndex, wgID, wfID int,
+) R9NanoPlatformBuilder {
+	b.isaDebugWf = &isaDebugWavefront{
+		cuIndex: cuIndex,
+		wgID:    wgID,
+		wfID:    wfID,
+	}
+	return b
+}
+
+// WithTranslationValidation lets the GPUs check the physical address of every
+// memory access against the page table and panic on mismatch. It is off by
+// default as it slows down the simulation.
+func (b R9NanoPlatformBuilder) WithTranslationValidation() R9NanoPlatformBuilder {
+	b.validateTranslation = true
+	return b
+}
+
+// WithFaultDetection lets the CUs of the GPUs check the addresses of the
+// vector memory accesses against the page table, so that the accesses to
+// unmapped addresses are reported by Driver.GetLastKernelError. It is off by
+// default as it looks up the page table on every vector memory transaction.
+func (b R9NanoPlatformBuilder) WithFaultDetection() R9NanoPlatformBuilder {
+	b.detectFaults = true
+	return b
+}
+
+// WithIdealTLB replaces the TLBs of the GPUs with ideal ones, which translate
+// every address in a single cycle and never miss. It removes the translation
+// overhead so that the cache and DRAM behavior can be studied alone. As the
+// MMU is never reached, the unified memory is not migrated.
+func (b R9NanoPlatformBuilder) WithIdealTLB() R9NanoPlatformBuilder {
+	b.idealTLB = true
+	return b
+}
+
 // WithVisTracing lets the platform to record traces for visualization purposes.
 func (b R9NanoPlatformBuilder) WithVisTracing() R9NanoPlatformBuilder {
 	b.traceVis = true
@@ -89,6 +215,35 @@ func (b R9NanoPlatformBuilder) WithPartialVisTracing(
 	return b
 }
 
+// WithChromeTracer lets the platform to record traces for visualization
+// purposes with the given ChromeTracer rather than storing the traces in a
+// database.
+func (b R9NanoPlatformBuilder) WithChromeTracer(
+	t *ChromeTracer,
+) R9NanoPlatformBuilder {
+	b.chromeTracer = t
+
+	return b
+}
+
+// WithCriticalPathAnalysis lets the platform record the task trace in memory,
+// so that Driver.GetKernelCriticalPath can reconstruct the critical paths of
+// the kernels. It cannot be used together with vis tracing or Chrome tracing.
+func (b R9NanoPlatformBuilder) WithCriticalPathAnalysis() R9NanoPlatformBuilder {
+	b.criticalPathAnalysis = true
+	return b
+}
+
+// WithAccelSimTracer lets the platform record the vector memory accesses of
+// all the CUs with the given AccelSimTracer.
+func (b R9NanoPlatformBuilder) WithAccelSimTracer(
+	t *AccelSimTracer,
+) R9NanoPlatformBuilder {
+	b.accelSimTracer = t
+
+	return b
+}
+
 // WithMemTracing lets the platform to trace memory operations.
 func (b R9NanoPlatformBuilder) WithMemTracing() R9NanoPlatformBuilder {
 	b.traceMem = true
@@ -101,6 +256,42 @@ func (b R9NanoPlatformBuilder) WithNumGPU(n int) R9NanoPlatformBuilder {
 	return b
 }
 
+// WithNumSpareGPU builds n more GPUs that are connected to the platform but
+// not registered with the driver. Platform.HotAddGPU registers the spare GPUs
+// one by one while the simulation is running.
+func (b R9NanoPlatformBuilder) WithNumSpareGPU(n int) R9NanoPlatformBuilder {
+	b.numSpareGPU = n
+	return b
+}
+
+// WithGPUFreqs sets the frequency of each GPU, in the order of the GPU IDs.
+// All the components of a GPU tick at the frequency of the GPU, and the shared
+// engine interleaves the events of the GPUs by their time. The GPUs that are
+// not listed run at the default frequency of the GPU builder.
+func (b R9NanoPlatformBuilder) WithGPUFreqs(
+	freqs ...sim.Freq,
+) R9NanoPlatformBuilder {
+	b.gpuFreqs = freqs
+	return b
+}
+
+// WithNumSIMDPerCU sets the number of SIMD units in each CU of all the GPUs.
+func (b R9NanoPlatformBuilder) WithNumSIMDPerCU(n int) R9NanoPlatformBuilder {
+	b.numSIMDPerCU = n
+	return b
+}
+
+// WithLog2CacheLineSize sets the cache line size of all the caches of all the
+// GPUs as a power of 2. The cache line size must not be larger than the memory
+// bank interleaving size, which is 128 bytes, and the DRAM access unit, which
+// is 64 bytes.
+func (b R9NanoPlatformBuilder) WithLog2CacheLineSize(
+	n uint64,
+) R9NanoPlatformBuilder {
+	b.log2CacheLineSize = n
+	return b
+}
+
 // WithLog2PageSize sets the page size as a power of 2.
 func (b R9NanoPlatformBuilder) WithLog2PageSize(
 	n uint64,
@@ -109,6 +300,444 @@ func (b R9NanoPlatformBuilder) WithLog2PageSize(
 	return b
 }
 
+// WithHugePageSize lets the large allocations on the GPUs be backed by huge
+// pages, whose size is given as a power of 2. The TLBs hold the base pages and
+// the huge pages at the same time.
+func (b R9NanoPlatformBuilder) WithHugePageSize(
+	log2 uint64,
+) R9NanoPlatformBuilder {
+	b.log2HugePageSize = log2
+	return b
+}
+
+// WithPageWalkLatency sets the number of cycles that the MMU takes to walk
+// the page table when an L2 TLB miss occurs.
+func (b R9NanoPlatformBuilder) WithPageWalkLatency(
+	cycles int,
+) R9NanoPlatformBuilder {
+	b.pageWalkLatency = cycles
+	return b
+}
+
+// WithTimingScale multiplies all the latency-like parameters of the platform,
+// including the page walk latency, by the given factor. The compute latencies
+// of the CUs are not scaled, so only the memory part of the runtime grows with
+// the factor. See R9NanoGPUBuilder.WithTimingScale for details.
+func (b R9NanoPlatformBuilder) WithTimingScale(
+	factor float64,
+) R9NanoPlatformBuilder {
+	b.timingScale = factor
+	return b
+}
+
+// WithDMAMaxOutstanding sets the number of memory copy requests that the DMA
+// engine of each GPU can process at the same time.
+func (b R9NanoPlatformBuilder) WithDMAMaxOutstanding(
+	n int,
+) R9NanoPlatformBuilder {
+	b.dmaMaxOutstanding = n
+	return b
+}
+
+// WithECCEnabled lets the DRAM controllers of all the GPUs model the overhead
+// of ECC.
+func (b R9NanoPlatformBuilder) WithECCEnabled() R9NanoPlatformBuilder {
+	b.eccEnabled = true
+	return b
+}
+
+// WithECCOverhead sets the fraction of the raw DRAM bandwidth that is used to
+// transfer the ECC bits and the number of DRAM cycles that are added to each
+// read to check the ECC bits. It only takes effect if ECC is enabled.
+func (b R9NanoPlatformBuilder) WithECCOverhead(
+	bandwidthOverhead float64,
+	latencyPenalty int,
+) R9NanoPlatformBuilder {
+	b.eccBandwidthOverhead = bandwidthOverhead
+	b.eccLatencyPenalty = latencyPenalty
+	return b
+}
+
+// WithUncorrectableFaultInjector injects uncorrectable memory errors into the
+// reads of the physical addresses that the predicate selects in the DRAM
+// controllers of all the GPUs. A faulty read returns zeroed data, and the
+// fault is reported by Driver.GetLastKernelError for the kernels that are
+// running on the GPU that owns the DRAM. This models ECC that detects, but
+// cannot correct, an error.
+func (b R9NanoPlatformBuilder) WithUncorrectableFaultInjector(
+	predicate dram.UncorrectableFaultPredicate,
+) R9NanoPlatformBuilder {
+	b.dramFaultInjector = predicate
+	return b
+}
+
+// WithDRAMRefreshInterval sets the number of DRAM cycles between two refreshes
+// of the DRAM controllers of all the GPUs. Setting the interval to 0, which is
+// the default, disables refresh.
+func (b R9NanoPlatformBuilder) WithDRAMRefreshInterval(
+	trefi int,
+) R9NanoPlatformBuilder {
+	b.dramRefreshInterval = trefi
+	return b
+}
+
+// WithDRAMChannelsPerBank sets the number of channels that each DRAM
+// controller of all the GPUs manages.
+func (b R9NanoPlatformBuilder) WithDRAMChannelsPerBank(
+	n int,
+) R9NanoPlatformBuilder {
+	b.dramChannelsPerBank = n
+	return b
+}
+
+// WithDRAMSubChannels sets the number of sub-channels that each DRAM channel
+// of all the GPUs is split into.
+func (b R9NanoPlatformBuilder) WithDRAMSubChannels(
+	n int,
+) R9NanoPlatformBuilder {
+	b.dramSubChannels = n
+	return b
+}
+
+// WithDRAMReadQueueSize sets the number of read sub-transactions that each
+// DRAM controller of all the GPUs can buffer, separately from the writes.
+func (b R9NanoPlatformBuilder) WithDRAMReadQueueSize(
+	n int,
+) R9NanoPlatformBuilder {
+	b.dramReadQueueSize = n
+	return b
+}
+
+// WithDRAMWriteQueueSize sets the number of write sub-transactions that each
+// DRAM controller of all the GPUs can buffer, separately from the reads.
+func (b R9NanoPlatformBuilder) WithDRAMWriteQueueSize(
+	n int,
+) R9NanoPlatformBuilder {
+	b.dramWriteQueueSize = n
+	return b
+}
+
+// WithTieredDRAM splits the DRAM of each GPU into a fast stacked tier that
+// holds the first fastSize bytes of the GPU memory and a slower capacity tier
+// that holds the rest. It cannot be used with a shared DRAM pool.
+func (b R9NanoPlatformBuilder) WithTieredDRAM(
+	fastSize uint64,
+) R9NanoPlatformBuilder {
+	b.dramFastTierSize = fastSize
+	return b
+}
+
+// WithDRAMOpenPagePolicy lets the DRAM controllers of all the GPUs keep the
+// row of a bank open after an access.
+func (b R9NanoPlatformBuilder) WithDRAMOpenPagePolicy() R9NanoPlatformBuilder {
+	b.dramOpenPage = true
+	return b
+}
+
+// WithDRAMQoSScheduling lets the DRAM controllers of all the GPUs serve the
+// accesses to the latency-critical buffers before the other accesses.
+func (b R9NanoPlatformBuilder) WithDRAMQoSScheduling() R9NanoPlatformBuilder {
+	b.dramQoSScheduling = true
+	return b
+}
+
+// WithDRAMScheduler sets the order in which the DRAM controllers of all the
+// GPUs issue the commands.
+func (b R9NanoPlatformBuilder) WithDRAMScheduler(
+	policy dram.SchedulingPolicy,
+) R9NanoPlatformBuilder {
+	b.dramScheduler = policy
+	return b
+}
+
+// WithPowerModel lets all the GPUs estimate the energy that they consume with
+// the given power model.
+func (b R9NanoPlatformBuilder) WithPowerModel(
+	model PowerModel,
+) R9NanoPlatformBuilder {
+	b.powerModel = &model
+	return b
+}
+
+// WithThermalModel lets all the GPUs model their temperatures with the given
+// thermal model and throttle their CUs when they are too hot.
+func (b R9NanoPlatformBuilder) WithThermalModel(
+	model ThermalModel,
+) R9NanoPlatformBuilder {
+	b.thermalModel = &model
+	return b
+}
+
+// WithInterGPUTopology links the RDMA engines of the GPUs with a dedicated
+// network of the given topology, instead of the PCIe switches. A message
+// between two GPUs that are not linked directly takes multiple hops.
+func (b R9NanoPlatformBuilder) WithInterGPUTopology(
+	topology InterGPUTopology,
+) R9NanoPlatformBuilder {
+	b.interGPUTopology = topology
+	return b
+}
+
+// WithL1VVictimCache inserts a fully-associative victim cache of the given
+// size between each L1 vector cache and the L2 caches of all the GPUs.
+func (b R9NanoPlatformBuilder) WithL1VVictimCache(
+	byteSize uint64,
+) R9NanoPlatformBuilder {
+	b.l1vVictimCacheSize = byteSize
+	return b
+}
+
+// WithL0Cache inserts a cache of the given size inside each CU of all the GPUs,
+// between the vector memory unit and the L1 vector cache.
+func (b R9NanoPlatformBuilder) WithL0Cache(
+	byteSize uint64,
+) R9NanoPlatformBuilder {
+	b.l0CacheSize = byteSize
+	return b
+}
+
+// WithConstantCache builds a constant cache of the given size inside each CU of
+// all the GPUs, which serves the accesses to the buffers allocated with
+// Driver.AllocateConstantMemory.
+func (b R9NanoPlatformBuilder) WithConstantCache(
+	byteSize uint64,
+) R9NanoPlatformBuilder {
+	b.constantCacheSize = byteSize
+	return b
+}
+
+// WithWriteCombining inserts a write-combining buffer that holds up to depth
+// cache lines in front of each L1 vector cache of all the GPUs.
+func (b R9NanoPlatformBuilder) WithWriteCombining(
+	depth int,
+) R9NanoPlatformBuilder {
+	b.wcbDepth = depth
+	return b
+}
+
+// WithTLBShootdownLatency sets the number of cycles that each TLB of all the
+// GPUs takes to invalidate its entries during a TLB shootdown.
+func (b R9NanoPlatformBuilder) WithTLBShootdownLatency(
+	cycles int,
+) R9NanoPlatformBuilder {
+	b.tlbShootdownLatency = cycles
+	return b
+}
+
+// WithL1TLBNumSets sets the number of sets of each L1 TLB of all the GPUs.
+func (b R9NanoPlatformBuilder) WithL1TLBNumSets(
+	numSets int,
+) R9NanoPlatformBuilder {
+	b.l1TLBNumSets = numSets
+	return b
+}
+
+// WithL1TLBNumWays sets the number of ways in each set of each L1 TLB of all
+// the GPUs.
+func (b R9NanoPlatformBuilder) WithL1TLBNumWays(
+	numWays int,
+) R9NanoPlatformBuilder {
+	b.l1TLBNumWays = numWays
+	return b
+}
+
+// WithL1VNumPorts sets the number of accesses that each L1 vector cache of all
+// the GPUs can serve in each cycle.
+func (b R9NanoPlatformBuilder) WithL1VNumPorts(
+	numPorts int,
+) R9NanoPlatformBuilder {
+	b.l1vNumPorts = numPorts
+	return b
+}
+
+// WithKernelLaunchOverhead sets the number of cycles that the Command
+// Processor of each GPU takes to set up a kernel before the first wavefront
+// starts.
+func (b R9NanoPlatformBuilder) WithKernelLaunchOverhead(
+	cycles int,
+) R9NanoPlatformBuilder {
+	b.kernelLaunchOverhead = cycles
+	return b
+}
+
+// WithWGDispatchRate sets the number of work-groups that the Command Processor
+// of each GPU can dispatch to the CUs in each cycle for a kernel.
+func (b R9NanoPlatformBuilder) WithWGDispatchRate(
+	wgPerCycle int,
+) R9NanoPlatformBuilder {
+	b.wgDispatchRate = wgPerCycle
+	return b
+}
+
+// WithWGSchedulingPolicy sets how the Command Processor of each GPU chooses
+// the CU that each work-group runs on.
+func (b R9NanoPlatformBuilder) WithWGSchedulingPolicy(
+	policy cp.WGSchedulingPolicy,
+) R9NanoPlatformBuilder {
+	b.wgSchedulingPolicy = policy
+	return b
+}
+
+// WithL1VCoherence keeps the L1 vector caches of each GPU coherent with a
+// write-invalidate directory at the L2 caches. The coherence is relaxed: a
+// write completes without waiting for the other L1 vector caches to drop their
+// copies of the line.
+func (b R9NanoPlatformBuilder) WithL1VCoherence() R9NanoPlatformBuilder {
+	b.l1vCoherence = true
+	return b
+}
+
+// WithUnifiedL1 replaces the L1 vector, scalar, and instruction caches of each
+// shader array of all the GPUs with a single L1 cache.
+func (b R9NanoPlatformBuilder) WithUnifiedL1() R9NanoPlatformBuilder {
+	b.unifiedL1 = true
+	return b
+}
+
+// WithWavefrontSchedulingPolicy sets the order in which the CUs of all the GPUs
+// consider the wavefronts of a SIMD when issuing instructions.
+func (b R9NanoPlatformBuilder) WithWavefrontSchedulingPolicy(
+	policy cu.WavefrontSchedulingPolicy,
+) R9NanoPlatformBuilder {
+	b.wfSchedulingPolicy = policy
+	return b
+}
+
+// WithWavefrontSize sets the number of work-items in a wavefront of all the
+// GPUs, which can be 32 or 64.
+func (b R9NanoPlatformBuilder) WithWavefrontSize(n int) R9NanoPlatformBuilder {
+	b.wavefrontSize = n
+	return b
+}
+
+// WithWavefrontSlotsPerSIMD sets the maximum number of wavefronts that can be
+// resident on each SIMD unit of all the GPUs.
+func (b R9NanoPlatformBuilder) WithWavefrontSlotsPerSIMD(
+	n int,
+) R9NanoPlatformBuilder {
+	b.wfSlotsPerSIMD = n
+	return b
+}
+
+// WithSFULatencyTable sets the number of cycles that a wavefront takes to
+// execute each of the transcendental VOP1 instructions on the CUs of all the
+// GPUs. By default, the CUs use cu.DefaultSFULatencyTable.
+func (b R9NanoPlatformBuilder) WithSFULatencyTable(
+	table map[insts.Opcode]int,
+) R9NanoPlatformBuilder {
+	b.sfuLatencyTable = table
+	return b
+}
+
+// WithBarrierLatency sets the number of cycles that the wavefronts of a
+// work-group wait after the last of them arrives at an s_barrier on the CUs of
+// all the GPUs. By default, the wavefronts continue right away.
+func (b R9NanoPlatformBuilder) WithBarrierLatency(
+	cycles int,
+) R9NanoPlatformBuilder {
+	b.barrierLatency = cycles
+	return b
+}
+
+// WithInterposerLatency adds the given number of cycles to every memory
+// request that goes from the L2 caches to the DRAM controllers of each GPU,
+// as if the DRAM controllers were on a separate die. It cannot be used with
+// WithSharedDRAMPool or WithSharedLLC.
+func (b R9NanoPlatformBuilder) WithInterposerLatency(
+	cycles int,
+) R9NanoPlatformBuilder {
+	b.interposerLatency = cycles
+	return b
+}
+
+// WithL1IMissLatency sets the number of extra cycles that an instruction fetch
+// takes when it misses in the L1 instruction caches of all the GPUs.
+func (b R9NanoPlatformBuilder) WithL1IMissLatency(
+	cycles int,
+) R9NanoPlatformBuilder {
+	b.l1iMissLatency = cycles
+	return b
+}
+
+// WithScalarMSHREntries sets the number of scalar memory requests that each CU
+// of all the GPUs can keep outstanding, which is also the number of MSHR
+// entries of the L1 scalar caches.
+func (b R9NanoPlatformBuilder) WithScalarMSHREntries(
+	n int,
+) R9NanoPlatformBuilder {
+	b.scalarMSHREntries = n
+	return b
+}
+
+// WithBankedRegisterFile models the vector register files of the CUs of all
+// the GPUs with a number of banks, each of which can read a number of
+// registers per cycle.
+func (b R9NanoPlatformBuilder) WithBankedRegisterFile(
+	numBanks, numPorts int,
+) R9NanoPlatformBuilder {
+	b.numVGPRBanks = numBanks
+	b.numVGPRBankPorts = numPorts
+	return b
+}
+
+// WithLDSSizePerCU sets the number of bytes in the LDS of each CU of all the
+// GPUs. The default size is 64KB.
+func (b R9NanoPlatformBuilder) WithLDSSizePerCU(
+	size uint64,
+) R9NanoPlatformBuilder {
+	b.ldsSizePerCU = size
+	return b
+}
+
+// WithMemRequestInterceptor calls the interceptor on each memory request that
+// the L1 caches of all the GPUs send to the L2 caches.
+func (b R9NanoPlatformBuilder) WithMemRequestInterceptor(
+	interceptor MemRequestInterceptor,
+) R9NanoPlatformBuilder {
+	b.memRequestInterceptor = interceptor
+	return b
+}
+
+// WithL2ReplacementPolicy sets the policy that the L2 caches of all the GPUs
+// use to select the block to evict.
+func (b R9NanoPlatformBuilder) WithL2ReplacementPolicy(
+	policy replacement.Policy,
+) R9NanoPlatformBuilder {
+	b.l2ReplacementPolicy = policy
+	return b
+}
+
+// WithL2WriteBufferSize sets the number of evicted cache lines that the write
+// buffer of each L2 cache bank of all the GPUs can hold.
+func (b R9NanoPlatformBuilder) WithL2WriteBufferSize(
+	n int,
+) R9NanoPlatformBuilder {
+	b.l2WriteBufferSize = n
+	return b
+}
+
+// WithL2BankHashMapping lets all the GPUs map the addresses to the L2 cache
+// banks with an XOR hash of the address bits.
+func (b R9NanoPlatformBuilder) WithL2BankHashMapping() R9NanoPlatformBuilder {
+	b.l2BankHashMapping = true
+	return b
+}
+
+// WithReuseDistanceAnalysis lets all the GPUs record the reuse distance of the
+// accesses to their L2 caches.
+func (b R9NanoPlatformBuilder) WithReuseDistanceAnalysis() R9NanoPlatformBuilder {
+	b.reuseDistanceAnalysis = true
+	return b
+}
+
+// WithL1VReuseDistanceAnalysis lets all the GPUs record the reuse distance of
+// the accesses to both their L1 vector caches and L2 caches.
+func (b R9NanoPlatformBuilder) WithL1VReuseDistanceAnalysis() R9NanoPlatformBuilder {
+	b.reuseDistanceAnalysis = true
+	b.l1vReuseDistanceAnalysis = true
+	return b
+}
+
 // WithMonitor sets the monitor that is used to monitor the simulation
 func (b R9NanoPlatformBuilder) WithMonitor(
 	m *monitoring.Monitor,
@@ -133,6 +762,22 @@ func (b R9NanoPlatformBuilder) WithMagicMemoryCopy() R9NanoPlatformBuilder {
 	return b
 }
 
+// WithSharedDRAMPool lets all the GPUs share a single pool of memory
+// controllers rather than having private DRAMs.
+func (b R9NanoPlatformBuilder) WithSharedDRAMPool() R9NanoPlatformBuilder {
+	b.useSharedDRAMPool = true
+	return b
+}
+
+// WithSharedLLC inserts a last-level cache with the given total capacity in
+// bytes between the L2 caches of all the GPUs and the DRAM. Since the LLC is
+// shared by all the GPUs, it implies WithSharedDRAMPool.
+func (b R9NanoPlatformBuilder) WithSharedLLC(byteSize uint64) R9NanoPlatformBuilder {
+	b.useSharedDRAMPool = true
+	b.sharedLLCSize = byteSize
+	return b
+}
+
 // Build builds a platform with R9Nano GPUs.
 func (b R9NanoPlatformBuilder) Build() *Platform {
 	b.engine = b.createEngine()
@@ -143,19 +788,35 @@ func (b R9NanoPlatformBuilder) Build() *Platform {
 	b.setupPerformanceAnalyzer()
 	b.setupVisTracing()
 
-	b.globalStorage = mem.NewStorage(uint64(1+b.numGPU) * 4 * mem.GB)
+	b.globalStorage = mem.NewStorage(uint64(1+b.numAllGPU()) * 4 * mem.GB)
 
 	mmuComponent, pageTable := b.createMMU(b.engine)
 
 	gpuDriver := b.buildGPUDriver(pageTable)
 
-	gpuBuilder := b.createGPUBuilder(b.engine, gpuDriver, mmuComponent)
-	pcieConnector, rootComplexID :=
-		b.createConnection(b.engine, gpuDriver, mmuComponent)
+	gpuBuilder := b.createGPUBuilder(
+		b.engine, gpuDriver, mmuComponent, pageTable)
+
+	var llc *SharedLLC
+	if b.useSharedDRAMPool {
+		pool := gpuBuilder.BuildSharedDRAMPool(
+			"SharedDRAM", uint64(b.numAllGPU())*4*mem.GB)
+		gpuBuilder = gpuBuilder.WithSharedDRAMPool(pool)
+
+		if b.sharedLLCSize > 0 {
+			llc = gpuBuilder.BuildSharedLLC("SharedLLC", b.sharedLLCSize, pool)
+			gpuBuilder = gpuBuilder.WithSharedLLC(llc)
+		}
+	}
+
+	hostMemory := b.buildHostMemory()
+
+	pcieConnector, rootComplexID := b.createConnection(
+		b.engine, gpuDriver, mmuComponent, hostMemory)
 
 	mmuComponent.MigrationServiceProvider = gpuDriver.GetPortByName("MMU").AsRemote()
 
-	rdmaAddressTable := b.createRDMAAddrTable()
+	rdmaAddressTable := b.createRDMAAddrTable(hostMemory)
 	pmcAddressTable := b.createPMCPageTable()
 
 	b.createGPUs(
@@ -165,10 +826,31 @@ func (b R9NanoPlatformBuilder) Build() *Platform {
 
 	pcieConnector.EstablishRoute()
 
+	if b.interGPUTopology != InterGPUTopologyPCIe {
+		b.connectRDMAEngines()
+	}
+
 	return &Platform{
-		Engine: b.engine,
-		Driver: gpuDriver,
-		GPUs:   b.gpus,
+		Engine:             b.engine,
+		Driver:             gpuDriver,
+		GPUs:               b.gpus[:b.numGPU:b.numGPU],
+		SharedLLC:          llc,
+		HostMemory:         hostMemory,
+		spareGPUs:          b.gpus[b.numGPU:],
+		spareGPUProperties: b.gpuProperties(),
+	}
+}
+
+// numAllGPU returns the number of GPUs in the platform, including the spare
+// GPUs.
+func (b *R9NanoPlatformBuilder) numAllGPU() int {
+	return b.numGPU + b.numSpareGPU
+}
+
+func (b *R9NanoPlatformBuilder) gpuProperties() driver.DeviceProperties {
+	return driver.DeviceProperties{
+		CUCount:  b.numCUPerSA * b.numSAPerGPU,
+		DRAMSize: 4 * mem.GB,
 	}
 }
 
@@ -179,10 +861,14 @@ func (b R9NanoPlatformBuilder) buildGPUDriver(
 	if b.useMagicMemoryCopy {
 		gpuDriverBuilder = gpuDriverBuilder.WithMagicMemoryCopyMiddleware()
 	}
+	if b.isHostMemoryReachable() {
+		gpuDriverBuilder = gpuDriverBuilder.WithHostMemory()
+	}
 	gpuDriver := gpuDriverBuilder.
 		WithEngine(b.engine).
 		WithPageTable(pageTable).
 		WithLog2PageSize(b.log2PageSize).
+		WithHugePageSize(b.log2HugePageSize).
 		WithGlobalStorage(b.globalStorage).
 		WithD2HCycles(8500).
 		WithH2DCycles(14500).
@@ -191,6 +877,10 @@ func (b R9NanoPlatformBuilder) buildGPUDriver(
 		tracing.CollectTrace(gpuDriver, b.visTracer)
 	}
 
+	if b.criticalPathTracer != nil {
+		gpuDriver.RegisterCriticalPathAnalyzer(b.criticalPathTracer)
+	}
+
 	if b.monitor != nil {
 		b.monitor.RegisterComponent(gpuDriver)
 	}
@@ -210,6 +900,29 @@ func (b R9NanoPlatformBuilder) buildGPUDriver(
 }
 
 func (b *R9NanoPlatformBuilder) setupVisTracing() {
+	if b.criticalPathAnalysis {
+		if b.traceVis || b.chromeTracer != nil {
+			panic("cannot analyze the critical paths and record the " +
+				"traces at the same time")
+		}
+
+		b.criticalPathTracer = newCriticalPathTracer(b.engine)
+		b.visTracer = b.criticalPathTracer
+
+		return
+	}
+
+	if b.chromeTracer != nil {
+		if b.traceVis {
+			panic("cannot use vis tracing and chrome tracing at the same time")
+		}
+
+		b.chromeTracer.setTimeTeller(b.engine)
+		b.visTracer = b.chromeTracer
+
+		return
+	}
+
 	if !b.traceVis {
 		return
 	}
@@ -231,6 +944,44 @@ func (b *R9NanoPlatformBuilder) setupPerformanceAnalyzer() {
 	}
 }
 
+// registerGPUComponents lets the driver reach the components of the GPU that
+// has the given ID in the driver.
+func registerGPUComponents(gpuDriver *driver.Driver, index int, gpu *GPU) {
+	gpuDriver.RegisterOccupancyReporter(index, gpu.CommandProcessor)
+	gpuDriver.RegisterCUController(index, gpu.CommandProcessor)
+	gpuDriver.RegisterDMAEngine(index, gpu.DMAEngine)
+	gpuDriver.RegisterL2Partitioner(index, gpu.L2Partitioner)
+
+	gpuDriver.RegisterAtomicStatsReporter(index, gpu.AtomicStatsReporter)
+	gpuDriver.RegisterRDMAStatsReporter(index, gpu.RDMAStatsReporter)
+	gpuDriver.RegisterDRAMRowBufferStatsReporter(
+		index, gpu.DRAMRowBufferStatsReporter)
+	gpuDriver.RegisterMemQoSController(index, gpu.MemQoSController)
+	gpuDriver.RegisterSIMDUtilizationReporter(
+		index, gpu.SIMDUtilizationReporter)
+	gpuDriver.RegisterCoalescingStatsReporter(
+		index, gpu.CoalescingStatsReporter)
+	gpuDriver.RegisterStallBreakdownReporter(
+		index, gpu.StallBreakdownReporter)
+
+	if gpu.ConstantMemoryRouter != nil {
+		gpuDriver.RegisterConstantMemoryRouter(index, gpu.ConstantMemoryRouter)
+	}
+
+	if gpu.EnergyReporter != nil {
+		gpuDriver.RegisterEnergyReporter(index, gpu.EnergyReporter)
+	}
+
+	if gpu.ThermalSensor != nil {
+		gpuDriver.RegisterThermalSensor(index, gpu.ThermalSensor)
+	}
+
+	if gpu.ReuseDistanceAnalyzer != nil {
+		gpuDriver.RegisterReuseDistanceAnalyzer(
+			index, gpu.ReuseDistanceAnalyzer)
+	}
+}
+
 func (b *R9NanoPlatformBuilder) createGPUs(
 	rootComplexID int,
 	pcieConnector *pcie.Connector,
@@ -240,7 +991,7 @@ func (b *R9NanoPlatformBuilder) createGPUs(
 	pmcAddressTable *mem.BankedAddressPortMapper,
 ) {
 	lastSwitchID := rootComplexID
-	for i := 1; i < b.numGPU+1; i++ {
+	for i := 1; i < b.numAllGPU()+1; i++ {
 		if i%2 == 1 {
 			lastSwitchID = pcieConnector.AddSwitch(rootComplexID)
 		}
@@ -258,10 +1009,16 @@ func (b R9NanoPlatformBuilder) createPMCPageTable() *mem.BankedAddressPortMapper
 	return pmcAddressTable
 }
 
-func (b R9NanoPlatformBuilder) createRDMAAddrTable() *mem.BankedAddressPortMapper {
+// createRDMAAddrTable creates the table that finds the destination of the
+// remote accesses. The first 4 GB belong to the host memory, and each GPU
+// appends its RDMA engine for the next 4 GB.
+func (b R9NanoPlatformBuilder) createRDMAAddrTable(
+	hostMemory *idealmemcontroller.Comp,
+) *mem.BankedAddressPortMapper {
 	rdmaAddressTable := new(mem.BankedAddressPortMapper)
 	rdmaAddressTable.BankSize = 4 * mem.GB
-	rdmaAddressTable.LowModules = append(rdmaAddressTable.LowModules, "")
+	rdmaAddressTable.LowModules = append(rdmaAddressTable.LowModules,
+		hostMemory.GetPortByName("Top").AsRemote())
 	return rdmaAddressTable
 }
 
@@ -269,6 +1026,7 @@ func (b R9NanoPlatformBuilder) createConnection(
 	engine sim.Engine,
 	gpuDriver *driver.Driver,
 	mmuComponent *mmu.Comp,
+	hostMemory *idealmemcontroller.Comp,
 ) (*pcie.Connector, int) {
 	//connection := sim.NewDirectConnection(engine)
 	// connection := noc.NewFixedBandwidthConnection(32, engine, 1*sim.GHz)
@@ -289,6 +1047,7 @@ func (b R9NanoPlatformBuilder) createConnection(
 			gpuDriver.GetPortByName("MMU"),
 			mmuComponent.GetPortByName("Migration"),
 			mmuComponent.GetPortByName("Top"),
+			hostMemory.GetPortByName("Top"),
 		})
 	return pcieConnector, rootComplexID
 }
@@ -310,10 +1069,16 @@ func (b R9NanoPlatformBuilder) createMMU(
 	engine sim.Engine,
 ) (*mmu.Comp, vm.PageTable) {
 	pageTable := vm.NewPageTable(b.log2PageSize)
+	if b.log2HugePageSize != 0 {
+		pageTable = driver.NewHugePageTable(
+			b.log2PageSize, b.log2HugePageSize)
+	}
+
 	mmuBuilder := mmu.MakeBuilder().
 		WithEngine(engine).
 		WithFreq(1 * sim.GHz).
-		WithPageWalkingLatency(100).
+		WithPageWalkingLatency(
+			scaleLatency(b.pageWalkLatency, b.timingScale)).
 		WithLog2PageSize(b.log2PageSize).
 		WithPageTable(pageTable)
 
@@ -330,16 +1095,118 @@ func (b *R9NanoPlatformBuilder) createGPUBuilder(
 	engine sim.Engine,
 	gpuDriver *driver.Driver,
 	mmuComponent *mmu.Comp,
+	pageTable vm.PageTable,
 ) R9NanoGPUBuilder {
 	gpuBuilder := MakeR9NanoGPUBuilder().
 		WithEngine(engine).
 		WithMMU(mmuComponent).
 		WithNumCUPerShaderArray(b.numCUPerSA).
+		WithNumSIMDPerCU(b.numSIMDPerCU).
+		WithWavefrontSchedulingPolicy(b.wfSchedulingPolicy).
+		WithBankedRegisterFile(b.numVGPRBanks, b.numVGPRBankPorts).
+		WithWavefrontSize(b.wavefrontSize).
+		WithWavefrontSlotsPerSIMD(b.wfSlotsPerSIMD).
+		WithSFULatencyTable(b.sfuLatencyTable).
+		WithBarrierLatency(b.barrierLatency).
+		WithInterposerLatency(b.interposerLatency).
+		WithL1IMissLatency(b.l1iMissLatency).
+		WithLDSSizePerCU(b.ldsSizePerCU).
+		WithMemRequestInterceptor(b.memRequestInterceptor).
 		WithNumShaderArray(b.numSAPerGPU).
 		WithNumMemoryBank(16).
 		WithLog2MemoryBankInterleavingSize(7).
+		WithLog2CacheLineSize(b.log2CacheLineSize).
 		WithLog2PageSize(b.log2PageSize).
-		WithGlobalStorage(b.globalStorage)
+		WithHugePageSize(b.log2HugePageSize).
+		WithGlobalStorage(b.globalStorage).
+		WithTimingScale(b.timingScale).
+		WithDMAMaxOutstanding(b.dmaMaxOutstanding).
+		WithECCOverhead(b.eccBandwidthOverhead, b.eccLatencyPenalty).
+		WithDRAMRefreshInterval(b.dramRefreshInterval).
+		WithDRAMChannelsPerBank(b.dramChannelsPerBank).
+		WithDRAMSubChannels(b.dramSubChannels).
+		WithDRAMReadQueueSize(b.dramReadQueueSize).
+		WithDRAMWriteQueueSize(b.dramWriteQueueSize).
+		WithDRAMScheduler(b.dramScheduler).
+		WithL2ReplacementPolicy(b.l2ReplacementPolicy).
+		WithL2WriteBufferSize(b.l2WriteBufferSize).
+		WithL1VVictimCache(b.l1vVictimCacheSize).
+		WithL0Cache(b.l0CacheSize).
+		WithConstantCache(b.constantCacheSize).
+		WithWriteCombining(b.wcbDepth).
+		WithTLBShootdownLatency(b.tlbShootdownLatency).
+		WithL1TLBNumSets(b.l1TLBNumSets).
+		WithL1TLBNumWays(b.l1TLBNumWays).
+		WithKernelLaunchOverhead(b.kernelLaunchOverhead).
+		WithWGDispatchRate(b.wgDispatchRate).
+		WithWGSchedulingPolicy(b.wgSchedulingPolicy)
+
+	if b.eccEnabled {
+		gpuBuilder = gpuBuilder.WithECCEnabled()
+	}
+
+	if b.l1vCoherence {
+		gpuBuilder = gpuBuilder.WithL1VCoherence()
+	}
+
+	if b.l1vNumPorts > 0 {
+		gpuBuilder = gpuBuilder.WithL1VNumPorts(b.l1vNumPorts)
+	}
+
+	if b.unifiedL1 {
+		gpuBuilder = gpuBuilder.WithUnifiedL1()
+	}
+
+	if b.scalarMSHREntries > 0 {
+		gpuBuilder = gpuBuilder.WithScalarMSHREntries(b.scalarMSHREntries)
+	}
+
+	if b.l2BankHashMapping {
+		gpuBuilder = gpuBuilder.WithL2BankHashMapping()
+	}
+
+	if b.dramOpenPage {
+		gpuBuilder = gpuBuilder.WithDRAMOpenPagePolicy()
+	}
+
+	if b.dramQoSScheduling {
+		gpuBuilder = gpuBuilder.WithDRAMQoSScheduling()
+	}
+
+	if b.dramFastTierSize > 0 {
+		gpuBuilder = gpuBuilder.WithTieredDRAM(b.dramFastTierSize)
+	}
+
+	if b.dramFaultInjector != nil {
+		gpuBuilder = gpuBuilder.WithUncorrectableFaultInjector(
+			b.dramFaultInjector)
+	}
+
+	if b.powerModel != nil {
+		gpuBuilder = gpuBuilder.WithPowerModel(*b.powerModel)
+	}
+
+	if b.thermalModel != nil {
+		gpuBuilder = gpuBuilder.WithThermalModel(*b.thermalModel)
+	}
+
+	if b.validateTranslation {
+		gpuBuilder = gpuBuilder.WithTranslationValidation(pageTable)
+	}
+
+	if b.detectFaults {
+		gpuBuilder = gpuBuilder.WithFaultDetection(pageTable)
+	}
+
+	if b.idealTLB {
+		gpuBuilder = gpuBuilder.WithIdealTLB(pageTable)
+	}
+
+	if b.l1vReuseDistanceAnalysis {
+		gpuBuilder = gpuBuilder.WithL1VReuseDistanceAnalysis()
+	} else if b.reuseDistanceAnalysis {
+		gpuBuilder = gpuBuilder.WithReuseDistanceAnalysis()
+	}
 
 	if b.monitor != nil {
 		gpuBuilder = gpuBuilder.WithMonitor(b.monitor)
@@ -362,6 +1229,11 @@ func (b *R9NanoPlatformBuilder) createGPUBuilder(
 func (b *R9NanoPlatformBuilder) setISADebugger(
 	gpuBuilder R9NanoGPUBuilder,
 ) R9NanoGPUBuilder {
+	if b.isaDebugWf != nil {
+		gpuBuilder = gpuBuilder.WithISADebuggingForWavefront(
+			b.isaDebugWf.cuIndex, b.isaDebugWf.wgID, b.isaDebugWf.wfID)
+	}
+
 	if !b.debugISA {
 		return gpuBuilder
 	}
@@ -398,28 +1270,55 @@ func (b *R9NanoPlatformBuilder) createGPU(
 ) *GPU {
 	name := fmt.Sprintf("GPU[%d]", index)
 	memAddrOffset := uint64(index) * 4 * mem.GB
+	if index <= len(b.gpuFreqs) {
+		gpuBuilder = gpuBuilder.WithFreq(b.gpuFreqs[index-1])
+	}
+
 	gpu := gpuBuilder.
 		WithMemAddrOffset(memAddrOffset).
 		Build(name, uint64(index))
-	gpuDriver.RegisterGPU(
-		gpu.Domain.GetPortByName("CommandProcessor"),
-		driver.DeviceProperties{
-			CUCount:  b.numCUPerSA * b.numSAPerGPU,
-			DRAMSize: 4 * mem.GB,
-		},
-	)
 	gpu.CommandProcessor.Driver = gpuDriver.GetPortByName("GPU")
 
+	if b.accelSimTracer != nil {
+		for _, cu := range gpu.CUs {
+			b.accelSimTracer.traceCU(cu)
+		}
+	}
+
+	if index <= b.numGPU {
+		gpuDriver.RegisterGPU(
+			gpu.Domain.GetPortByName("CommandProcessor"),
+			b.gpuProperties(),
+		)
+		registerGPUComponents(gpuDriver, index, gpu)
+	}
+
 	b.configRDMAEngine(gpu, rdmaAddressTable)
 	b.configPMC(gpu, gpuDriver, pmcAddressTable)
 
-	pcieConnector.PlugInDevice(pcieSwitchID, gpu.Domain.Ports())
+	pcieConnector.PlugInDevice(pcieSwitchID, b.pciePorts(gpu))
 
 	b.gpus = append(b.gpus, gpu)
 
 	return gpu
 }
 
+// pciePorts returns the ports of the GPU that connect to the PCIe switches.
+func (b *R9NanoPlatformBuilder) pciePorts(gpu *GPU) []sim.Port {
+	if b.interGPUTopology == InterGPUTopologyPCIe {
+		return gpu.Domain.Ports()
+	}
+
+	var ports []sim.Port
+	for _, port := range gpu.Domain.Ports() {
+		if port != gpu.RDMAEngine.ToOutside {
+			ports = append(ports, port)
+		}
+	}
+
+	return ports
+}
+
 func (b *R9NanoPlatformBuilder) configRDMAEngine(
 	gpu *GPU,
 	addrTable *mem.BankedAddressPortMapper,
@@ -0,0 +1,60 @@
+package runner
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/sarchlab/mgpusim/v4/amd/benchmarks/heteromark/fir"
+	"github.com/sarchlab/mgpusim/v4/amd/driver"
+)
+
+// runFIR runs the FIR benchmark on the first GPU.
+func runFIR(gpuDriver *driver.Driver) {
+	benchmark := fir.NewBenchmark(gpuDriver)
+	benchmark.Length = 1024
+	benchmark.SelectGPU([]int{1})
+	benchmark.Run()
+}
+
+var _ = Describe("Timing Scale", func() {
+	It("should scale the runtime roughly linearly", func() {
+		builder := MakeR9NanoBuilder().WithNumGPU(1)
+
+		t1 := kernelTime(builder, runFIR)
+		t2 := kernelTime(builder.WithTimingScale(2), runFIR)
+		t4 := kernelTime(builder.WithTimingScale(4), runFIR)
+
+		// The compute latencies are not scaled, so the kernel time is a
+		// fixed part plus a part that is proportional to the factor. Going
+		// from 2 to 4 should add about twice as much time as going from 1
+		// to 2.
+		Expect(t2).To(BeNumerically(">", t1))
+		Expect(float64((t4 - t2) / (t2 - t1))).To(BeNumerically("~", 2, 0.5))
+	})
+
+	// A platform with a latency that is scaled by 2 should be at least as
+	// slow as the platform with twice the latency, since the rest of the
+	// platform is also slowed down.
+	DescribeTable("should scale the latency options",
+		func(
+			numGPU int,
+			run func(gpuDriver *driver.Driver),
+			withLatency func(R9NanoPlatformBuilder, int) R9NanoPlatformBuilder,
+		) {
+			const latency = 1000
+
+			builder := MakeR9NanoBuilder().WithNumGPU(numGPU)
+			scaled := kernelTime(
+				withLatency(builder, latency).WithTimingScale(2), run)
+			doubled := kernelTime(withLatency(builder, 2*latency), run)
+
+			Expect(scaled).To(BeNumerically(">=", doubled))
+		},
+		Entry("L1I miss latency", 1, runLargeCodeKernel,
+			R9NanoPlatformBuilder.WithL1IMissLatency),
+		Entry("barrier latency", 1, runMatrixMultiplication,
+			R9NanoPlatformBuilder.WithBarrierLatency),
+		Entry("TLB shootdown latency", 2, runMigratingKernel,
+			R9NanoPlatformBuilder.WithTLBShootdownLatency),
+	)
+})
@@ -0,0 +1,147 @@
+package runner
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/sarchlab/akita/v4/noc/networking/networkconnector"
+	"github.com/sarchlab/akita/v4/sim"
+)
+
+// An InterGPUTopology determines how the RDMA engines of the GPUs are linked
+// with each other.
+type InterGPUTopology int
+
+// A list of supported inter-GPU topologies.
+const (
+	// InterGPUTopologyPCIe lets the RDMA engines talk to each other through
+	// the PCIe switches, which also connect the GPUs with the CPU.
+	InterGPUTopologyPCIe InterGPUTopology = iota
+
+	// InterGPUTopologyRing links each GPU with the GPU before and the GPU
+	// after it, and links the last GPU with the first one.
+	InterGPUTopologyRing
+
+	// InterGPUTopologyMesh places the GPUs on a 2D grid, row by row, and links
+	// each GPU with its neighbors on the grid.
+	InterGPUTopologyMesh
+
+	// InterGPUTopologyAllToAll links each GPU with every other GPU.
+	InterGPUTopologyAllToAll
+)
+
+// interGPUSwitchLatency is the number of cycles that a message spends in each
+// switch of the inter-GPU network. A message between two GPUs that are not
+// linked directly passes through the switches of the GPUs in between.
+const interGPUSwitchLatency = 140
+
+// connectRDMAEngines links the RDMA engines of all the GPUs with a network
+// that follows the inter-GPU topology. Each GPU has its own switch.
+func (b *R9NanoPlatformBuilder) connectRDMAEngines() {
+	connector := networkconnector.MakeConnector().
+		WithEngine(b.engine).
+		WithDefaultFreq(1 * sim.GHz)
+
+	if b.visTracer != nil {
+		connector = connector.WithVisTracer(b.visTracer)
+	}
+
+	connector.NewNetwork("InterGPU")
+
+	switchIDs := make([]int, len(b.gpus))
+	for i, gpu := range b.gpus {
+		switchIDs[i] = connector.AddSwitchWithName(
+			fmt.Sprintf("Switch[%d]", i+1))
+		connector.ConnectDevice(switchIDs[i],
+			[]sim.Port{gpu.RDMAEngine.ToOutside},
+			interGPUDeviceLinkParameter())
+	}
+
+	for _, link := range b.interGPULinks() {
+		connector.ConnectSwitches(
+			switchIDs[link[0]], switchIDs[link[1]],
+			interGPUSwitchLinkParameter())
+	}
+
+	connector.EstablishRoute()
+}
+
+// interGPULinks returns the pairs of GPUs, by their index in the platform,
+// that are linked directly.
+func (b *R9NanoPlatformBuilder) interGPULinks() [][2]int {
+	n := len(b.gpus)
+	var links [][2]int
+
+	switch b.interGPUTopology {
+	case InterGPUTopologyRing:
+		for i := 0; i < n-1; i++ {
+			links = append(links, [2]int{i, i + 1})
+		}
+
+		if n > 2 {
+			links = append(links, [2]int{n - 1, 0})
+		}
+	case InterGPUTopologyMesh:
+		width := int(math.Ceil(math.Sqrt(float64(n))))
+		for i := 0; i < n; i++ {
+			if (i+1)%width != 0 && i+1 < n {
+				links = append(links, [2]int{i, i + 1})
+			}
+
+			if i+width < n {
+				links = append(links, [2]int{i, i + width})
+			}
+		}
+	case InterGPUTopologyAllToAll:
+		for i := 0; i < n; i++ {
+			for j := i + 1; j < n; j++ {
+				links = append(links, [2]int{i, j})
+			}
+		}
+	default:
+		panic("unknown inter-GPU topology")
+	}
+
+	return links
+}
+
+func interGPUDeviceLinkParameter() networkconnector.DeviceToSwitchLinkParameter {
+	return networkconnector.DeviceToSwitchLinkParameter{
+		DeviceEndParam: networkconnector.LinkEndDeviceParameter{
+			IncomingBufSize:  16,
+			OutgoingBufSize:  16,
+			NumInputChannel:  1,
+			NumOutputChannel: 1,
+		},
+		SwitchEndParam: networkconnector.LinkEndSwitchParameter{
+			IncomingBufSize:  16,
+			OutgoingBufSize:  16,
+			Latency:          interGPUSwitchLatency,
+			NumInputChannel:  1,
+			NumOutputChannel: 1,
+		},
+		LinkParam: networkconnector.LinkParameter{
+			IsIdeal:   true,
+			Frequency: 1 * sim.GHz,
+		},
+	}
+}
+
+func interGPUSwitchLinkParameter() networkconnector.SwitchToSwitchLinkParameter {
+	end := networkconnector.LinkEndSwitchParameter{
+		IncomingBufSize:  16,
+		OutgoingBufSize:  16,
+		Latency:          interGPUSwitchLatency,
+		NumInputChannel:  1,
+		NumOutputChannel: 1,
+	}
+
+	return networkconnector.SwitchToSwitchLinkParameter{
+		LeftEndParam:  end,
+		RightEndParam: end,
+		LinkParam: networkconnector.LinkParameter{
+			IsIdeal:   true,
+			Frequency: 1 * sim.GHz,
+		},
+	}
+}
@@ -0,0 +1,37 @@
+package runner
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Inter-GPU Topology", func() {
+	links := func(topology InterGPUTopology, numGPU int) [][2]int {
+		b := R9NanoPlatformBuilder{
+			interGPUTopology: topology,
+			gpus:             make([]*GPU, numGPU),
+		}
+
+		return b.interGPULinks()
+	}
+
+	DescribeTable("should link the GPUs",
+		func(topology InterGPUTopology, numGPU int, expected [][2]int) {
+			Expect(links(topology, numGPU)).To(Equal(expected))
+		},
+		Entry("in a ring", InterGPUTopologyRing, 4,
+			[][2]int{{0, 1}, {1, 2}, {2, 3}, {3, 0}}),
+		Entry("in a ring of 2 GPUs with a single link",
+			InterGPUTopologyRing, 2, [][2]int{{0, 1}}),
+		Entry("in a square mesh", InterGPUTopologyMesh, 4,
+			[][2]int{{0, 1}, {0, 2}, {1, 3}, {2, 3}}),
+		Entry("in a mesh with an incomplete row", InterGPUTopologyMesh, 5,
+			[][2]int{{0, 1}, {0, 3}, {1, 2}, {1, 4}, {3, 4}}),
+		Entry("all to all", InterGPUTopologyAllToAll, 3,
+			[][2]int{{0, 1}, {0, 2}, {1, 2}}),
+	)
+
+	It("should not build a network for the PCIe topology", func() {
+		Expect(func() { links(InterGPUTopologyPCIe, 4) }).To(Panic())
+	})
+})
@@ -0,0 +1,127 @@
+package runner
+
+import (
+	"sync"
+
+	"github.com/sarchlab/akita/v4/mem/mem"
+	"github.com/sarchlab/akita/v4/tracing"
+)
+
+// MemoryTrafficBreakdown is the number of bytes that each level of the memory
+// hierarchy of a GPU has served since the start of the simulation. The bytes
+// of a level are the sum over all the components of the level.
+type MemoryTrafficBreakdown struct {
+	// L1V, L1S, and L1I are the bytes that the L1 vector, scalar, and
+	// instruction caches have read or written for the CUs.
+	L1V uint64
+	L1S uint64
+	L1I uint64
+
+	// L1 is the bytes that the unified L1 caches have read or written for the
+	// CUs. It is 0 unless the GPU has a unified L1.
+	L1 uint64
+
+	// L2 is the bytes that the L2 caches have read or written.
+	L2 uint64
+
+	// DRAM is the bytes that the DRAM controllers have read or written,
+	// including the data that the DMA engine copies.
+	DRAM uint64
+
+	// RDMA is the bytes that the RDMA engine has read from or written to the
+	// other GPUs.
+	RDMA uint64
+}
+
+// A memTrafficTracer counts the bytes of the requests that a memory component
+// receives.
+type memTrafficTracer struct {
+	sync.Mutex
+
+	bytes uint64
+}
+
+func newMemTrafficTracer() *memTrafficTracer {
+	return &memTrafficTracer{}
+}
+
+// StartTask counts the bytes of an incoming read or write request.
+func (t *memTrafficTracer) StartTask(task tracing.Task) {
+	if task.Kind != "req_in" {
+		return
+	}
+
+	t.Lock()
+	defer t.Unlock()
+
+	switch req := task.Detail.(type) {
+	case *mem.ReadReq:
+		t.bytes += req.AccessByteSize
+	case *mem.WriteReq:
+		t.bytes += uint64(len(req.Data))
+	}
+}
+
+func (t *memTrafficTracer) StepTask(_ tracing.Task) {
+	// Do nothing
+}
+
+func (t *memTrafficTracer) AddMilestone(_ tracing.Milestone) {
+	// Do nothing
+}
+
+func (t *memTrafficTracer) EndTask(_ tracing.Task) {
+	// Do nothing
+}
+
+type cacheTrafficTracer struct {
+	tracer *memTrafficTracer
+	cache  TraceableComponent
+}
+
+type rdmaTrafficCounter struct {
+	tracer *rdmaTrafficTracer
+	rdma   TraceableComponent
+}
+
+// GetMemoryTrafficBreakdown returns the bytes that each level of the memory
+// hierarchy of the GPU with the given ID has served. The first GPU has ID 1.
+// A cache level serves more bytes than the level below it when the data is
+// reused in the cache. The traffic is only collected if CollectCounters is
+// set.
+func (r *Runner) GetMemoryTrafficBreakdown(gpuID int) MemoryTrafficBreakdown {
+	gpu := r.platform.GPUs[gpuID-1]
+	breakdown := MemoryTrafficBreakdown{}
+
+	for _, t := range r.cacheTrafficCounters {
+		t.tracer.Lock()
+		bytes := t.tracer.bytes
+		t.tracer.Unlock()
+
+		switch {
+		case containsComponent(gpu.L1VCaches, t.cache):
+			breakdown.L1V += bytes
+		case containsComponent(gpu.L1SCaches, t.cache):
+			breakdown.L1S += bytes
+		case containsComponent(gpu.L1ICaches, t.cache):
+			breakdown.L1I += bytes
+		case containsComponent(gpu.L1Caches, t.cache):
+			breakdown.L1 += bytes
+		case containsComponent(gpu.L2Caches, t.cache):
+			breakdown.L2 += bytes
+		}
+	}
+
+	stats := r.GetGPUStats(gpuID)
+	breakdown.DRAM = stats.DRAMReadBytes + stats.DRAMWriteBytes
+
+	for _, t := range r.rdmaCounters {
+		if t.rdma == gpu.RDMAEngine {
+			t.tracer.Lock()
+			breakdown.RDMA += t.tracer.bytes
+			t.tracer.Unlock()
+		}
+	}
+
+	return breakdown
+}
@@ -0,0 +1,120 @@
+package runner
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/sarchlab/akita/v4/mem/mem"
+	"github.com/sarchlab/akita/v4/sim"
+	"github.com/sarchlab/akita/v4/tracing"
+	"github.com/sarchlab/mgpusim/v4/amd/timing/rdma"
+)
+
+var _ = Describe("Memory Traffic", func() {
+	Context("tracer", func() {
+		var tracer *memTrafficTracer
+
+		BeforeEach(func() {
+			tracer = newMemTrafficTracer()
+		})
+
+		It("should count the bytes of the incoming requests", func() {
+			tracer.StartTask(tracing.Task{
+				Kind:   "req_in",
+				Detail: mem.ReadReqBuilder{}.WithByteSize(64).Build(),
+			})
+			tracer.StartTask(tracing.Task{
+				Kind: "req_in",
+				Detail: mem.WriteReqBuilder{}.
+					WithData(make([]byte, 16)).
+					Build(),
+			})
+
+			Expect(tracer.bytes).To(Equal(uint64(80)))
+		})
+
+		It("should not count the outgoing requests", func() {
+			tracer.StartTask(tracing.Task{
+				Kind:   "req_out",
+				Detail: mem.ReadReqBuilder{}.WithByteSize(64).Build(),
+			})
+
+			Expect(tracer.bytes).To(BeZero())
+		})
+	})
+
+	Context("breakdown", func() {
+		var (
+			r   *Runner
+			gpu *GPU
+		)
+
+		// addCache adds a cache that has served the given bytes.
+		addCache := func(
+			caches *[]TraceableComponent,
+			name string,
+			bytes uint64,
+		) {
+			cache := sim.NewComponentBase(name)
+			*caches = append(*caches, cache)
+			r.cacheTrafficCounters = append(r.cacheTrafficCounters,
+				cacheTrafficTracer{
+					tracer: &memTrafficTracer{bytes: bytes},
+					cache:  cache,
+				})
+		}
+
+		BeforeEach(func() {
+			dram := sim.NewComponentBase("GPU[1].DRAM")
+			gpu = &GPU{
+				MemControllers: []TraceableComponent{dram},
+				RDMAEngine:     &rdma.Comp{},
+			}
+			r = &Runner{platform: &Platform{GPUs: []*GPU{gpu}}}
+
+			addCache(&gpu.L1VCaches, "GPU[1].L1VCache[0]", 4096)
+			addCache(&gpu.L1VCaches, "GPU[1].L1VCache[1]", 2048)
+			addCache(&gpu.L1SCaches, "GPU[1].L1SCache", 512)
+			addCache(&gpu.L1ICaches, "GPU[1].L1ICache", 256)
+			addCache(&gpu.L2Caches, "GPU[1].L2Cache", 1024)
+
+			r.dramCounters = append(r.dramCounters, dramTransactionCountTracer{
+				tracer: &dramTracer{readSize: 384, writeSize: 128},
+				dram:   dram,
+			})
+		})
+
+		It("should sum the bytes of each memory level", func() {
+			Expect(r.GetMemoryTrafficBreakdown(1)).
+				To(Equal(MemoryTrafficBreakdown{
+					L1V:  6144,
+					L1S:  512,
+					L1I:  256,
+					L2:   1024,
+					DRAM: 512,
+				}))
+		})
+
+		It("should count the bytes of the unified L1 caches", func() {
+			addCache(&gpu.L1Caches, "GPU[1].L1Cache", 8192)
+
+			Expect(r.GetMemoryTrafficBreakdown(1).L1).
+				To(Equal(uint64(8192)))
+		})
+
+		It("should only count the RDMA traffic of the GPU", func() {
+			r.rdmaCounters = append(r.rdmaCounters,
+				rdmaTrafficCounter{
+					tracer: &rdmaTrafficTracer{bytes: 64},
+					rdma:   gpu.RDMAEngine,
+				},
+				rdmaTrafficCounter{
+					tracer: &rdmaTrafficTracer{bytes: 128},
+					rdma:   &rdma.Comp{},
+				})
+
+			Expect(r.GetMemoryTrafficBreakdown(1).RDMA).
+				To(Equal(uint64(64)))
+		})
+	})
+})
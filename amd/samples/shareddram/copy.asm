@@ -0,0 +1,40 @@
+// memory_bound copies in[gid] to out[gid].
+//
+// Assemble with:
+//   llvm-mc -triple amdgcn--amdhsa -mcpu=fiji \
+//     --amdhsa-code-object-version=2 -filetype=obj \
+//     -o copy.hsaco copy.asm
+
+.hsa_code_object_version 2,1
+.hsa_code_object_isa 8,0,3,"AMD","AMDGPU"
+
+.text
+.amdgpu_hsa_kernel memory_bound
+memory_bound:
+  .amd_kernel_code_t
+    enable_sgpr_kernarg_segment_ptr = 1
+    enable_sgpr_workgroup_id_x = 1
+    user_sgpr_count = 2
+    is_ptr64 = 1
+    kernarg_segment_byte_size = 16
+    wavefront_sgpr_count = 16
+    workitem_vgpr_count = 8
+    granulated_workitem_vgpr_count = 1
+    granulated_wavefront_sgpr_count = 1
+  .end_amd_kernel_code_t
+
+  s_load_dwordx4 s[4:7], s[0:1], 0x0
+  s_lshl_b32 s8, s2, 6
+  v_add_u32 v1, vcc, s8, v0
+  v_lshlrev_b32 v1, 2, v1
+  s_waitcnt lgkmcnt(0)
+  v_mov_b32 v3, s5
+  v_add_u32 v2, vcc, s4, v1
+  v_addc_u32 v3, vcc, 0, v3, vcc
+  flat_load_dword v4, v[2:3]
+  v_mov_b32 v6, s7
+  v_add_u32 v5, vcc, s6, v1
+  v_addc_u32 v6, vcc, 0, v6, vcc
+  s_waitcnt vmcnt(0)
+  flat_store_dword v[5:6], v4
+  s_endpgm
@@ -0,0 +1,139 @@
+// Package main demonstrates two GPUs reading and writing the same physical
+// pages. Run it with `-timing -shared-dram -gpus=1,2` so that both GPUs are
+// connected to a shared DRAM pool. The benchmark fails without
+// `-shared-dram`, as the GPUs then reach each other's memory through RDMA.
+package main
+
+import (
+	// embed hsaco files
+	_ "embed"
+	"flag"
+	"log"
+	"math/rand"
+
+	"github.com/sarchlab/mgpusim/v4/amd/driver"
+	"github.com/sarchlab/mgpusim/v4/amd/insts"
+	"github.com/sarchlab/mgpusim/v4/amd/kernels"
+	"github.com/sarchlab/mgpusim/v4/amd/samples/runner"
+)
+
+// KernelArgs defines kernel arguments
+type KernelArgs struct {
+	In  driver.Ptr
+	Out driver.Ptr
+}
+
+// Benchmark defines a benchmark
+type Benchmark struct {
+	driver  *driver.Driver
+	context *driver.Context
+	gpus    []int
+	hsaco   *insts.HsaCo
+
+	NumElements uint32
+	data        []uint32
+	retData     []uint32
+
+	copyBackData []uint32
+}
+
+//go:embed copy.hsaco
+var hsacoBytes []byte
+
+// NewBenchmark creates a new benchmark
+func NewBenchmark(driver *driver.Driver) *Benchmark {
+	b := new(Benchmark)
+	b.driver = driver
+	b.context = driver.Init()
+	b.hsaco = kernels.LoadProgramFromMemory(hsacoBytes, "")
+	return b
+}
+
+// SelectGPU selects gpu
+func (b *Benchmark) SelectGPU(gpus []int) {
+	if len(gpus) != 2 {
+		panic("shared DRAM benchmark requires exactly two GPUs")
+	}
+	b.gpus = gpus
+}
+
+// SetUnifiedMemory Use Unified Memory
+func (b *Benchmark) SetUnifiedMemory() {
+	panic("unified memory is not supported by the shared DRAM benchmark")
+}
+
+// Run runs the benchmark. The first GPU owns the source buffer and the second
+// GPU owns the destination buffer. A kernel on the second GPU copies the
+// source to the destination and a kernel on the first GPU copies the
+// destination back to a buffer that it owns. Both kernels read the pages that
+// the other GPU writes. Since the L2 caches are not coherent, the kernels are
+// separated by a memory copy to the host, which flushes the caches.
+func (b *Benchmark) Run() {
+	b.data = make([]uint32, b.NumElements)
+	b.retData = make([]uint32, b.NumElements)
+	b.copyBackData = make([]uint32, b.NumElements)
+	for i := uint32(0); i < b.NumElements; i++ {
+		b.data[i] = rand.Uint32()
+	}
+
+	byteSize := uint64(b.NumElements * 4)
+
+	b.driver.SelectGPU(b.context, b.gpus[0])
+	src := b.driver.AllocateMemory(b.context, byteSize)
+	copyBack := b.driver.AllocateMemory(b.context, byteSize)
+	b.driver.MemCopyH2D(b.context, src, b.data)
+
+	b.driver.SelectGPU(b.context, b.gpus[1])
+	dst := b.driver.AllocateMemory(b.context, byteSize)
+	b.copy(dst, src)
+	b.driver.MemCopyD2H(b.context, b.retData, dst)
+
+	b.driver.SelectGPU(b.context, b.gpus[0])
+	b.copy(copyBack, dst)
+	b.driver.MemCopyD2H(b.context, b.copyBackData, copyBack)
+}
+
+func (b *Benchmark) copy(dst, src driver.Ptr) {
+	args := KernelArgs{In: src, Out: dst}
+	b.driver.LaunchKernel(b.context, b.hsaco,
+		[3]uint32{b.NumElements, 1, 1}, [3]uint16{64, 1, 1}, &args)
+}
+
+// Verify verifies
+func (b *Benchmark) Verify() {
+	for i := uint32(0); i < b.NumElements; i++ {
+		if b.data[i] != b.retData[i] {
+			log.Panicf("error at %d, expected %08x, but get %08x",
+				i, b.data[i], b.retData[i])
+		}
+
+		if b.data[i] != b.copyBackData[i] {
+			log.Panicf("error at %d, expected %08x, but get %08x",
+				i, b.data[i], b.copyBackData[i])
+		}
+	}
+
+	for _, gpu := range b.gpus {
+		traffic := b.driver.GetRDMATraffic(gpu)
+		if traffic.NumReqSent != 0 {
+			log.Panicf("GPU %d sends %d RDMA requests, "+
+				"run with -timing -shared-dram",
+				gpu, traffic.NumReqSent)
+		}
+	}
+
+	log.Printf("Passed!")
+}
+
+func main() {
+	flag.Parse()
+
+	runner := new(runner.Runner).Init()
+
+	benchmark := NewBenchmark(runner.Driver())
+	benchmark.NumElements = 16384
+
+	runner.AddBenchmark(benchmark)
+
+	runner.Run()
+}
@@ -0,0 +1,98 @@
+// Package main demonstrates two GPUs reading the same data through a shared
+// last-level cache. Run it with
+// `-timing -shared-llc-size=4194304 -gpus=1,2 -report-cache-hit-rate` and
+// check the read hits of the SharedLLC banks.
+package main
+
+import (
+	"flag"
+	"log"
+	"math/rand"
+
+	"github.com/sarchlab/mgpusim/v4/amd/driver"
+	"github.com/sarchlab/mgpusim/v4/amd/samples/runner"
+)
+
+// Benchmark defines a benchmark
+type Benchmark struct {
+	driver  *driver.Driver
+	context *driver.Context
+	gpus    []int
+
+	ByteSize uint64
+	data     []byte
+	retData  [][]byte
+}
+
+// NewBenchmark creates a new benchmark
+func NewBenchmark(driver *driver.Driver) *Benchmark {
+	b := new(Benchmark)
+	b.driver = driver
+	b.context = driver.Init()
+	return b
+}
+
+// SelectGPU selects gpu
+func (b *Benchmark) SelectGPU(gpus []int) {
+	if len(gpus) < 2 {
+		panic("shared LLC benchmark requires at least two GPUs")
+	}
+	b.gpus = gpus
+}
+
+// SetUnifiedMemory Use Unified Memory
+func (b *Benchmark) SetUnifiedMemory() {
+	panic("unified memory is not supported by the shared LLC benchmark")
+}
+
+// Run runs the benchmark. The first GPU owns the source buffer. Every GPU, one
+// after another, copies the source buffer to a buffer that it owns. The
+// source data is brought into the LLC by the first GPU, so that the other
+// GPUs read the data from the LLC rather than from the DRAM.
+func (b *Benchmark) Run() {
+	b.data = make([]byte, b.ByteSize)
+	for i := uint64(0); i < b.ByteSize; i++ {
+		b.data[i] = byte(rand.Int())
+	}
+
+	b.driver.SelectGPU(b.context, b.gpus[0])
+	src := b.driver.AllocateMemory(b.context, b.ByteSize)
+	b.driver.MemCopyH2D(b.context, src, b.data)
+
+	b.retData = make([][]byte, len(b.gpus))
+	for i, gpu := range b.gpus {
+		b.driver.SelectGPU(b.context, gpu)
+		dst := b.driver.AllocateMemory(b.context, b.ByteSize)
+		b.driver.MemCopyD2D(b.context, dst, src, int(b.ByteSize))
+
+		b.retData[i] = make([]byte, b.ByteSize)
+		b.driver.MemCopyD2H(b.context, b.retData[i], dst)
+	}
+}
+
+// Verify verifies
+func (b *Benchmark) Verify() {
+	for gpuIndex, retData := range b.retData {
+		for i := uint64(0); i < b.ByteSize; i++ {
+			if b.data[i] != retData[i] {
+				log.Panicf("error on GPU %d at %d, "+
+					"expected %02x, but get %02x",
+					b.gpus[gpuIndex], i, b.data[i], retData[i])
+			}
+		}
+	}
+	log.Printf("Passed!")
+}
+
+func main() {
+	flag.Parse()
+
+	runner := new(runner.Runner).Init()
+
+	benchmark := NewBenchmark(runner.Driver())
+	benchmark.ByteSize = 65536
+
+	runner.AddBenchmark(benchmark)
+
+	runner.Run()
+}
@@ -0,0 +1,8 @@
+// Package addresstranslator implements a component that can forward the
+// translated read and write request to the bottom memory unit.
+//
+// The package is derived from the address translator in Akita. It
+// additionally supports the pages that are larger than the base page size, and
+// the translated requests carry the PID of the page, so that the physically
+// addressed caches can tell which process owns each block.
+package addresstranslator
@@ -20,6 +20,10 @@ type Builder struct {
 	monitor        *monitoring.Monitor
 	perfAnalyzer   *analysis.PerfAnalyzer
 	numDispatchers int
+	wavefrontSize  int
+	launchOverhead int
+	wgPerCycle     int
+	wgPolicy       WGSchedulingPolicy
 }
 
 // MakeBuilder creates a new builder with default configuration values.
@@ -27,6 +31,7 @@ func MakeBuilder() Builder {
 	b := Builder{
 		freq:           1 * sim.GHz,
 		numDispatchers: 8,
+		wavefrontSize:  64,
 	}
 	return b
 }
@@ -50,6 +55,55 @@ func (b Builder) WithFreq(freq sim.Freq) Builder {
 	return b
 }
 
+// WithWavefrontSize sets the number of work-items in each wavefront that the
+// dispatchers form, which can be 32 or 64. The Compute Units must be built with
+// the same wavefront size.
+func (b Builder) WithWavefrontSize(n int) Builder {
+	if n != 32 && n != 64 {
+		panic(fmt.Sprintf("wavefront size %d is not supported", n))
+	}
+
+	b.wavefrontSize = n
+	return b
+}
+
+// WithKernelLaunchOverhead sets the number of cycles that the Command
+// Processor takes to set up each kernel before it dispatches the first
+// work-group.
+func (b Builder) WithKernelLaunchOverhead(cycles int) Builder {
+	if cycles < 0 {
+		panic("the kernel launch overhead must not be negative")
+	}
+
+	b.launchOverhead = cycles
+	return b
+}
+
+// WithWGDispatchRate sets the number of work-groups that the Command
+// Processor can dispatch to the CUs in each cycle for a kernel. By default, a
+// dispatcher waits for a few cycles after each work-group, depending on the
+// number of wavefronts in the work-group.
+func (b Builder) WithWGDispatchRate(wgPerCycle int) Builder {
+	if wgPerCycle <= 0 {
+		panic("the work-group dispatch rate must be positive")
+	}
+
+	b.wgPerCycle = wgPerCycle
+	return b
+}
+
+// WithWGSchedulingPolicy sets how the dispatchers choose the CU that each
+// work-group runs on. By default, the work-groups are assigned to the CUs in a
+// round-robin fashion.
+func (b Builder) WithWGSchedulingPolicy(policy WGSchedulingPolicy) Builder {
+	if policy < WGSchedulingRoundRobin || policy > WGSchedulingLoadBalanced {
+		panic(fmt.Sprintf("unknown work-group scheduling policy %d", policy))
+	}
+
+	b.wgPolicy = policy
+	return b
+}
+
 // WithMonitor sets the monitor used to show progress bars.
 func (b Builder) WithMonitor(monitor *monitoring.Monitor) Builder {
 	b.monitor = monitor
@@ -105,14 +159,20 @@ func (Builder) createPorts(cp *CommandProcessor, name string) {
 }
 
 func (b *Builder) buildDispatchers(cp *CommandProcessor) {
-	cuResourcePool := resource.NewCUResourcePool()
+	cuResourcePool := resource.NewCUResourcePoolWithWavefrontSize(
+		b.wavefrontSize)
+	cp.cuResourcePool = cuResourcePool
+	cp.wavefrontSize = b.wavefrontSize
 	builder := dispatching.MakeBuilder().
 		WithCP(cp).
-		WithAlg("round-robin").
+		WithAlg(b.wgPolicy.alg()).
 		WithCUResourcePool(cuResourcePool).
 		WithDispatchingPort(cp.ToCUs).
 		WithRespondingPort(cp.ToDriver).
-		WithMonitor(b.monitor)
+		WithMonitor(b.monitor).
+		WithWavefrontSize(b.wavefrontSize).
+		WithLaunchOverhead(b.launchOverhead).
+		WithWGPerCycle(b.wgPerCycle)
 
 	for i := 0; i < b.numDispatchers; i++ {
 		disp := builder.Build(fmt.Sprintf("%s.Dispatcher%d", cp.Name(), i))
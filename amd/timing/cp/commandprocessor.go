@@ -1,18 +1,22 @@
 package cp
 
 import (
+	"log"
+	"sync"
+
 	"github.com/sarchlab/akita/v4/mem/cache"
 	"github.com/sarchlab/akita/v4/mem/idealmemcontroller"
 	"github.com/sarchlab/akita/v4/mem/mem"
-	"github.com/sarchlab/akita/v4/mem/vm/tlb"
 	"github.com/sarchlab/akita/v4/sim"
 	"github.com/sarchlab/akita/v4/tracing"
+	"github.com/sarchlab/mgpusim/v4/amd/insts"
 	"github.com/sarchlab/mgpusim/v4/amd/protocol"
 	"github.com/sarchlab/mgpusim/v4/amd/sampling"
 	"github.com/sarchlab/mgpusim/v4/amd/timing/cp/internal/dispatching"
 	"github.com/sarchlab/mgpusim/v4/amd/timing/cp/internal/resource"
 	"github.com/sarchlab/mgpusim/v4/amd/timing/pagemigrationcontroller"
 	"github.com/sarchlab/mgpusim/v4/amd/timing/rdma"
+	"github.com/sarchlab/mgpusim/v4/amd/timing/tlb"
 )
 
 // CommandProcessor is an Akita component that is responsible for receiving
@@ -22,6 +26,8 @@ type CommandProcessor struct {
 	*sim.TickingComponent
 
 	Dispatchers        []dispatching.Dispatcher
+	cuResourcePool     resource.CUResourcePool
+	wavefrontSize      int
 	DMAEngine          sim.Port
 	Driver             sim.Port
 	TLBs               []sim.Port
@@ -56,9 +62,18 @@ type CommandProcessor struct {
 
 	shootDownInProcess bool
 
+	currPreemptReq *protocol.PreemptKernelReq
+	currResumeReq  *protocol.ResumeKernelReq
+	preempted      bool
+
+	currInvalidateTLBReq *protocol.InvalidateTLBReq
+
 	bottomKernelLaunchReqIDToTopReqMap map[string]*protocol.LaunchKernelReq
 	bottomMemCopyH2DReqIDToTopReqMap   map[string]*protocol.MemCopyH2DReq
 	bottomMemCopyD2HReqIDToTopReqMap   map[string]*protocol.MemCopyD2HReq
+
+	faultMutex    sync.Mutex
+	pendingFaults []error
 }
 
 // CUInterfaceForCP defines the interface that a CP requires from CU.
@@ -78,17 +93,101 @@ func (p *CommandProcessor) RegisterCU(cu CUInterfaceForCP) {
 	}
 }
 
+// GetOccupancy returns how the work-groups occupy each CU that is registered
+// to the Command Processor.
+func (p *CommandProcessor) GetOccupancy() []protocol.CUOccupancy {
+	occupancy := make([]protocol.CUOccupancy, p.cuResourcePool.NumCU())
+	for i := range occupancy {
+		occupancy[i] = p.cuResourcePool.GetCU(i).Occupancy()
+	}
+
+	return occupancy
+}
+
+// MaxResidentWGPerCU returns how many work-groups of the kernel, each with
+// the given number of work-items, can be resident on the CU with the fewest
+// resources at the same time.
+func (p *CommandProcessor) MaxResidentWGPerCU(co *insts.HsaCo, numWI int) int {
+	numWf := (numWI + p.wavefrontSize - 1) / p.wavefrontSize
+
+	maxWG := 0
+	for i := 0; i < p.cuResourcePool.NumCU(); i++ {
+		numWG := p.cuResourcePool.GetCU(i).MaxResidentWGs(co, numWf)
+		if i == 0 || numWG < maxWG {
+			maxWG = numWG
+		}
+	}
+
+	return maxWG
+}
+
+// DisableCU stops dispatching work-groups to the CU at the given index. The
+// work-groups that are resident on the CU run to completion.
+func (p *CommandProcessor) DisableCU(cuIndex int) {
+	p.mustHaveCU(cuIndex)
+	p.cuResourcePool.GetCU(cuIndex).Disable()
+}
+
+// EnableCU resumes dispatching work-groups to the CU at the given index.
+func (p *CommandProcessor) EnableCU(cuIndex int) {
+	p.mustHaveCU(cuIndex)
+	p.cuResourcePool.GetCU(cuIndex).Enable()
+
+	// The dispatchers may be waiting for a CU to become available.
+	p.TickLater()
+}
+
+func (p *CommandProcessor) mustHaveCU(cuIndex int) {
+	if cuIndex < 0 || cuIndex >= p.cuResourcePool.NumCU() {
+		log.Panicf("CU %d does not exist, the GPU has %d CUs",
+			cuIndex, p.cuResourcePool.NumCU())
+	}
+}
+
 // Tick ticks
 func (p *CommandProcessor) Tick() bool {
-	madeProgress := false
+	madeProgress := p.distributeFaults()
+
+	if !p.isPreemptedOrPreempting() {
+		madeProgress = p.tickDispatchers() || madeProgress
+	}
 
-	madeProgress = p.tickDispatchers() || madeProgress
 	madeProgress = p.processReqFromDriver() || madeProgress
 	madeProgress = p.processRspFromInternal() || madeProgress
 
 	return madeProgress
 }
 
+// ReportFault reports a fault that a memory component encounters while serving
+// a kernel. As the memory components cannot tell which kernel an access
+// belongs to, the fault is reported to all the kernels that are being
+// dispatched. ReportFault can be called by other components while the
+// simulation is running.
+func (p *CommandProcessor) ReportFault(fault error) {
+	p.faultMutex.Lock()
+	p.pendingFaults = append(p.pendingFaults, fault)
+	p.faultMutex.Unlock()
+
+	p.TickLater()
+}
+
+func (p *CommandProcessor) distributeFaults() (madeProgress bool) {
+	p.faultMutex.Lock()
+	faults := p.pendingFaults
+	p.pendingFaults = nil
+	p.faultMutex.Unlock()
+
+	for _, fault := range faults {
+		for _, d := range p.Dispatchers {
+			if d.IsDispatching() {
+				d.ReportFault(fault)
+			}
+		}
+	}
+
+	return len(faults) > 0
+}
+
 func (p *CommandProcessor) tickDispatchers() (madeProgress bool) {
 	for _, d := range p.Dispatchers {
 		madeProgress = d.Tick() || madeProgress
@@ -120,6 +219,12 @@ func (p *CommandProcessor) processReqFromDriver() bool {
 		return p.processGPURestartReq(req)
 	case *protocol.PageMigrationReqToCP:
 		return p.processPageMigrationReq(req)
+	case *protocol.PreemptKernelReq:
+		return p.processPreemptKernelReq(req)
+	case *protocol.ResumeKernelReq:
+		return p.processResumeKernelReq(req)
+	case *protocol.InvalidateTLBReq:
+		return p.processInvalidateTLBReq(req)
 	}
 
 	panic("never")
@@ -322,6 +427,14 @@ func (p *CommandProcessor) processShootdownCommand(
 	p.currShootdownRequest = cmd
 	p.shootDownInProcess = true
 
+	p.flushCUPipelines()
+
+	p.ToDriver.RetrieveIncoming()
+
+	return true
+}
+
+func (p *CommandProcessor) flushCUPipelines() {
 	for i := 0; i < len(p.CUs); i++ {
 		p.numCUAck++
 		req := protocol.CUPipelineFlushReqBuilder{}.
@@ -330,10 +443,6 @@ func (p *CommandProcessor) processShootdownCommand(
 			Build()
 		p.ToCUs.Send(req)
 	}
-
-	p.ToDriver.RetrieveIncoming()
-
-	return true
 }
 
 func (p *CommandProcessor) processCUPipelineFlushRsp(
@@ -424,6 +533,10 @@ func (p *CommandProcessor) processCacheFlushRsp(
 		if p.shootDownInProcess {
 			return p.processCacheFlushCausedByTLBShootdown(rsp)
 		}
+
+		if p.currPreemptReq != nil {
+			return p.processCacheFlushCausedByPreemption(rsp)
+		}
 		return p.processRegularCacheFlush(rsp)
 	}
 
@@ -471,6 +584,10 @@ func (p *CommandProcessor) processCacheFlushCausedByTLBShootdown(
 func (p *CommandProcessor) processTLBFlushRsp(
 	rsp *tlb.FlushRsp,
 ) bool {
+	if p.currInvalidateTLBReq != nil {
+		return p.processTLBFlushRspCausedByInvalidation(rsp)
+	}
+
 	p.numTLBAck--
 
 	if p.numTLBAck == 0 {
@@ -511,6 +628,14 @@ func (p *CommandProcessor) processRDMARestartRsp(rsp *rdma.RestartRsp) bool {
 func (p *CommandProcessor) processGPURestartReq(
 	cmd *protocol.GPURestartReq,
 ) bool {
+	p.restartAllCaches()
+
+	p.ToDriver.RetrieveIncoming()
+
+	return true
+}
+
+func (p *CommandProcessor) restartAllCaches() {
 	for _, port := range p.L2Caches {
 		p.restartCache(port)
 	}
@@ -524,10 +649,6 @@ func (p *CommandProcessor) processGPURestartReq(
 	for _, port := range p.L1VCaches {
 		p.restartCache(port)
 	}
-
-	p.ToDriver.RetrieveIncoming()
-
-	return true
 }
 
 func (p *CommandProcessor) restartCache(port sim.Port) {
@@ -548,7 +669,10 @@ func (p *CommandProcessor) processCacheRestartRsp(
 	rsp *cache.RestartRsp,
 ) bool {
 	p.numCacheACK--
-	if p.numCacheACK == 0 {
+	if p.numCacheACK == 0 && p.currResumeReq != nil {
+		// Preemption does not pause the TLBs.
+		p.restartAddressTranslators()
+	} else if p.numCacheACK == 0 {
 		for i := 0; i < len(p.TLBs); i++ {
 			p.numTLBAck++
 
@@ -568,21 +692,14 @@ func (p *CommandProcessor) processCacheRestartRsp(
 func (p *CommandProcessor) processTLBRestartRsp(
 	rsp *tlb.RestartRsp,
 ) bool {
+	if p.currInvalidateTLBReq != nil {
+		return p.processTLBRestartRspCausedByInvalidation(rsp)
+	}
+
 	p.numTLBAck--
 
 	if p.numTLBAck == 0 {
-		for i := 0; i < len(p.AddressTranslators); i++ {
-			req := mem.ControlMsgBuilder{}.
-				WithSrc(p.ToAddressTranslators.AsRemote()).
-				WithDst(p.AddressTranslators[i].AsRemote()).
-				ToRestart().
-				Build()
-			p.ToAddressTranslators.Send(req)
-
-			// fmt.Printf("Restarting %s\n", p.AddressTranslators[i].Name())
-
-			p.numAddrTranslationRestartAck++
-		}
+		p.restartAddressTranslators()
 	}
 
 	p.ToTLBs.RetrieveIncoming()
@@ -590,6 +707,21 @@ func (p *CommandProcessor) processTLBRestartRsp(
 	return true
 }
 
+func (p *CommandProcessor) restartAddressTranslators() {
+	for i := 0; i < len(p.AddressTranslators); i++ {
+		req := mem.ControlMsgBuilder{}.
+			WithSrc(p.ToAddressTranslators.AsRemote()).
+			WithDst(p.AddressTranslators[i].AsRemote()).
+			ToRestart().
+			Build()
+		p.ToAddressTranslators.Send(req)
+
+		// fmt.Printf("Restarting %s\n", p.AddressTranslators[i].Name())
+
+		p.numAddrTranslationRestartAck++
+	}
+}
+
 func (p *CommandProcessor) processAddressTranslatorRestartRsp(
 	rsp *mem.ControlMsg,
 ) bool {
@@ -618,8 +750,12 @@ func (p *CommandProcessor) processCUPipelineRestartRsp(
 	p.numCUAck--
 
 	if p.numCUAck == 0 {
-		rsp := protocol.NewGPURestartRsp(p.ToDriver, p.Driver)
-		p.ToDriver.Send(rsp)
+		if p.currResumeReq != nil {
+			p.completeResume()
+		} else {
+			rsp := protocol.NewGPURestartRsp(p.ToDriver, p.Driver)
+			p.ToDriver.Send(rsp)
+		}
 	}
 
 	p.ToCUs.RetrieveIncoming()
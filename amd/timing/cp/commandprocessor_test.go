@@ -1,19 +1,46 @@
 package cp
 
 import (
+	"errors"
+
 	"github.com/golang/mock/gomock"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"github.com/sarchlab/akita/v4/mem/cache"
 	"github.com/sarchlab/akita/v4/mem/mem"
-	"github.com/sarchlab/akita/v4/mem/vm/tlb"
 	"github.com/sarchlab/akita/v4/sim"
 	"github.com/sarchlab/mgpusim/v4/amd/protocol"
 	"github.com/sarchlab/mgpusim/v4/amd/timing/cp/internal/dispatching"
 	"github.com/sarchlab/mgpusim/v4/amd/timing/pagemigrationcontroller"
 	"github.com/sarchlab/mgpusim/v4/amd/timing/rdma"
+	"github.com/sarchlab/mgpusim/v4/amd/timing/tlb"
 )
 
+// fakeDispatchableCU provides the resources of an R9 Nano CU.
+type fakeDispatchableCU struct {
+	port sim.Port
+}
+
+func (cu *fakeDispatchableCU) DispatchingPort() sim.Port {
+	return cu.port
+}
+
+func (cu *fakeDispatchableCU) WfPoolSizes() []int {
+	return []int{10, 10, 10, 10}
+}
+
+func (cu *fakeDispatchableCU) VRegCounts() []int {
+	return []int{16384, 16384, 16384, 16384}
+}
+
+func (cu *fakeDispatchableCU) SRegCount() int {
+	return 3200
+}
+
+func (cu *fakeDispatchableCU) LDSBytes() int {
+	return 64 * 1024
+}
+
 var _ = Describe("CommandProcessor", func() {
 
 	var (
@@ -182,6 +209,61 @@ var _ = Describe("CommandProcessor", func() {
 		Expect(madeProgress).To(BeFalse())
 	})
 
+	It("should report a fault to the dispatching dispatchers", func() {
+		fault := errors.New("uncorrectable memory error")
+		engine.EXPECT().CurrentTime().Return(sim.VTimeInSec(0))
+		engine.EXPECT().Schedule(gomock.Any())
+
+		commandProcessor.ReportFault(fault)
+
+		dispatcher.EXPECT().IsDispatching().Return(true)
+		dispatcher.EXPECT().ReportFault(fault)
+
+		madeProgress := commandProcessor.distributeFaults()
+
+		Expect(madeProgress).To(BeTrue())
+		Expect(commandProcessor.distributeFaults()).To(BeFalse())
+	})
+
+	It("should panic if the CU to disable does not exist", func() {
+		Expect(func() { commandProcessor.DisableCU(0) }).To(Panic())
+	})
+
+	It("should tick after enabling a CU", func() {
+		commandProcessor.cuResourcePool.RegisterCU(&fakeDispatchableCU{
+			port: cus[0],
+		})
+		commandProcessor.DisableCU(0)
+
+		engine.EXPECT().CurrentTime().Return(sim.VTimeInSec(0))
+		engine.EXPECT().Schedule(gomock.Any())
+
+		commandProcessor.EnableCU(0)
+	})
+
+	It("should panic if the kernel launch overhead is negative", func() {
+		Expect(func() { MakeBuilder().WithKernelLaunchOverhead(-1) }).
+			To(Panic())
+	})
+
+	It("should panic if the work-group dispatch rate is not positive",
+		func() {
+			Expect(func() { MakeBuilder().WithWGDispatchRate(0) }).To(Panic())
+		})
+
+	It("should dispatch with the algorithm of the work-group scheduling policy",
+		func() {
+			Expect(WGSchedulingRoundRobin.alg()).To(Equal("round-robin"))
+			Expect(WGSchedulingFillFirst.alg()).To(Equal("greedy"))
+			Expect(WGSchedulingLoadBalanced.alg()).To(Equal("load-balanced"))
+		})
+
+	It("should panic if the work-group scheduling policy is unknown", func() {
+		Expect(func() {
+			MakeBuilder().WithWGSchedulingPolicy(WGSchedulingPolicy(100))
+		}).To(Panic())
+	})
+
 	It("should handle a RDMA drain req from driver", func() {
 		nilPort := NewMockPort(mockCtrl)
 		nilPort.EXPECT().AsRemote().AnyTimes()
@@ -476,6 +558,126 @@ var _ = Describe("CommandProcessor", func() {
 		Expect(madeProgress).To(BeTrue())
 	})
 
+	It("should flush the CU pipelines on a preempt kernel req", func() {
+		nilPort := NewMockPort(mockCtrl)
+		nilPort.EXPECT().AsRemote().AnyTimes()
+		req := protocol.NewPreemptKernelReq(nilPort, commandProcessor.ToDriver)
+
+		toCU.EXPECT().
+			Send(gomock.AssignableToTypeOf(&protocol.CUPipelineFlushReq{})).
+			Times(10)
+		toDriver.EXPECT().RetrieveIncoming()
+
+		madeProgress := commandProcessor.processPreemptKernelReq(req)
+
+		Expect(madeProgress).To(BeTrue())
+		Expect(commandProcessor.numCUAck).To(Equal(uint64(10)))
+		Expect(commandProcessor.currPreemptReq).To(BeIdenticalTo(req))
+	})
+
+	It("should respond to the driver when the caches are flushed for "+
+		"preemption", func() {
+		nilPort := NewMockPort(mockCtrl)
+		nilPort.EXPECT().AsRemote().AnyTimes()
+		req := protocol.NewPreemptKernelReq(nilPort, commandProcessor.ToDriver)
+		flushRsp := cache.FlushRspBuilder{}.Build()
+
+		commandProcessor.numCacheACK = 1
+		commandProcessor.currPreemptReq = req
+
+		toCaches.EXPECT().RetrieveIncoming()
+		toDriver.EXPECT().
+			Send(gomock.AssignableToTypeOf(&protocol.PreemptKernelRsp{})).
+			Do(func(rsp *protocol.PreemptKernelRsp) {
+				Expect(rsp.RspTo).To(Equal(req.ID))
+			})
+
+		commandProcessor.processCacheFlushRsp(flushRsp)
+
+		Expect(commandProcessor.preempted).To(BeTrue())
+		Expect(commandProcessor.currPreemptReq).To(BeNil())
+	})
+
+	It("should not dispatch work-groups when preempted", func() {
+		commandProcessor.preempted = true
+
+		toDriver.EXPECT().PeekIncoming().Return(nil)
+		toDMA.EXPECT().PeekIncoming().Return(nil)
+		toCU.EXPECT().PeekIncoming().Return(nil)
+		toAddressTranslator.EXPECT().PeekIncoming().Return(nil)
+		toCaches.EXPECT().PeekIncoming().Return(nil)
+		toTLB.EXPECT().PeekIncoming().Return(nil)
+		toRDMA.EXPECT().PeekIncoming().Return(nil)
+		toPMC.EXPECT().PeekIncoming().Return(nil)
+
+		madeProgress := commandProcessor.Tick()
+
+		Expect(madeProgress).To(BeFalse())
+	})
+
+	It("should restart the caches on a resume kernel req", func() {
+		nilPort := NewMockPort(mockCtrl)
+		nilPort.EXPECT().AsRemote().AnyTimes()
+		req := protocol.NewResumeKernelReq(nilPort, commandProcessor.ToDriver)
+
+		commandProcessor.preempted = true
+
+		toCaches.EXPECT().
+			Send(gomock.AssignableToTypeOf(&cache.RestartReq{})).
+			Times(40)
+		toDriver.EXPECT().RetrieveIncoming()
+
+		madeProgress := commandProcessor.processResumeKernelReq(req)
+
+		Expect(madeProgress).To(BeTrue())
+		Expect(commandProcessor.numCacheACK).To(Equal(uint64(40)))
+		Expect(commandProcessor.currResumeReq).To(BeIdenticalTo(req))
+	})
+
+	It("should restart the address translators without restarting the "+
+		"TLBs when resuming", func() {
+		nilPort := NewMockPort(mockCtrl)
+		nilPort.EXPECT().AsRemote().AnyTimes()
+		commandProcessor.currResumeReq = protocol.NewResumeKernelReq(
+			nilPort, commandProcessor.ToDriver)
+		commandProcessor.numCacheACK = 1
+
+		toAddressTranslator.EXPECT().Send(gomock.Any()).Times(10)
+		toCaches.EXPECT().RetrieveIncoming()
+
+		madeProgress := commandProcessor.processCacheRestartRsp(
+			cache.RestartRspBuilder{}.Build())
+
+		Expect(madeProgress).To(BeTrue())
+		Expect(commandProcessor.numTLBAck).To(Equal(uint64(0)))
+		Expect(commandProcessor.numAddrTranslationRestartAck).
+			To(Equal(uint64(10)))
+	})
+
+	It("should respond to the driver when the CUs are resumed", func() {
+		nilPort := NewMockPort(mockCtrl)
+		nilPort.EXPECT().AsRemote().AnyTimes()
+		req := protocol.NewResumeKernelReq(nilPort, commandProcessor.ToDriver)
+
+		commandProcessor.preempted = true
+		commandProcessor.currResumeReq = req
+		commandProcessor.numCUAck = 1
+
+		toDriver.EXPECT().
+			Send(gomock.AssignableToTypeOf(&protocol.ResumeKernelRsp{})).
+			Do(func(rsp *protocol.ResumeKernelRsp) {
+				Expect(rsp.RspTo).To(Equal(req.ID))
+			})
+		toCU.EXPECT().RetrieveIncoming()
+
+		madeProgress := commandProcessor.processCUPipelineRestartRsp(
+			protocol.CUPipelineRestartRspBuilder{}.Build())
+
+		Expect(madeProgress).To(BeTrue())
+		Expect(commandProcessor.preempted).To(BeFalse())
+		Expect(commandProcessor.currResumeReq).To(BeNil())
+	})
+
 	It("should handle a page migration req", func() {
 		nilPort := NewMockPort(mockCtrl)
 		nilPort.EXPECT().AsRemote().AnyTimes()
@@ -520,4 +722,64 @@ var _ = Describe("CommandProcessor", func() {
 		Expect(madeProgress).To(BeTrue())
 	})
 
+	It("should flush the TLBs on an invalidate TLB req", func() {
+		nilPort := NewMockPort(mockCtrl)
+		nilPort.EXPECT().AsRemote().AnyTimes()
+		req := protocol.NewInvalidateTLBReq(
+			nilPort, commandProcessor.ToDriver, 1, []uint64{0x1000})
+
+		toTLB.EXPECT().
+			Send(gomock.AssignableToTypeOf(&tlb.FlushReq{})).
+			Do(func(flushReq *tlb.FlushReq) {
+				Expect(flushReq.VAddr).To(Equal([]uint64{0x1000}))
+			}).
+			Times(10)
+		toDriver.EXPECT().RetrieveIncoming()
+
+		madeProgress := commandProcessor.processInvalidateTLBReq(req)
+
+		Expect(madeProgress).To(BeTrue())
+		Expect(commandProcessor.numTLBAck).To(Equal(uint64(10)))
+		Expect(commandProcessor.currInvalidateTLBReq).To(BeIdenticalTo(req))
+	})
+
+	It("should restart the TLBs after flushing them for invalidation",
+		func() {
+			nilPort := NewMockPort(mockCtrl)
+			nilPort.EXPECT().AsRemote().AnyTimes()
+			commandProcessor.currInvalidateTLBReq = protocol.NewInvalidateTLBReq(
+				nilPort, commandProcessor.ToDriver, 1, []uint64{0x1000})
+			commandProcessor.numTLBAck = 1
+
+			toTLB.EXPECT().
+				Send(gomock.AssignableToTypeOf(&tlb.RestartReq{})).
+				Times(10)
+			toTLB.EXPECT().RetrieveIncoming()
+
+			commandProcessor.processTLBFlushRsp(tlb.FlushRspBuilder{}.Build())
+
+			Expect(commandProcessor.numTLBAck).To(Equal(uint64(10)))
+		})
+
+	It("should respond to the driver when the TLBs are restarted after "+
+		"invalidation", func() {
+		nilPort := NewMockPort(mockCtrl)
+		nilPort.EXPECT().AsRemote().AnyTimes()
+		req := protocol.NewInvalidateTLBReq(
+			nilPort, commandProcessor.ToDriver, 1, []uint64{0x1000})
+		commandProcessor.currInvalidateTLBReq = req
+		commandProcessor.numTLBAck = 1
+
+		toTLB.EXPECT().RetrieveIncoming()
+		toDriver.EXPECT().
+			Send(gomock.AssignableToTypeOf(&protocol.InvalidateTLBRsp{})).
+			Do(func(rsp *protocol.InvalidateTLBRsp) {
+				Expect(rsp.RspTo).To(Equal(req.ID))
+			})
+
+		commandProcessor.processTLBRestartRsp(tlb.RestartRspBuilder{}.Build())
+
+		Expect(commandProcessor.currInvalidateTLBReq).To(BeNil())
+	})
+
 })
@@ -3,6 +3,7 @@ package cp
 import (
 	"log"
 	"reflect"
+	"sync/atomic"
 
 	"github.com/sarchlab/akita/v4/mem/mem"
 	"github.com/sarchlab/akita/v4/sim"
@@ -66,18 +67,41 @@ type DMAEngine struct {
 	Log2AccessSize uint64
 
 	localDataSource mem.AddressToPortMapper
+	l2DataSource    mem.AddressToPortMapper
 
 	processingReqs []*RequestCollection
 
-	processingReq   sim.Msg
-	maxRequestCount uint64
+	processingReq sim.Msg
+
+	// maxRequestCount is atomic as the driver can change it while the engine
+	// is running.
+	maxRequestCount atomic.Uint64
 
 	toSendToMem []sim.Msg
+	toSendToL2  []sim.Msg
 	toSendToCP  []sim.Msg
 	pendingReqs []sim.Msg
 
 	ToCP  sim.Port
 	ToMem sim.Port
+	ToL2  sim.Port
+}
+
+// SetMaxOutstanding sets the maximum number of memory copy requests that the
+// DMA engine can process at the same time. It can be called while the
+// simulation is running, and the requests being processed are not affected.
+func (dma *DMAEngine) SetMaxOutstanding(n int) {
+	if n <= 0 {
+		log.Panicf("max outstanding of DMA engine must be positive, got %d", n)
+	}
+
+	dma.maxRequestCount.Store(uint64(n))
+}
+
+// MaxOutstanding returns the maximum number of memory copy requests that the
+// DMA engine can process at the same time.
+func (dma *DMAEngine) MaxOutstanding() int {
+	return int(dma.maxRequestCount.Load())
 }
 
 // SetLocalDataSource sets the table that maps from addresses to port that can
@@ -86,13 +110,23 @@ func (dma *DMAEngine) SetLocalDataSource(s mem.AddressToPortMapper) {
 	dma.localDataSource = s
 }
 
+// SetL2DataSource sets the table that maps from addresses to the L2 caches,
+// which the copies that are written through the caches go to. The ToL2 port
+// must be connected to the L2 caches. If the table is not set, the copies are
+// written to the local data source.
+func (dma *DMAEngine) SetL2DataSource(s mem.AddressToPortMapper) {
+	dma.l2DataSource = s
+}
+
 // Tick ticks
 func (dma *DMAEngine) Tick() bool {
 	madeProgress := false
 
 	madeProgress = dma.send(dma.ToCP, &dma.toSendToCP) || madeProgress
 	madeProgress = dma.send(dma.ToMem, &dma.toSendToMem) || madeProgress
-	madeProgress = dma.parseFromMem() || madeProgress
+	madeProgress = dma.send(dma.ToL2, &dma.toSendToL2) || madeProgress
+	madeProgress = dma.parseFromMem(dma.ToMem) || madeProgress
+	madeProgress = dma.parseFromMem(dma.ToL2) || madeProgress
 	madeProgress = dma.parseFromCP() || madeProgress
 
 	return madeProgress
@@ -116,8 +150,8 @@ func (dma *DMAEngine) send(
 	return false
 }
 
-func (dma *DMAEngine) parseFromMem() bool {
-	req := dma.ToMem.RetrieveIncoming()
+func (dma *DMAEngine) parseFromMem(port sim.Port) bool {
+	req := port.RetrieveIncoming()
 	if req == nil {
 		return false
 	}
@@ -242,7 +276,7 @@ func (dma *DMAEngine) removeReqFromProcessingReqList(id string) {
 }
 
 func (dma *DMAEngine) parseFromCP() bool {
-	if uint64(len(dma.processingReqs)) >= dma.maxRequestCount {
+	if uint64(len(dma.processingReqs)) >= dma.maxRequestCount.Load() {
 		return false
 	}
 
@@ -271,6 +305,11 @@ func (dma *DMAEngine) parseMemCopyH2D(
 	req *protocol.MemCopyH2DReq,
 	rqC *RequestCollection,
 ) {
+	port, dataSource, toSend := dma.ToMem, dma.localDataSource, &dma.toSendToMem
+	if req.ThroughCache && dma.l2DataSource != nil {
+		port, dataSource, toSend = dma.ToL2, dma.l2DataSource, &dma.toSendToL2
+	}
+
 	offset := uint64(0)
 	lengthLeft := uint64(len(req.SrcBuffer))
 	addr := req.DstAddress
@@ -285,14 +324,14 @@ func (dma *DMAEngine) parseMemCopyH2D(
 			length = lengthInUnit
 		}
 
-		module := dma.localDataSource.Find(addr)
+		module := dataSource.Find(addr)
 		reqToBottom := mem.WriteReqBuilder{}.
-			WithSrc(dma.ToMem.AsRemote()).
+			WithSrc(port.AsRemote()).
 			WithDst(module).
 			WithAddress(addr).
 			WithData(req.SrcBuffer[offset : offset+length]).
 			Build()
-		dma.toSendToMem = append(dma.toSendToMem, reqToBottom)
+		*toSend = append(*toSend, reqToBottom)
 		dma.pendingReqs = append(dma.pendingReqs, reqToBottom)
 		rqC.appendSubordinateID(reqToBottom.Meta().ID)
 
@@ -343,6 +382,11 @@ func (dma *DMAEngine) parseMemCopyD2H(
 	}
 }
 
+// SetFreq sets the frequency that the DMA engine ticks at.
+func (dma *DMAEngine) SetFreq(freq sim.Freq) {
+	dma.TickingComponent.Freq = freq
+}
+
 // NewDMAEngine creates a DMAEngine, injecting a engine and a "LowModuleFinder"
 // that helps with locating the module that holds the data.
 func NewDMAEngine(
@@ -357,10 +401,11 @@ func NewDMAEngine(
 	dma.Log2AccessSize = 6
 	dma.localDataSource = localDataSource
 
-	dma.maxRequestCount = 4
+	dma.maxRequestCount.Store(4)
 
 	dma.ToCP = sim.NewPort(dma, 40960000, 40960000, name+".ToCP")
 	dma.ToMem = sim.NewPort(dma, 64, 64, name+".ToMem")
+	dma.ToL2 = sim.NewPort(dma, 64, 64, name+".ToL2")
 
 	return dma
 }
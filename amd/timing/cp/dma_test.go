@@ -4,8 +4,10 @@ import (
 	"github.com/golang/mock/gomock"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/sarchlab/akita/v4/mem/idealmemcontroller"
 	"github.com/sarchlab/akita/v4/mem/mem"
 	"github.com/sarchlab/akita/v4/sim"
+	"github.com/sarchlab/akita/v4/sim/directconnection"
 	"github.com/sarchlab/mgpusim/v4/amd/protocol"
 )
 
@@ -42,7 +44,7 @@ var _ = Describe("DMAEngine", func() {
 		nilPort := NewMockPort(mockCtrl)
 		nilPort.EXPECT().AsRemote().AnyTimes()
 
-		for i := 0; i < int(dmaEngine.maxRequestCount); i++ {
+		for i := 0; i < dmaEngine.MaxOutstanding(); i++ {
 			srcBuf := make([]byte, 128)
 			req := protocol.NewMemCopyH2DReq(nilPort, toCP, srcBuf, uint64(20+128*i))
 			rqC := NewRequestCollection(req)
@@ -79,6 +81,29 @@ var _ = Describe("DMAEngine", func() {
 		Expect(dmaEngine.pendingReqs).To(HaveLen(3))
 	})
 
+	It("should write a MemCopyH2D through the L2 caches", func() {
+		nilPort := NewMockPort(mockCtrl)
+		nilPort.EXPECT().AsRemote().AnyTimes()
+
+		toL2 := NewMockPort(mockCtrl)
+		toL2.EXPECT().AsRemote().AnyTimes()
+		dmaEngine.ToL2 = toL2
+		dmaEngine.SetL2DataSource(new(mem.SinglePortMapper))
+
+		req := protocol.NewMemCopyH2DReq(nilPort, toCP, make([]byte, 4), 20)
+		req.ThroughCache = true
+
+		toCP.EXPECT().RetrieveIncoming().Return(req)
+
+		madeProgress := dmaEngine.parseFromCP()
+
+		Expect(madeProgress).To(BeTrue())
+		Expect(dmaEngine.toSendToMem).To(BeEmpty())
+		Expect(dmaEngine.toSendToL2).To(HaveLen(1))
+		Expect(dmaEngine.toSendToL2[0].(*mem.WriteReq).Address).
+			To(Equal(uint64(20)))
+	})
+
 	It("should parse MemCopyD2H from CP", func() {
 		nilPort := NewMockPort(mockCtrl)
 		nilPort.EXPECT().AsRemote().AnyTimes()
@@ -148,7 +173,7 @@ var _ = Describe("DMAEngine", func() {
 			}).Build()
 		toMem.EXPECT().RetrieveIncoming().Return(dataReady)
 
-		madeProgress := dmaEngine.parseFromMem()
+		madeProgress := dmaEngine.parseFromMem(toMem)
 
 		Expect(madeProgress).To(BeTrue())
 		Expect(dmaEngine.processingReqs[0].superiorRequest).To(BeIdenticalTo(req))
@@ -192,7 +217,7 @@ var _ = Describe("DMAEngine", func() {
 			Build()
 		toMem.EXPECT().RetrieveIncoming().Return(dataReady)
 
-		madeProgress := dmaEngine.parseFromMem()
+		madeProgress := dmaEngine.parseFromMem(toMem)
 
 		Expect(madeProgress).To(BeTrue())
 		Expect(dmaEngine.processingReqs).To(BeEmpty())
@@ -238,7 +263,7 @@ var _ = Describe("DMAEngine", func() {
 
 		toMem.EXPECT().RetrieveIncoming().Return(done)
 
-		madeProgress := dmaEngine.parseFromMem()
+		madeProgress := dmaEngine.parseFromMem(toMem)
 
 		Expect(madeProgress).To(BeTrue())
 		Expect(dmaEngine.processingReqs[0].superiorRequest).To(BeIdenticalTo(req))
@@ -271,7 +296,7 @@ var _ = Describe("DMAEngine", func() {
 
 		toMem.EXPECT().RetrieveIncoming().Return(done)
 
-		madeProgress := dmaEngine.parseFromMem()
+		madeProgress := dmaEngine.parseFromMem(toMem)
 
 		Expect(madeProgress).To(BeTrue())
 		Expect(dmaEngine.processingReqs).To(BeEmpty())
@@ -280,3 +305,100 @@ var _ = Describe("DMAEngine", func() {
 			To(BeIdenticalTo(req))
 	})
 })
+
+type dmaTestCP struct {
+	*sim.TickingComponent
+
+	port    sim.Port
+	numDone int
+}
+
+func (c *dmaTestCP) Tick() bool {
+	msg := c.port.RetrieveIncoming()
+	if msg == nil {
+		return false
+	}
+
+	c.numDone++
+
+	return true
+}
+
+var _ = Describe("DMAEngine Bandwidth", func() {
+	const (
+		numCopies = 16
+		copySize  = 1024
+	)
+
+	measureCopyTime := func(maxOutstanding int) sim.VTimeInSec {
+		engine := sim.NewSerialEngine()
+
+		cp := &dmaTestCP{}
+		cp.TickingComponent = sim.NewTickingComponent(
+			"CP", engine, 1*sim.GHz, cp)
+		cp.port = sim.NewPort(cp, numCopies, numCopies, "CP.ToDMA")
+
+		memCtrl := idealmemcontroller.MakeBuilder().
+			WithEngine(engine).
+			WithNewStorage(1 * mem.MB).
+			WithLatency(100).
+			Build("Mem")
+
+		dma := NewDMAEngine("DMA", engine, &mem.SinglePortMapper{
+			Port: memCtrl.GetPortByName("Top").AsRemote(),
+		})
+		dma.SetMaxOutstanding(maxOutstanding)
+
+		conn := directconnection.MakeBuilder().
+			WithEngine(engine).
+			WithFreq(1 * sim.GHz).
+			Build("Conn")
+		conn.PlugIn(cp.port)
+		conn.PlugIn(dma.ToCP)
+		conn.PlugIn(dma.ToMem)
+		conn.PlugIn(memCtrl.GetPortByName("Top"))
+
+		for i := 0; i < numCopies; i++ {
+			req := protocol.NewMemCopyH2DReq(cp.port, dma.ToCP,
+				make([]byte, copySize), uint64(i*copySize))
+			Expect(cp.port.Send(req)).To(BeNil())
+		}
+
+		Expect(engine.Run()).To(Succeed())
+		Expect(cp.numDone).To(Equal(numCopies))
+
+		return engine.CurrentTime()
+	}
+
+	It("should increase bandwidth with more outstanding transfers", func() {
+		time1 := measureCopyTime(1)
+		time2 := measureCopyTime(2)
+		time4 := measureCopyTime(4)
+		time8 := measureCopyTime(8)
+		time16 := measureCopyTime(16)
+
+		Expect(time2).To(BeNumerically("<", time1))
+		Expect(time4).To(BeNumerically("<", time2))
+		Expect(time8).To(BeNumerically("<", time4))
+
+		// With 8 outstanding copies, the DMA engine already issues one
+		// request to the memory per cycle, which is the limit of the link.
+		Expect(time16).To(BeNumerically("~", time8, time8*0.1))
+	})
+
+	It("should report the max outstanding transfers", func() {
+		dma := NewDMAEngine("DMA", sim.NewSerialEngine(), nil)
+
+		dma.SetMaxOutstanding(8)
+
+		Expect(dma.MaxOutstanding()).To(Equal(8))
+		Expect(func() { dma.SetMaxOutstanding(0) }).To(Panic())
+	})
+	It("should tick at the given frequency", func() {
+		dma := NewDMAEngine("DMA", sim.NewSerialEngine(), nil)
+
+		dma.SetFreq(1500 * sim.MHz)
+
+		Expect(dma.Freq).To(Equal(1500 * sim.MHz))
+	})
+})
@@ -17,12 +17,16 @@ type Builder struct {
 	respondingPort  sim.Port
 	dispatchingPort sim.Port
 	monitor         *monitoring.Monitor
+	wavefrontSize   int
+	launchOverhead  int
+	wgPerCycle      int
 }
 
 // MakeBuilder creates a builder with default dispatching configureations.
 func MakeBuilder() Builder {
 	b := Builder{
-		alg: "partition",
+		alg:           "partition",
+		wavefrontSize: 64,
 	}
 	return b
 }
@@ -56,7 +60,7 @@ func (b Builder) WithDispatchingPort(p sim.Port) Builder {
 // WithAlg sets the dispatching algorithm.
 func (b Builder) WithAlg(alg string) Builder {
 	switch alg {
-	case "round-robin", "greedy", "partition":
+	case "round-robin", "greedy", "load-balanced", "partition":
 		b.alg = alg
 	default:
 		panic("unknown dispatching algorithm " + alg)
@@ -65,6 +69,28 @@ func (b Builder) WithAlg(alg string) Builder {
 	return b
 }
 
+// WithWavefrontSize sets the number of work-items in a wavefront, which can be
+// 32 or 64.
+func (b Builder) WithWavefrontSize(n int) Builder {
+	b.wavefrontSize = n
+	return b
+}
+
+// WithLaunchOverhead sets the number of cycles that the dispatcher waits after
+// receiving a kernel before it dispatches the first work-group.
+func (b Builder) WithLaunchOverhead(cycles int) Builder {
+	b.launchOverhead = cycles
+	return b
+}
+
+// WithWGPerCycle sets the number of work-groups that the dispatcher can
+// dispatch in each cycle. Use 0 to wait for a latency that depends on the
+// number of wavefronts after dispatching each work-group.
+func (b Builder) WithWGPerCycle(n int) Builder {
+	b.wgPerCycle = n
+	return b
+}
+
 // WithMonitor sets the monitor that manages progress bars.
 func (b Builder) WithMonitor(monitor *monitoring.Monitor) Builder {
 	b.monitor = monitor
@@ -88,23 +114,31 @@ func (b Builder) Build(name string) Dispatcher {
 			13, 14, 15, 16,
 		},
 		constantKernelOverhead: 0,
+		launchOverhead:         b.launchOverhead,
+		wgPerCycle:             b.wgPerCycle,
 		monitor:                b.monitor,
 	}
 
 	switch b.alg {
 	case "round-robin":
 		d.alg = &roundRobinAlgorithm{
-			gridBuilder: kernels.NewGridBuilder(),
+			gridBuilder: kernels.NewGridBuilderWithWavefrontSize(b.wavefrontSize),
 			cuPool:      b.cuResourcePool,
 		}
 	case "greedy":
 		d.alg = &greedyAlgorithm{
-			gridBuilder: kernels.NewGridBuilder(),
+			gridBuilder: kernels.NewGridBuilderWithWavefrontSize(b.wavefrontSize),
+			cuPool:      b.cuResourcePool,
+		}
+	case "load-balanced":
+		d.alg = &loadBalancedAlgorithm{
+			gridBuilder: kernels.NewGridBuilderWithWavefrontSize(b.wavefrontSize),
 			cuPool:      b.cuResourcePool,
 		}
 	case "partition":
 		d.alg = &partitionAlgorithm{
-			cuPool: b.cuResourcePool,
+			cuPool:        b.cuResourcePool,
+			wavefrontSize: b.wavefrontSize,
 		}
 	default:
 		panic("unknown dispatching algorithm " + b.alg)
@@ -20,6 +20,7 @@ type Dispatcher interface {
 	RegisterCU(cu resource.DispatchableCU)
 	IsDispatching() bool
 	StartDispatching(req *protocol.LaunchKernelReq)
+	ReportFault(fault error)
 	Tick() (madeProgress bool)
 }
 
@@ -41,6 +42,9 @@ type DispatcherImpl struct {
 	originalReqs           map[string]*protocol.MapWGReq
 	latencyTable           []int
 	constantKernelOverhead int
+	launchOverhead         int
+	wgPerCycle             int
+	fault                  error
 
 	monitor     *monitoring.Monitor
 	progressBar *monitoring.ProgressBar
@@ -70,15 +74,28 @@ func (d *DispatcherImpl) StartDispatching(req *protocol.LaunchKernelReq) {
 		Packet:     req.Packet,
 		PacketAddr: req.PacketAddress,
 		WGFilter:   req.WGFilter,
+
+		MaxWorkGroupSize: req.MaxWorkGroupSize,
 	})
 	d.dispatching = req
 
 	d.numDispatchedWGs = 0
 	d.numCompletedWGs = 0
+	d.fault = nil
+	d.cycleLeft = d.launchOverhead
 
 	d.initializeProgressBar(req.ID)
 }
 
+// ReportFault records a fault that the kernel being dispatched encounters
+// outside of the compute units. The kernel completes with the first fault
+// that is recorded.
+func (d *DispatcherImpl) ReportFault(fault error) {
+	if d.fault == nil {
+		d.fault = fault
+	}
+}
+
 func (d *DispatcherImpl) initializeProgressBar(kernelID string) {
 	if d.monitor != nil {
 		d.progressBar = d.monitor.CreateProgressBar(
@@ -105,7 +122,7 @@ func (d *DispatcherImpl) Tick() (madeProgress bool) {
 		if d.kernelCompleted() {
 			madeProgress = d.completeKernel() || madeProgress
 		} else {
-			madeProgress = d.dispatchNextWG() || madeProgress
+			madeProgress = d.dispatchWGs() || madeProgress
 		}
 	}
 
@@ -148,6 +165,10 @@ func (d *DispatcherImpl) processMessagesFromCU() bool {
 			log.Panic("In emulation all finished WGs from more than one dispatcher")
 		}
 
+		if msg.Fault != nil && d.fault == nil {
+			d.fault = msg.Fault
+		}
+
 		for _, rspToID := range msg.RspTo {
 			location := d.inflightWGs[rspToID]
 			d.alg.FreeResources(location)
@@ -195,6 +216,7 @@ func (d *DispatcherImpl) completeKernel() (
 	req := d.dispatching
 
 	rsp := protocol.NewLaunchKernelRsp(req.Dst, req.Src, req.ID)
+	rsp.Fault = d.fault
 
 	err := d.respondingPort.Send(rsp)
 	if err == nil {
@@ -212,6 +234,25 @@ func (d *DispatcherImpl) completeKernel() (
 	return false
 }
 
+// dispatchWGs dispatches up to wgPerCycle work-groups in a cycle. Without a
+// dispatch rate, the dispatcher dispatches one work-group and waits for the
+// latency of the work-group size.
+func (d *DispatcherImpl) dispatchWGs() (madeProgress bool) {
+	if d.wgPerCycle == 0 {
+		return d.dispatchNextWG()
+	}
+
+	for i := 0; i < d.wgPerCycle; i++ {
+		if !d.dispatchNextWG() {
+			break
+		}
+
+		madeProgress = true
+	}
+
+	return madeProgress
+}
+
 func (d *DispatcherImpl) dispatchNextWG() (madeProgress bool) {
 	if !d.currWG.valid {
 		if !d.alg.HasNext() {
@@ -241,7 +282,9 @@ func (d *DispatcherImpl) dispatchNextWG() (madeProgress bool) {
 		d.numDispatchedWGs++
 		d.inflightWGs[req.ID] = d.currWG
 		d.originalReqs[req.ID] = req
-		d.cycleLeft = d.latencyTable[len(d.currWG.locations)]
+		if d.wgPerCycle == 0 {
+			d.cycleLeft = d.latencyTable[len(d.currWG.locations)]
+		}
 
 		if d.progressBar != nil {
 			d.progressBar.IncrementInProgress(1)
@@ -1,6 +1,8 @@
 package dispatching
 
 import (
+	"errors"
+
 	"github.com/golang/mock/gomock"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -74,6 +76,53 @@ var _ = Describe("Dispatcher", func() {
 		Expect(dispatcher.dispatching).To(BeIdenticalTo(req))
 	})
 
+	It("should build a dispatcher with the given launch overhead", func() {
+		d := MakeBuilder().
+			WithCP(cp).
+			WithDispatchingPort(dispatchingPort).
+			WithRespondingPort(respondingPort).
+			WithLaunchOverhead(100).
+			Build("dispatcher").(*DispatcherImpl)
+
+		Expect(d.launchOverhead).To(Equal(100))
+	})
+
+	It("should build a dispatcher with the given dispatch rate", func() {
+		d := MakeBuilder().
+			WithCP(cp).
+			WithDispatchingPort(dispatchingPort).
+			WithRespondingPort(respondingPort).
+			WithWGPerCycle(8).
+			Build("dispatcher").(*DispatcherImpl)
+
+		Expect(d.wgPerCycle).To(Equal(8))
+	})
+
+	It("should build a load-balanced dispatcher", func() {
+		d := MakeBuilder().
+			WithCP(cp).
+			WithDispatchingPort(dispatchingPort).
+			WithRespondingPort(respondingPort).
+			WithAlg("load-balanced").
+			Build("dispatcher").(*DispatcherImpl)
+
+		Expect(d.alg).To(BeAssignableToTypeOf(&loadBalancedAlgorithm{}))
+	})
+
+	It("should wait for the launch overhead before dispatching", func() {
+		nilPort := NewMockPort(ctrl)
+		nilPort.EXPECT().AsRemote().AnyTimes()
+
+		req := protocol.NewLaunchKernelReq(nilPort, respondingPort)
+		dispatcher.launchOverhead = 100
+
+		alg.EXPECT().StartNewKernel(gomock.Any())
+
+		dispatcher.StartDispatching(req)
+
+		Expect(dispatcher.cycleLeft).To(Equal(100))
+	})
+
 	It("should panic if the dispatcher is dispatching another kernel", func() {
 		nilPort := NewMockPort(ctrl)
 		nilPort.EXPECT().AsRemote().AnyTimes()
@@ -108,6 +157,30 @@ var _ = Describe("Dispatcher", func() {
 		Expect(dispatcher.cycleLeft).NotTo(Equal(0))
 	})
 
+	It("should dispatch multiple work-groups in a cycle", func() {
+		nilPort := NewMockPort(ctrl)
+		nilPort.EXPECT().AsRemote().AnyTimes()
+
+		req := protocol.NewLaunchKernelReq(nilPort, respondingPort)
+		dispatcher.dispatching = req
+		dispatcher.wgPerCycle = 2
+
+		alg.EXPECT().HasNext().Return(true).AnyTimes()
+		alg.EXPECT().Next().Return(dispatchLocation{
+			valid: true,
+			cu:    nilPort,
+		}).Times(2)
+		dispatchingPort.EXPECT().PeekIncoming().Return(nil)
+		dispatchingPort.EXPECT().Send(gomock.Any()).Return(nil).Times(2)
+
+		madeProgress := dispatcher.Tick()
+
+		Expect(madeProgress).To(BeTrue())
+		Expect(dispatcher.numDispatchedWGs).To(Equal(2))
+		Expect(dispatcher.inflightWGs).To(HaveLen(2))
+		Expect(dispatcher.cycleLeft).To(Equal(0))
+	})
+
 	It("should wait until cycle left becomes 0", func() {
 		nilPort := NewMockPort(ctrl)
 		nilPort.EXPECT().AsRemote().AnyTimes()
@@ -300,6 +373,76 @@ var _ = Describe("Dispatcher", func() {
 		Expect(dispatcher.dispatching).To(BeNil())
 	})
 
+	It("should record the fault reported by a CU", func() {
+		nilPort := NewMockPort(ctrl)
+		nilPort.EXPECT().AsRemote().AnyTimes()
+
+		req := protocol.NewLaunchKernelReq(nilPort, respondingPort)
+		dispatcher.dispatching = req
+
+		mapWGReq := protocol.MapWGReqBuilder{}.Build()
+		location := dispatchLocation{}
+		dispatcher.inflightWGs[mapWGReq.ID] = location
+		dispatcher.originalReqs[mapWGReq.ID] = mapWGReq
+
+		fault := errors.New("illegal memory address 0x0 on CU")
+		wgCompletionMsg := protocol.WGCompletionMsgBuilder{}.
+			WithRspTo([]string{mapWGReq.ID}).
+			WithFault(fault).
+			Build()
+
+		dispatcher.numDispatchedWGs = 64
+		dispatcher.numCompletedWGs = 48
+
+		alg.EXPECT().HasNext().Return(false).AnyTimes()
+		alg.EXPECT().NumWG().Return(64)
+		alg.EXPECT().FreeResources(location)
+		dispatchingPort.EXPECT().
+			PeekIncoming().
+			Return(wgCompletionMsg)
+		dispatchingPort.EXPECT().
+			RetrieveIncoming()
+
+		dispatcher.Tick()
+
+		Expect(dispatcher.fault).To(BeIdenticalTo(fault))
+	})
+
+	It("should keep the first fault that is reported", func() {
+		first := errors.New("uncorrectable memory error at 0x40")
+		dispatcher.ReportFault(first)
+		dispatcher.ReportFault(errors.New("uncorrectable memory error at 0x80"))
+
+		Expect(dispatcher.fault).To(BeIdenticalTo(first))
+	})
+
+	It("should send the fault to the driver when a kernel is completed",
+		func() {
+			nilPort := NewMockPort(ctrl)
+			nilPort.EXPECT().AsRemote().AnyTimes()
+
+			req := protocol.NewLaunchKernelReq(nilPort, respondingPort)
+			dispatcher.dispatching = req
+
+			fault := errors.New("illegal memory address 0x0 on CU")
+			dispatcher.fault = fault
+			dispatcher.numDispatchedWGs = 64
+			dispatcher.numCompletedWGs = 64
+
+			alg.EXPECT().HasNext().Return(false).AnyTimes()
+			dispatchingPort.EXPECT().PeekIncoming().Return(nil)
+			respondingPort.EXPECT().
+				Send(gomock.Any()).
+				Do(func(rsp *protocol.LaunchKernelRsp) {
+					Expect(rsp.Fault).To(BeIdenticalTo(fault))
+				}).
+				Return(nil)
+
+			madeProgress := dispatcher.Tick()
+
+			Expect(madeProgress).To(BeTrue())
+		})
+
 	It("should wait if response is failed to send", func() {
 		nilPort := NewMockPort(ctrl)
 		nilPort.EXPECT().AsRemote().AnyTimes()
@@ -0,0 +1,99 @@
+package dispatching
+
+import (
+	"sort"
+
+	"github.com/sarchlab/mgpusim/v4/amd/kernels"
+	"github.com/sarchlab/mgpusim/v4/amd/protocol"
+	"github.com/sarchlab/mgpusim/v4/amd/timing/cp/internal/resource"
+)
+
+// loadBalancedAlgorithm dispatches each work-group to the CU that has the
+// fewest resident wavefronts.
+type loadBalancedAlgorithm struct {
+	gridBuilder kernels.GridBuilder
+	cuPool      resource.CUResourcePool
+
+	currWG           *kernels.WorkGroup
+	numDispatchedWGs int
+}
+
+// RegisterCU allows the loadBalancedAlgorithm to dispatch work-group to the
+// CU.
+func (a *loadBalancedAlgorithm) RegisterCU(cu resource.DispatchableCU) {
+	a.cuPool.RegisterCU(cu)
+}
+
+// StartNewKernel lets the algorithms to start dispatching a new kernel.
+func (a *loadBalancedAlgorithm) StartNewKernel(info kernels.KernelLaunchInfo) {
+	a.numDispatchedWGs = 0
+	a.gridBuilder.SetKernel(info)
+}
+
+// NumWG returns the number of work-groups in the currently-dispatching
+// work-group.
+func (a *loadBalancedAlgorithm) NumWG() int {
+	return a.gridBuilder.NumWG()
+}
+
+// HasNext check if there are more work-groups to dispatch.
+func (a *loadBalancedAlgorithm) HasNext() bool {
+	return a.numDispatchedWGs < a.gridBuilder.NumWG()
+}
+
+// Next finds the location to dispatch the next work-group. The CUs are tried
+// from the least loaded one. The CUs with the same load are tried in the
+// order of their IDs.
+func (a *loadBalancedAlgorithm) Next() (location dispatchLocation) {
+	if a.currWG == nil {
+		a.currWG = a.gridBuilder.NextWG()
+	}
+
+	for _, cuID := range a.cuIDsByLoad() {
+		cu := a.cuPool.GetCU(cuID)
+
+		locations, ok := cu.ReserveResourceForWG(a.currWG)
+		if ok {
+			dispatch := dispatchLocation{
+				valid: true,
+				cu:    cu.DispatchingPort(),
+				cuID:  cuID,
+				wg:    a.currWG,
+			}
+			dispatch.locations =
+				make([]protocol.WfDispatchLocation, len(locations))
+			for i, localtion := range locations {
+				dispatch.locations[i] = protocol.WfDispatchLocation(localtion)
+			}
+
+			a.currWG = nil
+			a.numDispatchedWGs++
+
+			return dispatch
+		}
+	}
+
+	return dispatchLocation{}
+}
+
+func (a *loadBalancedAlgorithm) cuIDsByLoad() []int {
+	numCU := a.cuPool.NumCU()
+	cuIDs := make([]int, numCU)
+	numWfs := make([]int, numCU)
+
+	for i := 0; i < numCU; i++ {
+		cuIDs[i] = i
+		numWfs[i] = a.cuPool.GetCU(i).Occupancy().NumWf
+	}
+
+	sort.SliceStable(cuIDs, func(i, j int) bool {
+		return numWfs[cuIDs[i]] < numWfs[cuIDs[j]]
+	})
+
+	return cuIDs
+}
+
+// FreeResources marks the dispatched location to be available.
+func (a *loadBalancedAlgorithm) FreeResources(location dispatchLocation) {
+	a.cuPool.GetCU(location.cuID).FreeResourcesForWG(location.wg)
+}
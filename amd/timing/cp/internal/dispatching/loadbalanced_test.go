@@ -0,0 +1,108 @@
+package dispatching
+
+import (
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/sarchlab/mgpusim/v4/amd/kernels"
+	"github.com/sarchlab/mgpusim/v4/amd/protocol"
+	"github.com/sarchlab/mgpusim/v4/amd/timing/cp/internal/resource"
+)
+
+var _ = Describe("Load Balanced Algorithm", func() {
+	var (
+		ctrl        *gomock.Controller
+		gridBuilder *MockGridBuilder
+		pool        *MockCUResourcePool
+		cus         []*MockCUResource
+		alg         *loadBalancedAlgorithm
+	)
+
+	BeforeEach(func() {
+		ctrl = gomock.NewController(GinkgoT())
+		gridBuilder = NewMockGridBuilder(ctrl)
+
+		cus = make([]*MockCUResource, 3)
+		for i := 0; i < 3; i++ {
+			cus[i] = NewMockCUResource(ctrl)
+			cus[i].EXPECT().DispatchingPort().Return(nil).AnyTimes()
+		}
+
+		pool = NewMockCUResourcePool(ctrl)
+		pool.EXPECT().NumCU().Return(len(cus)).AnyTimes()
+		pool.EXPECT().
+			GetCU(gomock.Any()).
+			DoAndReturn(func(i int) resource.CUResource {
+				return cus[i]
+			}).
+			AnyTimes()
+
+		alg = &loadBalancedAlgorithm{
+			gridBuilder: gridBuilder,
+			cuPool:      pool,
+		}
+	})
+
+	AfterEach(func() {
+		ctrl.Finish()
+	})
+
+	expectNumWfs := func(numWfs ...int) {
+		for i, n := range numWfs {
+			cus[i].EXPECT().Occupancy().
+				Return(protocol.CUOccupancy{NumWf: n}).AnyTimes()
+		}
+	}
+
+	It("should dispatch to the CU with the fewest wavefronts", func() {
+		wg := kernels.NewWorkGroup()
+		expectNumWfs(4, 1, 2)
+
+		gridBuilder.EXPECT().NextWG().Return(wg)
+		cus[1].EXPECT().ReserveResourceForWG(wg).
+			Return([]resource.WfLocation{}, true)
+
+		location := alg.Next()
+
+		Expect(location.valid).To(BeTrue())
+		Expect(location.cuID).To(Equal(1))
+		Expect(alg.numDispatchedWGs).To(Equal(1))
+	})
+
+	It("should try the more loaded CUs if the least loaded one is full",
+		func() {
+			wg := kernels.NewWorkGroup()
+			expectNumWfs(4, 1, 1)
+
+			gridBuilder.EXPECT().NextWG().Return(wg)
+			call1 := cus[1].EXPECT().ReserveResourceForWG(wg).
+				Return([]resource.WfLocation{}, false)
+			call2 := cus[2].EXPECT().ReserveResourceForWG(wg).
+				Return([]resource.WfLocation{}, false).
+				After(call1)
+			cus[0].EXPECT().ReserveResourceForWG(wg).
+				Return([]resource.WfLocation{}, true).
+				After(call2)
+
+			location := alg.Next()
+
+			Expect(location.valid).To(BeTrue())
+			Expect(location.cuID).To(Equal(0))
+		})
+
+	It("should return invalid location if dispatch is not possible", func() {
+		wg := kernels.NewWorkGroup()
+		expectNumWfs(0, 0, 0)
+
+		gridBuilder.EXPECT().NextWG().Return(wg)
+		for _, cu := range cus {
+			cu.EXPECT().ReserveResourceForWG(wg).
+				Return([]resource.WfLocation{}, false)
+		}
+
+		location := alg.Next()
+
+		Expect(location.valid).To(BeFalse())
+		Expect(alg.numDispatchedWGs).To(Equal(0))
+	})
+})
@@ -8,7 +8,9 @@ import (
 
 	gomock "github.com/golang/mock/gomock"
 	sim "github.com/sarchlab/akita/v4/sim"
+	insts "github.com/sarchlab/mgpusim/v4/amd/insts"
 	kernels "github.com/sarchlab/mgpusim/v4/amd/kernels"
+	protocol "github.com/sarchlab/mgpusim/v4/amd/protocol"
 	resource "github.com/sarchlab/mgpusim/v4/amd/timing/cp/internal/resource"
 )
 
@@ -98,6 +100,18 @@ func (m *MockCUResource) EXPECT() *MockCUResourceMockRecorder {
 	return m.recorder
 }
 
+// Disable mocks base method.
+func (m *MockCUResource) Disable() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Disable")
+}
+
+// Disable indicates an expected call of Disable.
+func (mr *MockCUResourceMockRecorder) Disable() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Disable", reflect.TypeOf((*MockCUResource)(nil).Disable))
+}
+
 // DispatchingPort mocks base method.
 func (m *MockCUResource) DispatchingPort() sim.Port {
 	m.ctrl.T.Helper()
@@ -112,6 +126,18 @@ func (mr *MockCUResourceMockRecorder) DispatchingPort() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DispatchingPort", reflect.TypeOf((*MockCUResource)(nil).DispatchingPort))
 }
 
+// Enable mocks base method.
+func (m *MockCUResource) Enable() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Enable")
+}
+
+// Enable indicates an expected call of Enable.
+func (mr *MockCUResourceMockRecorder) Enable() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Enable", reflect.TypeOf((*MockCUResource)(nil).Enable))
+}
+
 // FreeResourcesForWG mocks base method.
 func (m *MockCUResource) FreeResourcesForWG(arg0 *kernels.WorkGroup) {
 	m.ctrl.T.Helper()
@@ -124,6 +150,34 @@ func (mr *MockCUResourceMockRecorder) FreeResourcesForWG(arg0 interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FreeResourcesForWG", reflect.TypeOf((*MockCUResource)(nil).FreeResourcesForWG), arg0)
 }
 
+// MaxResidentWGs mocks base method.
+func (m *MockCUResource) MaxResidentWGs(arg0 *insts.HsaCo, arg1 int) int {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MaxResidentWGs", arg0, arg1)
+	ret0, _ := ret[0].(int)
+	return ret0
+}
+
+// MaxResidentWGs indicates an expected call of MaxResidentWGs.
+func (mr *MockCUResourceMockRecorder) MaxResidentWGs(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MaxResidentWGs", reflect.TypeOf((*MockCUResource)(nil).MaxResidentWGs), arg0, arg1)
+}
+
+// Occupancy mocks base method.
+func (m *MockCUResource) Occupancy() protocol.CUOccupancy {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Occupancy")
+	ret0, _ := ret[0].(protocol.CUOccupancy)
+	return ret0
+}
+
+// Occupancy indicates an expected call of Occupancy.
+func (mr *MockCUResourceMockRecorder) Occupancy() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Occupancy", reflect.TypeOf((*MockCUResource)(nil).Occupancy))
+}
+
 // ReserveResourceForWG mocks base method.
 func (m *MockCUResource) ReserveResourceForWG(arg0 *kernels.WorkGroup) ([]resource.WfLocation, bool) {
 	m.ctrl.T.Helper()
@@ -14,8 +14,9 @@ type partition struct {
 // partitionAlgorithm can dispatch workgroups to CUs in a round robin
 // fasion.
 type partitionAlgorithm struct {
-	partitions []*partition
-	cuPool     resource.CUResourcePool
+	partitions    []*partition
+	cuPool        resource.CUResourcePool
+	wavefrontSize int
 
 	nextPartition     int
 	currWGs           []*kernels.WorkGroup
@@ -35,7 +36,7 @@ func (a *partitionAlgorithm) RegisterCU(cu resource.DispatchableCU) {
 func (a *partitionAlgorithm) StartNewKernel(info kernels.KernelLaunchInfo) {
 	a.numDispatchedWG = 0
 
-	gb := kernels.NewGridBuilder()
+	gb := kernels.NewGridBuilderWithWavefrontSize(a.wavefrontSize)
 	gb.SetKernel(info)
 	a.numWG = gb.NumWG()
 	numCU := a.cuPool.NumCU()
@@ -44,7 +45,8 @@ func (a *partitionAlgorithm) StartNewKernel(info kernels.KernelLaunchInfo) {
 	a.partitions = nil
 	for i := 0; i < numCU; i++ {
 		p := &partition{
-			gridBuilder: kernels.NewGridBuilder(),
+			gridBuilder: kernels.NewGridBuilderWithWavefrontSize(
+				a.wavefrontSize),
 		}
 
 		p.gridBuilder.SetKernel(info)
@@ -2,7 +2,9 @@ package resource
 
 import (
 	"github.com/sarchlab/akita/v4/sim"
+	"github.com/sarchlab/mgpusim/v4/amd/insts"
 	"github.com/sarchlab/mgpusim/v4/amd/kernels"
+	"github.com/sarchlab/mgpusim/v4/amd/protocol"
 )
 
 // CUResource handle CU resources
@@ -13,4 +15,8 @@ type CUResource interface {
 	)
 	FreeResourcesForWG(wg *kernels.WorkGroup)
 	DispatchingPort() sim.Port
+	Occupancy() protocol.CUOccupancy
+	MaxResidentWGs(co *insts.HsaCo, numWf int) int
+	Disable()
+	Enable()
 }
@@ -5,6 +5,7 @@ import (
 	. "github.com/onsi/gomega"
 	"github.com/sarchlab/mgpusim/v4/amd/insts"
 	"github.com/sarchlab/mgpusim/v4/amd/kernels"
+	"github.com/sarchlab/mgpusim/v4/amd/protocol"
 )
 
 func assertAllResourcesFree(r *CUResourceImpl) {
@@ -278,4 +279,121 @@ var _ = Describe("cuResource", func() {
 		r.FreeResourcesForWG(wg)
 		assertAllResourcesFree(r)
 	})
+	It("should not reserve resources if the CU is disabled", func() {
+		co.WIVgprCount = 16
+		co.WFSgprCount = 16
+		co.WGGroupSegmentByteSize = 1024
+
+		r.Disable()
+		_, ok := r.ReserveResourceForWG(wg)
+
+		Expect(ok).To(BeFalse())
+		assertAllResourcesFree(r)
+	})
+
+	It("should reserve resources again after the CU is enabled", func() {
+		co.WIVgprCount = 16
+		co.WFSgprCount = 16
+		co.WGGroupSegmentByteSize = 1024
+
+		r.Disable()
+		r.Enable()
+		_, ok := r.ReserveResourceForWG(wg)
+
+		Expect(ok).To(BeTrue())
+	})
+
+	It("should report no occupancy if no work-group is resident", func() {
+		occupancy := r.Occupancy()
+
+		Expect(occupancy.NumWG).To(Equal(0))
+		Expect(occupancy.NumWf).To(Equal(0))
+		Expect(occupancy.Limiter).To(Equal(protocol.OccupancyLimiterNone))
+	})
+
+	It("should report LDS as the occupancy limiter", func() {
+		co.WIVgprCount = 16
+		co.WFSgprCount = 16
+		co.WGGroupSegmentByteSize = 32 * 1024
+
+		_, ok := r.ReserveResourceForWG(wg)
+		occupancy := r.Occupancy()
+
+		Expect(ok).To(BeTrue())
+		Expect(occupancy.NumWG).To(Equal(1))
+		Expect(occupancy.NumWf).To(Equal(10))
+		Expect(occupancy.Limiter).To(Equal(protocol.OccupancyLimiterLDS))
+	})
+
+	It("should report VGPR as the occupancy limiter", func() {
+		co.WIVgprCount = 64
+		co.WFSgprCount = 16
+		co.WGGroupSegmentByteSize = 1024
+
+		_, ok := r.ReserveResourceForWG(wg)
+		occupancy := r.Occupancy()
+
+		Expect(ok).To(BeTrue())
+		Expect(occupancy.Limiter).To(Equal(protocol.OccupancyLimiterVGPR))
+	})
+
+	It("should report SGPR as the occupancy limiter", func() {
+		co.WIVgprCount = 16
+		co.WFSgprCount = 160
+		co.WGGroupSegmentByteSize = 1024
+
+		_, ok := r.ReserveResourceForWG(wg)
+		occupancy := r.Occupancy()
+
+		Expect(ok).To(BeTrue())
+		Expect(occupancy.Limiter).To(Equal(protocol.OccupancyLimiterSGPR))
+	})
+
+	It("should report WG slots as the occupancy limiter", func() {
+		co.WIVgprCount = 4
+		co.WFSgprCount = 16
+		co.WGGroupSegmentByteSize = 256
+
+		r.ReserveResourceForWG(wg)
+		wg2 := kernels.NewWorkGroup()
+		wg2.Wavefronts = wg.Wavefronts
+		wg2.CodeObject = co
+		r.ReserveResourceForWG(wg2)
+		occupancy := r.Occupancy()
+
+		Expect(occupancy.NumWG).To(Equal(2))
+		Expect(occupancy.NumWf).To(Equal(20))
+		Expect(occupancy.Limiter).To(Equal(protocol.OccupancyLimiterWGSlots))
+	})
+
+	It("should reserve the resources of the wavefronts that the work-group "+
+		"asks for", func() {
+		wg.Wavefronts = wg.Wavefronts[:2]
+		wg.NumWfToReserve = 8
+		co.WIVgprCount = 16
+		co.WFSgprCount = 16
+
+		locations, ok := r.ReserveResourceForWG(wg)
+		occupancy := r.Occupancy()
+
+		Expect(ok).To(BeTrue())
+		Expect(locations).To(HaveLen(2))
+		Expect(r.wfPoolFreeCount).To(Equal([]int{8, 8, 8, 8}))
+		Expect(r.sregMask.statusCount(allocStatusFree)).To(Equal(192))
+		Expect(occupancy.NumWf).To(Equal(2))
+
+		r.FreeResourcesForWG(wg)
+		assertAllResourcesFree(r)
+	})
+
+	It("should count the resident work-groups with the register "+
+		"granularity", func() {
+		// 50 VGPRs take 13 units of 4 registers, so 4 wavefronts fit in a
+		// SIMD.
+		co.WIVgprCount = 50
+		co.WFSgprCount = 16
+
+		Expect(r.MaxResidentWGs(co, 4)).To(Equal(4))
+		Expect(r.MaxResidentWGs(co, 3)).To(Equal(5))
+	})
 })
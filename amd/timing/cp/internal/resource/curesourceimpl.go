@@ -1,10 +1,13 @@
 package resource
 
 import (
+	"math"
 	"sync"
 
 	"github.com/sarchlab/akita/v4/sim"
+	"github.com/sarchlab/mgpusim/v4/amd/insts"
 	"github.com/sarchlab/mgpusim/v4/amd/kernels"
+	"github.com/sarchlab/mgpusim/v4/amd/protocol"
 )
 
 // CUResourceImpl implements CUResource
@@ -27,8 +30,11 @@ type CUResourceImpl struct {
 	ldsGranularity int
 	ldsMask        resourceMask
 
-	nextSIMD    int
-	reservedWGs map[*kernels.WorkGroup][]WfLocation
+	disabled bool
+
+	nextSIMD       int
+	reservedWGs    map[*kernels.WorkGroup][]WfLocation
+	lastReservedWG *kernels.WorkGroup
 }
 
 // DispatchingPort returns the port that the dispatcher send message to.
@@ -38,13 +44,22 @@ func (r *CUResourceImpl) DispatchingPort() sim.Port {
 
 // ReserveResourceForWG checks if there is space to hold the work-group. If so,
 // this function reserves the resouces for the work-group and returns how the
-// resources are allocated.
+// resources are allocated. If the work-group asks for more wavefronts than it
+// has, the resources of the extra wavefronts are reserved too, but only the
+// locations of the actual wavefronts are returned.
 func (r *CUResourceImpl) ReserveResourceForWG(wg *kernels.WorkGroup) (
 	locations []WfLocation,
 	ok bool,
 ) {
+	r.Lock()
+	defer r.Unlock()
+
+	if r.disabled {
+		return nil, false
+	}
+
 	ok = true
-	locations = make([]WfLocation, len(wg.Wavefronts))
+	locations = make([]WfLocation, numWfToReserve(wg))
 
 	for i, wf := range wg.Wavefronts {
 		locations[i].Wavefront = wf
@@ -64,7 +79,7 @@ func (r *CUResourceImpl) ReserveResourceForWG(wg *kernels.WorkGroup) (
 
 	if ok {
 		r.reserveResources(wg, locations)
-		return locations, true
+		return locations[:len(wg.Wavefronts)], true
 	}
 
 	r.clearTempReservation(wg)
@@ -78,7 +93,7 @@ func (r *CUResourceImpl) withinSGPRLimitation(
 	co := wg.CodeObject
 	required := r.unitsOccupy(int(co.WFSgprCount), r.sregGranularity)
 
-	for i := range wg.Wavefronts {
+	for i := range locations {
 		location := &locations[i]
 		offset, ok := r.sregMask.nextRegion(required, allocStatusFree)
 		if !ok {
@@ -102,7 +117,7 @@ func (r *CUResourceImpl) withinLDSLimitation(
 		return false
 	}
 
-	for i := range wg.Wavefronts {
+	for i := range locations {
 		location := &locations[i]
 		location.LDSOffset = offset * r.ldsGranularity
 	}
@@ -123,7 +138,7 @@ func (r *CUResourceImpl) matchWfWithSIMDs(
 	wfPoolEntryUsed := make([]int, len(r.wfPoolFreeCount))
 	co := wg.CodeObject
 
-	for i := range wg.Wavefronts {
+	for i := range locations {
 		location := &locations[i]
 		firstSIMDTested := r.nextSIMD
 		firstTry := true
@@ -186,6 +201,7 @@ func (r *CUResourceImpl) reserveResources(
 
 	r.neverReserveTwice(wg)
 	r.reservedWGs[wg] = locations
+	r.lastReservedWG = wg
 }
 
 func (r *CUResourceImpl) neverReserveTwice(wg *kernels.WorkGroup) {
@@ -204,6 +220,9 @@ func (r *CUResourceImpl) clearTempReservation(wg *kernels.WorkGroup) {
 
 // FreeResourcesForWG marks all the resources used by a work-group available.
 func (r *CUResourceImpl) FreeResourcesForWG(wg *kernels.WorkGroup) {
+	r.Lock()
+	defer r.Unlock()
+
 	locations, found := r.reservedWGs[wg]
 	if !found {
 		panic("work-group not found")
@@ -230,3 +249,138 @@ func (r *CUResourceImpl) FreeResourcesForWG(wg *kernels.WorkGroup) {
 
 	delete(r.reservedWGs, wg)
 }
+
+// Disable stops the CU from accepting new work-groups. The work-groups that
+// are already resident on the CU run to completion.
+func (r *CUResourceImpl) Disable() {
+	r.Lock()
+	defer r.Unlock()
+
+	r.disabled = true
+}
+
+// Enable lets the CU accept new work-groups again.
+func (r *CUResourceImpl) Enable() {
+	r.Lock()
+	defer r.Unlock()
+
+	r.disabled = false
+}
+
+// Occupancy returns the number of work-groups and wavefronts that are resident
+// on the CU. It also reports the resource that limits the number of
+// work-groups of the most recently dispatched kernel that can be resident on
+// the CU at the same time.
+func (r *CUResourceImpl) Occupancy() protocol.CUOccupancy {
+	r.Lock()
+	defer r.Unlock()
+
+	occupancy := protocol.CUOccupancy{
+		NumWG: len(r.reservedWGs),
+	}
+
+	for wg := range r.reservedWGs {
+		occupancy.NumWf += len(wg.Wavefronts)
+	}
+
+	if r.lastReservedWG != nil {
+		wg := r.lastReservedWG
+		_, occupancy.Limiter = r.residency(wg.CodeObject, numWfToReserve(wg))
+	}
+
+	return occupancy
+}
+
+// MaxResidentWGs returns how many work-groups of the kernel, each with the
+// given number of wavefronts, can be resident on the CU at the same time.
+func (r *CUResourceImpl) MaxResidentWGs(co *insts.HsaCo, numWf int) int {
+	r.Lock()
+	defer r.Unlock()
+
+	numWG, _ := r.residency(co, numWf)
+
+	return numWG
+}
+
+// residency calculates how many work-groups of the kernel can fit in the CU
+// if each type of resource is considered separately. The resource that allows
+// the fewest work-groups is the limiting resource. Ties are resolved in the
+// order of VGPR, SGPR, LDS, and WG slots.
+func (r *CUResourceImpl) residency(
+	co *insts.HsaCo,
+	numWf int,
+) (int, protocol.OccupancyLimiter) {
+	if numWf == 0 {
+		return 0, protocol.OccupancyLimiterNone
+	}
+
+	limiter := protocol.OccupancyLimiterNone
+	maxWG := math.MaxInt
+	consider := func(l protocol.OccupancyLimiter, numWG int) {
+		if numWG < maxWG {
+			maxWG = numWG
+			limiter = l
+		}
+	}
+
+	if wfCount, limited := r.maxWfByVGPR(int(co.WIVgprCount)); limited {
+		consider(protocol.OccupancyLimiterVGPR, wfCount/numWf)
+	}
+
+	sgprUnits := r.unitsOccupy(int(co.WFSgprCount), r.sregGranularity)
+	if r.sregCount >= 0 && sgprUnits > 0 {
+		wfCount := maskSize(r.sregMask) / sgprUnits
+		consider(protocol.OccupancyLimiterSGPR, wfCount/numWf)
+	}
+
+	ldsUnits := r.unitsOccupy(int(co.WGGroupSegmentByteSize), r.ldsGranularity)
+	if r.ldsByteSize >= 0 && ldsUnits > 0 {
+		consider(protocol.OccupancyLimiterLDS, maskSize(r.ldsMask)/ldsUnits)
+	}
+
+	consider(protocol.OccupancyLimiterWGSlots, r.totalWfSlots()/numWf)
+
+	return maxWG, limiter
+}
+
+func numWfToReserve(wg *kernels.WorkGroup) int {
+	return max(len(wg.Wavefronts), wg.NumWfToReserve)
+}
+
+func (r *CUResourceImpl) maxWfByVGPR(vgprPerWorkItem int) (int, bool) {
+	units := r.unitsOccupy(vgprPerWorkItem, r.vregGranularity)
+	if units == 0 {
+		return 0, false
+	}
+
+	wfCount := 0
+	for i, count := range r.vregCounts {
+		if count < 0 {
+			return 0, false
+		}
+
+		wfCount += maskSize(r.vregMasks[i]) / units
+	}
+
+	return wfCount, true
+}
+
+func (r *CUResourceImpl) totalWfSlots() int {
+	slots := 0
+	for _, count := range r.wfPoolFreeCount {
+		slots += count
+	}
+
+	for _, locations := range r.reservedWGs {
+		slots += len(locations)
+	}
+
+	return slots
+}
+
+func maskSize(m resourceMask) int {
+	return m.statusCount(allocStatusFree) +
+		m.statusCount(allocStatusToReserve) +
+		m.statusCount(allocStatusReserved) +
+		m.statusCount(allocStatusUsed)
+}
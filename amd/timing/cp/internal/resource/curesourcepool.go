@@ -38,12 +38,22 @@ type CUResourcePool interface {
 type CUResourcePoolImpl struct {
 	registeredCUs map[DispatchableCU]bool
 	cus           []CUResource
+	wavefrontSize int
 }
 
 // NewCUResourcePool returns a CUResourcePoll
 func NewCUResourcePool() *CUResourcePoolImpl {
+	return NewCUResourcePoolWithWavefrontSize(64)
+}
+
+// NewCUResourcePoolWithWavefrontSize returns a CUResourcePool that allocates
+// the vector registers for wavefronts of the given number of work-items. A
+// vector register of a smaller wavefront takes less space, so more registers
+// fit in the register file.
+func NewCUResourcePoolWithWavefrontSize(wavefrontSize int) *CUResourcePoolImpl {
 	p := &CUResourcePoolImpl{
 		registeredCUs: make(map[DispatchableCU]bool),
+		wavefrontSize: wavefrontSize,
 	}
 	return p
 }
@@ -108,9 +118,10 @@ func (p *CUResourcePoolImpl) createVRegMasks(
 		}
 
 		p.countMustBeAMultipleOfGranularity(
-			r.vregCounts[i], r.vregGranularity*64)
+			r.vregCounts[i], r.vregGranularity*p.wavefrontSize)
 		r.vregMasks = append(r.vregMasks,
-			newResourceMask(r.vregCounts[i]/r.vregGranularity/64))
+			newResourceMask(
+				r.vregCounts[i]/r.vregGranularity/p.wavefrontSize))
 	}
 }
 
@@ -0,0 +1,36 @@
+package resource
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/sarchlab/akita/v4/sim"
+)
+
+type fakeDispatchableCU struct{}
+
+func (cu *fakeDispatchableCU) DispatchingPort() sim.Port { return nil }
+func (cu *fakeDispatchableCU) WfPoolSizes() []int        { return []int{10, 10, 10, 10} }
+func (cu *fakeDispatchableCU) SRegCount() int            { return 3200 }
+func (cu *fakeDispatchableCU) LDSBytes() int             { return 64 * 1024 }
+
+func (cu *fakeDispatchableCU) VRegCounts() []int {
+	return []int{16384, 16384, 16384, 16384}
+}
+
+var _ = Describe("CUResourcePool", func() {
+	It("should allocate the VGPRs of wave64", func() {
+		pool := NewCUResourcePool()
+		pool.RegisterCU(&fakeDispatchableCU{})
+
+		r := pool.GetCU(0).(*CUResourceImpl)
+		Expect(r.vregMasks[0].statusCount(allocStatusFree)).To(Equal(64))
+	})
+
+	It("should fit twice as many VGPRs of wave32", func() {
+		pool := NewCUResourcePoolWithWavefrontSize(32)
+		pool.RegisterCU(&fakeDispatchableCU{})
+
+		r := pool.GetCU(0).(*CUResourceImpl)
+		Expect(r.vregMasks[0].statusCount(allocStatusFree)).To(Equal(128))
+	})
+})
@@ -127,6 +127,18 @@ func (mr *MockDispatcherMockRecorder) RegisterCU(arg0 interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RegisterCU", reflect.TypeOf((*MockDispatcher)(nil).RegisterCU), arg0)
 }
 
+// ReportFault mocks base method.
+func (m *MockDispatcher) ReportFault(arg0 error) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ReportFault", arg0)
+}
+
+// ReportFault indicates an expected call of ReportFault.
+func (mr *MockDispatcherMockRecorder) ReportFault(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReportFault", reflect.TypeOf((*MockDispatcher)(nil).ReportFault), arg0)
+}
+
 // StartDispatching mocks base method.
 func (m *MockDispatcher) StartDispatching(arg0 *protocol.LaunchKernelReq) {
 	m.ctrl.T.Helper()
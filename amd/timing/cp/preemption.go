@@ -0,0 +1,95 @@
+package cp
+
+import (
+	"github.com/sarchlab/akita/v4/mem/cache"
+	"github.com/sarchlab/akita/v4/tracing"
+	"github.com/sarchlab/mgpusim/v4/amd/protocol"
+)
+
+// Preemption reuses the pipeline flush and restart sequences of the TLB
+// shootdown. When preempted, the CUs keep the wavefronts and the shadow
+// buffers, the caches are flushed and paused, and the dispatchers stop
+// dispatching work-groups. The TLBs keep running. Resuming restarts the caches,
+// the address translators, and the CUs, in this order.
+
+func (p *CommandProcessor) isPreemptedOrPreempting() bool {
+	return p.preempted || p.currPreemptReq != nil
+}
+
+func (p *CommandProcessor) processPreemptKernelReq(
+	req *protocol.PreemptKernelReq,
+) bool {
+	if p.shootDownInProcess ||
+		p.currPreemptReq != nil ||
+		p.currResumeReq != nil {
+		return false
+	}
+
+	p.ToDriver.RetrieveIncoming()
+	tracing.TraceReqReceive(req, p)
+
+	if p.preempted {
+		p.respondPreemptKernelReq(req)
+		return true
+	}
+
+	p.currPreemptReq = req
+	p.flushCUPipelines()
+
+	return true
+}
+
+func (p *CommandProcessor) processCacheFlushCausedByPreemption(
+	_ *cache.FlushRsp,
+) bool {
+	p.preempted = true
+	p.respondPreemptKernelReq(p.currPreemptReq)
+	p.currPreemptReq = nil
+
+	return true
+}
+
+func (p *CommandProcessor) respondPreemptKernelReq(
+	req *protocol.PreemptKernelReq,
+) {
+	rsp := protocol.NewPreemptKernelRsp(p.ToDriver, p.Driver, req.ID)
+	p.ToDriver.Send(rsp)
+
+	tracing.TraceReqComplete(req, p)
+}
+
+func (p *CommandProcessor) processResumeKernelReq(
+	req *protocol.ResumeKernelReq,
+) bool {
+	if p.currPreemptReq != nil || p.currResumeReq != nil {
+		return false
+	}
+
+	p.ToDriver.RetrieveIncoming()
+	tracing.TraceReqReceive(req, p)
+
+	if !p.preempted {
+		p.respondResumeKernelReq(req)
+		return true
+	}
+
+	p.currResumeReq = req
+	p.restartAllCaches()
+
+	return true
+}
+
+func (p *CommandProcessor) completeResume() {
+	p.preempted = false
+	p.respondResumeKernelReq(p.currResumeReq)
+	p.currResumeReq = nil
+}
+
+func (p *CommandProcessor) respondResumeKernelReq(
+	req *protocol.ResumeKernelReq,
+) {
+	rsp := protocol.NewResumeKernelRsp(p.ToDriver, p.Driver, req.ID)
+	p.ToDriver.Send(rsp)
+
+	tracing.TraceReqComplete(req, p)
+}
@@ -0,0 +1,90 @@
+package cp
+
+import (
+	"github.com/sarchlab/akita/v4/tracing"
+	"github.com/sarchlab/mgpusim/v4/amd/protocol"
+	"github.com/sarchlab/mgpusim/v4/amd/timing/tlb"
+)
+
+// TLB invalidation removes the translations of a set of pages from all the
+// TLBs without draining the CUs or flushing the caches. The TLBs pause after
+// flushing, so they are restarted before responding to the driver. It is meant
+// to be used when no kernel is accessing the pages.
+
+func (p *CommandProcessor) processInvalidateTLBReq(
+	req *protocol.InvalidateTLBReq,
+) bool {
+	if p.shootDownInProcess ||
+		p.currInvalidateTLBReq != nil ||
+		p.numTLBAck > 0 {
+		return false
+	}
+
+	p.ToDriver.RetrieveIncoming()
+	tracing.TraceReqReceive(req, p)
+
+	if len(p.TLBs) == 0 {
+		p.respondInvalidateTLBReq(req)
+		return true
+	}
+
+	p.currInvalidateTLBReq = req
+
+	for _, port := range p.TLBs {
+		flushReq := tlb.FlushReqBuilder{}.
+			WithSrc(p.ToTLBs.AsRemote()).
+			WithDst(port.AsRemote()).
+			WithPID(req.PID).
+			WithVAddrs(req.VAddrs).
+			Build()
+		p.ToTLBs.Send(flushReq)
+		p.numTLBAck++
+	}
+
+	return true
+}
+
+func (p *CommandProcessor) processTLBFlushRspCausedByInvalidation(
+	_ *tlb.FlushRsp,
+) bool {
+	p.numTLBAck--
+
+	if p.numTLBAck == 0 {
+		for _, port := range p.TLBs {
+			restartReq := tlb.RestartReqBuilder{}.
+				WithSrc(p.ToTLBs.AsRemote()).
+				WithDst(port.AsRemote()).
+				Build()
+			p.ToTLBs.Send(restartReq)
+			p.numTLBAck++
+		}
+	}
+
+	p.ToTLBs.RetrieveIncoming()
+
+	return true
+}
+
+func (p *CommandProcessor) processTLBRestartRspCausedByInvalidation(
+	_ *tlb.RestartRsp,
+) bool {
+	p.numTLBAck--
+
+	if p.numTLBAck == 0 {
+		p.respondInvalidateTLBReq(p.currInvalidateTLBReq)
+		p.currInvalidateTLBReq = nil
+	}
+
+	p.ToTLBs.RetrieveIncoming()
+
+	return true
+}
+
+func (p *CommandProcessor) respondInvalidateTLBReq(
+	req *protocol.InvalidateTLBReq,
+) {
+	rsp := protocol.NewInvalidateTLBRsp(p.ToDriver, p.Driver, req.ID)
+	p.ToDriver.Send(rsp)
+
+	tracing.TraceReqComplete(req, p)
+}
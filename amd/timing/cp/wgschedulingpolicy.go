@@ -0,0 +1,35 @@
+package cp
+
+// A WGSchedulingPolicy determines which CU the dispatchers assign each
+// work-group to.
+type WGSchedulingPolicy int
+
+// A list of supported work-group scheduling policies.
+const (
+	// WGSchedulingRoundRobin assigns each work-group to the CU after the one
+	// that receives the previous work-group, spreading the work-groups
+	// across all the CUs.
+	WGSchedulingRoundRobin WGSchedulingPolicy = iota
+
+	// WGSchedulingFillFirst assigns each work-group to the CU with the
+	// smallest ID that has enough resources, so that a kernel with few
+	// work-groups leaves the CUs with large IDs idle.
+	WGSchedulingFillFirst
+
+	// WGSchedulingLoadBalanced assigns each work-group to the CU with the
+	// fewest resident wavefronts.
+	WGSchedulingLoadBalanced
+)
+
+func (p WGSchedulingPolicy) alg() string {
+	switch p {
+	case WGSchedulingRoundRobin:
+		return "round-robin"
+	case WGSchedulingFillFirst:
+		return "greedy"
+	case WGSchedulingLoadBalanced:
+		return "load-balanced"
+	default:
+		panic("unknown work-group scheduling policy")
+	}
+}
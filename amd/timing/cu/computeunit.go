@@ -6,6 +6,7 @@ import (
 
 	"github.com/rs/xid"
 	"github.com/sarchlab/akita/v4/mem/mem"
+	"github.com/sarchlab/akita/v4/mem/vm"
 	"github.com/sarchlab/akita/v4/sim"
 	"github.com/sarchlab/akita/v4/tracing"
 	"github.com/sarchlab/mgpusim/v4/amd/emu"
@@ -49,6 +50,20 @@ type ComputeUnit struct {
 	SRegFile         RegisterFile
 	VRegFile         []RegisterFile
 
+	vgprCounts []int
+	ldsBytes   int
+
+	// pageTable is used to detect the vector memory accesses to unmapped
+	// addresses. It is nil if the CU does not detect faults.
+	pageTable vm.PageTable
+
+	// instFetchByteSize is the number of bytes of each instruction fetch. The
+	// fetches are aligned to their size, so they never cross a cache line.
+	instFetchByteSize uint64
+
+	stallCycles        [NumStallReasons]uint64
+	lastStallCountTime sim.VTimeInSec
+
 	InstMem          sim.Port
 	ScalarMem        sim.Port
 	VectorMemModules mem.AddressToPortMapper
@@ -76,6 +91,15 @@ type ComputeUnit struct {
 	wftime map[string]sim.VTimeInSec
 }
 
+// reportFault records the fault that a wavefront encounters, so that it is
+// reported to the dispatcher when the work-group completes. Only the first
+// fault of a work-group is kept.
+func (cu *ComputeUnit) reportFault(wf *wavefront.Wavefront, fault error) {
+	if wf.WG.Fault == nil {
+		wf.WG.Fault = fault
+	}
+}
+
 // ControlPort returns the port that can receive controlling messages from the
 // Command Processor.
 func (cu *ComputeUnit) ControlPort() sim.Port {
@@ -91,13 +115,18 @@ func (cu *ComputeUnit) DispatchingPort() sim.Port {
 // WfPoolSizes returns an array of the numbers of wavefronts that each SIMD unit
 // can execute.
 func (cu *ComputeUnit) WfPoolSizes() []int {
-	return []int{10, 10, 10, 10}
+	sizes := make([]int, len(cu.WfPools))
+	for i, pool := range cu.WfPools {
+		sizes[i] = pool.Capacity
+	}
+
+	return sizes
 }
 
 // VRegCounts returns an array of the numbers of vector regsiters in each SIMD
 // unit.
 func (cu *ComputeUnit) VRegCounts() []int {
-	return []int{16384, 16384, 16384, 16384}
+	return cu.vgprCounts
 }
 
 // SRegCount returns the number of scalar register in the Compute Unit.
@@ -107,7 +136,7 @@ func (cu *ComputeUnit) SRegCount() int {
 
 // LDSBytes returns the number of bytes in the LDS of the CU.
 func (cu *ComputeUnit) LDSBytes() int {
-	return 64 * 1024
+	return cu.ldsBytes
 }
 
 // Tick ticks
@@ -115,6 +144,8 @@ func (cu *ComputeUnit) Tick() bool {
 	cu.Lock()
 	defer cu.Unlock()
 
+	cu.countStallCycles()
+
 	madeProgress := false
 
 	madeProgress = cu.runPipeline() || madeProgress
@@ -675,9 +706,9 @@ func (cu *ComputeUnit) UpdatePCAndSetReady(wf *wavefront.Wavefront) {
 
 func (cu *ComputeUnit) removeStaleInstBuffer(wf *wavefront.Wavefront) {
 	if len(wf.InstBuffer) != 0 {
-		for wf.PC >= wf.InstBufferStartPC+64 {
-			wf.InstBuffer = wf.InstBuffer[64:]
-			wf.InstBufferStartPC += 64
+		for wf.PC >= wf.InstBufferStartPC+cu.instFetchByteSize {
+			wf.InstBuffer = wf.InstBuffer[cu.instFetchByteSize:]
+			wf.InstBufferStartPC += cu.instFetchByteSize
 		}
 	}
 }
@@ -880,6 +911,7 @@ func NewComputeUnit(
 	cu.ToVectorMem = sim.NewPort(cu, 4, 4, name+".ToVectorMem")
 	cu.ToCP = sim.NewPort(cu, 4, 4, name+".ToCP")
 	cu.wftime = make(map[string]sim.VTimeInSec)
+	cu.instFetchByteSize = 64
 
 	return cu
 }
@@ -3,6 +3,7 @@ package cu
 import (
 	"fmt"
 
+	"github.com/sarchlab/akita/v4/mem/vm"
 	"github.com/sarchlab/akita/v4/pipelining"
 	"github.com/sarchlab/akita/v4/sim"
 	"github.com/sarchlab/akita/v4/tracing"
@@ -12,13 +13,23 @@ import (
 
 // A Builder can construct a fully functional Compute Unit.
 type Builder struct {
-	engine            sim.Engine
-	freq              sim.Freq
-	name              string
-	simdCount         int
-	vgprCount         []int
-	sgprCount         int
-	log2CachelineSize uint64
+	engine             sim.Engine
+	freq               sim.Freq
+	name               string
+	simdCount          int
+	vgprCount          []int
+	sgprCount          int
+	ldsByteSize        uint64
+	log2CachelineSize  uint64
+	wfSchedulingPolicy WavefrontSchedulingPolicy
+	numVGPRBanks       int
+	numVGPRBankPorts   int
+	wavefrontSize      int
+	wfSlotsPerSIMD     int
+	sfuLatencyTable    map[insts.Opcode]int
+	barrierLatency     int
+	scalarMSHREntries  int
+	pageTable          vm.PageTable
 
 	decoder            emu.Decoder
 	scratchpadPreparer ScratchpadPreparer
@@ -34,8 +45,12 @@ func MakeBuilder() Builder {
 	b.freq = 1000 * sim.MHz
 	b.simdCount = 4
 	b.sgprCount = 3200
+	b.ldsByteSize = 64 * 1024
 	b.vgprCount = []int{16384, 16384, 16384, 16384}
 	b.log2CachelineSize = 6
+	b.wavefrontSize = 64
+	b.wfSlotsPerSIMD = 10
+	b.sfuLatencyTable = DefaultSFULatencyTable()
 
 	return b
 }
@@ -52,9 +67,17 @@ func (b Builder) WithFreq(f sim.Freq) Builder {
 	return b
 }
 
-// WithSIMDCount sets the number of SIMD unit in the ComputeUnit.
+// WithSIMDCount sets the number of SIMD unit in the ComputeUnit. It also
+// resets the VGPR count of each SIMD unit to the default value, so
+// WithVGPRCount should be called after this function.
 func (b Builder) WithSIMDCount(n int) Builder {
 	b.simdCount = n
+
+	b.vgprCount = make([]int, n)
+	for i := range b.vgprCount {
+		b.vgprCount[i] = 16384
+	}
+
 	return b
 }
 
@@ -68,18 +91,124 @@ func (b Builder) WithVGPRCount(counts []int) Builder {
 	return b
 }
 
+// WithWavefrontSize sets the number of work-items in a wavefront, which can be
+// 32 or 64. A SIMD unit executes an instruction of a smaller wavefront in fewer
+// cycles, and a vector register of a smaller wavefront takes less space in the
+// register file. The dispatcher must form wavefronts of the same size.
+func (b Builder) WithWavefrontSize(n int) Builder {
+	if n != 32 && n != 64 {
+		panic(fmt.Sprintf("wavefront size %d is not supported", n))
+	}
+
+	b.wavefrontSize = n
+	return b
+}
+
+// WithWavefrontSlotsPerSIMD sets the maximum number of wavefronts that can be
+// resident on each SIMD unit at the same time.
+func (b Builder) WithWavefrontSlotsPerSIMD(n int) Builder {
+	if n <= 0 {
+		panic("the number of wavefront slots must be positive")
+	}
+
+	b.wfSlotsPerSIMD = n
+	return b
+}
+
 // WithSGPRCount equals the number of SGPRs in the Compute Unit.
 func (b Builder) WithSGPRCount(count int) Builder {
 	b.sgprCount = count
 	return b
 }
 
-// WithLog2CachelineSize sets the cacheline size as a power of 2.
+// WithLDSByteSize sets the number of bytes in the LDS of the Compute Unit. The
+// work-groups that are resident on the Compute Unit share the LDS.
+func (b Builder) WithLDSByteSize(size uint64) Builder {
+	b.ldsByteSize = size
+	return b
+}
+
+// WithLog2CachelineSize sets the cacheline size as a power of 2. The memory
+// accesses of the Compute Unit never cross a cacheline. The instruction
+// fetches are 64 bytes, or one cacheline if the cacheline is smaller.
 func (b Builder) WithLog2CachelineSize(n uint64) Builder {
 	b.log2CachelineSize = n
 	return b
 }
 
+// WithWavefrontSchedulingPolicy sets the order in which the wavefronts of a
+// SIMD are considered when issuing instructions.
+func (b Builder) WithWavefrontSchedulingPolicy(
+	policy WavefrontSchedulingPolicy,
+) Builder {
+	b.wfSchedulingPolicy = policy
+	return b
+}
+
+// WithBankedRegisterFile models the vector register file with a number of
+// banks, each of which can read a number of registers per cycle. An
+// instruction whose operands read more registers from a bank than the bank has
+// ports stalls the SIMD. By default, the register file has no bank conflicts.
+func (b Builder) WithBankedRegisterFile(numBanks, numPorts int) Builder {
+	if numBanks <= 0 || numPorts <= 0 {
+		panic("the number of banks and ports must be positive")
+	}
+
+	b.numVGPRBanks = numBanks
+	b.numVGPRBankPorts = numPorts
+	return b
+}
+
+// WithSFULatencyTable sets the number of cycles that a wavefront takes to
+// execute each of the VOP1 instructions on the special function unit. The
+// instructions that are not in the table take the same number of cycles as the
+// basic arithmetic. By default, the table is DefaultSFULatencyTable.
+func (b Builder) WithSFULatencyTable(table map[insts.Opcode]int) Builder {
+	for opcode, cycles := range table {
+		if cycles <= 0 {
+			panic(fmt.Sprintf(
+				"the latency of opcode %d must be positive", opcode))
+		}
+	}
+
+	b.sfuLatencyTable = table
+	return b
+}
+
+// WithBarrierLatency sets the number of cycles that the wavefronts of a
+// work-group wait after the last of them arrives at an s_barrier before they
+// can continue. By default, the wavefronts continue right away.
+func (b Builder) WithBarrierLatency(cycles int) Builder {
+	if cycles < 0 {
+		panic("the barrier latency must not be negative")
+	}
+
+	b.barrierLatency = cycles
+	return b
+}
+
+// WithScalarMSHREntries sets the number of scalar memory requests that the CU
+// can keep outstanding. A scalar load that needs more requests than the free
+// entries stalls the scalar unit until the earlier loads return. By default,
+// the number is not limited.
+func (b Builder) WithScalarMSHREntries(n int) Builder {
+	if n < 1 {
+		panic("the number of scalar MSHR entries must be at least 1")
+	}
+
+	b.scalarMSHREntries = n
+	return b
+}
+
+// WithFaultDetection lets the Compute Unit check the addresses of the vector
+// memory accesses against the page table. An access to an unmapped address is
+// not sent to the memory, and the fault is reported to the dispatcher when the
+// work-group completes. By default, the addresses are not checked.
+func (b Builder) WithFaultDetection(pageTable vm.PageTable) Builder {
+	b.pageTable = pageTable
+	return b
+}
+
 // WithVisTracer adds a tracer to the builder.
 func (b Builder) WithVisTracer(t tracing.Tracer) Builder {
 	b.enableVisTracing = true
@@ -96,12 +225,18 @@ func (b *Builder) Build(name string) *ComputeUnit {
 	cu.Decoder = insts.NewDisassembler()
 	cu.WfDispatcher = NewWfDispatcher(cu)
 	cu.InFlightVectorMemAccessLimit = 512
+	cu.ldsBytes = int(b.ldsByteSize)
+	cu.pageTable = b.pageTable
+
+	if b.log2CachelineSize < 6 {
+		cu.instFetchByteSize = 1 << b.log2CachelineSize
+	}
 
 	b.alu = emu.NewALU(nil)
 	b.scratchpadPreparer = NewScratchpadPreparerImpl(cu)
 
-	for i := 0; i < 4; i++ {
-		cu.WfPools = append(cu.WfPools, NewWavefrontPool(10))
+	for i := 0; i < b.simdCount; i++ {
+		cu.WfPools = append(cu.WfPools, NewWavefrontPool(b.wfSlotsPerSIMD))
 	}
 
 	b.equipScheduler(cu)
@@ -118,7 +253,9 @@ func (b *Builder) equipScheduler(cu *ComputeUnit) {
 	fetchArbitor := new(FetchArbiter)
 	fetchArbitor.InstBufByteSize = 256
 	issueArbitor := new(IssueArbiter)
+	issueArbitor.policy = b.wfSchedulingPolicy
 	scheduler := NewScheduler(cu, fetchArbitor, issueArbitor)
+	scheduler.barrierLatency = b.barrierLatency
 	cu.Scheduler = scheduler
 }
 
@@ -129,6 +266,7 @@ func (b *Builder) equipScalarUnits(cu *ComputeUnit) {
 	cu.ScalarDecoder = scalarDecoder
 	scalarUnit := NewScalarUnit(cu, b.scratchpadPreparer, b.alu)
 	scalarUnit.log2CachelineSize = b.log2CachelineSize
+	scalarUnit.maxNumInflightReqs = b.scalarMSHREntries
 	cu.ScalarUnit = scalarUnit
 	for i := 0; i < b.simdCount; i++ {
 		scalarDecoder.AddExecutionUnit(scalarUnit)
@@ -141,6 +279,10 @@ func (b *Builder) equipSIMDUnits(cu *ComputeUnit) {
 	for i := 0; i < b.simdCount; i++ {
 		name := fmt.Sprintf(b.name+".SIMD%d", i)
 		simdUnit := NewSIMDUnit(cu, name, b.scratchpadPreparer, b.alu)
+		simdUnit.NumVGPRBanks = b.numVGPRBanks
+		simdUnit.NumVGPRBankPorts = b.numVGPRBankPorts
+		simdUnit.WavefrontSize = b.wavefrontSize
+		simdUnit.SFULatency = b.sfuLatencyTable
 		if b.enableVisTracing {
 			tracing.CollectTrace(simdUnit, b.visTracer)
 		}
@@ -195,7 +337,13 @@ func (b *Builder) equipRegisterFiles(cu *ComputeUnit) {
 	cu.SRegFile = sRegFile
 
 	for i := 0; i < b.simdCount; i++ {
-		vRegFile := NewSimpleRegisterFile(uint64(b.vgprCount[i]*4), 1024)
+		// The lanes beyond the wavefront size are never active, but they
+		// still get storage, as the register accesses iterate over 64 lanes.
+		byteSizePerLane := b.vgprCount[i] * 4 / b.wavefrontSize
+		vRegFile := NewSimpleRegisterFile(
+			uint64(byteSizePerLane*64), byteSizePerLane)
 		cu.VRegFile = append(cu.VRegFile, vRegFile)
 	}
+
+	cu.vgprCounts = b.vgprCount
 }
@@ -0,0 +1,140 @@
+package cu
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/sarchlab/akita/v4/sim"
+	"github.com/sarchlab/mgpusim/v4/amd/insts"
+)
+
+var _ = Describe("Builder", func() {
+	var builder Builder
+
+	BeforeEach(func() {
+		builder = MakeBuilder().WithEngine(sim.NewSerialEngine())
+	})
+
+	It("should build a CU with 4 SIMD units by default", func() {
+		cu := builder.Build("CU")
+
+		Expect(cu.SIMDUnit).To(HaveLen(4))
+		Expect(cu.WfPoolSizes()).To(Equal([]int{10, 10, 10, 10}))
+		Expect(cu.VRegCounts()).To(Equal([]int{16384, 16384, 16384, 16384}))
+		Expect(cu.LDSBytes()).To(Equal(64 * 1024))
+	})
+
+	It("should build a CU with the given number of SIMD units", func() {
+		builder = builder.WithSIMDCount(2)
+		cu := builder.Build("CU")
+
+		Expect(cu.SIMDUnit).To(HaveLen(2))
+		Expect(cu.VRegFile).To(HaveLen(2))
+		Expect(cu.WfPoolSizes()).To(Equal([]int{10, 10}))
+		Expect(cu.VRegCounts()).To(Equal([]int{16384, 16384}))
+	})
+
+	It("should build a CU with the given LDS size", func() {
+		builder = builder.WithLDSByteSize(32 * 1024)
+		cu := builder.Build("CU")
+
+		Expect(cu.LDSBytes()).To(Equal(32 * 1024))
+	})
+
+	It("should build SIMD units with the given wavefront slots", func() {
+		builder = builder.WithWavefrontSlotsPerSIMD(4)
+		cu := builder.Build("CU")
+
+		Expect(cu.WfPoolSizes()).To(Equal([]int{4, 4, 4, 4}))
+	})
+
+	It("should panic if the number of wavefront slots is not positive",
+		func() {
+			Expect(func() { builder.WithWavefrontSlotsPerSIMD(0) }).To(Panic())
+		})
+
+	It("should issue with the given wavefront scheduling policy", func() {
+		builder = builder.WithWavefrontSchedulingPolicy(
+			WavefrontSchedulingRoundRobin)
+		cu := builder.Build("CU")
+
+		scheduler := cu.Scheduler.(*SchedulerImpl)
+		Expect(scheduler.issueArbiter.(*IssueArbiter).policy).
+			To(Equal(WavefrontSchedulingRoundRobin))
+	})
+
+	It("should build SIMD units with a banked register file", func() {
+		builder = builder.WithBankedRegisterFile(4, 2)
+		cu := builder.Build("CU")
+
+		for _, simdUnit := range cu.SIMDUnit {
+			Expect(simdUnit.(*SIMDUnit).NumVGPRBanks).To(Equal(4))
+			Expect(simdUnit.(*SIMDUnit).NumVGPRBankPorts).To(Equal(2))
+		}
+	})
+
+	It("should panic if the register file has no banks", func() {
+		Expect(func() { builder.WithBankedRegisterFile(0, 1) }).To(Panic())
+	})
+
+	It("should build SIMD units with the default SFU latency", func() {
+		cu := builder.Build("CU")
+
+		for _, simdUnit := range cu.SIMDUnit {
+			Expect(simdUnit.(*SIMDUnit).SFULatency).
+				To(Equal(DefaultSFULatencyTable()))
+		}
+	})
+
+	It("should build SIMD units with the given SFU latency", func() {
+		table := map[insts.Opcode]int{51: 32}
+		builder = builder.WithSFULatencyTable(table)
+		cu := builder.Build("CU")
+
+		for _, simdUnit := range cu.SIMDUnit {
+			Expect(simdUnit.(*SIMDUnit).SFULatency).To(Equal(table))
+		}
+	})
+
+	It("should panic if an SFU latency is not positive", func() {
+		Expect(func() {
+			builder.WithSFULatencyTable(map[insts.Opcode]int{51: 0})
+		}).To(Panic())
+	})
+
+	It("should build a scheduler with the given barrier latency", func() {
+		builder = builder.WithBarrierLatency(500)
+		cu := builder.Build("CU")
+
+		Expect(cu.Scheduler.(*SchedulerImpl).barrierLatency).To(Equal(500))
+	})
+
+	It("should panic if the barrier latency is negative", func() {
+		Expect(func() { builder.WithBarrierLatency(-1) }).To(Panic())
+	})
+
+	It("should build a scalar unit with the given MSHR entries", func() {
+		builder = builder.WithScalarMSHREntries(4)
+		cu := builder.Build("CU")
+
+		Expect(cu.ScalarUnit.(*ScalarUnit).maxNumInflightReqs).To(Equal(4))
+	})
+
+	It("should panic if the CU has no scalar MSHR entry", func() {
+		Expect(func() { builder.WithScalarMSHREntries(0) }).To(Panic())
+	})
+
+	It("should fetch 64 bytes of instructions by default", func() {
+		builder = builder.WithLog2CachelineSize(7)
+		cu := builder.Build("CU")
+
+		Expect(cu.instFetchByteSize).To(Equal(uint64(64)))
+	})
+
+	It("should fetch one cacheline of instructions if it is smaller",
+		func() {
+			builder = builder.WithLog2CachelineSize(5)
+			cu := builder.Build("CU")
+
+			Expect(cu.instFetchByteSize).To(Equal(uint64(32)))
+		})
+})
@@ -20,6 +20,10 @@ type ISADebugger struct {
 	cu            *ComputeUnit
 	executingInst map[string]tracing.Task
 	// prevWf *Wavefront
+
+	onlyOneWf bool
+	wgID      int
+	wfID      int
 }
 
 // NewISADebugger returns a new ISADebugger that keeps instruction log in logger
@@ -36,22 +40,50 @@ func NewISADebugger(logger *log.Logger, cu *ComputeUnit) *ISADebugger {
 	return h
 }
 
+// OnlyLogWavefront restricts the log to the instructions of a single
+// wavefront. The work-group is identified by its ID flattened in the x-major
+// order, and the wavefront by its index in the work-group.
+func (h *ISADebugger) OnlyLogWavefront(wgID, wfID int) {
+	h.onlyOneWf = true
+	h.wgID = wgID
+	h.wfID = wfID
+}
+
 // StartTask marks the start of an instruction.
 func (h *ISADebugger) StartTask(task tracing.Task) {
 	if task.Kind != "inst" {
 		return
 	}
 
-	// For debugging
 	detail := task.Detail.(map[string]interface{})
 	wf := detail["wf"].(*wavefront.Wavefront)
-	if wf.FirstWiFlatID != 0 {
+	if h.onlyOneWf && !h.isTargetWf(wf) {
 		return
 	}
 
 	h.executingInst[task.ID] = task
 }
 
+func (h *ISADebugger) isTargetWf(wf *wavefront.Wavefront) bool {
+	packet := wf.Packet
+	numWGX := int(packet.GridSizeX-1)/int(packet.WorkgroupSizeX) + 1
+	numWGY := int(packet.GridSizeY-1)/int(packet.WorkgroupSizeY) + 1
+	wg := wf.WG
+	wgID := wg.IDX + wg.IDY*numWGX + wg.IDZ*numWGX*numWGY
+
+	if wgID != h.wgID {
+		return false
+	}
+
+	for i, w := range wg.Wfs {
+		if w == wf {
+			return i == h.wfID
+		}
+	}
+
+	return false
+}
+
 // StepTask does nothing as of now.
 func (h *ISADebugger) StepTask(task tracing.Task) {
 	// Do nothing.
@@ -74,9 +106,7 @@ func (h *ISADebugger) EndTask(task tracing.Task) {
 	wf := detail["wf"].(*wavefront.Wavefront)
 	inst := detail["inst"].(*wavefront.Inst).Inst
 
-	if wf.WG.IDX == 75 && wf.WG.IDY == 1 {
-		h.logWholeWf(inst, wf)
-	}
+	h.logWholeWf(inst, wf)
 
 	delete(h.executingInst, task.ID)
 }
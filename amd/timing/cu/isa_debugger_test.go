@@ -0,0 +1,77 @@
+package cu
+
+import (
+	"bytes"
+	"log"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/sarchlab/akita/v4/tracing"
+	"github.com/sarchlab/mgpusim/v4/amd/kernels"
+	"github.com/sarchlab/mgpusim/v4/amd/timing/wavefront"
+)
+
+var _ = Describe("ISA Debugger", func() {
+	var (
+		debugger *ISADebugger
+		wg       *wavefront.WorkGroup
+	)
+
+	BeforeEach(func() {
+		debugger = NewISADebugger(log.New(new(bytes.Buffer), "", 0), nil)
+
+		// The grid has 4x2 work-groups, and the work-group has an ID of 5.
+		packet := &kernels.HsaKernelDispatchPacket{
+			GridSizeX:      256,
+			GridSizeY:      2,
+			GridSizeZ:      1,
+			WorkgroupSizeX: 64,
+			WorkgroupSizeY: 1,
+			WorkgroupSizeZ: 1,
+		}
+		rawWG := kernels.NewWorkGroup()
+		rawWG.IDX, rawWG.IDY = 1, 1
+		wg = wavefront.NewWorkGroup(rawWG, nil)
+
+		for i := 0; i < 2; i++ {
+			raw := kernels.NewWavefront()
+			raw.Packet = packet
+			wf := wavefront.NewWavefront(raw)
+			wf.WG = wg
+			wg.Wfs = append(wg.Wfs, wf)
+		}
+	})
+
+	startInst := func(id string, wf *wavefront.Wavefront) {
+		debugger.StartTask(tracing.Task{
+			ID:     id,
+			Kind:   "inst",
+			Detail: map[string]interface{}{"wf": wf},
+		})
+	}
+
+	It("should log the instructions of all the wavefronts by default", func() {
+		startInst("inst0", wg.Wfs[0])
+		startInst("inst1", wg.Wfs[1])
+
+		Expect(debugger.executingInst).To(HaveLen(2))
+	})
+
+	It("should only log the instructions of the given wavefront", func() {
+		debugger.OnlyLogWavefront(5, 1)
+
+		startInst("inst0", wg.Wfs[0])
+		startInst("inst1", wg.Wfs[1])
+
+		Expect(debugger.executingInst).To(HaveLen(1))
+		Expect(debugger.executingInst).To(HaveKey("inst1"))
+	})
+
+	It("should not log the instructions of other work-groups", func() {
+		debugger.OnlyLogWavefront(4, 1)
+
+		startInst("inst1", wg.Wfs[1])
+
+		Expect(debugger.executingInst).To(BeEmpty())
+	})
+})
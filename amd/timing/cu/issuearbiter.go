@@ -2,9 +2,31 @@ package cu
 
 import "github.com/sarchlab/mgpusim/v4/amd/timing/wavefront"
 
+// A WavefrontSchedulingPolicy determines the order in which the IssueArbiter
+// considers the wavefronts of a SIMD.
+type WavefrontSchedulingPolicy int
+
+// A list of supported wavefront scheduling policies.
+const (
+	// WavefrontSchedulingOldestFirst always prioritizes the wavefronts that
+	// are dispatched earlier.
+	WavefrontSchedulingOldestFirst WavefrontSchedulingPolicy = iota
+
+	// WavefrontSchedulingRoundRobin gives the turn to one wavefront at a time.
+	// If the wavefront is not ready, the SIMD does not issue in the cycle.
+	WavefrontSchedulingRoundRobin
+
+	// WavefrontSchedulingLooseRoundRobin starts from the wavefront after the
+	// last one that issued and skips the wavefronts that are not ready.
+	WavefrontSchedulingLooseRoundRobin
+)
+
 // An IssueArbiter decides which wavefront can issue instruction
 type IssueArbiter struct {
 	lastSIMDID int
+
+	policy      WavefrontSchedulingPolicy
+	nextWfIndex []int
 }
 
 // NewIssueArbiter returns a newly created IssueArbiter
@@ -15,7 +37,7 @@ func NewIssueArbiter() *IssueArbiter {
 }
 
 // Arbitrate will take a round-robin fashion at SIMD level. For wavefronts
-// in each SIMD, oldest first.
+// in each SIMD, the order is determined by the wavefront scheduling policy.
 func (a *IssueArbiter) Arbitrate(
 	wfPools []*WavefrontPool,
 ) []*wavefront.Wavefront {
@@ -23,22 +45,15 @@ func (a *IssueArbiter) Arbitrate(
 		return []*wavefront.Wavefront{}
 	}
 
+	if len(a.nextWfIndex) != len(wfPools) {
+		a.nextWfIndex = make([]int, len(wfPools))
+	}
+
 	wfToIssue := make([]*wavefront.Wavefront, 0)
 	for i := 0; i < len(wfPools); i++ {
 		simdID := (a.lastSIMDID + i) % len(wfPools)
 
-		typeMask := make([]bool, 7)
-		wfPool := wfPools[simdID]
-		for _, wf := range wfPool.wfs {
-			if wf.State != wavefront.WfReady || wf.InstToIssue == nil {
-				continue
-			}
-
-			if typeMask[wf.InstToIssue.ExeUnit] == false {
-				wfToIssue = append(wfToIssue, wf)
-				typeMask[wf.InstToIssue.ExeUnit] = true
-			}
-		}
+		wfToIssue = a.arbitrateSIMD(simdID, wfPools[simdID], wfToIssue)
 
 		if len(wfToIssue) != 0 {
 			a.lastSIMDID = simdID
@@ -49,6 +64,47 @@ func (a *IssueArbiter) Arbitrate(
 	return wfToIssue
 }
 
+func (a *IssueArbiter) arbitrateSIMD(
+	simdID int,
+	wfPool *WavefrontPool,
+	wfToIssue []*wavefront.Wavefront,
+) []*wavefront.Wavefront {
+	numWfs := len(wfPool.wfs)
+	if numWfs == 0 {
+		return wfToIssue
+	}
+
+	start := 0
+	numCandidates := numWfs
+	switch a.policy {
+	case WavefrontSchedulingRoundRobin:
+		start = a.nextWfIndex[simdID] % numWfs
+		numCandidates = 1
+		a.nextWfIndex[simdID] = start + 1
+	case WavefrontSchedulingLooseRoundRobin:
+		start = a.nextWfIndex[simdID] % numWfs
+	}
+
+	typeMask := make([]bool, 7)
+	for i := 0; i < numCandidates; i++ {
+		wf := wfPool.wfs[(start+i)%numWfs]
+		if wf.State != wavefront.WfReady || wf.InstToIssue == nil {
+			continue
+		}
+
+		if typeMask[wf.InstToIssue.ExeUnit] == false {
+			wfToIssue = append(wfToIssue, wf)
+			typeMask[wf.InstToIssue.ExeUnit] = true
+
+			if a.policy == WavefrontSchedulingLooseRoundRobin {
+				a.nextWfIndex[simdID] = start + i + 1
+			}
+		}
+	}
+
+	return wfToIssue
+}
+
 func (a *IssueArbiter) moveToNextSIMD(wfPools []*WavefrontPool) {
 	a.lastSIMDID++
 	if a.lastSIMDID >= len(wfPools) {
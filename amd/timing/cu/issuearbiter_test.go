@@ -61,4 +61,44 @@ var _ = Describe("IssueArbiter", func() {
 		Expect(issueCandidate).To(ContainElement(BeIdenticalTo(wfs[8])))
 		Expect(issueCandidate).NotTo(ContainElement(BeIdenticalTo(wfs[9])))
 	})
+
+	Context("with round-robin policies", func() {
+		var wfs []*wavefront.Wavefront
+
+		BeforeEach(func() {
+			wfs = nil
+			for i := 0; i < 3; i++ {
+				wf := new(wavefront.Wavefront)
+				wf.State = wavefront.WfReady
+				wf.InstToIssue = wavefront.NewInst(insts.NewInst())
+				wf.InstToIssue.ExeUnit = insts.ExeUnitVALU
+				wfs = append(wfs, wf)
+				wfPools[0].AddWf(wf)
+			}
+		})
+
+		It("should always issue the oldest ready wf", func() {
+			Expect(arbiter.Arbitrate(wfPools)).To(Equal(wfs[0:1]))
+			Expect(arbiter.Arbitrate(wfPools)).To(Equal(wfs[0:1]))
+		})
+
+		It("should give the turn to one wf at a time", func() {
+			arbiter.policy = WavefrontSchedulingRoundRobin
+			wfs[1].State = wavefront.WfRunning
+
+			Expect(arbiter.Arbitrate(wfPools)).To(Equal(wfs[0:1]))
+			Expect(arbiter.Arbitrate(wfPools)).To(BeEmpty())
+			Expect(arbiter.Arbitrate(wfPools)).To(Equal(wfs[2:3]))
+			Expect(arbiter.Arbitrate(wfPools)).To(Equal(wfs[0:1]))
+		})
+
+		It("should skip the wfs that are not ready", func() {
+			arbiter.policy = WavefrontSchedulingLooseRoundRobin
+			wfs[1].State = wavefront.WfRunning
+
+			Expect(arbiter.Arbitrate(wfPools)).To(Equal(wfs[0:1]))
+			Expect(arbiter.Arbitrate(wfPools)).To(Equal(wfs[2:3]))
+			Expect(arbiter.Arbitrate(wfPools)).To(Equal(wfs[0:1]))
+		})
+	})
 })
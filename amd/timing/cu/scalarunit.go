@@ -24,6 +24,11 @@ type ScalarUnit struct {
 	readBufSize int
 	readBuf     []*mem.ReadReq
 
+	// maxNumInflightReqs is the number of scalar memory requests that the CU
+	// can keep outstanding. A scalar load waits in the exec stage until its
+	// requests fit. There is no limit if it is 0.
+	maxNumInflightReqs int
+
 	log2CachelineSize uint64
 
 	isIdle bool
@@ -92,8 +97,7 @@ func (u *ScalarUnit) runExecStage() bool {
 	}
 	if u.toWrite == nil {
 		if u.toExec.Inst().FormatType == insts.SMEM {
-			u.executeSMEMInst()
-			return true
+			return u.executeSMEMInst()
 		}
 
 		u.alu.Run(u.toExec)
@@ -135,6 +139,11 @@ func (u *ScalarUnit) executeSMEMLoad(byteSize int) bool {
 		return false
 	}
 
+	if u.maxNumInflightReqs > 0 &&
+		len(u.cu.InFlightScalarMemAccess)+numCacheline > u.maxNumInflightReqs {
+		return false
+	}
+
 	curr := start
 	bytesLeft := uint64(byteSize)
 	regIndex := inst.Data.Register.RegIndex()
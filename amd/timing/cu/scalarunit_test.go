@@ -244,6 +244,32 @@ var _ = Describe("Scalar Unit", func() {
 
 		Expect(bu.readBuf).To(HaveLen(1))
 	})
+	It("should stall s_load when the scalar MSHR entries are used up", func() {
+		bu.maxNumInflightReqs = 1
+		cu.InFlightScalarMemAccess = append(cu.InFlightScalarMemAccess,
+			&ScalarMemAccessInfo{})
+
+		wave := wavefront.NewWavefront(nil)
+		bu.toExec = wave
+
+		inst := wavefront.NewInst(insts.NewInst())
+		inst.FormatType = insts.SMEM
+		inst.Opcode = 1
+		inst.Data = insts.NewSRegOperand(0, 0, 1)
+		wave.SetDynamicInst(inst)
+
+		sp := wave.Scratchpad().AsSMEM()
+		sp.Base = 0x1000
+		sp.Offset = 0x24
+
+		madeProgress := bu.Run()
+
+		Expect(madeProgress).To(BeFalse())
+		Expect(bu.toExec).To(BeIdenticalTo(wave))
+		Expect(wave.OutstandingScalarMemAccess).To(Equal(0))
+		Expect(bu.readBuf).To(BeEmpty())
+	})
+
 	It("should flush the scalar unit", func() {
 		wave := wavefront.NewWavefront(nil)
 		inst := wavefront.NewInst(insts.NewInst())
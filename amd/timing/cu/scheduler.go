@@ -30,6 +30,8 @@ type SchedulerImpl struct {
 
 	barrierBuffer     []*wavefront.Wavefront
 	barrierBufferSize int
+	barrierLatency    int
+	barrierReleases   []*barrierRelease
 
 	cyclesNoProgress                  int
 	stopTickingAfterNCyclesNoProgress int
@@ -37,6 +39,13 @@ type SchedulerImpl struct {
 	isPaused bool
 }
 
+// A barrierRelease is a work-group whose wavefronts have all arrived at a
+// barrier, but that still waits for the barrier latency to pass.
+type barrierRelease struct {
+	wg         *wavefront.WorkGroup
+	cyclesLeft int
+}
+
 // NewScheduler returns a newly created scheduler, injecting dependency
 // of the compute unit, the fetch arbiter, and the issue arbiter.
 func NewScheduler(
@@ -61,6 +70,7 @@ func NewScheduler(
 func (s *SchedulerImpl) Run() bool {
 	madeProgress := false
 	if s.isPaused == false {
+		madeProgress = s.releaseBarriers() || madeProgress
 		madeProgress = s.EvaluateInternalInst() || madeProgress
 		madeProgress = s.DecodeNextInst() || madeProgress
 		madeProgress = s.DoIssue() || madeProgress
@@ -84,7 +94,7 @@ func (s *SchedulerImpl) DecodeNextInst() bool {
 	for _, wfPool := range s.cu.WfPools {
 		for _, wf := range wfPool.wfs {
 			if len(wf.InstBuffer) == 0 {
-				wf.InstBufferStartPC = wf.PC & 0xffffffffffffffc0
+				wf.InstBufferStartPC = s.alignToInstFetch(wf.PC)
 				continue
 			}
 
@@ -116,6 +126,10 @@ func (s *SchedulerImpl) wfHasAtLeast4BytesInInstBuffer(wf *wavefront.Wavefront)
 	return len(wf.InstBuffer[wf.PC-wf.InstBufferStartPC:]) >= 4
 }
 
+func (s *SchedulerImpl) alignToInstFetch(addr uint64) uint64 {
+	return addr &^ (s.cu.instFetchByteSize - 1)
+}
+
 // DoFetch function of the scheduler will fetch instructions from the
 // instruction memory
 func (s *SchedulerImpl) DoFetch() bool {
@@ -126,16 +140,16 @@ func (s *SchedulerImpl) DoFetch() bool {
 		wf := wfs[0]
 
 		if len(wf.InstBuffer) == 0 {
-			wf.InstBufferStartPC = wf.PC & 0xffffffffffffffc0
+			wf.InstBufferStartPC = s.alignToInstFetch(wf.PC)
 		}
 		addr := wf.InstBufferStartPC + uint64(len(wf.InstBuffer))
-		addr = addr & 0xffffffffffffffc0
+		addr = s.alignToInstFetch(addr)
 		req := mem.ReadReqBuilder{}.
 			WithSrc(s.cu.ToInstMem.AsRemote()).
 			WithDst(s.cu.InstMem.AsRemote()).
 			WithAddress(addr).
 			WithPID(wf.PID()).
-			WithByteSize(64).
+			WithByteSize(s.cu.instFetchByteSize).
 			Build()
 
 		err := s.cu.ToInstMem.Send(req)
@@ -304,7 +318,11 @@ func (s *SchedulerImpl) evalSEndPgm(
 	}
 
 	if s.areAllOtherWfsInWGAtBarrier(wf.WG, wf) {
-		s.passBarrier(wf.WG)
+		if s.barrierLatency > 0 {
+			s.delayBarrierRelease(wf.WG)
+		} else {
+			s.passBarrier(wf.WG)
+		}
 		s.resetRegisterValue(wf)
 
 		wf.State = wavefront.WfCompleted
@@ -367,6 +385,7 @@ func (s *SchedulerImpl) sendWGCompletionMessage(
 		WithSrc(s.cu.ToACE.AsRemote()).
 		WithDst(dispatcher).
 		WithRspTo([]string{mapReq.ID}).
+		WithFault(wg.Fault).
 		Build()
 
 	err := s.cu.ToACE.Send(msg)
@@ -418,8 +437,17 @@ func (s *SchedulerImpl) evalSBarrier(
 	wf.State = wavefront.WfAtBarrier
 
 	wg := wf.WG
+	if s.isBarrierReleaseDelayed(wg) {
+		return false, false, false
+	}
+
 	allAtBarrier := s.areAllWfInWGAtBarrier(wg)
 
+	if allAtBarrier && s.barrierLatency > 0 {
+		s.delayBarrierRelease(wg)
+		return true, true, false
+	}
+
 	if allAtBarrier {
 		s.passBarrier(wg)
 		return true, true, true
@@ -442,6 +470,51 @@ func (s *SchedulerImpl) areAllWfInWGAtBarrier(wg *wavefront.WorkGroup) bool {
 	return true
 }
 
+// delayBarrierRelease lets the wavefronts of the work-group wait at the barrier
+// until the barrier latency passes.
+func (s *SchedulerImpl) delayBarrierRelease(wg *wavefront.WorkGroup) {
+	s.barrierReleases = append(s.barrierReleases, &barrierRelease{
+		wg:         wg,
+		cyclesLeft: s.barrierLatency,
+	})
+}
+
+func (s *SchedulerImpl) isBarrierReleaseDelayed(
+	wg *wavefront.WorkGroup,
+) bool {
+	for _, r := range s.barrierReleases {
+		if r.wg == wg {
+			return true
+		}
+	}
+
+	return false
+}
+
+// releaseBarriers counts down the barrier latency of the work-groups that are
+// waiting at a barrier and lets the wavefronts of the work-groups whose
+// latency has passed continue.
+func (s *SchedulerImpl) releaseBarriers() bool {
+	if len(s.barrierReleases) == 0 {
+		return false
+	}
+
+	newBarrierReleases := make([]*barrierRelease, 0, len(s.barrierReleases))
+	for _, r := range s.barrierReleases {
+		r.cyclesLeft--
+		if r.cyclesLeft > 0 {
+			newBarrierReleases = append(newBarrierReleases, r)
+			continue
+		}
+
+		s.removeAllWfFromInternalExecuting(r.wg, &s.internalExecuting)
+		s.passBarrier(r.wg)
+	}
+	s.barrierReleases = newBarrierReleases
+
+	return true
+}
+
 func (s *SchedulerImpl) passBarrier(
 	wg *wavefront.WorkGroup,
 ) {
@@ -521,5 +594,6 @@ func (s *SchedulerImpl) Resume() {
 // Flush flushes
 func (s *SchedulerImpl) Flush() {
 	s.barrierBuffer = nil
+	s.barrierReleases = nil
 	s.internalExecuting = nil
 }
@@ -124,7 +124,7 @@ var _ = Describe("Scheduler", func() {
 		scheduler = NewScheduler(cu, fetchArbitor, issueArbitor)
 	})
 
-	It("should always fetch 64 bytes", func() {
+	It("should fetch 64 bytes", func() {
 		wf := new(wavefront.Wavefront)
 		wf.Wavefront = new(kernels.Wavefront)
 		wf.InstBufferStartPC = 0x100
@@ -147,6 +147,28 @@ var _ = Describe("Scheduler", func() {
 		Expect(wf.IsFetching).To(BeTrue())
 	})
 
+	It("should fetch one cacheline if the cacheline is smaller", func() {
+		cu.instFetchByteSize = 32
+
+		wf := new(wavefront.Wavefront)
+		wf.Wavefront = new(kernels.Wavefront)
+		wf.InstBufferStartPC = 0x100
+		wf.InstBuffer = make([]byte, 0x60)
+
+		fetchArbitor.wfsToReturn = append(fetchArbitor.wfsToReturn,
+			[]*wavefront.Wavefront{wf})
+
+		toInstMem.EXPECT().Send(gomock.Any()).Do(func(r sim.Msg) {
+			req := r.(*mem.ReadReq)
+			Expect(req.Address).To(Equal(uint64(0x160)))
+			Expect(req.AccessByteSize).To(Equal(uint64(32)))
+		})
+
+		scheduler.DoFetch()
+
+		Expect(cu.InFlightInstFetch).To(HaveLen(1))
+	})
+
 	It("should wait if fetch failed", func() {
 		wf := new(wavefront.Wavefront)
 		wf.InstBufferStartPC = 0x100
@@ -517,6 +539,46 @@ var _ = Describe("Scheduler", func() {
 
 	})
 
+	It("should wait for the barrier latency before continuing execution", func() {
+		scheduler.barrierLatency = 2
+
+		wg := new(wavefront.WorkGroup)
+		for i := 0; i < 4; i++ {
+			wf := wavefront.NewWavefront(kernels.NewWavefront())
+			wf.SetDynamicInst(wavefront.NewInst(insts.NewInst()))
+			wf.DynamicInst().Format = insts.FormatTable[insts.SOPP]
+			wf.DynamicInst().Opcode = 10
+			wf.State = wavefront.WfAtBarrier
+			wf.WG = wg
+			wg.Wfs = append(wg.Wfs, wf)
+		}
+
+		wf := wg.Wfs[0]
+		wf.State = wavefront.WfRunning
+		scheduler.barrierBuffer = append(scheduler.barrierBuffer, wg.Wfs[1:]...)
+
+		scheduler.internalExecuting = []*wavefront.Wavefront{wf}
+		scheduler.EvaluateInternalInst()
+
+		Expect(scheduler.internalExecuting).NotTo(ContainElement(wf))
+		Expect(scheduler.barrierReleases).To(HaveLen(1))
+		for _, wf := range wg.Wfs {
+			Expect(wf.State).To(Equal(wavefront.WfAtBarrier))
+		}
+
+		Expect(scheduler.releaseBarriers()).To(BeTrue())
+		for _, wf := range wg.Wfs {
+			Expect(wf.State).To(Equal(wavefront.WfAtBarrier))
+		}
+
+		Expect(scheduler.releaseBarriers()).To(BeTrue())
+		Expect(scheduler.barrierReleases).To(BeEmpty())
+		Expect(scheduler.barrierBuffer).To(BeEmpty())
+		for _, wf := range wg.Wfs {
+			Expect(wf.State).To(Equal(wavefront.WfReady))
+		}
+	})
+
 	It("should flush", func() {
 		wg := new(wavefront.WorkGroup)
 		for i := 0; i < 4; i++ {
@@ -0,0 +1,33 @@
+package cu
+
+import "github.com/sarchlab/mgpusim/v4/amd/insts"
+
+// DefaultSFULatencyTable returns the number of cycles that the special
+// function unit takes to execute a wavefront of each of the transcendental
+// VOP1 instructions. Single- and half-precision operations run at a quarter of
+// the rate of the basic arithmetic and double-precision operations at an
+// eighth.
+func DefaultSFULatencyTable() map[insts.Opcode]int {
+	return map[insts.Opcode]int{
+		32: 16, // v_exp_f32
+		33: 16, // v_log_f32
+		34: 16, // v_rcp_f32
+		35: 16, // v_rcp_iflag_f32
+		36: 16, // v_rsq_f32
+		37: 32, // v_rcp_f64
+		38: 32, // v_rsq_f64
+		39: 16, // v_sqrt_f32
+		40: 32, // v_sqrt_f64
+		41: 16, // v_sin_f32
+		42: 16, // v_cos_f32
+		61: 16, // v_rcp_f16
+		62: 16, // v_sqrt_f16
+		63: 16, // v_rsq_f16
+		64: 16, // v_log_f16
+		65: 16, // v_exp_f16
+		73: 16, // v_sin_f16
+		74: 16, // v_cos_f16
+		75: 16, // v_exp_legacy_f32
+		76: 16, // v_log_legacy_f32
+	}
+}
@@ -1,9 +1,12 @@
 package cu
 
 import (
+	"math/bits"
+
 	"github.com/sarchlab/akita/v4/sim"
 	"github.com/sarchlab/akita/v4/tracing"
 	"github.com/sarchlab/mgpusim/v4/amd/emu"
+	"github.com/sarchlab/mgpusim/v4/amd/insts"
 	"github.com/sarchlab/mgpusim/v4/amd/timing/wavefront"
 )
 
@@ -23,6 +26,27 @@ type SIMDUnit struct {
 
 	NumSinglePrecisionUnit int
 
+	// WavefrontSize is the number of work-items in a wavefront. An
+	// instruction takes WavefrontSize/NumSinglePrecisionUnit cycles.
+	WavefrontSize int
+
+	// NumVGPRBanks is the number of banks of the vector register file. The
+	// register file is idealized and has no bank conflicts if it is 0.
+	NumVGPRBanks int
+
+	// NumVGPRBankPorts is the number of registers that a bank can read in
+	// each cycle.
+	NumVGPRBankPorts int
+
+	// SFULatency is the number of cycles that a wavefront takes to execute a
+	// VOP1 instruction on the special function unit, indexed by the opcode.
+	// Instructions that are not in the table take the same number of cycles
+	// as the basic arithmetic.
+	SFULatency map[insts.Opcode]int
+
+	numInstExecuted uint64
+	numActiveLanes  uint64
+
 	isIdle bool
 }
 
@@ -41,6 +65,7 @@ func NewSIMDUnit(
 	u.alu = alu
 
 	u.NumSinglePrecisionUnit = 16
+	u.WavefrontSize = 64
 
 	return u
 }
@@ -60,10 +85,59 @@ func (u *SIMDUnit) IsIdle() bool {
 func (u *SIMDUnit) AcceptWave(wave *wavefront.Wavefront) {
 	u.toExec = wave
 
-	u.cycleLeft = 64 / u.NumSinglePrecisionUnit
+	u.cycleLeft = u.issueCycles(wave.DynamicInst()) +
+		u.bankConflictStallCycles(wave.DynamicInst())
 	u.logPipelineTask(u.toExec.DynamicInst(), false)
 }
 
+// issueCycles returns the number of cycles that the SIMD takes to execute an
+// instruction, regardless of the register reads.
+func (u *SIMDUnit) issueCycles(inst *wavefront.Inst) int {
+	if inst.FormatType == insts.VOP1 {
+		if cycles, ok := u.SFULatency[inst.Opcode]; ok {
+			return cycles
+		}
+	}
+
+	return u.WavefrontSize / u.NumSinglePrecisionUnit
+}
+
+// bankConflictStallCycles returns the number of extra cycles to read the
+// VGPR operands of an instruction. The registers are interleaved across the
+// banks, and a bank with more reads than ports needs more than one cycle.
+func (u *SIMDUnit) bankConflictStallCycles(inst *wavefront.Inst) int {
+	if u.NumVGPRBanks == 0 {
+		return 0
+	}
+
+	regs := make(map[int]bool)
+	for _, o := range []*insts.Operand{inst.Src0, inst.Src1, inst.Src2} {
+		if o == nil || o.OperandType != insts.RegOperand ||
+			!o.Register.IsVReg() {
+			continue
+		}
+
+		for i := 0; i < max(o.RegCount, 1); i++ {
+			regs[o.Register.RegIndex()+i] = true
+		}
+	}
+
+	readsPerBank := make([]int, u.NumVGPRBanks)
+	maxReads := 0
+	for reg := range regs {
+		bank := reg % u.NumVGPRBanks
+		readsPerBank[bank]++
+		maxReads = max(maxReads, readsPerBank[bank])
+	}
+
+	readCycles := (maxReads + u.NumVGPRBankPorts - 1) / u.NumVGPRBankPorts
+	if readCycles <= 1 {
+		return 0
+	}
+
+	return readCycles - 1
+}
+
 // Run executes three pipeline stages that are controlled by the SIMDUnit
 func (u *SIMDUnit) Run() bool {
 	madeProgress := u.runExecStage()
@@ -80,6 +154,9 @@ func (u *SIMDUnit) runExecStage() bool {
 		return true
 	}
 
+	u.numInstExecuted++
+	u.numActiveLanes += uint64(bits.OnesCount64(u.toExec.EXEC))
+
 	u.scratchpadPreparer.Prepare(u.toExec, u.toExec)
 	u.alu.Run(u.toExec)
 	u.scratchpadPreparer.Commit(u.toExec, u.toExec)
@@ -92,6 +169,18 @@ func (u *SIMDUnit) runExecStage() bool {
 	return true
 }
 
+// LaneUtilization returns the average fraction of the lanes that are active
+// in the instructions that the SIMD unit has executed. It returns 0 if the
+// SIMD unit has not executed any instruction.
+func (u *SIMDUnit) LaneUtilization() float64 {
+	if u.numInstExecuted == 0 {
+		return 0
+	}
+
+	return float64(u.numActiveLanes) /
+		float64(u.numInstExecuted*uint64(u.WavefrontSize))
+}
+
 // Flush flushes
 func (u *SIMDUnit) Flush() {
 	u.toExec = nil
@@ -46,6 +46,77 @@ var _ = Describe("SIMD Unit", func() {
 		Expect(bu.cycleLeft).To(Equal(4))
 	})
 
+	It("should execute the instructions of wave32 in fewer cycles", func() {
+		bu.WavefrontSize = 32
+
+		wave := new(wavefront.Wavefront)
+		inst := wavefront.NewInst(insts.NewInst())
+		wave.SetDynamicInst(inst)
+		bu.AcceptWave(wave)
+
+		Expect(bu.cycleLeft).To(Equal(2))
+	})
+
+	It("should use the SFU latency of transcendental instructions", func() {
+		bu.SFULatency = DefaultSFULatencyTable()
+
+		wave := new(wavefront.Wavefront)
+		inst := wavefront.NewInst(insts.NewInst())
+		inst.FormatType = insts.VOP1
+		inst.Opcode = 36 // v_rsq_f32
+		wave.SetDynamicInst(inst)
+
+		bu.AcceptWave(wave)
+
+		Expect(bu.cycleLeft).To(Equal(16))
+	})
+
+	It("should not use the SFU latency of other formats", func() {
+		bu.SFULatency = DefaultSFULatencyTable()
+
+		wave := new(wavefront.Wavefront)
+		inst := wavefront.NewInst(insts.NewInst())
+		inst.FormatType = insts.VOP2
+		inst.Opcode = 36
+		wave.SetDynamicInst(inst)
+
+		bu.AcceptWave(wave)
+
+		Expect(bu.cycleLeft).To(Equal(4))
+	})
+
+	It("should stall if the operands read the same VGPR bank", func() {
+		bu.NumVGPRBanks = 4
+		bu.NumVGPRBankPorts = 1
+
+		wave := new(wavefront.Wavefront)
+		inst := wavefront.NewInst(insts.NewInst())
+		inst.Src0 = insts.NewVRegOperand(0, 0, 1)
+		inst.Src1 = insts.NewVRegOperand(4, 4, 1)
+		inst.Src2 = insts.NewVRegOperand(8, 8, 1)
+		wave.SetDynamicInst(inst)
+
+		bu.AcceptWave(wave)
+
+		Expect(bu.cycleLeft).To(Equal(6))
+	})
+
+	It("should not stall if a bank has a port for each read", func() {
+		bu.NumVGPRBanks = 4
+		bu.NumVGPRBankPorts = 2
+
+		wave := new(wavefront.Wavefront)
+		inst := wavefront.NewInst(insts.NewInst())
+		inst.Src0 = insts.NewVRegOperand(0, 0, 1)
+		inst.Src1 = insts.NewVRegOperand(1, 1, 1)
+		inst.Src2 = insts.NewVRegOperand(4, 4, 1)
+		wave.SetDynamicInst(inst)
+
+		bu.AcceptWave(wave)
+
+		Expect(bu.cycleLeft).To(Equal(4))
+	})
+
 	It("should run", func() {
 		wave := new(wavefront.Wavefront)
 		inst := wavefront.NewInst(insts.NewInst())
@@ -80,6 +151,31 @@ var _ = Describe("SIMD Unit", func() {
 
 	})
 
+	It("should report the fraction of the active lanes", func() {
+		run := func(exec uint64) {
+			wave := new(wavefront.Wavefront)
+			inst := wavefront.NewInst(insts.NewInst())
+			inst.FormatType = insts.VOP2
+			inst.ByteSize = 4
+			wave.InstBuffer = make([]byte, 256)
+			wave.InstBufferStartPC = 0x100
+			wave.SetDynamicInst(inst)
+			wave.PC = 0x100
+			wave.EXEC = exec
+
+			bu.toExec = wave
+			bu.cycleLeft = 1
+			bu.Run()
+		}
+
+		Expect(bu.LaneUtilization()).To(Equal(0.0))
+
+		run(0xffffffffffffffff)
+		run(0x1)
+
+		Expect(bu.LaneUtilization()).To(BeNumerically("~", 65.0/128.0))
+	})
+
 	It("should flush SIMD", func() {
 		wave := new(wavefront.Wavefront)
 		inst := wavefront.NewInst(insts.NewInst())
@@ -0,0 +1,118 @@
+package cu
+
+import (
+	"github.com/sarchlab/mgpusim/v4/amd/insts"
+	"github.com/sarchlab/mgpusim/v4/amd/timing/wavefront"
+)
+
+// A StallReason is what keeps a wavefront from issuing its next instruction.
+type StallReason int
+
+// A list of all the reasons that a wavefront can stall on.
+const (
+	// StallVMem marks the wavefronts that wait for their vector memory
+	// accesses to complete, at an S_WAITCNT or at the end of the program.
+	StallVMem StallReason = iota
+
+	// StallSMem marks the wavefronts that wait for their scalar memory
+	// accesses to complete, at an S_WAITCNT or at the end of the program.
+	StallSMem
+
+	// StallBarrier marks the wavefronts that wait for the other wavefronts of
+	// the work-group to arrive at a barrier.
+	StallBarrier
+
+	// StallDependency marks the wavefronts that wait for their previous
+	// instruction to complete before the next one can issue.
+	StallDependency
+
+	// StallIssuePort marks the wavefronts that have an instruction to issue,
+	// but lose the issue arbitration to another wavefront or find the
+	// execution unit busy.
+	StallIssuePort
+
+	// StallInstFetch marks the wavefronts that wait for their next
+	// instruction to be fetched and decoded.
+	StallInstFetch
+
+	// NumStallReasons is the number of stall reasons.
+	NumStallReasons
+)
+
+// StallCycles returns the number of cycles that the wavefronts of the CU have
+// stalled on the given reason since the start of the simulation. The cycles
+// of the wavefronts that stall at the same time are added up.
+func (cu *ComputeUnit) StallCycles(reason StallReason) uint64 {
+	cu.Lock()
+	defer cu.Unlock()
+
+	return cu.stallCycles[reason]
+}
+
+// countStallCycles charges the cycles since the last count to the reasons that
+// the wavefronts stall on. The states of the wavefronts only change when the
+// CU ticks, so the states at the beginning of a tick have held since the end
+// of the previous tick, even if the CU has stopped ticking in between.
+func (cu *ComputeUnit) countStallCycles() {
+	now := cu.CurrentTime()
+	cycles := cu.Freq.Cycle(now) - cu.Freq.Cycle(cu.lastStallCountTime)
+	cu.lastStallCountTime = now
+
+	if cycles == 0 {
+		return
+	}
+
+	for _, wfPool := range cu.WfPools {
+		for _, wf := range wfPool.wfs {
+			reason, stalled := stallReasonOf(wf)
+			if stalled {
+				cu.stallCycles[reason] += cycles
+			}
+		}
+	}
+}
+
+func stallReasonOf(wf *wavefront.Wavefront) (reason StallReason, stalled bool) {
+	switch wf.State {
+	case wavefront.WfAtBarrier:
+		return StallBarrier, true
+	case wavefront.WfRunning:
+		return runningStallReasonOf(wf), true
+	case wavefront.WfReady:
+		if wf.InstToIssue != nil {
+			return StallIssuePort, true
+		}
+
+		return StallInstFetch, true
+	}
+
+	return 0, false
+}
+
+func runningStallReasonOf(wf *wavefront.Wavefront) StallReason {
+	inst := wf.Inst()
+	if inst == nil || inst.ExeUnit != insts.ExeUnitSpecial {
+		return StallDependency
+	}
+
+	switch inst.Opcode {
+	case 1: // S_ENDPGM
+		if wf.OutstandingVectorMemAccess > 0 {
+			return StallVMem
+		}
+
+		if wf.OutstandingScalarMemAccess > 0 {
+			return StallSMem
+		}
+	case 12: // S_WAITCNT
+		if wf.OutstandingVectorMemAccess > inst.VMCNT {
+			return StallVMem
+		}
+
+		if wf.OutstandingScalarMemAccess > inst.LKGMCNT {
+			return StallSMem
+		}
+	}
+
+	return StallDependency
+}
@@ -0,0 +1,137 @@
+package cu
+
+import (
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/sarchlab/akita/v4/sim"
+	"github.com/sarchlab/mgpusim/v4/amd/insts"
+	"github.com/sarchlab/mgpusim/v4/amd/kernels"
+	"github.com/sarchlab/mgpusim/v4/amd/timing/wavefront"
+)
+
+var _ = Describe("Stall Breakdown", func() {
+	var (
+		mockCtrl *gomock.Controller
+		engine   *MockEngine
+		cu       *ComputeUnit
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		engine = NewMockEngine(mockCtrl)
+
+		cu = NewComputeUnit("CU", engine)
+		cu.Freq = 1 * sim.GHz
+		cu.WfPools = append(cu.WfPools, NewWavefrontPool(10))
+	})
+
+	AfterEach(func() {
+		mockCtrl.Finish()
+	})
+
+	newWf := func(state wavefront.WfState) *wavefront.Wavefront {
+		wf := wavefront.NewWavefront(kernels.NewWavefront())
+		wf.State = state
+		return wf
+	}
+
+	withInst := func(
+		wf *wavefront.Wavefront,
+		opcode insts.Opcode,
+	) *wavefront.Wavefront {
+		inst := insts.NewInst()
+		inst.ExeUnit = insts.ExeUnitSpecial
+		inst.Opcode = opcode
+		wf.SetDynamicInst(wavefront.NewInst(inst))
+		return wf
+	}
+
+	It("should charge the wavefronts at a barrier to barrier", func() {
+		reason, stalled := stallReasonOf(newWf(wavefront.WfAtBarrier))
+
+		Expect(stalled).To(BeTrue())
+		Expect(reason).To(Equal(StallBarrier))
+	})
+
+	It("should charge the ready wavefronts with an instruction to the "+
+		"issue port", func() {
+		wf := newWf(wavefront.WfReady)
+		wf.InstToIssue = wavefront.NewInst(insts.NewInst())
+
+		reason, _ := stallReasonOf(wf)
+
+		Expect(reason).To(Equal(StallIssuePort))
+	})
+
+	It("should charge the ready wavefronts without an instruction to the "+
+		"instruction fetch", func() {
+		reason, _ := stallReasonOf(newWf(wavefront.WfReady))
+
+		Expect(reason).To(Equal(StallInstFetch))
+	})
+
+	It("should charge the running wavefronts to the dependency", func() {
+		reason, _ := stallReasonOf(newWf(wavefront.WfRunning))
+
+		Expect(reason).To(Equal(StallDependency))
+	})
+
+	It("should charge the waiting vector memory accesses to VMEM", func() {
+		wf := withInst(newWf(wavefront.WfRunning), 12)
+		wf.OutstandingVectorMemAccess = 1
+
+		reason, _ := stallReasonOf(wf)
+
+		Expect(reason).To(Equal(StallVMem))
+	})
+
+	It("should charge the waiting scalar memory accesses to SMEM", func() {
+		wf := withInst(newWf(wavefront.WfRunning), 1)
+		wf.OutstandingScalarMemAccess = 1
+
+		reason, _ := stallReasonOf(wf)
+
+		Expect(reason).To(Equal(StallSMem))
+	})
+
+	It("should not charge the accesses within the S_WAITCNT count", func() {
+		wf := withInst(newWf(wavefront.WfRunning), 12)
+		wf.Inst().VMCNT = 1
+		wf.OutstandingVectorMemAccess = 1
+
+		reason, _ := stallReasonOf(wf)
+
+		Expect(reason).To(Equal(StallDependency))
+	})
+
+	It("should not charge the wavefronts that are not dispatched", func() {
+		_, stalled := stallReasonOf(newWf(wavefront.WfDispatching))
+
+		Expect(stalled).To(BeFalse())
+	})
+
+	It("should add up the cycles of all the stalled wavefronts", func() {
+		cu.WfPools[0].AddWf(newWf(wavefront.WfAtBarrier))
+		cu.WfPools[0].AddWf(newWf(wavefront.WfAtBarrier))
+		cu.WfPools[0].AddWf(newWf(wavefront.WfRunning))
+
+		engine.EXPECT().CurrentTime().Return(sim.VTimeInSec(10e-9))
+		cu.countStallCycles()
+
+		Expect(cu.StallCycles(StallBarrier)).To(Equal(uint64(20)))
+		Expect(cu.StallCycles(StallDependency)).To(Equal(uint64(10)))
+		Expect(cu.StallCycles(StallVMem)).To(Equal(uint64(0)))
+	})
+
+	It("should only charge the cycles since the last count", func() {
+		cu.WfPools[0].AddWf(newWf(wavefront.WfAtBarrier))
+
+		engine.EXPECT().CurrentTime().Return(sim.VTimeInSec(10e-9))
+		cu.countStallCycles()
+		engine.EXPECT().CurrentTime().Return(sim.VTimeInSec(14e-9))
+		cu.countStallCycles()
+
+		Expect(cu.StallCycles(StallBarrier)).To(Equal(uint64(14)))
+	})
+})
@@ -1,12 +1,16 @@
 package cu
 
 import (
+	"fmt"
 	"log"
 
+	"github.com/sarchlab/akita/v4/mem/mem"
 	"github.com/sarchlab/akita/v4/pipelining"
 	"github.com/sarchlab/akita/v4/sim"
 	"github.com/sarchlab/akita/v4/tracing"
+	"github.com/sarchlab/mgpusim/v4/amd/atomic"
 	"github.com/sarchlab/mgpusim/v4/amd/insts"
+	"github.com/sarchlab/mgpusim/v4/amd/protocol"
 	"github.com/sarchlab/mgpusim/v4/amd/timing/wavefront"
 )
 
@@ -30,6 +34,9 @@ type VectorMemoryUnit struct {
 	numTransactionInFlight  uint64
 	maxInstructionsInFlight uint64
 
+	numVMemInst        uint64
+	numVMemTransaction uint64
+
 	instructionPipeline           pipelining.Pipeline
 	postInstructionPipelineBuffer sim.Buffer
 	transactionsWaiting           []VectorMemAccessInfo
@@ -73,6 +80,14 @@ func (u *VectorMemoryUnit) IsIdle() bool {
 	return u.isIdle
 }
 
+// CoalescingStats returns the number of vector memory instructions that the
+// unit has issued and the number of cache-line transactions that the
+// instructions have generated. The instructions whose lanes are all masked off
+// do not access the memory and are not counted.
+func (u *VectorMemoryUnit) CoalescingStats() (numInst, numTransaction uint64) {
+	return u.numVMemInst, u.numVMemTransaction
+}
+
 // Run executes three pipeline stages that are controlled by the
 // VectorMemoryUnit
 func (u *VectorMemoryUnit) Run() bool {
@@ -138,6 +153,10 @@ func (u *VectorMemoryUnit) executeFlatInsts(
 	case 24, 25, 26, 27, 28, 29, 30, 31:
 		return u.executeFlatStore(wavefront)
 	default:
+		if _, _, ok := atomic.FlatOp(inst.Opcode); ok {
+			return u.executeFlatAtomic(wavefront)
+		}
+
 		log.Panicf("Opcode %d for format FLAT is not supported.", inst.Opcode)
 	}
 
@@ -149,6 +168,7 @@ func (u *VectorMemoryUnit) executeFlatLoad(
 ) bool {
 	u.scratchpadPreparer.Prepare(wave, wave)
 	transactions := u.coalescer.generateMemTransactions(wave)
+	transactions = u.dropUnmappedTransactions(wave, transactions)
 
 	if len(transactions) == 0 {
 		u.cu.logInstTask(
@@ -166,6 +186,8 @@ func (u *VectorMemoryUnit) executeFlatLoad(
 
 	wave.OutstandingVectorMemAccess++
 	wave.OutstandingScalarMemAccess++
+	u.numVMemInst++
+	u.numVMemTransaction += uint64(len(transactions))
 
 	for i, t := range transactions {
 		u.cu.InFlightVectorMemAccess = append(u.cu.InFlightVectorMemAccess, t)
@@ -188,6 +210,7 @@ func (u *VectorMemoryUnit) executeFlatStore(
 ) bool {
 	u.scratchpadPreparer.Prepare(wave, wave)
 	transactions := u.coalescer.generateMemTransactions(wave)
+	transactions = u.dropUnmappedTransactions(wave, transactions)
 
 	if len(transactions) == 0 {
 		u.cu.logInstTask(
@@ -205,12 +228,20 @@ func (u *VectorMemoryUnit) executeFlatStore(
 
 	wave.OutstandingVectorMemAccess++
 	wave.OutstandingScalarMemAccess++
+	u.numVMemInst++
+	u.numVMemTransaction += uint64(len(transactions))
+
+	// The SLC bit marks a streaming store, which the caches do not allocate.
+	streaming := wave.DynamicInst().SystemLevelCoherent
 
 	for i, t := range transactions {
 		u.cu.InFlightVectorMemAccess = append(u.cu.InFlightVectorMemAccess, t)
 		if i != len(transactions)-1 {
 			t.Write.CanWaitForCoalesce = true
 		}
+		if streaming {
+			t.Write.Info = &protocol.StreamingStoreInfo{}
+		}
 		lowModule := u.cu.VectorMemModules.Find(t.Write.Address)
 		t.Write.Dst = lowModule
 		t.Write.Src = u.cu.ToVectorMem.AsRemote()
@@ -221,6 +252,135 @@ func (u *VectorMemoryUnit) executeFlatStore(
 	return true
 }
 
+// executeFlatAtomic sends one request for each active lane, as the atomic
+// operations of different lanes cannot be combined.
+func (u *VectorMemoryUnit) executeFlatAtomic(
+	wave *wavefront.Wavefront,
+) bool {
+	u.scratchpadPreparer.Prepare(wave, wave)
+	transactions := u.generateAtomicTransactions(wave)
+	transactions = u.dropUnmappedTransactions(wave, transactions)
+
+	if len(transactions) == 0 {
+		u.cu.logInstTask(
+			wave,
+			wave.DynamicInst(),
+			true,
+		)
+		return true
+	}
+
+	if len(transactions)+len(u.cu.InFlightVectorMemAccess) >
+		u.cu.InFlightVectorMemAccessLimit {
+		return false
+	}
+
+	wave.OutstandingVectorMemAccess++
+	wave.OutstandingScalarMemAccess++
+	u.numVMemInst++
+	u.numVMemTransaction += uint64(len(transactions))
+
+	for i, t := range transactions {
+		u.cu.InFlightVectorMemAccess = append(u.cu.InFlightVectorMemAccess, t)
+		if i != len(transactions)-1 {
+			t.Read.CanWaitForCoalesce = true
+		}
+
+		lowModule := u.cu.VectorMemModules.Find(t.Read.Address)
+		t.Read.Dst = lowModule
+		t.Read.Src = u.cu.ToVectorMem.AsRemote()
+		t.Read.PID = wave.PID()
+		u.transactionsWaiting = append(u.transactionsWaiting, t)
+	}
+
+	return true
+}
+
+// dropUnmappedTransactions removes the transactions that access unmapped
+// addresses and reports the first of them as a fault of the work-group. A
+// transaction never crosses a cache line, so checking its start address is
+// enough.
+func (u *VectorMemoryUnit) dropUnmappedTransactions(
+	wave *wavefront.Wavefront,
+	transactions []VectorMemAccessInfo,
+) []VectorMemAccessInfo {
+	if u.cu.pageTable == nil {
+		return transactions
+	}
+
+	mapped := transactions[:0]
+	for _, t := range transactions {
+		var addr uint64
+		if t.Read != nil {
+			addr = t.Read.Address
+		} else {
+			addr = t.Write.Address
+		}
+
+		_, found := u.cu.pageTable.Find(wave.PID(), addr)
+		if !found {
+			u.cu.reportFault(wave, fmt.Errorf(
+				"illegal VMEM address 0x%x on %s", addr, u.cu.Name()))
+			continue
+		}
+
+		mapped = append(mapped, t)
+	}
+
+	return mapped
+}
+
+func (u *VectorMemoryUnit) generateAtomicTransactions(
+	wave *wavefront.Wavefront,
+) []VectorMemAccessInfo {
+	inst := wave.Inst()
+	op, byteSize, _ := atomic.FlatOp(inst.Opcode)
+	numReg := int(byteSize / 4)
+	sp := wave.Scratchpad().AsFlat()
+
+	var transactions []VectorMemAccessInfo
+
+	for i := uint(0); i < 64; i++ {
+		if !laneMasked(sp.EXEC, i) {
+			continue
+		}
+
+		info := &atomic.Info{
+			Op:      op,
+			Data:    make([]byte, byteSize),
+			CmpData: make([]byte, byteSize),
+		}
+
+		for j := 0; j < numReg; j++ {
+			copy(info.Data[j*4:], insts.Uint32ToBytes(sp.DATA[int(i)*4+j]))
+			copy(info.CmpData[j*4:],
+				insts.Uint32ToBytes(sp.DATA[int(i)*4+numReg+j]))
+		}
+
+		t := VectorMemAccessInfo{
+			Read: mem.ReadReqBuilder{}.
+				WithAddress(sp.ADDR[i]).
+				WithByteSize(byteSize).
+				WithInfo(info).
+				Build(),
+			Wavefront: wave,
+			Inst:      wave.DynamicInst(),
+		}
+
+		if inst.GlobalLevelCoherent {
+			t.laneInfo = []vectorMemAccessLaneInfo{{
+				laneID:   int(i),
+				reg:      insts.VReg(inst.Dst.Register.RegIndex()),
+				regCount: numReg,
+			}}
+		}
+
+		transactions = append(transactions, t)
+	}
+
+	return transactions
+}
+
 func (u *VectorMemoryUnit) sendRequest() bool {
 	item := u.postTransactionPipelineBuffer.Peek()
 	if item == nil {
@@ -5,8 +5,11 @@ import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"github.com/sarchlab/akita/v4/mem/mem"
+	"github.com/sarchlab/akita/v4/mem/vm"
+	"github.com/sarchlab/mgpusim/v4/amd/atomic"
 	"github.com/sarchlab/mgpusim/v4/amd/insts"
 	"github.com/sarchlab/mgpusim/v4/amd/kernels"
+	"github.com/sarchlab/mgpusim/v4/amd/protocol"
 	"github.com/sarchlab/mgpusim/v4/amd/timing/wavefront"
 )
 
@@ -106,6 +109,89 @@ var _ = Describe("Vector Memory Unit", func() {
 		Expect(cu.InFlightVectorMemAccess[3].Read.CanWaitForCoalesce).
 			To(BeFalse())
 		Expect(vecMemUnit.transactionsWaiting).To(HaveLen(4))
+
+		numInst, numTransaction := vecMemUnit.CoalescingStats()
+		Expect(numInst).To(Equal(uint64(1)))
+		Expect(numTransaction).To(Equal(uint64(4)))
+	})
+
+	It("should drop the accesses to unmapped addresses and report the "+
+		"fault", func() {
+		pageTable := vm.NewPageTable(12)
+		pageTable.Insert(vm.Page{
+			PID:      1,
+			VAddr:    0x1000,
+			PageSize: 4096,
+			Valid:    true,
+		})
+		cu.pageTable = pageTable
+
+		kernelWave := kernels.NewWavefront()
+		wave := wavefront.NewWavefront(kernelWave)
+		wave.SetPID(1)
+		wave.WG = wavefront.NewWorkGroup(kernels.NewWorkGroup(), nil)
+		inst := wavefront.NewInst(insts.NewInst())
+		inst.Format = insts.FormatTable[insts.FLAT]
+		inst.Opcode = 20
+		inst.Dst = insts.NewVRegOperand(0, 0, 1)
+		wave.SetDynamicInst(inst)
+
+		transactions := make([]VectorMemAccessInfo, 2)
+		for i, addr := range []uint64{0x1000, 0xdead0000} {
+			transactions[i].Read = mem.ReadReqBuilder{}.
+				WithAddress(addr).
+				WithByteSize(4).
+				Build()
+		}
+		coalescer.EXPECT().generateMemTransactions(wave).Return(transactions)
+		instBuffer.EXPECT().Peek().Return(vectorMemInst{wavefront: wave})
+		instBuffer.EXPECT().Pop().Return(vectorMemInst{wavefront: wave})
+
+		madeProgress := vecMemUnit.instToTransaction()
+
+		Expect(madeProgress).To(BeTrue())
+		Expect(vecMemUnit.transactionsWaiting).To(HaveLen(1))
+		Expect(vecMemUnit.transactionsWaiting[0].Read.Address).
+			To(Equal(uint64(0x1000)))
+		Expect(wave.WG.Fault).To(MatchError(
+			"illegal VMEM address 0xdead0000 on CU"))
+	})
+
+	It("should run flat_atomic_add", func() {
+		kernelWave := kernels.NewWavefront()
+		wave := wavefront.NewWavefront(kernelWave)
+		inst := wavefront.NewInst(insts.NewInst())
+		inst.Format = insts.FormatTable[insts.FLAT]
+		inst.Opcode = 66
+		inst.GlobalLevelCoherent = true
+		inst.Dst = insts.NewVRegOperand(2, 2, 1)
+		wave.SetDynamicInst(inst)
+
+		layout := wave.Scratchpad().AsFlat()
+		layout.EXEC = 0x5
+		layout.ADDR[0] = 0x100
+		layout.ADDR[2] = 0x100
+		layout.DATA[0] = 1
+		layout.DATA[8] = 2
+		instBuffer.EXPECT().Peek().Return(vectorMemInst{wavefront: wave})
+		instBuffer.EXPECT().Pop().Return(vectorMemInst{wavefront: wave})
+
+		madeProgress := vecMemUnit.instToTransaction()
+
+		Expect(madeProgress).To(BeTrue())
+		Expect(wave.OutstandingVectorMemAccess).To(Equal(1))
+		Expect(cu.InFlightVectorMemAccess).To(HaveLen(2))
+
+		read := cu.InFlightVectorMemAccess[1].Read
+		Expect(read.Address).To(Equal(uint64(0x100)))
+		Expect(read.AccessByteSize).To(Equal(uint64(4)))
+		Expect(read.CanWaitForCoalesce).To(BeFalse())
+		Expect(atomic.InfoOf(read).Op).To(Equal(atomic.OpAdd))
+		Expect(atomic.InfoOf(read).Data).To(Equal([]byte{2, 0, 0, 0}))
+		Expect(cu.InFlightVectorMemAccess[1].laneInfo).To(HaveLen(1))
+		Expect(cu.InFlightVectorMemAccess[1].laneInfo[0].laneID).To(Equal(2))
+		Expect(cu.InFlightVectorMemAccess[1].laneInfo[0].reg).
+			To(Equal(insts.VReg(2)))
 	})
 
 	It("should run flat_store_dword", func() {
@@ -140,6 +226,34 @@ var _ = Describe("Vector Memory Unit", func() {
 		Expect(vecMemUnit.transactionsWaiting).To(HaveLen(4))
 	})
 
+	It("should mark the writes of a streaming store", func() {
+		kernelWave := kernels.NewWavefront()
+		wave := wavefront.NewWavefront(kernelWave)
+		inst := wavefront.NewInst(insts.NewInst())
+		inst.Format = insts.FormatTable[insts.FLAT]
+		inst.Opcode = 28
+		inst.SystemLevelCoherent = true
+		wave.SetDynamicInst(inst)
+
+		transactions := make([]VectorMemAccessInfo, 2)
+		for i := 0; i < 2; i++ {
+			write := mem.WriteReqBuilder{}.
+				WithAddress(0x100).
+				Build()
+			transactions[i].Write = write
+		}
+		coalescer.EXPECT().generateMemTransactions(wave).Return(transactions)
+		instBuffer.EXPECT().Peek().Return(vectorMemInst{wavefront: wave})
+		instBuffer.EXPECT().Pop().Return(vectorMemInst{wavefront: wave})
+
+		vecMemUnit.instToTransaction()
+
+		Expect(cu.InFlightVectorMemAccess).To(HaveLen(2))
+		for _, t := range cu.InFlightVectorMemAccess {
+			Expect(protocol.IsStreamingStore(t.Write)).To(BeTrue())
+		}
+	})
+
 	It("should add transactions to pipeline", func() {
 		transactions := make([]VectorMemAccessInfo, 4)
 		for i := 0; i < 4; i++ {
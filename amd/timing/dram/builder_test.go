@@ -0,0 +1,36 @@
+package dram
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/sarchlab/akita/v4/sim"
+	"github.com/sarchlab/mgpusim/v4/amd/timing/dram/internal/cmdq"
+	"github.com/sarchlab/mgpusim/v4/amd/timing/dram/internal/org"
+)
+
+var _ = Describe("Builder", func() {
+	var builder Builder
+
+	BeforeEach(func() {
+		builder = MakeBuilder().WithEngine(sim.NewSerialEngine())
+	})
+
+	It("should build a channel for each sub-channel", func() {
+		memCtrl := builder.
+			WithNumChannel(2).
+			WithNumSubChannel(2).
+			Build("MemCtrl")
+
+		Expect(memCtrl.channel.(org.Channels)).To(HaveLen(4))
+
+		cmdQueue := memCtrl.cmdQueue.(*cmdq.CommandQueueImpl)
+		Expect(cmdQueue.NumChannel).To(Equal(4))
+		Expect(cmdQueue.Queues).To(HaveLen(8))
+	})
+
+	It("should panic if the sub-channels do not fit the bus width", func() {
+		Expect(func() {
+			builder.WithNumSubChannel(3).Build("MemCtrl")
+		}).To(Panic())
+	})
+})
@@ -0,0 +1,13 @@
+// Package dram defines detailed DRAM modeling.
+//
+// The package is derived from the DRAM controller in Akita. It additionally
+// models refresh, which blocks all the commands for tRFC cycles every tREFI
+// cycles and closes the rows that the open-page policy keeps open, and
+// multiple channels, which have their own banks and command buses and
+// therefore serve requests in parallel. The reads and the writes can also be
+// buffered in separate queues, so that a burst of writes does not fill the
+// slots of the reads. With QoS scheduling, the accesses to the address ranges
+// that are marked as latency-critical are served before the other accesses.
+// The commands issue in the FR-FCFS order by default, or strictly in order
+// with the FCFS scheduling policy.
+package dram
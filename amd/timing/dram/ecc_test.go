@@ -0,0 +1,82 @@
+package dram
+
+import (
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/sarchlab/akita/v4/mem/mem"
+	"github.com/sarchlab/akita/v4/sim"
+	"github.com/sarchlab/akita/v4/sim/directconnection"
+)
+
+var _ = Describe("ECC", func() {
+	var (
+		mockCtrl *gomock.Controller
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+	})
+
+	AfterEach(func() {
+		mockCtrl.Finish()
+	})
+
+	// readLines reads 8 access units and returns the memory controller.
+	readLines := func(builder Builder) *Comp {
+		engine := sim.NewSerialEngine()
+		memCtrl := builder.WithEngine(engine).Build("MemCtrl")
+
+		srcPort := NewMockPort(mockCtrl)
+		srcPort.EXPECT().PeekOutgoing().Return(nil).AnyTimes()
+		srcPort.EXPECT().AsRemote().
+			Return(sim.RemotePort("SrcPort")).AnyTimes()
+		srcPort.EXPECT().Deliver(gomock.Any()).Times(8)
+
+		conn := directconnection.MakeBuilder().
+			WithEngine(engine).
+			WithFreq(1 * sim.GHz).
+			Build("Conn")
+		srcPort.EXPECT().SetConnection(conn)
+		conn.PlugIn(memCtrl.topPort)
+		conn.PlugIn(srcPort)
+
+		for i := uint64(0); i < 8; i++ {
+			read := mem.ReadReqBuilder{}.
+				WithAddress(i * 64).
+				WithByteSize(64).
+				WithSrc(srcPort.AsRemote()).
+				WithDst(memCtrl.topPort.AsRemote()).
+				Build()
+			memCtrl.topPort.Deliver(read)
+		}
+
+		Expect(engine.Run()).To(Succeed())
+
+		return memCtrl
+	}
+
+	numAccess := func(memCtrl *Comp) uint64 {
+		total := uint64(0)
+		for _, s := range memCtrl.RowBufferStats() {
+			total += s.Hits + s.Misses + s.Conflicts
+		}
+
+		return total
+	}
+
+	It("should not access ECC bits by default", func() {
+		memCtrl := readLines(MakeBuilder())
+
+		Expect(memCtrl.ECCStats()).To(Equal(ECCStats{}))
+		Expect(numAccess(memCtrl)).To(Equal(uint64(8)))
+	})
+
+	It("should access the ECC bits of every 8 access units", func() {
+		memCtrl := readLines(MakeBuilder().WithECCBandwidthOverhead(1.0 / 9))
+
+		Expect(memCtrl.ECCStats()).
+			To(Equal(ECCStats{NumAccess: 1, NumByte: 64}))
+		Expect(numAccess(memCtrl)).To(Equal(uint64(9)))
+	})
+})
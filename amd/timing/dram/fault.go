@@ -0,0 +1,33 @@
+package dram
+
+import (
+	"fmt"
+
+	"github.com/sarchlab/mgpusim/v4/amd/timing/dram/internal/signal"
+)
+
+// An UncorrectableFaultPredicate decides if reading the memory at a physical
+// address encounters an error that ECC detects but cannot correct.
+type UncorrectableFaultPredicate func(pAddr uint64) bool
+
+// A FaultHandler is notified of the faults that the memory controller
+// encounters while serving the requests.
+type FaultHandler func(fault error)
+
+func (m *middleware) assignTransFault(t *signal.Transaction) {
+	if m.faultInjector == nil || !t.IsRead() {
+		return
+	}
+
+	t.Faulty = m.faultInjector(t.GlobalAddress())
+}
+
+func (m *middleware) reportUncorrectableFault(t *signal.Transaction) {
+	if m.faultHandler == nil {
+		return
+	}
+
+	m.faultHandler(fmt.Errorf(
+		"uncorrectable memory error at physical address 0x%x on %s",
+		t.GlobalAddress(), m.Name()))
+}
@@ -0,0 +1,43 @@
+package addressmapping
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Default Mapper", func() {
+	var (
+		mapper Mapper
+		table  map[uint64]Location
+	)
+
+	BeforeEach(func() {
+		mapper = MakeBuilder().Build()
+		table = map[uint64]Location{
+			0x0000_0000_0000_0000: {0, 0, 0, 0, 0, 0},
+			0x0000_0000_0000_0040: {0, 0, 0, 0, 0, 1},
+			0x0000_0000_0002_0040: {0, 0, 0, 0, 1, 1},
+			0x0000_0000_0002_4040: {0, 0, 0, 1, 1, 1},
+		}
+	})
+
+	It("should map", func() {
+		for addr, location := range table {
+			loc := mapper.Map(addr)
+			Expect(loc).To(Equal(location))
+		}
+	})
+
+	It("should interleave sub-channels at the access unit granularity", func() {
+		mapper = MakeBuilder().
+			WithNumChannel(2).
+			WithNumSubChannel(2).
+			Build()
+
+		Expect(mapper.Map(0x0000)).To(Equal(Location{}))
+		Expect(mapper.Map(0x0020)).To(Equal(Location{Channel: 1}))
+		Expect(mapper.Map(0x0040)).To(Equal(Location{Column: 1}))
+		Expect(mapper.Map(0x0060)).To(Equal(Location{Channel: 1, Column: 1}))
+		Expect(mapper.Map(0x2_0000)).To(Equal(Location{Channel: 2}))
+	})
+})
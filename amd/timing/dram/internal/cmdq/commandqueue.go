@@ -0,0 +1,14 @@
+// Package cmdq provides command queue implementations
+package cmdq
+
+import (
+	"github.com/sarchlab/mgpusim/v4/amd/timing/dram/internal/signal"
+)
+
+// A CommandQueue is a queue of command that needs to be executed by a rank or
+// a bank.
+type CommandQueue interface {
+	GetCommandsToIssue() []*signal.Command
+	CanAccept(command *signal.Command) bool
+	Accept(command *signal.Command)
+}
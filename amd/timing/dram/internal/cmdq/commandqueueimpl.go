@@ -0,0 +1,176 @@
+package cmdq
+
+import (
+	"github.com/sarchlab/mgpusim/v4/amd/timing/dram/internal/org"
+	"github.com/sarchlab/mgpusim/v4/amd/timing/dram/internal/signal"
+)
+
+// A Queue is a list of commands that needs to be executed by either a bank or a
+// rank.
+type Queue []*signal.Command
+
+// CommandQueueImpl implements a command queue. There is one queue for each
+// rank of each channel. The queues of a channel are placed next to each other.
+type CommandQueueImpl struct {
+	Queues           []Queue
+	CapacityPerQueue int
+	NumChannel       int
+	nextQueueIndex   []int
+	Channel          org.Channel
+
+	// PrioritizeLatencyCritical lets the commands of the latency-critical
+	// transactions issue before the other commands of the same channel.
+	PrioritizeLatencyCritical bool
+
+	// PrioritizeRowHits lets the commands that access the open row of a bank
+	// issue before the older commands of the same queue, as in FR-FCFS.
+	PrioritizeRowHits bool
+
+	// InOrder only lets the oldest command of each queue issue, as in FCFS.
+	InOrder bool
+}
+
+// GetCommandsToIssue returns the commands that are ready to issue. Since each
+// channel has its own command bus, it returns at most one command for each
+// channel.
+func (q *CommandQueueImpl) GetCommandsToIssue() []*signal.Command {
+	if len(q.nextQueueIndex) != q.NumChannel {
+		q.nextQueueIndex = make([]int, q.NumChannel)
+	}
+
+	var cmds []*signal.Command
+
+	for channel := 0; channel < q.NumChannel; channel++ {
+		cmd := q.getCommandToIssueInChannel(channel)
+		if cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+	}
+
+	return cmds
+}
+
+func (q *CommandQueueImpl) getCommandToIssueInChannel(
+	channel int,
+) *signal.Command {
+	numQueuePerChannel := len(q.Queues) / q.NumChannel
+
+	if q.PrioritizeLatencyCritical {
+		for i := 0; i < numQueuePerChannel; i++ {
+			queueIndex := channel*numQueuePerChannel + i
+			readyCmd := q.getFirstReadyInQueue(queueIndex, isLatencyCritical)
+
+			if readyCmd != nil {
+				return readyCmd
+			}
+		}
+	}
+
+	for i := 0; i < numQueuePerChannel; i++ {
+		queueIndex := q.getNextQueue(channel, numQueuePerChannel)
+		readyCmd := q.getFirstReadyInQueue(queueIndex, anyCommand)
+
+		if readyCmd != nil {
+			return readyCmd
+		}
+	}
+
+	return nil
+}
+
+func (q *CommandQueueImpl) getNextQueue(
+	channel int,
+	numQueuePerChannel int,
+) (queueIndex int) {
+	queueIndex = channel*numQueuePerChannel + q.nextQueueIndex[channel]
+	q.nextQueueIndex[channel] =
+		(q.nextQueueIndex[channel] + 1) % numQueuePerChannel
+
+	return queueIndex
+}
+
+func isLatencyCritical(cmd *signal.Command) bool {
+	return cmd.SubTrans != nil && cmd.SubTrans.Transaction.LatencyCritical
+}
+
+func anyCommand(_ *signal.Command) bool {
+	return true
+}
+
+func (q *CommandQueueImpl) getFirstReadyInQueue(
+	queueIndex int,
+	filter func(cmd *signal.Command) bool,
+) *signal.Command {
+	firstReadyIndex := -1
+	var firstReadyCmd *signal.Command
+
+	for i, cmd := range q.Queues[queueIndex] {
+		if !filter(cmd) {
+			continue
+		}
+
+		readyCmd := q.Channel.GetReadyCommand(cmd)
+
+		if readyCmd != nil && cmd.Kind == readyCmd.Kind {
+			return q.takeReadyCommand(queueIndex, i, readyCmd)
+		}
+
+		if readyCmd != nil && firstReadyCmd == nil {
+			firstReadyIndex = i
+			firstReadyCmd = readyCmd
+		}
+
+		if q.InOrder || (firstReadyCmd != nil && !q.PrioritizeRowHits) {
+			break
+		}
+	}
+
+	if firstReadyCmd == nil {
+		return nil
+	}
+
+	return q.takeReadyCommand(queueIndex, firstReadyIndex, firstReadyCmd)
+}
+
+// takeReadyCommand returns the command that is ready to issue for the command
+// at the index of the queue. The command is removed from the queue if the
+// ready command is the command itself, rather than a precharge or an
+// activation that the command needs first.
+func (q *CommandQueueImpl) takeReadyCommand(
+	queueIndex, index int,
+	readyCmd *signal.Command,
+) *signal.Command {
+	cmd := q.Queues[queueIndex][index]
+	if cmd.Kind == readyCmd.Kind {
+		q.Queues[queueIndex] = append(
+			q.Queues[queueIndex][:index], q.Queues[queueIndex][index+1:]...)
+	}
+
+	return readyCmd
+}
+
+// CanAccept returns true is there is empty space in the command queue.
+func (q *CommandQueueImpl) CanAccept(cmd *signal.Command) bool {
+	queueIndex := q.getQueueIndex(cmd)
+	queue := q.Queues[queueIndex]
+
+	return len(queue) < q.CapacityPerQueue
+}
+
+// Accept adds a new command in the command queue.
+func (q *CommandQueueImpl) Accept(cmd *signal.Command) {
+	queueIndex := q.getQueueIndex(cmd)
+	queue := q.Queues[queueIndex]
+
+	if len(queue) >= q.CapacityPerQueue {
+		panic("command queue overflow")
+	}
+
+	q.Queues[queueIndex] = append(queue, cmd)
+}
+
+func (q *CommandQueueImpl) getQueueIndex(cmd *signal.Command) int {
+	numQueuePerChannel := len(q.Queues) / q.NumChannel
+
+	return int(cmd.Channel)*numQueuePerChannel + int(cmd.Rank)
+}
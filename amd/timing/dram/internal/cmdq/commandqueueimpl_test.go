@@ -0,0 +1,161 @@
+package cmdq
+
+import (
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/sarchlab/mgpusim/v4/amd/timing/dram/internal/addressmapping"
+	"github.com/sarchlab/mgpusim/v4/amd/timing/dram/internal/signal"
+)
+
+var _ = Describe("CommandQueueImpl", func() {
+	var (
+		mockCtrl *gomock.Controller
+		channel  *MockChannel
+		q        CommandQueueImpl
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		channel = NewMockChannel(mockCtrl)
+		q = CommandQueueImpl{
+			Queues:           make([]Queue, 8),
+			CapacityPerQueue: 8,
+			NumChannel:       1,
+			Channel:          channel,
+		}
+	})
+
+	AfterEach(func() {
+		mockCtrl.Finish()
+	})
+
+	It("should get the next command to issue", func() {
+		cmd1 := &signal.Command{
+			ID:   "1",
+			Kind: signal.CmdKindRead,
+			Location: addressmapping.Location{
+				Rank: 0,
+				Bank: 0,
+			},
+		}
+		q.Queues[0] = append(q.Queues[0], cmd1)
+
+		cmd2 := &signal.Command{
+			ID:   "2",
+			Kind: signal.CmdKindRead,
+			Location: addressmapping.Location{
+				Rank: 0,
+				Bank: 0,
+			},
+		}
+		q.Queues[0] = append(q.Queues[0], cmd2)
+
+		cmd3 := &signal.Command{
+			ID:   "3",
+			Kind: signal.CmdKindRead,
+			Location: addressmapping.Location{
+				Rank: 0,
+				Bank: 1,
+			},
+		}
+		q.Queues[1] = append(q.Queues[1], cmd3)
+
+		channel.EXPECT().
+			GetReadyCommand(cmd1).
+			Return(nil)
+		channel.EXPECT().
+			GetReadyCommand(cmd2).
+			Return(cmd2)
+
+		readyCmds := q.GetCommandsToIssue()
+
+		Expect(readyCmds).To(Equal([]*signal.Command{cmd2}))
+		Expect(q.Queues[0]).NotTo(ContainElement(cmd2))
+	})
+
+	It("should issue one command in each channel", func() {
+		q.NumChannel = 2
+
+		cmd1 := &signal.Command{
+			ID:   "1",
+			Kind: signal.CmdKindRead,
+			Location: addressmapping.Location{
+				Channel: 0,
+				Rank:    1,
+			},
+		}
+		q.Accept(cmd1)
+
+		cmd2 := &signal.Command{
+			ID:   "2",
+			Kind: signal.CmdKindRead,
+			Location: addressmapping.Location{
+				Channel: 1,
+				Rank:    1,
+			},
+		}
+		q.Accept(cmd2)
+
+		channel.EXPECT().
+			GetReadyCommand(cmd1).
+			Return(cmd1)
+		channel.EXPECT().
+			GetReadyCommand(cmd2).
+			Return(cmd2)
+
+		readyCmds := q.GetCommandsToIssue()
+
+		Expect(readyCmds).To(Equal([]*signal.Command{cmd1, cmd2}))
+		Expect(q.Queues[1]).To(BeEmpty())
+		Expect(q.Queues[5]).To(BeEmpty())
+	})
+
+	It("should accept new commands", func() {
+		cmd := &signal.Command{}
+
+		Expect(q.CanAccept(cmd)).To(BeTrue())
+
+		q.Accept(cmd)
+
+		Expect(q.Queues[0]).To(ContainElement(cmd))
+	})
+
+	It("should issue the row hits first with PrioritizeRowHits", func() {
+		q.PrioritizeRowHits = true
+
+		cmd1 := &signal.Command{ID: "1", Kind: signal.CmdKindRead}
+		cmd2 := &signal.Command{ID: "2", Kind: signal.CmdKindRead}
+		q.Queues[0] = append(q.Queues[0], cmd1, cmd2)
+
+		precharge := &signal.Command{ID: "1", Kind: signal.CmdKindPrecharge}
+		channel.EXPECT().
+			GetReadyCommand(cmd1).
+			Return(precharge)
+		channel.EXPECT().
+			GetReadyCommand(cmd2).
+			Return(cmd2)
+
+		readyCmds := q.GetCommandsToIssue()
+
+		Expect(readyCmds).To(Equal([]*signal.Command{cmd2}))
+		Expect(q.Queues[0]).To(Equal(Queue{cmd1}))
+	})
+
+	It("should only issue the oldest command with InOrder", func() {
+		q.InOrder = true
+
+		cmd1 := &signal.Command{ID: "1", Kind: signal.CmdKindRead}
+		cmd2 := &signal.Command{ID: "2", Kind: signal.CmdKindRead}
+		q.Queues[0] = append(q.Queues[0], cmd1, cmd2)
+
+		channel.EXPECT().
+			GetReadyCommand(cmd1).
+			Return(nil)
+
+		readyCmds := q.GetCommandsToIssue()
+
+		Expect(readyCmds).To(BeEmpty())
+		Expect(q.Queues[0]).To(Equal(Queue{cmd1, cmd2}))
+	})
+})
@@ -0,0 +1,18 @@
+package org
+
+import (
+	"github.com/sarchlab/akita/v4/tracing"
+	"github.com/sarchlab/mgpusim/v4/amd/timing/dram/internal/signal"
+)
+
+// A Bank is a DRAM Bank. It contains a number of rows and columns.
+type Bank interface {
+	tracing.NamedHookable
+
+	GetReadyCommand(
+		cmd *signal.Command,
+	) *signal.Command
+	StartCommand(cmd *signal.Command)
+	UpdateTiming(cmdKind signal.CommandKind, cycleNeeded int)
+	Tick() bool
+}
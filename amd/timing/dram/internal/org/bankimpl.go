@@ -0,0 +1,316 @@
+package org
+
+import (
+	"github.com/sarchlab/akita/v4/sim"
+	"github.com/sarchlab/akita/v4/tracing"
+	"github.com/sarchlab/mgpusim/v4/amd/timing/dram/internal/signal"
+)
+
+// BankState represents the current state of a bank.
+type BankState int
+
+// A list of possible bank states.
+const (
+	BankStateOpen BankState = iota
+	BankStateClosed
+	BankStateSRef
+	BankStatePD
+	BankStateInvalid
+)
+
+// RowBufferStats counts how the accesses to a bank use the row buffer.
+type RowBufferStats struct {
+	// Hits counts the accesses to the row that is already open.
+	Hits uint64
+
+	// Misses counts the accesses that find the bank precharged and have to
+	// activate the row.
+	Misses uint64
+
+	// Conflicts counts the accesses that find another row open and have to
+	// precharge the bank before activating the row.
+	Conflicts uint64
+}
+
+// BankImpl provides a basic implementation of a bank.
+type BankImpl struct {
+	sim.HookableBase
+	BankName             string
+	state                BankState
+	currentCmd           *signal.Command
+	openRow              uint64
+	CmdCycles            map[signal.CommandKind]int
+	cyclesToCmdAvailable map[signal.CommandKind]int
+
+	rowBufferStats RowBufferStats
+	classified     map[*signal.SubTransaction]bool
+}
+
+// NewBankImpl creates a new BankImpl.
+func NewBankImpl(name string) *BankImpl {
+	b := &BankImpl{
+		BankName:             name,
+		state:                BankStateClosed,
+		cyclesToCmdAvailable: make(map[signal.CommandKind]int),
+		CmdCycles:            make(map[signal.CommandKind]int),
+	}
+
+	return b
+}
+
+// Name returns the name of the Bank.
+func (b *BankImpl) Name() string {
+	return b.BankName
+}
+
+// Tick updates the internal states of the bank.
+func (b *BankImpl) Tick() (madeProgress bool) {
+	madeProgress = b.countDownCurrentCmd() || madeProgress
+	madeProgress = b.countDownTiming() || madeProgress
+
+	return madeProgress
+}
+
+func (b *BankImpl) countDownTiming() (madeProgress bool) {
+	for i := range b.cyclesToCmdAvailable {
+		if b.cyclesToCmdAvailable[i] > 0 {
+			b.cyclesToCmdAvailable[i]--
+			madeProgress = true
+		}
+	}
+
+	return madeProgress
+}
+
+func (b *BankImpl) countDownCurrentCmd() (madeProgress bool) {
+	if b.currentCmd != nil {
+		b.currentCmd.CycleLeft--
+		if b.currentCmd.CycleLeft <= 0 {
+			b.completeCurrentCmd()
+		}
+
+		madeProgress = true
+	}
+
+	return madeProgress
+}
+
+func (b *BankImpl) completeCurrentCmd() {
+	b.currentCmd.CycleLeft = 0
+
+	tracing.EndTask(b.currentCmd.ID, b)
+
+	if b.currentCmd.IsReadOrWrite() {
+		b.currentCmd.SubTrans.Completed = true
+
+		tracing.EndTask(b.currentCmd.SubTrans.ID, b)
+	}
+
+	// fmt.Printf("%.10f, %s, cmd completed, %s\n",
+	// 	now, b.Name(), b.currentCmd.Kind.String())
+
+	b.currentCmd = nil
+}
+
+// GetReadyCommand returns the next command is ready to be issued. The bank
+// runs one command at a time, so no command is ready while the bank is busy.
+func (b *BankImpl) GetReadyCommand(
+	cmd *signal.Command,
+) *signal.Command {
+	if b.currentCmd != nil {
+		return nil
+	}
+
+	requiredKind := b.getRequiredCommandKind(cmd)
+	if requiredKind == signal.NumCmdKind {
+		panic("never")
+	}
+
+	if b.cyclesToCmdAvailable[requiredKind] == 0 {
+		readyCmd := cmd.Clone()
+		readyCmd.Kind = requiredKind
+
+		return readyCmd
+	}
+
+	return nil
+}
+
+func (b *BankImpl) getRequiredCommandKind(
+	cmd *signal.Command,
+) signal.CommandKind {
+	key := cmdKindTableKey{b.state, cmd.Kind}
+
+	kindFunc, found := requiredCmdKindTable[key]
+	if !found {
+		return signal.NumCmdKind
+	}
+
+	return kindFunc(b, cmd)
+}
+
+// StartCommand starts a new command in the Bank.
+func (b *BankImpl) StartCommand(cmd *signal.Command) {
+	if b.currentCmd != nil {
+		panic("previous cmd is not completed")
+	}
+
+	b.currentCmd = cmd
+	b.currentCmd.CycleLeft = b.CmdCycles[cmd.Kind]
+
+	key := cmdKindTableKey{b.state, cmd.Kind}
+
+	updateFunc, found := stateUpdateTable[key]
+	if !found {
+		panic("never")
+	}
+
+	updateFunc(b, cmd)
+	b.countRowBufferAccess(cmd)
+
+	// fmt.Printf("%.10f, %s, cmd started, %s\n",
+	// 	now, b.Name(), b.currentCmd.Kind.String())
+
+	tracing.StartTask(
+		cmd.ID,
+		cmd.SubTrans.ID,
+		b,
+		"cmd",
+		cmd.Kind.String(),
+		nil,
+	)
+}
+
+// PrechargeForRefresh closes the open row of the bank, as a refresh
+// precharges all the banks of the rank before refreshing them.
+func (b *BankImpl) PrechargeForRefresh() {
+	if b.state == BankStateOpen {
+		b.state = BankStateClosed
+	}
+}
+
+// RowBufferStats returns how the accesses to the bank have used the row buffer.
+func (b *BankImpl) RowBufferStats() RowBufferStats {
+	return b.rowBufferStats
+}
+
+// countRowBufferAccess classifies an access by the first command that the
+// bank starts for it. The access is a conflict if the first command is a
+// precharge, a miss if it is an activation, and a hit if the access can read
+// or write the open row directly.
+func (b *BankImpl) countRowBufferAccess(cmd *signal.Command) {
+	if cmd.SubTrans == nil {
+		return
+	}
+
+	if cmd.IsReadOrWrite() {
+		if !b.classified[cmd.SubTrans] {
+			b.rowBufferStats.Hits++
+		}
+
+		delete(b.classified, cmd.SubTrans)
+
+		return
+	}
+
+	if b.classified[cmd.SubTrans] {
+		return
+	}
+
+	if b.classified == nil {
+		b.classified = make(map[*signal.SubTransaction]bool)
+	}
+
+	switch cmd.Kind {
+	case signal.CmdKindPrecharge:
+		b.rowBufferStats.Conflicts++
+	case signal.CmdKindActivate:
+		b.rowBufferStats.Misses++
+	default:
+		return
+	}
+
+	b.classified[cmd.SubTrans] = true
+}
+
+// UpdateTiming updates timing related states of the bank.
+func (b *BankImpl) UpdateTiming(cmdKind signal.CommandKind, cycleNeeded int) {
+	t := b.cyclesToCmdAvailable[cmdKind]
+
+	//fmt.Printf("%s, cmd timing updated, %s, %d, %d\n",
+	//	b.Name(), cmdKind.String(),
+	//	cycleNeeded, b.cyclesToCmdAvailable[cmdKind])
+
+	if t < cycleNeeded {
+		b.cyclesToCmdAvailable[cmdKind] = cycleNeeded
+	}
+}
+
+type cmdKindTableKey struct {
+	bankState BankState
+	cmdKind   signal.CommandKind
+}
+
+type requiredCmdKindFunc func(
+	b *BankImpl,
+	cmd *signal.Command,
+) signal.CommandKind
+
+type updateStateFunc func(
+	b *BankImpl,
+	cmd *signal.Command,
+)
+
+var requiredCmdKindTable map[cmdKindTableKey]requiredCmdKindFunc
+var stateUpdateTable map[cmdKindTableKey]updateStateFunc
+
+func returnCmdKindActive(b *BankImpl, cmd *signal.Command) signal.CommandKind {
+	return signal.CmdKindActivate
+}
+
+func actionOnOpenRowOrPrecharge(
+	b *BankImpl,
+	cmd *signal.Command,
+) signal.CommandKind {
+	if b.openRow == cmd.Row {
+		return cmd.Kind
+	}
+
+	return signal.CmdKindPrecharge
+}
+
+func openRow(b *BankImpl, cmd *signal.Command) {
+	b.openRow = cmd.Row
+	b.state = BankStateOpen
+}
+
+func closeRow(b *BankImpl, cmd *signal.Command) {
+	b.state = BankStateClosed
+}
+
+func doNothing(b *BankImpl, cmd *signal.Command) {
+	// Do nothing
+}
+
+// nolint: lll
+func init() {
+	requiredCmdKindTable = map[cmdKindTableKey]requiredCmdKindFunc{
+		{BankStateClosed, signal.CmdKindRead}:           returnCmdKindActive,
+		{BankStateClosed, signal.CmdKindReadPrecharge}:  returnCmdKindActive,
+		{BankStateClosed, signal.CmdKindWrite}:          returnCmdKindActive,
+		{BankStateClosed, signal.CmdKindWritePrecharge}: returnCmdKindActive,
+		{BankStateOpen, signal.CmdKindRead}:             actionOnOpenRowOrPrecharge,
+		{BankStateOpen, signal.CmdKindReadPrecharge}:    actionOnOpenRowOrPrecharge,
+		{BankStateOpen, signal.CmdKindWrite}:            actionOnOpenRowOrPrecharge,
+		{BankStateOpen, signal.CmdKindWritePrecharge}:   actionOnOpenRowOrPrecharge,
+	}
+
+	stateUpdateTable = map[cmdKindTableKey]updateStateFunc{
+		{BankStateClosed, signal.CmdKindActivate}:     openRow,
+		{BankStateOpen, signal.CmdKindPrecharge}:      closeRow,
+		{BankStateOpen, signal.CmdKindReadPrecharge}:  closeRow,
+		{BankStateOpen, signal.CmdKindWritePrecharge}: closeRow,
+		{BankStateOpen, signal.CmdKindRead}:           doNothing,
+		{BankStateOpen, signal.CmdKindWrite}:          doNothing,
+	}
+}
@@ -0,0 +1,3 @@
+// Package org defines the DRAM organization related sub-component definitions,
+// such as Channels and Banks.
+package org
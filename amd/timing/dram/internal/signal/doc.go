@@ -0,0 +1,3 @@
+// Package signal defines the common data structures used in the dram system,
+// including transactions, sub-transactions, and commands.
+package signal
@@ -0,0 +1,10 @@
+package trans
+
+import (
+	"github.com/sarchlab/mgpusim/v4/amd/timing/dram/internal/signal"
+)
+
+// A CommandCreator can convert a subtransaction to a command.
+type CommandCreator interface {
+	Create(subTrans *signal.SubTransaction) *signal.Command
+}
@@ -0,0 +1,3 @@
+// Package trans defines concepts related to DRAM transactions and
+// subtransactions.
+package trans
@@ -0,0 +1,34 @@
+package trans
+
+import (
+	"github.com/sarchlab/akita/v4/sim"
+	"github.com/sarchlab/mgpusim/v4/amd/timing/dram/internal/addressmapping"
+	"github.com/sarchlab/mgpusim/v4/amd/timing/dram/internal/signal"
+)
+
+// OpenPageCommandCreator creates read and write commands that keep the row
+// open after the access, so that the following accesses to the same row can
+// hit in the row buffer.
+type OpenPageCommandCreator struct {
+	AddrMapper addressmapping.Mapper
+}
+
+// Create creates new commands that can accomplish the subTrans.
+func (c *OpenPageCommandCreator) Create(
+	subTrans *signal.SubTransaction,
+) *signal.Command {
+	cmd := &signal.Command{
+		ID: sim.GetIDGenerator().Generate(),
+	}
+
+	if subTrans.IsRead() {
+		cmd.Kind = signal.CmdKindRead
+	} else {
+		cmd.Kind = signal.CmdKindWrite
+	}
+
+	cmd.Location = c.AddrMapper.Map(subTrans.Address)
+	cmd.SubTrans = subTrans
+
+	return cmd
+}
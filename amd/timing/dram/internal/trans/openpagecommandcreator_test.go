@@ -0,0 +1,49 @@
+package trans
+
+import (
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/sarchlab/akita/v4/mem/mem"
+	"github.com/sarchlab/mgpusim/v4/amd/timing/dram/internal/addressmapping"
+	"github.com/sarchlab/mgpusim/v4/amd/timing/dram/internal/signal"
+)
+
+var _ = Describe("OpenPageCommandCreator", func() {
+	var (
+		mockCtrl   *gomock.Controller
+		mapper     *MockMapper
+		cmdCreator *OpenPageCommandCreator
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		mapper = NewMockMapper(mockCtrl)
+		cmdCreator = &OpenPageCommandCreator{
+			AddrMapper: mapper,
+		}
+	})
+
+	AfterEach(func() {
+		mockCtrl.Finish()
+	})
+
+	It("should create write commands", func() {
+		write := mem.WriteReqBuilder{}.Build()
+		trans := &signal.Transaction{Write: write}
+		subTrans := &signal.SubTransaction{
+			Transaction: trans,
+			Address:     0x40,
+		}
+
+		mapper.EXPECT().Map(uint64(0x40)).Return(addressmapping.Location{
+			Row: 5,
+		})
+
+		cmd := cmdCreator.Create(subTrans)
+
+		Expect(cmd.Kind).To(Equal(signal.CmdKindWrite))
+		Expect(cmd.Row).To(Equal(uint64(5)))
+		Expect(cmd.SubTrans).To(BeIdenticalTo(subTrans))
+	})
+})
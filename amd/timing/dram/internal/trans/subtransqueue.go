@@ -0,0 +1,12 @@
+package trans
+
+import (
+	"github.com/sarchlab/mgpusim/v4/amd/timing/dram/internal/signal"
+)
+
+// A SubTransactionQueue is a queue for subtransactions.
+type SubTransactionQueue interface {
+	CanPush(n int) bool
+	Push(t *signal.Transaction)
+	Tick() bool
+}
@@ -0,0 +1,481 @@
+package dram
+
+import (
+	"sync"
+
+	"github.com/sarchlab/akita/v4/mem/mem"
+	"github.com/sarchlab/akita/v4/sim"
+	"github.com/sarchlab/akita/v4/tracing"
+	"github.com/sarchlab/mgpusim/v4/amd/timing/dram/internal/addressmapping"
+	"github.com/sarchlab/mgpusim/v4/amd/timing/dram/internal/cmdq"
+	"github.com/sarchlab/mgpusim/v4/amd/timing/dram/internal/org"
+	"github.com/sarchlab/mgpusim/v4/amd/timing/dram/internal/signal"
+	"github.com/sarchlab/mgpusim/v4/amd/timing/dram/internal/trans"
+)
+
+// Protocol defines the category of the memory controller.
+type Protocol int
+
+// A list of all supported DRAM protocols.
+const (
+	DDR3 Protocol = iota
+	DDR4
+	GDDR5
+	GDDR5X
+	GDDR6
+	LPDDR
+	LPDDR3
+	LPDDR4
+	HBM
+	HBM2
+	HMC
+)
+
+func (p Protocol) isGDDR() bool {
+	return p == GDDR5 || p == GDDR5X || p == GDDR6
+}
+
+func (p Protocol) isHBM() bool {
+	return p == HBM || p == HBM2
+}
+
+// Comp is a MemController handles read and write requests.
+type Comp struct {
+	*sim.TickingComponent
+	sim.MiddlewareHolder
+
+	topPort sim.Port
+
+	storage             *mem.Storage
+	addrConverter       mem.AddressConverter
+	subTransSplitter    trans.SubTransSplitter
+	addrMapper          addressmapping.Mapper
+	subTransactionQueue trans.SubTransactionQueue
+	cmdQueue            cmdq.CommandQueue
+	channel             org.Channel
+
+	// writeSubTransactionQueue buffers the write sub-transactions. It is nil
+	// if the reads and the writes share the subTransactionQueue.
+	writeSubTransactionQueue *trans.FCFSSubTransactionQueue
+	writeDrainHigh           int
+	writeDrainLow            int
+	drainingWrites           bool
+
+	// latencyCriticalSubTransactionQueue buffers the sub-transactions of the
+	// latency-critical transactions. It is nil if QoS scheduling is disabled.
+	latencyCriticalSubTransactionQueue trans.SubTransactionQueue
+	qosMutex                           sync.RWMutex
+	qosPages                           map[uint64]QoSClass
+
+	inflightTransactions []*signal.Transaction
+	banks                []*org.BankImpl
+
+	refreshInterval int
+	refreshCycles   int
+
+	// eccBytesPerDataByte is the number of ECC bytes that are accessed along
+	// with each data byte. It is 0 if ECC is disabled.
+	eccBytesPerDataByte float64
+	pendingECCBytes     float64
+	accessUnitSize      uint64
+	eccStats            ECCStats
+
+	faultInjector UncorrectableFaultPredicate
+	faultHandler  FaultHandler
+}
+
+// ECCStats counts the accesses to the ECC bits.
+type ECCStats struct {
+	// NumAccess is the number of accesses to the ECC bits.
+	NumAccess uint64
+
+	// NumByte is the number of ECC bytes that are read or written.
+	NumByte uint64
+}
+
+// ECCStats returns the accesses that the memory controller has made to the ECC
+// bits.
+func (c *Comp) ECCStats() ECCStats {
+	return c.eccStats
+}
+
+// RowBufferStats counts how the accesses to a bank use the row buffer.
+type RowBufferStats struct {
+	// Bank is the name of the bank.
+	Bank string
+
+	// Hits counts the accesses to the row that is already open.
+	Hits uint64
+
+	// Misses counts the accesses that find the bank precharged.
+	Misses uint64
+
+	// Conflicts counts the accesses that find another row open.
+	Conflicts uint64
+}
+
+// RowBufferStats returns how the accesses to each bank of the memory
+// controller have used the row buffer. With the default close-page policy,
+// the banks are precharged after each access, so all the accesses are misses.
+func (c *Comp) RowBufferStats() []RowBufferStats {
+	stats := make([]RowBufferStats, 0, len(c.banks))
+
+	for _, bank := range c.banks {
+		s := bank.RowBufferStats()
+		stats = append(stats, RowBufferStats{
+			Bank:      bank.Name(),
+			Hits:      s.Hits,
+			Misses:    s.Misses,
+			Conflicts: s.Conflicts,
+		})
+	}
+
+	return stats
+}
+
+func (c *Comp) Tick() bool {
+	return c.MiddlewareHolder.Tick()
+}
+
+type middleware struct {
+	*Comp
+}
+
+// Tick updates memory controller's internal state.
+func (m *middleware) Tick() (madeProgress bool) {
+	madeProgress = m.respond() || madeProgress
+	madeProgress = m.respond() || madeProgress
+	madeProgress = m.channel.Tick() || madeProgress
+	madeProgress = m.refreshOrIssue() || madeProgress
+	madeProgress = m.tickSubTransactionQueues() || madeProgress
+	madeProgress = m.parseTop() || madeProgress
+
+	return madeProgress
+}
+
+func (m *middleware) parseTop() (madeProgress bool) {
+	msg := m.topPort.PeekIncoming()
+	if msg == nil {
+		return false
+	}
+
+	trans := &signal.Transaction{}
+	switch msg := msg.(type) {
+	case *mem.ReadReq:
+		trans.Read = msg
+	case *mem.WriteReq:
+		trans.Write = msg
+	}
+
+	m.assignTransInternalAddress(trans)
+	m.assignTransQoSClass(trans)
+	m.assignTransFault(trans)
+	m.subTransSplitter.Split(trans)
+	pendingECCBytes, numECCAccess := m.addECCSubTransactions(trans)
+
+	queue := m.subTransactionQueueOf(trans)
+	if !queue.CanPush(len(trans.SubTransactions)) {
+		return false
+	}
+
+	m.pendingECCBytes = pendingECCBytes
+	m.eccStats.NumAccess += numECCAccess
+	m.eccStats.NumByte += numECCAccess * m.accessUnitSize
+
+	queue.Push(trans)
+	m.inflightTransactions = append(m.inflightTransactions, trans)
+	m.topPort.RetrieveIncoming()
+
+	tracing.TraceReqReceive(msg, m.Comp)
+
+	for _, st := range trans.SubTransactions {
+		tracing.StartTaskWithSpecificLocation(
+			st.ID,
+			tracing.MsgIDAtReceiver(msg, m.Comp),
+			m.Comp,
+			"sub-trans",
+			"sub-trans",
+			m.Comp.Name()+".SubTransQueue",
+			nil,
+		)
+	}
+
+	// fmt.Printf("%.10f, %s, start transaction, %s, %x\n",
+	// 	now, c.Name(), msg.Meta().ID, trans.InternalAddress)
+
+	return true
+}
+
+// addECCSubTransactions adds the accesses to the ECC bits of the data to the
+// transaction, so that the transaction completes after its ECC bits are read
+// or written. The ECC bytes are accumulated across the transactions, and an
+// access is added whenever they fill an access unit. It returns the ECC bytes
+// that are left for the following transactions and the number of accesses
+// added.
+func (m *middleware) addECCSubTransactions(
+	t *signal.Transaction,
+) (pendingECCBytes float64, numAccess uint64) {
+	if m.eccBytesPerDataByte == 0 {
+		return 0, 0
+	}
+
+	dataBytes := uint64(len(t.SubTransactions)) * m.accessUnitSize
+	pendingECCBytes = m.pendingECCBytes +
+		float64(dataBytes)*m.eccBytesPerDataByte
+
+	for pendingECCBytes >= float64(m.accessUnitSize) {
+		pendingECCBytes -= float64(m.accessUnitSize)
+		numAccess++
+
+		st := &signal.SubTransaction{
+			ID:          sim.GetIDGenerator().Generate(),
+			Transaction: t,
+			Address:     t.SubTransactions[0].Address,
+		}
+		t.SubTransactions = append(t.SubTransactions, st)
+	}
+
+	return pendingECCBytes, numAccess
+}
+
+func (m *middleware) subTransactionQueueOf(
+	t *signal.Transaction,
+) trans.SubTransactionQueue {
+	if t.LatencyCritical {
+		return m.latencyCriticalSubTransactionQueue
+	}
+
+	if t.Write != nil && m.writeSubTransactionQueue != nil {
+		return m.writeSubTransactionQueue
+	}
+
+	return m.subTransactionQueue
+}
+
+// tickSubTransactionQueues moves sub-transactions to the command queues. The
+// latency-critical sub-transactions go first. When reads and writes are in
+// separate queues, the reads go before the writes, as the requesters are
+// waiting for their data, unless the write queue is being drained.
+func (m *middleware) tickSubTransactionQueues() bool {
+	if m.latencyCriticalSubTransactionQueue != nil &&
+		m.latencyCriticalSubTransactionQueue.Tick() {
+		return true
+	}
+
+	if m.writeSubTransactionQueue == nil {
+		return m.subTransactionQueue.Tick()
+	}
+
+	m.updateWriteDrain()
+
+	if m.drainingWrites && m.writeSubTransactionQueue.Tick() {
+		return true
+	}
+
+	if m.subTransactionQueue.Tick() {
+		return true
+	}
+
+	return m.writeSubTransactionQueue.Tick()
+}
+
+// updateWriteDrain starts draining the write queue when it reaches the high
+// watermark and stops when it falls to the low watermark.
+func (m *middleware) updateWriteDrain() {
+	numWrites := len(m.writeSubTransactionQueue.Queue)
+
+	if numWrites >= m.writeDrainHigh {
+		m.drainingWrites = true
+	} else if numWrites <= m.writeDrainLow {
+		m.drainingWrites = false
+	}
+}
+
+// dependsOnOlderTransaction returns true if the sub-transaction has to wait
+// for an older transaction that accesses the same bytes and that has not
+// completed, which is the case when a read follows a write or a write follows
+// a read. As the data is read from and written to the storage when the
+// transactions complete, letting the sub-transaction go first would return
+// stale data or overwrite the data that a read has not returned yet. The
+// reads can go before each other.
+func (c *Comp) dependsOnOlderTransaction(st *signal.SubTransaction) bool {
+	t := st.Transaction
+
+	for _, older := range c.inflightTransactions {
+		if older == t {
+			return false
+		}
+
+		if older.IsRead() && t.IsRead() {
+			continue
+		}
+
+		if overlaps(older, t) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func overlaps(a, b *signal.Transaction) bool {
+	return a.InternalAddress < b.InternalAddress+b.AccessByteSize() &&
+		b.InternalAddress < a.InternalAddress+a.AccessByteSize()
+}
+
+func (m *middleware) assignTransInternalAddress(trans *signal.Transaction) {
+	if m.addrConverter != nil {
+		trans.InternalAddress = m.addrConverter.ConvertExternalToInternal(
+			trans.GlobalAddress())
+		return
+	}
+
+	trans.InternalAddress = trans.GlobalAddress()
+}
+
+// refreshOrIssue issues a command if the DRAM is not being refreshed. If the
+// DRAM is being refreshed, it keeps the controller ticking so that the pending
+// commands are issued after the refresh completes.
+func (m *middleware) refreshOrIssue() (madeProgress bool) {
+	if m.isRefreshing() {
+		m.closeRowsForRefresh()
+		return len(m.inflightTransactions) > 0
+	}
+
+	return m.issue()
+}
+
+// closeRowsForRefresh closes the rows that the open-page policy keeps open,
+// so that the first access to each bank after a refresh activates its row.
+// With the close-page policy, the banks are already precharged.
+func (m *middleware) closeRowsForRefresh() {
+	for _, bank := range m.banks {
+		bank.PrechargeForRefresh()
+	}
+}
+
+// isRefreshing returns true if the current cycle is in a refresh window. A
+// refresh window starts every refreshInterval cycles and lasts for
+// refreshCycles cycles, during which no command can be issued.
+func (m *middleware) isRefreshing() bool {
+	if m.refreshInterval <= 0 {
+		return false
+	}
+
+	cycle := m.Freq.Cycle(m.Engine.CurrentTime())
+
+	return cycle%uint64(m.refreshInterval) < uint64(m.refreshCycles)
+}
+
+func (m *middleware) issue() (madeProgress bool) {
+	cmds := m.cmdQueue.GetCommandsToIssue()
+	if len(cmds) == 0 {
+		return false
+	}
+
+	for _, cmd := range cmds {
+		m.channel.StartCommand(cmd)
+		m.channel.UpdateTiming(cmd)
+	}
+
+	return true
+}
+
+func (m *middleware) respond() (madeProgress bool) {
+	for i, t := range m.inflightTransactions {
+		if t.IsCompleted() {
+			done := m.finalizeTransaction(t, i)
+			if done {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func (m *middleware) finalizeTransaction(
+	t *signal.Transaction,
+	i int,
+) (done bool) {
+	if t.Write != nil {
+		done = m.finalizeWriteTrans(t, i)
+		if done {
+			tracing.TraceReqComplete(t.Write, m.Comp)
+		}
+	} else {
+		done = m.finalizeReadTrans(t, i)
+		if done {
+			tracing.TraceReqComplete(t.Read, m.Comp)
+		}
+	}
+
+	return done
+}
+
+func (m *middleware) finalizeWriteTrans(
+	t *signal.Transaction,
+	i int,
+) (done bool) {
+	err := m.storage.Write(t.InternalAddress, t.Write.Data)
+	if err != nil {
+		panic(err)
+	}
+
+	writeDone := mem.WriteDoneRspBuilder{}.
+		WithSrc(m.topPort.AsRemote()).
+		WithDst(t.Write.Src).
+		WithRspTo(t.Write.ID).
+		Build()
+
+	sendErr := m.topPort.Send(writeDone)
+	if sendErr == nil {
+		m.inflightTransactions = append(
+			m.inflightTransactions[:i],
+			m.inflightTransactions[i+1:]...)
+
+		// fmt.Printf("%.10f, %s, finish transaction %s, %x\n",
+		// 	now, c.Name(), t.Write.ID, t.InternalAddress)
+		return true
+	}
+
+	return false
+}
+
+func (m *middleware) finalizeReadTrans(
+	t *signal.Transaction,
+	i int,
+) (done bool) {
+	data, err := m.storage.Read(t.InternalAddress, t.Read.AccessByteSize)
+	if err != nil {
+		panic(err)
+	}
+
+	if t.Faulty {
+		data = make([]byte, len(data))
+	}
+
+	dataReady := mem.DataReadyRspBuilder{}.
+		WithSrc(m.topPort.AsRemote()).
+		WithDst(t.Read.Src).
+		WithData(data).
+		WithRspTo(t.Read.ID).
+		Build()
+
+	sendErr := m.topPort.Send(dataReady)
+	if sendErr == nil {
+		m.inflightTransactions = append(
+			m.inflightTransactions[:i],
+			m.inflightTransactions[i+1:]...)
+
+		if t.Faulty {
+			m.reportUncorrectableFault(t)
+		}
+
+		// fmt.Printf("%.10f, %s, finish transaction %s, %x\n",
+		// 	now, c.Name(), t.Read.ID, t.InternalAddress)
+		return true
+	}
+
+	return false
+}
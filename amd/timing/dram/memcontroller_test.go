@@ -0,0 +1,514 @@
+package dram
+
+import (
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/sarchlab/akita/v4/mem/mem"
+	"github.com/sarchlab/akita/v4/sim"
+	"github.com/sarchlab/akita/v4/sim/directconnection"
+	"github.com/sarchlab/mgpusim/v4/amd/timing/dram/internal/signal"
+)
+
+var _ = Describe("MemController", func() {
+	var (
+		mockCtrl *gomock.Controller
+
+		topPort             *MockPort
+		addrConverter       *MockAddressConverter
+		subTransSplitter    *MockSubTransSplitter
+		subTransactionQueue *MockSubTransactionQueue
+		cmdQueue            *MockCommandQueue
+		channel             *MockChannel
+		storage             *mem.Storage
+
+		memCtrl           *Comp
+		memCtrlMiddleware *middleware
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+
+		topPort = NewMockPort(mockCtrl)
+		topPort.EXPECT().AsRemote().Return(sim.RemotePort("TopPort")).AnyTimes()
+
+		subTransactionQueue = NewMockSubTransactionQueue(mockCtrl)
+		subTransSplitter = NewMockSubTransSplitter(mockCtrl)
+		addrConverter = NewMockAddressConverter(mockCtrl)
+		cmdQueue = NewMockCommandQueue(mockCtrl)
+		channel = NewMockChannel(mockCtrl)
+		storage = mem.NewStorage(4 * mem.GB)
+
+		memCtrl = MakeBuilder().Build("MemCtrl")
+		memCtrl.topPort = topPort
+		memCtrl.subTransactionQueue = subTransactionQueue
+		memCtrl.subTransSplitter = subTransSplitter
+		memCtrl.addrConverter = addrConverter
+		memCtrl.cmdQueue = cmdQueue
+		memCtrl.channel = channel
+		memCtrl.storage = storage
+		memCtrlMiddleware = memCtrl.Middlewares()[0].(*middleware)
+	})
+
+	AfterEach(func() {
+		mockCtrl.Finish()
+	})
+
+	Context("parse top", func() {
+		It("should do nothing if no message", func() {
+			topPort.EXPECT().PeekIncoming().Return(nil)
+
+			madeProgress := memCtrlMiddleware.parseTop()
+
+			Expect(madeProgress).To(BeFalse())
+		})
+
+		It("should stall if substransaction queue is full", func() {
+			read := mem.ReadReqBuilder{}.
+				WithAddress(0x1000).
+				Build()
+
+			topPort.EXPECT().PeekIncoming().Return(read)
+			addrConverter.EXPECT().ConvertExternalToInternal(uint64(0x1000))
+			subTransSplitter.EXPECT().
+				Split(gomock.Any()).
+				Do(func(t *signal.Transaction) {
+					Expect(t.Read).To(BeIdenticalTo(read))
+					t.SubTransactions = make([]*signal.SubTransaction, 3)
+				})
+			subTransactionQueue.EXPECT().CanPush(3).Return(false)
+
+			madeProgress := memCtrlMiddleware.parseTop()
+
+			Expect(madeProgress).To(BeFalse())
+		})
+
+		It("should push sub-transactions to subtrans queue", func() {
+			read := mem.ReadReqBuilder{}.
+				WithAddress(0x1000).
+				Build()
+
+			topPort.EXPECT().PeekIncoming().Return(read)
+			topPort.EXPECT().RetrieveIncoming().Return(read)
+			addrConverter.EXPECT().ConvertExternalToInternal(uint64(0x1000))
+			subTransSplitter.EXPECT().
+				Split(gomock.Any()).
+				Do(func(t *signal.Transaction) {
+					Expect(t.Read).To(BeIdenticalTo(read))
+					for i := 0; i < 3; i++ {
+						st := &signal.SubTransaction{}
+						t.SubTransactions = append(t.SubTransactions, st)
+					}
+				})
+			subTransactionQueue.EXPECT().CanPush(3).Return(true)
+			subTransactionQueue.EXPECT().Push(gomock.Any())
+
+			madeProgress := memCtrlMiddleware.parseTop()
+
+			Expect(madeProgress).To(BeTrue())
+			Expect(memCtrl.inflightTransactions).To(HaveLen(1))
+		})
+
+		It("should mark the reads that the fault injector selects", func() {
+			memCtrl.faultInjector = func(pAddr uint64) bool {
+				return pAddr == 0x1000
+			}
+			read := mem.ReadReqBuilder{}.
+				WithAddress(0x1000).
+				Build()
+
+			topPort.EXPECT().PeekIncoming().Return(read)
+			topPort.EXPECT().RetrieveIncoming().Return(read)
+			addrConverter.EXPECT().ConvertExternalToInternal(uint64(0x1000))
+			subTransSplitter.EXPECT().Split(gomock.Any())
+			subTransactionQueue.EXPECT().CanPush(0).Return(true)
+			subTransactionQueue.EXPECT().Push(gomock.Any())
+
+			memCtrlMiddleware.parseTop()
+
+			Expect(memCtrl.inflightTransactions[0].Faulty).To(BeTrue())
+		})
+	})
+
+	Context("issue", func() {
+		It("should not issue if nothing is ready", func() {
+			cmdQueue.EXPECT().
+				GetCommandsToIssue().
+				Return(nil)
+
+			madeProgress := memCtrlMiddleware.issue()
+
+			Expect(madeProgress).To(BeFalse())
+		})
+
+		It("should issue", func() {
+			cmd := &signal.Command{}
+			cmdQueue.EXPECT().
+				GetCommandsToIssue().
+				Return([]*signal.Command{cmd})
+			channel.EXPECT().StartCommand(cmd)
+			channel.EXPECT().UpdateTiming(cmd)
+
+			madeProgress := memCtrlMiddleware.issue()
+
+			Expect(madeProgress).To(BeTrue())
+		})
+	})
+
+	Context("refresh", func() {
+		BeforeEach(func() {
+			memCtrl.Engine = sim.NewSerialEngine()
+			memCtrl.refreshInterval = 100
+			memCtrl.refreshCycles = 10
+		})
+
+		It("should not issue during a refresh", func() {
+			memCtrl.inflightTransactions = []*signal.Transaction{{}}
+
+			madeProgress := memCtrlMiddleware.refreshOrIssue()
+
+			Expect(madeProgress).To(BeTrue())
+		})
+
+		It("should not tick during a refresh if there is no transaction",
+			func() {
+				madeProgress := memCtrlMiddleware.refreshOrIssue()
+
+				Expect(madeProgress).To(BeFalse())
+			})
+
+		It("should issue if refresh is disabled", func() {
+			memCtrl.refreshInterval = 0
+			cmd := &signal.Command{}
+			cmdQueue.EXPECT().
+				GetCommandsToIssue().
+				Return([]*signal.Command{cmd})
+			channel.EXPECT().StartCommand(cmd)
+			channel.EXPECT().UpdateTiming(cmd)
+
+			madeProgress := memCtrlMiddleware.refreshOrIssue()
+
+			Expect(madeProgress).To(BeTrue())
+		})
+
+		It("should close the open rows during a refresh", func() {
+			bank := memCtrl.banks[0]
+			activate := &signal.Command{
+				Kind:     signal.CmdKindActivate,
+				SubTrans: &signal.SubTransaction{},
+			}
+			bank.StartCommand(activate)
+			for bank.Tick() {
+			}
+
+			memCtrlMiddleware.refreshOrIssue()
+
+			read := &signal.Command{Kind: signal.CmdKindRead}
+			Expect(bank.GetReadyCommand(read).Kind).
+				To(Equal(signal.CmdKindActivate))
+		})
+	})
+
+	Context("respond", func() {
+		It("should do nothing if there is no transaction", func() {
+			madeProgress := memCtrlMiddleware.respond()
+
+			Expect(madeProgress).To(BeFalse())
+		})
+
+		It("should do nothing if there is no completed transaction",
+			func() {
+				trans := &signal.Transaction{}
+				subTransaction := &signal.SubTransaction{
+					Transaction: trans,
+					Completed:   false,
+				}
+				trans.SubTransactions = append(trans.SubTransactions,
+					subTransaction)
+				memCtrl.inflightTransactions = append(
+					memCtrl.inflightTransactions, trans)
+
+				madeProgress := memCtrlMiddleware.respond()
+
+				Expect(madeProgress).To(BeFalse())
+			})
+
+		It("should send write done response", func() {
+			write := mem.WriteReqBuilder{}.
+				WithAddress(0x40).
+				WithData([]byte{1, 2, 3, 4}).
+				Build()
+			trans := &signal.Transaction{
+				InternalAddress: 0x40,
+				Write:           write,
+			}
+			subTransaction := &signal.SubTransaction{
+				Transaction: trans,
+				Completed:   true,
+			}
+			trans.SubTransactions = append(trans.SubTransactions,
+				subTransaction)
+			memCtrl.inflightTransactions = append(memCtrl.inflightTransactions,
+				trans)
+
+			topPort.EXPECT().Send(gomock.Any()).Return(nil)
+
+			madeProgress := memCtrlMiddleware.respond()
+
+			Expect(madeProgress).To(BeTrue())
+			data, _ := storage.Read(0x40, 4)
+			Expect(data).To(Equal([]byte{1, 2, 3, 4}))
+			Expect(memCtrl.inflightTransactions).NotTo(ContainElement(trans))
+		})
+
+		It("should send data ready response", func() {
+			storage.Write(0x40, []byte{1, 2, 3, 4})
+			read := mem.ReadReqBuilder{}.
+				WithAddress(0x40).
+				WithByteSize(4).
+				Build()
+			trans := &signal.Transaction{
+				InternalAddress: 0x40,
+				Read:            read,
+			}
+			subTransaction := &signal.SubTransaction{
+				Transaction: trans,
+				Completed:   true,
+			}
+			trans.SubTransactions = append(trans.SubTransactions,
+				subTransaction)
+			memCtrl.inflightTransactions = append(memCtrl.inflightTransactions,
+				trans)
+
+			topPort.EXPECT().Send(gomock.Any()).Do(func(dr *mem.DataReadyRsp) {
+				Expect(dr.Data).To(Equal([]byte{1, 2, 3, 4}))
+			}).Return(nil)
+
+			madeProgress := memCtrlMiddleware.respond()
+
+			Expect(madeProgress).To(BeTrue())
+			Expect(memCtrl.inflightTransactions).NotTo(ContainElement(trans))
+		})
+
+		It("should send zeroed data and report a faulty read", func() {
+			var fault error
+			memCtrl.faultHandler = func(f error) { fault = f }
+			storage.Write(0x40, []byte{1, 2, 3, 4})
+			read := mem.ReadReqBuilder{}.
+				WithAddress(0x40).
+				WithByteSize(4).
+				Build()
+			trans := &signal.Transaction{
+				InternalAddress: 0x40,
+				Read:            read,
+				Faulty:          true,
+			}
+			subTransaction := &signal.SubTransaction{
+				Transaction: trans,
+				Completed:   true,
+			}
+			trans.SubTransactions = append(trans.SubTransactions,
+				subTransaction)
+			memCtrl.inflightTransactions = append(memCtrl.inflightTransactions,
+				trans)
+
+			topPort.EXPECT().Send(gomock.Any()).Do(func(dr *mem.DataReadyRsp) {
+				Expect(dr.Data).To(Equal([]byte{0, 0, 0, 0}))
+			}).Return(nil)
+
+			madeProgress := memCtrlMiddleware.respond()
+
+			Expect(madeProgress).To(BeTrue())
+			Expect(fault).To(MatchError(
+				"uncorrectable memory error at physical address 0x40 on MemCtrl"))
+		})
+	})
+})
+
+var _ = Describe("MemController with separate read and write queues", func() {
+	var (
+		mockCtrl *gomock.Controller
+		topPort  *MockPort
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+
+		topPort = NewMockPort(mockCtrl)
+		topPort.EXPECT().AsRemote().Return(sim.RemotePort("TopPort")).AnyTimes()
+	})
+
+	AfterEach(func() {
+		mockCtrl.Finish()
+	})
+
+	build := func(builder Builder) *middleware {
+		memCtrl := builder.Build("MemCtrl")
+		memCtrl.topPort = topPort
+
+		return memCtrl.Middlewares()[0].(*middleware)
+	}
+
+	// parse delivers a request to the memory controller and returns if the
+	// controller accepts it.
+	parse := func(m *middleware, req mem.AccessReq) bool {
+		topPort.EXPECT().PeekIncoming().Return(req)
+		topPort.EXPECT().RetrieveIncoming().Return(req).MaxTimes(1)
+
+		return m.parseTop()
+	}
+
+	fillWithWrites := func(m *middleware) {
+		for i := 0; i < 4; i++ {
+			write := mem.WriteReqBuilder{}.
+				WithAddress(uint64(i * 64)).
+				WithData(make([]byte, 64)).
+				Build()
+			Expect(parse(m, write)).To(BeTrue())
+		}
+
+		write := mem.WriteReqBuilder{}.
+			WithAddress(0x1000).
+			WithData(make([]byte, 64)).
+			Build()
+		Expect(parse(m, write)).To(BeFalse())
+	}
+
+	It("should let writes occupy the slots of reads in a shared queue",
+		func() {
+			m := build(MakeBuilder().WithTransactionQueueSize(4))
+
+			fillWithWrites(m)
+
+			read := mem.ReadReqBuilder{}.
+				WithAddress(0x2000).
+				WithByteSize(64).
+				Build()
+			Expect(parse(m, read)).To(BeFalse())
+		})
+
+	It("should accept reads when the write queue is full", func() {
+		m := build(MakeBuilder().WithReadQueueSize(4).WithWriteQueueSize(4))
+
+		fillWithWrites(m)
+
+		for i := 0; i < 4; i++ {
+			read := mem.ReadReqBuilder{}.
+				WithAddress(uint64(0x2000 + i*64)).
+				WithByteSize(64).
+				Build()
+			Expect(parse(m, read)).To(BeTrue())
+		}
+
+		read := mem.ReadReqBuilder{}.
+			WithAddress(0x3000).
+			WithByteSize(64).
+			Build()
+		Expect(parse(m, read)).To(BeFalse())
+		Expect(m.inflightTransactions).To(HaveLen(8))
+	})
+	// run delivers the requests to a memory controller in order, runs the
+	// simulation, and returns the responses by the IDs of the requests, in
+	// the order in which they are received.
+	run := func(
+		builder Builder,
+		reqs []mem.AccessReq,
+	) (map[string]sim.Msg, []string) {
+		engine := sim.NewSerialEngine()
+		memCtrl := builder.WithEngine(engine).Build("MemCtrl")
+
+		rsps := make(map[string]sim.Msg)
+		var order []string
+
+		srcPort := NewMockPort(mockCtrl)
+		srcPort.EXPECT().PeekOutgoing().Return(nil).AnyTimes()
+		srcPort.EXPECT().AsRemote().
+			Return(sim.RemotePort("SrcPort")).AnyTimes()
+		srcPort.EXPECT().Deliver(gomock.Any()).
+			Do(func(rsp mem.AccessRsp) {
+				rsps[rsp.GetRspTo()] = rsp
+				order = append(order, rsp.GetRspTo())
+			}).
+			Times(len(reqs))
+
+		conn := directconnection.MakeBuilder().
+			WithEngine(engine).
+			WithFreq(1 * sim.GHz).
+			Build("Conn")
+		srcPort.EXPECT().SetConnection(conn)
+		conn.PlugIn(memCtrl.topPort)
+		conn.PlugIn(srcPort)
+
+		for _, req := range reqs {
+			req.Meta().Src = srcPort.AsRemote()
+			req.Meta().Dst = memCtrl.topPort.AsRemote()
+			memCtrl.topPort.Deliver(req)
+		}
+
+		Expect(engine.Run()).To(Succeed())
+
+		return rsps, order
+	}
+
+	It("should not let a read pass a write to the same address", func() {
+		const numWrites = 16
+
+		var reqs []mem.AccessReq
+		for i := 0; i < numWrites; i++ {
+			write := mem.WriteReqBuilder{}.
+				WithAddress(0x1000).
+				WithData([]byte{byte(i), 0, 0, 0}).
+				Build()
+			reqs = append(reqs, write)
+		}
+
+		read := mem.ReadReqBuilder{}.
+			WithAddress(0x1000).
+			WithByteSize(4).
+			Build()
+		reqs = append(reqs, read)
+
+		rsps, _ := run(
+			MakeBuilder().WithReadQueueSize(32).WithWriteQueueSize(32),
+			reqs)
+
+		dataReady := rsps[read.ID].(*mem.DataReadyRsp)
+		Expect(dataReady.Data).To(Equal([]byte{numWrites - 1, 0, 0, 0}))
+	})
+
+	It("should drain the writes under a steady stream of reads", func() {
+		const (
+			numReads  = 256
+			numWrites = 6
+		)
+
+		var reqs []mem.AccessReq
+		for i := 0; i < numReads; i++ {
+			read := mem.ReadReqBuilder{}.
+				WithAddress(uint64(i * 4096)).
+				WithByteSize(64).
+				Build()
+			reqs = append(reqs, read)
+		}
+
+		var writeIDs []string
+		for i := 0; i < numWrites; i++ {
+			write := mem.WriteReqBuilder{}.
+				WithAddress(uint64(0x1000000 + i*4096)).
+				WithData(make([]byte, 64)).
+				Build()
+			reqs = append(reqs, write)
+			writeIDs = append(writeIDs, write.ID)
+		}
+
+		_, order := run(
+			MakeBuilder().
+				WithTransactionQueueSize(512).
+				WithReadQueueSize(512).
+				WithWriteQueueSize(8).
+				WithWriteDrainWatermarks(4, 2),
+			reqs)
+
+		Expect(order[:len(order)-numWrites]).To(ContainElements(writeIDs[:4]))
+	})
+})
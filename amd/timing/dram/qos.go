@@ -0,0 +1,75 @@
+package dram
+
+import "github.com/sarchlab/mgpusim/v4/amd/timing/dram/internal/signal"
+
+// A QoSClass tells the memory controller how urgent the accesses to an address
+// range are.
+type QoSClass int
+
+// A list of all supported QoS classes.
+const (
+	// QoSBandwidth is the class of the accesses that only need throughput. It
+	// is the class of all the addresses by default.
+	QoSBandwidth QoSClass = iota
+
+	// QoSLatencyCritical is the class of the accesses that the requesters are
+	// waiting for. With QoS scheduling, they are served before the accesses of
+	// the QoSBandwidth class.
+	QoSLatencyCritical
+)
+
+// log2QoSPageSize is the log2 of the granularity at which the QoS classes are
+// recorded. Larger pages are recorded as several 4 KB pages.
+const log2QoSPageSize = 12
+
+// SetQoSClass sets the QoS class of the accesses to the address range that
+// starts at addr. The addresses are the addresses of the requests that the
+// memory controller receives, and the class is recorded for every whole 4 KB
+// page that the range touches. A later call overrides the earlier calls for the
+// overlapping pages, and setting QoSBandwidth drops the pages, so that the
+// memory that is freed can be reset. The classes only take effect if the memory
+// controller is built with QoS scheduling. SetQoSClass can be called while the
+// simulation is running.
+func (c *Comp) SetQoSClass(addr, size uint64, class QoSClass) {
+	if size == 0 {
+		return
+	}
+
+	c.qosMutex.Lock()
+	defer c.qosMutex.Unlock()
+
+	if c.qosPages == nil {
+		c.qosPages = make(map[uint64]QoSClass)
+	}
+
+	firstPage := addr >> log2QoSPageSize
+	lastPage := (addr + size - 1) >> log2QoSPageSize
+	for page := firstPage; page <= lastPage; page++ {
+		if class == QoSBandwidth {
+			delete(c.qosPages, page)
+			continue
+		}
+
+		c.qosPages[page] = class
+	}
+}
+
+func (c *Comp) qosClassOf(addr uint64) QoSClass {
+	c.qosMutex.RLock()
+	defer c.qosMutex.RUnlock()
+
+	class, found := c.qosPages[addr>>log2QoSPageSize]
+	if !found {
+		return QoSBandwidth
+	}
+
+	return class
+}
+
+func (m *middleware) assignTransQoSClass(t *signal.Transaction) {
+	if m.latencyCriticalSubTransactionQueue == nil {
+		return
+	}
+
+	t.LatencyCritical = m.qosClassOf(t.GlobalAddress()) == QoSLatencyCritical
+}
@@ -0,0 +1,115 @@
+package dram
+
+import (
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/sarchlab/akita/v4/mem/mem"
+	"github.com/sarchlab/akita/v4/sim"
+	"github.com/sarchlab/akita/v4/sim/directconnection"
+)
+
+var _ = Describe("QoS Scheduling", func() {
+	const (
+		numReqPerStream     = 64
+		latencyCriticalBase = 0x10000000
+	)
+
+	var (
+		mockCtrl *gomock.Controller
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+	})
+
+	AfterEach(func() {
+		mockCtrl.Finish()
+	})
+
+	// readTwoStreams sends a bandwidth stream and a latency-critical stream
+	// of reads at the same time, interleaving the requests of the two streams.
+	// It returns the average latency of the bandwidth stream and the
+	// latency-critical stream.
+	readTwoStreams := func(builder Builder) (sim.VTimeInSec, sim.VTimeInSec) {
+		engine := sim.NewSerialEngine()
+		memCtrl := builder.WithEngine(engine).Build("MemCtrl")
+		memCtrl.SetQoSClass(latencyCriticalBase, numReqPerStream*4096,
+			QoSLatencyCritical)
+
+		isLatencyCritical := make(map[string]bool)
+		var bandwidthLatency, latencyCriticalLatency sim.VTimeInSec
+
+		srcPort := NewMockPort(mockCtrl)
+		srcPort.EXPECT().PeekOutgoing().Return(nil).AnyTimes()
+		srcPort.EXPECT().AsRemote().
+			Return(sim.RemotePort("SrcPort")).AnyTimes()
+		srcPort.EXPECT().Deliver(gomock.Any()).
+			Do(func(rsp *mem.DataReadyRsp) {
+				if isLatencyCritical[rsp.RespondTo] {
+					latencyCriticalLatency += engine.CurrentTime()
+				} else {
+					bandwidthLatency += engine.CurrentTime()
+				}
+			}).
+			Times(2 * numReqPerStream)
+
+		conn := directconnection.MakeBuilder().
+			WithEngine(engine).
+			WithFreq(1 * sim.GHz).
+			Build("Conn")
+		srcPort.EXPECT().SetConnection(conn)
+		conn.PlugIn(memCtrl.topPort)
+		conn.PlugIn(srcPort)
+
+		// Each request accesses a different row, so that the requests
+		// contend for the banks.
+		for i := 0; i < numReqPerStream; i++ {
+			for _, base := range []uint64{0, latencyCriticalBase} {
+				read := mem.ReadReqBuilder{}.
+					WithAddress(base + uint64(i)*4096).
+					WithByteSize(64).
+					WithSrc(srcPort.AsRemote()).
+					WithDst(memCtrl.topPort.AsRemote()).
+					Build()
+				isLatencyCritical[read.ID] = base == latencyCriticalBase
+				memCtrl.topPort.Deliver(read)
+			}
+		}
+
+		Expect(engine.Run()).To(Succeed())
+
+		return bandwidthLatency / numReqPerStream,
+			latencyCriticalLatency / numReqPerStream
+	}
+
+	It("should ignore the QoS classes without QoS scheduling", func() {
+		bandwidth, latencyCritical := readTwoStreams(MakeBuilder())
+
+		Expect(latencyCritical).To(BeNumerically(">", 0.8*bandwidth))
+	})
+
+	It("should serve the latency-critical stream first", func() {
+		bandwidth, latencyCritical := readTwoStreams(
+			MakeBuilder().WithQoSScheduling())
+
+		Expect(latencyCritical).To(BeNumerically("<", 0.8*bandwidth))
+	})
+	It("should record the classes per page and drop the reset pages", func() {
+		memCtrl := MakeBuilder().
+			WithEngine(sim.NewSerialEngine()).
+			WithQoSScheduling().
+			Build("MemCtrl")
+
+		memCtrl.SetQoSClass(0x3000, 0x2000, QoSLatencyCritical)
+		Expect(memCtrl.qosClassOf(0x2fff)).To(Equal(QoSBandwidth))
+		Expect(memCtrl.qosClassOf(0x3000)).To(Equal(QoSLatencyCritical))
+		Expect(memCtrl.qosClassOf(0x4fff)).To(Equal(QoSLatencyCritical))
+		Expect(memCtrl.qosClassOf(0x5000)).To(Equal(QoSBandwidth))
+
+		memCtrl.SetQoSClass(0x3000, 0x1000, QoSBandwidth)
+		Expect(memCtrl.qosClassOf(0x3000)).To(Equal(QoSBandwidth))
+		Expect(memCtrl.qosClassOf(0x4000)).To(Equal(QoSLatencyCritical))
+		Expect(memCtrl.qosPages).To(HaveLen(1))
+	})
+})
@@ -0,0 +1,75 @@
+package dram
+
+import (
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/sarchlab/akita/v4/mem/mem"
+	"github.com/sarchlab/akita/v4/sim"
+	"github.com/sarchlab/akita/v4/sim/directconnection"
+)
+
+var _ = Describe("Row Buffer Stats", func() {
+	var (
+		mockCtrl *gomock.Controller
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+	})
+
+	AfterEach(func() {
+		mockCtrl.Finish()
+	})
+
+	// readTwice reads two addresses in the same row and returns the sum of the
+	// row buffer stats of all the banks.
+	readTwice := func(builder Builder) RowBufferStats {
+		engine := sim.NewSerialEngine()
+		memCtrl := builder.WithEngine(engine).Build("MemCtrl")
+
+		srcPort := NewMockPort(mockCtrl)
+		srcPort.EXPECT().PeekOutgoing().Return(nil).AnyTimes()
+		srcPort.EXPECT().AsRemote().
+			Return(sim.RemotePort("SrcPort")).AnyTimes()
+		srcPort.EXPECT().Deliver(gomock.Any()).Times(2)
+
+		conn := directconnection.MakeBuilder().
+			WithEngine(engine).
+			WithFreq(1 * sim.GHz).
+			Build("Conn")
+		srcPort.EXPECT().SetConnection(conn)
+		conn.PlugIn(memCtrl.topPort)
+		conn.PlugIn(srcPort)
+
+		for _, addr := range []uint64{0x0, 0x40} {
+			read := mem.ReadReqBuilder{}.
+				WithAddress(addr).
+				WithByteSize(4).
+				WithSrc(srcPort.AsRemote()).
+				WithDst(memCtrl.topPort.AsRemote()).
+				Build()
+			memCtrl.topPort.Deliver(read)
+		}
+
+		Expect(engine.Run()).To(Succeed())
+
+		total := RowBufferStats{}
+		for _, s := range memCtrl.RowBufferStats() {
+			total.Hits += s.Hits
+			total.Misses += s.Misses
+			total.Conflicts += s.Conflicts
+		}
+
+		return total
+	}
+
+	It("should count all accesses as misses with the close-page policy", func() {
+		Expect(readTwice(MakeBuilder())).To(Equal(RowBufferStats{Misses: 2}))
+	})
+
+	It("should hit in the open row with the open-page policy", func() {
+		Expect(readTwice(MakeBuilder().WithOpenPagePolicy())).
+			To(Equal(RowBufferStats{Hits: 1, Misses: 1}))
+	})
+})
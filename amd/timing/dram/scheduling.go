@@ -0,0 +1,18 @@
+package dram
+
+// A SchedulingPolicy decides the order in which the memory controller issues
+// the commands that are buffered in a command queue.
+type SchedulingPolicy int
+
+// A list of all supported scheduling policies.
+const (
+	// SchedulingFRFCFS issues the commands that hit in the open row of a bank
+	// first, and otherwise the oldest command that is ready. It is the
+	// default policy.
+	SchedulingFRFCFS SchedulingPolicy = iota
+
+	// SchedulingFCFS issues the commands strictly in the order that they
+	// arrive. A command waits for all the older commands of the same rank,
+	// even if they access other banks.
+	SchedulingFCFS
+)
@@ -0,0 +1,94 @@
+package dram
+
+import (
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/sarchlab/akita/v4/mem/mem"
+	"github.com/sarchlab/akita/v4/sim"
+	"github.com/sarchlab/akita/v4/sim/directconnection"
+)
+
+var _ = Describe("Scheduling Policy", func() {
+	const (
+		numReqPerStream = 64
+		rowStride       = 0x20000
+	)
+
+	var (
+		mockCtrl *gomock.Controller
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+	})
+
+	AfterEach(func() {
+		mockCtrl.Finish()
+	})
+
+	// readMixedStreams interleaves a stream that reads one row of a bank
+	// sequentially with a stream that reads a different row of the same bank
+	// each time. It returns the row buffer hit rate and the time that the
+	// memory controller takes to serve all the reads.
+	readMixedStreams := func(builder Builder) (float64, sim.VTimeInSec) {
+		engine := sim.NewSerialEngine()
+		memCtrl := builder.
+			WithEngine(engine).
+			WithOpenPagePolicy().
+			Build("MemCtrl")
+
+		srcPort := NewMockPort(mockCtrl)
+		srcPort.EXPECT().PeekOutgoing().Return(nil).AnyTimes()
+		srcPort.EXPECT().AsRemote().
+			Return(sim.RemotePort("SrcPort")).AnyTimes()
+		srcPort.EXPECT().Deliver(gomock.Any()).Times(2 * numReqPerStream)
+
+		conn := directconnection.MakeBuilder().
+			WithEngine(engine).
+			WithFreq(1 * sim.GHz).
+			Build("Conn")
+		srcPort.EXPECT().SetConnection(conn)
+		conn.PlugIn(memCtrl.topPort)
+		conn.PlugIn(srcPort)
+
+		for i := 0; i < numReqPerStream; i++ {
+			sequential := uint64(i) * 64
+			scattered := uint64(i+1) * rowStride
+
+			for _, addr := range []uint64{sequential, scattered} {
+				read := mem.ReadReqBuilder{}.
+					WithAddress(addr).
+					WithByteSize(64).
+					WithSrc(srcPort.AsRemote()).
+					WithDst(memCtrl.topPort.AsRemote()).
+					Build()
+				memCtrl.topPort.Deliver(read)
+			}
+		}
+
+		Expect(engine.Run()).To(Succeed())
+
+		total := RowBufferStats{}
+		for _, s := range memCtrl.RowBufferStats() {
+			total.Hits += s.Hits
+			total.Misses += s.Misses
+			total.Conflicts += s.Conflicts
+		}
+
+		hitRate := float64(total.Hits) /
+			float64(total.Hits+total.Misses+total.Conflicts)
+
+		return hitRate, engine.CurrentTime()
+	}
+
+	It("should hit more in the row buffer with FR-FCFS than with FCFS", func() {
+		frfcfsHitRate, frfcfsTime := readMixedStreams(
+			MakeBuilder().WithSchedulingPolicy(SchedulingFRFCFS))
+		fcfsHitRate, fcfsTime := readMixedStreams(
+			MakeBuilder().WithSchedulingPolicy(SchedulingFCFS))
+
+		Expect(frfcfsHitRate).To(BeNumerically(">", fcfsHitRate))
+		Expect(frfcfsTime).To(BeNumerically("<", fcfsTime))
+	})
+})
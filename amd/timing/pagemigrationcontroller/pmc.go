@@ -407,7 +407,7 @@ func (e *PageMigrationController) sendMigrationCompleteRspToCtrlPort() bool {
 
 // SetFreq sets freq
 func (e *PageMigrationController) SetFreq(freq sim.Freq) {
-	panic("not implemented")
+	e.TickingComponent.Freq = freq
 }
 
 // NewPageMigrationController returns a new controller
@@ -340,4 +340,9 @@ var _ = Describe("PMC", func() {
 			Expect(pmc.isHandlingPageMigration).To(BeFalse())
 		})
 	})
+	It("should tick at the given frequency", func() {
+		pmc.SetFreq(1500 * sim.MHz)
+
+		Expect(pmc.Freq).To(Equal(1500 * sim.MHz))
+	})
 })
@@ -340,6 +340,7 @@ func (c *Comp) cloneReq(origin mem.AccessReq) mem.AccessReq {
 			WithDst(origin.Dst).
 			WithAddress(origin.Address).
 			WithByteSize(origin.AccessByteSize).
+			WithInfo(origin.Info).
 			Build()
 		return read
 	case *mem.WriteReq:
@@ -0,0 +1,148 @@
+// Package replacement provides the policies that select the block that a
+// cache evicts when a set is full.
+//
+// The policies are victim finders that can be given to the caches that accept
+// a cache.VictimFinder, such as the writeback caches.
+package replacement
+
+import (
+	"math/rand"
+
+	"github.com/sarchlab/akita/v4/mem/cache"
+)
+
+// Policy determines which block is evicted when a set is full.
+type Policy int
+
+// A list of all the supported replacement policies.
+const (
+	// LRU evicts the least recently used block.
+	LRU Policy = iota
+
+	// FIFO evicts the block that has been in the cache for the longest time,
+	// regardless of how recently it is accessed.
+	FIFO
+
+	// Random evicts a random block.
+	Random
+)
+
+// NewVictimFinder creates a victim finder that implements the policy. Each
+// cache needs its own victim finder, as the victim finders keep track of the
+// blocks of the cache. The FIFO victim finder has a BlockFilled method, which
+// the caches call when they fill a block with a new cache line.
+func NewVictimFinder(policy Policy) cache.VictimFinder {
+	switch policy {
+	case LRU:
+		return cache.NewLRUVictimFinder()
+	case FIFO:
+		return newFIFOVictimFinder()
+	case Random:
+		return newRandomVictimFinder(1)
+	}
+
+	panic("unknown replacement policy")
+}
+
+// findEmptyBlock returns a block that does not hold any data, or nil if all
+// the blocks are in use.
+func findEmptyBlock(set *cache.Set) *cache.Block {
+	for _, block := range set.Blocks {
+		if !block.IsValid && !block.IsLocked {
+			return block
+		}
+	}
+
+	return nil
+}
+
+// fifoVictimFinder evicts the block that is filled the earliest. The caches
+// report the fills by calling BlockFilled. The blocks that are filled without
+// being reported, such as by a cache that does not report the fills, are
+// ordered when the finder first sees them.
+type fifoVictimFinder struct {
+	nextOrder uint64
+	fillOrder map[*cache.Block]uint64
+}
+
+func newFIFOVictimFinder() *fifoVictimFinder {
+	return &fifoVictimFinder{
+		fillOrder: make(map[*cache.Block]uint64),
+	}
+}
+
+// BlockFilled records that the block is filled with a new cache line.
+func (f *fifoVictimFinder) BlockFilled(block *cache.Block) {
+	f.fillOrder[block] = f.nextOrder
+	f.nextOrder++
+}
+
+// FindVictim returns the block that is filled the earliest in a set.
+func (f *fifoVictimFinder) FindVictim(set *cache.Set) *cache.Block {
+	f.recordUnreportedFills(set)
+
+	if block := findEmptyBlock(set); block != nil {
+		return block
+	}
+
+	var victim *cache.Block
+	for _, block := range set.Blocks {
+		if block.IsLocked {
+			continue
+		}
+
+		if victim == nil || f.fillOrder[block] < f.fillOrder[victim] {
+			victim = block
+		}
+	}
+
+	if victim == nil {
+		return set.Blocks[0]
+	}
+
+	return victim
+}
+
+func (f *fifoVictimFinder) recordUnreportedFills(set *cache.Set) {
+	for _, block := range set.Blocks {
+		if !block.IsValid {
+			continue
+		}
+
+		if _, found := f.fillOrder[block]; !found {
+			f.BlockFilled(block)
+		}
+	}
+}
+
+// randomVictimFinder evicts a random block. It uses its own random number
+// generator so that the simulation is deterministic.
+type randomVictimFinder struct {
+	rand *rand.Rand
+}
+
+func newRandomVictimFinder(seed int64) *randomVictimFinder {
+	return &randomVictimFinder{
+		rand: rand.New(rand.NewSource(seed)),
+	}
+}
+
+// FindVictim returns a random unlocked block in a set.
+func (f *randomVictimFinder) FindVictim(set *cache.Set) *cache.Block {
+	if block := findEmptyBlock(set); block != nil {
+		return block
+	}
+
+	candidates := make([]*cache.Block, 0, len(set.Blocks))
+	for _, block := range set.Blocks {
+		if !block.IsLocked {
+			candidates = append(candidates, block)
+		}
+	}
+
+	if len(candidates) == 0 {
+		return set.Blocks[0]
+	}
+
+	return candidates[f.rand.Intn(len(candidates))]
+}
@@ -0,0 +1,13 @@
+package replacement
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestReplacement(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Replacement Suite")
+}
@@ -0,0 +1,129 @@
+package replacement
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/sarchlab/akita/v4/mem/cache"
+)
+
+var _ = Describe("Replacement Policy", func() {
+	const (
+		a uint64 = 0x0000
+		b uint64 = 0x1000
+		c uint64 = 0x2000
+		d uint64 = 0x3000
+		e uint64 = 0x4000
+	)
+
+	// runAccesses accesses the addresses in a 4-way directory with a single
+	// set, filling a block on each miss, and returns the directory.
+	runAccesses := func(policy Policy, addrs []uint64) cache.Directory {
+		victimFinder := NewVictimFinder(policy)
+		directory := cache.NewDirectory(1, 4, 64, victimFinder)
+
+		for _, addr := range addrs {
+			block := directory.Lookup(0, addr)
+			if block == nil || !block.IsValid {
+				block = directory.FindVictim(addr)
+				block.Tag = addr
+				block.IsValid = true
+
+				if fifo, ok := victimFinder.(*fifoVictimFinder); ok {
+					fifo.BlockFilled(block)
+				}
+			}
+
+			directory.Visit(block)
+		}
+
+		return directory
+	}
+
+	isCached := func(directory cache.Directory, addr uint64) bool {
+		block := directory.Lookup(0, addr)
+		return block != nil && block.IsValid
+	}
+
+	It("should evict the least recently used block with LRU", func() {
+		directory := runAccesses(LRU, []uint64{a, b, c, d, a, e})
+
+		Expect(isCached(directory, a)).To(BeTrue())
+		Expect(isCached(directory, b)).To(BeFalse())
+		Expect(isCached(directory, c)).To(BeTrue())
+		Expect(isCached(directory, d)).To(BeTrue())
+		Expect(isCached(directory, e)).To(BeTrue())
+	})
+
+	It("should evict the earliest filled block with FIFO", func() {
+		directory := runAccesses(FIFO, []uint64{a, b, c, d, a, e})
+
+		Expect(isCached(directory, a)).To(BeFalse())
+		Expect(isCached(directory, b)).To(BeTrue())
+		Expect(isCached(directory, c)).To(BeTrue())
+		Expect(isCached(directory, d)).To(BeTrue())
+		Expect(isCached(directory, e)).To(BeTrue())
+	})
+
+	It("should evict a random block with random replacement", func() {
+		blocks := []uint64{a, b, c, d}
+		addrs := []uint64{a, b, c, d}
+		for i := uint64(0); i < 16; i++ {
+			blocks = append(blocks, e+i*0x1000)
+			addrs = append(addrs, a, e+i*0x1000)
+		}
+
+		lru := runAccesses(LRU, addrs)
+		fifo := runAccesses(FIFO, addrs)
+		random := runAccesses(Random, addrs)
+
+		cachedBlocks := func(directory cache.Directory) []uint64 {
+			cached := []uint64{}
+			for _, addr := range blocks {
+				if isCached(directory, addr) {
+					cached = append(cached, addr)
+				}
+			}
+			return cached
+		}
+
+		Expect(isCached(lru, a)).To(BeTrue())
+		Expect(cachedBlocks(random)).NotTo(Equal(cachedBlocks(lru)))
+		Expect(cachedBlocks(random)).NotTo(Equal(cachedBlocks(fifo)))
+	})
+
+	It("should order a block refilled with the same cache line as a new fill",
+		func() {
+			victimFinder := newFIFOVictimFinder()
+			set := &cache.Set{}
+			for i := 0; i < 4; i++ {
+				block := &cache.Block{
+					WayID:   i,
+					Tag:     uint64(i) * 0x1000,
+					IsValid: true,
+				}
+				set.Blocks = append(set.Blocks, block)
+				victimFinder.BlockFilled(block)
+			}
+
+			victimFinder.BlockFilled(set.Blocks[0])
+
+			Expect(victimFinder.FindVictim(set)).
+				To(BeIdenticalTo(set.Blocks[1]))
+		})
+
+	It("should not evict a locked block", func() {
+		victimFinder := NewVictimFinder(FIFO)
+		set := &cache.Set{}
+		for i := 0; i < 4; i++ {
+			set.Blocks = append(set.Blocks, &cache.Block{
+				WayID:   i,
+				Tag:     uint64(i) * 0x1000,
+				IsValid: true,
+			})
+		}
+		victimFinder.FindVictim(set)
+		set.Blocks[0].IsLocked = true
+
+		Expect(victimFinder.FindVictim(set)).To(BeIdenticalTo(set.Blocks[1]))
+	})
+})
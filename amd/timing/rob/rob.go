@@ -247,6 +247,7 @@ func (b *ReorderBuffer) duplicateReadReq(req *mem.ReadReq) *mem.ReadReq {
 		WithAddress(req.Address).
 		WithByteSize(req.AccessByteSize).
 		WithPID(req.PID).
+		WithInfo(req.Info).
 		WithDst(b.BottomUnit.AsRemote()).
 		Build()
 }
@@ -257,6 +258,7 @@ func (b *ReorderBuffer) duplicateWriteReq(req *mem.WriteReq) *mem.WriteReq {
 		WithPID(req.PID).
 		WithData(req.Data).
 		WithDirtyMask(req.DirtyMask).
+		WithInfo(req.Info).
 		WithDst(b.BottomUnit.AsRemote()).
 		Build()
 }
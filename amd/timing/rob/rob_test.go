@@ -101,6 +101,23 @@ var _ = Describe("Reorder Buffer", func() {
 			Expect(rob.transactions.Len()).To(Equal(1))
 			Expect(rob.toBottomReqIDToTransactionTable).To(HaveLen(1))
 		})
+
+		It("should keep the info of a write request", func() {
+			info := &struct{}{}
+			write := mem.WriteReqBuilder{}.WithInfo(info).Build()
+			topPort.EXPECT().PeekIncoming().Return(write)
+			topPort.EXPECT().RetrieveIncoming()
+			bottomPort.EXPECT().
+				Send(gomock.Any()).
+				Do(func(req *mem.WriteReq) {
+					Expect(req.Info).To(BeIdenticalTo(info))
+				}).
+				Return(nil)
+
+			madeProgress := rob.topDown()
+
+			Expect(madeProgress).To(BeTrue())
+		})
 	})
 
 	Context("parse bottom", func() {
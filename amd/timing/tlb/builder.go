@@ -0,0 +1,164 @@
+package tlb
+
+import (
+	"github.com/sarchlab/akita/v4/mem/vm"
+	"github.com/sarchlab/akita/v4/sim"
+)
+
+// A Builder can build TLBs
+type Builder struct {
+	engine         sim.Engine
+	freq           sim.Freq
+	numReqPerCycle int
+	numSets        int
+	numWays        int
+	pageSize       uint64
+	hugePageSize   uint64
+	lowModule      sim.RemotePort
+	numMSHREntry   int
+	state          string
+	latency        int
+
+	shootdownLatency int
+	idealPageTable   vm.PageTable
+}
+
+// MakeBuilder returns a Builder
+func MakeBuilder() Builder {
+	return Builder{
+		freq:           1 * sim.GHz,
+		numReqPerCycle: 4,
+		numSets:        1,
+		numWays:        32,
+		pageSize:       4096,
+		numMSHREntry:   4,
+		state:          "enable",
+		latency:        4,
+	}
+}
+
+// WithEngine sets the engine that the TLBs to use
+func (b Builder) WithEngine(engine sim.Engine) Builder {
+	b.engine = engine
+	return b
+}
+
+// WithFreq sets the freq the TLBs use
+func (b Builder) WithFreq(freq sim.Freq) Builder {
+	b.freq = freq
+	return b
+}
+
+// WithNumSets sets the number of sets in a TLB. Use 1 for fully associated
+// TLBs.
+func (b Builder) WithNumSets(n int) Builder {
+	b.numSets = n
+	return b
+}
+
+// WithNumWays sets the number of ways in a TLB. Set this field to the number
+// of TLB entries for all the functions.
+func (b Builder) WithNumWays(n int) Builder {
+	b.numWays = n
+	return b
+}
+
+// WithPageSize sets the page size that the TLB works with.
+func (b Builder) WithPageSize(n uint64) Builder {
+	b.pageSize = n
+	return b
+}
+
+// WithHugePageSize sets the size of the huge pages that the TLB can hold in
+// addition to the base pages. Use 0 to disable huge pages.
+func (b Builder) WithHugePageSize(n uint64) Builder {
+	b.hugePageSize = n
+	return b
+}
+
+// WithNumReqPerCycle sets the number of requests per cycle can be processed by
+// a TLB
+func (b Builder) WithNumReqPerCycle(n int) Builder {
+	b.numReqPerCycle = n
+	return b
+}
+
+// WithLowModule sets the port that can provide the address translation in case
+// of tlb miss.
+func (b Builder) WithLowModule(lowModule sim.RemotePort) Builder {
+	b.lowModule = lowModule
+	return b
+}
+
+// WithNumMSHREntry sets the number of mshr entry
+func (b Builder) WithNumMSHREntry(num int) Builder {
+	b.numMSHREntry = num
+	return b
+}
+
+func (b Builder) WithLatency(cycles int) Builder {
+	b.latency = cycles
+	return b
+}
+
+// WithShootdownLatency sets the number of cycles that the TLB takes to
+// invalidate the entries of a shootdown before it responds to the flush
+// request.
+func (b Builder) WithShootdownLatency(cycles int) Builder {
+	b.shootdownLatency = cycles
+	return b
+}
+
+// WithIdealTranslation makes the TLB translate every request by looking up the
+// page table directly. The TLB then never misses, responds in the cycle that
+// it receives a request, and never sends requests to the low module. As the
+// MMU is not involved, the accesses never trigger page migrations.
+func (b Builder) WithIdealTranslation(pageTable vm.PageTable) Builder {
+	b.idealPageTable = pageTable
+	return b
+}
+
+// Build creates a new TLB
+func (b Builder) Build(name string) *Comp {
+	tlb := &Comp{}
+	tlb.TickingComponent =
+		sim.NewTickingComponent(name, b.engine, b.freq, tlb)
+
+	tlb.numSets = b.numSets
+	tlb.numWays = b.numWays
+	tlb.numReqPerCycle = b.numReqPerCycle
+	tlb.pageSize = b.pageSize
+	tlb.hugePageSize = b.hugePageSize
+	tlb.LowModule = b.lowModule
+	tlb.mshr = newMSHR(b.numMSHREntry)
+	tlb.shootdownLatency = b.shootdownLatency
+	tlb.idealPageTable = b.idealPageTable
+
+	b.createPorts(name, tlb)
+
+	tlb.reset()
+
+	ctrlMiddleware := &ctrlMiddleware{Comp: tlb}
+	tlb.AddMiddleware(ctrlMiddleware)
+
+	middleware := &tlbMiddleware{Comp: tlb}
+	tlb.AddMiddleware(middleware)
+
+	return tlb
+}
+
+func (b Builder) createPorts(name string, c *Comp) {
+	c.topPort = sim.NewPort(c,
+		b.numReqPerCycle, b.numReqPerCycle,
+		name+".TopPort")
+	c.AddPort("Top", c.topPort)
+
+	c.bottomPort = sim.NewPort(c,
+		b.numReqPerCycle, b.numReqPerCycle,
+		name+".BottomPort")
+	c.AddPort("Bottom", c.bottomPort)
+
+	c.controlPort = sim.NewPort(c, 1, 1,
+		name+".ControlPort")
+	c.AddPort("Control", c.controlPort)
+}
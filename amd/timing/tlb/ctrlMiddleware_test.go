@@ -0,0 +1,64 @@
+package tlb
+
+import (
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/sarchlab/mgpusim/v4/amd/timing/tlb/internal"
+)
+
+var _ = Describe("TLB", func() {
+
+	var (
+		mockCtrl    *gomock.Controller
+		engine      *MockEngine
+		comp        *Comp
+		tlbMW       *tlbMiddleware
+		ctrlMW      *ctrlMiddleware
+		set         *MockSet
+		topPort     *MockPort
+		bottomPort  *MockPort
+		controlPort *MockPort
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		engine = NewMockEngine(mockCtrl)
+		set = NewMockSet(mockCtrl)
+		topPort = NewMockPort(mockCtrl)
+		bottomPort = NewMockPort(mockCtrl)
+		controlPort = NewMockPort(mockCtrl)
+
+		comp = MakeBuilder().WithEngine(engine).Build("TLB")
+		comp.topPort = topPort
+		comp.bottomPort = bottomPort
+		comp.controlPort = controlPort
+		comp.Sets = []internal.Set{set}
+
+		ctrlMW = comp.Middlewares()[0].(*ctrlMiddleware)
+		tlbMW = comp.Middlewares()[1].(*tlbMiddleware)
+	})
+
+	AfterEach(func() {
+		mockCtrl.Finish()
+	})
+
+	It("should do nothing if there is no req in TopPort", func() {
+		topPort.EXPECT().PeekIncoming().Return(nil)
+
+		madeProgress := tlbMW.lookup()
+
+		Expect(madeProgress).To(BeFalse())
+	})
+
+	It("should do nothing if there is no req in ctrlPort", func() {
+		topPort.EXPECT().PeekIncoming().Return(nil)
+		controlPort.EXPECT().PeekIncoming().Return(nil)
+
+		madeProgress := tlbMW.lookup()
+		madeProgress = ctrlMW.Tick() || madeProgress
+
+		Expect(madeProgress).To(BeFalse())
+	})
+
+})
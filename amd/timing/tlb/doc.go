@@ -0,0 +1,5 @@
+// Package tlb provides a TLB component implementation.
+//
+// The package is derived from the TLB in Akita. It additionally supports huge
+// pages, so that the entries of different page sizes can coexist in a TLB.
+package tlb
@@ -0,0 +1,545 @@
+package tlb
+
+import (
+	"log"
+	"reflect"
+
+	"github.com/sarchlab/akita/v4/mem/mem"
+	"github.com/sarchlab/akita/v4/mem/vm"
+	"github.com/sarchlab/akita/v4/sim"
+	"github.com/sarchlab/akita/v4/tracing"
+	"github.com/sarchlab/mgpusim/v4/amd/timing/tlb/internal"
+)
+
+// Comp is a cache(TLB) that maintains some page information.
+type Comp struct {
+	*sim.TickingComponent
+	sim.MiddlewareHolder
+
+	topPort     sim.Port
+	bottomPort  sim.Port
+	controlPort sim.Port
+
+	LowModule sim.RemotePort
+
+	numSets        int
+	numWays        int
+	pageSize       uint64
+	hugePageSize   uint64
+	numReqPerCycle int
+	state          string
+
+	Sets []internal.Set
+
+	mshr                mshr
+	respondingMSHREntry *mshrEntry
+
+	isPaused bool
+
+	shootdownLatency    int
+	shootdownCyclesLeft int
+	pendingFlushRsp     *FlushRsp
+
+	idealPageTable vm.PageTable
+}
+
+// Reset sets all the entries in the TLB to be invalid
+func (c *Comp) reset() {
+	c.Sets = make([]internal.Set, c.numSets)
+	for i := 0; i < c.numSets; i++ {
+		set := internal.NewSet(c.numWays)
+		c.Sets[i] = set
+	}
+}
+
+func (c *Comp) Tick() bool {
+	return c.MiddlewareHolder.Tick()
+}
+
+type tlbMiddleware struct {
+	*Comp
+}
+
+func (m *tlbMiddleware) Tick() bool {
+	madeProgress := m.respondFlush()
+
+	if m.pendingFlushRsp == nil {
+		madeProgress = m.performCtrlReq() || madeProgress
+	}
+
+	switch m.state {
+	case "drain":
+		madeProgress = m.handleDrain() || madeProgress
+
+	case "pause":
+		// No action
+
+	default: // When state is enable or in initial state
+		madeProgress = m.handleEnable() || madeProgress
+	}
+
+	return madeProgress
+}
+
+// Handle enable state
+func (m *tlbMiddleware) handleEnable() bool {
+	madeProgress := false
+	for i := 0; i < m.numReqPerCycle; i++ {
+		madeProgress = m.respondMSHREntry() || madeProgress
+	}
+	for i := 0; i < m.numReqPerCycle; i++ {
+		madeProgress = m.lookup() || madeProgress
+	}
+	madeProgress = m.refetchSplitMSHREntries() || madeProgress
+	for i := 0; i < m.numReqPerCycle; i++ {
+		madeProgress = m.parseBottom() || madeProgress
+	}
+	return madeProgress
+}
+
+// Handle drain state
+func (m *tlbMiddleware) handleDrain() bool {
+	madeProgress := false
+	for i := 0; i < m.numReqPerCycle; i++ {
+		madeProgress = m.respondMSHREntry() || madeProgress
+	}
+	madeProgress = m.refetchSplitMSHREntries() || madeProgress
+	for i := 0; i < m.numReqPerCycle; i++ {
+		madeProgress = m.parseBottom() || madeProgress
+	}
+
+	if m.mshr.IsEmpty() && m.bottomPort.PeekIncoming() == nil {
+		m.state = "pause"
+	}
+
+	return madeProgress
+}
+
+func (m *tlbMiddleware) respondMSHREntry() bool {
+	if m.respondingMSHREntry == nil {
+		return false
+	}
+
+	mshrEntry := m.respondingMSHREntry
+	page := mshrEntry.page
+	req := mshrEntry.Requests[0]
+	rspToTop := vm.TranslationRspBuilder{}.
+		WithSrc(m.topPort.AsRemote()).
+		WithDst(req.Src).
+		WithRspTo(req.ID).
+		WithPage(page).
+		Build()
+
+	err := m.topPort.Send(rspToTop)
+	if err != nil {
+		return false
+	}
+
+	mshrEntry.Requests = mshrEntry.Requests[1:]
+	if len(mshrEntry.Requests) == 0 {
+		m.respondingMSHREntry = nil
+	}
+
+	tracing.TraceReqComplete(req, m.Comp)
+
+	return true
+}
+
+func (m *tlbMiddleware) lookup() bool {
+	msg := m.topPort.PeekIncoming()
+	if msg == nil {
+		return false
+	}
+
+	req := msg.(*vm.TranslationReq)
+
+	if m.idealPageTable != nil {
+		return m.lookupIdeal(req)
+	}
+
+	mshrEntry := m.mshr.Query(req.PID, req.VAddr)
+	if mshrEntry == nil && m.hugePageSize != 0 {
+		mshrEntry = m.mshr.QueryHugePageFrame(
+			req.PID, req.VAddr, m.hugePageSize)
+	}
+
+	if mshrEntry != nil {
+		return m.processTLBMSHRHit(mshrEntry, req)
+	}
+
+	setID, wayID, page, found := m.lookupPage(req.PID, req.VAddr)
+	if found && page.Valid {
+		return m.handleTranslationHit(req, setID, wayID, page)
+	}
+
+	return m.handleTranslationMiss(req)
+}
+
+// lookupPage searches for the page that covers the virtual address, trying the
+// base page first and then the huge page. The virtual address is already
+// aligned to the base page size.
+func (m *tlbMiddleware) lookupPage(
+	pid vm.PID,
+	vAddr uint64,
+) (setID, wayID int, page vm.Page, found bool) {
+	setID = m.vAddrToSetID(vAddr, m.pageSize)
+	wayID, page, found = m.Sets[setID].Lookup(pid, vAddr)
+
+	if (found && page.Valid) || m.hugePageSize == 0 {
+		return setID, wayID, page, found
+	}
+
+	hugePageVAddr := vAddr / m.hugePageSize * m.hugePageSize
+	setID = m.vAddrToSetID(hugePageVAddr, m.hugePageSize)
+	wayID, page, found = m.Sets[setID].Lookup(pid, hugePageVAddr)
+
+	return setID, wayID, page, found
+}
+
+func (m *tlbMiddleware) handleTranslationHit(
+	req *vm.TranslationReq,
+	setID, wayID int,
+	page vm.Page,
+) bool {
+	ok := m.sendRspToTop(req, page)
+	if !ok {
+		return false
+	}
+
+	m.visit(setID, wayID)
+	m.topPort.RetrieveIncoming()
+
+	tracing.TraceReqReceive(req, m.Comp)
+	tracing.AddTaskStep(tracing.MsgIDAtReceiver(req, m.Comp), m.Comp, "hit")
+	tracing.TraceReqComplete(req, m.Comp)
+
+	return true
+}
+
+// lookupIdeal responds with the page in the page table, as if every page were
+// in the TLB.
+func (m *tlbMiddleware) lookupIdeal(req *vm.TranslationReq) bool {
+	page, found := m.idealPageTable.Find(req.PID, req.VAddr)
+	if !found {
+		log.Panicf("address 0x%x of process %d is not mapped",
+			req.VAddr, req.PID)
+	}
+
+	ok := m.sendRspToTop(req, page)
+	if !ok {
+		return false
+	}
+
+	m.topPort.RetrieveIncoming()
+
+	tracing.TraceReqReceive(req, m.Comp)
+	tracing.AddTaskStep(tracing.MsgIDAtReceiver(req, m.Comp), m.Comp, "hit")
+	tracing.TraceReqComplete(req, m.Comp)
+
+	return true
+}
+
+func (m *tlbMiddleware) handleTranslationMiss(
+	req *vm.TranslationReq,
+) bool {
+	if m.mshr.IsFull() {
+		return false
+	}
+
+	fetched := m.fetchBottom(req)
+	if fetched {
+		m.topPort.RetrieveIncoming()
+		tracing.TraceReqReceive(req, m.Comp)
+		tracing.AddTaskStep(
+			tracing.MsgIDAtReceiver(req, m.Comp),
+			m.Comp,
+			"miss",
+		)
+
+		return true
+	}
+
+	return false
+}
+
+func (m *tlbMiddleware) vAddrToSetID(
+	vAddr uint64,
+	pageSize uint64,
+) (setID int) {
+	return int(vAddr / pageSize % uint64(m.numSets))
+}
+
+func (m *tlbMiddleware) sendRspToTop(
+	req *vm.TranslationReq,
+	page vm.Page,
+) bool {
+	rsp := vm.TranslationRspBuilder{}.
+		WithSrc(m.topPort.AsRemote()).
+		WithDst(req.Src).
+		WithRspTo(req.ID).
+		WithPage(page).
+		Build()
+
+	err := m.topPort.Send(rsp)
+
+	return err == nil
+}
+
+func (m *tlbMiddleware) processTLBMSHRHit(
+	mshrEntry *mshrEntry,
+	req *vm.TranslationReq,
+) bool {
+	mshrEntry.Requests = append(mshrEntry.Requests, req)
+
+	m.topPort.RetrieveIncoming()
+	tracing.TraceReqReceive(req, m.Comp)
+	tracing.AddTaskStep(
+		tracing.MsgIDAtReceiver(req, m.Comp), m.Comp, "mshr-hit")
+
+	return true
+}
+
+func (m *tlbMiddleware) fetchBottom(req *vm.TranslationReq) bool {
+	fetchBottom := vm.TranslationReqBuilder{}.
+		WithSrc(m.bottomPort.AsRemote()).
+		WithDst(m.LowModule).
+		WithPID(req.PID).
+		WithVAddr(req.VAddr).
+		WithDeviceID(req.DeviceID).
+		Build()
+
+	err := m.bottomPort.Send(fetchBottom)
+	if err != nil {
+		return false
+	}
+
+	mshrEntry := m.mshr.Add(req.PID, req.VAddr)
+	mshrEntry.Requests = append(mshrEntry.Requests, req)
+	mshrEntry.reqToBottom = fetchBottom
+
+	tracing.TraceReqInitiate(fetchBottom, m.Comp,
+		tracing.MsgIDAtReceiver(req, m.Comp))
+
+	return true
+}
+
+func (m *tlbMiddleware) parseBottom() bool {
+	if m.respondingMSHREntry != nil {
+		return false
+	}
+
+	item := m.bottomPort.PeekIncoming()
+	if item == nil {
+		return false
+	}
+
+	rsp := item.(*vm.TranslationRsp)
+	page := rsp.Page
+
+	mshrEntry := m.mshr.GetEntryCoveredByPage(page)
+	if mshrEntry == nil {
+		m.bottomPort.RetrieveIncoming()
+		return true
+	}
+
+	m.insertPage(page)
+
+	m.mshr.Remove(mshrEntry.pid, mshrEntry.vAddr)
+	m.splitMSHREntry(mshrEntry, page)
+
+	m.respondingMSHREntry = mshrEntry
+	mshrEntry.page = page
+
+	m.bottomPort.RetrieveIncoming()
+	tracing.TraceReqFinalize(mshrEntry.reqToBottom, m.Comp)
+
+	return true
+}
+
+// splitMSHREntry keeps the requests that the page covers in the entry. When
+// huge pages are enabled, the requests to the same huge page frame share an
+// MSHR entry, as the page that is being fetched may turn out to be a huge page.
+// If it turns out to be a base page, the requests to the other base pages are
+// moved to a new MSHR entry, which fetches the page again.
+func (m *tlbMiddleware) splitMSHREntry(mshrEntry *mshrEntry, page vm.Page) {
+	var covered, uncovered []*vm.TranslationReq
+
+	for _, req := range mshrEntry.Requests {
+		if pageCovers(page, req.VAddr) {
+			covered = append(covered, req)
+		} else {
+			uncovered = append(uncovered, req)
+		}
+	}
+
+	if len(uncovered) == 0 {
+		return
+	}
+
+	mshrEntry.Requests = covered
+
+	newEntry := m.mshr.Add(mshrEntry.pid, uncovered[0].VAddr)
+	newEntry.Requests = uncovered
+}
+
+// refetchSplitMSHREntries fetches the pages for the MSHR entries that are
+// created by splitMSHREntry.
+func (m *tlbMiddleware) refetchSplitMSHREntries() bool {
+	madeProgress := false
+
+	for _, mshrEntry := range m.mshr.AllEntries() {
+		if mshrEntry.reqToBottom != nil {
+			continue
+		}
+
+		req := mshrEntry.Requests[0]
+		fetchBottom := vm.TranslationReqBuilder{}.
+			WithSrc(m.bottomPort.AsRemote()).
+			WithDst(m.LowModule).
+			WithPID(req.PID).
+			WithVAddr(req.VAddr).
+			WithDeviceID(req.DeviceID).
+			Build()
+
+		err := m.bottomPort.Send(fetchBottom)
+		if err != nil {
+			return madeProgress
+		}
+
+		mshrEntry.reqToBottom = fetchBottom
+		madeProgress = true
+
+		tracing.TraceReqInitiate(fetchBottom, m.Comp,
+			tracing.MsgIDAtReceiver(req, m.Comp))
+	}
+
+	return madeProgress
+}
+
+// insertPage places the page in the set selected by its own page size. A huge
+// page may have been inserted already if several base pages that it covers
+// missed at the same time.
+func (m *tlbMiddleware) insertPage(page vm.Page) {
+	pageSize := m.pageSize
+	if m.hugePageSize != 0 && page.PageSize == m.hugePageSize {
+		pageSize = m.hugePageSize
+	}
+
+	setID := m.vAddrToSetID(page.VAddr, pageSize)
+	set := m.Sets[setID]
+
+	wayID, _, found := set.Lookup(page.PID, page.VAddr)
+	if !found {
+		var ok bool
+
+		wayID, ok = set.Evict()
+		if !ok {
+			panic("failed to evict")
+		}
+	}
+
+	set.Update(wayID, page)
+	set.Visit(wayID)
+}
+
+func (m *tlbMiddleware) performCtrlReq() bool {
+	item := m.controlPort.PeekIncoming()
+	if item == nil {
+		return false
+	}
+	item = m.controlPort.RetrieveIncoming()
+
+	switch req := item.(type) {
+	case *FlushReq:
+		return m.handleTLBFlush(req)
+	case *RestartReq:
+		return m.handleTLBRestart(req)
+	case *mem.ControlMsg:
+		if req.Enable {
+			m.state = "enable"
+		} else if req.Drain {
+			m.state = "drain"
+		} else if req.Pause {
+			m.state = "pause"
+		}
+	default:
+		log.Panicf("cannot process request %s", reflect.TypeOf(req))
+	}
+
+	return true
+}
+
+func (m *tlbMiddleware) visit(setID, wayID int) {
+	set := m.Sets[setID]
+	set.Visit(wayID)
+}
+
+func (m *tlbMiddleware) handleTLBFlush(req *FlushReq) bool {
+	for _, vAddr := range req.VAddr {
+		setID, wayID, page, found := m.lookupPage(req.PID, vAddr)
+		if !found {
+			continue
+		}
+
+		page.Valid = false
+		m.Sets[setID].Update(wayID, page)
+	}
+
+	m.mshr.Reset()
+	m.isPaused = true
+
+	m.pendingFlushRsp = FlushRspBuilder{}.
+		WithSrc(m.controlPort.AsRemote()).
+		WithDst(req.Src).
+		Build()
+	m.shootdownCyclesLeft = m.shootdownLatency
+	m.respondFlush()
+
+	return true
+}
+
+// respondFlush responds to a flush request after the TLB spends the shootdown
+// latency on invalidating the entries.
+func (m *tlbMiddleware) respondFlush() bool {
+	if m.pendingFlushRsp == nil {
+		return false
+	}
+
+	if m.shootdownCyclesLeft > 0 {
+		m.shootdownCyclesLeft--
+		return true
+	}
+
+	err := m.controlPort.Send(m.pendingFlushRsp)
+	if err != nil {
+		return false
+	}
+
+	m.pendingFlushRsp = nil
+
+	return true
+}
+
+func (m *tlbMiddleware) handleTLBRestart(req *RestartReq) bool {
+	rsp := RestartRspBuilder{}.
+		WithSrc(m.controlPort.AsRemote()).
+		WithDst(req.Src).
+		Build()
+
+	err := m.controlPort.Send(rsp)
+	if err != nil {
+		return false
+	}
+
+	m.isPaused = false
+
+	for m.topPort.RetrieveIncoming() != nil {
+		m.topPort.RetrieveIncoming()
+	}
+
+	for m.bottomPort.RetrieveIncoming() != nil {
+		m.bottomPort.RetrieveIncoming()
+	}
+
+	return true
+}
@@ -0,0 +1,102 @@
+package victimcache
+
+import (
+	"container/list"
+
+	"github.com/sarchlab/akita/v4/mem/mem"
+	"github.com/sarchlab/akita/v4/sim"
+)
+
+// A Builder can build victim caches.
+type Builder struct {
+	engine         sim.Engine
+	freq           sim.Freq
+	log2BlockSize  uint64
+	byteSize       uint64
+	numReqPerCycle int
+}
+
+// MakeBuilder creates a builder with default parameters.
+func MakeBuilder() Builder {
+	return Builder{
+		freq:           1 * sim.GHz,
+		log2BlockSize:  6,
+		byteSize:       4 * mem.KB,
+		numReqPerCycle: 4,
+	}
+}
+
+// WithEngine sets the engine to use.
+func (b Builder) WithEngine(engine sim.Engine) Builder {
+	b.engine = engine
+	return b
+}
+
+// WithFreq sets the frequency that the victim cache works at.
+func (b Builder) WithFreq(freq sim.Freq) Builder {
+	b.freq = freq
+	return b
+}
+
+// WithLog2BlockSize sets the number of bytes in a cache line as a power of 2.
+func (b Builder) WithLog2BlockSize(n uint64) Builder {
+	b.log2BlockSize = n
+	return b
+}
+
+// WithByteSize sets the capacity of the victim cache.
+func (b Builder) WithByteSize(byteSize uint64) Builder {
+	b.byteSize = byteSize
+	return b
+}
+
+// WithNumReqPerCycle sets the number of requests that the victim cache can
+// handle in each cycle.
+func (b Builder) WithNumReqPerCycle(n int) Builder {
+	b.numReqPerCycle = n
+	return b
+}
+
+// Build creates a victim cache with the given parameters.
+func (b Builder) Build(name string) *Comp {
+	c := &Comp{}
+
+	c.TickingComponent = sim.NewTickingComponent(name, b.engine, b.freq, c)
+
+	c.log2BlockSize = b.log2BlockSize
+	c.numLines = int(b.byteSize >> b.log2BlockSize)
+	c.numReqPerCycle = b.numReqPerCycle
+	c.lines = list.New()
+	c.lineTable = make(map[lineKey]*list.Element)
+	c.transactions = make(map[string]*transaction)
+
+	b.createPorts(name, c)
+
+	return c
+}
+
+func (b *Builder) createPorts(name string, c *Comp) {
+	c.topPort = sim.NewPort(
+		c,
+		2*b.numReqPerCycle,
+		2*b.numReqPerCycle,
+		name+".TopPort",
+	)
+	c.AddPort("Top", c.topPort)
+
+	c.bottomPort = sim.NewPort(
+		c,
+		2*b.numReqPerCycle,
+		2*b.numReqPerCycle,
+		name+".BottomPort",
+	)
+	c.AddPort("Bottom", c.bottomPort)
+
+	c.controlPort = sim.NewPort(
+		c,
+		1,
+		1,
+		name+".ControlPort",
+	)
+	c.AddPort("Control", c.controlPort)
+}
@@ -0,0 +1,408 @@
+// Package victimcache implements a small fully-associative cache that holds
+// the lines that an L1 cache evicts.
+//
+// The victim cache sits between an L1 cache and the low level modules. The L1
+// cache attaches the line that a read miss evicts to the read request (see
+// writearound.EvictionInfo). If the missing line is in the victim cache, the
+// victim cache responds with the line and drops it, as the line moves back to
+// the L1 cache. Otherwise, it forwards the request. Either way, it keeps the
+// evicted line. Writes and atomic accesses invalidate the local copy and are
+// always forwarded.
+package victimcache
+
+import (
+	"container/list"
+	"log"
+	"reflect"
+
+	"github.com/sarchlab/akita/v4/mem/cache"
+	"github.com/sarchlab/akita/v4/mem/mem"
+	"github.com/sarchlab/akita/v4/mem/vm"
+	"github.com/sarchlab/akita/v4/sim"
+	"github.com/sarchlab/akita/v4/tracing"
+	"github.com/sarchlab/mgpusim/v4/amd/timing/writearound"
+)
+
+type lineKey struct {
+	pid     vm.PID
+	address uint64
+}
+
+type line struct {
+	key  lineKey
+	data []byte
+}
+
+type transaction struct {
+	reqFromTop  mem.AccessReq
+	reqToBottom mem.AccessReq
+}
+
+// Comp is a victim cache.
+type Comp struct {
+	*sim.TickingComponent
+
+	topPort     sim.Port
+	bottomPort  sim.Port
+	controlPort sim.Port
+
+	addressToPortMapper mem.AddressToPortMapper
+
+	log2BlockSize  uint64
+	numLines       int
+	numReqPerCycle int
+
+	// lines is ordered from the most recently inserted line to the least
+	// recently inserted line.
+	lines        *list.List
+	lineTable    map[lineKey]*list.Element
+	transactions map[string]*transaction
+	isPaused     bool
+}
+
+// SetAddressToPortMapper sets the finder that tells which remote port can serve
+// the data on a certain address.
+func (c *Comp) SetAddressToPortMapper(lmf mem.AddressToPortMapper) {
+	c.addressToPortMapper = lmf
+}
+
+// Tick updates the status of the victim cache.
+func (c *Comp) Tick() (madeProgress bool) {
+	madeProgress = c.processControlMsg() || madeProgress
+
+	if c.isPaused {
+		return madeProgress
+	}
+
+	for i := 0; i < c.numReqPerCycle; i++ {
+		madeProgress = c.parseBottom() || madeProgress
+	}
+
+	for i := 0; i < c.numReqPerCycle; i++ {
+		madeProgress = c.parseTop() || madeProgress
+	}
+
+	return madeProgress
+}
+
+func (c *Comp) parseTop() bool {
+	item := c.topPort.PeekIncoming()
+	if item == nil {
+		return false
+	}
+
+	switch req := item.(type) {
+	case *mem.ReadReq:
+		return c.processRead(req)
+	case *mem.WriteReq:
+		return c.processWrite(req)
+	default:
+		log.Panicf("cannot handle request of type %s", reflect.TypeOf(item))
+	}
+
+	panic("never")
+}
+
+func (c *Comp) processRead(read *mem.ReadReq) bool {
+	if read.Info != nil && writearound.EvictionOf(read) == nil {
+		return c.processBypass(read)
+	}
+
+	elem := c.lookup(read)
+	if elem == nil {
+		if !c.forward(read) {
+			return false
+		}
+
+		tracing.AddTaskStep(
+			tracing.MsgIDAtReceiver(read, c), c, "read-miss")
+	} else {
+		if !c.respondHit(read, elem) {
+			return false
+		}
+
+		c.lines.Remove(elem)
+		delete(c.lineTable, elem.Value.(*line).key)
+	}
+
+	c.topPort.RetrieveIncoming()
+
+	if eviction := writearound.EvictionOf(read); eviction != nil {
+		c.insert(eviction)
+	}
+
+	return true
+}
+
+// processBypass forwards the reads that carry an operation, such as the
+// atomic accesses, after invalidating the local copy.
+func (c *Comp) processBypass(read *mem.ReadReq) bool {
+	if !c.forward(read) {
+		return false
+	}
+
+	tracing.AddTaskStep(tracing.MsgIDAtReceiver(read, c), c, "bypass")
+	c.invalidate(read.PID, read.Address)
+	c.topPort.RetrieveIncoming()
+
+	return true
+}
+
+func (c *Comp) processWrite(write *mem.WriteReq) bool {
+	if !c.forward(write) {
+		return false
+	}
+
+	tracing.AddTaskStep(tracing.MsgIDAtReceiver(write, c), c, "write")
+	c.invalidate(write.PID, write.Address)
+	c.topPort.RetrieveIncoming()
+
+	return true
+}
+
+func (c *Comp) lookup(read *mem.ReadReq) *list.Element {
+	blockSize := uint64(1) << c.log2BlockSize
+	lineAddr := read.Address / blockSize * blockSize
+
+	if read.Address+read.AccessByteSize > lineAddr+blockSize {
+		return nil
+	}
+
+	return c.lineTable[lineKey{pid: read.PID, address: lineAddr}]
+}
+
+func (c *Comp) respondHit(read *mem.ReadReq, elem *list.Element) bool {
+	l := elem.Value.(*line)
+	offset := read.Address - l.key.address
+
+	rsp := mem.DataReadyRspBuilder{}.
+		WithSrc(c.topPort.AsRemote()).
+		WithDst(read.Src).
+		WithRspTo(read.ID).
+		WithData(l.data[offset : offset+read.AccessByteSize]).
+		Build()
+
+	err := c.topPort.Send(rsp)
+	if err != nil {
+		return false
+	}
+
+	tracing.TraceReqReceive(read, c)
+	tracing.AddTaskStep(tracing.MsgIDAtReceiver(read, c), c, "read-hit")
+	tracing.TraceReqComplete(read, c)
+
+	return true
+}
+
+func (c *Comp) forward(req mem.AccessReq) bool {
+	reqToBottom := c.duplicateReq(req)
+
+	err := c.bottomPort.Send(reqToBottom)
+	if err != nil {
+		return false
+	}
+
+	c.transactions[reqToBottom.Meta().ID] = &transaction{
+		reqFromTop:  req,
+		reqToBottom: reqToBottom,
+	}
+
+	tracing.TraceReqReceive(req, c)
+	tracing.TraceReqInitiate(reqToBottom, c, tracing.MsgIDAtReceiver(req, c))
+
+	return true
+}
+
+func (c *Comp) duplicateReq(req mem.AccessReq) mem.AccessReq {
+	dst := c.addressToPortMapper.Find(req.GetAddress())
+
+	switch req := req.(type) {
+	case *mem.ReadReq:
+		info := req.Info
+		if _, isEviction := info.(*writearound.EvictionInfo); isEviction {
+			info = nil
+		}
+
+		return mem.ReadReqBuilder{}.
+			WithSrc(c.bottomPort.AsRemote()).
+			WithDst(dst).
+			WithAddress(req.Address).
+			WithByteSize(req.AccessByteSize).
+			WithPID(req.PID).
+			WithInfo(info).
+			Build()
+	case *mem.WriteReq:
+		return mem.WriteReqBuilder{}.
+			WithSrc(c.bottomPort.AsRemote()).
+			WithDst(dst).
+			WithAddress(req.Address).
+			WithPID(req.PID).
+			WithData(req.Data).
+			WithDirtyMask(req.DirtyMask).
+			WithInfo(req.Info).
+			Build()
+	default:
+		panic("unsupported type")
+	}
+}
+
+func (c *Comp) parseBottom() bool {
+	item := c.bottomPort.PeekIncoming()
+	if item == nil {
+		return false
+	}
+
+	rsp := item.(mem.AccessRsp)
+	trans, found := c.transactions[rsp.GetRspTo()]
+
+	if !found {
+		// The transaction is discarded by a flush.
+		c.bottomPort.RetrieveIncoming()
+		return true
+	}
+
+	rspToTop := c.duplicateRsp(rsp, trans.reqFromTop)
+
+	err := c.topPort.Send(rspToTop)
+	if err != nil {
+		return false
+	}
+
+	delete(c.transactions, rsp.GetRspTo())
+	c.bottomPort.RetrieveIncoming()
+
+	tracing.TraceReqFinalize(trans.reqToBottom, c)
+	tracing.TraceReqComplete(trans.reqFromTop, c)
+
+	return true
+}
+
+func (c *Comp) duplicateRsp(
+	rsp mem.AccessRsp,
+	reqFromTop mem.AccessReq,
+) mem.AccessRsp {
+	switch rsp := rsp.(type) {
+	case *mem.DataReadyRsp:
+		return mem.DataReadyRspBuilder{}.
+			WithSrc(c.topPort.AsRemote()).
+			WithDst(reqFromTop.Meta().Src).
+			WithRspTo(reqFromTop.Meta().ID).
+			WithData(rsp.Data).
+			Build()
+	case *mem.WriteDoneRsp:
+		return mem.WriteDoneRspBuilder{}.
+			WithSrc(c.topPort.AsRemote()).
+			WithDst(reqFromTop.Meta().Src).
+			WithRspTo(reqFromTop.Meta().ID).
+			Build()
+	default:
+		panic("type not supported")
+	}
+}
+
+func (c *Comp) insert(eviction *writearound.EvictionInfo) {
+	if c.numLines == 0 {
+		return
+	}
+
+	key := lineKey{pid: eviction.PID, address: eviction.Address}
+	if elem, found := c.lineTable[key]; found {
+		elem.Value.(*line).data = eviction.Data
+		c.lines.MoveToFront(elem)
+
+		return
+	}
+
+	if c.lines.Len() >= c.numLines {
+		oldest := c.lines.Back()
+		c.lines.Remove(oldest)
+		delete(c.lineTable, oldest.Value.(*line).key)
+	}
+
+	c.lineTable[key] = c.lines.PushFront(&line{key: key, data: eviction.Data})
+}
+
+func (c *Comp) invalidate(pid vm.PID, address uint64) {
+	blockSize := uint64(1) << c.log2BlockSize
+	key := lineKey{pid: pid, address: address / blockSize * blockSize}
+
+	elem, found := c.lineTable[key]
+	if !found {
+		return
+	}
+
+	c.lines.Remove(elem)
+	delete(c.lineTable, key)
+}
+
+func (c *Comp) invalidateAll() {
+	c.lines.Init()
+	c.lineTable = make(map[lineKey]*list.Element)
+}
+
+func (c *Comp) processControlMsg() bool {
+	item := c.controlPort.PeekIncoming()
+	if item == nil {
+		return false
+	}
+
+	switch req := item.(type) {
+	case *cache.FlushReq:
+		return c.flush(req)
+	case *cache.RestartReq:
+		return c.restart(req)
+	default:
+		log.Panicf("cannot handle request of type %s", reflect.TypeOf(item))
+	}
+
+	panic("never")
+}
+
+func (c *Comp) flush(req *cache.FlushReq) bool {
+	if !req.DiscardInflight && len(c.transactions) > 0 {
+		return false
+	}
+
+	rsp := cache.FlushRspBuilder{}.
+		WithSrc(c.controlPort.AsRemote()).
+		WithDst(req.Src).
+		WithRspTo(req.ID).
+		Build()
+
+	err := c.controlPort.Send(rsp)
+	if err != nil {
+		return false
+	}
+
+	c.invalidateAll()
+	c.transactions = make(map[string]*transaction)
+	c.isPaused = req.PauseAfterFlushing
+	c.controlPort.RetrieveIncoming()
+
+	return true
+}
+
+func (c *Comp) restart(req *cache.RestartReq) bool {
+	rsp := cache.RestartRspBuilder{}.
+		WithSrc(c.controlPort.AsRemote()).
+		WithDst(req.Src).
+		WithRspTo(req.ID).
+		Build()
+
+	err := c.controlPort.Send(rsp)
+	if err != nil {
+		return false
+	}
+
+	c.isPaused = false
+
+	for c.topPort.RetrieveIncoming() != nil {
+	}
+
+	for c.bottomPort.RetrieveIncoming() != nil {
+	}
+
+	c.controlPort.RetrieveIncoming()
+
+	return true
+}
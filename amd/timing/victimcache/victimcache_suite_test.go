@@ -0,0 +1,16 @@
+package victimcache
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+//go:generate mockgen -write_package_comment=false -package=$GOPACKAGE -destination=mock_sim_test.go github.com/sarchlab/akita/v4/sim Port
+//go:generate mockgen -write_package_comment=false -package=$GOPACKAGE -destination=mock_mem_test.go github.com/sarchlab/akita/v4/mem/mem AddressToPortMapper
+
+func TestVictimCache(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Victim Cache Suite")
+}
@@ -0,0 +1,249 @@
+package victimcache
+
+import (
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/sarchlab/akita/v4/mem/cache"
+	"github.com/sarchlab/akita/v4/mem/mem"
+	"github.com/sarchlab/akita/v4/sim"
+	"github.com/sarchlab/mgpusim/v4/amd/atomic"
+	"github.com/sarchlab/mgpusim/v4/amd/timing/writearound"
+)
+
+var _ = Describe("Victim Cache", func() {
+	var (
+		mockCtrl            *gomock.Controller
+		c                   *Comp
+		topPort             *MockPort
+		bottomPort          *MockPort
+		ctrlPort            *MockPort
+		addressToPortMapper *MockAddressToPortMapper
+	)
+
+	lineData := func(first byte) []byte {
+		data := make([]byte, 64)
+		for i := range data {
+			data[i] = first + byte(i)
+		}
+
+		return data
+	}
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+
+		topPort = NewMockPort(mockCtrl)
+		bottomPort = NewMockPort(mockCtrl)
+		ctrlPort = NewMockPort(mockCtrl)
+		addressToPortMapper = NewMockAddressToPortMapper(mockCtrl)
+
+		topPort.EXPECT().AsRemote().Return(sim.RemotePort("Top")).AnyTimes()
+		bottomPort.EXPECT().AsRemote().
+			Return(sim.RemotePort("Bottom")).AnyTimes()
+		ctrlPort.EXPECT().AsRemote().
+			Return(sim.RemotePort("Control")).AnyTimes()
+		addressToPortMapper.EXPECT().Find(gomock.Any()).
+			Return(sim.RemotePort("L2")).AnyTimes()
+
+		c = MakeBuilder().
+			WithByteSize(128).
+			WithNumReqPerCycle(1).
+			Build("VictimCache")
+		c.topPort = topPort
+		c.bottomPort = bottomPort
+		c.controlPort = ctrlPort
+		c.SetAddressToPortMapper(addressToPortMapper)
+	})
+
+	AfterEach(func() {
+		mockCtrl.Finish()
+	})
+
+	Context("read", func() {
+		var read *mem.ReadReq
+
+		BeforeEach(func() {
+			read = mem.ReadReqBuilder{}.
+				WithSrc(sim.RemotePort("L1")).
+				WithAddress(0x100).
+				WithByteSize(64).
+				WithInfo(&writearound.EvictionInfo{
+					Address: 0x1100,
+					Data:    lineData(1),
+				}).
+				Build()
+
+			ctrlPort.EXPECT().PeekIncoming().Return(nil)
+			bottomPort.EXPECT().PeekIncoming().Return(nil)
+			topPort.EXPECT().PeekIncoming().Return(read)
+		})
+
+		It("should forward a miss and keep the evicted line", func() {
+			var readToBottom *mem.ReadReq
+			bottomPort.EXPECT().Send(gomock.Any()).
+				DoAndReturn(func(req *mem.ReadReq) *sim.SendError {
+					readToBottom = req
+					return nil
+				})
+			topPort.EXPECT().RetrieveIncoming()
+
+			madeProgress := c.Tick()
+
+			Expect(madeProgress).To(BeTrue())
+			Expect(readToBottom.Address).To(Equal(uint64(0x100)))
+			Expect(readToBottom.Dst).To(Equal(sim.RemotePort("L2")))
+			Expect(readToBottom.Info).To(BeNil())
+			Expect(c.transactions).To(HaveKey(readToBottom.ID))
+			Expect(c.lineTable).To(HaveKey(lineKey{address: 0x1100}))
+		})
+
+		It("should respond a hit and drop the line", func() {
+			c.insert(&writearound.EvictionInfo{
+				Address: 0x100,
+				Data:    lineData(2),
+			})
+
+			topPort.EXPECT().Send(gomock.Any()).
+				DoAndReturn(func(rsp *mem.DataReadyRsp) *sim.SendError {
+					Expect(rsp.RespondTo).To(Equal(read.ID))
+					Expect(rsp.Dst).To(Equal(sim.RemotePort("L1")))
+					Expect(rsp.Data).To(Equal(lineData(2)))
+					return nil
+				})
+			topPort.EXPECT().RetrieveIncoming()
+
+			madeProgress := c.Tick()
+
+			Expect(madeProgress).To(BeTrue())
+			Expect(c.lineTable).NotTo(HaveKey(lineKey{address: 0x100}))
+			Expect(c.lineTable).To(HaveKey(lineKey{address: 0x1100}))
+		})
+
+		It("should replace the oldest line if full", func() {
+			c.insert(&writearound.EvictionInfo{Address: 0x200})
+			c.insert(&writearound.EvictionInfo{Address: 0x300})
+
+			bottomPort.EXPECT().Send(gomock.Any())
+			topPort.EXPECT().RetrieveIncoming()
+
+			c.Tick()
+
+			Expect(c.lineTable).To(HaveLen(2))
+			Expect(c.lineTable).NotTo(HaveKey(lineKey{address: 0x200}))
+			Expect(c.lineTable).To(HaveKey(lineKey{address: 0x1100}))
+		})
+
+		It("should stall if cannot forward", func() {
+			bottomPort.EXPECT().Send(gomock.Any()).Return(&sim.SendError{})
+
+			madeProgress := c.Tick()
+
+			Expect(madeProgress).To(BeFalse())
+			Expect(c.lineTable).To(BeEmpty())
+		})
+	})
+
+	It("should invalidate the line and forward atomics", func() {
+		c.insert(&writearound.EvictionInfo{Address: 0x100, Data: lineData(0)})
+		info := &atomic.Info{Op: atomic.OpAdd, Data: []byte{1, 0, 0, 0}}
+		read := mem.ReadReqBuilder{}.
+			WithAddress(0x104).
+			WithByteSize(4).
+			WithInfo(info).
+			Build()
+
+		ctrlPort.EXPECT().PeekIncoming().Return(nil)
+		bottomPort.EXPECT().PeekIncoming().Return(nil)
+		topPort.EXPECT().PeekIncoming().Return(read)
+		bottomPort.EXPECT().Send(gomock.Any()).
+			DoAndReturn(func(req *mem.ReadReq) *sim.SendError {
+				Expect(req.Info).To(BeIdenticalTo(info))
+				return nil
+			})
+		topPort.EXPECT().RetrieveIncoming()
+
+		c.Tick()
+
+		Expect(c.lineTable).To(BeEmpty())
+	})
+
+	It("should invalidate the line and forward writes", func() {
+		c.insert(&writearound.EvictionInfo{Address: 0x100, Data: lineData(0)})
+		write := mem.WriteReqBuilder{}.
+			WithAddress(0x104).
+			WithData([]byte{1, 2, 3, 4}).
+			Build()
+
+		ctrlPort.EXPECT().PeekIncoming().Return(nil)
+		bottomPort.EXPECT().PeekIncoming().Return(nil)
+		topPort.EXPECT().PeekIncoming().Return(write)
+		bottomPort.EXPECT().Send(gomock.Any()).
+			DoAndReturn(func(req *mem.WriteReq) *sim.SendError {
+				Expect(req.Address).To(Equal(uint64(0x104)))
+				Expect(req.Data).To(Equal([]byte{1, 2, 3, 4}))
+				return nil
+			})
+		topPort.EXPECT().RetrieveIncoming()
+
+		c.Tick()
+
+		Expect(c.lineTable).To(BeEmpty())
+	})
+
+	It("should return the responses from the bottom", func() {
+		read := mem.ReadReqBuilder{}.
+			WithSrc(sim.RemotePort("L1")).
+			WithAddress(0x100).
+			WithByteSize(64).
+			Build()
+		readToBottom := c.duplicateReq(read)
+		c.transactions[readToBottom.Meta().ID] = &transaction{
+			reqFromTop:  read,
+			reqToBottom: readToBottom,
+		}
+		rsp := mem.DataReadyRspBuilder{}.
+			WithRspTo(readToBottom.Meta().ID).
+			WithData(lineData(3)).
+			Build()
+
+		ctrlPort.EXPECT().PeekIncoming().Return(nil)
+		bottomPort.EXPECT().PeekIncoming().Return(rsp)
+		bottomPort.EXPECT().RetrieveIncoming()
+		topPort.EXPECT().PeekIncoming().Return(nil)
+		topPort.EXPECT().Send(gomock.Any()).
+			DoAndReturn(func(rsp *mem.DataReadyRsp) *sim.SendError {
+				Expect(rsp.RespondTo).To(Equal(read.ID))
+				Expect(rsp.Dst).To(Equal(sim.RemotePort("L1")))
+				Expect(rsp.Data).To(Equal(lineData(3)))
+				return nil
+			})
+
+		madeProgress := c.Tick()
+
+		Expect(madeProgress).To(BeTrue())
+		Expect(c.transactions).To(BeEmpty())
+	})
+
+	It("should invalidate all the lines when flushing", func() {
+		c.insert(&writearound.EvictionInfo{Address: 0x100, Data: lineData(0)})
+		flush := cache.FlushReqBuilder{}.
+			WithSrc(sim.RemotePort("CP")).
+			PauseAfterFlushing().
+			Build()
+
+		ctrlPort.EXPECT().PeekIncoming().Return(flush)
+		ctrlPort.EXPECT().RetrieveIncoming()
+		ctrlPort.EXPECT().Send(gomock.Any()).
+			DoAndReturn(func(rsp *cache.FlushRsp) *sim.SendError {
+				Expect(rsp.RspTo).To(Equal(flush.ID))
+				return nil
+			})
+
+		madeProgress := c.Tick()
+
+		Expect(madeProgress).To(BeTrue())
+		Expect(c.lineTable).To(BeEmpty())
+		Expect(c.isPaused).To(BeTrue())
+	})
+})
@@ -12,6 +12,11 @@ type WorkGroup struct {
 	Wfs    []*Wavefront
 	MapReq *protocol.MapWGReq
 	LDS    []byte
+
+	// Fault is the first fault that the wavefronts of the work-group
+	// encounter. It is reported to the dispatcher when the work-group
+	// completes.
+	Fault error
 }
 
 // NewWorkGroup returns a newly constructed WorkGroup
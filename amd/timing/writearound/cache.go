@@ -0,0 +1,142 @@
+package writearound
+
+import (
+	"github.com/sarchlab/akita/v4/mem/cache"
+	"github.com/sarchlab/akita/v4/mem/mem"
+	"github.com/sarchlab/akita/v4/sim"
+)
+
+// Comp is a customized L1 cache the for R9nano GPUs.
+type Comp struct {
+	*sim.TickingComponent
+	sim.MiddlewareHolder
+
+	topPort     sim.Port
+	bottomPort  sim.Port
+	controlPort sim.Port
+
+	numReqPerCycle      int
+	numPort             int
+	numPortUsed         int
+	log2BlockSize       uint64
+	storage             *mem.Storage
+	directory           cache.Directory
+	mshr                cache.MSHR
+	bankLatency         int
+	wayAssociativity    int
+	addressToPortMapper mem.AddressToPortMapper
+	forwardEvictions    bool
+
+	dirBuf   sim.Buffer
+	bankBufs []sim.Buffer
+
+	coalesceStage    *coalescer
+	directoryStage   *directory
+	bankStages       []*bankStage
+	parseBottomStage *bottomParser
+	respondStage     *respondStage
+	controlStage     *controlStage
+
+	maxNumConcurrentTrans    int
+	transactions             []*transaction
+	postCoalesceTransactions []*transaction
+
+	isPaused bool
+}
+
+// SetAddressToPortMapper sets the finder that tells which remote port can serve
+// the data on a certain address.
+func (c *Comp) SetAddressToPortMapper(lmf mem.AddressToPortMapper) {
+	c.addressToPortMapper = lmf
+}
+
+// acquirePort takes a port for an access to the tag or the data array in the
+// current cycle. It returns false if all the ports are taken.
+func (c *Comp) acquirePort() bool {
+	if c.numPort == 0 {
+		return true
+	}
+
+	if c.numPortUsed >= c.numPort {
+		return false
+	}
+
+	c.numPortUsed++
+
+	return true
+}
+
+func (c *Comp) Tick() bool {
+	return c.MiddlewareHolder.Tick()
+}
+
+type middleware struct {
+	*Comp
+}
+
+// Tick update the state of the cache
+func (m *middleware) Tick() bool {
+	madeProgress := false
+
+	if !m.isPaused {
+		madeProgress = m.runPipeline() || madeProgress
+	}
+
+	madeProgress = m.controlStage.Tick() || madeProgress
+
+	return madeProgress
+}
+
+func (m *middleware) runPipeline() bool {
+	madeProgress := false
+	m.numPortUsed = 0
+
+	madeProgress = m.tickRespondStage() || madeProgress
+	madeProgress = m.tickParseBottomStage() || madeProgress
+	madeProgress = m.tickBankStage() || madeProgress
+	madeProgress = m.tickDirectoryStage() || madeProgress
+	madeProgress = m.tickCoalesceState() || madeProgress
+
+	return madeProgress
+}
+
+func (m *middleware) tickRespondStage() bool {
+	madeProgress := false
+	for i := 0; i < m.numReqPerCycle; i++ {
+		madeProgress = m.respondStage.Tick() || madeProgress
+	}
+
+	return madeProgress
+}
+
+func (m *middleware) tickParseBottomStage() bool {
+	madeProgress := false
+
+	for i := 0; i < m.numReqPerCycle; i++ {
+		madeProgress = m.parseBottomStage.Tick() || madeProgress
+	}
+
+	return madeProgress
+}
+
+func (m *middleware) tickBankStage() bool {
+	madeProgress := false
+	for _, bs := range m.bankStages {
+		madeProgress = bs.Tick() || madeProgress
+	}
+
+	return madeProgress
+}
+
+func (m *middleware) tickDirectoryStage() bool {
+	return m.directoryStage.Tick()
+}
+
+func (m *middleware) tickCoalesceState() bool {
+	madeProgress := false
+	for i := 0; i < m.numReqPerCycle; i++ {
+		madeProgress = m.coalesceStage.Tick() || madeProgress
+	}
+
+	return madeProgress
+}
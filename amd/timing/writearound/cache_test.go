@@ -0,0 +1,235 @@
+package writearound_test
+
+import (
+	gomock "github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/sarchlab/akita/v4/mem/idealmemcontroller"
+	"github.com/sarchlab/akita/v4/sim"
+	"github.com/sarchlab/akita/v4/sim/directconnection"
+	. "github.com/sarchlab/mgpusim/v4/amd/timing/writearound"
+
+	"github.com/sarchlab/akita/v4/mem/mem"
+)
+
+var _ = Describe("Cache", func() {
+	var (
+		mockCtrl            *gomock.Controller
+		engine              sim.Engine
+		connection          sim.Connection
+		addressToPortMapper mem.AddressToPortMapper
+		dram                *idealmemcontroller.Comp
+		cuPort              *MockPort
+		c                   *Comp
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+
+		cuPort = NewMockPort(mockCtrl)
+		cuPort.EXPECT().PeekOutgoing().Return(nil).AnyTimes()
+		cuPort.EXPECT().AsRemote().Return(sim.RemotePort("cuPort")).AnyTimes()
+
+		engine = sim.NewSerialEngine()
+		connection = directconnection.MakeBuilder().
+			WithEngine(engine).
+			WithFreq(1 * sim.GHz).
+			Build("Conn")
+
+		dram = idealmemcontroller.MakeBuilder().
+			WithEngine(engine).
+			WithNewStorage(4 * mem.GB).
+			Build("DRAM")
+		addressToPortMapper = &mem.SinglePortMapper{
+			Port: dram.GetPortByName("Top").AsRemote(),
+		}
+
+		c = NewBuilder().
+			WithEngine(engine).
+			WithAddressToPortMapper(addressToPortMapper).
+			Build("Cache")
+
+		connection.PlugIn(dram.GetPortByName("Top"))
+		connection.PlugIn(c.GetPortByName("Top"))
+		connection.PlugIn(c.GetPortByName("Bottom"))
+		cuPort.EXPECT().SetConnection(connection)
+		connection.PlugIn(cuPort)
+	})
+
+	AfterEach(func() {
+		mockCtrl.Finish()
+	})
+
+	It("should do read miss", func() {
+		dram.Storage.Write(0x100, []byte{1, 2, 3, 4})
+		read := mem.ReadReqBuilder{}.
+			WithSrc(cuPort.AsRemote()).
+			WithDst(c.GetPortByName("Top").AsRemote()).
+			WithAddress(0x100).
+			WithByteSize(4).
+			Build()
+		c.GetPortByName("Top").Deliver(read)
+
+		cuPort.EXPECT().Deliver(gomock.Any()).
+			Do(func(dr *mem.DataReadyRsp) {
+				Expect(dr.Data).To(Equal([]byte{1, 2, 3, 4}))
+			})
+
+		engine.Run()
+	})
+
+	It("should do read miss coalesce", func() {
+		dram.Storage.Write(0x100, []byte{1, 2, 3, 4, 5, 6, 7, 8})
+		read1 := mem.ReadReqBuilder{}.
+			WithSrc(cuPort.AsRemote()).
+			WithDst(c.GetPortByName("Top").AsRemote()).
+			WithAddress(0x100).
+			WithByteSize(4).
+			Build()
+		c.GetPortByName("Top").Deliver(read1)
+
+		read2 := mem.ReadReqBuilder{}.
+			WithSrc(cuPort.AsRemote()).
+			WithDst(c.GetPortByName("Top").AsRemote()).
+			WithAddress(0x104).
+			WithByteSize(4).
+			Build()
+		c.GetPortByName("Top").Deliver(read2)
+
+		cuPort.EXPECT().Deliver(gomock.Any()).
+			Do(func(dr *mem.DataReadyRsp) {
+				Expect(dr.Data).To(Equal([]byte{1, 2, 3, 4}))
+			})
+		cuPort.EXPECT().Deliver(gomock.Any()).
+			Do(func(dr *mem.DataReadyRsp) {
+				Expect(dr.Data).To(Equal([]byte{5, 6, 7, 8}))
+			})
+
+		engine.Run()
+	})
+
+	It("should do read hit", func() {
+		dram.Storage.Write(0x100, []byte{1, 2, 3, 4, 5, 6, 7, 8})
+		read1 := mem.ReadReqBuilder{}.
+			WithSrc(cuPort.AsRemote()).
+			WithDst(c.GetPortByName("Top").AsRemote()).
+			WithAddress(0x100).
+			WithByteSize(4).
+			Build()
+		c.GetPortByName("Top").Deliver(read1)
+		cuPort.EXPECT().Deliver(gomock.Any()).
+			Do(func(dr *mem.DataReadyRsp) {
+				Expect(dr.Data).To(Equal([]byte{1, 2, 3, 4}))
+			})
+		engine.Run()
+		t1 := engine.CurrentTime()
+
+		read2 := mem.ReadReqBuilder{}.
+			WithSrc(cuPort.AsRemote()).
+			WithDst(c.GetPortByName("Top").AsRemote()).
+			WithAddress(0x104).
+			WithByteSize(4).
+			Build()
+		c.GetPortByName("Top").Deliver(read2)
+		cuPort.EXPECT().Deliver(gomock.Any()).
+			Do(func(dr *mem.DataReadyRsp) {
+				Expect(dr.Data).To(Equal([]byte{5, 6, 7, 8}))
+			})
+		engine.Run()
+		t2 := engine.CurrentTime()
+
+		Expect(t2 - t1).To(BeNumerically("<", t1))
+	})
+
+	It("should take longer to serve read hits with a single port", func() {
+		onePort := NewBuilder().
+			WithEngine(engine).
+			WithAddressToPortMapper(addressToPortMapper).
+			WithNumPorts(1).
+			Build("OnePortCache")
+		connection.PlugIn(onePort.GetPortByName("Top"))
+		connection.PlugIn(onePort.GetPortByName("Bottom"))
+		cuPort.EXPECT().Deliver(gomock.Any()).Times(10)
+		cuPort.EXPECT().NotifyAvailable().AnyTimes()
+
+		// The first read brings the line into the cache and the next four
+		// reads hit at the same time.
+		readHitTime := func(cache *Comp) sim.VTimeInSec {
+			read := func(addr uint64) {
+				req := mem.ReadReqBuilder{}.
+					WithSrc(cuPort.AsRemote()).
+					WithDst(cache.GetPortByName("Top").AsRemote()).
+					WithAddress(addr).
+					WithByteSize(4).
+					Build()
+				cache.GetPortByName("Top").Deliver(req)
+			}
+
+			read(0x100)
+			engine.Run()
+
+			start := engine.CurrentTime()
+			for i := uint64(0); i < 4; i++ {
+				read(0x100 + 4*i)
+			}
+			engine.Run()
+
+			return engine.CurrentTime() - start
+		}
+
+		// The hits take 8 accesses to the arrays, which the single port
+		// serves one per cycle.
+		unlimited := readHitTime(c)
+		Expect(readHitTime(onePort)).
+			To(BeNumerically(">=", unlimited+4*sim.VTimeInSec(1e-9)))
+	})
+
+	It("should write partial line", func() {
+		write := mem.WriteReqBuilder{}.
+			WithSrc(cuPort.AsRemote()).
+			WithDst(c.GetPortByName("Top").AsRemote()).
+			WithAddress(0x100).
+			WithData([]byte{1, 2, 3, 4}).
+			Build()
+		c.GetPortByName("Top").Deliver(write)
+		cuPort.EXPECT().Deliver(gomock.Any()).
+			Do(func(done *mem.WriteDoneRsp) {
+				Expect(done.RespondTo).To(Equal(write.ID))
+			})
+
+		engine.Run()
+
+		data, _ := dram.Storage.Read(0x100, 4)
+		Expect(data).To(Equal([]byte{1, 2, 3, 4}))
+	})
+
+	It("should write full line", func() {
+		write := mem.WriteReqBuilder{}.
+			WithSrc(cuPort.AsRemote()).
+			WithDst(c.GetPortByName("Top").AsRemote()).
+			WithAddress(0x100).
+			WithData(
+				[]byte{
+					1, 2, 3, 4, 5, 6, 7, 8,
+					1, 2, 3, 4, 5, 6, 7, 8,
+					1, 2, 3, 4, 5, 6, 7, 8,
+					1, 2, 3, 4, 5, 6, 7, 8,
+					1, 2, 3, 4, 5, 6, 7, 8,
+					1, 2, 3, 4, 5, 6, 7, 8,
+					1, 2, 3, 4, 5, 6, 7, 8,
+					1, 2, 3, 4, 5, 6, 7, 8,
+				}).
+			Build()
+		c.GetPortByName("Top").Deliver(write)
+		cuPort.EXPECT().Deliver(gomock.Any()).
+			Do(func(done *mem.WriteDoneRsp) {
+				Expect(done.RespondTo).To(Equal(write.ID))
+			})
+		engine.Run()
+
+		data, _ := dram.Storage.Read(0x100, 4)
+		Expect(data).To(Equal([]byte{1, 2, 3, 4}))
+	})
+
+})
@@ -0,0 +1,257 @@
+package writearound
+
+import (
+	"log"
+	"reflect"
+
+	"github.com/sarchlab/akita/v4/mem/mem"
+	"github.com/sarchlab/akita/v4/sim"
+	"github.com/sarchlab/akita/v4/tracing"
+	"github.com/sarchlab/mgpusim/v4/amd/atomic"
+	"github.com/sarchlab/mgpusim/v4/amd/protocol"
+)
+
+type coalescer struct {
+	cache      *Comp
+	toCoalesce []*transaction
+}
+
+func (c *coalescer) Reset() {
+	c.toCoalesce = nil
+}
+
+func (c *coalescer) Tick() bool {
+	req := c.cache.topPort.PeekIncoming()
+	if req == nil {
+		return false
+	}
+
+	return c.processReq(req.(mem.AccessReq))
+}
+
+func (c *coalescer) processReq(req mem.AccessReq) bool {
+	if len(c.cache.transactions) >= c.cache.maxNumConcurrentTrans {
+		return false
+	}
+
+	if c.isReqLastInWave(req) {
+		if len(c.toCoalesce) == 0 || c.canReqCoalesce(req) {
+			return c.processReqLastInWaveCoalescable(req)
+		}
+
+		return c.processReqLastInWaveNoncoalescable(req)
+	}
+
+	if len(c.toCoalesce) == 0 || c.canReqCoalesce(req) {
+		return c.processReqCoalescable(req)
+	}
+
+	return c.processReqNoncoalescable(req)
+}
+
+func (c *coalescer) processReqCoalescable(req mem.AccessReq) bool {
+	trans := c.createTransaction(req)
+	c.toCoalesce = append(c.toCoalesce, trans)
+	c.cache.transactions = append(c.cache.transactions, trans)
+	c.cache.topPort.RetrieveIncoming()
+
+	tracing.TraceReqReceive(req, c.cache)
+
+	return true
+}
+
+func (c *coalescer) processReqNoncoalescable(req mem.AccessReq) bool {
+	if !c.cache.dirBuf.CanPush() {
+		return false
+	}
+
+	c.coalesceAndSend()
+
+	trans := c.createTransaction(req)
+	c.toCoalesce = append(c.toCoalesce, trans)
+	c.cache.transactions = append(c.cache.transactions, trans)
+	c.cache.topPort.RetrieveIncoming()
+
+	tracing.TraceReqReceive(req, c.cache)
+
+	return true
+}
+
+func (c *coalescer) processReqLastInWaveCoalescable(req mem.AccessReq) bool {
+	if !c.cache.dirBuf.CanPush() {
+		return false
+	}
+
+	trans := c.createTransaction(req)
+	c.toCoalesce = append(c.toCoalesce, trans)
+	c.cache.transactions = append(c.cache.transactions, trans)
+	c.coalesceAndSend()
+	c.cache.topPort.RetrieveIncoming()
+
+	tracing.TraceReqReceive(req, c.cache)
+
+	return true
+}
+
+func (c *coalescer) processReqLastInWaveNoncoalescable(req mem.AccessReq) bool {
+	if !c.cache.dirBuf.CanPush() {
+		return false
+	}
+
+	c.coalesceAndSend()
+
+	if !c.cache.dirBuf.CanPush() {
+		return true
+	}
+
+	trans := c.createTransaction(req)
+	c.toCoalesce = append(c.toCoalesce, trans)
+	c.cache.transactions = append(c.cache.transactions, trans)
+	c.coalesceAndSend()
+	c.cache.topPort.RetrieveIncoming()
+
+	tracing.TraceReqReceive(req, c.cache)
+
+	return true
+}
+
+func (c *coalescer) createTransaction(req mem.AccessReq) *transaction {
+	switch req := req.(type) {
+	case *mem.ReadReq:
+		t := &transaction{
+			read: req,
+		}
+
+		return t
+	case *mem.WriteReq:
+		t := &transaction{
+			write: req,
+		}
+
+		return t
+	default:
+		log.Panicf("cannot process request of type %s\n", reflect.TypeOf(req))
+		return nil
+	}
+}
+
+func (c *coalescer) isReqLastInWave(req mem.AccessReq) bool {
+	switch req := req.(type) {
+	case *mem.ReadReq:
+		return !req.CanWaitForCoalesce
+	case *mem.WriteReq:
+		return !req.CanWaitForCoalesce
+	default:
+		panic("unknown type")
+	}
+}
+
+func (c *coalescer) canReqCoalesce(req mem.AccessReq) bool {
+	if atomic.InfoOf(req) != nil || c.toCoalesce[0].isAtomic() {
+		return false
+	}
+
+	if protocol.IsStreamingStore(req) != c.toCoalesce[0].isStreamingStore() {
+		return false
+	}
+
+	blockSize := uint64(1 << c.cache.log2BlockSize)
+	return req.GetAddress()/blockSize == c.toCoalesce[0].Address()/blockSize
+}
+
+func (c *coalescer) coalesceAndSend() bool {
+	var trans *transaction
+	if c.toCoalesce[0].isAtomic() {
+		trans = c.coalesceAtomic()
+		tracing.StartTaskWithSpecificLocation(trans.id,
+			tracing.MsgIDAtReceiver(c.toCoalesce[0].read, c.cache),
+			c.cache, "cache_transaction", "atomic",
+			c.cache.Name()+".Local",
+			nil)
+	} else if c.toCoalesce[0].read != nil {
+		trans = c.coalesceRead()
+		tracing.StartTaskWithSpecificLocation(trans.id,
+			tracing.MsgIDAtReceiver(c.toCoalesce[0].read, c.cache),
+			c.cache, "cache_transaction", "read",
+			c.cache.Name()+".Local",
+			nil)
+	} else {
+		trans = c.coalesceWrite()
+		tracing.StartTaskWithSpecificLocation(trans.id,
+			tracing.MsgIDAtReceiver(c.toCoalesce[0].write, c.cache),
+			c.cache, "cache_transaction", "write",
+			c.cache.Name()+".Local",
+			nil)
+	}
+
+	c.cache.dirBuf.Push(trans)
+	c.cache.postCoalesceTransactions =
+		append(c.cache.postCoalesceTransactions, trans)
+	c.toCoalesce = nil
+
+	return true
+}
+
+func (c *coalescer) coalesceRead() *transaction {
+	blockSize := uint64(1 << c.cache.log2BlockSize)
+	cachelineID := c.toCoalesce[0].Address() / blockSize * blockSize
+	coalescedRead := mem.ReadReqBuilder{}.
+		WithAddress(cachelineID).
+		WithByteSize(blockSize).
+		WithPID(c.toCoalesce[0].PID()).
+		Build()
+
+	return &transaction{
+		id:                      sim.GetIDGenerator().Generate(),
+		read:                    coalescedRead,
+		preCoalesceTransactions: c.toCoalesce,
+	}
+}
+
+// coalesceAtomic creates a transaction for an atomic access. Atomic accesses
+// are never combined with other accesses.
+func (c *coalescer) coalesceAtomic() *transaction {
+	read := c.toCoalesce[0].read
+	atomicRead := mem.ReadReqBuilder{}.
+		WithAddress(read.Address).
+		WithByteSize(read.AccessByteSize).
+		WithPID(read.PID).
+		WithInfo(read.Info).
+		Build()
+
+	return &transaction{
+		id:                      sim.GetIDGenerator().Generate(),
+		read:                    atomicRead,
+		preCoalesceTransactions: c.toCoalesce,
+	}
+}
+
+func (c *coalescer) coalesceWrite() *transaction {
+	blockSize := uint64(1 << c.cache.log2BlockSize)
+	cachelineID := c.toCoalesce[0].Address() / blockSize * blockSize
+	write := mem.WriteReqBuilder{}.
+		WithAddress(cachelineID).
+		WithPID(c.toCoalesce[0].PID()).
+		WithData(make([]byte, blockSize)).
+		WithDirtyMask(make([]bool, blockSize)).
+		WithInfo(c.toCoalesce[0].write.Info).
+		Build()
+
+	for _, t := range c.toCoalesce {
+		w := t.write
+		offset := int(w.Address - cachelineID)
+
+		for i := 0; i < len(w.Data); i++ {
+			if w.DirtyMask == nil || w.DirtyMask[i] {
+				write.Data[i+offset] = w.Data[i]
+				write.DirtyMask[i+offset] = true
+			}
+		}
+	}
+
+	return &transaction{
+		id:                      sim.GetIDGenerator().Generate(),
+		write:                   write,
+		preCoalesceTransactions: c.toCoalesce,
+	}
+}
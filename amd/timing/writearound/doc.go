@@ -0,0 +1,9 @@
+// Package writearound provides a GCN3 GPU L1 cache implementation.
+//
+// The package is derived from the writearound cache in Akita. It additionally
+// forwards the atomic accesses to the lower-level cache, which performs the
+// read-modify-write operations. It can also attach the evicted lines to the
+// read misses so that a victim cache can capture them. The streaming stores
+// keep their marker when they are forwarded, so that the lower-level cache
+// does not allocate them either.
+package writearound
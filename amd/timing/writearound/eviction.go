@@ -0,0 +1,28 @@
+package writearound
+
+import (
+	"github.com/sarchlab/akita/v4/mem/mem"
+	"github.com/sarchlab/akita/v4/mem/vm"
+)
+
+// EvictionInfo is the line that a read miss evicts from the cache. If the
+// eviction forwarding is enabled, the cache sets it as the Info of the read
+// request that it sends to the low level module.
+type EvictionInfo struct {
+	Address uint64
+	PID     vm.PID
+	Data    []byte
+}
+
+// EvictionOf returns the line that a request evicts. It returns nil if the
+// request does not carry an evicted line.
+func EvictionOf(req mem.AccessReq) *EvictionInfo {
+	read, ok := req.(*mem.ReadReq)
+	if !ok {
+		return nil
+	}
+
+	info, _ := read.Info.(*EvictionInfo)
+
+	return info
+}
@@ -0,0 +1,73 @@
+package writearound
+
+import (
+	"github.com/sarchlab/akita/v4/mem/vm"
+	"github.com/sarchlab/akita/v4/sim"
+)
+
+// An InvalidateReq asks the cache to invalidate a cache line, as another cache
+// has written to the line. The low-level module that keeps the caches coherent
+// sends the request to the bottom port of the cache. The cache does not
+// respond.
+type InvalidateReq struct {
+	sim.MsgMeta
+
+	Address uint64
+	PID     vm.PID
+}
+
+// Meta returns the meta data associated with the message.
+func (r *InvalidateReq) Meta() *sim.MsgMeta {
+	return &r.MsgMeta
+}
+
+// Clone returns a clone of the InvalidateReq with different ID.
+func (r *InvalidateReq) Clone() sim.Msg {
+	cloneMsg := *r
+	cloneMsg.ID = sim.GetIDGenerator().Generate()
+
+	return &cloneMsg
+}
+
+// InvalidateReqBuilder can build InvalidateReqs.
+type InvalidateReqBuilder struct {
+	src, dst sim.RemotePort
+	address  uint64
+	pid      vm.PID
+}
+
+// WithSrc sets the source of the request to build.
+func (b InvalidateReqBuilder) WithSrc(src sim.RemotePort) InvalidateReqBuilder {
+	b.src = src
+	return b
+}
+
+// WithDst sets the destination of the request to build.
+func (b InvalidateReqBuilder) WithDst(dst sim.RemotePort) InvalidateReqBuilder {
+	b.dst = dst
+	return b
+}
+
+// WithAddress sets the address of the cache line to invalidate.
+func (b InvalidateReqBuilder) WithAddress(address uint64) InvalidateReqBuilder {
+	b.address = address
+	return b
+}
+
+// WithPID sets the PID of the cache line to invalidate.
+func (b InvalidateReqBuilder) WithPID(pid vm.PID) InvalidateReqBuilder {
+	b.pid = pid
+	return b
+}
+
+// Build creates a new InvalidateReq.
+func (b InvalidateReqBuilder) Build() *InvalidateReq {
+	r := &InvalidateReq{}
+	r.ID = sim.GetIDGenerator().Generate()
+	r.Src = b.src
+	r.Dst = b.dst
+	r.Address = b.address
+	r.PID = b.pid
+
+	return r
+}
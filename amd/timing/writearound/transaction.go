@@ -0,0 +1,62 @@
+package writearound
+
+import (
+	"github.com/sarchlab/akita/v4/mem/cache"
+	"github.com/sarchlab/akita/v4/mem/mem"
+	"github.com/sarchlab/akita/v4/mem/vm"
+	"github.com/sarchlab/mgpusim/v4/amd/atomic"
+	"github.com/sarchlab/mgpusim/v4/amd/protocol"
+)
+
+type bankActionType int
+
+const (
+	bankActionInvalid bankActionType = iota
+	bankActionReadHit
+	bankActionWrite
+	bankActionWriteFetched
+)
+
+type transaction struct {
+	id string
+
+	read         *mem.ReadReq
+	readToBottom *mem.ReadReq
+
+	write         *mem.WriteReq
+	writeToBottom *mem.WriteReq
+
+	preCoalesceTransactions []*transaction
+
+	bankAction            bankActionType
+	block                 *cache.Block
+	data                  []byte
+	writeFetchedDirtyMask []bool
+
+	fetchAndWrite bool
+	done          bool
+}
+
+func (t *transaction) Address() uint64 {
+	if t.read != nil {
+		return t.read.Address
+	}
+
+	return t.write.Address
+}
+
+func (t *transaction) PID() vm.PID {
+	if t.read != nil {
+		return t.read.PID
+	}
+
+	return t.write.PID
+}
+
+func (t *transaction) isAtomic() bool {
+	return t.read != nil && atomic.InfoOf(t.read) != nil
+}
+
+func (t *transaction) isStreamingStore() bool {
+	return t.write != nil && protocol.IsStreamingStore(t.write)
+}
@@ -0,0 +1,447 @@
+package writeback
+
+import (
+	"log"
+
+	"github.com/sarchlab/akita/v4/mem/cache"
+	"github.com/sarchlab/akita/v4/mem/mem"
+	"github.com/sarchlab/akita/v4/pipelining"
+	"github.com/sarchlab/akita/v4/sim"
+	"github.com/sarchlab/akita/v4/tracing"
+)
+
+type bankStage struct {
+	cache  *Comp
+	bankID int
+
+	pipeline           pipelining.Pipeline
+	pipelineWidth      int
+	postPipelineBuf    *bufferImpl
+	inflightTransCount int
+
+	// Count the trans that needs to be sent to the write buffer.
+	downwardInflightTransCount int
+}
+
+type bufferImpl struct {
+	sim.HookableBase
+
+	name     string
+	capacity int
+	elements []interface{}
+}
+
+func (b *bufferImpl) Name() string {
+	return b.name
+}
+
+func (b *bufferImpl) CanPush() bool {
+	return len(b.elements) < b.capacity
+}
+
+func (b *bufferImpl) Push(e interface{}) {
+	if len(b.elements) >= b.capacity {
+		log.Panic("buffer overflow")
+	}
+
+	b.elements = append(b.elements, e)
+
+	if b.NumHooks() > 0 {
+		b.InvokeHook(sim.HookCtx{
+			Domain: b,
+			Pos:    sim.HookPosBufPush,
+			Item:   e,
+			Detail: nil,
+		})
+	}
+}
+
+func (b *bufferImpl) Pop() interface{} {
+	if len(b.elements) == 0 {
+		return nil
+	}
+
+	e := b.elements[0]
+	b.elements = b.elements[1:]
+
+	if b.NumHooks() > 0 {
+		b.InvokeHook(sim.HookCtx{
+			Domain: b,
+			Pos:    sim.HookPosBufPush,
+			Item:   e,
+			Detail: nil,
+		})
+	}
+
+	return e
+}
+
+func (b *bufferImpl) Peek() interface{} {
+	if len(b.elements) == 0 {
+		return nil
+	}
+
+	return b.elements[0]
+}
+
+func (b *bufferImpl) Capacity() int {
+	return b.capacity
+}
+
+func (b *bufferImpl) Size() int {
+	return len(b.elements)
+}
+
+func (b *bufferImpl) Clear() {
+	b.elements = nil
+}
+
+func (b *bufferImpl) Get(i int) interface{} {
+	return b.elements[i]
+}
+
+func (b *bufferImpl) Remove(i int) {
+	element := b.elements[i]
+
+	b.elements = append(b.elements[:i], b.elements[i+1:]...)
+
+	if b.NumHooks() > 0 {
+		b.InvokeHook(sim.HookCtx{
+			Domain: b,
+			Pos:    sim.HookPosBufPush,
+			Item:   element,
+			Detail: nil,
+		})
+	}
+}
+
+type bankPipelineElem struct {
+	trans *transaction
+}
+
+func (e bankPipelineElem) TaskID() string {
+	return e.trans.req().Meta().ID + "_write_back_bank_pipeline"
+}
+
+func (s *bankStage) Tick() (madeProgress bool) {
+	for i := 0; i < s.cache.numReqPerCycle; i++ {
+		madeProgress = s.finalizeTrans() || madeProgress
+	}
+
+	madeProgress = s.pipeline.Tick() || madeProgress
+
+	for i := 0; i < s.cache.numReqPerCycle; i++ {
+		madeProgress = s.pullFromBuf() || madeProgress
+	}
+
+	return madeProgress
+}
+
+func (s *bankStage) Reset() {
+	s.cache.dirToBankBuffers[s.bankID].Clear()
+	s.pipeline.Clear()
+	s.postPipelineBuf.Clear()
+	s.inflightTransCount = 0
+}
+
+func (s *bankStage) pullFromBuf() bool {
+	if !s.pipeline.CanAccept() {
+		return false
+	}
+
+	inBuf := s.cache.writeBufferToBankBuffers[s.bankID]
+
+	trans := inBuf.Pop()
+	if trans != nil {
+		s.pipeline.Accept(bankPipelineElem{trans: trans.(*transaction)})
+
+		s.inflightTransCount++
+
+		return true
+	}
+
+	// Do not jam the writeBufferBuffer
+	if !s.cache.writeBufferBuffer.CanPush() {
+		return false
+	}
+
+	// Always reserve one lane for up-going transactions
+	if s.downwardInflightTransCount >= s.pipelineWidth-1 {
+		return false
+	}
+
+	inBuf = s.cache.dirToBankBuffers[s.bankID]
+	trans = inBuf.Pop()
+
+	if trans != nil {
+		t := trans.(*transaction)
+
+		if t.action == writeBufferFetch {
+			s.cache.writeBufferBuffer.Push(trans)
+			return true
+		}
+
+		s.pipeline.Accept(bankPipelineElem{trans: trans.(*transaction)})
+
+		s.inflightTransCount++
+
+		switch t.action {
+		case bankEvict, bankEvictAndFetch, bankEvictAndWrite:
+			s.downwardInflightTransCount++
+		}
+
+		return true
+	}
+
+	return false
+}
+
+func (s *bankStage) finalizeTrans() bool {
+	for i := 0; i < s.postPipelineBuf.Size(); i++ {
+		trans := s.postPipelineBuf.Get(i).(bankPipelineElem).trans
+
+		done := false
+
+		switch trans.action {
+		case bankReadHit:
+			done = s.finalizeReadHit(trans)
+		case bankWriteHit:
+			done = s.finalizeWriteHit(trans)
+		case bankAtomicHit:
+			done = s.finalizeAtomicHit(trans)
+		case bankWriteFetched:
+			done = s.finalizeBankWriteFetched(trans)
+		case bankEvictAndFetch, bankEvictAndWrite, bankEvict:
+			done = s.finalizeBankEviction(trans)
+		default:
+			panic("bank action not supported")
+		}
+
+		if done {
+			s.postPipelineBuf.Remove(i)
+
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s *bankStage) finalizeReadHit(trans *transaction) bool {
+	if !s.cache.topPort.CanSend() {
+		return false
+	}
+
+	read := trans.read
+	addr := read.Address
+	_, offset := getCacheLineID(addr, s.cache.log2BlockSize)
+	block := trans.block
+
+	data, err := s.cache.storage.Read(
+		block.CacheAddress+offset, read.AccessByteSize)
+	if err != nil {
+		panic(err)
+	}
+
+	s.removeTransaction(trans)
+
+	s.inflightTransCount--
+	s.downwardInflightTransCount--
+	block.ReadCount--
+
+	dataReady := mem.DataReadyRspBuilder{}.
+		WithSrc(s.cache.topPort.AsRemote()).
+		WithDst(read.Src).
+		WithRspTo(read.ID).
+		WithData(data).
+		Build()
+	s.cache.topPort.Send(dataReady)
+
+	tracing.TraceReqComplete(read, s.cache)
+
+	return true
+}
+
+func (s *bankStage) finalizeWriteHit(trans *transaction) bool {
+	if !s.cache.topPort.CanSend() {
+		return false
+	}
+
+	write := trans.write
+	addr := write.Address
+	_, offset := getCacheLineID(addr, s.cache.log2BlockSize)
+	block := trans.block
+
+	dirtyMask := s.writeData(block, write, offset)
+
+	block.IsValid = true
+	block.IsLocked = false
+	block.IsDirty = true
+	block.DirtyMask = dirtyMask
+
+	s.removeTransaction(trans)
+
+	s.inflightTransCount--
+	s.downwardInflightTransCount--
+
+	done := mem.WriteDoneRspBuilder{}.
+		WithSrc(s.cache.topPort.AsRemote()).
+		WithDst(write.Src).
+		WithRspTo(write.ID).
+		Build()
+	s.cache.topPort.Send(done)
+
+	tracing.TraceReqComplete(write, s.cache)
+
+	return true
+}
+
+func (s *bankStage) finalizeAtomicHit(trans *transaction) bool {
+	if !s.cache.topPort.CanSend() {
+		return false
+	}
+
+	read := trans.read
+	_, offset := getCacheLineID(read.Address, s.cache.log2BlockSize)
+	block := trans.block
+
+	oldData, err := s.cache.storage.Read(
+		block.CacheAddress+offset, read.AccessByteSize)
+	if err != nil {
+		panic(err)
+	}
+
+	write := mem.WriteReqBuilder{}.
+		WithData(trans.atomicInfo().Apply(oldData)).
+		Build()
+	dirtyMask := s.writeData(block, write, offset)
+
+	block.IsValid = true
+	block.IsLocked = false
+	block.IsDirty = true
+	block.DirtyMask = dirtyMask
+
+	s.removeTransaction(trans)
+
+	s.inflightTransCount--
+	s.downwardInflightTransCount--
+
+	dataReady := mem.DataReadyRspBuilder{}.
+		WithSrc(s.cache.topPort.AsRemote()).
+		WithDst(read.Src).
+		WithRspTo(read.ID).
+		WithData(oldData).
+		Build()
+	s.cache.topPort.Send(dataReady)
+
+	tracing.TraceReqComplete(read, s.cache)
+
+	return true
+}
+
+func (s *bankStage) writeData(
+	block *cache.Block,
+	write *mem.WriteReq,
+	offset uint64,
+) []bool {
+	data, err := s.cache.storage.Read(
+		block.CacheAddress, 1<<s.cache.log2BlockSize)
+	if err != nil {
+		panic(err)
+	}
+
+	dirtyMask := block.DirtyMask
+	if dirtyMask == nil {
+		dirtyMask = make([]bool, 1<<s.cache.log2BlockSize)
+	}
+
+	for i := 0; i < len(write.Data); i++ {
+		if write.DirtyMask == nil || write.DirtyMask[i] {
+			index := offset + uint64(i)
+			data[index] = write.Data[i]
+			dirtyMask[index] = true
+		}
+	}
+
+	err = s.cache.storage.Write(block.CacheAddress, data)
+	if err != nil {
+		panic(err)
+	}
+
+	return dirtyMask
+}
+
+func (s *bankStage) finalizeBankWriteFetched(
+	trans *transaction,
+) bool {
+	if !s.cache.mshrStageBuffer.CanPush() {
+		return false
+	}
+
+	mshrEntry := trans.mshrEntry
+	block := mshrEntry.Block
+	s.cache.mshrStageBuffer.Push(mshrEntry)
+
+	err := s.cache.storage.Write(block.CacheAddress, mshrEntry.Data)
+	if err != nil {
+		panic(err)
+	}
+
+	block.IsLocked = false
+	block.IsValid = true
+
+	s.inflightTransCount--
+
+	return true
+}
+
+func (s *bankStage) removeTransaction(trans *transaction) {
+	for i, t := range s.cache.inFlightTransactions {
+		if trans == t {
+			s.cache.inFlightTransactions = append(
+				(s.cache.inFlightTransactions)[:i],
+				(s.cache.inFlightTransactions)[i+1:]...)
+
+			return
+		}
+	}
+
+	log.Panicf("%s: transaction %s not found", s.cache.Name(), trans.id)
+}
+
+func (s *bankStage) finalizeBankEviction(
+	trans *transaction,
+) bool {
+	if !s.cache.writeBufferBuffer.CanPush() {
+		return false
+	}
+
+	victim := trans.victim
+
+	data, err := s.cache.storage.Read(
+		victim.CacheAddress, 1<<s.cache.log2BlockSize)
+	if err != nil {
+		panic(err)
+	}
+
+	trans.evictingData = data
+
+	switch trans.action {
+	case bankEvict:
+		trans.action = writeBufferFlush
+	case bankEvictAndFetch:
+		trans.action = writeBufferEvictAndFetch
+	case bankEvictAndWrite:
+		trans.action = writeBufferEvictAndWrite
+	default:
+		panic("unsupported action")
+	}
+
+	delete(s.cache.evictingList, trans.evictingAddr)
+	s.cache.writeBufferBuffer.Push(trans)
+
+	s.inflightTransCount--
+	s.downwardInflightTransCount--
+
+	return true
+}
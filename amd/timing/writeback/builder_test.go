@@ -0,0 +1,18 @@
+package writeback
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/sarchlab/akita/v4/sim"
+)
+
+var _ = Describe("Builder", func() {
+	It("should build a write buffer of the given size", func() {
+		c := MakeBuilder().
+			WithEngine(sim.NewSerialEngine()).
+			WithWriteBufferSize(4).
+			Build("Cache")
+
+		Expect(c.writeBuffer.writeBufferCapacity).To(Equal(4))
+	})
+})
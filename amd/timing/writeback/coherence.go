@@ -0,0 +1,143 @@
+package writeback
+
+import (
+	"github.com/sarchlab/akita/v4/mem/mem"
+	"github.com/sarchlab/akita/v4/mem/vm"
+	"github.com/sarchlab/akita/v4/sim"
+	"github.com/sarchlab/mgpusim/v4/amd/atomic"
+	"github.com/sarchlab/mgpusim/v4/amd/timing/writearound"
+)
+
+// A coherenceDirectory tracks which of the high-level caches hold a copy of
+// each cache line. When a cache line is written, the directory invalidates the
+// copies held by all the other caches.
+//
+// The model is relaxed. The high-level caches do not acknowledge the
+// invalidations, so the write completes without waiting for the other caches
+// to drop their copies, and a cache can still return the old value until the
+// invalidation arrives. The high-level caches do not report their evictions
+// either. Instead, the directory only tracks the lines that the cache holds:
+// when the cache replaces a line, the directory invalidates all the copies of
+// the line, and a flush drops all the copies, as the command processor flushes
+// the high-level caches together with this cache.
+type coherenceDirectory struct {
+	cache *Comp
+
+	sharers     map[sim.RemotePort]bool
+	lineSharers map[uint64][]lineSharer
+	pending     []*writearound.InvalidateReq
+}
+
+// A lineSharer is a high-level cache that holds a copy of a cache line. The
+// high-level caches tag their blocks with the PID of the accesses, so the copy
+// can only be invalidated with the PID that it was read with.
+type lineSharer struct {
+	port sim.RemotePort
+	pid  vm.PID
+}
+
+func newCoherenceDirectory(
+	cache *Comp,
+	sharers []sim.RemotePort,
+) *coherenceDirectory {
+	d := &coherenceDirectory{
+		cache:       cache,
+		sharers:     make(map[sim.RemotePort]bool),
+		lineSharers: make(map[uint64][]lineSharer),
+	}
+
+	for _, s := range sharers {
+		d.sharers[s] = true
+	}
+
+	return d
+}
+
+// recordAccess updates the owners of the cache line that the request accesses.
+func (d *coherenceDirectory) recordAccess(req mem.AccessReq) {
+	src := req.Meta().Src
+	cacheLineID := req.GetAddress() >> d.cache.log2BlockSize <<
+		d.cache.log2BlockSize
+
+	read, isRead := req.(*mem.ReadReq)
+	if isRead && atomic.InfoOf(read) == nil {
+		d.addSharer(cacheLineID, lineSharer{port: src, pid: read.PID})
+		return
+	}
+
+	d.invalidateOthers(cacheLineID, src)
+}
+
+func (d *coherenceDirectory) addSharer(cacheLineID uint64, sharer lineSharer) {
+	if !d.sharers[sharer.port] {
+		return
+	}
+
+	for _, s := range d.lineSharers[cacheLineID] {
+		if s == sharer {
+			return
+		}
+	}
+
+	d.lineSharers[cacheLineID] = append(d.lineSharers[cacheLineID], sharer)
+}
+
+func (d *coherenceDirectory) invalidateOthers(
+	cacheLineID uint64,
+	src sim.RemotePort,
+) {
+	var remaining []lineSharer
+
+	for _, s := range d.lineSharers[cacheLineID] {
+		if s.port == src {
+			remaining = append(remaining, s)
+			continue
+		}
+
+		inv := writearound.InvalidateReqBuilder{}.
+			WithSrc(d.cache.topPort.AsRemote()).
+			WithDst(s.port).
+			WithAddress(cacheLineID).
+			WithPID(s.pid).
+			Build()
+		d.pending = append(d.pending, inv)
+	}
+
+	if len(remaining) == 0 {
+		delete(d.lineSharers, cacheLineID)
+		return
+	}
+
+	d.lineSharers[cacheLineID] = remaining
+}
+
+// lineReplaced invalidates all the copies of a cache line that the cache
+// replaces with another line.
+func (d *coherenceDirectory) lineReplaced(cacheLineID uint64) {
+	if _, found := d.lineSharers[cacheLineID]; !found {
+		return
+	}
+
+	d.invalidateOthers(cacheLineID, "")
+}
+
+// reset forgets all the copies once the cache is flushed.
+func (d *coherenceDirectory) reset() {
+	d.lineSharers = make(map[uint64][]lineSharer)
+}
+
+// Tick sends the invalidation requests to the high-level caches.
+func (d *coherenceDirectory) Tick() bool {
+	if len(d.pending) == 0 {
+		return false
+	}
+
+	err := d.cache.topPort.Send(d.pending[0])
+	if err != nil {
+		return false
+	}
+
+	d.pending = d.pending[1:]
+
+	return true
+}
@@ -0,0 +1,195 @@
+package writeback
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/sarchlab/akita/v4/mem/idealmemcontroller"
+	"github.com/sarchlab/akita/v4/mem/mem"
+	"github.com/sarchlab/akita/v4/sim"
+	"github.com/sarchlab/akita/v4/sim/directconnection"
+	"github.com/sarchlab/mgpusim/v4/amd/timing/writearound"
+)
+
+type coherenceStep struct {
+	agent int
+	addr  uint64
+	write bool
+	value uint32
+}
+
+// coherenceAgent accesses the memory through a number of L1 caches, one step
+// after another, and records the values that the reads return.
+type coherenceAgent struct {
+	*sim.TickingComponent
+
+	ports   []sim.Port
+	dsts    []sim.RemotePort
+	steps   []coherenceStep
+	reads   []uint32
+	waiting bool
+}
+
+func (a *coherenceAgent) Tick() bool {
+	if a.waiting {
+		msg := a.ports[a.steps[0].agent].RetrieveIncoming()
+		if msg == nil {
+			return false
+		}
+
+		if rsp, ok := msg.(*mem.DataReadyRsp); ok {
+			a.reads = append(a.reads, binary.LittleEndian.Uint32(rsp.Data))
+		}
+
+		a.waiting = false
+		a.steps = a.steps[1:]
+	}
+
+	if len(a.steps) == 0 {
+		return false
+	}
+
+	step := a.steps[0]
+	port := a.ports[step.agent]
+
+	var req sim.Msg
+	if step.write {
+		data := make([]byte, 4)
+		binary.LittleEndian.PutUint32(data, step.value)
+		req = mem.WriteReqBuilder{}.
+			WithSrc(port.AsRemote()).
+			WithDst(a.dsts[step.agent]).
+			WithAddress(step.addr).
+			WithData(data).
+			Build()
+	} else {
+		req = mem.ReadReqBuilder{}.
+			WithSrc(port.AsRemote()).
+			WithDst(a.dsts[step.agent]).
+			WithAddress(step.addr).
+			WithByteSize(4).
+			Build()
+	}
+
+	if port.Send(req) != nil {
+		return false
+	}
+
+	a.waiting = true
+
+	return true
+}
+
+var _ = Describe("Coherence Directory", func() {
+	const addr uint64 = 0x1000
+
+	// runSteps lets agent 0 and agent 1 access the memory through their own L1
+	// caches, which share an L2 cache, and returns the values that the reads
+	// return.
+	runSteps := func(
+		l2Builder Builder,
+		coherent bool,
+		steps []coherenceStep,
+	) ([]uint32, *Comp) {
+		engine := sim.NewSerialEngine()
+		conn := directconnection.MakeBuilder().
+			WithEngine(engine).
+			WithFreq(1 * sim.GHz).
+			Build("Connection")
+
+		dram := idealmemcontroller.MakeBuilder().
+			WithEngine(engine).
+			WithNewStorage(4 * mem.GB).
+			WithFreq(1 * sim.GHz).
+			WithLatency(100).
+			Build("DRAM")
+		conn.PlugIn(dram.GetPortByName("Top"))
+
+		agent := &coherenceAgent{steps: steps}
+		agent.TickingComponent = sim.NewTickingComponent(
+			"Agent", engine, 1*sim.GHz, agent)
+
+		var l1s []*writearound.Comp
+		var sharers []sim.RemotePort
+		for i := 0; i < 2; i++ {
+			l1 := writearound.NewBuilder().
+				WithEngine(engine).
+				Build(fmt.Sprintf("L1[%d]", i))
+			l1s = append(l1s, l1)
+			sharers = append(sharers, l1.GetPortByName("Bottom").AsRemote())
+
+			port := sim.NewPort(agent, 1, 1, fmt.Sprintf("Agent.Port[%d]", i))
+			agent.ports = append(agent.ports, port)
+			agent.dsts = append(agent.dsts, l1.GetPortByName("Top").AsRemote())
+
+			conn.PlugIn(port)
+			conn.PlugIn(l1.GetPortByName("Top"))
+			conn.PlugIn(l1.GetPortByName("Bottom"))
+		}
+
+		builder := l2Builder.
+			WithEngine(engine).
+			WithAddressToPortMapper(&mem.SinglePortMapper{
+				Port: dram.GetPortByName("Top").AsRemote(),
+			})
+		if coherent {
+			builder = builder.WithCoherenceDirectory(sharers)
+		}
+		l2 := builder.Build("L2")
+		conn.PlugIn(l2.topPort)
+		conn.PlugIn(l2.bottomPort)
+
+		for _, l1 := range l1s {
+			l1.SetAddressToPortMapper(&mem.SinglePortMapper{
+				Port: l2.topPort.AsRemote(),
+			})
+		}
+
+		agent.TickLater()
+		Expect(engine.Run()).To(Succeed())
+		Expect(agent.steps).To(BeEmpty())
+
+		return agent.reads, l2
+	}
+
+	// The consumer reads the address through one L1 cache, the producer
+	// writes 42 to the address through another L1 cache, and the consumer
+	// reads the address again.
+	producerConsumer := []coherenceStep{
+		{agent: 1, addr: addr},
+		{agent: 0, addr: addr, write: true, value: 42},
+		{agent: 1, addr: addr},
+	}
+
+	It("should return stale data without the directory", func() {
+		reads, _ := runSteps(MakeBuilder(), false, producerConsumer)
+		Expect(reads).To(Equal([]uint32{0, 0}))
+	})
+
+	It("should let the consumer see the store of the producer", func() {
+		reads, _ := runSteps(MakeBuilder(), true, producerConsumer)
+		Expect(reads).To(Equal([]uint32{0, 42}))
+	})
+
+	It("should only track the lines that the L2 cache holds", func() {
+		// With a single way, the other address replaces the line in the L2
+		// cache, but not in the L1 cache of the consumer.
+		const otherAddr = addr + 4096
+		l2Builder := MakeBuilder().
+			WithWayAssociativity(1).
+			WithByteSize(4096)
+
+		reads, l2 := runSteps(l2Builder, true, []coherenceStep{
+			{agent: 1, addr: addr},
+			{agent: 1, addr: otherAddr},
+			{agent: 0, addr: addr, write: true, value: 42},
+			{agent: 1, addr: addr},
+		})
+
+		Expect(reads).To(Equal([]uint32{0, 0, 42}))
+		Expect(l2.coherenceDirectory.lineSharers).To(HaveLen(1))
+		Expect(l2.coherenceDirectory.lineSharers).To(HaveKey(addr))
+	})
+})
@@ -0,0 +1,587 @@
+package writeback
+
+import (
+	"fmt"
+
+	"github.com/sarchlab/akita/v4/mem/cache"
+	"github.com/sarchlab/akita/v4/mem/mem"
+	"github.com/sarchlab/akita/v4/mem/vm"
+	"github.com/sarchlab/akita/v4/pipelining"
+	"github.com/sarchlab/akita/v4/sim"
+	"github.com/sarchlab/akita/v4/tracing"
+	"github.com/sarchlab/mgpusim/v4/amd/protocol"
+)
+
+type dirPipelineItem struct {
+	trans *transaction
+}
+
+func (i dirPipelineItem) TaskID() string {
+	return i.trans.id + "_dir_pipeline"
+}
+
+type directoryStage struct {
+	cache    *Comp
+	pipeline pipelining.Pipeline
+	buf      sim.Buffer
+}
+
+func (ds *directoryStage) Tick() (madeProgress bool) {
+	madeProgress = ds.acceptNewTransaction() || madeProgress
+
+	madeProgress = ds.pipeline.Tick() || madeProgress
+
+	madeProgress = ds.processTransaction() || madeProgress
+
+	return madeProgress
+}
+
+func (ds *directoryStage) processTransaction() bool {
+	madeProgress := false
+
+	for i := 0; i < ds.cache.numReqPerCycle; i++ {
+		item := ds.buf.Peek()
+		if item == nil {
+			break
+		}
+
+		trans := item.(dirPipelineItem).trans
+
+		addr := trans.accessReq().GetAddress()
+		cacheLineID, _ := getCacheLineID(addr, ds.cache.log2BlockSize)
+
+		if _, evicting := ds.cache.evictingList[cacheLineID]; evicting {
+			break
+		}
+
+		if trans.atomicInfo() != nil {
+			madeProgress = ds.doAtomic(trans) || madeProgress
+			continue
+		}
+
+		if trans.read != nil {
+			madeProgress = ds.doRead(trans) || madeProgress
+			continue
+		}
+
+		madeProgress = ds.doWrite(trans) || madeProgress
+	}
+
+	return madeProgress
+}
+
+func (ds *directoryStage) acceptNewTransaction() bool {
+	madeProgress := false
+
+	for i := 0; i < ds.cache.numReqPerCycle; i++ {
+		if !ds.pipeline.CanAccept() {
+			break
+		}
+
+		item := ds.cache.dirStageBuffer.Peek()
+		if item == nil {
+			break
+		}
+
+		trans := item.(*transaction)
+		ds.pipeline.Accept(dirPipelineItem{trans})
+		ds.cache.dirStageBuffer.Pop()
+
+		madeProgress = true
+	}
+
+	return madeProgress
+}
+
+func (ds *directoryStage) Reset() {
+	ds.pipeline.Clear()
+	ds.buf.Clear()
+	ds.cache.dirStageBuffer.Clear()
+}
+
+func (ds *directoryStage) doRead(trans *transaction) bool {
+	cachelineID, _ := getCacheLineID(
+		trans.read.Address, ds.cache.log2BlockSize)
+
+	mshrEntry := ds.cache.mshr.Query(blockPID, cachelineID)
+	if mshrEntry != nil {
+		return ds.handleReadMSHRHit(trans, mshrEntry)
+	}
+
+	block := ds.cache.directory.Lookup(blockPID, cachelineID)
+	if block != nil {
+		return ds.handleReadHit(trans, block)
+	}
+
+	return ds.handleReadMiss(trans)
+}
+
+func (ds *directoryStage) handleReadMSHRHit(
+	trans *transaction,
+	mshrEntry *cache.MSHREntry,
+) bool {
+	trans.mshrEntry = mshrEntry
+	mshrEntry.Requests = append(mshrEntry.Requests, trans)
+
+	ds.buf.Pop()
+
+	tracing.AddTaskStep(
+		tracing.MsgIDAtReceiver(trans.read, ds.cache),
+		ds.cache,
+		"read-mshr-hit",
+	)
+
+	return true
+}
+
+func (ds *directoryStage) handleReadHit(
+	trans *transaction,
+	block *cache.Block,
+) bool {
+	if block.IsLocked {
+		return false
+	}
+
+	tracing.AddTaskStep(
+		tracing.MsgIDAtReceiver(trans.read, ds.cache),
+		ds.cache,
+		"read-hit",
+	)
+
+	return ds.readFromBank(trans, block)
+}
+
+func (ds *directoryStage) handleReadMiss(trans *transaction) bool {
+	req := trans.read
+	cacheLineID, _ := getCacheLineID(req.Address, ds.cache.log2BlockSize)
+
+	if ds.cache.mshr.IsFull() {
+		return false
+	}
+
+	victim := ds.cache.findVictim(req.PID, cacheLineID)
+	if victim.IsLocked || victim.ReadCount > 0 {
+		return false
+	}
+
+	if ds.needEviction(victim) {
+		ok := ds.evict(trans, victim)
+		if ok {
+			tracing.AddTaskStep(
+				tracing.MsgIDAtReceiver(trans.read, ds.cache),
+				ds.cache,
+				"read-miss",
+			)
+		}
+
+		return ok
+	}
+
+	ok := ds.fetch(trans, victim)
+	if ok {
+		tracing.AddTaskStep(
+			tracing.MsgIDAtReceiver(trans.read, ds.cache),
+			ds.cache,
+			"read-miss",
+		)
+	}
+
+	return ok
+}
+
+// doAtomic performs an atomic access like a write to part of the line, as the
+// data before the operation must be available.
+func (ds *directoryStage) doAtomic(trans *transaction) bool {
+	read := trans.read
+	cachelineID, _ := getCacheLineID(read.Address, ds.cache.log2BlockSize)
+
+	mshrEntry := ds.cache.mshr.Query(blockPID, cachelineID)
+	if mshrEntry != nil {
+		ok := ds.doWriteMSHRHit(trans, mshrEntry)
+		tracing.AddTaskStep(
+			tracing.MsgIDAtReceiver(read, ds.cache),
+			ds.cache,
+			"atomic-mshr-hit",
+		)
+
+		return ok
+	}
+
+	block := ds.cache.directory.Lookup(blockPID, cachelineID)
+	if block != nil {
+		ok := ds.doWriteHit(trans, block)
+		if ok {
+			tracing.AddTaskStep(
+				tracing.MsgIDAtReceiver(read, ds.cache),
+				ds.cache,
+				"atomic-hit",
+			)
+		}
+
+		return ok
+	}
+
+	ok := ds.writePartialLineMiss(trans)
+	if ok {
+		tracing.AddTaskStep(
+			tracing.MsgIDAtReceiver(read, ds.cache),
+			ds.cache,
+			"atomic-miss",
+		)
+	}
+
+	return ok
+}
+
+func (ds *directoryStage) doWrite(trans *transaction) bool {
+	write := trans.write
+	cachelineID, _ := getCacheLineID(write.Address, ds.cache.log2BlockSize)
+
+	mshrEntry := ds.cache.mshr.Query(blockPID, cachelineID)
+	if mshrEntry != nil {
+		ok := ds.doWriteMSHRHit(trans, mshrEntry)
+		tracing.AddTaskStep(
+			tracing.MsgIDAtReceiver(trans.write, ds.cache),
+			ds.cache,
+			"write-mshr-hit",
+		)
+
+		return ok
+	}
+
+	block := ds.cache.directory.Lookup(blockPID, cachelineID)
+	if block != nil {
+		ok := ds.doWriteHit(trans, block)
+		if ok {
+			tracing.AddTaskStep(
+				tracing.MsgIDAtReceiver(trans.write, ds.cache),
+				ds.cache,
+				"write-hit",
+			)
+		}
+
+		return ok
+	}
+
+	if protocol.IsStreamingStore(write) {
+		ok := ds.bypass(trans)
+		if ok {
+			tracing.AddTaskStep(
+				tracing.MsgIDAtReceiver(trans.write, ds.cache),
+				ds.cache,
+				"write-bypass",
+			)
+		}
+
+		return ok
+	}
+
+	ok := ds.doWriteMiss(trans)
+	if ok {
+		tracing.AddTaskStep(
+			tracing.MsgIDAtReceiver(trans.write, ds.cache),
+			ds.cache,
+			"write-miss",
+		)
+	}
+
+	return ok
+}
+
+// bypass sends a streaming store that misses in the cache to the write buffer,
+// which writes it to the low-level module without allocating a line.
+func (ds *directoryStage) bypass(trans *transaction) bool {
+	if !ds.cache.writeBufferBuffer.CanPush() {
+		return false
+	}
+
+	trans.action = writeBufferBypass
+
+	ds.buf.Pop()
+	ds.cache.writeBufferBuffer.Push(trans)
+
+	return true
+}
+
+func (ds *directoryStage) doWriteMSHRHit(
+	trans *transaction,
+	mshrEntry *cache.MSHREntry,
+) bool {
+	trans.mshrEntry = mshrEntry
+	mshrEntry.Requests = append(mshrEntry.Requests, trans)
+
+	ds.buf.Pop()
+
+	return true
+}
+
+func (ds *directoryStage) doWriteHit(
+	trans *transaction,
+	block *cache.Block,
+) bool {
+	if block.IsLocked || block.ReadCount > 0 {
+		return false
+	}
+
+	return ds.writeToBank(trans, block)
+}
+
+func (ds *directoryStage) doWriteMiss(trans *transaction) bool {
+	write := trans.write
+
+	if ds.isWritingFullLine(write) {
+		return ds.writeFullLineMiss(trans)
+	}
+
+	return ds.writePartialLineMiss(trans)
+}
+
+func (ds *directoryStage) writeFullLineMiss(trans *transaction) bool {
+	write := trans.write
+	cachelineID, _ := getCacheLineID(write.Address, ds.cache.log2BlockSize)
+
+	victim := ds.cache.findVictim(write.PID, cachelineID)
+	if victim.IsLocked || victim.ReadCount > 0 {
+		return false
+	}
+
+	if ds.needEviction(victim) {
+		return ds.evict(trans, victim)
+	}
+
+	return ds.writeToBank(trans, victim)
+}
+
+func (ds *directoryStage) writePartialLineMiss(trans *transaction) bool {
+	cachelineID, _ := getCacheLineID(
+		trans.accessReq().GetAddress(), ds.cache.log2BlockSize)
+
+	if ds.cache.mshr.IsFull() {
+		return false
+	}
+
+	victim := ds.cache.findVictim(trans.accessReq().GetPID(), cachelineID)
+	if victim.IsLocked || victim.ReadCount > 0 {
+		return false
+	}
+
+	if ds.needEviction(victim) {
+		return ds.evict(trans, victim)
+	}
+
+	return ds.fetch(trans, victim)
+}
+
+func (ds *directoryStage) readFromBank(
+	trans *transaction,
+	block *cache.Block,
+) bool {
+	numBanks := len(ds.cache.dirToBankBuffers)
+	bank := bankID(block, ds.cache.directory.WayAssociativity(), numBanks)
+	bankBuf := ds.cache.dirToBankBuffers[bank]
+
+	if !bankBuf.CanPush() {
+		return false
+	}
+
+	ds.cache.directory.Visit(block)
+
+	block.ReadCount++
+	trans.block = block
+	trans.action = bankReadHit
+
+	ds.buf.Pop()
+	bankBuf.Push(trans)
+
+	return true
+}
+
+func (ds *directoryStage) writeToBank(
+	trans *transaction,
+	block *cache.Block,
+) bool {
+	numBanks := len(ds.cache.dirToBankBuffers)
+	bank := bankID(block, ds.cache.directory.WayAssociativity(), numBanks)
+	bankBuf := ds.cache.dirToBankBuffers[bank]
+
+	if !bankBuf.CanPush() {
+		return false
+	}
+
+	req := trans.accessReq()
+	cachelineID, _ := getCacheLineID(req.GetAddress(), ds.cache.log2BlockSize)
+
+	if !block.IsValid || block.Tag != cachelineID {
+		ds.cache.blockReplaced(block)
+		ds.cache.victimFinder.blockFilled(block)
+	}
+
+	ds.cache.directory.Visit(block)
+	block.IsLocked = true
+	block.Tag = cachelineID
+	block.IsValid = true
+	block.PID = blockPID
+	ds.cache.setOwner(block, req.GetPID())
+	trans.block = block
+	trans.action = bankWriteHit
+
+	if trans.atomicInfo() != nil {
+		trans.action = bankAtomicHit
+	}
+
+	ds.buf.Pop()
+	bankBuf.Push(trans)
+
+	return true
+}
+
+func (ds *directoryStage) evict(
+	trans *transaction,
+	victim *cache.Block,
+) bool {
+	bankNum := bankID(victim,
+		ds.cache.directory.WayAssociativity(), len(ds.cache.dirToBankBuffers))
+	bankBuf := ds.cache.dirToBankBuffers[bankNum]
+
+	if !bankBuf.CanPush() {
+		return false
+	}
+
+	req := trans.accessReq()
+	cacheLineID, _ := getCacheLineID(req.GetAddress(), ds.cache.log2BlockSize)
+
+	ds.updateTransForEviction(trans, victim, blockPID, cacheLineID)
+	ds.cache.blockReplaced(victim)
+	ds.updateVictimBlockMetaData(victim, cacheLineID, blockPID)
+	ds.cache.setOwner(victim, req.GetPID())
+
+	ds.buf.Pop()
+	bankBuf.Push(trans)
+
+	ds.cache.evictingList[trans.victim.Tag] = true
+
+	return true
+}
+
+func (ds *directoryStage) updateVictimBlockMetaData(
+	victim *cache.Block,
+	cacheLineID uint64,
+	pid vm.PID,
+) {
+	victim.Tag = cacheLineID
+	victim.PID = pid
+	victim.IsLocked = true
+	victim.IsDirty = false
+	ds.cache.directory.Visit(victim)
+	ds.cache.victimFinder.blockFilled(victim)
+}
+
+func (ds *directoryStage) updateTransForEviction(
+	trans *transaction,
+	victim *cache.Block,
+	pid vm.PID,
+	cacheLineID uint64,
+) {
+	trans.action = bankEvictAndFetch
+	trans.victim = &cache.Block{
+		PID:          victim.PID,
+		Tag:          victim.Tag,
+		CacheAddress: victim.CacheAddress,
+		DirtyMask:    victim.DirtyMask,
+	}
+	trans.block = victim
+	trans.evictingPID = trans.victim.PID
+	trans.evictingAddr = trans.victim.Tag
+	trans.evictingDirtyMask = victim.DirtyMask
+
+	if ds.evictionNeedFetch(trans) {
+		mshrEntry := ds.cache.mshr.Add(pid, cacheLineID)
+		mshrEntry.Block = victim
+		mshrEntry.Requests = append(mshrEntry.Requests, trans)
+		trans.mshrEntry = mshrEntry
+		trans.fetchPID = pid
+		trans.fetchAddress = cacheLineID
+		trans.action = bankEvictAndFetch
+	} else {
+		trans.action = bankEvictAndWrite
+	}
+}
+
+func (ds *directoryStage) evictionNeedFetch(t *transaction) bool {
+	if t.write == nil {
+		return true
+	}
+
+	if ds.isWritingFullLine(t.write) {
+		return false
+	}
+
+	return true
+}
+
+func (ds *directoryStage) fetch(
+	trans *transaction,
+	block *cache.Block,
+) bool {
+	req := trans.accessReq()
+	pid := blockPID
+	cacheLineID, _ := getCacheLineID(req.GetAddress(), ds.cache.log2BlockSize)
+
+	bankNum := bankID(block,
+		ds.cache.directory.WayAssociativity(), len(ds.cache.dirToBankBuffers))
+	bankBuf := ds.cache.dirToBankBuffers[bankNum]
+
+	if !bankBuf.CanPush() {
+		return false
+	}
+
+	mshrEntry := ds.cache.mshr.Add(pid, cacheLineID)
+	trans.mshrEntry = mshrEntry
+	trans.block = block
+	ds.cache.blockReplaced(block)
+	block.IsLocked = true
+	block.Tag = cacheLineID
+	block.PID = pid
+	block.IsValid = true
+	ds.cache.setOwner(block, req.GetPID())
+	ds.cache.directory.Visit(block)
+	ds.cache.victimFinder.blockFilled(block)
+
+	tracing.AddTaskStep(
+		tracing.MsgIDAtReceiver(req, ds.cache),
+		ds.cache,
+		fmt.Sprintf("add-mshr-entry-0x%x-0x%x", mshrEntry.Address, block.Tag),
+	)
+
+	ds.buf.Pop()
+
+	trans.action = writeBufferFetch
+	trans.fetchPID = pid
+	trans.fetchAddress = cacheLineID
+	bankBuf.Push(trans)
+
+	mshrEntry.Block = block
+	mshrEntry.Requests = append(mshrEntry.Requests, trans)
+
+	return true
+}
+
+func (ds *directoryStage) isWritingFullLine(write *mem.WriteReq) bool {
+	if len(write.Data) != (1 << ds.cache.log2BlockSize) {
+		return false
+	}
+
+	if write.DirtyMask != nil {
+		for _, dirty := range write.DirtyMask {
+			if !dirty {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+func (ds *directoryStage) needEviction(victim *cache.Block) bool {
+	return victim.IsValid && victim.IsDirty
+}
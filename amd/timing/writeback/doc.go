@@ -0,0 +1,7 @@
+// Package writeback implements a writeback cache.
+//
+// The package is derived from the writeback cache in Akita. It additionally
+// accepts a victim finder, so that the replacement policy can be configured.
+// A streaming store that misses in the cache is written to the low-level
+// module without allocating a line.
+package writeback
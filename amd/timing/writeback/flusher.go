@@ -0,0 +1,215 @@
+package writeback
+
+import (
+	"log"
+	"reflect"
+
+	"github.com/sarchlab/akita/v4/mem/cache"
+	"github.com/sarchlab/akita/v4/tracing"
+)
+
+type flusher struct {
+	cache *Comp
+
+	blockToEvict    []*cache.Block
+	processingFlush *cache.FlushReq
+}
+
+func (f *flusher) Tick() bool {
+	if f.processingFlush != nil && f.cache.state == cacheStatePreFlushing {
+		return f.processPreFlushing()
+	}
+
+	madeProgress := false
+	if f.processingFlush != nil && f.cache.state == cacheStateFlushing {
+		madeProgress = f.finalizeFlushing() || madeProgress
+		madeProgress = f.processFlush() || madeProgress
+
+		return madeProgress
+	}
+
+	return f.extractFromPort()
+}
+
+func (f *flusher) processPreFlushing() bool {
+	if f.existInflightTransaction() {
+		return false
+	}
+
+	f.prepareBlockToFlushList()
+	f.cache.state = cacheStateFlushing
+
+	return true
+}
+
+func (f *flusher) existInflightTransaction() bool {
+	return len(f.cache.inFlightTransactions) > 0
+}
+
+func (f *flusher) prepareBlockToFlushList() {
+	sets := f.cache.directory.GetSets()
+	for _, set := range sets {
+		for _, block := range set.Blocks {
+			if block.ReadCount > 0 || block.IsLocked {
+				panic("all the blocks should be unlocked before flushing")
+			}
+
+			if block.IsValid && block.IsDirty {
+				f.blockToEvict = append(f.blockToEvict, block)
+			}
+		}
+	}
+}
+
+func (f *flusher) processFlush() bool {
+	if len(f.blockToEvict) == 0 {
+		return false
+	}
+
+	block := f.blockToEvict[0]
+	bankNum := bankID(
+		block,
+		f.cache.directory.WayAssociativity(),
+		len(f.cache.dirToBankBuffers))
+	bankBuf := f.cache.dirToBankBuffers[bankNum]
+
+	if !bankBuf.CanPush() {
+		return false
+	}
+
+	trans := &transaction{
+		flush:             f.processingFlush,
+		victim:            block,
+		action:            bankEvict,
+		evictingAddr:      block.Tag,
+		evictingDirtyMask: block.DirtyMask,
+	}
+	bankBuf.Push(trans)
+
+	f.blockToEvict = f.blockToEvict[1:]
+
+	return true
+}
+
+func (f *flusher) extractFromPort() bool {
+	item := f.cache.controlPort.PeekIncoming()
+	if item == nil {
+		return false
+	}
+
+	switch req := item.(type) {
+	case *cache.FlushReq:
+		return f.startProcessingFlush(req)
+	case *cache.RestartReq:
+		return f.handleCacheRestart(req)
+	default:
+		log.Panicf("Cannot process request of %s", reflect.TypeOf(req))
+	}
+
+	return true
+}
+
+func (f *flusher) startProcessingFlush(
+	req *cache.FlushReq,
+) bool {
+	f.processingFlush = req
+	if req.DiscardInflight {
+		f.cache.discardInflightTransactions()
+	}
+
+	f.cache.state = cacheStatePreFlushing
+	f.cache.controlPort.RetrieveIncoming()
+
+	tracing.TraceReqReceive(req, f.cache)
+
+	return true
+}
+
+func (f *flusher) handleCacheRestart(
+	req *cache.RestartReq,
+) bool {
+	if !f.cache.controlPort.CanSend() {
+		return false
+	}
+
+	clearPort(f.cache.topPort)
+	clearPort(f.cache.bottomPort)
+
+	f.cache.state = cacheStateRunning
+
+	rsp := cache.RestartRspBuilder{}.
+		WithSrc(f.cache.controlPort.AsRemote()).
+		WithDst(req.Src).
+		WithRspTo(req.ID).
+		Build()
+	f.cache.controlPort.Send(rsp)
+
+	f.cache.controlPort.RetrieveIncoming()
+
+	return true
+}
+
+func (f *flusher) finalizeFlushing() bool {
+	if len(f.blockToEvict) > 0 {
+		return false
+	}
+
+	if !f.flushCompleted() {
+		return false
+	}
+
+	if !f.cache.controlPort.CanSend() {
+		return false
+	}
+
+	rsp := cache.FlushRspBuilder{}.
+		WithSrc(f.cache.controlPort.AsRemote()).
+		WithDst(f.processingFlush.Src).
+		WithRspTo(f.processingFlush.ID).
+		Build()
+	f.cache.controlPort.Send(rsp)
+
+	f.cache.mshr.Reset()
+	f.cache.directory.Reset()
+
+	if f.cache.coherenceDirectory != nil {
+		f.cache.coherenceDirectory.reset()
+	}
+
+	if f.processingFlush.PauseAfterFlushing {
+		f.cache.state = cacheStatePaused
+	} else {
+		f.cache.state = cacheStateRunning
+	}
+
+	tracing.TraceReqComplete(f.processingFlush, f.cache)
+	f.processingFlush = nil
+
+	return true
+}
+
+func (f *flusher) flushCompleted() bool {
+	for _, b := range f.cache.dirToBankBuffers {
+		if b.Size() > 0 {
+			return false
+		}
+	}
+
+	for _, b := range f.cache.bankStages {
+		if b.inflightTransCount > 0 {
+			return false
+		}
+	}
+
+	if f.cache.writeBufferBuffer.Size() > 0 {
+		return false
+	}
+
+	if len(f.cache.writeBuffer.inflightFetch) > 0 ||
+		len(f.cache.writeBuffer.inflightEviction) > 0 ||
+		len(f.cache.writeBuffer.pendingEvictions) > 0 {
+		return false
+	}
+
+	return true
+}
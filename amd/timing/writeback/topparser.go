@@ -0,0 +1,50 @@
+package writeback
+
+import (
+	"github.com/sarchlab/akita/v4/mem/mem"
+	"github.com/sarchlab/akita/v4/sim"
+	"github.com/sarchlab/akita/v4/tracing"
+)
+
+type topParser struct {
+	cache *Comp
+}
+
+func (p *topParser) Tick() bool {
+	if p.cache.state != cacheStateRunning {
+		return false
+	}
+
+	req := p.cache.topPort.PeekIncoming()
+	if req == nil {
+		return false
+	}
+
+	if !p.cache.dirStageBuffer.CanPush() {
+		return false
+	}
+
+	trans := &transaction{
+		id: sim.GetIDGenerator().Generate(),
+	}
+	if p.cache.coherenceDirectory != nil {
+		p.cache.coherenceDirectory.recordAccess(req.(mem.AccessReq))
+	}
+
+	switch req := req.(type) {
+	case *mem.ReadReq:
+		trans.read = req
+	case *mem.WriteReq:
+		trans.write = req
+	}
+
+	p.cache.dirStageBuffer.Push(trans)
+
+	p.cache.inFlightTransactions = append(p.cache.inFlightTransactions, trans)
+
+	tracing.TraceReqReceive(req, p.cache)
+
+	p.cache.topPort.RetrieveIncoming()
+
+	return true
+}
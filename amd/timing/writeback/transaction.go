@@ -0,0 +1,80 @@
+package writeback
+
+import (
+	"github.com/sarchlab/akita/v4/mem/cache"
+	"github.com/sarchlab/akita/v4/mem/mem"
+	"github.com/sarchlab/akita/v4/mem/vm"
+	"github.com/sarchlab/akita/v4/sim"
+	"github.com/sarchlab/mgpusim/v4/amd/atomic"
+)
+
+type action int
+
+const (
+	actionInvalid action = iota
+	bankReadHit
+	bankWriteHit
+	bankAtomicHit
+	bankEvict
+	bankEvictAndWrite
+	bankEvictAndFetch
+	bankWriteFetched
+	writeBufferFetch
+	writeBufferEvictAndFetch
+	writeBufferEvictAndWrite
+	writeBufferFlush
+	writeBufferBypass
+)
+
+type transaction struct {
+	action
+	id                string
+	read              *mem.ReadReq
+	write             *mem.WriteReq
+	flush             *cache.FlushReq
+	block             *cache.Block
+	victim            *cache.Block
+	fetchPID          vm.PID
+	fetchAddress      uint64
+	fetchedData       []byte
+	fetchReadReq      *mem.ReadReq
+	evictingPID       vm.PID
+	evictingAddr      uint64
+	evictingData      []byte
+	evictingDirtyMask []bool
+	evictionWriteReq  *mem.WriteReq
+	mshrEntry         *cache.MSHREntry
+	atomicOldData     []byte
+}
+
+func (t transaction) accessReq() mem.AccessReq {
+	if t.read != nil {
+		return t.read
+	}
+
+	if t.write != nil {
+		return t.write
+	}
+
+	return nil
+}
+
+func (t transaction) atomicInfo() *atomic.Info {
+	if t.read == nil {
+		return nil
+	}
+
+	return atomic.InfoOf(t.read)
+}
+
+func (t transaction) req() sim.Msg {
+	if t.accessReq() != nil {
+		return t.accessReq()
+	}
+
+	if t.flush != nil {
+		return t.flush
+	}
+
+	return nil
+}
@@ -0,0 +1,109 @@
+package writeback
+
+import (
+	"log"
+	"sort"
+	"sync"
+
+	"github.com/sarchlab/akita/v4/mem/cache"
+	"github.com/sarchlab/akita/v4/mem/vm"
+)
+
+// A FillObserver is a victim finder that needs to know when a block is filled
+// with a new cache line, such as a victim finder that evicts the block that is
+// filled the earliest. The cache reports the fills to the victim finders that
+// implement the interface.
+type FillObserver interface {
+	BlockFilled(block *cache.Block)
+}
+
+// wayRange is the range of ways, [first, end), that a process can fill.
+type wayRange struct {
+	first, end int
+}
+
+// wayPartitionVictimFinder limits the victims of the processes to the ways
+// that are assigned to them. The cache sets the PID of the access before
+// looking for a victim, since the directory does not pass the PID to the
+// victim finder.
+type wayPartitionVictimFinder struct {
+	cache.VictimFinder
+
+	lock       sync.RWMutex
+	partitions map[vm.PID]wayRange
+	pid        vm.PID
+}
+
+func newWayPartitionVictimFinder(
+	victimFinder cache.VictimFinder,
+) *wayPartitionVictimFinder {
+	return &wayPartitionVictimFinder{
+		VictimFinder: victimFinder,
+	}
+}
+
+// FindVictim returns a victim among the ways that the current process can
+// fill.
+func (f *wayPartitionVictimFinder) FindVictim(set *cache.Set) *cache.Block {
+	f.lock.RLock()
+	ways, partitioned := f.partitions[f.pid]
+	f.lock.RUnlock()
+
+	if !partitioned {
+		return f.VictimFinder.FindVictim(set)
+	}
+
+	partition := &cache.Set{}
+	for _, block := range set.Blocks {
+		if block.WayID >= ways.first && block.WayID < ways.end {
+			partition.Blocks = append(partition.Blocks, block)
+		}
+	}
+
+	for _, block := range set.LRUQueue {
+		if block.WayID >= ways.first && block.WayID < ways.end {
+			partition.LRUQueue = append(partition.LRUQueue, block)
+		}
+	}
+
+	return f.VictimFinder.FindVictim(partition)
+}
+
+// blockFilled reports a fill to the victim finder that the partitions wrap.
+func (f *wayPartitionVictimFinder) blockFilled(block *cache.Block) {
+	if observer, ok := f.VictimFinder.(FillObserver); ok {
+		observer.BlockFilled(block)
+	}
+}
+
+func (f *wayPartitionVictimFinder) setPartitions(
+	partitions map[vm.PID]int,
+	wayAssociativity int,
+) {
+	pids := make([]vm.PID, 0, len(partitions))
+	for pid := range partitions {
+		pids = append(pids, pid)
+	}
+	sort.Slice(pids, func(i, j int) bool { return pids[i] < pids[j] })
+
+	ranges := make(map[vm.PID]wayRange, len(partitions))
+	first := 0
+	for _, pid := range pids {
+		numWays := partitions[pid]
+		if numWays <= 0 {
+			log.Panicf("process %d must be given at least one way", pid)
+		}
+
+		ranges[pid] = wayRange{first: first, end: first + numWays}
+		first += numWays
+	}
+
+	if first > wayAssociativity {
+		log.Panicf("cannot partition %d ways, the cache only has %d ways",
+			first, wayAssociativity)
+	}
+
+	f.lock.Lock()
+	f.partitions = ranges
+	f.lock.Unlock()
+}
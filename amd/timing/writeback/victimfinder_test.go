@@ -0,0 +1,193 @@
+package writeback
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/sarchlab/akita/v4/mem/cache"
+	"github.com/sarchlab/akita/v4/mem/idealmemcontroller"
+	"github.com/sarchlab/akita/v4/mem/mem"
+	"github.com/sarchlab/akita/v4/mem/vm"
+	"github.com/sarchlab/akita/v4/sim"
+	"github.com/sarchlab/akita/v4/sim/directconnection"
+)
+
+// sequentialReader reads a list of addresses one after another. If pids is
+// not empty, each read is sent on behalf of the process at the same index.
+type sequentialReader struct {
+	*sim.TickingComponent
+
+	port    sim.Port
+	dst     sim.RemotePort
+	addrs   []uint64
+	pids    []vm.PID
+	waiting bool
+}
+
+func (r *sequentialReader) Tick() bool {
+	if r.waiting {
+		if r.port.RetrieveIncoming() == nil {
+			return false
+		}
+
+		r.waiting = false
+		r.addrs = r.addrs[1:]
+		if len(r.pids) > 0 {
+			r.pids = r.pids[1:]
+		}
+	}
+
+	if len(r.addrs) == 0 {
+		return false
+	}
+
+	builder := mem.ReadReqBuilder{}.
+		WithSrc(r.port.AsRemote()).
+		WithDst(r.dst).
+		WithAddress(r.addrs[0]).
+		WithByteSize(4)
+	if len(r.pids) > 0 {
+		builder = builder.WithPID(r.pids[0])
+	}
+
+	read := builder.Build()
+	if r.port.Send(read) != nil {
+		return false
+	}
+
+	r.waiting = true
+
+	return true
+}
+
+// runSequentialReads lets the reader read from a 4-way cache with a single set
+// and returns the cache. The ways of the cache are partitioned among the
+// processes if partitions is not nil.
+func runSequentialReads(
+	builder Builder,
+	partitions map[vm.PID]int,
+	reader *sequentialReader,
+) *Comp {
+	engine := sim.NewSerialEngine()
+
+	dram := idealmemcontroller.MakeBuilder().
+		WithEngine(engine).
+		WithNewStorage(4 * mem.GB).
+		WithFreq(1 * sim.GHz).
+		WithLatency(200).
+		Build("DRAM")
+
+	cacheModule := builder.
+		WithEngine(engine).
+		WithWayAssociativity(4).
+		WithByteSize(4 * 64).
+		WithAddressToPortMapper(&mem.SinglePortMapper{
+			Port: dram.GetPortByName("Top").AsRemote(),
+		}).
+		Build("Cache")
+	cacheModule.SetWayPartition(partitions)
+
+	reader.dst = cacheModule.topPort.AsRemote()
+	reader.TickingComponent = sim.NewTickingComponent(
+		"Reader", engine, 1*sim.GHz, reader)
+	reader.port = sim.NewPort(reader, 1, 1, "Reader.Port")
+
+	conn := directconnection.MakeBuilder().
+		WithEngine(engine).
+		WithFreq(1 * sim.GHz).
+		Build("Connection")
+	conn.PlugIn(reader.port)
+	conn.PlugIn(cacheModule.topPort)
+	conn.PlugIn(cacheModule.bottomPort)
+	conn.PlugIn(dram.GetPortByName("Top"))
+
+	reader.TickLater()
+	Expect(engine.Run()).To(Succeed())
+
+	return cacheModule
+}
+
+// fifoVictimFinder evicts the blocks in the order in which they are filled.
+type fifoVictimFinder struct {
+	next int
+}
+
+func (f *fifoVictimFinder) FindVictim(set *cache.Set) *cache.Block {
+	victim := set.Blocks[f.next%len(set.Blocks)]
+	f.next++
+
+	return victim
+}
+
+var _ = Describe("Victim Finder", func() {
+	It("should evict with the victim finder given to the builder", func() {
+		// LRU would evict 0x1000, as 0x0000 is read again before 0x4000.
+		cacheModule := runSequentialReads(
+			MakeBuilder().WithVictimFinder(&fifoVictimFinder{}), nil,
+			&sequentialReader{
+				addrs: []uint64{
+					0x0000, 0x1000, 0x2000, 0x3000, 0x0000, 0x4000,
+				},
+			})
+
+		isCached := func(addr uint64) bool {
+			block := cacheModule.directory.Lookup(0, addr)
+			return block != nil && block.IsValid
+		}
+
+		Expect(isCached(0x0000)).To(BeFalse())
+		Expect(isCached(0x1000)).To(BeTrue())
+		Expect(isCached(0x4000)).To(BeTrue())
+	})
+})
+
+var _ = Describe("Way Partitioning", func() {
+	It("should only fill the ways assigned to each process", func() {
+		// Process 1 gets way 0 and process 2 gets ways 1 to 3, so the second
+		// read of process 1 evicts its first read, while the three reads of
+		// process 2 all stay in the cache.
+		cacheModule := runSequentialReads(
+			MakeBuilder(), map[vm.PID]int{1: 1, 2: 3},
+			&sequentialReader{
+				addrs: []uint64{0x0000, 0x1000, 0x2000, 0x3000, 0x4000},
+				pids:  []vm.PID{1, 1, 2, 2, 2},
+			})
+
+		set := cacheModule.directory.GetSets()[0]
+		for _, block := range set.Blocks {
+			Expect(block.IsValid).To(BeTrue())
+
+			if block.WayID == 0 {
+				Expect(cacheModule.owner(block)).To(Equal(vm.PID(1)))
+				Expect(block.Tag).To(Equal(uint64(0x1000)))
+			} else {
+				Expect(cacheModule.owner(block)).To(Equal(vm.PID(2)))
+			}
+		}
+	})
+
+	It("should count the blocks of each process in each way", func() {
+		cacheModule := runSequentialReads(
+			MakeBuilder(), map[vm.PID]int{1: 1, 2: 3},
+			&sequentialReader{
+				addrs: []uint64{0x0000, 0x1000, 0x2000, 0x3000},
+				pids:  []vm.PID{1, 2, 2, 2},
+			})
+
+		Expect(cacheModule.CountBlocksPerWay(1)).To(Equal([]int{1, 0, 0, 0}))
+		Expect(cacheModule.CountBlocksPerWay(2)).To(Equal([]int{0, 1, 1, 1}))
+		Expect(cacheModule.CountBlocksPerWay(3)).To(Equal([]int{0, 0, 0, 0}))
+	})
+
+	It("should panic if the processes get more ways than the cache has",
+		func() {
+			cacheModule := MakeBuilder().
+				WithEngine(sim.NewSerialEngine()).
+				WithWayAssociativity(4).
+				WithByteSize(4 * 64).
+				Build("Cache")
+
+			Expect(func() {
+				cacheModule.SetWayPartition(map[vm.PID]int{1: 2, 2: 3})
+			}).To(Panic())
+		})
+})
@@ -0,0 +1,193 @@
+package writeback
+
+import (
+	"github.com/sarchlab/akita/v4/mem/cache"
+	"github.com/sarchlab/akita/v4/mem/mem"
+	"github.com/sarchlab/akita/v4/mem/vm"
+
+	"github.com/sarchlab/akita/v4/sim"
+)
+
+// blockPID is the PID that the blocks and the MSHR entries are tagged with. The
+// cache receives physical addresses, which the processes do not share, so all
+// the accesses to an address must find the same block, including the accesses
+// of the DMA engine that carry no PID. The PID of an access only tells which
+// process owns the block.
+const blockPID vm.PID = 0
+
+type cacheState int
+
+const (
+	cacheStateInvalid cacheState = iota
+	cacheStateRunning
+	cacheStatePreFlushing
+	cacheStateFlushing
+	cacheStatePaused
+)
+
+// Comp in the writeback package is a cache that performs the write-back policy.
+type Comp struct {
+	*sim.TickingComponent
+	sim.MiddlewareHolder
+
+	topPort     sim.Port
+	bottomPort  sim.Port
+	controlPort sim.Port
+
+	dirStageBuffer           sim.Buffer
+	dirToBankBuffers         []sim.Buffer
+	writeBufferToBankBuffers []sim.Buffer
+	mshrStageBuffer          sim.Buffer
+	writeBufferBuffer        sim.Buffer
+
+	topParser   *topParser
+	writeBuffer *writeBufferStage
+	dirStage    *directoryStage
+	bankStages  []*bankStage
+	mshrStage   *mshrStage
+	flusher     *flusher
+
+	storage             *mem.Storage
+	addressToPortMapper mem.AddressToPortMapper
+	directory           cache.Directory
+	victimFinder        *wayPartitionVictimFinder
+	owners              []vm.PID
+	mshr                cache.MSHR
+	coherenceDirectory  *coherenceDirectory
+	log2BlockSize       uint64
+	numReqPerCycle      int
+
+	state                cacheState
+	inFlightTransactions []*transaction
+	evictingList         map[uint64]bool
+}
+
+// SetAddressToPortMapper sets the AddressToPortMapper used by the cache.
+func (c *Comp) SetAddressToPortMapper(lmf mem.AddressToPortMapper) {
+	c.addressToPortMapper = lmf
+}
+
+// SetWayPartition restricts the processes to fill only a part of the ways of
+// each set. Each process in partitions is given the number of ways, and the
+// ways are assigned in the order of the PIDs, starting from way 0. The
+// processes that are not in partitions can fill any way. Passing nil removes
+// the partitioning.
+func (c *Comp) SetWayPartition(partitions map[vm.PID]int) {
+	c.victimFinder.setPartitions(partitions, c.directory.WayAssociativity())
+}
+
+// CountBlocksPerWay returns the number of valid blocks that the given process
+// holds in each way, summed over all the sets. A block belongs to the process
+// of the last access that filled or wrote it.
+func (c *Comp) CountBlocksPerWay(pid vm.PID) []int {
+	counts := make([]int, c.directory.WayAssociativity())
+
+	for _, set := range c.directory.GetSets() {
+		for _, block := range set.Blocks {
+			if block.IsValid && c.owner(block) == pid {
+				counts[block.WayID]++
+			}
+		}
+	}
+
+	return counts
+}
+
+// setOwner records the process that owns the data in the block. The blocks are
+// all tagged with blockPID, so the owners are kept apart from the blocks.
+func (c *Comp) setOwner(block *cache.Block, pid vm.PID) {
+	c.owners[block.SetID*c.directory.WayAssociativity()+block.WayID] = pid
+}
+
+func (c *Comp) owner(block *cache.Block) vm.PID {
+	return c.owners[block.SetID*c.directory.WayAssociativity()+block.WayID]
+}
+
+// blockReplaced invalidates the copies that the high-level caches hold of the
+// line in a block that is about to hold another line.
+func (c *Comp) blockReplaced(block *cache.Block) {
+	if c.coherenceDirectory == nil || !block.IsValid {
+		return
+	}
+
+	c.coherenceDirectory.lineReplaced(block.Tag)
+}
+
+func (c *Comp) findVictim(pid vm.PID, cacheLineID uint64) *cache.Block {
+	c.victimFinder.pid = pid
+
+	return c.directory.FindVictim(cacheLineID)
+}
+
+func (c *Comp) Tick() bool {
+	return c.MiddlewareHolder.Tick()
+}
+
+type middleware struct {
+	*Comp
+}
+
+// Tick updates the internal states of the Cache.
+func (m *middleware) Tick() bool {
+	madeProgress := false
+
+	if m.state != cacheStatePaused {
+		madeProgress = m.runPipeline() || madeProgress
+	}
+
+	madeProgress = m.flusher.Tick() || madeProgress
+
+	if m.coherenceDirectory != nil {
+		madeProgress = m.coherenceDirectory.Tick() || madeProgress
+	}
+
+	return madeProgress
+}
+
+func (m *middleware) runPipeline() bool {
+	madeProgress := false
+
+	madeProgress = m.runStage(m.mshrStage) || madeProgress
+
+	for _, bs := range m.bankStages {
+		madeProgress = bs.Tick() || madeProgress
+	}
+
+	madeProgress = m.runStage(m.writeBuffer) || madeProgress
+	madeProgress = m.runStage(m.dirStage) || madeProgress
+	madeProgress = m.runStage(m.topParser) || madeProgress
+
+	return madeProgress
+}
+
+func (m *middleware) runStage(stage sim.Ticker) bool {
+	madeProgress := false
+	for i := 0; i < m.numReqPerCycle; i++ {
+		madeProgress = stage.Tick() || madeProgress
+	}
+
+	return madeProgress
+}
+
+func (c *Comp) discardInflightTransactions() {
+	sets := c.directory.GetSets()
+	for _, set := range sets {
+		for _, block := range set.Blocks {
+			block.ReadCount = 0
+			block.IsLocked = false
+		}
+	}
+
+	c.dirStage.Reset()
+
+	for _, bs := range c.bankStages {
+		bs.Reset()
+	}
+
+	c.mshrStage.Reset()
+	c.writeBuffer.Reset()
+
+	clearPort(c.topPort)
+
+	c.inFlightTransactions = nil
+}
@@ -0,0 +1,474 @@
+package writeback
+
+import (
+	"github.com/sarchlab/akita/v4/mem/cache"
+	"github.com/sarchlab/akita/v4/mem/mem"
+	"github.com/sarchlab/akita/v4/tracing"
+	"github.com/sarchlab/mgpusim/v4/amd/atomic"
+)
+
+type writeBufferStage struct {
+	cache *Comp
+
+	writeBufferCapacity int
+	maxInflightFetch    int
+	maxInflightEviction int
+
+	pendingEvictions []*transaction
+	inflightFetch    []*transaction
+	inflightEviction []*transaction
+}
+
+func (wb *writeBufferStage) Tick() bool {
+	madeProgress := false
+
+	madeProgress = wb.write() || madeProgress
+	madeProgress = wb.processReturnRsp() || madeProgress
+	madeProgress = wb.processNewTransaction() || madeProgress
+
+	return madeProgress
+}
+
+func (wb *writeBufferStage) processNewTransaction() bool {
+	item := wb.cache.writeBufferBuffer.Peek()
+	if item == nil {
+		return false
+	}
+
+	trans := item.(*transaction)
+	switch trans.action {
+	case writeBufferFetch:
+		return wb.processWriteBufferFetch(trans)
+	case writeBufferEvictAndWrite:
+		return wb.processWriteBufferEvictAndWrite(trans)
+	case writeBufferEvictAndFetch:
+		return wb.processWriteBufferFetchAndEvict(trans)
+	case writeBufferFlush:
+		return wb.processWriteBufferFlush(trans, true)
+	case writeBufferBypass:
+		return wb.processWriteBufferBypass(trans)
+	default:
+		panic("unknown transaction action")
+	}
+}
+
+func (wb *writeBufferStage) processWriteBufferFetch(
+	trans *transaction,
+) bool {
+	// The fetch must not overtake a streaming store to the same line.
+	if wb.isBypassingLine(trans.fetchAddress) {
+		return false
+	}
+
+	if wb.findDataLocally(trans) {
+		return wb.sendFetchedDataToBank(trans)
+	}
+
+	return wb.fetchFromBottom(trans)
+}
+
+func (wb *writeBufferStage) findDataLocally(trans *transaction) bool {
+	for _, e := range wb.inflightEviction {
+		if e.action == writeBufferBypass {
+			continue
+		}
+
+		if e.evictingAddr == trans.fetchAddress {
+			trans.fetchedData = e.evictingData
+			return true
+		}
+	}
+
+	for _, e := range wb.pendingEvictions {
+		if e.action == writeBufferBypass {
+			continue
+		}
+
+		if e.evictingAddr == trans.fetchAddress {
+			trans.fetchedData = e.evictingData
+			return true
+		}
+	}
+
+	return false
+}
+
+func (wb *writeBufferStage) sendFetchedDataToBank(
+	trans *transaction,
+) bool {
+	bankNum := bankID(trans.block,
+		wb.cache.directory.WayAssociativity(),
+		len(wb.cache.dirToBankBuffers))
+	bankBuf := wb.cache.writeBufferToBankBuffers[bankNum]
+
+	if !bankBuf.CanPush() {
+		trans.fetchedData = nil
+		return false
+	}
+
+	trans.mshrEntry.Data = trans.fetchedData
+	trans.action = bankWriteFetched
+	wb.combineData(trans.mshrEntry)
+
+	wb.cache.mshr.Remove(trans.mshrEntry.PID, trans.mshrEntry.Address)
+
+	bankBuf.Push(trans)
+
+	wb.cache.writeBufferBuffer.Pop()
+
+	return true
+}
+
+func (wb *writeBufferStage) fetchFromBottom(
+	trans *transaction,
+) bool {
+	if wb.tooManyInflightFetches() {
+		return false
+	}
+
+	if !wb.cache.bottomPort.CanSend() {
+		return false
+	}
+
+	lowModulePort := wb.cache.addressToPortMapper.Find(trans.fetchAddress)
+	read := mem.ReadReqBuilder{}.
+		WithSrc(wb.cache.bottomPort.AsRemote()).
+		WithDst(lowModulePort).
+		WithPID(trans.fetchPID).
+		WithAddress(trans.fetchAddress).
+		WithByteSize(1 << wb.cache.log2BlockSize).
+		Build()
+	wb.cache.bottomPort.Send(read)
+
+	trans.fetchReadReq = read
+	wb.inflightFetch = append(wb.inflightFetch, trans)
+	wb.cache.writeBufferBuffer.Pop()
+
+	tracing.TraceReqInitiate(read, wb.cache,
+		tracing.MsgIDAtReceiver(trans.req(), wb.cache))
+
+	return true
+}
+
+func (wb *writeBufferStage) isBypassingLine(cacheLineID uint64) bool {
+	for _, list := range [][]*transaction{
+		wb.pendingEvictions, wb.inflightEviction,
+	} {
+		for _, e := range list {
+			if e.action != writeBufferBypass {
+				continue
+			}
+
+			lineID, _ := getCacheLineID(e.evictingAddr, wb.cache.log2BlockSize)
+			if lineID == cacheLineID {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// processWriteBufferBypass queues a streaming store to be written to the
+// low-level module. The cache responds to the store when the write completes.
+func (wb *writeBufferStage) processWriteBufferBypass(
+	trans *transaction,
+) bool {
+	if wb.writeBufferFull() {
+		return false
+	}
+
+	write := trans.write
+	trans.evictingPID = write.PID
+	trans.evictingAddr = write.Address
+	trans.evictingData = write.Data
+	trans.evictingDirtyMask = write.DirtyMask
+
+	wb.pendingEvictions = append(wb.pendingEvictions, trans)
+	wb.cache.writeBufferBuffer.Pop()
+
+	return true
+}
+
+func (wb *writeBufferStage) processWriteBufferEvictAndWrite(
+	trans *transaction,
+) bool {
+	if wb.writeBufferFull() {
+		return false
+	}
+
+	bankNum := bankID(
+		trans.block,
+		wb.cache.directory.WayAssociativity(),
+		len(wb.cache.dirToBankBuffers),
+	)
+	bankBuf := wb.cache.writeBufferToBankBuffers[bankNum]
+
+	if !bankBuf.CanPush() {
+		return false
+	}
+
+	trans.action = bankWriteHit
+	bankBuf.Push(trans)
+
+	wb.pendingEvictions = append(wb.pendingEvictions, trans)
+	wb.cache.writeBufferBuffer.Pop()
+
+	return true
+}
+
+func (wb *writeBufferStage) processWriteBufferFetchAndEvict(
+	trans *transaction,
+) bool {
+	ok := wb.processWriteBufferFlush(trans, false)
+	if ok {
+		trans.action = writeBufferFetch
+		return true
+	}
+
+	return false
+}
+
+func (wb *writeBufferStage) processWriteBufferFlush(
+	trans *transaction,
+	popAfterDone bool,
+) bool {
+	if wb.writeBufferFull() {
+		return false
+	}
+
+	wb.pendingEvictions = append(wb.pendingEvictions, trans)
+
+	if popAfterDone {
+		wb.cache.writeBufferBuffer.Pop()
+	}
+
+	return true
+}
+
+func (wb *writeBufferStage) write() bool {
+	if len(wb.pendingEvictions) == 0 {
+		return false
+	}
+
+	trans := wb.pendingEvictions[0]
+
+	if wb.tooManyInflightEvictions() {
+		return false
+	}
+
+	if !wb.cache.bottomPort.CanSend() {
+		return false
+	}
+
+	lowModulePort := wb.cache.addressToPortMapper.Find(trans.evictingAddr)
+	write := mem.WriteReqBuilder{}.
+		WithSrc(wb.cache.bottomPort.AsRemote()).
+		WithDst(lowModulePort).
+		WithPID(trans.evictingPID).
+		WithAddress(trans.evictingAddr).
+		WithData(trans.evictingData).
+		WithDirtyMask(trans.evictingDirtyMask).
+		Build()
+	wb.cache.bottomPort.Send(write)
+
+	trans.evictionWriteReq = write
+	wb.pendingEvictions = wb.pendingEvictions[1:]
+	wb.inflightEviction = append(wb.inflightEviction, trans)
+
+	tracing.TraceReqInitiate(write, wb.cache,
+		tracing.MsgIDAtReceiver(trans.req(), wb.cache))
+
+	return true
+}
+
+func (wb *writeBufferStage) processReturnRsp() bool {
+	msg := wb.cache.bottomPort.PeekIncoming()
+	if msg == nil {
+		return false
+	}
+
+	switch msg := msg.(type) {
+	case *mem.DataReadyRsp:
+		return wb.processDataReadyRsp(msg)
+	case *mem.WriteDoneRsp:
+		return wb.processWriteDoneRsp(msg)
+	default:
+		panic("unknown msg type")
+	}
+}
+
+func (wb *writeBufferStage) processDataReadyRsp(
+	dataReady *mem.DataReadyRsp,
+) bool {
+	trans := wb.findInflightFetchByFetchReadReqID(dataReady.RespondTo)
+	bankIndex := bankID(
+		trans.block,
+		wb.cache.directory.WayAssociativity(),
+		len(wb.cache.dirToBankBuffers),
+	)
+	bankBuf := wb.cache.writeBufferToBankBuffers[bankIndex]
+
+	if !bankBuf.CanPush() {
+		return false
+	}
+
+	trans.fetchedData = dataReady.Data
+	trans.action = bankWriteFetched
+	trans.mshrEntry.Data = dataReady.Data
+	wb.combineData(trans.mshrEntry)
+
+	wb.cache.mshr.Remove(trans.mshrEntry.PID, trans.mshrEntry.Address)
+
+	bankBuf.Push(trans)
+
+	wb.removeInflightFetch(trans)
+	wb.cache.bottomPort.RetrieveIncoming()
+
+	tracing.TraceReqFinalize(trans.fetchReadReq, wb.cache)
+
+	return true
+}
+
+func (wb *writeBufferStage) combineData(mshrEntry *cache.MSHREntry) {
+	mshrEntry.Block.DirtyMask = make([]bool, 1<<wb.cache.log2BlockSize)
+	for _, t := range mshrEntry.Requests {
+		trans := t.(*transaction)
+		if info := trans.atomicInfo(); info != nil {
+			wb.applyAtomic(mshrEntry, trans, info)
+			continue
+		}
+
+		if trans.read != nil {
+			continue
+		}
+
+		mshrEntry.Block.IsDirty = true
+		write := trans.write
+		_, offset := getCacheLineID(write.Address, wb.cache.log2BlockSize)
+
+		for i := 0; i < len(write.Data); i++ {
+			if write.DirtyMask == nil || write.DirtyMask[i] {
+				index := offset + uint64(i)
+				mshrEntry.Data[index] = write.Data[i]
+				mshrEntry.Block.DirtyMask[index] = true
+			}
+		}
+	}
+}
+
+func (wb *writeBufferStage) applyAtomic(
+	mshrEntry *cache.MSHREntry,
+	trans *transaction,
+	info *atomic.Info,
+) {
+	read := trans.read
+	_, offset := getCacheLineID(read.Address, wb.cache.log2BlockSize)
+	end := offset + read.AccessByteSize
+
+	trans.atomicOldData = append([]byte(nil), mshrEntry.Data[offset:end]...)
+	copy(mshrEntry.Data[offset:end], info.Apply(trans.atomicOldData))
+
+	mshrEntry.Block.IsDirty = true
+	for i := offset; i < end; i++ {
+		mshrEntry.Block.DirtyMask[i] = true
+	}
+}
+
+func (wb *writeBufferStage) findInflightFetchByFetchReadReqID(
+	id string,
+) *transaction {
+	for _, t := range wb.inflightFetch {
+		if t.fetchReadReq.ID == id {
+			return t
+		}
+	}
+
+	panic("inflight read not found")
+}
+
+func (wb *writeBufferStage) removeInflightFetch(f *transaction) {
+	for i, trans := range wb.inflightFetch {
+		if trans == f {
+			wb.inflightFetch = append(
+				wb.inflightFetch[:i],
+				wb.inflightFetch[i+1:]...,
+			)
+
+			return
+		}
+	}
+
+	panic("not found")
+}
+
+func (wb *writeBufferStage) processWriteDoneRsp(
+	writeDone *mem.WriteDoneRsp,
+) bool {
+	for i := len(wb.inflightEviction) - 1; i >= 0; i-- {
+		e := wb.inflightEviction[i]
+		if e.evictionWriteReq.ID == writeDone.RespondTo {
+			if e.action == writeBufferBypass && !wb.respondBypass(e) {
+				return false
+			}
+
+			wb.inflightEviction = append(
+				wb.inflightEviction[:i],
+				wb.inflightEviction[i+1:]...,
+			)
+			wb.cache.bottomPort.RetrieveIncoming()
+			tracing.TraceReqFinalize(e.evictionWriteReq, wb.cache)
+
+			return true
+		}
+	}
+
+	panic("write request not found")
+}
+
+// respondBypass responds to a streaming store after it is written to the
+// low-level module.
+func (wb *writeBufferStage) respondBypass(trans *transaction) bool {
+	if !wb.cache.topPort.CanSend() {
+		return false
+	}
+
+	write := trans.write
+	done := mem.WriteDoneRspBuilder{}.
+		WithSrc(wb.cache.topPort.AsRemote()).
+		WithDst(write.Src).
+		WithRspTo(write.ID).
+		Build()
+	wb.cache.topPort.Send(done)
+
+	tracing.TraceReqComplete(write, wb.cache)
+
+	for i, t := range wb.cache.inFlightTransactions {
+		if t == trans {
+			wb.cache.inFlightTransactions = append(
+				wb.cache.inFlightTransactions[:i],
+				wb.cache.inFlightTransactions[i+1:]...)
+
+			break
+		}
+	}
+
+	return true
+}
+
+func (wb *writeBufferStage) writeBufferFull() bool {
+	numEntry := len(wb.pendingEvictions) + len(wb.inflightEviction)
+	return numEntry >= wb.writeBufferCapacity
+}
+
+func (wb *writeBufferStage) tooManyInflightFetches() bool {
+	return len(wb.inflightFetch) >= wb.maxInflightFetch
+}
+
+func (wb *writeBufferStage) tooManyInflightEvictions() bool {
+	return len(wb.inflightEviction) >= wb.maxInflightEviction
+}
+
+func (wb *writeBufferStage) Reset() {
+	wb.cache.writeBufferBuffer.Clear()
+}
@@ -0,0 +1,111 @@
+package writecombining
+
+import (
+	"github.com/sarchlab/akita/v4/sim"
+)
+
+// A Builder can build write-combining buffers.
+type Builder struct {
+	engine          sim.Engine
+	freq            sim.Freq
+	log2BlockSize   uint64
+	depth           int
+	idleFlushCycles int
+	numReqPerCycle  int
+}
+
+// MakeBuilder creates a builder with default parameters.
+func MakeBuilder() Builder {
+	return Builder{
+		freq:            1 * sim.GHz,
+		log2BlockSize:   6,
+		depth:           8,
+		idleFlushCycles: 16,
+		numReqPerCycle:  4,
+	}
+}
+
+// WithEngine sets the engine to use.
+func (b Builder) WithEngine(engine sim.Engine) Builder {
+	b.engine = engine
+	return b
+}
+
+// WithFreq sets the frequency that the buffer works at.
+func (b Builder) WithFreq(freq sim.Freq) Builder {
+	b.freq = freq
+	return b
+}
+
+// WithLog2BlockSize sets the number of bytes in a cache line as a power of 2.
+func (b Builder) WithLog2BlockSize(n uint64) Builder {
+	b.log2BlockSize = n
+	return b
+}
+
+// WithDepth sets the number of cache lines that the buffer can hold.
+func (b Builder) WithDepth(depth int) Builder {
+	b.depth = depth
+	return b
+}
+
+// WithIdleFlushCycles sets the number of cycles that a line can stay in the
+// buffer without being written before the buffer sends it out.
+func (b Builder) WithIdleFlushCycles(n int) Builder {
+	b.idleFlushCycles = n
+	return b
+}
+
+// WithNumReqPerCycle sets the number of requests that the buffer can handle
+// in each cycle.
+func (b Builder) WithNumReqPerCycle(n int) Builder {
+	b.numReqPerCycle = n
+	return b
+}
+
+// Build creates a write-combining buffer with the given parameters.
+func (b Builder) Build(name string) *Comp {
+	if b.depth <= 0 {
+		panic("the write-combining buffer must hold at least one line")
+	}
+
+	c := &Comp{}
+
+	c.TickingComponent = sim.NewTickingComponent(name, b.engine, b.freq, c)
+
+	c.log2BlockSize = b.log2BlockSize
+	c.depth = b.depth
+	c.idleFlushCycles = b.idleFlushCycles
+	c.numReqPerCycle = b.numReqPerCycle
+	c.transactions = make(map[string]*transaction)
+
+	b.createPorts(name, c)
+
+	return c
+}
+
+func (b *Builder) createPorts(name string, c *Comp) {
+	c.topPort = sim.NewPort(
+		c,
+		2*b.numReqPerCycle,
+		2*b.numReqPerCycle,
+		name+".TopPort",
+	)
+	c.AddPort("Top", c.topPort)
+
+	c.bottomPort = sim.NewPort(
+		c,
+		2*b.numReqPerCycle,
+		2*b.numReqPerCycle,
+		name+".BottomPort",
+	)
+	c.AddPort("Bottom", c.bottomPort)
+
+	c.controlPort = sim.NewPort(
+		c,
+		1,
+		1,
+		name+".ControlPort",
+	)
+	c.AddPort("Control", c.controlPort)
+}
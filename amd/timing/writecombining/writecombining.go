@@ -0,0 +1,489 @@
+// Package writecombining implements a buffer that combines the writes to the
+// same cache line before they reach a cache.
+//
+// The buffer holds a number of cache lines, each with the bytes written so
+// far. A full-line write merges into the line that it writes to, and the
+// buffer sends the combined write to the low module when the whole line is
+// written, when the line has not been written for a number of cycles, or
+// when the buffer needs the space for another line. The buffer responds to
+// each write once the combined write completes. A read to a buffered line
+// sends the line out first, so the read observes the writes. The writes that
+// cannot be combined, such as the atomic accesses and the partial-line
+// writes, are forwarded as they are.
+package writecombining
+
+import (
+	"log"
+	"reflect"
+
+	"github.com/sarchlab/akita/v4/mem/cache"
+	"github.com/sarchlab/akita/v4/mem/mem"
+	"github.com/sarchlab/akita/v4/mem/vm"
+	"github.com/sarchlab/akita/v4/sim"
+	"github.com/sarchlab/akita/v4/tracing"
+)
+
+type lineKey struct {
+	pid     vm.PID
+	address uint64
+}
+
+type entry struct {
+	key        lineKey
+	data       []byte
+	dirtyMask  []bool
+	writes     []*mem.WriteReq
+	idleCycles int
+}
+
+type transaction struct {
+	reqsFromTop []mem.AccessReq
+	reqToBottom mem.AccessReq
+}
+
+// Comp is a write-combining buffer.
+type Comp struct {
+	*sim.TickingComponent
+
+	topPort     sim.Port
+	bottomPort  sim.Port
+	controlPort sim.Port
+
+	addressToPortMapper mem.AddressToPortMapper
+
+	log2BlockSize   uint64
+	depth           int
+	idleFlushCycles int
+	numReqPerCycle  int
+
+	// entries is ordered from the oldest line to the newest line.
+	entries      []*entry
+	transactions map[string]*transaction
+	isPaused     bool
+}
+
+// SetAddressToPortMapper sets the finder that tells which remote port can serve
+// the data on a certain address.
+func (c *Comp) SetAddressToPortMapper(lmf mem.AddressToPortMapper) {
+	c.addressToPortMapper = lmf
+}
+
+// Tick updates the status of the write-combining buffer.
+func (c *Comp) Tick() (madeProgress bool) {
+	madeProgress = c.processControlMsg() || madeProgress
+
+	if c.isPaused {
+		return madeProgress
+	}
+
+	for i := 0; i < c.numReqPerCycle; i++ {
+		madeProgress = c.parseBottom() || madeProgress
+	}
+
+	for i := 0; i < c.numReqPerCycle; i++ {
+		madeProgress = c.parseTop() || madeProgress
+	}
+
+	madeProgress = c.flushReadyEntries() || madeProgress
+
+	return madeProgress
+}
+
+func (c *Comp) parseTop() bool {
+	item := c.topPort.PeekIncoming()
+	if item == nil {
+		return false
+	}
+
+	switch req := item.(type) {
+	case *mem.ReadReq:
+		return c.processRead(req)
+	case *mem.WriteReq:
+		return c.processWrite(req)
+	default:
+		log.Panicf("cannot handle request of type %s", reflect.TypeOf(item))
+	}
+
+	panic("never")
+}
+
+func (c *Comp) processRead(read *mem.ReadReq) bool {
+	if !c.flushOverlappingEntry(read) {
+		return false
+	}
+
+	if !c.forward(read) {
+		return false
+	}
+
+	c.topPort.RetrieveIncoming()
+
+	return true
+}
+
+func (c *Comp) processWrite(write *mem.WriteReq) bool {
+	if !c.canCombine(write) {
+		return c.processBypassWrite(write)
+	}
+
+	key := lineKey{pid: write.PID, address: write.Address}
+	if i := c.findEntry(key); i >= 0 {
+		c.merge(c.entries[i], write)
+		tracing.AddTaskStep(
+			tracing.MsgIDAtReceiver(write, c), c, "write-combine")
+	} else {
+		if len(c.entries) >= c.depth && !c.flushEntry(0) {
+			return false
+		}
+
+		e := &entry{
+			key:       key,
+			data:      make([]byte, len(write.Data)),
+			dirtyMask: make([]bool, len(write.Data)),
+		}
+		c.entries = append(c.entries, e)
+		c.merge(e, write)
+		tracing.AddTaskStep(
+			tracing.MsgIDAtReceiver(write, c), c, "write-allocate")
+	}
+
+	c.topPort.RetrieveIncoming()
+
+	return true
+}
+
+// processBypassWrite forwards the writes that cannot be combined, after
+// sending out the buffered line that they write to.
+func (c *Comp) processBypassWrite(write *mem.WriteReq) bool {
+	if !c.flushOverlappingEntry(write) {
+		return false
+	}
+
+	if !c.forward(write) {
+		return false
+	}
+
+	tracing.AddTaskStep(tracing.MsgIDAtReceiver(write, c), c, "write-bypass")
+	c.topPort.RetrieveIncoming()
+
+	return true
+}
+
+// canCombine checks if the write covers exactly one cache line. The writes
+// that carry extra information cannot be combined, as the information would
+// be lost.
+func (c *Comp) canCombine(write *mem.WriteReq) bool {
+	blockSize := uint64(1) << c.log2BlockSize
+
+	return write.Info == nil &&
+		uint64(len(write.Data)) == blockSize &&
+		write.Address%blockSize == 0
+}
+
+func (c *Comp) merge(e *entry, write *mem.WriteReq) {
+	for i, b := range write.Data {
+		if write.DirtyMask != nil && !write.DirtyMask[i] {
+			continue
+		}
+
+		e.data[i] = b
+		e.dirtyMask[i] = true
+	}
+
+	e.writes = append(e.writes, write)
+	e.idleCycles = 0
+
+	tracing.TraceReqReceive(write, c)
+}
+
+func (c *Comp) findEntry(key lineKey) int {
+	for i, e := range c.entries {
+		if e.key == key {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// flushOverlappingEntry sends out the buffered lines that the request
+// accesses. It returns false if a line cannot be sent out.
+func (c *Comp) flushOverlappingEntry(req mem.AccessReq) bool {
+	blockSize := uint64(1) << c.log2BlockSize
+	start := req.GetAddress()
+	end := start + req.GetByteSize()
+
+	for i := 0; i < len(c.entries); i++ {
+		e := c.entries[i]
+		if e.key.pid != req.GetPID() ||
+			e.key.address >= end || e.key.address+blockSize <= start {
+			continue
+		}
+
+		if !c.flushEntry(i) {
+			return false
+		}
+
+		i--
+	}
+
+	return true
+}
+
+// flushReadyEntries sends out the lines that are fully written and the lines
+// that have not been written for a while. The buffer keeps ticking as long as
+// it holds lines, so that the idle lines are eventually sent out.
+func (c *Comp) flushReadyEntries() bool {
+	if len(c.entries) == 0 {
+		return false
+	}
+
+	for i := 0; i < len(c.entries); i++ {
+		e := c.entries[i]
+		e.idleCycles++
+
+		if !c.isFullyDirty(e) && e.idleCycles < c.idleFlushCycles {
+			continue
+		}
+
+		if c.flushEntry(i) {
+			i--
+		}
+	}
+
+	return true
+}
+
+func (c *Comp) isFullyDirty(e *entry) bool {
+	for _, dirty := range e.dirtyMask {
+		if !dirty {
+			return false
+		}
+	}
+
+	return true
+}
+
+// flushEntry sends the i-th line to the low module as one write.
+func (c *Comp) flushEntry(i int) bool {
+	e := c.entries[i]
+
+	write := mem.WriteReqBuilder{}.
+		WithSrc(c.bottomPort.AsRemote()).
+		WithDst(c.addressToPortMapper.Find(e.key.address)).
+		WithAddress(e.key.address).
+		WithPID(e.key.pid).
+		WithData(e.data).
+		WithDirtyMask(e.dirtyMask).
+		Build()
+
+	err := c.bottomPort.Send(write)
+	if err != nil {
+		return false
+	}
+
+	reqsFromTop := make([]mem.AccessReq, 0, len(e.writes))
+	for _, w := range e.writes {
+		reqsFromTop = append(reqsFromTop, w)
+	}
+
+	c.transactions[write.ID] = &transaction{
+		reqsFromTop: reqsFromTop,
+		reqToBottom: write,
+	}
+	c.entries = append(c.entries[:i], c.entries[i+1:]...)
+
+	tracing.TraceReqInitiate(write, c,
+		tracing.MsgIDAtReceiver(e.writes[0], c))
+
+	return true
+}
+
+func (c *Comp) forward(req mem.AccessReq) bool {
+	reqToBottom := c.duplicateReq(req)
+
+	err := c.bottomPort.Send(reqToBottom)
+	if err != nil {
+		return false
+	}
+
+	c.transactions[reqToBottom.Meta().ID] = &transaction{
+		reqsFromTop: []mem.AccessReq{req},
+		reqToBottom: reqToBottom,
+	}
+
+	tracing.TraceReqReceive(req, c)
+	tracing.TraceReqInitiate(reqToBottom, c, tracing.MsgIDAtReceiver(req, c))
+
+	return true
+}
+
+func (c *Comp) duplicateReq(req mem.AccessReq) mem.AccessReq {
+	dst := c.addressToPortMapper.Find(req.GetAddress())
+
+	switch req := req.(type) {
+	case *mem.ReadReq:
+		clone := mem.ReadReqBuilder{}.
+			WithSrc(c.bottomPort.AsRemote()).
+			WithDst(dst).
+			WithAddress(req.Address).
+			WithByteSize(req.AccessByteSize).
+			WithPID(req.PID).
+			WithInfo(req.Info).
+			Build()
+		clone.CanWaitForCoalesce = req.CanWaitForCoalesce
+
+		return clone
+	case *mem.WriteReq:
+		clone := mem.WriteReqBuilder{}.
+			WithSrc(c.bottomPort.AsRemote()).
+			WithDst(dst).
+			WithAddress(req.Address).
+			WithPID(req.PID).
+			WithData(req.Data).
+			WithDirtyMask(req.DirtyMask).
+			WithInfo(req.Info).
+			Build()
+		clone.CanWaitForCoalesce = req.CanWaitForCoalesce
+
+		return clone
+	default:
+		panic("unsupported type")
+	}
+}
+
+func (c *Comp) parseBottom() bool {
+	item := c.bottomPort.PeekIncoming()
+	if item == nil {
+		return false
+	}
+
+	rsp := item.(mem.AccessRsp)
+	trans, found := c.transactions[rsp.GetRspTo()]
+
+	if !found {
+		// The transaction is discarded by a flush.
+		c.bottomPort.RetrieveIncoming()
+		return true
+	}
+
+	madeProgress := false
+
+	for len(trans.reqsFromTop) > 0 {
+		reqFromTop := trans.reqsFromTop[0]
+
+		err := c.topPort.Send(c.duplicateRsp(rsp, reqFromTop))
+		if err != nil {
+			return madeProgress
+		}
+
+		tracing.TraceReqComplete(reqFromTop, c)
+		trans.reqsFromTop = trans.reqsFromTop[1:]
+		madeProgress = true
+	}
+
+	delete(c.transactions, rsp.GetRspTo())
+	c.bottomPort.RetrieveIncoming()
+
+	tracing.TraceReqFinalize(trans.reqToBottom, c)
+
+	return true
+}
+
+func (c *Comp) duplicateRsp(
+	rsp mem.AccessRsp,
+	reqFromTop mem.AccessReq,
+) mem.AccessRsp {
+	switch rsp := rsp.(type) {
+	case *mem.DataReadyRsp:
+		return mem.DataReadyRspBuilder{}.
+			WithSrc(c.topPort.AsRemote()).
+			WithDst(reqFromTop.Meta().Src).
+			WithRspTo(reqFromTop.Meta().ID).
+			WithData(rsp.Data).
+			Build()
+	case *mem.WriteDoneRsp:
+		return mem.WriteDoneRspBuilder{}.
+			WithSrc(c.topPort.AsRemote()).
+			WithDst(reqFromTop.Meta().Src).
+			WithRspTo(reqFromTop.Meta().ID).
+			Build()
+	default:
+		panic("type not supported")
+	}
+}
+
+func (c *Comp) processControlMsg() bool {
+	item := c.controlPort.PeekIncoming()
+	if item == nil {
+		return false
+	}
+
+	switch req := item.(type) {
+	case *cache.FlushReq:
+		return c.flush(req)
+	case *cache.RestartReq:
+		return c.restart(req)
+	default:
+		log.Panicf("cannot handle request of type %s", reflect.TypeOf(item))
+	}
+
+	panic("never")
+}
+
+// flush sends out all the buffered lines and waits for the writes to complete
+// before responding, unless the in-flight requests are discarded.
+func (c *Comp) flush(req *cache.FlushReq) bool {
+	if !req.DiscardInflight {
+		if len(c.entries) > 0 {
+			return c.flushEntry(0)
+		}
+
+		if len(c.transactions) > 0 {
+			return false
+		}
+	}
+
+	rsp := cache.FlushRspBuilder{}.
+		WithSrc(c.controlPort.AsRemote()).
+		WithDst(req.Src).
+		WithRspTo(req.ID).
+		Build()
+
+	err := c.controlPort.Send(rsp)
+	if err != nil {
+		return false
+	}
+
+	c.entries = nil
+	c.transactions = make(map[string]*transaction)
+	c.isPaused = req.PauseAfterFlushing
+	c.controlPort.RetrieveIncoming()
+
+	return true
+}
+
+func (c *Comp) restart(req *cache.RestartReq) bool {
+	rsp := cache.RestartRspBuilder{}.
+		WithSrc(c.controlPort.AsRemote()).
+		WithDst(req.Src).
+		WithRspTo(req.ID).
+		Build()
+
+	err := c.controlPort.Send(rsp)
+	if err != nil {
+		return false
+	}
+
+	c.isPaused = false
+
+	for c.topPort.RetrieveIncoming() != nil {
+	}
+
+	for c.bottomPort.RetrieveIncoming() != nil {
+	}
+
+	c.controlPort.RetrieveIncoming()
+
+	return true
+}
@@ -0,0 +1,329 @@
+package writecombining
+
+import (
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/sarchlab/akita/v4/mem/cache"
+	"github.com/sarchlab/akita/v4/mem/mem"
+	"github.com/sarchlab/akita/v4/sim"
+)
+
+var _ = Describe("Write-Combining Buffer", func() {
+	var (
+		mockCtrl            *gomock.Controller
+		c                   *Comp
+		topPort             *MockPort
+		bottomPort          *MockPort
+		ctrlPort            *MockPort
+		addressToPortMapper *MockAddressToPortMapper
+	)
+
+	// lineWrite writes n bytes from the offset of the line at the address,
+	// in the way that the coalescer of the CU writes.
+	lineWrite := func(address uint64, offset, n int) *mem.WriteReq {
+		data := make([]byte, 64)
+		dirtyMask := make([]bool, 64)
+		for i := offset; i < offset+n; i++ {
+			data[i] = byte(i)
+			dirtyMask[i] = true
+		}
+
+		return mem.WriteReqBuilder{}.
+			WithSrc(sim.RemotePort("L1AT")).
+			WithAddress(address).
+			WithData(data).
+			WithDirtyMask(dirtyMask).
+			Build()
+	}
+
+	// bufferedLine creates a line in the buffer with its first 4 bytes written.
+	bufferedLine := func(address uint64) *entry {
+		write := lineWrite(address, 0, 4)
+
+		return &entry{
+			key:       lineKey{address: address},
+			data:      write.Data,
+			dirtyMask: write.DirtyMask,
+			writes:    []*mem.WriteReq{write},
+		}
+	}
+
+	expectIdleTick := func(req sim.Msg) {
+		ctrlPort.EXPECT().PeekIncoming().Return(nil)
+		bottomPort.EXPECT().PeekIncoming().Return(nil)
+		topPort.EXPECT().PeekIncoming().Return(req)
+	}
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+
+		topPort = NewMockPort(mockCtrl)
+		bottomPort = NewMockPort(mockCtrl)
+		ctrlPort = NewMockPort(mockCtrl)
+		addressToPortMapper = NewMockAddressToPortMapper(mockCtrl)
+
+		topPort.EXPECT().AsRemote().Return(sim.RemotePort("Top")).AnyTimes()
+		bottomPort.EXPECT().AsRemote().
+			Return(sim.RemotePort("Bottom")).AnyTimes()
+		ctrlPort.EXPECT().AsRemote().
+			Return(sim.RemotePort("Control")).AnyTimes()
+		addressToPortMapper.EXPECT().Find(gomock.Any()).
+			Return(sim.RemotePort("L1V")).AnyTimes()
+
+		c = MakeBuilder().
+			WithDepth(2).
+			WithIdleFlushCycles(4).
+			WithNumReqPerCycle(1).
+			Build("WCB")
+		c.topPort = topPort
+		c.bottomPort = bottomPort
+		c.controlPort = ctrlPort
+		c.SetAddressToPortMapper(addressToPortMapper)
+	})
+
+	AfterEach(func() {
+		mockCtrl.Finish()
+	})
+
+	It("should buffer a partial-line write", func() {
+		write := lineWrite(0x100, 0, 16)
+
+		expectIdleTick(write)
+		topPort.EXPECT().RetrieveIncoming()
+
+		madeProgress := c.Tick()
+
+		Expect(madeProgress).To(BeTrue())
+		Expect(c.entries).To(HaveLen(1))
+		Expect(c.entries[0].key.address).To(Equal(uint64(0x100)))
+		Expect(c.entries[0].writes).To(ConsistOf(write))
+	})
+
+	It("should combine the writes to the same line", func() {
+		write1 := lineWrite(0x100, 0, 16)
+		write2 := lineWrite(0x100, 16, 16)
+
+		expectIdleTick(write1)
+		topPort.EXPECT().RetrieveIncoming()
+		c.Tick()
+
+		expectIdleTick(write2)
+		topPort.EXPECT().RetrieveIncoming()
+		c.Tick()
+
+		Expect(c.entries).To(HaveLen(1))
+		Expect(c.entries[0].writes).To(ConsistOf(write1, write2))
+		Expect(c.entries[0].dirtyMask[0:32]).NotTo(ContainElement(false))
+		Expect(c.entries[0].dirtyMask[32:]).NotTo(ContainElement(true))
+	})
+
+	It("should send out a line once it is fully written", func() {
+		write1 := lineWrite(0x100, 0, 32)
+		write2 := lineWrite(0x100, 32, 32)
+
+		expectIdleTick(write1)
+		topPort.EXPECT().RetrieveIncoming()
+		c.Tick()
+
+		var writeToBottom *mem.WriteReq
+		expectIdleTick(write2)
+		topPort.EXPECT().RetrieveIncoming()
+		bottomPort.EXPECT().Send(gomock.Any()).
+			DoAndReturn(func(req *mem.WriteReq) *sim.SendError {
+				writeToBottom = req
+				return nil
+			})
+		c.Tick()
+
+		Expect(c.entries).To(BeEmpty())
+		Expect(writeToBottom.Address).To(Equal(uint64(0x100)))
+		Expect(writeToBottom.Dst).To(Equal(sim.RemotePort("L1V")))
+		Expect(writeToBottom.DirtyMask).NotTo(ContainElement(false))
+		Expect(writeToBottom.Data[40]).To(Equal(byte(40)))
+		Expect(c.transactions[writeToBottom.ID].reqsFromTop).
+			To(HaveLen(2))
+	})
+
+	It("should send out the oldest line if full", func() {
+		c.entries = []*entry{
+			bufferedLine(0x200),
+			bufferedLine(0x300),
+		}
+		write := lineWrite(0x100, 0, 16)
+
+		expectIdleTick(write)
+		bottomPort.EXPECT().Send(gomock.Any()).
+			DoAndReturn(func(req *mem.WriteReq) *sim.SendError {
+				Expect(req.Address).To(Equal(uint64(0x200)))
+				return nil
+			})
+		topPort.EXPECT().RetrieveIncoming()
+
+		c.Tick()
+
+		Expect(c.entries).To(HaveLen(2))
+		Expect(c.entries[0].key.address).To(Equal(uint64(0x300)))
+		Expect(c.entries[1].key.address).To(Equal(uint64(0x100)))
+	})
+
+	It("should stall if the oldest line cannot be sent out", func() {
+		c.entries = []*entry{
+			bufferedLine(0x200),
+			bufferedLine(0x300),
+		}
+		write := lineWrite(0x100, 0, 16)
+
+		expectIdleTick(write)
+		bottomPort.EXPECT().Send(gomock.Any()).Return(&sim.SendError{})
+
+		c.Tick()
+
+		Expect(c.entries).To(HaveLen(2))
+		Expect(c.entries[0].key.address).To(Equal(uint64(0x200)))
+	})
+
+	It("should send out a line that is not written for a while", func() {
+		write := lineWrite(0x100, 0, 16)
+
+		expectIdleTick(write)
+		topPort.EXPECT().RetrieveIncoming()
+		c.Tick()
+
+		for i := 0; i < 2; i++ {
+			expectIdleTick(nil)
+			c.Tick()
+		}
+
+		expectIdleTick(nil)
+		bottomPort.EXPECT().Send(gomock.Any()).
+			DoAndReturn(func(req *mem.WriteReq) *sim.SendError {
+				Expect(req.Address).To(Equal(uint64(0x100)))
+				return nil
+			})
+		madeProgress := c.Tick()
+
+		Expect(madeProgress).To(BeTrue())
+		Expect(c.entries).To(BeEmpty())
+	})
+
+	It("should send out the line before forwarding a read to it", func() {
+		c.entries = []*entry{
+			bufferedLine(0x100),
+		}
+		read := mem.ReadReqBuilder{}.
+			WithAddress(0x100).
+			WithByteSize(64).
+			Build()
+
+		expectIdleTick(read)
+		gomock.InOrder(
+			bottomPort.EXPECT().
+				Send(gomock.AssignableToTypeOf(&mem.WriteReq{})),
+			bottomPort.EXPECT().
+				Send(gomock.AssignableToTypeOf(&mem.ReadReq{})),
+		)
+		topPort.EXPECT().RetrieveIncoming()
+
+		c.Tick()
+
+		Expect(c.entries).To(BeEmpty())
+		Expect(c.transactions).To(HaveLen(2))
+	})
+
+	It("should forward the writes that do not cover a line", func() {
+		write := mem.WriteReqBuilder{}.
+			WithAddress(0x104).
+			WithData([]byte{1, 2, 3, 4}).
+			Build()
+
+		expectIdleTick(write)
+		bottomPort.EXPECT().Send(gomock.Any()).
+			DoAndReturn(func(req *mem.WriteReq) *sim.SendError {
+				Expect(req.Address).To(Equal(uint64(0x104)))
+				Expect(req.Data).To(Equal([]byte{1, 2, 3, 4}))
+				return nil
+			})
+		topPort.EXPECT().RetrieveIncoming()
+
+		c.Tick()
+
+		Expect(c.entries).To(BeEmpty())
+	})
+
+	It("should respond to all the combined writes", func() {
+		write1 := lineWrite(0x100, 0, 4)
+		write2 := lineWrite(0x100, 4, 4)
+		writeToBottom := lineWrite(0x100, 0, 8)
+		c.transactions[writeToBottom.ID] = &transaction{
+			reqsFromTop: []mem.AccessReq{write1, write2},
+			reqToBottom: writeToBottom,
+		}
+		rsp := mem.WriteDoneRspBuilder{}.
+			WithRspTo(writeToBottom.ID).
+			Build()
+
+		var respondTo []string
+		ctrlPort.EXPECT().PeekIncoming().Return(nil)
+		bottomPort.EXPECT().PeekIncoming().Return(rsp)
+		bottomPort.EXPECT().RetrieveIncoming()
+		topPort.EXPECT().PeekIncoming().Return(nil)
+		topPort.EXPECT().Send(gomock.Any()).
+			DoAndReturn(func(rsp *mem.WriteDoneRsp) *sim.SendError {
+				Expect(rsp.Dst).To(Equal(sim.RemotePort("L1AT")))
+				respondTo = append(respondTo, rsp.RespondTo)
+				return nil
+			}).Times(2)
+
+		madeProgress := c.Tick()
+
+		Expect(madeProgress).To(BeTrue())
+		Expect(respondTo).To(Equal([]string{write1.ID, write2.ID}))
+		Expect(c.transactions).To(BeEmpty())
+	})
+
+	It("should send out the lines before responding to a flush", func() {
+		c.entries = []*entry{
+			bufferedLine(0x100),
+		}
+		flush := cache.FlushReqBuilder{}.
+			WithSrc(sim.RemotePort("CP")).
+			Build()
+
+		ctrlPort.EXPECT().PeekIncoming().Return(flush)
+		bottomPort.EXPECT().PeekIncoming().Return(nil)
+		topPort.EXPECT().PeekIncoming().Return(nil)
+		bottomPort.EXPECT().Send(gomock.Any())
+
+		madeProgress := c.Tick()
+
+		Expect(madeProgress).To(BeTrue())
+		Expect(c.entries).To(BeEmpty())
+		Expect(c.transactions).To(HaveLen(1))
+	})
+
+	It("should discard the lines when flushing with discarding", func() {
+		c.entries = []*entry{
+			bufferedLine(0x100),
+		}
+		flush := cache.FlushReqBuilder{}.
+			WithSrc(sim.RemotePort("CP")).
+			DiscardInflight().
+			PauseAfterFlushing().
+			Build()
+
+		ctrlPort.EXPECT().PeekIncoming().Return(flush)
+		ctrlPort.EXPECT().RetrieveIncoming()
+		ctrlPort.EXPECT().Send(gomock.Any()).
+			DoAndReturn(func(rsp *cache.FlushRsp) *sim.SendError {
+				Expect(rsp.RspTo).To(Equal(flush.ID))
+				return nil
+			})
+
+		madeProgress := c.Tick()
+
+		Expect(madeProgress).To(BeTrue())
+		Expect(c.entries).To(BeEmpty())
+		Expect(c.isPaused).To(BeTrue())
+	})
+})